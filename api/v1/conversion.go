@@ -0,0 +1,10 @@
+package v1
+
+// v1 is the hub and storage version: other versions of McallTask and McallWorkflow are
+// converted to and from it by the conversion webhook.
+
+// Hub marks McallTask as a conversion hub
+func (*McallTask) Hub() {}
+
+// Hub marks McallWorkflow as a conversion hub
+func (*McallWorkflow) Hub() {}
@@ -0,0 +1,148 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// McallLoggingConfigSpec defines the logging backends task results are logged to.
+// Unset fields keep the operator settings (LOGGING_* environment variables).
+type McallLoggingConfigSpec struct {
+	// Enabled turns logging of task results on or off
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// PostgreSQL backend
+	PostgreSQL *SQLLoggingBackend `json:"postgresql,omitempty"`
+
+	// MySQL backend
+	MySQL *SQLLoggingBackend `json:"mysql,omitempty"`
+
+	// Elasticsearch backend
+	Elasticsearch *ElasticsearchLoggingBackend `json:"elasticsearch,omitempty"`
+
+	// Kafka backend
+	Kafka *KafkaLoggingBackend `json:"kafka,omitempty"`
+
+	// Loki: Grafana Loki backend
+	Loki *LokiLoggingBackend `json:"loki,omitempty"`
+
+	// CloudWatch: AWS CloudWatch Logs backend, authenticated with the controller's IRSA role
+	CloudWatch *CloudWatchLoggingBackend `json:"cloudwatch,omitempty"`
+}
+
+// SQLLoggingBackend configures a PostgreSQL or MySQL logging backend
+type SQLLoggingBackend struct {
+	// Enabled turns the backend on or off
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Host of the database server
+	Host string `json:"host,omitempty"`
+
+	// Port of the database server
+	Port int32 `json:"port,omitempty"`
+
+	// Database name
+	Database string `json:"database,omitempty"`
+
+	// SSLMode of PostgreSQL connections, e.g. "require"
+	SSLMode string `json:"sslMode,omitempty"`
+
+	// Table entries are inserted into
+	Table string `json:"table,omitempty"`
+
+	// SecretName: Secret holding "username" and "password", in the namespace of the McallLoggingConfig
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// ElasticsearchLoggingBackend configures an Elasticsearch logging backend
+type ElasticsearchLoggingBackend struct {
+	// Enabled turns the backend on or off
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// URL of Elasticsearch, e.g. "http://elasticsearch:9200"
+	URL string `json:"url,omitempty"`
+
+	// Index entries are indexed into
+	Index string `json:"index,omitempty"`
+
+	// SecretName: Secret holding "username" and "password" for basic auth, in the namespace
+	// of the McallLoggingConfig
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// KafkaLoggingBackend configures a Kafka logging backend
+type KafkaLoggingBackend struct {
+	// Enabled turns the backend on or off
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Brokers: bootstrap broker addresses, e.g. ["kafka:9092"]
+	Brokers []string `json:"brokers,omitempty"`
+
+	// Topic entries are produced to
+	Topic string `json:"topic,omitempty"`
+
+	// SASLMechanism to authenticate with
+	// +kubebuilder:validation:Enum=PLAIN;SCRAM-SHA-256;SCRAM-SHA-512
+	SASLMechanism string `json:"saslMechanism,omitempty"`
+
+	// SecretName: Secret holding the SASL "username" and "password", in the namespace of the
+	// McallLoggingConfig
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// LokiLoggingBackend configures a Grafana Loki logging backend
+type LokiLoggingBackend struct {
+	// Enabled turns the backend on or off
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// URL of Loki, e.g. "http://loki:3100"
+	URL string `json:"url,omitempty"`
+
+	// TenantID sent as X-Scope-OrgID to multi-tenant Loki
+	TenantID string `json:"tenantID,omitempty"`
+
+	// SecretName: Secret holding "username" and "password" for basic auth, in the namespace
+	// of the McallLoggingConfig
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// CloudWatchLoggingBackend configures an AWS CloudWatch Logs logging backend
+type CloudWatchLoggingBackend struct {
+	// Enabled turns the backend on or off
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// LogGroup entries are put to
+	LogGroup string `json:"logGroup,omitempty"`
+
+	// LogStream entries are put to
+	LogStream string `json:"logStream,omitempty"`
+
+	// Region of the log group (default: the controller's AWS_REGION)
+	Region string `json:"region,omitempty"`
+}
+
+// McallLoggingConfig is the Schema for the mcallloggingconfigs API.
+// The McallLoggingConfig named by the controller's LOGGING_CONFIG_NAME ("default") in the
+// operator namespace configures logging for every task; the one of the same name in a task
+// namespace overrides it for the tasks of that namespace.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=mlc
+// +kubebuilder:printcolumn:name="Enabled",type="boolean",JSONPath=".spec.enabled"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type McallLoggingConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec McallLoggingConfigSpec `json:"spec,omitempty"`
+}
+
+// McallLoggingConfigList contains a list of McallLoggingConfig
+// +kubebuilder:object:root=true
+type McallLoggingConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []McallLoggingConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&McallLoggingConfig{}, &McallLoggingConfigList{})
+}
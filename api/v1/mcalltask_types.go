@@ -7,30 +7,97 @@ import (
 
 // McallTaskSpec defines the desired state of McallTask
 type McallTaskSpec struct {
-	// Type of request (command, HTTP GET, HTTP POST)
+	// Type of request (command, HTTP GET, HTTP POST, GraphQL, gRPC, TCP port check, SQL query, Kafka canary, pod exec, SFTP/FTP, SMTP, WebSocket)
 	Type string `json:"type"`
 
-	// Input command or URL to execute
+	// Input command or URL to execute (host:port for grpc and tcp tasks, the query for sql tasks,
+	// comma-separated bootstrap brokers for kafka tasks, the shell command for kubectl-exec tasks,
+	// an sftp:// or ftp:// URL of a directory or file for sftp tasks, host:port of the mail server for smtp tasks,
+	// a ws:// or wss:// URL for websocket tasks, the script arguments of cmd tasks with scriptRef)
 	Input string `json:"input"`
 
+	// ScriptRef: ConfigMap key holding the script run by a cmd task instead of the input command
+	ScriptRef *ScriptRef `json:"scriptRef,omitempty"`
+
+	// Shell running cmd inputs and scripts without a #! line: sh, bash or pwsh (default: bash)
+	// +kubebuilder:validation:Enum=sh;bash;pwsh
+	Shell string `json:"shell,omitempty"`
+
+	// WorkingDir: directory cmd inputs and scripts run in (default: the controller's working directory)
+	WorkingDir string `json:"workingDir,omitempty"`
+
+	// Body: request body sent by post tasks
+	Body string `json:"body,omitempty"`
+
+	// ContentType: Content-Type of the post body (default: "text/xml; charset=utf-8" for
+	// XML bodies such as SOAP envelopes, "application/json" otherwise)
+	ContentType string `json:"contentType,omitempty"`
+
+	// Headers: request headers for get/post tasks and the upgrade request of websocket tasks
+	// (e.g. Authorization, Accept). A User-Agent header replaces the default browser User-Agent.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// HttpAuth: credentials for get/post and websocket tasks, read from a Secret at execution time
+	HttpAuth *HttpAuth `json:"httpAuth,omitempty"`
+
+	// GraphQL: the operation posted by graphql tasks to the input URL
+	GraphQL *GraphQLRequest `json:"graphql,omitempty"`
+
+	// Grpc: the call made by grpc tasks (default: the standard health check)
+	Grpc *GrpcCall `json:"grpc,omitempty"`
+
+	// Sql: database connection of sql tasks
+	Sql *SqlQuery `json:"sql,omitempty"`
+
+	// Kafka: canary message of kafka tasks
+	Kafka *KafkaCheck `json:"kafka,omitempty"`
+
+	// Exec: pod and container of kubectl-exec tasks
+	Exec *PodExec `json:"exec,omitempty"`
+
+	// Sftp: credentials and operation of sftp tasks
+	Sftp *FileTransfer `json:"sftp,omitempty"`
+
+	// Smtp: handshake and test message of smtp tasks (default: greeting and EHLO only)
+	Smtp *SmtpCheck `json:"smtp,omitempty"`
+
+	// WebSocket: message exchanged by websocket tasks (default: only the upgrade is checked)
+	WebSocket *WebSocketCheck `json:"websocket,omitempty"`
+
+	// HttpRetry: retries 429/5xx responses and connection errors within one execution,
+	// separately from retryCount which re-runs the whole task
+	HttpRetry *HttpRetry `json:"httpRetry,omitempty"`
+
+	// TLS: client certificate and CA bundle for get/post tasks, overriding the controller defaults
+	TLS *TLSConfig `json:"tls,omitempty"`
+
 	// Name identifier for this task
 	Name string `json:"name,omitempty"`
 
-	// Timeout in seconds
+	// Timeout in seconds for each execution (defaults to the controller TASK_TIMEOUT)
 	Timeout int32 `json:"timeout,omitempty"`
 
 	// Number of retries on failure
 	RetryCount int32 `json:"retryCount,omitempty"`
 
+	// Backoff between retries (default: 5s doubling up to 5m with 10% jitter)
+	RetryBackoff *RetryBackoff `json:"retryBackoff,omitempty"`
+
 	// Cron schedule for recurring tasks (optional)
 	Schedule string `json:"schedule,omitempty"`
 
 	// List of task names this task depends on
 	Dependencies []string `json:"dependencies,omitempty"`
 
-	// Environment variables for task execution
+	// Environment variables for task execution, including the values of InputSources. They are
+	// exported to cmd inputs and scripts, and ${NAME} in the input is replaced by their value
+	// (except in sql queries).
 	Environment map[string]string `json:"environment,omitempty"`
 
+	// SecretRefs: environment variables read from Secrets at each execution, like environment
+	// but never stored in the spec; their values are masked in the output, status and logs
+	SecretRefs []SecretEnvRef `json:"secretRefs,omitempty"`
+
 	// Resource requirements for task execution
 	Resources v1.ResourceRequirements `json:"resources,omitempty"`
 
@@ -51,6 +118,281 @@ type McallTaskSpec struct {
 
 	// InputTemplate: template string with variable substitution
 	InputTemplate string `json:"inputTemplate,omitempty"`
+
+	// ConcurrencyKey: at most one task with the same key runs at a time cluster-wide,
+	// other tasks with the key wait in queue (e.g. "restart-payment-service")
+	ConcurrencyKey string `json:"concurrencyKey,omitempty"`
+
+	// WaitFor: wait until a Kubernetes resource reaches a state before running
+	// (e.g. Deployment Available, Certificate Ready)
+	WaitFor *ResourceCondition `json:"waitFor,omitempty"`
+
+	// WarmUp: when this template is created or changed, run it once in a sandbox namespace
+	// and record a Validated condition; workflows don't use it until the warm-up passed
+	WarmUp *WarmUp `json:"warmUp,omitempty"`
+}
+
+// WarmUp runs a copy of a template task in a sandbox namespace to validate it
+type WarmUp struct {
+	// Namespace: sandbox namespace the warm-up copy runs in
+	Namespace string `json:"namespace"`
+
+	// Input: overrides the task input for the warm-up (e.g. a staging URL or dry-run command)
+	Input string `json:"input,omitempty"`
+
+	// Environment: overrides merged into the task environment for the warm-up
+	Environment map[string]string `json:"environment,omitempty"`
+}
+
+// RetryBackoff defines the exponential backoff between task retries
+type RetryBackoff struct {
+	// InitialDelaySeconds: delay before the first retry, doubled for each further retry (default: 5)
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
+
+	// MaxDelaySeconds: upper bound of the delay (default: 300)
+	MaxDelaySeconds int32 `json:"maxDelaySeconds,omitempty"`
+
+	// JitterPercent: random +/- spread applied to each delay so retries don't align (default: 10)
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	JitterPercent *int32 `json:"jitterPercent,omitempty"`
+}
+
+// GraphQLRequest is the operation of a graphql task. The task fails when the
+// response has a non-empty errors array; its output is the response body, so
+// inputSources can extract fields with JSONPath, e.g. "$.data.user.id".
+type GraphQLRequest struct {
+	// Query: the GraphQL document
+	Query string `json:"query"`
+
+	// Variables: JSON object of the operation variables
+	Variables string `json:"variables,omitempty"`
+
+	// OperationName: operation to run when the document has several
+	OperationName string `json:"operationName,omitempty"`
+}
+
+// GrpcCall describes the call of a grpc task. Without a method the standard
+// health check (grpc.health.v1.Health/Check) is called.
+type GrpcCall struct {
+	// Method: full name of a unary method, e.g. "helloworld.Greeter/SayHello".
+	// The server must expose gRPC reflection so the request can be built from JSON.
+	Method string `json:"method,omitempty"`
+
+	// Request: JSON request message of the method (default: {})
+	Request string `json:"request,omitempty"`
+
+	// Service: service name checked by the health check ("" checks the whole server)
+	Service string `json:"service,omitempty"`
+
+	// TLS: connect with TLS, using the spec.tls settings; plaintext otherwise
+	TLS bool `json:"tls,omitempty"`
+}
+
+// KafkaCheck configures the canary message a kafka task produces to check the brokers
+type KafkaCheck struct {
+	// Topic the canary message is produced to (it must exist)
+	Topic string `json:"topic"`
+
+	// Partition of the topic (default: 0)
+	Partition int32 `json:"partition,omitempty"`
+
+	// Consume: read the canary message back from the partition leader before the timeout
+	Consume bool `json:"consume,omitempty"`
+}
+
+// PodExec selects the pod a kubectl-exec task runs its command in. The command runs
+// with /bin/sh -c through the pods/exec API, so the container must have a shell.
+type PodExec struct {
+	// Namespace of the pod (default: the task namespace)
+	Namespace string `json:"namespace,omitempty"`
+
+	// Selector: label selector of the pod, e.g. "app=api"; the first running pod by name is used
+	Selector string `json:"selector"`
+
+	// Container name (default: the first container of the pod)
+	Container string `json:"container,omitempty"`
+}
+
+// FileTransfer configures an sftp task. Its output is JSON: {"path": ..., "files": [...]}
+// for list, {"path": ..., "size": n} for download and upload.
+type FileTransfer struct {
+	// Name of the Secret holding "username" and "password" or "privateKey" (sftp only)
+	SecretName string `json:"secretName"`
+
+	// Operation: list the input directory (default), download a file, or upload a probe
+	// file, read it back and remove it
+	// +kubebuilder:validation:Enum=list;download;upload
+	Operation string `json:"operation,omitempty"`
+
+	// File: name in the input directory that must be listed, or the file downloaded or
+	// uploaded (default for download: the input path, for upload: ".mcall-probe-<time>")
+	File string `json:"file,omitempty"`
+
+	// MinSize: minimum size in bytes of the listed or downloaded file
+	MinSize int64 `json:"minSize,omitempty"`
+
+	// HostKey: expected SSH host key in authorized_keys format, e.g. "ssh-ed25519 AAAA..." (sftp only)
+	HostKey string `json:"hostKey,omitempty"`
+
+	// InsecureIgnoreHostKey: accept any SSH host key when no hostKey is set
+	InsecureIgnoreHostKey bool `json:"insecureIgnoreHostKey,omitempty"`
+}
+
+// SecretEnvRef sets an environment variable from a key of a Secret in the task namespace,
+// of a Vault secret, or of an AWS secret or parameter
+type SecretEnvRef struct {
+	// Name of the environment variable
+	Name string `json:"name"`
+
+	// SecretName: name of the Secret; exactly one of secretName, vault and aws is set
+	SecretName string `json:"secretName,omitempty"`
+
+	// Vault: read the value from a Vault secret instead of a Kubernetes Secret
+	Vault *VaultSecretRef `json:"vault,omitempty"`
+
+	// AWS: read the value from AWS Secrets Manager or SSM Parameter Store
+	AWS *AWSSecretRef `json:"aws,omitempty"`
+
+	// Key of the value in the Secret; for aws, the key of a JSON object value,
+	// empty for the whole value
+	Key string `json:"key,omitempty"`
+
+	// DefaultValue: used when the Secret or key doesn't exist; without it the task fails
+	DefaultValue string `json:"defaultValue,omitempty"`
+}
+
+// VaultSecretRef selects a Vault secret, read with the controller's Vault kubernetes auth
+type VaultSecretRef struct {
+	// Path of the secret under the Vault API, e.g. "secret/data/payments" for a KV v2 mount
+	Path string `json:"path"`
+
+	// Role: Vault kubernetes auth role to log in with (default: the controller's VAULT_ROLE)
+	Role string `json:"role,omitempty"`
+}
+
+// AWSSecretRef selects an AWS Secrets Manager secret or SSM parameter, read with the
+// controller's IRSA role
+type AWSSecretRef struct {
+	// SecretID: name or ARN of a Secrets Manager secret; exactly one of secretId and parameter is set
+	SecretID string `json:"secretId,omitempty"`
+
+	// Parameter: name of an SSM parameter; SecureString parameters are decrypted
+	Parameter string `json:"parameter,omitempty"`
+
+	// Region of the secret or parameter (default: the controller's AWS_REGION)
+	Region string `json:"region,omitempty"`
+}
+
+// ScriptRef selects a script stored in a ConfigMap in the task namespace. The script is
+// read at execution time and run with the interpreter of its #! line, spec.shell otherwise.
+type ScriptRef struct {
+	// Name of the ConfigMap
+	Name string `json:"name"`
+
+	// Key of the script in the ConfigMap, e.g. "check.sh"
+	Key string `json:"key"`
+}
+
+// SmtpCheck configures an smtp task. The task reads the 220 greeting, sends EHLO and,
+// when configured, STARTTLS, AUTH PLAIN and a test message; any unexpected reply code
+// fails it. Its output is JSON: {"banner": ..., "extensions": [...], "tls": ..., "sent": ...}.
+type SmtpCheck struct {
+	// Banner: text the 220 greeting must contain, e.g. "ESMTP Postfix"
+	Banner string `json:"banner,omitempty"`
+
+	// Hello: host name sent with EHLO (default: "mcall")
+	Hello string `json:"hello,omitempty"`
+
+	// StartTLS: upgrade the connection with STARTTLS using the spec.tls settings; the task
+	// fails when the server doesn't offer it
+	StartTLS bool `json:"startTLS,omitempty"`
+
+	// SecretName: Secret holding "username" and "password" for AUTH PLAIN, which requires startTLS
+	SecretName string `json:"secretName,omitempty"`
+
+	// From: envelope sender of the test message
+	From string `json:"from,omitempty"`
+
+	// To: recipients of the test message; no message is sent when empty
+	To []string `json:"to,omitempty"`
+
+	// Subject of the test message (default: "mcall smtp check")
+	Subject string `json:"subject,omitempty"`
+}
+
+// WebSocketCheck configures a websocket task. Without a message or readResponse the
+// task succeeds once the upgrade completes; otherwise the first message received from
+// the server is the task output, for expect and outputValidation.
+type WebSocketCheck struct {
+	// Message: text message sent after the upgrade; the task then waits for the response
+	Message string `json:"message,omitempty"`
+
+	// ReadResponse: wait for the first message from the server even when nothing is sent
+	ReadResponse bool `json:"readResponse,omitempty"`
+
+	// Subprotocol requested with Sec-WebSocket-Protocol, e.g. "graphql-transport-ws"
+	Subprotocol string `json:"subprotocol,omitempty"`
+}
+
+// SqlQuery configures the database connection of a sql task. The query runs in a
+// read-only transaction and its rows are returned as JSON: {"rowCount": n, "rows": [...]}.
+type SqlQuery struct {
+	// Driver: postgres or mysql
+	// +kubebuilder:validation:Enum=postgres;mysql
+	Driver string `json:"driver"`
+
+	// Name of the Secret holding the data source name (in the task namespace)
+	SecretName string `json:"secretName"`
+
+	// Key of the data source name in the Secret (default: "dsn")
+	Key string `json:"key,omitempty"`
+
+	// MaxRows: rows included in the output, rowCount still counts all rows (default: 100)
+	MaxRows int32 `json:"maxRows,omitempty"`
+}
+
+// HttpRetry configures retries of transient HTTP failures inside a single execution
+type HttpRetry struct {
+	// MaxAttempts: total number of requests including the first one (default: 3)
+	// +kubebuilder:validation:Minimum=1
+	MaxAttempts int32 `json:"maxAttempts,omitempty"`
+
+	// InitialDelayMs: delay before the second request, doubled for each further request (default: 200)
+	InitialDelayMs int32 `json:"initialDelayMs,omitempty"`
+
+	// MaxDelayMs: upper bound of the delay (default: 5000)
+	MaxDelayMs int32 `json:"maxDelayMs,omitempty"`
+}
+
+// ResourceCondition waits for a Kubernetes resource to reach a state
+type ResourceCondition struct {
+	// APIVersion of the resource (e.g. "apps/v1", "cert-manager.io/v1")
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the resource (e.g. "Deployment", "Certificate")
+	Kind string `json:"kind"`
+
+	// Name of the resource (either name or selector is required)
+	Name string `json:"name,omitempty"`
+
+	// Namespace of the resource (defaults to the task namespace)
+	Namespace string `json:"namespace,omitempty"`
+
+	// Selector: label selector for the resources, all matching resources must reach the state
+	// Example: "app=payment"
+	Selector string `json:"selector,omitempty"`
+
+	// JSONPath: value to check on the resource, in kubectl jsonpath syntax
+	// Example: '{.status.conditions[?(@.type=="Available")].status}'
+	JSONPath string `json:"jsonPath"`
+
+	// Value: expected JSONPath result (default: "True")
+	Value string `json:"value,omitempty"`
+
+	// TimeoutSeconds: fail the task if the state is not reached within this time
+	// after the run started waiting (default: wait indefinitely)
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
 }
 
 // TaskInputSource represents a reference to another task's result
@@ -58,9 +400,18 @@ type TaskInputSource struct {
 	// Name: variable name for template substitution or environment variable
 	Name string `json:"name"`
 
-	// TaskRef: name of the task to reference
+	// TaskRef: name of the task to reference. In a workflow it names a task of the same
+	// workflow unless raw or namespace is set.
 	TaskRef string `json:"taskRef"`
 
+	// Namespace: namespace of the referenced McallTask (default: the namespace of the task).
+	// Setting it references a McallTask outside the workflow, as with raw.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Raw: taskRef is the name of a McallTask outside the workflow, e.g. a task of another
+	// workflow ("<workflow>-<task>"), used as is instead of naming a task of this workflow
+	Raw bool `json:"raw,omitempty"`
+
 	// Field: which field to extract from task result
 	// - "output": task execution output
 	// - "errorCode": execution result code ("0" or "-1")
@@ -70,9 +421,14 @@ type TaskInputSource struct {
 	Field string `json:"field"`
 
 	// JSONPath: extract specific field from JSON output (optional)
-	// Example: "$.data.status", "$.items[0].name"
+	// Example: "$.data.status", "$.items[0].name", "$.items[?(@.name=='x')].id", "$..id".
+	// Paths with wildcards, filters or recursive descent extract the JSON array of their matches.
 	JSONPath string `json:"jsonPath,omitempty"`
 
+	// JQ: jq program reshaping the JSON output, after jsonPath when both are set (optional)
+	// Example: `[.items[].name] | join(",")`
+	JQ string `json:"jq,omitempty"`
+
 	// Default: default value if field not found or task failed
 	Default string `json:"default,omitempty"`
 }
@@ -102,18 +458,73 @@ type McallTaskStatus struct {
 
 	// Last retry attempt time
 	LastRetryTime *metav1.Time `json:"lastRetryTime,omitempty"`
+
+	// Time the next retry is due (set while waiting in Pending after a failed attempt)
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+
+	// Time the current run started waiting for the waitFor condition (cleared when the run starts or times out)
+	WaitStartTime *metav1.Time `json:"waitStartTime,omitempty"`
+
+	// Position in the concurrency key queue while waiting for another task to finish (0 when not queued)
+	QueuePosition int32 `json:"queuePosition,omitempty"`
+
+	// Name of the McallTaskRun recording the last execution
+	LastTaskRun string `json:"lastTaskRun,omitempty"`
+
+	// Generation of the spec the status was last updated for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions of the task (Ready: the last execution completed without failing, Succeeded:
+	// result of the last execution, Validated: warm-up result of the current spec generation)
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // McallTaskResult represents the result of task execution
 type McallTaskResult struct {
-	// Task output
+	// Task output, truncated at the controller's RESULT_OUTPUT_MAX_BYTES
 	Output string `json:"output,omitempty"`
 
+	// OutputTruncated is true when output holds only the beginning of the output
+	OutputTruncated bool `json:"outputTruncated,omitempty"`
+
+	// OutputBytes is the size of the full output when it was truncated or compressed
+	OutputBytes int `json:"outputBytes,omitempty"`
+
+	// OutputEncoding is "gzip" or "zstd" when output holds the compressed output, base64 encoded
+	OutputEncoding string `json:"outputEncoding,omitempty"`
+
+	// OutputConfigMap is the ConfigMap holding the full output under the "output" key,
+	// when the controller overflows truncated outputs to ConfigMaps
+	OutputConfigMap string `json:"outputConfigMap,omitempty"`
+
+	// OutputURL is the s3:// or gs:// URL of the full output in the artifact store
+	OutputURL string `json:"outputURL,omitempty"`
+
 	// Error code (0 for success, -1 for failure)
 	ErrorCode string `json:"errorCode,omitempty"`
 
 	// Error message if failed
 	ErrorMessage string `json:"errorMessage,omitempty"`
+
+	// Differences from the golden response (httpValidation.goldenResponse)
+	ResponseDiff []ResponseDiff `json:"responseDiff,omitempty"`
+
+	// Number of HTTP requests made by a get/post execution (more than one when httpRetry retried)
+	Attempts int32 `json:"attempts,omitempty"`
+}
+
+// ResponseDiff describes one difference between a JSON response and its golden document
+type ResponseDiff struct {
+	// Path of the differing field (dot-separated, array indexes as numbers)
+	Path string `json:"path"`
+
+	// Expected value from the golden document (empty if the field is unexpected)
+	Expected string `json:"expected,omitempty"`
+
+	// Actual value from the response (empty if the field is missing)
+	Actual string `json:"actual,omitempty"`
 }
 
 // McallTaskPhase represents the phase of a task
@@ -125,6 +536,8 @@ const (
 	McallTaskPhaseSucceeded McallTaskPhase = "Succeeded"
 	McallTaskPhaseFailed    McallTaskPhase = "Failed"
 	McallTaskPhaseSkipped   McallTaskPhase = "Skipped"
+	// McallTaskPhaseBlocked means the task type is disabled by the operator kill switch
+	McallTaskPhaseBlocked McallTaskPhase = "Blocked"
 )
 
 // Execution mode constants
@@ -135,15 +548,35 @@ const (
 
 const McallTaskFinalizer = "mcall.tz.io/finalizer"
 
+// ReadyCondition is the condition type set while a task or workflow isn't failing: its last
+// execution or run completed without failing
+const ReadyCondition = "Ready"
+
+// SucceededCondition is the condition type recording the result of the last execution of a
+// task or run of a workflow, Unknown while it is in progress
+const SucceededCondition = "Succeeded"
+
+// ValidatedCondition is the condition type recording the warm-up result of a template task
+const ValidatedCondition = "Validated"
+
+// DegradedCondition is the condition type set when the last response exceeded maxLatencyMs
+const DegradedCondition = "Degraded"
+
+// PausedAnnotation pauses a task: "true" keeps it in Pending until the annotation is removed,
+// "skip" marks it Skipped instead of executing it
+const PausedAnnotation = "mcall.tz.io/paused"
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",priority=1
 //+kubebuilder:printcolumn:name="Type",type="string",JSONPath=".spec.type"
 //+kubebuilder:printcolumn:name="Input",type="string",JSONPath=".spec.input"
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 
 // McallTask is the Schema for the mcalltasks API
 type McallTask struct {
@@ -181,17 +614,80 @@ type HttpValidation struct {
 	// Regex pattern for response body matching
 	ResponseBodyPattern string `json:"responseBodyPattern,omitempty"`
 
-	// Expected response headers
+	// Expected response headers; each header must contain the given value ("" only checks presence)
 	ResponseHeaders map[string]string `json:"responseHeaders,omitempty"`
 
-	// HTTP response timeout in seconds
+	// HTTP response timeout in seconds (overrides the task timeout for the request)
 	ResponseTimeout int32 `json:"responseTimeout,omitempty"`
 
-	// Whether to follow redirects
+	// Whether to follow redirects. When false the redirect response itself is validated.
 	FollowRedirects bool `json:"followRedirects,omitempty"`
 
-	// Maximum number of redirects to follow
+	// Maximum number of redirects to follow (default: 10)
 	MaxRedirects int32 `json:"maxRedirects,omitempty"`
+
+	// Compare the JSON response against a golden document (contract check)
+	GoldenResponse *GoldenResponse `json:"goldenResponse,omitempty"`
+
+	// Additional rules the response body must satisfy (all must pass)
+	Assertions []Assertion `json:"assertions,omitempty"`
+
+	// Maximum response time in milliseconds. A slower response fails the check even with
+	// an expected status code, or only sets the Degraded condition (see latencyAction).
+	MaxLatencyMs int64 `json:"maxLatencyMs,omitempty"`
+
+	// What a response slower than maxLatencyMs does: "fail" (default) or "degrade"
+	// +kubebuilder:validation:Enum=fail;degrade
+	LatencyAction string `json:"latencyAction,omitempty"`
+}
+
+// HttpAuth authenticates HTTP requests with credentials stored in a Secret
+type HttpAuth struct {
+	// Authentication type: basic, bearer or apiKey
+	// +kubebuilder:validation:Enum=basic;bearer;apiKey
+	Type string `json:"type"`
+
+	// Name of the Secret holding the credentials (in the task namespace)
+	SecretName string `json:"secretName"`
+
+	// Key of the username in the Secret, for basic auth (default: "username")
+	UsernameKey string `json:"usernameKey,omitempty"`
+
+	// Key of the password, token or API key in the Secret
+	// (default: "password" for basic, "token" for bearer, "apiKey" for apiKey)
+	Key string `json:"key,omitempty"`
+
+	// Header carrying the API key, for apiKey auth (default: "X-API-Key")
+	HeaderName string `json:"headerName,omitempty"`
+}
+
+// TLSConfig defines the TLS settings used to call services with a private PKI
+type TLSConfig struct {
+	// Name of a kubernetes.io/tls Secret holding the client certificate (tls.crt and tls.key),
+	// in the task namespace
+	ClientCertSecretName string `json:"clientCertSecretName,omitempty"`
+
+	// Name of a ConfigMap holding the CA bundle used to verify the server, in the task namespace
+	CABundleConfigMapName string `json:"caBundleConfigMapName,omitempty"`
+
+	// Key of the CA bundle in the ConfigMap (default: "ca.crt")
+	CABundleKey string `json:"caBundleKey,omitempty"`
+
+	// Skip verification of the server certificate. Only use this for testing.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// GoldenResponse compares a JSON response against a golden document stored in a ConfigMap
+type GoldenResponse struct {
+	// Name of the ConfigMap holding the golden document (in the task namespace)
+	ConfigMapName string `json:"configMapName"`
+
+	// Key of the golden document in the ConfigMap (default: "response.json")
+	Key string `json:"key,omitempty"`
+
+	// Fields ignored in the comparison, as dot-separated paths; "*" matches any key or array index
+	// Example: ["meta.timestamp", "items.*.id"]
+	IgnoreFields []string `json:"ignoreFields,omitempty"`
 }
 
 // OutputValidation defines command output validation rules
@@ -231,4 +727,29 @@ type OutputValidation struct {
 
 	// Expected output content that indicates failure
 	ExpectedFailureOutput string `json:"expectedFailureOutput,omitempty"`
+
+	// Additional rules the output must satisfy (all must pass)
+	Assertions []Assertion `json:"assertions,omitempty"`
+}
+
+// Assertion is a matching rule evaluated by the shared assertion engine
+type Assertion struct {
+	// Operator: contains, notContains, equals, notEquals, regex, exists, gt, gte, lt, lte, cel
+	// +kubebuilder:validation:Enum=contains;notContains;equals;notEquals;regex;exists;gt;gte;lt;lte;cel
+	Operator string `json:"operator"`
+
+	// Path: JSONPath selecting the value to check from JSON content (optional)
+	// Example: "$.data.status", "$.items[0].name", "$.items[?(@.state=='down')]"
+	Path string `json:"path,omitempty"`
+
+	// XPath: XPath selecting the value to check from XML content, e.g. SOAP responses (optional)
+	// Example: "//GetStatusResponse/status", "/Envelope/Body/Fault/faultcode"
+	XPath string `json:"xpath,omitempty"`
+
+	// Value: expected value, regex pattern, number or CEL expression
+	// (CEL can use content, value and json, e.g. "json.items.size() > 0")
+	Value string `json:"value,omitempty"`
+
+	// IgnoreCase: compare strings case-insensitively
+	IgnoreCase bool `json:"ignoreCase,omitempty"`
 }
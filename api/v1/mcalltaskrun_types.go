@@ -0,0 +1,73 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// McallTaskRunSpec defines the desired state of McallTaskRun
+type McallTaskRunSpec struct {
+	// TaskRef is the name of the McallTask that was executed
+	TaskRef string `json:"taskRef"`
+
+	// Type is the task type at execution time (cmd, get, post)
+	Type string `json:"type,omitempty"`
+
+	// Input is the executed input (after template substitution)
+	Input string `json:"input,omitempty"`
+
+	// WorkflowRunRef is the McallWorkflowRun that triggered this execution (empty for standalone tasks)
+	WorkflowRunRef string `json:"workflowRunRef,omitempty"`
+}
+
+// McallTaskRunStatus defines the observed state of McallTaskRun
+type McallTaskRunStatus struct {
+	// Phase is the final phase of the execution
+	Phase McallTaskPhase `json:"phase,omitempty"`
+
+	// StartTime is the time when the execution started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time when the execution completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// ExecutionTimeMs is the execution time in milliseconds
+	ExecutionTimeMs int64 `json:"executionTimeMs,omitempty"`
+
+	// HTTPStatusCode is the HTTP response status code (for HTTP requests)
+	HTTPStatusCode int `json:"httpStatusCode,omitempty"`
+
+	// Result is the execution result (output, errorCode, errorMessage)
+	Result *McallTaskResult `json:"result,omitempty"`
+}
+
+// McallTaskRun is the Schema for the mcalltaskruns API.
+// A run is created for every execution of a McallTask, so repeated executions
+// keep their own output and timings instead of overwriting the task status.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:printcolumn:name="Task",type="string",JSONPath=".spec.taskRef"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Start Time",type="date",JSONPath=".status.startTime"
+// +kubebuilder:printcolumn:name="Duration(ms)",type="integer",JSONPath=".status.executionTimeMs"
+// +kubebuilder:printcolumn:name="Workflow Run",type="string",JSONPath=".spec.workflowRunRef",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type McallTaskRun struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   McallTaskRunSpec   `json:"spec,omitempty"`
+	Status McallTaskRunStatus `json:"status,omitempty"`
+}
+
+// McallTaskRunList contains a list of McallTaskRun
+// +kubebuilder:object:root=true
+type McallTaskRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []McallTaskRun `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&McallTaskRun{}, &McallTaskRunList{})
+}
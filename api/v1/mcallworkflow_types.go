@@ -20,14 +20,43 @@ type McallWorkflowSpec struct {
 	// Tasks is the list of McallTask references in this workflow
 	Tasks []WorkflowTaskRef `json:"tasks"`
 
+	// OnExit tasks run once all tasks of a run completed, whether it succeeded or failed, e.g.
+	// for cleanup and notifications. They can depend on each other and reference the tasks of
+	// the run; an input source with taskRef "workflow" receives the outcome of the run. A
+	// failed exit task fails the run.
+	OnExit []WorkflowTaskRef `json:"onExit,omitempty"`
+
 	// Schedule is the cron schedule for workflow execution (optional)
 	// Format: "minute hour day month weekday"
 	// Example: "0 2 * * *" (every day at 2 AM)
+	// Also accepts names ("Mon-Fri", "Jan"), ranges with steps ("0-30/10")
+	// and descriptors ("@hourly", "@daily", "@every 15m")
 	Schedule string `json:"schedule,omitempty"`
 
+	// StartingDeadlineSeconds is the deadline in seconds for starting a scheduled run
+	// that was missed (e.g. while the controller was down). Missed runs older than
+	// the deadline are skipped and the workflow waits for the next schedule window.
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
+	// Suspend stops the workflow from starting new runs; a run in progress is not affected
+	Suspend bool `json:"suspend,omitempty"`
+
+	// BackfillOnResume starts the latest run missed while suspended right after resuming.
+	// By default missed runs are skipped and the workflow waits for the next schedule time.
+	BackfillOnResume bool `json:"backfillOnResume,omitempty"`
+
 	// Concurrency is the maximum number of concurrent task executions
 	Concurrency int32 `json:"concurrency,omitempty"`
 
+	// StaggerSeconds spaces out task starts within a run: a task starts at least this many
+	// seconds after the previous task of the workflow started (default: 0, no spacing).
+	// Dependencies and conditions are still honored, stagger only delays admission.
+	StaggerSeconds int32 `json:"staggerSeconds,omitempty"`
+
+	// FailFast skips the tasks that haven't started yet once a task of the run failed. Running
+	// tasks complete, onFailure handlers and exit tasks still run.
+	FailFast bool `json:"failFast,omitempty"`
+
 	// Timeout is the overall workflow timeout in seconds
 	Timeout int32 `json:"timeout,omitempty"`
 
@@ -37,8 +66,33 @@ type McallWorkflowSpec struct {
 	// Environment variables for all tasks in the workflow
 	Environment map[string]string `json:"environment,omitempty"`
 
+	// Parameters of the workflow, substituted as ${params.<name>} into the input, inputTemplate,
+	// body and headers of its task instances. The mcall.tz.io/parameters annotation sets their
+	// values for the next runs, the mcall.tz.io/trigger annotation for a single run.
+	Parameters []WorkflowParameter `json:"parameters,omitempty"`
+
 	// Resources defines resource requirements for all tasks
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// SuccessfulRunsHistoryLimit is the number of successful scheduled runs whose
+	// task instances are kept for inspection (default: 0, deleted after each run)
+	SuccessfulRunsHistoryLimit *int32 `json:"successfulRunsHistoryLimit,omitempty"`
+
+	// FailedRunsHistoryLimit is the number of failed scheduled runs whose
+	// task instances are kept for inspection (default: 0, deleted after each run)
+	FailedRunsHistoryLimit *int32 `json:"failedRunsHistoryLimit,omitempty"`
+}
+
+// WorkflowParameter declares a parameter of a workflow
+type WorkflowParameter struct {
+	// Name of the parameter
+	Name string `json:"name"`
+
+	// Default value, used when the parameters annotation doesn't set one
+	Default string `json:"default,omitempty"`
+
+	// Description of the parameter
+	Description string `json:"description,omitempty"`
 }
 
 // WorkflowTaskRef represents a reference to a McallTask in a workflow
@@ -55,11 +109,43 @@ type WorkflowTaskRef struct {
 	// Condition defines when this task should run
 	Condition *TaskCondition `json:"condition,omitempty"`
 
+	// Conditions combines conditions over several dependent tasks, e.g. run if A succeeded and
+	// B failed. The task runs when Condition, all AllOf and at least one AnyOf conditions are met.
+	Conditions *TaskConditions `json:"conditions,omitempty"`
+
 	// InputSources defines data to pass from other tasks
 	InputSources []TaskInputSource `json:"inputSources,omitempty"`
 
 	// InputTemplate for variable substitution
 	InputTemplate string `json:"inputTemplate,omitempty"`
+
+	// WithItems expands the task into one instance per item, run in parallel. ${item} in the
+	// input, input template, body and headers of an instance is replaced with its item. The
+	// task succeeds once all instances succeeded, its output is the JSON array of their outputs.
+	WithItems []string `json:"withItems,omitempty"`
+
+	// WithParam expands the task like WithItems, over the elements of the JSON array output of
+	// a task of the workflow once it succeeded
+	WithParam *TaskParamSource `json:"withParam,omitempty"`
+
+	// OnFailure references a McallTask run as soon as this task fails, independently of the
+	// other tasks, e.g. to collect diagnostics. Its instance "<name>-on-failure" is skipped when
+	// the task doesn't fail; the run completes once it completed.
+	OnFailure *TaskRef `json:"onFailure,omitempty"`
+
+	// ContinueOn "failure" tolerates the failure of an optional or flaky task: the run isn't
+	// marked Failed for it and counts it in toleratedFailures
+	// +kubebuilder:validation:Enum=failure
+	ContinueOn string `json:"continueOn,omitempty"`
+}
+
+// TaskParamSource references the JSON array output of a workflow task
+type TaskParamSource struct {
+	// TaskRef: name of the task in the workflow
+	TaskRef string `json:"taskRef"`
+
+	// JSONPath: path of the array in the output, e.g. "$.items[*].name" (default: the whole output)
+	JSONPath string `json:"jsonPath,omitempty"`
 }
 
 // TaskCondition defines execution conditions for a task
@@ -79,6 +165,24 @@ type TaskCondition struct {
 
 	// OutputContains: run if output contains specific string
 	OutputContains string `json:"outputContains,omitempty"`
+
+	// Assertions: run if the dependent task output satisfies all rules
+	Assertions []Assertion `json:"assertions,omitempty"`
+
+	// CELExpression: run if the CEL expression evaluates to true. It can use result (output,
+	// errorCode, errorMessage and phase of the dependent task), output (the output parsed as
+	// JSON, null otherwise) and params (the workflow environment and parameters), e.g.
+	// "result.errorCode == '0' && double(output.latency) < 200"
+	CELExpression string `json:"celExpression,omitempty"`
+}
+
+// TaskConditions combines task conditions
+type TaskConditions struct {
+	// AllOf: run if all conditions are met
+	AllOf []TaskCondition `json:"allOf,omitempty"`
+
+	// AnyOf: run if at least one condition is met
+	AnyOf []TaskCondition `json:"anyOf,omitempty"`
 }
 
 // FieldCondition defines a field-based condition
@@ -90,6 +194,93 @@ type FieldCondition struct {
 	Value string `json:"value"`
 }
 
+// FailureInjectionAnnotation holds failure injections for chaos testing.
+// On a McallWorkflow it is a JSON list of FailureInjection entries; on a McallTask a single entry.
+// Injections are only honored when the operator runs with FAILURE_INJECTION_ENABLED=true.
+const FailureInjectionAnnotation = "mcall.tz.io/failure-injection"
+
+// NotifyAnnotation selects the notifiers of the failures of a McallWorkflow or McallTask,
+// overriding the operator's: comma-separated types, e.g. "teams,discord", or "none".
+// Workflow annotations are copied to their task instances.
+const NotifyAnnotation = "mcall.tz.io/notify"
+
+// NotifySecretAnnotation names a Secret in the namespace of a McallWorkflow or McallTask whose
+// "slack", "teams" and "discord" keys override the webhook URLs of the notifiers
+const NotifySecretAnnotation = "mcall.tz.io/notify-secret"
+
+// TraceParentAnnotation holds the W3C traceparent of the workflow run that created a task
+// instance, so the spans of its executions join the trace of the run
+const TraceParentAnnotation = "mcall.tz.io/traceparent"
+
+// ParametersAnnotation holds the parameter values of the next runs of a McallWorkflow as a JSON
+// object, e.g. {"region": "eu"}. Parameters it doesn't set use their default.
+const ParametersAnnotation = "mcall.tz.io/parameters"
+
+// TriggerAnnotation starts a run of a McallWorkflow, right away or once the current run
+// completes. Its value is a JSON object of parameter values overriding the parameters
+// annotation for that run only, e.g. {"region": "eu"}, or empty. The controller removes it
+// when the run starts.
+const TriggerAnnotation = "mcall.tz.io/trigger"
+
+// WorkflowOutcomeTaskRef is the taskRef of the input sources of exit tasks receiving the outcome
+// of the run: its phase, an errorCode ("0" or "-1"), the failed tasks in errorMessage and the
+// JSON results of its tasks as output
+const WorkflowOutcomeTaskRef = "workflow"
+
+// WorkflowOutcomeAnnotation holds the outcome of the run on the exit task instances, as the
+// JSON status of a McallTask
+const WorkflowOutcomeAnnotation = "mcall.tz.io/workflow-outcome"
+
+// RetryFailedAnnotation requests the failed run of a McallWorkflow to be resumed: its failed,
+// blocked and skipped tasks run again while the results of its succeeded tasks are kept for
+// their input sources. Its value is ignored. The controller removes it when the run resumes.
+const RetryFailedAnnotation = "mcall.tz.io/retry-failed"
+
+// RestartAnnotation set to "true" restarts a McallWorkflow: the task instances of its current or
+// last run are deleted, a run in progress being recorded as Failed, and a new run starts right
+// away regardless of the schedule. The controller removes it when the run is restarted.
+const RestartAnnotation = "mcall.tz.io/restart"
+
+// FanOutAnnotation holds the FanOut of a workflow task instance expanded with withItems or
+// withParam as JSON. Once the task may start, the controller creates one instance per item
+// and completes the task with their aggregated results.
+const FanOutAnnotation = "mcall.tz.io/fan-out"
+
+// FanOut lists the items of an expanded task instance, or the task whose output lists them
+type FanOut struct {
+	// Items: the items of the instances
+	Items []string `json:"items,omitempty"`
+
+	// Param: the task whose JSON array output lists the items, by its instance name
+	Param *TaskParamSource `json:"param,omitempty"`
+}
+
+// SchedulingActiveCondition is the condition type set on scheduled workflows while their runs
+// are scheduled, False while suspended
+const SchedulingActiveCondition = "SchedulingActive"
+
+// Failure injection actions
+const (
+	FailureInjectionFail    = "fail"
+	FailureInjectionDelay   = "delay"
+	FailureInjectionTimeout = "timeout"
+)
+
+// FailureInjection defines a failure to inject into a task execution
+type FailureInjection struct {
+	// Task is the name of the task in the workflow (ignored on McallTask annotations)
+	Task string `json:"task,omitempty"`
+
+	// Action: "fail", "delay" or "timeout"
+	Action string `json:"action"`
+
+	// DelaySeconds is how long to delay execution for the "delay" action
+	DelaySeconds int32 `json:"delaySeconds,omitempty"`
+
+	// Message is the error message reported for the "fail" action
+	Message string `json:"message,omitempty"`
+}
+
 // TaskRef represents a reference to a McallTask
 type TaskRef struct {
 	// Name is the name of the McallTask
@@ -122,9 +313,19 @@ type McallWorkflowStatus struct {
 	// CompletionTime is the time when the workflow completed
 	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 
+	// OnExitStartTime is the time when the exit tasks of the current or last run started
+	OnExitStartTime *metav1.Time `json:"onExitStartTime,omitempty"`
+
+	// ToleratedFailures is the number of tasks of the current or last run that failed with
+	// continueOn: failure, without failing the run
+	ToleratedFailures int32 `json:"toleratedFailures,omitempty"`
+
 	// TaskStatuses is the status of individual tasks
 	TaskStatuses []TaskStatus `json:"taskStatuses,omitempty"`
 
+	// Parameters are the effective parameter values of the current or last run
+	Parameters map[string]string `json:"parameters,omitempty"`
+
 	// Message is a human-readable message about the workflow status
 	Message string `json:"message,omitempty"`
 
@@ -137,11 +338,39 @@ type McallWorkflowStatus struct {
 	// LastRetryTime is the time of the last retry
 	LastRetryTime *metav1.Time `json:"lastRetryTime,omitempty"`
 
+	// RestartCount is the number of times the workflow was restarted with the restart annotation
+	RestartCount int32 `json:"restartCount,omitempty"`
+
+	// LastRestartTime is the time of the last restart
+	LastRestartTime *metav1.Time `json:"lastRestartTime,omitempty"`
+
 	// LastRunTime is the time when the workflow was last executed
 	LastRunTime *metav1.Time `json:"lastRunTime,omitempty"`
 
+	// NextRunTime is the next time a scheduled workflow is due to run
+	NextRunTime *metav1.Time `json:"nextRunTime,omitempty"`
+
+	// SuspendedTime is the time when the workflow was suspended (nil while not suspended)
+	SuspendedTime *metav1.Time `json:"suspendedTime,omitempty"`
+
+	// ResumedTime is the time when the workflow was last resumed without backfill.
+	// Schedule times before it are not run.
+	ResumedTime *metav1.Time `json:"resumedTime,omitempty"`
+
+	// LastRunDuration is the duration of the last completed run in human-readable format
+	LastRunDuration string `json:"lastRunDuration,omitempty"`
+
 	// DAG representation for UI visualization (current/last run)
 	DAG *WorkflowDAG `json:"dag,omitempty"`
+
+	// ObservedGeneration is the generation of the spec the status was last updated for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions of the workflow (Ready: the last run completed without failing and the spec
+	// was accepted, Succeeded: result of the last run, SchedulingActive: runs are scheduled)
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // TaskStatus represents the status of a single task in the workflow
@@ -169,9 +398,15 @@ type TaskStatus struct {
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",priority=1
 // +kubebuilder:printcolumn:name="Start Time",type="date",JSONPath=".status.startTime"
 // +kubebuilder:printcolumn:name="Completion Time",type="date",JSONPath=".status.completionTime"
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule",priority=1
+// +kubebuilder:printcolumn:name="Suspend",type="boolean",JSONPath=".spec.suspend",priority=1
+// +kubebuilder:printcolumn:name="Next Run",type="string",JSONPath=".status.nextRunTime",priority=1
+// +kubebuilder:printcolumn:name="Last Duration",type="string",JSONPath=".status.lastRunDuration",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type McallWorkflow struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -276,13 +511,14 @@ type DAGEdge struct {
 	// Target is the target node ID
 	Target string `json:"target"`
 
-	// Type is the edge type (dependency, success, failure, always)
+	// Type is the edge type (dependency, success, failure, always, dataflow)
+	// "dataflow" edges are derived from InputSources and show how data moves between tasks
 	Type string `json:"type,omitempty"`
 
 	// Condition is the execution condition
 	Condition string `json:"condition,omitempty"`
 
-	// Label for display
+	// Label for display (for dataflow edges: variable name and extracted field/JSONPath)
 	Label string `json:"label,omitempty"`
 }
 
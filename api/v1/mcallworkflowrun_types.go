@@ -0,0 +1,106 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// McallWorkflowRunSpec defines the desired state of McallWorkflowRun
+type McallWorkflowRunSpec struct {
+	// WorkflowRef is the name of the McallWorkflow this run was triggered from
+	WorkflowRef string `json:"workflowRef"`
+
+	// RunID is the unique identifier of this run within the workflow
+	RunID string `json:"runID"`
+
+	// Trigger is what started this run (schedule, manual)
+	Trigger string `json:"trigger,omitempty"`
+}
+
+// WorkflowRunTaskResult represents the result of a single task in a workflow run
+type WorkflowRunTaskResult struct {
+	// Name is the name of the task in the workflow
+	Name string `json:"name"`
+
+	// Phase is the final phase of the task
+	Phase McallTaskPhase `json:"phase,omitempty"`
+
+	// StartTime is the time when the task started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time when the task completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// ExecutionTimeMs is the task execution time in milliseconds
+	ExecutionTimeMs int64 `json:"executionTimeMs,omitempty"`
+
+	// HTTPStatusCode is the HTTP response status code for get/post tasks
+	HTTPStatusCode int `json:"httpStatusCode,omitempty"`
+
+	// ErrorCode is the execution result code ("0" or "-1")
+	ErrorCode string `json:"errorCode,omitempty"`
+
+	// ErrorMessage is the error message if the task failed
+	ErrorMessage string `json:"errorMessage,omitempty"`
+
+	// Output is the task output (truncated)
+	Output string `json:"output,omitempty"`
+
+	// Tolerated is set on tasks that failed with continueOn: failure, without failing the run
+	Tolerated bool `json:"tolerated,omitempty"`
+}
+
+// McallWorkflowRunStatus defines the observed state of McallWorkflowRun
+type McallWorkflowRunStatus struct {
+	// Phase is the current phase of the run
+	Phase McallWorkflowPhase `json:"phase,omitempty"`
+
+	// StartTime is the time when the run started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time when the run completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Duration is the run duration in human-readable format
+	Duration string `json:"duration,omitempty"`
+
+	// Parameters are the effective parameter values of this run, including trigger overrides
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// TaskResults is the result of each task in the run
+	TaskResults []WorkflowRunTaskResult `json:"taskResults,omitempty"`
+
+	// DAG is the snapshot of the workflow DAG at completion
+	DAG *WorkflowDAG `json:"dag,omitempty"`
+}
+
+// McallWorkflowRun is the Schema for the mcallworkflowruns API.
+// A run is created for every trigger of a McallWorkflow and records its results,
+// so the McallWorkflow itself stays a template whose status only tracks the current run.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:printcolumn:name="Workflow",type="string",JSONPath=".spec.workflowRef"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Start Time",type="date",JSONPath=".status.startTime"
+// +kubebuilder:printcolumn:name="Duration",type="string",JSONPath=".status.duration"
+// +kubebuilder:printcolumn:name="Trigger",type="string",JSONPath=".spec.trigger",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type McallWorkflowRun struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   McallWorkflowRunSpec   `json:"spec,omitempty"`
+	Status McallWorkflowRunStatus `json:"status,omitempty"`
+}
+
+// McallWorkflowRunList contains a list of McallWorkflowRun
+// +kubebuilder:object:root=true
+type McallWorkflowRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []McallWorkflowRun `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&McallWorkflowRun{}, &McallWorkflowRunList{})
+}
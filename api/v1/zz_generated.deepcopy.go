@@ -6,9 +6,60 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSSecretRef) DeepCopyInto(out *AWSSecretRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSSecretRef.
+func (in *AWSSecretRef) DeepCopy() *AWSSecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSSecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Assertion) DeepCopyInto(out *Assertion) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Assertion.
+func (in *Assertion) DeepCopy() *Assertion {
+	if in == nil {
+		return nil
+	}
+	out := new(Assertion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudWatchLoggingBackend) DeepCopyInto(out *CloudWatchLoggingBackend) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudWatchLoggingBackend.
+func (in *CloudWatchLoggingBackend) DeepCopy() *CloudWatchLoggingBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudWatchLoggingBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DAGEdge) DeepCopyInto(out *DAGEdge) {
 	*out = *in
@@ -67,6 +118,66 @@ func (in *DAGNode) DeepCopy() *DAGNode {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchLoggingBackend) DeepCopyInto(out *ElasticsearchLoggingBackend) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchLoggingBackend.
+func (in *ElasticsearchLoggingBackend) DeepCopy() *ElasticsearchLoggingBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchLoggingBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailureInjection) DeepCopyInto(out *FailureInjection) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailureInjection.
+func (in *FailureInjection) DeepCopy() *FailureInjection {
+	if in == nil {
+		return nil
+	}
+	out := new(FailureInjection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FanOut) DeepCopyInto(out *FanOut) {
+	*out = *in
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Param != nil {
+		in, out := &in.Param, &out.Param
+		*out = new(TaskParamSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FanOut.
+func (in *FanOut) DeepCopy() *FanOut {
+	if in == nil {
+		return nil
+	}
+	out := new(FanOut)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FieldCondition) DeepCopyInto(out *FieldCondition) {
 	*out = *in
@@ -82,6 +193,101 @@ func (in *FieldCondition) DeepCopy() *FieldCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileTransfer) DeepCopyInto(out *FileTransfer) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileTransfer.
+func (in *FileTransfer) DeepCopy() *FileTransfer {
+	if in == nil {
+		return nil
+	}
+	out := new(FileTransfer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoldenResponse) DeepCopyInto(out *GoldenResponse) {
+	*out = *in
+	if in.IgnoreFields != nil {
+		in, out := &in.IgnoreFields, &out.IgnoreFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoldenResponse.
+func (in *GoldenResponse) DeepCopy() *GoldenResponse {
+	if in == nil {
+		return nil
+	}
+	out := new(GoldenResponse)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraphQLRequest) DeepCopyInto(out *GraphQLRequest) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraphQLRequest.
+func (in *GraphQLRequest) DeepCopy() *GraphQLRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(GraphQLRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrpcCall) DeepCopyInto(out *GrpcCall) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrpcCall.
+func (in *GrpcCall) DeepCopy() *GrpcCall {
+	if in == nil {
+		return nil
+	}
+	out := new(GrpcCall)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HttpAuth) DeepCopyInto(out *HttpAuth) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpAuth.
+func (in *HttpAuth) DeepCopy() *HttpAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(HttpAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HttpRetry) DeepCopyInto(out *HttpRetry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpRetry.
+func (in *HttpRetry) DeepCopy() *HttpRetry {
+	if in == nil {
+		return nil
+	}
+	out := new(HttpRetry)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HttpValidation) DeepCopyInto(out *HttpValidation) {
 	*out = *in
@@ -97,6 +303,16 @@ func (in *HttpValidation) DeepCopyInto(out *HttpValidation) {
 			(*out)[key] = val
 		}
 	}
+	if in.GoldenResponse != nil {
+		in, out := &in.GoldenResponse, &out.GoldenResponse
+		*out = new(GoldenResponse)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Assertions != nil {
+		in, out := &in.Assertions, &out.Assertions
+		*out = make([]Assertion, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpValidation.
@@ -110,26 +326,85 @@ func (in *HttpValidation) DeepCopy() *HttpValidation {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *McallTask) DeepCopyInto(out *McallTask) {
+func (in *KafkaCheck) DeepCopyInto(out *KafkaCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaCheck.
+func (in *KafkaCheck) DeepCopy() *KafkaCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaLoggingBackend) DeepCopyInto(out *KafkaLoggingBackend) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Brokers != nil {
+		in, out := &in.Brokers, &out.Brokers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaLoggingBackend.
+func (in *KafkaLoggingBackend) DeepCopy() *KafkaLoggingBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaLoggingBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LokiLoggingBackend) DeepCopyInto(out *LokiLoggingBackend) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LokiLoggingBackend.
+func (in *LokiLoggingBackend) DeepCopy() *LokiLoggingBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(LokiLoggingBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *McallLoggingConfig) DeepCopyInto(out *McallLoggingConfig) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallTask.
-func (in *McallTask) DeepCopy() *McallTask {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallLoggingConfig.
+func (in *McallLoggingConfig) DeepCopy() *McallLoggingConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(McallTask)
+	out := new(McallLoggingConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *McallTask) DeepCopyObject() runtime.Object {
+func (in *McallLoggingConfig) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -137,31 +412,31 @@ func (in *McallTask) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *McallTaskList) DeepCopyInto(out *McallTaskList) {
+func (in *McallLoggingConfigList) DeepCopyInto(out *McallLoggingConfigList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]McallTask, len(*in))
+		*out = make([]McallLoggingConfig, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallTaskList.
-func (in *McallTaskList) DeepCopy() *McallTaskList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallLoggingConfigList.
+func (in *McallLoggingConfigList) DeepCopy() *McallLoggingConfigList {
 	if in == nil {
 		return nil
 	}
-	out := new(McallTaskList)
+	out := new(McallLoggingConfigList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *McallTaskList) DeepCopyObject() runtime.Object {
+func (in *McallLoggingConfigList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -169,262 +444,939 @@ func (in *McallTaskList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *McallTaskResult) DeepCopyInto(out *McallTaskResult) {
+func (in *McallLoggingConfigSpec) DeepCopyInto(out *McallLoggingConfigSpec) {
 	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PostgreSQL != nil {
+		in, out := &in.PostgreSQL, &out.PostgreSQL
+		*out = new(SQLLoggingBackend)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MySQL != nil {
+		in, out := &in.MySQL, &out.MySQL
+		*out = new(SQLLoggingBackend)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Elasticsearch != nil {
+		in, out := &in.Elasticsearch, &out.Elasticsearch
+		*out = new(ElasticsearchLoggingBackend)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Kafka != nil {
+		in, out := &in.Kafka, &out.Kafka
+		*out = new(KafkaLoggingBackend)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Loki != nil {
+		in, out := &in.Loki, &out.Loki
+		*out = new(LokiLoggingBackend)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CloudWatch != nil {
+		in, out := &in.CloudWatch, &out.CloudWatch
+		*out = new(CloudWatchLoggingBackend)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallTaskResult.
-func (in *McallTaskResult) DeepCopy() *McallTaskResult {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallLoggingConfigSpec.
+func (in *McallLoggingConfigSpec) DeepCopy() *McallLoggingConfigSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(McallTaskResult)
+	out := new(McallLoggingConfigSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *McallTaskSpec) DeepCopyInto(out *McallTaskSpec) {
+func (in *McallTask) DeepCopyInto(out *McallTask) {
 	*out = *in
-	if in.Dependencies != nil {
-		in, out := &in.Dependencies, &out.Dependencies
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallTask.
+func (in *McallTask) DeepCopy() *McallTask {
+	if in == nil {
+		return nil
 	}
-	if in.Environment != nil {
-		in, out := &in.Environment, &out.Environment
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	out := new(McallTask)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *McallTask) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
-	in.Resources.DeepCopyInto(&out.Resources)
-	if in.HttpValidation != nil {
-		in, out := &in.HttpValidation, &out.HttpValidation
-		*out = new(HttpValidation)
-		(*in).DeepCopyInto(*out)
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *McallTaskList) DeepCopyInto(out *McallTaskList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]McallTask, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallTaskList.
+func (in *McallTaskList) DeepCopy() *McallTaskList {
+	if in == nil {
+		return nil
+	}
+	out := new(McallTaskList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *McallTaskList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *McallTaskResult) DeepCopyInto(out *McallTaskResult) {
+	*out = *in
+	if in.ResponseDiff != nil {
+		in, out := &in.ResponseDiff, &out.ResponseDiff
+		*out = make([]ResponseDiff, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallTaskResult.
+func (in *McallTaskResult) DeepCopy() *McallTaskResult {
+	if in == nil {
+		return nil
+	}
+	out := new(McallTaskResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *McallTaskRun) DeepCopyInto(out *McallTaskRun) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallTaskRun.
+func (in *McallTaskRun) DeepCopy() *McallTaskRun {
+	if in == nil {
+		return nil
+	}
+	out := new(McallTaskRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *McallTaskRun) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *McallTaskRunList) DeepCopyInto(out *McallTaskRunList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]McallTaskRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallTaskRunList.
+func (in *McallTaskRunList) DeepCopy() *McallTaskRunList {
+	if in == nil {
+		return nil
+	}
+	out := new(McallTaskRunList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *McallTaskRunList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *McallTaskRunSpec) DeepCopyInto(out *McallTaskRunSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallTaskRunSpec.
+func (in *McallTaskRunSpec) DeepCopy() *McallTaskRunSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(McallTaskRunSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *McallTaskRunStatus) DeepCopyInto(out *McallTaskRunStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Result != nil {
+		in, out := &in.Result, &out.Result
+		*out = new(McallTaskResult)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallTaskRunStatus.
+func (in *McallTaskRunStatus) DeepCopy() *McallTaskRunStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(McallTaskRunStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *McallTaskSpec) DeepCopyInto(out *McallTaskSpec) {
+	*out = *in
+	if in.ScriptRef != nil {
+		in, out := &in.ScriptRef, &out.ScriptRef
+		*out = new(ScriptRef)
+		**out = **in
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.HttpAuth != nil {
+		in, out := &in.HttpAuth, &out.HttpAuth
+		*out = new(HttpAuth)
+		**out = **in
+	}
+	if in.GraphQL != nil {
+		in, out := &in.GraphQL, &out.GraphQL
+		*out = new(GraphQLRequest)
+		**out = **in
+	}
+	if in.Grpc != nil {
+		in, out := &in.Grpc, &out.Grpc
+		*out = new(GrpcCall)
+		**out = **in
+	}
+	if in.Sql != nil {
+		in, out := &in.Sql, &out.Sql
+		*out = new(SqlQuery)
+		**out = **in
+	}
+	if in.Kafka != nil {
+		in, out := &in.Kafka, &out.Kafka
+		*out = new(KafkaCheck)
+		**out = **in
+	}
+	if in.Exec != nil {
+		in, out := &in.Exec, &out.Exec
+		*out = new(PodExec)
+		**out = **in
+	}
+	if in.Sftp != nil {
+		in, out := &in.Sftp, &out.Sftp
+		*out = new(FileTransfer)
+		**out = **in
+	}
+	if in.Smtp != nil {
+		in, out := &in.Smtp, &out.Smtp
+		*out = new(SmtpCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WebSocket != nil {
+		in, out := &in.WebSocket, &out.WebSocket
+		*out = new(WebSocketCheck)
+		**out = **in
+	}
+	if in.HttpRetry != nil {
+		in, out := &in.HttpRetry, &out.HttpRetry
+		*out = new(HttpRetry)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(TLSConfig)
+		**out = **in
+	}
+	if in.RetryBackoff != nil {
+		in, out := &in.RetryBackoff, &out.RetryBackoff
+		*out = new(RetryBackoff)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Dependencies != nil {
+		in, out := &in.Dependencies, &out.Dependencies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Environment != nil {
+		in, out := &in.Environment, &out.Environment
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SecretRefs != nil {
+		in, out := &in.SecretRefs, &out.SecretRefs
+		*out = make([]SecretEnvRef, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.HttpValidation != nil {
+		in, out := &in.HttpValidation, &out.HttpValidation
+		*out = new(HttpValidation)
+		(*in).DeepCopyInto(*out)
 	}
 	if in.OutputValidation != nil {
 		in, out := &in.OutputValidation, &out.OutputValidation
 		*out = new(OutputValidation)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.InputSources != nil {
 		in, out := &in.InputSources, &out.InputSources
 		*out = make([]TaskInputSource, len(*in))
 		copy(*out, *in)
 	}
+	if in.WaitFor != nil {
+		in, out := &in.WaitFor, &out.WaitFor
+		*out = new(ResourceCondition)
+		**out = **in
+	}
+	if in.WarmUp != nil {
+		in, out := &in.WarmUp, &out.WarmUp
+		*out = new(WarmUp)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallTaskSpec.
+func (in *McallTaskSpec) DeepCopy() *McallTaskSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(McallTaskSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *McallTaskStatus) DeepCopyInto(out *McallTaskStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Result != nil {
+		in, out := &in.Result, &out.Result
+		*out = new(McallTaskResult)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastRetryTime != nil {
+		in, out := &in.LastRetryTime, &out.LastRetryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.WaitStartTime != nil {
+		in, out := &in.WaitStartTime, &out.WaitStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallTaskStatus.
+func (in *McallTaskStatus) DeepCopy() *McallTaskStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(McallTaskStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *McallWorkflow) DeepCopyInto(out *McallWorkflow) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallWorkflow.
+func (in *McallWorkflow) DeepCopy() *McallWorkflow {
+	if in == nil {
+		return nil
+	}
+	out := new(McallWorkflow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *McallWorkflow) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *McallWorkflowList) DeepCopyInto(out *McallWorkflowList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]McallWorkflow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallWorkflowList.
+func (in *McallWorkflowList) DeepCopy() *McallWorkflowList {
+	if in == nil {
+		return nil
+	}
+	out := new(McallWorkflowList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *McallWorkflowList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *McallWorkflowRun) DeepCopyInto(out *McallWorkflowRun) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallWorkflowRun.
+func (in *McallWorkflowRun) DeepCopy() *McallWorkflowRun {
+	if in == nil {
+		return nil
+	}
+	out := new(McallWorkflowRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *McallWorkflowRun) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *McallWorkflowRunList) DeepCopyInto(out *McallWorkflowRunList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]McallWorkflowRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallWorkflowRunList.
+func (in *McallWorkflowRunList) DeepCopy() *McallWorkflowRunList {
+	if in == nil {
+		return nil
+	}
+	out := new(McallWorkflowRunList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *McallWorkflowRunList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *McallWorkflowRunSpec) DeepCopyInto(out *McallWorkflowRunSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallWorkflowRunSpec.
+func (in *McallWorkflowRunSpec) DeepCopy() *McallWorkflowRunSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(McallWorkflowRunSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *McallWorkflowRunStatus) DeepCopyInto(out *McallWorkflowRunStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TaskResults != nil {
+		in, out := &in.TaskResults, &out.TaskResults
+		*out = make([]WorkflowRunTaskResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DAG != nil {
+		in, out := &in.DAG, &out.DAG
+		*out = new(WorkflowDAG)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallWorkflowRunStatus.
+func (in *McallWorkflowRunStatus) DeepCopy() *McallWorkflowRunStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(McallWorkflowRunStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *McallWorkflowSpec) DeepCopyInto(out *McallWorkflowSpec) {
+	*out = *in
+	if in.Tasks != nil {
+		in, out := &in.Tasks, &out.Tasks
+		*out = make([]WorkflowTaskRef, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OnExit != nil {
+		in, out := &in.OnExit, &out.OnExit
+		*out = make([]WorkflowTaskRef, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(WorkflowRetryPolicy)
+		**out = **in
+	}
+	if in.Environment != nil {
+		in, out := &in.Environment, &out.Environment
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]WorkflowParameter, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SuccessfulRunsHistoryLimit != nil {
+		in, out := &in.SuccessfulRunsHistoryLimit, &out.SuccessfulRunsHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailedRunsHistoryLimit != nil {
+		in, out := &in.FailedRunsHistoryLimit, &out.FailedRunsHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallWorkflowSpec.
+func (in *McallWorkflowSpec) DeepCopy() *McallWorkflowSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(McallWorkflowSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *McallWorkflowStatus) DeepCopyInto(out *McallWorkflowStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.OnExitStartTime != nil {
+		in, out := &in.OnExitStartTime, &out.OnExitStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.TaskStatuses != nil {
+		in, out := &in.TaskStatuses, &out.TaskStatuses
+		*out = make([]TaskStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LastRetryTime != nil {
+		in, out := &in.LastRetryTime, &out.LastRetryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRestartTime != nil {
+		in, out := &in.LastRestartTime, &out.LastRestartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRunTime != nil {
+		in, out := &in.LastRunTime, &out.LastRunTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextRunTime != nil {
+		in, out := &in.NextRunTime, &out.NextRunTime
+		*out = (*in).DeepCopy()
+	}
+	if in.SuspendedTime != nil {
+		in, out := &in.SuspendedTime, &out.SuspendedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ResumedTime != nil {
+		in, out := &in.ResumedTime, &out.ResumedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.DAG != nil {
+		in, out := &in.DAG, &out.DAG
+		*out = new(WorkflowDAG)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallWorkflowStatus.
+func (in *McallWorkflowStatus) DeepCopy() *McallWorkflowStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(McallWorkflowStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePosition) DeepCopyInto(out *NodePosition) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePosition.
+func (in *NodePosition) DeepCopy() *NodePosition {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePosition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutputValidation) DeepCopyInto(out *OutputValidation) {
+	*out = *in
+	if in.Assertions != nil {
+		in, out := &in.Assertions, &out.Assertions
+		*out = make([]Assertion, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputValidation.
+func (in *OutputValidation) DeepCopy() *OutputValidation {
+	if in == nil {
+		return nil
+	}
+	out := new(OutputValidation)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallTaskSpec.
-func (in *McallTaskSpec) DeepCopy() *McallTaskSpec {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodExec) DeepCopyInto(out *PodExec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodExec.
+func (in *PodExec) DeepCopy() *PodExec {
 	if in == nil {
 		return nil
 	}
-	out := new(McallTaskSpec)
+	out := new(PodExec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *McallTaskStatus) DeepCopyInto(out *McallTaskStatus) {
+func (in *ResourceCondition) DeepCopyInto(out *ResourceCondition) {
 	*out = *in
-	if in.StartTime != nil {
-		in, out := &in.StartTime, &out.StartTime
-		*out = (*in).DeepCopy()
-	}
-	if in.CompletionTime != nil {
-		in, out := &in.CompletionTime, &out.CompletionTime
-		*out = (*in).DeepCopy()
-	}
-	if in.Result != nil {
-		in, out := &in.Result, &out.Result
-		*out = new(McallTaskResult)
-		**out = **in
-	}
-	if in.LastRetryTime != nil {
-		in, out := &in.LastRetryTime, &out.LastRetryTime
-		*out = (*in).DeepCopy()
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallTaskStatus.
-func (in *McallTaskStatus) DeepCopy() *McallTaskStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceCondition.
+func (in *ResourceCondition) DeepCopy() *ResourceCondition {
 	if in == nil {
 		return nil
 	}
-	out := new(McallTaskStatus)
+	out := new(ResourceCondition)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *McallWorkflow) DeepCopyInto(out *McallWorkflow) {
+func (in *ResponseDiff) DeepCopyInto(out *ResponseDiff) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallWorkflow.
-func (in *McallWorkflow) DeepCopy() *McallWorkflow {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResponseDiff.
+func (in *ResponseDiff) DeepCopy() *ResponseDiff {
 	if in == nil {
 		return nil
 	}
-	out := new(McallWorkflow)
+	out := new(ResponseDiff)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *McallWorkflow) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryBackoff) DeepCopyInto(out *RetryBackoff) {
+	*out = *in
+	if in.JitterPercent != nil {
+		in, out := &in.JitterPercent, &out.JitterPercent
+		*out = new(int32)
+		**out = **in
 	}
-	return nil
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryBackoff.
+func (in *RetryBackoff) DeepCopy() *RetryBackoff {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryBackoff)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *McallWorkflowList) DeepCopyInto(out *McallWorkflowList) {
+func (in *SQLLoggingBackend) DeepCopyInto(out *SQLLoggingBackend) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]McallWorkflow, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallWorkflowList.
-func (in *McallWorkflowList) DeepCopy() *McallWorkflowList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQLLoggingBackend.
+func (in *SQLLoggingBackend) DeepCopy() *SQLLoggingBackend {
 	if in == nil {
 		return nil
 	}
-	out := new(McallWorkflowList)
+	out := new(SQLLoggingBackend)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *McallWorkflowList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScriptRef) DeepCopyInto(out *ScriptRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScriptRef.
+func (in *ScriptRef) DeepCopy() *ScriptRef {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(ScriptRef)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *McallWorkflowSpec) DeepCopyInto(out *McallWorkflowSpec) {
+func (in *SecretEnvRef) DeepCopyInto(out *SecretEnvRef) {
 	*out = *in
-	if in.Tasks != nil {
-		in, out := &in.Tasks, &out.Tasks
-		*out = make([]WorkflowTaskRef, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.RetryPolicy != nil {
-		in, out := &in.RetryPolicy, &out.RetryPolicy
-		*out = new(WorkflowRetryPolicy)
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(VaultSecretRef)
 		**out = **in
 	}
-	if in.Environment != nil {
-		in, out := &in.Environment, &out.Environment
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = new(corev1.ResourceRequirements)
-		(*in).DeepCopyInto(*out)
+	if in.AWS != nil {
+		in, out := &in.AWS, &out.AWS
+		*out = new(AWSSecretRef)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallWorkflowSpec.
-func (in *McallWorkflowSpec) DeepCopy() *McallWorkflowSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretEnvRef.
+func (in *SecretEnvRef) DeepCopy() *SecretEnvRef {
 	if in == nil {
 		return nil
 	}
-	out := new(McallWorkflowSpec)
+	out := new(SecretEnvRef)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *McallWorkflowStatus) DeepCopyInto(out *McallWorkflowStatus) {
+func (in *SmtpCheck) DeepCopyInto(out *SmtpCheck) {
 	*out = *in
-	if in.StartTime != nil {
-		in, out := &in.StartTime, &out.StartTime
-		*out = (*in).DeepCopy()
-	}
-	if in.CompletionTime != nil {
-		in, out := &in.CompletionTime, &out.CompletionTime
-		*out = (*in).DeepCopy()
-	}
-	if in.TaskStatuses != nil {
-		in, out := &in.TaskStatuses, &out.TaskStatuses
-		*out = make([]TaskStatus, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.LastRetryTime != nil {
-		in, out := &in.LastRetryTime, &out.LastRetryTime
-		*out = (*in).DeepCopy()
-	}
-	if in.LastRunTime != nil {
-		in, out := &in.LastRunTime, &out.LastRunTime
-		*out = (*in).DeepCopy()
-	}
-	if in.DAG != nil {
-		in, out := &in.DAG, &out.DAG
-		*out = new(WorkflowDAG)
-		(*in).DeepCopyInto(*out)
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McallWorkflowStatus.
-func (in *McallWorkflowStatus) DeepCopy() *McallWorkflowStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmtpCheck.
+func (in *SmtpCheck) DeepCopy() *SmtpCheck {
 	if in == nil {
 		return nil
 	}
-	out := new(McallWorkflowStatus)
+	out := new(SmtpCheck)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodePosition) DeepCopyInto(out *NodePosition) {
+func (in *SqlQuery) DeepCopyInto(out *SqlQuery) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePosition.
-func (in *NodePosition) DeepCopy() *NodePosition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SqlQuery.
+func (in *SqlQuery) DeepCopy() *SqlQuery {
 	if in == nil {
 		return nil
 	}
-	out := new(NodePosition)
+	out := new(SqlQuery)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OutputValidation) DeepCopyInto(out *OutputValidation) {
+func (in *TLSConfig) DeepCopyInto(out *TLSConfig) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputValidation.
-func (in *OutputValidation) DeepCopy() *OutputValidation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSConfig.
+func (in *TLSConfig) DeepCopy() *TLSConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(OutputValidation)
+	out := new(TLSConfig)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -437,6 +1389,11 @@ func (in *TaskCondition) DeepCopyInto(out *TaskCondition) {
 		*out = new(FieldCondition)
 		**out = **in
 	}
+	if in.Assertions != nil {
+		in, out := &in.Assertions, &out.Assertions
+		*out = make([]Assertion, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskCondition.
@@ -449,6 +1406,35 @@ func (in *TaskCondition) DeepCopy() *TaskCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskConditions) DeepCopyInto(out *TaskConditions) {
+	*out = *in
+	if in.AllOf != nil {
+		in, out := &in.AllOf, &out.AllOf
+		*out = make([]TaskCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AnyOf != nil {
+		in, out := &in.AnyOf, &out.AnyOf
+		*out = make([]TaskCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskConditions.
+func (in *TaskConditions) DeepCopy() *TaskConditions {
+	if in == nil {
+		return nil
+	}
+	out := new(TaskConditions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TaskInputSource) DeepCopyInto(out *TaskInputSource) {
 	*out = *in
@@ -464,6 +1450,21 @@ func (in *TaskInputSource) DeepCopy() *TaskInputSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskParamSource) DeepCopyInto(out *TaskParamSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskParamSource.
+func (in *TaskParamSource) DeepCopy() *TaskParamSource {
+	if in == nil {
+		return nil
+	}
+	out := new(TaskParamSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TaskRef) DeepCopyInto(out *TaskRef) {
 	*out = *in
@@ -502,6 +1503,58 @@ func (in *TaskStatus) DeepCopy() *TaskStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultSecretRef) DeepCopyInto(out *VaultSecretRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSecretRef.
+func (in *VaultSecretRef) DeepCopy() *VaultSecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultSecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WarmUp) DeepCopyInto(out *WarmUp) {
+	*out = *in
+	if in.Environment != nil {
+		in, out := &in.Environment, &out.Environment
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WarmUp.
+func (in *WarmUp) DeepCopy() *WarmUp {
+	if in == nil {
+		return nil
+	}
+	out := new(WarmUp)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebSocketCheck) DeepCopyInto(out *WebSocketCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebSocketCheck.
+func (in *WebSocketCheck) DeepCopy() *WebSocketCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(WebSocketCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkflowDAG) DeepCopyInto(out *WorkflowDAG) {
 	*out = *in
@@ -534,6 +1587,21 @@ func (in *WorkflowDAG) DeepCopy() *WorkflowDAG {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkflowParameter) DeepCopyInto(out *WorkflowParameter) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowParameter.
+func (in *WorkflowParameter) DeepCopy() *WorkflowParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkflowParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkflowRetryPolicy) DeepCopyInto(out *WorkflowRetryPolicy) {
 	*out = *in
@@ -549,6 +1617,29 @@ func (in *WorkflowRetryPolicy) DeepCopy() *WorkflowRetryPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkflowRunTaskResult) DeepCopyInto(out *WorkflowRunTaskResult) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowRunTaskResult.
+func (in *WorkflowRunTaskResult) DeepCopy() *WorkflowRunTaskResult {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkflowRunTaskResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkflowTaskRef) DeepCopyInto(out *WorkflowTaskRef) {
 	*out = *in
@@ -563,11 +1654,31 @@ func (in *WorkflowTaskRef) DeepCopyInto(out *WorkflowTaskRef) {
 		*out = new(TaskCondition)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = new(TaskConditions)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.InputSources != nil {
 		in, out := &in.InputSources, &out.InputSources
 		*out = make([]TaskInputSource, len(*in))
 		copy(*out, *in)
 	}
+	if in.WithItems != nil {
+		in, out := &in.WithItems, &out.WithItems
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WithParam != nil {
+		in, out := &in.WithParam, &out.WithParam
+		*out = new(TaskParamSource)
+		**out = **in
+	}
+	if in.OnFailure != nil {
+		in, out := &in.OnFailure, &out.OnFailure
+		*out = new(TaskRef)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowTaskRef.
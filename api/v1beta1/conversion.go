@@ -0,0 +1,43 @@
+package v1beta1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// ConvertTo converts this McallTask to the v1 hub
+func (src *McallTask) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*mcallv1.McallTask)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	src.Spec.DeepCopyInto(&dst.Spec)
+	src.Status.DeepCopyInto(&dst.Status)
+	return nil
+}
+
+// ConvertFrom converts the v1 hub to this McallTask
+func (dst *McallTask) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*mcallv1.McallTask)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	src.Spec.DeepCopyInto(&dst.Spec)
+	src.Status.DeepCopyInto(&dst.Status)
+	return nil
+}
+
+// ConvertTo converts this McallWorkflow to the v1 hub
+func (src *McallWorkflow) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*mcallv1.McallWorkflow)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	src.Spec.DeepCopyInto(&dst.Spec)
+	src.Status.DeepCopyInto(&dst.Status)
+	return nil
+}
+
+// ConvertFrom converts the v1 hub to this McallWorkflow
+func (dst *McallWorkflow) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*mcallv1.McallWorkflow)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	src.Spec.DeepCopyInto(&dst.Spec)
+	src.Status.DeepCopyInto(&dst.Status)
+	return nil
+}
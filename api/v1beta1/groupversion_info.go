@@ -0,0 +1,22 @@
+// Package v1beta1 contains API Schema definitions for the mcall v1beta1 API group.
+// Its McallTask and McallWorkflow are served next to v1 and converted to and from v1,
+// the storage version, by the conversion webhook.
+// +kubebuilder:object:generate=true
+// +groupName=mcall.tz.io
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is group version used to register these objects
+	GroupVersion = schema.GroupVersion{Group: "mcall.tz.io", Version: "v1beta1"}
+
+	// SchemeBuilder initializes a scheme builder
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme is a global function that registers this API group & version to a scheme
+	AddToScheme = SchemeBuilder.AddToScheme
+)
@@ -0,0 +1,38 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",priority=1
+//+kubebuilder:printcolumn:name="Type",type="string",JSONPath=".spec.type"
+//+kubebuilder:printcolumn:name="Input",type="string",JSONPath=".spec.input"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// McallTask is the Schema for the mcalltasks API at v1beta1. Its spec and status have the
+// same fields as v1's until a breaking change gives this version its own.
+type McallTask struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   mcallv1.McallTaskSpec   `json:"spec,omitempty"`
+	Status mcallv1.McallTaskStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// McallTaskList contains a list of McallTask
+type McallTaskList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []McallTask `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&McallTask{}, &McallTaskList{})
+}
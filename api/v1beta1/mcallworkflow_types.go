@@ -0,0 +1,41 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// McallWorkflow is the Schema for the mcallworkflows API at v1beta1. Its spec and status have
+// the same fields as v1's until a breaking change gives this version its own.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",priority=1
+// +kubebuilder:printcolumn:name="Start Time",type="date",JSONPath=".status.startTime"
+// +kubebuilder:printcolumn:name="Completion Time",type="date",JSONPath=".status.completionTime"
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule",priority=1
+// +kubebuilder:printcolumn:name="Suspend",type="boolean",JSONPath=".spec.suspend",priority=1
+// +kubebuilder:printcolumn:name="Next Run",type="string",JSONPath=".status.nextRunTime",priority=1
+// +kubebuilder:printcolumn:name="Last Duration",type="string",JSONPath=".status.lastRunDuration",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type McallWorkflow struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   mcallv1.McallWorkflowSpec   `json:"spec,omitempty"`
+	Status mcallv1.McallWorkflowStatus `json:"status,omitempty"`
+}
+
+// McallWorkflowList contains a list of McallWorkflow
+// +kubebuilder:object:root=true
+type McallWorkflowList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []McallWorkflow `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&McallWorkflow{}, &McallWorkflowList{})
+}
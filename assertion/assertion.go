@@ -0,0 +1,188 @@
+// Package assertion is the matching engine shared by expect strings, output and HTTP
+// validation and task conditions, so every rule is evaluated the same way.
+//
+// An Assertion applies an operator to a subject: the content itself, the value
+// selected from JSON content by Path, or the value selected from XML content by XPath.
+//
+//	contains, notContains  substring match
+//	equals, notEquals      exact match
+//	regex                  regular expression match (Go RE2 syntax)
+//	exists                 Path or XPath resolves in the content
+//	gt, gte, lt, lte       numeric comparison against Value
+//	cel                    CEL expression in Value evaluating to a bool; it can use
+//	                       content (string), value (the subject) and json (parsed content)
+package assertion
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Operators supported by Evaluate
+const (
+	Contains       = "contains"
+	NotContains    = "notContains"
+	Equals         = "equals"
+	NotEquals      = "notEquals"
+	Regex          = "regex"
+	Exists         = "exists"
+	GreaterThan    = "gt"
+	GreaterOrEqual = "gte"
+	LessThan       = "lt"
+	LessOrEqual    = "lte"
+	CEL            = "cel"
+)
+
+// Assertion is a single matching rule
+type Assertion struct {
+	// Operator is one of the operator constants
+	Operator string
+	// Path is an optional JSONPath (e.g. "$.data.status") selecting the subject from JSON content
+	Path string
+	// XPath is an optional XPath (e.g. "//GetStatusResponse/status") selecting the subject from XML content
+	XPath string
+	// Value is the expected value, pattern, number or CEL expression
+	Value string
+	// IgnoreCase compares strings case-insensitively
+	IgnoreCase bool
+}
+
+// String describes the assertion for error messages
+func (a Assertion) String() string {
+	subject := "content"
+	if a.Path != "" {
+		subject = a.Path
+	} else if a.XPath != "" {
+		subject = a.XPath
+	}
+	if a.Operator == Exists {
+		return fmt.Sprintf("%s exists", subject)
+	}
+	return fmt.Sprintf("%s %s %q", subject, a.Operator, a.Value)
+}
+
+// Evaluate reports whether content satisfies the assertion.
+// An error is returned for invalid rules (unknown operator, bad pattern, non-numeric operands).
+func (a Assertion) Evaluate(content string) (bool, error) {
+	subject := content
+	if a.Path != "" {
+		value, err := ExtractJSONPath(content, a.Path)
+		if err != nil {
+			// A missing path fails the assertion instead of erroring
+			return a.Operator == NotContains || a.Operator == NotEquals, nil
+		}
+		subject = value
+	} else if a.XPath != "" {
+		value, err := ExtractXPath(content, a.XPath)
+		if err != nil {
+			// Like a missing JSON path, a missing element or invalid XML fails the assertion
+			return a.Operator == NotContains || a.Operator == NotEquals, nil
+		}
+		subject = value
+	}
+
+	value := a.Value
+	if a.IgnoreCase && a.Operator != Regex && a.Operator != CEL {
+		subject = strings.ToLower(subject)
+		value = strings.ToLower(value)
+	}
+
+	switch a.Operator {
+	case Contains, "":
+		return strings.Contains(subject, value), nil
+	case NotContains:
+		return !strings.Contains(subject, value), nil
+	case Equals:
+		return subject == value, nil
+	case NotEquals:
+		return subject != value, nil
+	case Regex:
+		pattern := value
+		if a.IgnoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, fmt.Errorf("invalid regex %q: %w", a.Value, err)
+		}
+		return re.MatchString(subject), nil
+	case Exists:
+		if a.Path == "" && a.XPath == "" {
+			return false, fmt.Errorf("operator %s requires a path or xpath", Exists)
+		}
+		return true, nil
+	case GreaterThan, GreaterOrEqual, LessThan, LessOrEqual:
+		return compareNumbers(a.Operator, subject, value)
+	case CEL:
+		return evaluateCEL(value, content, subject)
+	default:
+		return false, fmt.Errorf("unknown assertion operator: %s", a.Operator)
+	}
+}
+
+// All reports whether content satisfies every assertion; it returns the first failed assertion
+func All(content string, assertions []Assertion) (bool, *Assertion, error) {
+	for i := range assertions {
+		ok, err := assertions[i].Evaluate(content)
+		if err != nil {
+			return false, &assertions[i], err
+		}
+		if !ok {
+			return false, &assertions[i], nil
+		}
+	}
+	return true, nil, nil
+}
+
+// Any reports whether content satisfies at least one assertion
+func Any(content string, assertions []Assertion) (bool, error) {
+	for _, a := range assertions {
+		ok, err := a.Evaluate(content)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ParseExpect converts an mcall expect string into assertions to be combined with Any.
+// Alternatives are separated by "|" and matched as substrings; count expressions
+// ("$count < N") are not supported and ignored.
+func ParseExpect(expect string) []Assertion {
+	var assertions []Assertion
+	for _, item := range strings.Split(expect, "|") {
+		if strings.Contains(item, "$count") {
+			continue
+		}
+		assertions = append(assertions, Assertion{Operator: Contains, Value: strings.TrimSpace(item)})
+	}
+	return assertions
+}
+
+// compareNumbers compares subject with value numerically
+func compareNumbers(operator, subject, value string) (bool, error) {
+	actual, err := strconv.ParseFloat(strings.TrimSpace(subject), 64)
+	if err != nil {
+		return false, nil // Non-numeric content doesn't satisfy a numeric comparison
+	}
+	expected, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid number %q for operator %s", value, operator)
+	}
+
+	switch operator {
+	case GreaterThan:
+		return actual > expected, nil
+	case GreaterOrEqual:
+		return actual >= expected, nil
+	case LessThan:
+		return actual < expected, nil
+	default:
+		return actual <= expected, nil
+	}
+}
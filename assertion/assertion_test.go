@@ -0,0 +1,162 @@
+package assertion
+
+import "testing"
+
+// soapResponse is a SOAP 1.1 response envelope with namespace prefixes
+const soapResponse = `<?xml version="1.0" encoding="utf-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:m="http://example.com/status">
+  <soap:Body>
+    <m:GetStatusResponse>
+      <m:status>UP</m:status>
+      <m:check name="db" latency="12">ok</m:check>
+      <m:check name="cache" latency="3">degraded</m:check>
+    </m:GetStatusResponse>
+  </soap:Body>
+</soap:Envelope>`
+
+// TestEvaluate tests each assertion operator
+func TestEvaluate(t *testing.T) {
+	body := `{"status":"ok","count":3,"items":[{"name":"a"},{"name":"b"}]}`
+
+	tests := []struct {
+		name      string
+		assertion Assertion
+		content   string
+		want      bool
+		wantErr   bool
+	}{
+		{name: "contains", assertion: Assertion{Operator: Contains, Value: "World"}, content: "Hello World", want: true},
+		{name: "empty operator is contains", assertion: Assertion{Value: "Hello"}, content: "Hello World", want: true},
+		{name: "contains ignore case", assertion: Assertion{Operator: Contains, Value: "world", IgnoreCase: true}, content: "Hello World", want: true},
+		{name: "contains is case sensitive", assertion: Assertion{Operator: Contains, Value: "world"}, content: "Hello World", want: false},
+		{name: "not contains", assertion: Assertion{Operator: NotContains, Value: "ERROR"}, content: "all good", want: true},
+		{name: "equals", assertion: Assertion{Operator: Equals, Value: "ok"}, content: "ok", want: true},
+		{name: "not equals", assertion: Assertion{Operator: NotEquals, Value: "ok"}, content: "fail", want: true},
+		{name: "regex", assertion: Assertion{Operator: Regex, Value: `^HTTP/\d\.\d 200`}, content: "HTTP/1.1 200 OK", want: true},
+		{name: "regex ignore case", assertion: Assertion{Operator: Regex, Value: `^ok$`, IgnoreCase: true}, content: "OK", want: true},
+		{name: "invalid regex", assertion: Assertion{Operator: Regex, Value: `(`}, content: "x", wantErr: true},
+		{name: "path equals", assertion: Assertion{Operator: Equals, Path: "$.status", Value: "ok"}, content: body, want: true},
+		{name: "path array index", assertion: Assertion{Operator: Equals, Path: "$.items[1].name", Value: "b"}, content: body, want: true},
+		{name: "missing path fails", assertion: Assertion{Operator: Equals, Path: "$.missing", Value: "ok"}, content: body, want: false},
+		{name: "missing path satisfies notEquals", assertion: Assertion{Operator: NotEquals, Path: "$.missing", Value: "ok"}, content: body, want: true},
+		{name: "exists", assertion: Assertion{Operator: Exists, Path: "$.items"}, content: body, want: true},
+		{name: "exists without path", assertion: Assertion{Operator: Exists}, content: body, wantErr: true},
+		{name: "greater than", assertion: Assertion{Operator: GreaterThan, Path: "$.count", Value: "2"}, content: body, want: true},
+		{name: "greater or equal", assertion: Assertion{Operator: GreaterOrEqual, Value: "3"}, content: "3", want: true},
+		{name: "less than", assertion: Assertion{Operator: LessThan, Value: "100"}, content: " 42\n", want: true},
+		{name: "less or equal fails", assertion: Assertion{Operator: LessOrEqual, Value: "1.5"}, content: "2", want: false},
+		{name: "numeric comparison on text", assertion: Assertion{Operator: GreaterThan, Value: "1"}, content: "abc", want: false},
+		{name: "invalid number", assertion: Assertion{Operator: GreaterThan, Value: "abc"}, content: "1", wantErr: true},
+		{name: "cel on json", assertion: Assertion{Operator: CEL, Value: `json.status == "ok" && json.items.size() == 2`}, content: body, want: true},
+		{name: "cel on value", assertion: Assertion{Operator: CEL, Path: "$.status", Value: `value.startsWith("o")`}, content: body, want: true},
+		{name: "cel on content", assertion: Assertion{Operator: CEL, Value: `content.contains("items")`}, content: body, want: true},
+		{name: "cel non-bool", assertion: Assertion{Operator: CEL, Value: `content.size()`}, content: body, wantErr: true},
+		{name: "cel syntax error", assertion: Assertion{Operator: CEL, Value: `json.status ==`}, content: body, wantErr: true},
+		{name: "unknown operator", assertion: Assertion{Operator: "like", Value: "x"}, content: "x", wantErr: true},
+		{name: "xpath equals", assertion: Assertion{Operator: Equals, XPath: "//status", Value: "UP"}, content: soapResponse, want: true},
+		{name: "xpath exists", assertion: Assertion{Operator: Exists, XPath: "/Envelope/Body/GetStatusResponse"}, content: soapResponse, want: true},
+		{name: "missing xpath fails", assertion: Assertion{Operator: Exists, XPath: "//Fault"}, content: soapResponse, want: false},
+		{name: "xpath on non-xml fails", assertion: Assertion{Operator: Equals, XPath: "//status", Value: "UP"}, content: body, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.assertion.Evaluate(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Evaluate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseExpect tests converting mcall expect strings into assertions
+func TestParseExpect(t *testing.T) {
+	tests := []struct {
+		name    string
+		expect  string
+		content string
+		want    bool
+	}{
+		{name: "single match", expect: "Hello", content: "Hello World", want: true},
+		{name: "alternatives", expect: "200|301|500", content: "Status: 301", want: true},
+		{name: "no alternative matches", expect: "200|301|500", content: "Status: 404", want: false},
+		{name: "spaces trimmed", expect: "200 | OK", content: "OK", want: true},
+		{name: "count expressions ignored", expect: "$count < 5", content: "anything", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Any(tt.content, ParseExpect(tt.expect))
+			if err != nil {
+				t.Fatalf("Any() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Any(ParseExpect(%q)) = %v, want %v", tt.expect, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestAll tests that All reports the first failed assertion
+func TestAll(t *testing.T) {
+	assertions := []Assertion{
+		{Operator: Contains, Value: "ok"},
+		{Operator: NotContains, Value: "warning"},
+	}
+
+	if ok, failed, err := All("ok", assertions); !ok || failed != nil || err != nil {
+		t.Errorf("All() = %v, %v, %v, want true", ok, failed, err)
+	}
+
+	ok, failed, err := All("ok with warning", assertions)
+	if ok || err != nil || failed == nil || failed.Value != "warning" {
+		t.Errorf("All() = %v, %v, %v, want the notContains assertion to fail", ok, failed, err)
+	}
+
+	if ok, _, _ := All("anything", nil); !ok {
+		t.Errorf("All() with no assertions = false, want true")
+	}
+}
+
+// TestExtractXPath tests the supported XPath subset on a SOAP envelope
+func TestExtractXPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "absolute path", path: "/Envelope/Body/GetStatusResponse/status", want: "UP"},
+		{name: "namespace prefixes", path: "/soap:Envelope/soap:Body/m:GetStatusResponse/m:status", want: "UP"},
+		{name: "descendant", path: "//status", want: "UP"},
+		{name: "first match", path: "//check", want: "ok"},
+		{name: "position", path: "//GetStatusResponse/check[2]", want: "degraded"},
+		{name: "attribute predicate", path: "//check[@name='cache']/@latency", want: "3"},
+		{name: "wildcard", path: "/Envelope/*/GetStatusResponse/status/text()", want: "UP"},
+		{name: "element text content", path: "/Envelope/Body", want: "UP\n      ok\n      degraded"},
+		{name: "missing element", path: "//Fault/faultcode", wantErr: true},
+		{name: "position out of range", path: "//check[3]", wantErr: true},
+		{name: "relative path", path: "Envelope/Body", wantErr: true},
+		{name: "unsupported predicate", path: "//check[last()]", wantErr: true},
+		{name: "attribute not last", path: "//check/@name/status", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractXPath(soapResponse, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExtractXPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ExtractXPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ExtractXPath("<unclosed>", "//unclosed"); err == nil {
+		t.Errorf("ExtractXPath() on invalid XML expected an error")
+	}
+}
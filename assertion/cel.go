@@ -0,0 +1,51 @@
+package assertion
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+)
+
+// evaluateCEL evaluates a boolean CEL expression over the content and the selected subject
+func evaluateCEL(expression, content, subject string) (bool, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("content", cel.StringType),
+		cel.Variable("value", cel.StringType),
+		cel.Variable("json", cel.DynType),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return false, fmt.Errorf("invalid CEL expression %q: %w", expression, issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return false, fmt.Errorf("CEL expression %q must evaluate to a bool, got %s", expression, ast.OutputType())
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return false, fmt.Errorf("invalid CEL expression %q: %w", expression, err)
+	}
+
+	// json is null when the content isn't JSON
+	var data interface{}
+	_ = json.Unmarshal([]byte(content), &data)
+
+	out, _, err := program.Eval(map[string]interface{}{
+		"content": content,
+		"value":   subject,
+		"json":    data,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate CEL expression %q: %w", expression, err)
+	}
+
+	result, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("CEL expression %q must evaluate to a bool, got %T", expression, out.Value())
+	}
+	return result, nil
+}
@@ -0,0 +1,84 @@
+package assertion
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// filterIntegerPattern matches an integer literal compared in a filter like "?(@.count > 1)"
+var filterIntegerPattern = regexp.MustCompile(`((?:[<>]=?|[=!]=)\s*)(-?\d+)(\s*\))`)
+
+// ExtractJSONPath extracts a value from a JSON string using a JSONPath expression such as
+// "$.data.status", "$.items[0].name", "$.items[?(@.name=='x')].id", "$.items[*].id" or
+// "$..id". A definite path returns its value: scalars as plain strings, objects and arrays as
+// JSON. Paths with wildcards, filters, recursive descent, slices or unions return the JSON
+// array of their matches.
+func ExtractJSONPath(jsonStr string, path string) (string, error) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(jsonStr), &data); err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	expression := strings.TrimSpace(path)
+	if expression == "" {
+		expression = "$"
+	}
+	if !strings.HasPrefix(expression, "$") {
+		expression = "$." + strings.TrimPrefix(expression, ".")
+	}
+	// JSON numbers decode to float64, which the filters only compare to float literals
+	expression = filterIntegerPattern.ReplaceAllString(expression, "${1}${2}.0${3}")
+
+	parser := jsonpath.New("path")
+	if err := parser.Parse("{" + expression + "}"); err != nil {
+		return "", fmt.Errorf("invalid JSONPath %q: %w", path, err)
+	}
+	results, err := parser.FindResults(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to evaluate JSONPath %q: %w", path, err)
+	}
+
+	var matches []interface{}
+	for _, result := range results {
+		for _, value := range result {
+			matches = append(matches, value.Interface())
+		}
+	}
+
+	if isIndefinitePath(expression) {
+		if matches == nil {
+			matches = []interface{}{}
+		}
+		jsonBytes, _ := json.Marshal(matches)
+		return string(jsonBytes), nil
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no match for JSONPath %q", path)
+	}
+
+	// Convert result to string
+	switch v := matches[0].(type) {
+	case string:
+		return v, nil
+	case float64, int, int64, bool:
+		return fmt.Sprintf("%v", v), nil
+	default:
+		// Object or array - return as JSON
+		jsonBytes, _ := json.Marshal(v)
+		return string(jsonBytes), nil
+	}
+}
+
+// isIndefinitePath reports whether a JSONPath can match several values
+func isIndefinitePath(path string) bool {
+	for _, token := range []string{"*", "..", "?(", ":", ","} {
+		if strings.Contains(path, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,274 @@
+package assertion
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// xmlNode is an element of a parsed XML document
+type xmlNode struct {
+	name     string // Local name, namespace prefixes are ignored
+	attrs    map[string]string
+	children []*xmlNode   // Child elements
+	content  []xmlContent // Child elements and character data in document order
+}
+
+// xmlContent is either a child element or character data
+type xmlContent struct {
+	element *xmlNode
+	text    string
+}
+
+// value returns the XPath string value of the element: the text of all its descendants
+func (n *xmlNode) value() string {
+	var value strings.Builder
+	var collect func(*xmlNode)
+	collect = func(node *xmlNode) {
+		for _, content := range node.content {
+			if content.element != nil {
+				collect(content.element)
+			} else {
+				value.WriteString(content.text)
+			}
+		}
+	}
+	collect(n)
+	return strings.TrimSpace(value.String())
+}
+
+// directText returns the character data directly inside the element
+func (n *xmlNode) directText() string {
+	var text strings.Builder
+	for _, content := range n.content {
+		text.WriteString(content.text)
+	}
+	return strings.TrimSpace(text.String())
+}
+
+// xpathStep is one location step of a path such as "//soap:Body/m:status[1]"
+type xpathStep struct {
+	descendant bool   // "//" instead of "/"
+	name       string // Local name, "*", "@attr" or "text()"
+	position   int    // [n] predicate, 0 when absent
+	attrName   string // [@attr='value'] predicate
+	attrValue  string
+}
+
+// ExtractXPath extracts a value from an XML string using a subset of XPath 1.0:
+// absolute paths ("/Envelope/Body/status"), descendants ("//status"), "*",
+// positional ([1]) and attribute ([@id='1']) predicates, and "@attr" or "text()"
+// as the last step. Names match local names so namespace prefixes such as "soap:"
+// can be kept or left out. The value of the first match is returned, elements
+// as their trimmed text content.
+func ExtractXPath(xmlStr string, path string) (string, error) {
+	steps, err := parseXPath(path)
+	if err != nil {
+		return "", err
+	}
+	root, err := parseXML(xmlStr)
+	if err != nil {
+		return "", err
+	}
+
+	nodes := []*xmlNode{root}
+	for i, step := range steps {
+		last := i == len(steps)-1
+		if strings.HasPrefix(step.name, "@") || step.name == "text()" {
+			if !last {
+				return "", fmt.Errorf("invalid XPath %q: %s must be the last step", path, step.name)
+			}
+			for _, node := range selectXPathContext(nodes, step.descendant) {
+				if step.name == "text()" {
+					if text := node.directText(); text != "" {
+						return text, nil
+					}
+				} else if value, exists := node.attrs[step.name[1:]]; exists {
+					return value, nil
+				}
+			}
+			return "", fmt.Errorf("XPath %s not found", path)
+		}
+
+		var next []*xmlNode
+		for _, node := range selectXPathContext(nodes, step.descendant) {
+			next = append(next, step.match(node.children)...)
+		}
+		nodes = next
+		if len(nodes) == 0 {
+			return "", fmt.Errorf("XPath %s not found", path)
+		}
+	}
+	return nodes[0].value(), nil
+}
+
+// selectXPathContext returns the nodes whose children a step selects from:
+// the nodes themselves, or with "//" all their descendants too
+func selectXPathContext(nodes []*xmlNode, descendant bool) []*xmlNode {
+	if !descendant {
+		return nodes
+	}
+	var result []*xmlNode
+	seen := make(map[*xmlNode]bool)
+	var collect func(*xmlNode)
+	collect = func(node *xmlNode) {
+		if seen[node] {
+			return
+		}
+		seen[node] = true
+		result = append(result, node)
+		for _, child := range node.children {
+			collect(child)
+		}
+	}
+	for _, node := range nodes {
+		collect(node)
+	}
+	return result
+}
+
+// match returns the children selected by the step's name and predicates
+func (s xpathStep) match(children []*xmlNode) []*xmlNode {
+	var matched []*xmlNode
+	for _, child := range children {
+		if s.name != "*" && s.name != child.name {
+			continue
+		}
+		if s.attrName != "" && child.attrs[s.attrName] != s.attrValue {
+			continue
+		}
+		matched = append(matched, child)
+	}
+	if s.position > 0 {
+		if s.position > len(matched) {
+			return nil
+		}
+		return matched[s.position-1 : s.position]
+	}
+	return matched
+}
+
+// parseXPath splits a path into its location steps
+func parseXPath(path string) ([]xpathStep, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("invalid XPath %q: must start with / or //", path)
+	}
+
+	var steps []xpathStep
+	for rest := path; rest != ""; {
+		var step xpathStep
+		if strings.HasPrefix(rest, "//") {
+			step.descendant = true
+			rest = rest[2:]
+		} else if strings.HasPrefix(rest, "/") {
+			rest = rest[1:]
+		}
+
+		end := strings.Index(rest, "/")
+		if bracket := strings.Index(rest, "["); bracket >= 0 && (end < 0 || bracket < end) {
+			// Slashes inside a predicate don't end the step
+			if closing := strings.Index(rest[bracket:], "]"); closing >= 0 {
+				end = strings.Index(rest[bracket+closing:], "/")
+				if end >= 0 {
+					end += bracket + closing
+				}
+			}
+		}
+		token := rest
+		if end >= 0 {
+			token, rest = rest[:end], rest[end:]
+		} else {
+			rest = ""
+		}
+
+		name := token
+		if bracket := strings.Index(token, "["); bracket >= 0 {
+			if !strings.HasSuffix(token, "]") {
+				return nil, fmt.Errorf("invalid XPath %q: unterminated predicate", path)
+			}
+			name = token[:bracket]
+			if err := step.parsePredicate(token[bracket+1 : len(token)-1]); err != nil {
+				return nil, fmt.Errorf("invalid XPath %q: %w", path, err)
+			}
+		}
+		if name == "" {
+			return nil, fmt.Errorf("invalid XPath %q: empty step", path)
+		}
+		step.name = localName(name)
+		if strings.HasPrefix(name, "@") {
+			step.name = "@" + localName(name[1:])
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// parsePredicate parses a [n] or [@attr='value'] predicate
+func (s *xpathStep) parsePredicate(predicate string) error {
+	predicate = strings.TrimSpace(predicate)
+	if position, err := strconv.Atoi(predicate); err == nil {
+		if position < 1 {
+			return fmt.Errorf("position %d must be 1 or more", position)
+		}
+		s.position = position
+		return nil
+	}
+
+	name, value, found := strings.Cut(predicate, "=")
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	if !found || !strings.HasPrefix(name, "@") || len(value) < 2 ||
+		(value[0] != '\'' && value[0] != '"') || value[len(value)-1] != value[0] {
+		return fmt.Errorf("unsupported predicate [%s]", predicate)
+	}
+	s.attrName = localName(name[1:])
+	s.attrValue = value[1 : len(value)-1]
+	return nil
+}
+
+// localName strips the namespace prefix of a name
+func localName(name string) string {
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// parseXML parses a document into a tree under an unnamed document node
+func parseXML(xmlStr string) (*xmlNode, error) {
+	document := &xmlNode{}
+	stack := []*xmlNode{document}
+
+	decoder := xml.NewDecoder(strings.NewReader(xmlStr))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name.Local, attrs: make(map[string]string, len(t.Attr))}
+			for _, attr := range t.Attr {
+				node.attrs[attr.Name.Local] = attr.Value
+			}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, node)
+			parent.content = append(parent.content, xmlContent{element: node})
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			parent := stack[len(stack)-1]
+			parent.content = append(parent.content, xmlContent{text: string(t)})
+		}
+	}
+	if len(document.children) == 0 {
+		return nil, fmt.Errorf("invalid XML: no root element")
+	}
+	return document, nil
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"go.uber.org/zap/zapcore"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -20,6 +24,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+	mcallv1beta1 "github.com/doohee323/tz-mcall-operator/api/v1beta1"
 	"github.com/doohee323/tz-mcall-operator/controller"
 	//+kubebuilder:scaffold:imports
 )
@@ -59,9 +64,40 @@ func getTaskTimeout() time.Duration {
 	return time.Duration(timeout) * time.Second
 }
 
+// getLogLevel returns the log level from an environment variable, debug also logs every reconcile
+func getLogLevel() zapcore.Level {
+	if strings.EqualFold(os.Getenv("LOG_LEVEL"), "debug") {
+		return zapcore.DebugLevel
+	}
+
+	return zapcore.InfoLevel // default value
+}
+
+// getMaxConcurrentReconciles returns the number of parallel reconciles from an environment variable
+func getMaxConcurrentReconciles(key string) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return 1 // default value
+	}
+
+	return value
+}
+
+// getWebhookPort returns the port of the webhook server from an environment variable
+func getWebhookPort() int {
+	port, err := strconv.Atoi(os.Getenv("WEBHOOK_PORT"))
+	if err != nil || port <= 0 {
+		return 9443 // default value
+	}
+
+	return port
+}
+
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(mcallv1.AddToScheme(scheme))
+	utilruntime.Must(mcallv1beta1.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -71,13 +107,22 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var taskMaxConcurrentReconciles int
+	var workflowMaxConcurrentReconciles int
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.IntVar(&taskMaxConcurrentReconciles, "task-max-concurrent-reconciles",
+		getMaxConcurrentReconciles("TASK_MAX_CONCURRENT_RECONCILES"),
+		"Number of McallTasks reconciled in parallel.")
+	flag.IntVar(&workflowMaxConcurrentReconciles, "workflow-max-concurrent-reconciles",
+		getMaxConcurrentReconciles("WORKFLOW_MAX_CONCURRENT_RECONCILES"),
+		"Number of McallWorkflows reconciled in parallel.")
 	opts := zap.Options{
 		Development: true,
+		Level:       getLogLevel(),
 	}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
@@ -89,12 +134,15 @@ func main() {
 	taskTimeout := getTaskTimeout()
 	setupLog.Info("Controller configuration loaded",
 		"reconcileInterval", reconcileInterval.String(),
-		"taskTimeout", taskTimeout.String())
+		"taskTimeout", taskTimeout.String(),
+		"logLevel", getLogLevel().String(),
+		"taskMaxConcurrentReconciles", taskMaxConcurrentReconciles,
+		"workflowMaxConcurrentReconciles", workflowMaxConcurrentReconciles)
 
 	// Check CRD availability before starting manager
 	setupLog.Info("Checking CRD availability...")
 	config := ctrl.GetConfigOrDie()
-	client, err := client.New(config, client.Options{})
+	client, err := client.New(config, client.Options{Scheme: scheme})
 	if err != nil {
 		setupLog.Error(err, "unable to create client for CRD check")
 		os.Exit(1)
@@ -116,10 +164,23 @@ func main() {
 		setupLog.Info("McallWorkflow CRD is available", "count", len(mcallWorkflows.Items))
 	}
 
+	// Export the spans of workflow runs and task executions over OTLP
+	shutdownTracing, err := controller.SetupTracing(ctx, controller.GetTracingConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+
+	// The metrics server also serves the dry evaluation of task specs, see mcall-lint
+	metricsOptions := server.Options{
+		BindAddress:   metricsAddr,
+		ExtraHandlers: map[string]http.Handler{"/lint": controller.LintHandler()},
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
-		Metrics:                server.Options{BindAddress: metricsAddr},
-		WebhookServer:          webhook.NewServer(webhook.Options{Port: 9443}),
+		Metrics:                metricsOptions,
+		WebhookServer:          webhook.NewServer(webhook.Options{Port: getWebhookPort()}),
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "tz-mcall-operator",
@@ -130,22 +191,73 @@ func main() {
 	}
 
 	if err = (&controller.McallTaskReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		MaxConcurrentReconciles: taskMaxConcurrentReconciles,
+		Config:                  mgr.GetConfig(),
+		Recorder:                mgr.GetEventRecorderFor("mcalltask-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "McallTask")
 		os.Exit(1)
 	}
 
 	if err = (&controller.McallWorkflowReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		MaxConcurrentReconciles: workflowMaxConcurrentReconciles,
+		Recorder:                mgr.GetEventRecorderFor("mcallworkflow-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "McallWorkflow")
 		os.Exit(1)
 	}
+
+	// Reload the logging configuration when its ConfigMap, Secret or McallLoggingConfigs change
+	if err = (&controller.LoggingConfigReconciler{
+		Client: mgr.GetClient(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "LoggingConfig")
+		os.Exit(1)
+	}
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = (&controller.McallTaskValidator{
+			Client: mgr.GetAPIReader(),
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "McallTask")
+			os.Exit(1)
+		}
+		if err = (&controller.McallWorkflowValidator{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "McallWorkflow")
+			os.Exit(1)
+		}
+	}
+	// Serve the conversion between v1beta1 and the v1 hub at /convert and point the CRDs to it
+	if conversionConfig := controller.GetConversionWebhookConfig(); conversionConfig.ServiceName != "" {
+		if err = ctrl.NewWebhookManagedBy(mgr).For(&mcallv1.McallTask{}).Complete(); err != nil {
+			setupLog.Error(err, "unable to create conversion webhook", "webhook", "McallTask")
+			os.Exit(1)
+		}
+		if err = ctrl.NewWebhookManagedBy(mgr).For(&mcallv1.McallWorkflow{}).Complete(); err != nil {
+			setupLog.Error(err, "unable to create conversion webhook", "webhook", "McallWorkflow")
+			os.Exit(1)
+		}
+		if err := controller.EnableCRDConversion(ctx, client, conversionConfig); err != nil {
+			setupLog.Error(err, "unable to enable CRD conversion")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
+	// Connect the logging backends once and keep them healthy; entries reuse their connections
+	if err := mgr.Add(controller.RunLoggingBackends(mgr.GetClient())); err != nil {
+		setupLog.Error(err, "unable to set up logging backends")
+		os.Exit(1)
+	}
+	// Delete the log entries of the SQL backends once their retention period is over
+	if err := mgr.Add(controller.RunLogRetention(mgr.GetClient())); err != nil {
+		setupLog.Error(err, "unable to set up log retention")
+		os.Exit(1)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -160,4 +272,11 @@ func main() {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
+
+	// Flush the spans still buffered
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		setupLog.Error(err, "problem flushing traces")
+	}
 }
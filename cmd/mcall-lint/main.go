@@ -0,0 +1,103 @@
+// mcall-lint checks the conditions, input template, expect and output validation of a McallTask
+// against sample results without executing it, e.g.
+//
+//	mcall-lint -f lint.yaml
+//	mcall-lint -task task.yaml -output-file sample-output.txt
+//
+// It prints the report in JSON and exits with 1 when a check didn't pass.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+	"github.com/doohee323/tz-mcall-operator/controller"
+)
+
+func main() {
+	var requestFile, taskFile, output, outputFile string
+	flag.StringVar(&requestFile, "f", "", "Lint request file in YAML or JSON (task, conditions, output, samples), - for stdin.")
+	flag.StringVar(&taskFile, "task", "", "McallTask manifest whose spec is checked, overriding the task of the request.")
+	flag.StringVar(&output, "output", "", "Sample output of the task, overriding the output of the request.")
+	flag.StringVar(&outputFile, "output-file", "", "File holding the sample output of the task.")
+	flag.Parse()
+
+	report, err := lint(requestFile, taskFile, output, outputFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mcall-lint: %v\n", err)
+		os.Exit(2)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "mcall-lint: %v\n", err)
+		os.Exit(2)
+	}
+	if !report.Passed {
+		os.Exit(1)
+	}
+}
+
+// lint builds the lint request from the files and flags and evaluates it
+func lint(requestFile, taskFile, output, outputFile string) (*controller.TaskLintReport, error) {
+	request := &controller.TaskLintRequest{}
+	if requestFile != "" {
+		reader, closeFn, err := open(requestFile)
+		if err != nil {
+			return nil, err
+		}
+		defer closeFn()
+		if request, err = controller.DecodeTaskLintRequest(reader); err != nil {
+			return nil, err
+		}
+	}
+
+	if taskFile != "" {
+		reader, closeFn, err := open(taskFile)
+		if err != nil {
+			return nil, err
+		}
+		defer closeFn()
+		var task mcallv1.McallTask
+		if err := yaml.NewYAMLOrJSONDecoder(reader, 4096).Decode(&task); err != nil {
+			return nil, fmt.Errorf("invalid task manifest %s: %w", taskFile, err)
+		}
+		request.Task = task.Spec
+	}
+	if requestFile == "" && taskFile == "" {
+		return nil, fmt.Errorf("a lint request (-f) or a task manifest (-task) is required")
+	}
+
+	if outputFile != "" {
+		data, err := os.ReadFile(outputFile)
+		if err != nil {
+			return nil, err
+		}
+		request.Output = string(data)
+	}
+	if output != "" {
+		request.Output = output
+	}
+
+	return controller.LintTask(context.Background(), request)
+}
+
+// open opens a file, or stdin for -
+func open(name string) (io.Reader, func(), error) {
+	if name == "-" {
+		return os.Stdin, func() {}, nil
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, func() { file.Close() }, nil
+}
@@ -0,0 +1,227 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// maxArtifactBytes bounds the artifacts read back for InputSources
+const maxArtifactBytes = 64 << 20
+
+// artifactRequestTimeout bounds each request to Cloud Storage
+const artifactRequestTimeout = 30 * time.Second
+
+// artifactStoreConfig holds the object storage outputs are uploaded to
+type artifactStoreConfig struct {
+	Type       string // "s3" or "gcs", empty disables the artifact store
+	Bucket     string
+	Prefix     string
+	Region     string // Region of S3 buckets, AWS_REGION by default
+	Endpoint   string // S3-compatible or GCS endpoint, e.g. a MinIO URL (path-style requests)
+	AllOutputs bool   // Upload every output instead of truncated ones only
+}
+
+// getArtifactStoreConfig loads the artifact store settings from environment variables
+func getArtifactStoreConfig() artifactStoreConfig {
+	config := artifactStoreConfig{
+		Type:       os.Getenv("ARTIFACT_STORE_TYPE"),
+		Bucket:     os.Getenv("ARTIFACT_STORE_BUCKET"),
+		Prefix:     strings.Trim(getEnvOrDefault("ARTIFACT_STORE_PREFIX", "mcall"), "/"),
+		Region:     getEnvOrDefault("ARTIFACT_STORE_REGION", os.Getenv("AWS_REGION")),
+		Endpoint:   strings.TrimSuffix(os.Getenv("ARTIFACT_STORE_ENDPOINT"), "/"),
+		AllOutputs: os.Getenv("ARTIFACT_STORE_ALL_OUTPUTS") == "true",
+	}
+	if config.Bucket == "" {
+		config.Type = ""
+	}
+	return config
+}
+
+// artifactKey returns the object key of the output of an execution
+func artifactKey(config artifactStoreConfig, task *mcallv1.McallTask) string {
+	name := newTaskRunName(task)
+	if name == "" {
+		name = fmt.Sprintf("%s-%s", task.Name, time.Now().UTC().Format("20060102-150405"))
+	}
+	key := fmt.Sprintf("%s/%s/%s.txt", task.Namespace, task.Name, name)
+	if config.Prefix != "" {
+		key = config.Prefix + "/" + key
+	}
+	return key
+}
+
+// uploadArtifact uploads an output and returns its s3:// or gs:// URL
+func uploadArtifact(ctx context.Context, config artifactStoreConfig, key string, data []byte) (string, error) {
+	switch config.Type {
+	case "s3":
+		client, err := newAWSClient(ctx)
+		if err != nil {
+			return "", err
+		}
+		if _, err := client.s3Request(ctx, http.MethodPut, config, key, data); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("s3://%s/%s", config.Bucket, key), nil
+	case "gcs":
+		token, err := gcsAccessToken(ctx)
+		if err != nil {
+			return "", err
+		}
+		uploadURL := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s",
+			gcsEndpoint(config), url.PathEscape(config.Bucket), url.QueryEscape(key))
+		if _, err := gcsRequest(ctx, http.MethodPost, uploadURL, token, data); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("gs://%s/%s", config.Bucket, key), nil
+	default:
+		return "", fmt.Errorf("unsupported artifact store type %q", config.Type)
+	}
+}
+
+// downloadArtifact reads back an output uploaded to the artifact store
+func downloadArtifact(ctx context.Context, config artifactStoreConfig, artifactURL string) ([]byte, error) {
+	parsed, err := url.Parse(artifactURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifact URL %q: %w", artifactURL, err)
+	}
+	config.Bucket = parsed.Host
+	key := strings.TrimPrefix(parsed.Path, "/")
+
+	switch parsed.Scheme {
+	case "s3":
+		client, err := newAWSClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return client.s3Request(ctx, http.MethodGet, config, key, nil)
+	case "gs":
+		token, err := gcsAccessToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		objectURL := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media",
+			gcsEndpoint(config), url.PathEscape(config.Bucket), url.PathEscape(key))
+		return gcsRequest(ctx, http.MethodGet, objectURL, token, nil)
+	default:
+		return nil, fmt.Errorf("unsupported artifact URL %q", artifactURL)
+	}
+}
+
+// s3Request sends a signed object request, path-style to a custom endpoint and
+// virtual-hosted-style to AWS, and returns the response body
+func (c *awsClient) s3Request(ctx context.Context, method string, config artifactStoreConfig, key string, data []byte) ([]byte, error) {
+	region := config.Region
+	if region == "" {
+		region = c.region
+	}
+	if region == "" {
+		return nil, fmt.Errorf("no S3 region: set ARTIFACT_STORE_REGION or AWS_REGION")
+	}
+	objectURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", config.Bucket, region, key)
+	if config.Endpoint != "" {
+		objectURL = fmt.Sprintf("%s/%s/%s", config.Endpoint, config.Bucket, key)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, objectURL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	payloadHash := sha256.Sum256(data)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+	signAWSRequest(req, data, c.credentials, region, "s3", time.Now())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxArtifactBytes))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("S3 %s %s: status %d", method, key, resp.StatusCode)
+	}
+	return body, nil
+}
+
+// gcsEndpoint returns the Cloud Storage JSON API endpoint
+func gcsEndpoint(config artifactStoreConfig) string {
+	if config.Endpoint != "" {
+		return config.Endpoint
+	}
+	return "https://storage.googleapis.com"
+}
+
+// gcsAccessToken returns the OAuth access token for Cloud Storage: ARTIFACT_STORE_GCS_TOKEN,
+// or the token of the pod's service account from the metadata server (Workload Identity)
+func gcsAccessToken(ctx context.Context) (string, error) {
+	if token := os.Getenv("ARTIFACT_STORE_GCS_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	host := getEnvOrDefault("GCE_METADATA_HOST", "metadata.google.internal")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		"http://"+host+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := (&http.Client{Timeout: artifactRequestTimeout}).Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get a Cloud Storage access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get a Cloud Storage access token: status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil || token.AccessToken == "" {
+		return "", fmt.Errorf("invalid access token response from the metadata server")
+	}
+	return token.AccessToken, nil
+}
+
+// gcsRequest sends an authorized Cloud Storage request and returns the response body
+func gcsRequest(ctx context.Context, method, requestURL, token string, data []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if data != nil {
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	}
+
+	resp, err := (&http.Client{Timeout: artifactRequestTimeout}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxArtifactBytes))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Cloud Storage %s: status %d", method, resp.StatusCode)
+	}
+	return body, nil
+}
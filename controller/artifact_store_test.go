@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// newFakeObjectStore returns an endpoint serving path-style S3 requests signed with the
+// "AKIDS3" credentials and Cloud Storage JSON API requests authorized with "gcs-token"
+func newFakeObjectStore(t *testing.T) *httptest.Server {
+	credentials := awsCredentials{AccessKeyID: "AKIDS3", SecretAccessKey: "s3-secret"}
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+
+		if strings.HasPrefix(r.URL.Path, "/upload/storage/v1/b/") || strings.HasPrefix(r.URL.Path, "/storage/v1/b/") {
+			if r.Header.Get("Authorization") != "Bearer gcs-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.Method == http.MethodPost {
+				bucket := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/upload/storage/v1/b/"), "/o")
+				objects["gs://"+bucket+"/"+r.URL.Query().Get("name")] = body
+				_, _ = w.Write([]byte(`{"kind":"storage#object"}`))
+				return
+			}
+			bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/storage/v1/b/"), "/o/")
+			data, ok := objects["gs://"+bucket+"/"+key]
+			if !ok || r.URL.Query().Get("alt") != "media" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(data)
+			return
+		}
+
+		// Sign the request again with the same time to verify the signature
+		signed := r.Clone(context.Background())
+		signed.Header = r.Header.Clone()
+		signed.URL.Host = r.Host
+		amzDate, _ := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+		signAWSRequest(signed, body, credentials, "eu-west-1", "s3", amzDate)
+		if signed.Header.Get("Authorization") != r.Header.Get("Authorization") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		name := "s3:/" + r.URL.Path
+		switch r.Method {
+		case http.MethodPut:
+			objects[name] = body
+		case http.MethodGet:
+			data, ok := objects[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(data)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestArtifactStore tests uploading outputs to S3 and Cloud Storage and reading them back
+func TestArtifactStore(t *testing.T) {
+	endpoint := newFakeObjectStore(t).URL
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDS3")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "s3-secret")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("ARTIFACT_STORE_GCS_TOKEN", "gcs-token")
+	t.Setenv("ARTIFACT_STORE_BUCKET", "outputs")
+	t.Setenv("ARTIFACT_STORE_ENDPOINT", endpoint)
+
+	task := &mcallv1.McallTask{ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "default"}}
+	task.Status.StartTime = &metav1.Time{Time: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	tests := []struct {
+		storeType string
+		wantURL   string
+	}{
+		{storeType: "s3", wantURL: "s3://outputs/mcall/default/report/report-20250102-030405.txt"},
+		{storeType: "gcs", wantURL: "gs://outputs/mcall/default/report/report-20250102-030405.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.storeType, func(t *testing.T) {
+			t.Setenv("ARTIFACT_STORE_TYPE", tt.storeType)
+			config := getArtifactStoreConfig()
+			url, err := uploadArtifact(context.Background(), config, artifactKey(config, task), []byte("line 1\nline 2\n"))
+			if err != nil {
+				t.Fatalf("uploadArtifact() error = %v", err)
+			}
+			if url != tt.wantURL {
+				t.Errorf("uploadArtifact() = %q, want %q", url, tt.wantURL)
+			}
+			data, err := downloadArtifact(context.Background(), config, url)
+			if err != nil {
+				t.Fatalf("downloadArtifact() error = %v", err)
+			}
+			if string(data) != "line 1\nline 2\n" {
+				t.Errorf("downloadArtifact() = %q", data)
+			}
+			if _, err := downloadArtifact(context.Background(), config, url+".missing"); err == nil {
+				t.Errorf("downloadArtifact() of a missing object succeeded")
+			}
+		})
+	}
+}
+
+// TestResultOutputArtifact tests recording the artifact URL of a truncated output and reading
+// the full output back for InputSources
+func TestResultOutputArtifact(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDS3")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "s3-secret")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("ARTIFACT_STORE_TYPE", "s3")
+	t.Setenv("ARTIFACT_STORE_BUCKET", "outputs")
+	t.Setenv("ARTIFACT_STORE_ENDPOINT", newFakeObjectStore(t).URL)
+	t.Setenv("RESULT_OUTPUT_MAX_BYTES", "100")
+
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	start := metav1.NewTime(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	key := types.NamespacedName{Name: "report", Namespace: "default"}
+	task := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Spec:       mcallv1.McallTaskSpec{Type: "cmd", Input: "seq 1 1000"},
+		Status:     mcallv1.McallTaskStatus{Phase: mcallv1.McallTaskPhaseRunning, StartTime: &start},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&mcallv1.McallTask{}, &mcallv1.McallTaskRun{}).
+		WithObjects(task).
+		Build()
+	r := &McallTaskReconciler{Client: fakeClient, Scheme: scheme}
+
+	if _, err := r.handleRunning(ctx, task); err != nil {
+		t.Fatalf("handleRunning() error = %v", err)
+	}
+
+	var updated mcallv1.McallTask
+	if err := fakeClient.Get(ctx, key, &updated); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	result := updated.Status.Result
+	if result == nil || !result.OutputTruncated || result.OutputURL != "s3://outputs/mcall/default/report/report-20250102-030405.txt" {
+		t.Fatalf("result = %+v, want the artifact URL", result)
+	}
+
+	output, err := r.fullOutput(ctx, key.Namespace, result)
+	if err != nil {
+		t.Fatalf("fullOutput() error = %v", err)
+	}
+	if len(output) != 3893 || !strings.HasSuffix(output, "999\n1000\n") {
+		t.Errorf("fullOutput() has %d bytes", len(output))
+	}
+}
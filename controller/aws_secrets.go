@@ -0,0 +1,297 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// awsRequestTimeout bounds each request to AWS
+const awsRequestTimeout = 10 * time.Second
+
+// awsCredentials are the credentials requests to AWS are signed with
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// awsClient reads secrets and parameters from AWS with Signature Version 4 requests
+type awsClient struct {
+	credentials awsCredentials
+	region      string
+	endpoint    string
+	client      *http.Client
+}
+
+// newAWSClient returns a client for the controller's IRSA role: the projected service account
+// token in AWS_WEB_IDENTITY_TOKEN_FILE is exchanged for temporary credentials of AWS_ROLE_ARN.
+// Without IRSA, the static AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are used.
+func newAWSClient(ctx context.Context) (*awsClient, error) {
+	c := &awsClient{
+		region:   os.Getenv("AWS_REGION"),
+		endpoint: strings.TrimSuffix(os.Getenv("AWS_ENDPOINT_URL"), "/"),
+		client:   &http.Client{Timeout: awsRequestTimeout},
+	}
+	if c.region == "" {
+		c.region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+
+	roleARN, tokenFile := os.Getenv("AWS_ROLE_ARN"), os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	switch {
+	case roleARN != "" && tokenFile != "":
+		token, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read web identity token: %w", err)
+		}
+		if c.credentials, err = c.assumeRoleWithWebIdentity(ctx, roleARN, strings.TrimSpace(string(token))); err != nil {
+			return nil, fmt.Errorf("failed to assume role %s: %w", roleARN, err)
+		}
+	case os.Getenv("AWS_ACCESS_KEY_ID") != "":
+		c.credentials = awsCredentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+	default:
+		return nil, fmt.Errorf("no AWS credentials: set AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE (IRSA) or AWS_ACCESS_KEY_ID")
+	}
+	return c, nil
+}
+
+// serviceURL returns the endpoint of an AWS service in a region
+func (c *awsClient) serviceURL(service, region string) string {
+	if c.endpoint != "" {
+		return c.endpoint + "/"
+	}
+	if region == "" {
+		return fmt.Sprintf("https://%s.amazonaws.com/", service)
+	}
+	return fmt.Sprintf("https://%s.%s.amazonaws.com/", service, region)
+}
+
+// assumeRoleWithWebIdentity exchanges a web identity token for temporary credentials.
+// STS doesn't require this request to be signed.
+func (c *awsClient) assumeRoleWithWebIdentity(ctx context.Context, roleARN, token string) (awsCredentials, error) {
+	form := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {roleARN},
+		"RoleSessionName":  {getEnvOrDefault("AWS_ROLE_SESSION_NAME", "mcall-operator")},
+		"WebIdentityToken": {token},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.serviceURL("sts", c.region), strings.NewReader(form.Encode()))
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	defer resp.Body.Close()
+	payload, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return awsCredentials{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Code    string `xml:"Error>Code"`
+			Message string `xml:"Error>Message"`
+		}
+		if xml.Unmarshal(payload, &failure) == nil && failure.Code != "" {
+			return awsCredentials{}, fmt.Errorf("%s: %s", failure.Code, failure.Message)
+		}
+		return awsCredentials{}, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	var result struct {
+		Credentials struct {
+			AccessKeyID     string `xml:"AccessKeyId"`
+			SecretAccessKey string `xml:"SecretAccessKey"`
+			SessionToken    string `xml:"SessionToken"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	if err := xml.Unmarshal(payload, &result); err != nil || result.Credentials.AccessKeyID == "" {
+		return awsCredentials{}, fmt.Errorf("invalid STS response")
+	}
+	return awsCredentials(result.Credentials), nil
+}
+
+// read returns the value of a Secrets Manager secret or SSM parameter, or nil when it doesn't
+// exist. The whole value is under the empty key; the keys of a JSON object value are added.
+func (c *awsClient) read(ctx context.Context, ref *mcallv1.AWSSecretRef) (map[string]string, error) {
+	region := ref.Region
+	if region == "" {
+		region = c.region
+	}
+	if region == "" {
+		return nil, fmt.Errorf("no AWS region: set aws.region or AWS_REGION")
+	}
+
+	var value string
+	if ref.SecretID != "" {
+		var result struct {
+			SecretString *string `json:"SecretString"`
+		}
+		found, err := c.call(ctx, "secretsmanager", region, "secretsmanager.GetSecretValue",
+			map[string]interface{}{"SecretId": ref.SecretID}, &result)
+		if err != nil || !found {
+			return nil, err
+		}
+		if result.SecretString == nil {
+			return nil, fmt.Errorf("secret %s has a binary value", ref.SecretID)
+		}
+		value = *result.SecretString
+	} else {
+		var result struct {
+			Parameter struct {
+				Value string `json:"Value"`
+			} `json:"Parameter"`
+		}
+		found, err := c.call(ctx, "ssm", region, "AmazonSSM.GetParameter",
+			map[string]interface{}{"Name": ref.Parameter, "WithDecryption": true}, &result)
+		if err != nil || !found {
+			return nil, err
+		}
+		value = result.Parameter.Value
+	}
+
+	data := map[string]string{"": value}
+	var object map[string]interface{}
+	if json.Unmarshal([]byte(value), &object) == nil {
+		for key, field := range object {
+			switch field := field.(type) {
+			case string:
+				data[key] = field
+			case nil:
+			default:
+				encoded, _ := json.Marshal(field)
+				data[key] = string(encoded)
+			}
+		}
+	}
+	return data, nil
+}
+
+// call sends a signed JSON request to an AWS service and decodes its response into out.
+// It returns false when the secret or parameter doesn't exist.
+func (c *awsClient) call(ctx context.Context, service, region, target string, input, out interface{}) (bool, error) {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.serviceURL(service, region), bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+	signAWSRequest(req, body, c.credentials, region, service, time.Now())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	payload, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return false, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(payload, &failure) == nil && failure.Type != "" {
+			// The type may be prefixed with a namespace, e.g. "com.amazon...#ParameterNotFound"
+			errorType := failure.Type[strings.LastIndex(failure.Type, "#")+1:]
+			if errorType == "ResourceNotFoundException" || errorType == "ParameterNotFound" {
+				return false, nil
+			}
+			if failure.Message != "" {
+				return false, fmt.Errorf("%s: %s", errorType, failure.Message)
+			}
+			return false, fmt.Errorf("%s", errorType)
+		}
+		return false, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if err := json.Unmarshal(payload, out); err != nil {
+		return false, fmt.Errorf("invalid %s response: %w", service, err)
+	}
+	return true, nil
+}
+
+// signAWSRequest adds a Signature Version 4 Authorization header to a request, signing
+// its host, Content-Type and X-Amz-* headers
+func signAWSRequest(req *http.Request, body []byte, credentials awsCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "content-type" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	bodyHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method, path, req.URL.Query().Encode(), canonicalHeaders.String(), signedHeaders, hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + credentials.SecretAccessKey)
+	for _, part := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
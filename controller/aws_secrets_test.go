@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestSignAWSRequest tests Signature Version 4 signing with the get-vanilla case of the AWS test suite
+func TestSignAWSRequest(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	credentials := awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signAWSRequest(req, nil, credentials, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %q, want %q", got, want)
+	}
+}
+
+// newFakeAWSServer returns an endpoint for STS, Secrets Manager and SSM. STS exchanges the web
+// identity token "irsa-jwt" for temporary credentials, which the other requests must be signed with.
+func newFakeAWSServer(t *testing.T) *httptest.Server {
+	credentials := awsCredentials{AccessKeyID: "ASIATEMP", SecretAccessKey: "temp-secret", SessionToken: "temp-session"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		target := r.Header.Get("X-Amz-Target")
+		if target == "" {
+			if !strings.Contains(string(body), "WebIdentityToken=irsa-jwt") {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`<ErrorResponse><Error><Code>InvalidIdentityToken</Code><Message>bad token</Message></Error></ErrorResponse>`))
+				return
+			}
+			_, _ = w.Write([]byte(`<AssumeRoleWithWebIdentityResponse><AssumeRoleWithWebIdentityResult><Credentials>` +
+				`<AccessKeyId>ASIATEMP</AccessKeyId><SecretAccessKey>temp-secret</SecretAccessKey><SessionToken>temp-session</SessionToken>` +
+				`</Credentials></AssumeRoleWithWebIdentityResult></AssumeRoleWithWebIdentityResponse>`))
+			return
+		}
+
+		// Sign the request again with the issued credentials and the same time
+		service := strings.SplitN(strings.SplitN(r.Header.Get("Authorization"), "/", 5)[3], "/", 2)[0]
+		signed := r.Clone(context.Background())
+		signed.Header = r.Header.Clone()
+		signed.URL.Host = r.Host
+		amzDate, _ := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+		signAWSRequest(signed, body, credentials, "eu-west-1", service, amzDate)
+		if signed.Header.Get("Authorization") != r.Header.Get("Authorization") {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"__type":"InvalidSignatureException","message":"signature mismatch"}`))
+			return
+		}
+
+		var input map[string]interface{}
+		_ = json.Unmarshal(body, &input)
+		switch {
+		case target == "secretsmanager.GetSecretValue" && input["SecretId"] == "prod/api":
+			_, _ = w.Write([]byte(`{"Name":"prod/api","SecretString":"{\"token\":\"aws-token\",\"port\":443}"}`))
+		case target == "AmazonSSM.GetParameter" && input["Name"] == "/prod/password" && input["WithDecryption"] == true:
+			_, _ = w.Write([]byte(`{"Parameter":{"Name":"/prod/password","Type":"SecureString","Value":"ssm-pass"}}`))
+		case target == "AmazonSSM.GetParameter":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ParameterNotFound"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestResolveSecretRefsAWS tests reading secretRefs from Secrets Manager and SSM with IRSA credentials
+func TestResolveSecretRefsAWS(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("irsa-jwt\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_ENDPOINT_URL", newFakeAWSServer(t).URL)
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_ROLE_ARN", "arn:aws:iam::123456789012:role/mcall")
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", tokenFile)
+	r := &McallTaskReconciler{}
+
+	tests := []struct {
+		name        string
+		refs        []mcallv1.SecretEnvRef
+		wantEnv     map[string]string
+		wantSecrets []string
+		wantErr     string
+	}{
+		{
+			name: "secret keys and parameter",
+			refs: []mcallv1.SecretEnvRef{
+				{Name: "API_TOKEN", AWS: &mcallv1.AWSSecretRef{SecretID: "prod/api"}, Key: "token"},
+				{Name: "API_PORT", AWS: &mcallv1.AWSSecretRef{SecretID: "prod/api"}, Key: "port"},
+				{Name: "PASSWORD", AWS: &mcallv1.AWSSecretRef{Parameter: "/prod/password"}},
+			},
+			wantEnv:     map[string]string{"API_TOKEN": "aws-token", "API_PORT": "443", "PASSWORD": "ssm-pass"},
+			wantSecrets: []string{"aws-token", "443", "ssm-pass"},
+		},
+		{
+			name: "default values",
+			refs: []mcallv1.SecretEnvRef{
+				{Name: "REGION", AWS: &mcallv1.AWSSecretRef{SecretID: "prod/api"}, Key: "region", DefaultValue: "eu"},
+				{Name: "USER", AWS: &mcallv1.AWSSecretRef{Parameter: "/prod/user"}, DefaultValue: "svc"},
+			},
+			wantEnv: map[string]string{"REGION": "eu", "USER": "svc"},
+		},
+		{
+			name:    "missing secret",
+			refs:    []mcallv1.SecretEnvRef{{Name: "TOKEN", AWS: &mcallv1.AWSSecretRef{SecretID: "prod/missing"}, Key: "token"}},
+			wantErr: "secretRef TOKEN: AWS secret prod/missing not found",
+		},
+		{
+			name:    "secretId and parameter",
+			refs:    []mcallv1.SecretEnvRef{{Name: "TOKEN", AWS: &mcallv1.AWSSecretRef{SecretID: "prod/api", Parameter: "/prod/password"}}},
+			wantErr: "set exactly one of aws.secretId and aws.parameter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{Name: "check", Namespace: "default"},
+				Spec:       mcallv1.McallTaskSpec{Type: "cmd", SecretRefs: tt.refs},
+			}
+			environment, secrets, err := r.resolveSecretRefs(context.Background(), task)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveSecretRefs() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveSecretRefs() error = %v", err)
+			}
+			if !reflect.DeepEqual(environment, tt.wantEnv) {
+				t.Errorf("environment = %v, want %v", environment, tt.wantEnv)
+			}
+			if !reflect.DeepEqual(secrets, tt.wantSecrets) {
+				t.Errorf("secrets = %v, want %v", secrets, tt.wantSecrets)
+			}
+		})
+	}
+
+	// A rejected web identity token fails the resolution
+	if err := os.WriteFile(tokenFile, []byte("other-jwt"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	task := &mcallv1.McallTask{Spec: mcallv1.McallTaskSpec{SecretRefs: []mcallv1.SecretEnvRef{
+		{Name: "TOKEN", AWS: &mcallv1.AWSSecretRef{SecretID: "prod/api"}, Key: "token"},
+	}}}
+	if _, _, err := r.resolveSecretRefs(context.Background(), task); err == nil || !strings.Contains(err.Error(), "InvalidIdentityToken: bad token") {
+		t.Errorf("resolveSecretRefs() error = %v, want InvalidIdentityToken", err)
+	}
+}
@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// Command policy ConfigMap keys, each holding one regular expression per line
+const (
+	allowedCommandsKey = "allowedCommands"
+	deniedCommandsKey  = "deniedCommands"
+)
+
+// nonCommandTaskTypes are the task and input types that don't run a command;
+// every other type, including unknown ones, runs its input with the shell
+var nonCommandTaskTypes = map[string]bool{
+	"get": true, "post": true, "graphql": true, "grpc": true, "tcp": true, "sql": true,
+	"kafka": true, "sftp": true, "smtp": true, "websocket": true,
+}
+
+// commandPolicy holds the command patterns allowed and denied by the operator
+type commandPolicy struct {
+	allowed []*regexp.Regexp
+	denied  []*regexp.Regexp
+}
+
+// getCommandPolicyConfigMap returns the name and namespace of the command policy ConfigMap
+func getCommandPolicyConfigMap() (string, string) {
+	return os.Getenv("COMMAND_POLICY_CONFIGMAP"), getEnvOrDefault("NAMESPACE", "mcall-system")
+}
+
+// getCommandPolicy returns the command policy, or nil when none is configured.
+// The ConfigMap is read on every call so that changes take effect without restarting the operator.
+func getCommandPolicy(ctx context.Context, c client.Reader) (*commandPolicy, error) {
+	name, namespace := getCommandPolicyConfigMap()
+	if name == "" {
+		return nil, nil // Command policy not configured
+	}
+
+	var configMap corev1.ConfigMap
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return parseCommandPolicy(configMap.Data[allowedCommandsKey], configMap.Data[deniedCommandsKey])
+}
+
+// parseCommandPolicy compiles the allowed and denied patterns
+func parseCommandPolicy(allowed, denied string) (*commandPolicy, error) {
+	policy := &commandPolicy{}
+	var err error
+	if policy.allowed, err = parsePatternList(allowed); err != nil {
+		return nil, fmt.Errorf("command policy: %w", err)
+	}
+	if policy.denied, err = parsePatternList(denied); err != nil {
+		return nil, fmt.Errorf("command policy: %w", err)
+	}
+	return policy, nil
+}
+
+// parsePatternList compiles one regular expression per line; empty lines and lines
+// starting with # are ignored
+func parsePatternList(list string) ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pattern, err := regexp.Compile(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", line, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// check rejects a command matching a denied pattern, or no allowed pattern when there are any
+func (p *commandPolicy) check(command string) error {
+	for _, pattern := range p.denied {
+		if pattern.MatchString(command) {
+			return fmt.Errorf("command %q is denied by the command policy (%s)", truncateString(command, 100), pattern)
+		}
+	}
+	if len(p.allowed) == 0 {
+		return nil
+	}
+	for _, pattern := range p.allowed {
+		if pattern.MatchString(command) {
+			return nil
+		}
+	}
+	return fmt.Errorf("command %q is not allowed by the command policy", truncateString(command, 100))
+}
+
+// checkTask checks every command a task runs
+func (p *commandPolicy) checkTask(task *mcallv1.McallTask, environment map[string]string, script string) error {
+	if p == nil {
+		return nil
+	}
+	for _, command := range taskCommands(task, environment, script) {
+		if err := p.check(command); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// taskCommands returns the commands a task runs with ${NAME} variables substituted: its cmd
+// inputs, the kubectl-exec command, or the script of scriptRef when it has been read
+func taskCommands(task *mcallv1.McallTask, environment map[string]string, script string) []string {
+	switch {
+	case task.Spec.Type == "cmd" && task.Spec.ScriptRef != nil:
+		if script == "" {
+			return nil
+		}
+		return []string{script}
+	case task.Spec.Type == "cmd":
+		inputs, _ := parseJSONInputs(task.Spec.Input)
+		var commands []string
+		for _, input := range inputs {
+			command, ok := input["input"].(string)
+			inputType, _ := input["type"].(string)
+			if ok && !nonCommandTaskTypes[inputType] {
+				commands = append(commands, interpolateEnvironment(command, environment))
+			}
+		}
+		return commands
+	case !nonCommandTaskTypes[task.Spec.Type]:
+		return []string{interpolateEnvironment(task.Spec.Input, environment)}
+	}
+	return nil
+}
+
+// checkCommandPolicy checks the commands of a task against the command policy just before it runs
+func (r *McallTaskReconciler) checkCommandPolicy(ctx context.Context, task *mcallv1.McallTask, env executionEnv) error {
+	policy, err := getCommandPolicy(ctx, r.Client)
+	if err != nil {
+		return fmt.Errorf("failed to read command policy: %w", err)
+	}
+	return policy.checkTask(task, taskEnvironment(task, env), env.script)
+}
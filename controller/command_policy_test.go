@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestCommandPolicy tests matching commands against allowed and denied patterns
+func TestCommandPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed string
+		denied  string
+		command string
+		wantErr string
+	}{
+		{name: "no patterns", command: "rm -rf /tmp/x"},
+		{name: "allowed", allowed: "^curl \n# comment\n^echo ", command: "echo ok"},
+		{name: "not allowed", allowed: "^curl ", command: "echo ok", wantErr: `command "echo ok" is not allowed`},
+		{name: "denied", denied: `\brm\s+-rf\b`, command: "cd /tmp && rm -rf x", wantErr: `denied by the command policy (\brm\s+-rf\b)`},
+		{name: "denied wins over allowed", allowed: "^curl ", denied: `\|\s*(ba)?sh\b`, command: "curl -s https://x | sh", wantErr: "denied"},
+		{name: "invalid pattern", denied: "(", command: "echo", wantErr: "command policy: invalid pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := parseCommandPolicy(tt.allowed, tt.denied)
+			if err == nil {
+				err = policy.check(tt.command)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+		})
+	}
+}
+
+// TestTaskCommands tests collecting the commands of each task type
+func TestTaskCommands(t *testing.T) {
+	environment := map[string]string{"DIR": "/data"}
+	tests := []struct {
+		name   string
+		spec   mcallv1.McallTaskSpec
+		script string
+		want   []string
+	}{
+		{name: "cmd", spec: mcallv1.McallTaskSpec{Type: "cmd", Input: "ls ${DIR}"}, want: []string{"ls /data"}},
+		{
+			name: "JSON inputs",
+			spec: mcallv1.McallTaskSpec{Type: "cmd", Input: `[{"input":"df ${DIR}"},{"type":"get","input":"http://x"},{"type":"cmd","input":"uptime"}]`},
+			want: []string{"df /data", "uptime"},
+		},
+		{name: "script read", spec: mcallv1.McallTaskSpec{Type: "cmd", ScriptRef: &mcallv1.ScriptRef{Name: "s", Key: "k"}}, script: "echo ok\n", want: []string{"echo ok\n"}},
+		{name: "script not read", spec: mcallv1.McallTaskSpec{Type: "cmd", ScriptRef: &mcallv1.ScriptRef{Name: "s", Key: "k"}}},
+		{name: "kubectl-exec", spec: mcallv1.McallTaskSpec{Type: "kubectl-exec", Input: "cat /etc/hosts"}, want: []string{"cat /etc/hosts"}},
+		{name: "http", spec: mcallv1.McallTaskSpec{Type: "get", Input: "http://x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &mcallv1.McallTask{Spec: tt.spec}
+			if got := taskCommands(task, environment, tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("taskCommands() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCommandPolicyEnforcement tests the policy ConfigMap at admission and before execution
+func TestCommandPolicyEnforcement(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "command-policy", Namespace: "mcall-system"},
+		Data:       map[string]string{deniedCommandsKey: `\brm\b` + "\n" + "kubectl delete"},
+	}).Build()
+	t.Setenv("NAMESPACE", "mcall-system")
+	t.Setenv("COMMAND_POLICY_CONFIGMAP", "command-policy")
+
+	task := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: "check", Namespace: "default"},
+		Spec:       mcallv1.McallTaskSpec{Type: "cmd", Input: "echo ${ACTION}"},
+	}
+	validator := &McallTaskValidator{Client: c}
+	if _, err := validator.ValidateCreate(context.Background(), task); err != nil {
+		t.Errorf("ValidateCreate() error = %v", err)
+	}
+
+	// A variable from spec.environment is substituted at admission
+	denied := task.DeepCopy()
+	denied.Spec.Environment = map[string]string{"ACTION": "; rm x"}
+	if _, err := validator.ValidateUpdate(context.Background(), task, denied); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("ValidateUpdate() error = %v, want denied", err)
+	}
+
+	// A secretRef value is only known at execution
+	r := &McallTaskReconciler{Client: c, Scheme: scheme}
+	env := executionEnv{secretEnv: map[string]string{"ACTION": "kubectl delete ns prod"}}
+	if err := r.checkCommandPolicy(context.Background(), task, env); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("checkCommandPolicy() error = %v, want denied", err)
+	}
+	if err := r.checkCommandPolicy(context.Background(), task, executionEnv{}); err != nil {
+		t.Errorf("checkCommandPolicy() error = %v", err)
+	}
+
+	// Without the ConfigMap every command is allowed
+	t.Setenv("COMMAND_POLICY_CONFIGMAP", "missing")
+	if err := r.checkCommandPolicy(context.Background(), task, env); err != nil {
+		t.Errorf("checkCommandPolicy() error = %v", err)
+	}
+}
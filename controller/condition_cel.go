@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// compileConditionCEL compiles the CEL expression of a task condition, which must evaluate to
+// a bool. Numbers compare across types, so double(output.latency) < 200 needs no 200.0.
+func compileConditionCEL(expression string) (cel.Program, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("result", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("output", cel.DynType),
+		cel.Variable("params", cel.MapType(cel.StringType, cel.StringType)),
+		cel.CrossTypeNumericComparisons(true),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid CEL expression %q: %w", expression, issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("CEL expression %q must evaluate to a bool, got %s", expression, ast.OutputType())
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CEL expression %q: %w", expression, err)
+	}
+	return program, nil
+}
+
+// evaluateConditionCEL evaluates the CEL expression of a task condition against the status of
+// the dependent task and the workflow parameters
+func evaluateConditionCEL(expression string, depTask *mcallv1.McallTask, output string, params map[string]string) (bool, error) {
+	program, err := compileConditionCEL(expression)
+	if err != nil {
+		return false, err
+	}
+
+	result := map[string]string{
+		"output":       output,
+		"phase":        string(depTask.Status.Phase),
+		"errorCode":    "",
+		"errorMessage": "",
+	}
+	if depTask.Status.Result != nil {
+		result["errorCode"] = depTask.Status.Result.ErrorCode
+		result["errorMessage"] = depTask.Status.Result.ErrorMessage
+	}
+	if params == nil {
+		params = map[string]string{}
+	}
+
+	// output is null when the output isn't JSON
+	var data interface{}
+	_ = json.Unmarshal([]byte(output), &data)
+
+	out, _, err := program.Eval(map[string]interface{}{
+		"result": result,
+		"output": data,
+		"params": params,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate CEL expression %q: %w", expression, err)
+	}
+
+	matched, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("CEL expression %q must evaluate to a bool, got %T", expression, out.Value())
+	}
+	return matched, nil
+}
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestConditionCELExpression tests task conditions with CEL expressions
+func TestConditionCELExpression(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+
+	workflow := &mcallv1.McallWorkflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "checks", Namespace: "test-ns"},
+		Spec:       mcallv1.McallWorkflowSpec{Environment: map[string]string{"maxLatency": "200"}},
+	}
+	depTask := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: "checks-probe", Namespace: "test-ns"},
+		Status: mcallv1.McallTaskStatus{
+			Phase:  mcallv1.McallTaskPhaseSucceeded,
+			Result: &mcallv1.McallTaskResult{Output: `{"latency": 150, "status": "ok"}`, ErrorCode: "0"},
+		},
+	}
+	currentTask := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "checks-report",
+			Namespace: "test-ns",
+			Labels:    map[string]string{"mcall.tz.io/workflow": "checks"},
+		},
+	}
+	reconciler := &McallTaskReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(workflow, depTask, currentTask).Build(),
+		Scheme: scheme,
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		want       bool
+	}{
+		{name: "result and output", expression: `result.errorCode == '0' && double(output.latency) < 200`, want: true},
+		{name: "false expression", expression: `double(output.latency) < 100`, want: false},
+		{name: "workflow params", expression: `double(output.latency) < double(params.maxLatency)`, want: true},
+		{name: "phase", expression: `result.phase == 'Succeeded' && output.status == 'ok'`, want: true},
+		{name: "missing output field", expression: `output.missing == 'x'`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition := &mcallv1.TaskCondition{DependentTask: "checks-probe", When: "success", CELExpression: tt.expression}
+			got, err := reconciler.checkTaskCondition(context.Background(), currentTask, condition)
+			if err != nil {
+				t.Fatalf("checkTaskCondition() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("checkTaskCondition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Invalid expressions are rejected at admission
+	for _, expression := range []string{`result.errorCode ==`, `result.errorCode`} {
+		invalid := &mcallv1.McallWorkflow{
+			ObjectMeta: metav1.ObjectMeta{Name: "invalid", Namespace: "test-ns"},
+			Spec: mcallv1.McallWorkflowSpec{Tasks: []mcallv1.WorkflowTaskRef{
+				{Name: "a"},
+				{Name: "b", Condition: &mcallv1.TaskCondition{DependentTask: "a", When: "success", CELExpression: expression}},
+			}},
+		}
+		if _, err := (&McallWorkflowValidator{}).ValidateCreate(context.Background(), invalid); err == nil {
+			t.Errorf("ValidateCreate() with CEL expression %q error = nil", expression)
+		}
+	}
+}
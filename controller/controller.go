@@ -2,14 +2,17 @@ package controller
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/exec"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -19,15 +22,22 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+	"github.com/doohee323/tz-mcall-operator/assertion"
 )
 
 // LogEntry represents a log entry for storage
@@ -36,8 +46,61 @@ type LogEntry struct {
 	ServiceType  string
 	Status       string
 	Error        string
+	ErrorCode    string // "0" on success, "-1" on failure
 	ResponseTime int64
 	Timestamp    time.Time
+	Namespace    string
+	Workflow     string            // Workflow of the task, empty for standalone tasks
+	RunID        string            // Workflow run of the task, empty for standalone tasks
+	Labels       map[string]string // Labels of the task
+
+	// Output is the task output, compressed and base64 encoded when OutputEncoding is set.
+	// OutputTruncated is set when it was cut at LOGGING_OUTPUT_MAX_BYTES.
+	Output          string
+	OutputEncoding  string
+	OutputTruncated bool
+}
+
+// taskLogEntry returns a log entry with the fields identifying a task and its workflow run
+func taskLogEntry(task *mcallv1.McallTask) LogEntry {
+	return LogEntry{
+		Namespace: task.Namespace,
+		Workflow:  task.Labels["mcall.tz.io/workflow"],
+		RunID:     task.Labels["mcall.tz.io/run"],
+		Labels:    task.Labels,
+	}
+}
+
+// logEntryFields returns the fields of an entry as logged by the JSON backends
+func logEntryFields(entry LogEntry) map[string]interface{} {
+	return map[string]interface{}{
+		"service_name":     entry.ServiceName,
+		"service_type":     entry.ServiceType,
+		"namespace":        entry.Namespace,
+		"workflow":         entry.Workflow,
+		"run_id":           entry.RunID,
+		"labels":           entry.Labels,
+		"status":           entry.Status,
+		"error_code":       entry.ErrorCode,
+		"error_message":    entry.Error,
+		"response_time_ms": entry.ResponseTime,
+		"timestamp":        entry.Timestamp,
+		"output":           entry.Output,
+		"output_encoding":  entry.OutputEncoding,
+		"output_truncated": entry.OutputTruncated,
+	}
+}
+
+// logEntryLabels returns the labels of an entry as JSON for the SQL backends, NULL without labels
+func logEntryLabels(entry LogEntry) interface{} {
+	if len(entry.Labels) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(entry.Labels)
+	if err != nil {
+		return nil
+	}
+	return string(data)
 }
 
 // LoggingBackend defines the interface for different logging backends
@@ -51,7 +114,8 @@ type LoggingBackend interface {
 // LoggingConfig represents the logging configuration
 type LoggingConfig struct {
 	Enabled bool
-	Backend string // "postgres", "mysql", "elasticsearch", "kafka"
+	Backend string // Backend built by CreateLoggingBackend: "postgres", "mysql", "elasticsearch", "kafka", "loki", "cloudwatch"
+	Scope   string // Namespace whose McallLoggingConfig overrides the operator configuration, empty otherwise
 
 	// PostgreSQL configuration
 	PostgreSQL struct {
@@ -66,6 +130,10 @@ type LoggingConfig struct {
 			Name       string
 			AutoCreate bool
 		}
+		Retention struct {
+			Days        int  // age after which entries are deleted
+			AutoCleanup bool // delete expired entries periodically
+		}
 	}
 
 	// MySQL configuration
@@ -80,15 +148,27 @@ type LoggingConfig struct {
 			Name       string
 			AutoCreate bool
 		}
+		Retention struct {
+			Days        int  // age after which entries are deleted
+			AutoCleanup bool // delete expired entries periodically
+		}
 	}
 
 	// Elasticsearch configuration
 	Elasticsearch struct {
-		Enabled  bool
-		URL      string
-		Index    string
-		Username string
-		Password string
+		Enabled         bool
+		URL             string
+		Index           string
+		IndexDateFormat string // Go time layout of the date suffix of the index, e.g. "2006.01.02"; none when empty
+		Username        string
+		Password        string
+		APIKey          string        // Base64 encoded API key, used instead of the username and password
+		Template        bool          // put an index template with the mappings of the entries at connect
+		ILMPolicy       string        // ILM policy the index template assigns to new indices
+		BatchSize       int           // entries sent in one bulk request
+		BatchTimeout    time.Duration // longest wait before a partial batch is sent
+		MaxRetries      int           // delivery retries of a failed batch before it is dropped
+		BufferSize      int           // entries buffered while batches are delivered
 	}
 
 	// Kafka configuration
@@ -96,7 +176,69 @@ type LoggingConfig struct {
 		Enabled bool
 		Brokers []string
 		Topic   string
+		TLS     struct {
+			Enabled            bool
+			CAFile             string
+			InsecureSkipVerify bool
+		}
+		SASL struct {
+			Mechanism string // "", "PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512"
+			Username  string
+			Password  string
+		}
+		Compression  string        // "", "gzip", "snappy", "lz4", "zstd"
+		BatchSize    int           // entries sent in one produce request
+		BatchTimeout time.Duration // longest wait before a partial batch is sent
+		MaxRetries   int           // delivery retries of a failed batch before it is dropped
+		BufferSize   int           // entries buffered while batches are delivered
+	}
+
+	// Grafana Loki configuration
+	Loki struct {
+		Enabled  bool
+		URL      string
+		TenantID string // X-Scope-OrgID of multi-tenant Loki
+		Username string
+		Password string
 	}
+
+	// AWS CloudWatch Logs configuration
+	CloudWatch struct {
+		Enabled    bool
+		LogGroup   string
+		LogStream  string
+		Region     string
+		AutoCreate bool // create the log group and stream when they don't exist
+	}
+
+	// Connection pooling of the backends, reused across entries
+	Pool struct {
+		MaxOpenConns        int
+		MaxIdleConns        int
+		ConnMaxLifetime     time.Duration
+		HealthCheckInterval time.Duration
+	}
+
+	// Background queue entries are logged from, so reconciliation doesn't wait on the backends
+	Queue struct {
+		Size          int           // entries buffered; new entries are dropped when it's full
+		BatchSize     int           // entries logged per flush
+		FlushInterval time.Duration // longest wait before a partial batch is logged
+		MaxRetries    int           // retries of the failed backends of an entry before it is dropped
+		RetryBackoff  time.Duration // delay before the first retry, doubled for each one
+	}
+
+	// Interval of the cleanup of expired entries of the SQL backends
+	RetentionInterval time.Duration
+
+	// Output compression configuration
+	Compression struct {
+		Algorithm string // "none", "gzip", "zstd"
+		Threshold int    // outputs smaller than this (bytes) are stored uncompressed
+	}
+
+	// OutputMaxBytes truncates logged outputs, before compression (0 disables it)
+	OutputMaxBytes int
 }
 
 // PostgreSQLBackend implements LoggingBackend for PostgreSQL
@@ -120,23 +262,44 @@ func (p *PostgreSQLBackend) Connect() error {
 	if err != nil {
 		return fmt.Errorf("failed to connect to PostgreSQL: %w", err)
 	}
+	configureLoggingDB(p.db, p.config)
+
+	if err := p.Ping(); err != nil {
+		return err
+	}
+	if p.config.PostgreSQL.Table.AutoCreate {
+		return createLogTable(p.db, p.config.PostgreSQL.Table.Name, postgresLogTableStatements(p.config.PostgreSQL.Table.Name))
+	}
+	return nil
+}
 
-	return p.db.Ping()
+// Ping checks the connection to PostgreSQL
+func (p *PostgreSQLBackend) Ping() error {
+	return pingLoggingDB(p.db)
 }
 
 func (p *PostgreSQLBackend) Log(entry LogEntry) error {
 	query := fmt.Sprintf(`
-		INSERT INTO %s (service_name, service_type, status, error_message, response_time_ms, timestamp)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO %s (service_name, service_type, namespace, workflow, run_id, labels, status, error_code, error_message,
+			response_time_ms, timestamp, output, output_encoding, output_truncated)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`, p.config.PostgreSQL.Table.Name)
 
 	_, err := p.db.Exec(query,
 		entry.ServiceName,
 		entry.ServiceType,
+		entry.Namespace,
+		entry.Workflow,
+		entry.RunID,
+		logEntryLabels(entry),
 		entry.Status,
+		entry.ErrorCode,
 		entry.Error,
 		entry.ResponseTime,
 		entry.Timestamp,
+		entry.Output,
+		entry.OutputEncoding,
+		entry.OutputTruncated,
 	)
 
 	return err
@@ -173,23 +336,47 @@ func (m *MySQLBackend) Connect() error {
 	if err != nil {
 		return fmt.Errorf("failed to connect to MySQL: %w", err)
 	}
+	configureLoggingDB(m.db, m.config)
 
-	return m.db.Ping()
+	if err := m.Ping(); err != nil {
+		return err
+	}
+	if m.config.MySQL.Table.AutoCreate {
+		if err := createLogTable(m.db, m.config.MySQL.Table.Name, mysqlLogTableStatements(m.config.MySQL.Table.Name)); err != nil {
+			return err
+		}
+		return addMySQLLogTableColumns(m.db, m.config.MySQL.Table.Name)
+	}
+	return nil
+}
+
+// Ping checks the connection to MySQL
+func (m *MySQLBackend) Ping() error {
+	return pingLoggingDB(m.db)
 }
 
 func (m *MySQLBackend) Log(entry LogEntry) error {
 	query := fmt.Sprintf(`
-		INSERT INTO %s (service_name, service_type, status, error_message, response_time_ms, timestamp)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO %s (service_name, service_type, namespace, workflow, run_id, labels, status, error_code, error_message,
+			response_time_ms, timestamp, output, output_encoding, output_truncated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, m.config.MySQL.Table.Name)
 
 	_, err := m.db.Exec(query,
 		entry.ServiceName,
 		entry.ServiceType,
+		entry.Namespace,
+		entry.Workflow,
+		entry.RunID,
+		logEntryLabels(entry),
 		entry.Status,
+		entry.ErrorCode,
 		entry.Error,
 		entry.ResponseTime,
 		entry.Timestamp,
+		entry.Output,
+		entry.OutputEncoding,
+		entry.OutputTruncated,
 	)
 
 	return err
@@ -206,16 +393,36 @@ func (m *MySQLBackend) IsEnabled() bool {
 	return m.config.MySQL.Enabled
 }
 
-// ElasticsearchBackend implements LoggingBackend for Elasticsearch
+// ElasticsearchBackend implements LoggingBackend for Elasticsearch. Entries are handed to the
+// shared bulk indexer of the index, which delivers them in batches.
 type ElasticsearchBackend struct {
-	config LoggingConfig
-	client *http.Client
+	config  LoggingConfig
+	client  *http.Client
+	indexer *elasticsearchBulkIndexer
 }
 
 func (e *ElasticsearchBackend) Connect() error {
 	e.client = &http.Client{Timeout: 10 * time.Second}
-	// Test connection
-	resp, err := e.client.Get(e.config.Elasticsearch.URL)
+	if err := e.Ping(); err != nil {
+		return err
+	}
+	if e.config.Elasticsearch.Template {
+		if err := putElasticsearchIndexTemplate(e.client, e.config); err != nil {
+			return err
+		}
+	}
+	e.indexer = getElasticsearchBulkIndexer(e.config)
+	return nil
+}
+
+// Ping checks that Elasticsearch is reachable
+func (e *ElasticsearchBackend) Ping() error {
+	req, err := http.NewRequest(http.MethodGet, e.config.Elasticsearch.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	setElasticsearchAuth(req, e.config)
+	resp, err := e.client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to connect to Elasticsearch: %w", err)
 	}
@@ -230,149 +437,337 @@ func (e *ElasticsearchBackend) Connect() error {
 
 func (e *ElasticsearchBackend) Log(entry LogEntry) error {
 	// Create JSON document
-	doc := map[string]interface{}{
-		"service_name":     entry.ServiceName,
-		"service_type":     entry.ServiceType,
-		"status":           entry.Status,
-		"error_message":    entry.Error,
-		"response_time_ms": entry.ResponseTime,
-		"timestamp":        entry.Timestamp,
+	jsonData, err := json.Marshal(logEntryFields(entry))
+	if err != nil {
+		return fmt.Errorf("failed to marshal log entry: %w", err)
+	}
+
+	return e.indexer.enqueue(elasticsearchDocument{index: elasticsearchIndexName(e.config, entry.Timestamp), source: jsonData})
+}
+
+func (e *ElasticsearchBackend) Close() error {
+	// The bulk indexer is shared and keeps delivering buffered entries
+	return nil
+}
+
+func (e *ElasticsearchBackend) IsEnabled() bool {
+	return e.config.Elasticsearch.Enabled
+}
+
+// KafkaBackend implements LoggingBackend for Kafka. Entries are handed to the shared
+// producer of the topic, which delivers them in batches.
+type KafkaBackend struct {
+	config   LoggingConfig
+	producer *kafkaLogProducer
+}
+
+func (k *KafkaBackend) Connect() error {
+	var err error
+	k.producer, err = getKafkaLogProducer(k.config)
+	return err
+}
+
+func (k *KafkaBackend) Log(entry LogEntry) error {
+	// Create JSON message
+	jsonData, err := json.Marshal(logEntryFields(entry))
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	return k.producer.enqueue(jsonData)
+}
+
+func (k *KafkaBackend) Close() error {
+	// The producer is shared and keeps its connections open until the logging is reloaded
+	return nil
+}
+
+func (k *KafkaBackend) IsEnabled() bool {
+	return k.config.Kafka.Enabled
+}
+
+// LokiBackend implements LoggingBackend for Grafana Loki. Entries are pushed as JSON lines
+// labeled with the task, workflow, namespace, type and status.
+type LokiBackend struct {
+	config LoggingConfig
+	client *http.Client
+}
+
+func (l *LokiBackend) Connect() error {
+	l.client = &http.Client{Timeout: 10 * time.Second}
+	return nil
+}
+
+func (l *LokiBackend) Log(entry LogEntry) error {
+	// High-cardinality fields like the run ID stay in the line rather than the labels
+	fields := logEntryFields(entry)
+	delete(fields, "timestamp") // Timestamp of the line
+	line, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to marshal log line: %w", err)
 	}
 
-	jsonData, err := json.Marshal(doc)
+	labels := map[string]string{
+		"job":       "mcall-operator",
+		"task":      entry.ServiceName,
+		"namespace": entry.Namespace,
+		"type":      entry.ServiceType,
+		"status":    entry.Status,
+	}
+	if entry.Workflow != "" {
+		labels["workflow"] = entry.Workflow
+	}
+	push, err := json.Marshal(map[string]interface{}{
+		"streams": []map[string]interface{}{{
+			"stream": labels,
+			"values": [][]string{{strconv.FormatInt(entry.Timestamp.UnixNano(), 10), string(line)}},
+		}},
+	})
 	if err != nil {
-		return fmt.Errorf("failed to marshal log entry: %w", err)
+		return fmt.Errorf("failed to marshal push request: %w", err)
 	}
 
-	// Send to Elasticsearch
-	url := fmt.Sprintf("%s/%s/_doc", e.config.Elasticsearch.URL, e.config.Elasticsearch.Index)
-	req, err := http.NewRequest("POST", url, strings.NewReader(string(jsonData)))
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(l.config.Loki.URL, "/")+"/loki/api/v1/push", strings.NewReader(string(push)))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
-
 	req.Header.Set("Content-Type", "application/json")
+	if l.config.Loki.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", l.config.Loki.TenantID)
+	}
+	if l.config.Loki.Username != "" {
+		req.SetBasicAuth(l.config.Loki.Username, l.config.Loki.Password)
+	}
 
-	resp, err := e.client.Do(req)
+	resp, err := l.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send to Elasticsearch: %w", err)
+		return fmt.Errorf("failed to push to Loki: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		return fmt.Errorf("Elasticsearch request failed with status: %d", resp.StatusCode)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("Loki push failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
 	}
 
 	return nil
 }
 
-func (e *ElasticsearchBackend) Close() error {
+func (l *LokiBackend) Close() error {
 	// HTTP client doesn't need explicit closing
 	return nil
 }
 
-func (e *ElasticsearchBackend) IsEnabled() bool {
-	return e.config.Elasticsearch.Enabled
+func (l *LokiBackend) IsEnabled() bool {
+	return l.config.Loki.Enabled
 }
 
-// KafkaBackend implements LoggingBackend for Kafka
-type KafkaBackend struct {
+// CloudWatchBackend implements LoggingBackend for AWS CloudWatch Logs, authenticating
+// with IRSA or static credentials like the AWS secret sources
+type CloudWatchBackend struct {
 	config LoggingConfig
-	// In a real implementation, you would use a Kafka client library
-	// For now, we'll use HTTP for simplicity
-	client *http.Client
+	client *awsClient
 }
 
-func (k *KafkaBackend) Connect() error {
-	k.client = &http.Client{Timeout: 10 * time.Second}
-	// Kafka connection logic would go here
-	// For now, we'll assume connection is successful
+func (c *CloudWatchBackend) Connect() error {
+	var err error
+	c.client, err = newAWSClient(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to connect to CloudWatch Logs: %w", err)
+	}
+	if c.config.CloudWatch.Region == "" {
+		c.config.CloudWatch.Region = c.client.region
+	}
+	if c.config.CloudWatch.Region == "" {
+		return fmt.Errorf("no CloudWatch Logs region: set LOGGING_CLOUDWATCH_REGION or AWS_REGION")
+	}
 	return nil
 }
 
-func (k *KafkaBackend) Log(entry LogEntry) error {
-	// Create JSON message
-	message := map[string]interface{}{
-		"service_name":     entry.ServiceName,
-		"service_type":     entry.ServiceType,
-		"status":           entry.Status,
-		"error_message":    entry.Error,
-		"response_time_ms": entry.ResponseTime,
-		"timestamp":        entry.Timestamp,
+func (c *CloudWatchBackend) Log(entry LogEntry) error {
+	fields := logEntryFields(entry)
+	delete(fields, "timestamp") // Timestamp of the log event
+	message, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to marshal log event: %w", err)
 	}
 
-	jsonData, err := json.Marshal(message)
+	ctx := context.Background()
+	input := map[string]interface{}{
+		"logGroupName":  c.config.CloudWatch.LogGroup,
+		"logStreamName": c.config.CloudWatch.LogStream,
+		"logEvents":     []map[string]interface{}{{"timestamp": entry.Timestamp.UnixMilli(), "message": string(message)}},
+	}
+	var result struct {
+		RejectedLogEventsInfo map[string]interface{} `json:"rejectedLogEventsInfo"`
+	}
+	found, err := c.client.call(ctx, "logs", c.config.CloudWatch.Region, "Logs_20140328.PutLogEvents", input, &result)
+	if err == nil && !found {
+		if !c.config.CloudWatch.AutoCreate {
+			return fmt.Errorf("CloudWatch log stream %s/%s not found", c.config.CloudWatch.LogGroup, c.config.CloudWatch.LogStream)
+		}
+		if err := c.createLogStream(ctx); err != nil {
+			return err
+		}
+		found, err = c.client.call(ctx, "logs", c.config.CloudWatch.Region, "Logs_20140328.PutLogEvents", input, &result)
+		if err == nil && !found {
+			err = fmt.Errorf("log stream not found after creating it")
+		}
+	}
 	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
+		return fmt.Errorf("failed to put CloudWatch log event: %w", err)
 	}
+	if len(result.RejectedLogEventsInfo) > 0 {
+		return fmt.Errorf("CloudWatch rejected the log event: %v", result.RejectedLogEventsInfo)
+	}
+	return nil
+}
 
-	// In a real implementation, you would send to Kafka
-	// For now, we'll log the message
-	fmt.Printf("Kafka message: %s\n", string(jsonData))
-
+// createLogStream creates the log group and log stream, tolerating existing ones
+func (c *CloudWatchBackend) createLogStream(ctx context.Context) error {
+	var result struct{}
+	for _, step := range []struct {
+		target string
+		input  map[string]interface{}
+	}{
+		{"Logs_20140328.CreateLogGroup", map[string]interface{}{"logGroupName": c.config.CloudWatch.LogGroup}},
+		{"Logs_20140328.CreateLogStream", map[string]interface{}{
+			"logGroupName": c.config.CloudWatch.LogGroup, "logStreamName": c.config.CloudWatch.LogStream}},
+	} {
+		_, err := c.client.call(ctx, "logs", c.config.CloudWatch.Region, step.target, step.input, &result)
+		if err != nil && !strings.HasPrefix(err.Error(), "ResourceAlreadyExistsException") {
+			return fmt.Errorf("failed to create CloudWatch log stream %s/%s: %w",
+				c.config.CloudWatch.LogGroup, c.config.CloudWatch.LogStream, err)
+		}
+	}
 	return nil
 }
 
-func (k *KafkaBackend) Close() error {
-	// Kafka client cleanup would go here
+func (c *CloudWatchBackend) Close() error {
+	// HTTP client doesn't need explicit closing
 	return nil
 }
 
-func (k *KafkaBackend) IsEnabled() bool {
-	return k.config.Kafka.Enabled
+func (c *CloudWatchBackend) IsEnabled() bool {
+	return c.config.CloudWatch.Enabled
 }
 
-// GetLoggingConfig returns the logging configuration from environment variables
+// GetLoggingConfig returns the logging configuration from environment variables, overridden by
+// the settings of the watched logging ConfigMap and Secret
 func GetLoggingConfig() LoggingConfig {
 	config := LoggingConfig{}
 
-	// Check if logging is enabled
-	config.Enabled = os.Getenv("LOGGING_ENABLED") == "true"
-	if !config.Enabled {
-		return config
-	}
+	// Check if logging is enabled. The backends are loaded either way, as a McallLoggingConfig
+	// may enable logging.
+	config.Enabled = loggingGetenv("LOGGING_ENABLED") == "true"
 
 	// Get backend type
-	config.Backend = getEnvOrDefault("LOGGING_BACKEND", "postgres")
+	config.Backend = loggingEnvOrDefault("LOGGING_BACKEND", "postgres")
 
 	// PostgreSQL configuration
-	config.PostgreSQL.Enabled = os.Getenv("LOGGING_POSTGRESQL_ENABLED") == "true"
-	config.PostgreSQL.Host = getEnvOrDefault("LOGGING_POSTGRESQL_HOST", "devops-postgres-postgresql.devops.svc.cluster.local")
-	config.PostgreSQL.Port = getEnvIntOrDefault("LOGGING_POSTGRESQL_PORT", 5432)
-	config.PostgreSQL.Username = getEnvOrDefault("LOGGING_POSTGRESQL_USERNAME", "admin")
-	config.PostgreSQL.Password = getEnvOrDefault("LOGGING_POSTGRES_PASSWORD", "")
-	config.PostgreSQL.Database = getEnvOrDefault("LOGGING_POSTGRESQL_DATABASE", "mcall_logs")
-	config.PostgreSQL.SSLMode = getEnvOrDefault("LOGGING_POSTGRESQL_SSLMODE", "disable")
-	config.PostgreSQL.Table.Name = getEnvOrDefault("LOGGING_POSTGRESQL_TABLE_NAME", "monitoring_logs")
-	config.PostgreSQL.Table.AutoCreate = os.Getenv("LOGGING_POSTGRESQL_TABLE_AUTOCREATE") == "true"
+	config.PostgreSQL.Enabled = loggingGetenv("LOGGING_POSTGRESQL_ENABLED") == "true"
+	config.PostgreSQL.Host = loggingEnvOrDefault("LOGGING_POSTGRESQL_HOST", "devops-postgres-postgresql.devops.svc.cluster.local")
+	config.PostgreSQL.Port = loggingEnvIntOrDefault("LOGGING_POSTGRESQL_PORT", 5432)
+	config.PostgreSQL.Username = loggingEnvOrDefault("LOGGING_POSTGRESQL_USERNAME", "admin")
+	config.PostgreSQL.Password = loggingEnvOrDefault("LOGGING_POSTGRES_PASSWORD", "")
+	config.PostgreSQL.Database = loggingEnvOrDefault("LOGGING_POSTGRESQL_DATABASE", "mcall_logs")
+	config.PostgreSQL.SSLMode = loggingEnvOrDefault("LOGGING_POSTGRESQL_SSLMODE", "disable")
+	config.PostgreSQL.Table.Name = loggingEnvOrDefault("LOGGING_POSTGRESQL_TABLE_NAME", "monitoring_logs")
+	config.PostgreSQL.Table.AutoCreate = loggingGetenv("LOGGING_POSTGRESQL_TABLE_AUTOCREATE") == "true"
+	config.PostgreSQL.Retention.Days = loggingEnvIntOrDefault("LOGGING_POSTGRESQL_RETENTION_DAYS", 30)
+	config.PostgreSQL.Retention.AutoCleanup = loggingGetenv("LOGGING_POSTGRESQL_RETENTION_AUTO_CLEANUP") == "true"
 
 	// MySQL configuration
-	config.MySQL.Enabled = os.Getenv("LOGGING_MYSQL_ENABLED") == "true"
-	config.MySQL.Host = getEnvOrDefault("LOGGING_MYSQL_HOST", "localhost")
-	config.MySQL.Port = getEnvIntOrDefault("LOGGING_MYSQL_PORT", 3306)
-	config.MySQL.Username = getEnvOrDefault("LOGGING_MYSQL_USERNAME", "root")
-	config.MySQL.Password = getEnvOrDefault("LOGGING_MYSQL_PASSWORD", "")
-	config.MySQL.Database = getEnvOrDefault("LOGGING_MYSQL_DATABASE", "mcall_logs")
-	config.MySQL.Table.Name = getEnvOrDefault("LOGGING_MYSQL_TABLE_NAME", "monitoring_logs")
-	config.MySQL.Table.AutoCreate = os.Getenv("LOGGING_MYSQL_TABLE_AUTOCREATE") == "true"
+	config.MySQL.Enabled = loggingGetenv("LOGGING_MYSQL_ENABLED") == "true"
+	config.MySQL.Host = loggingEnvOrDefault("LOGGING_MYSQL_HOST", "localhost")
+	config.MySQL.Port = loggingEnvIntOrDefault("LOGGING_MYSQL_PORT", 3306)
+	config.MySQL.Username = loggingEnvOrDefault("LOGGING_MYSQL_USERNAME", "root")
+	config.MySQL.Password = loggingEnvOrDefault("LOGGING_MYSQL_PASSWORD", "")
+	config.MySQL.Database = loggingEnvOrDefault("LOGGING_MYSQL_DATABASE", "mcall_logs")
+	config.MySQL.Table.Name = loggingEnvOrDefault("LOGGING_MYSQL_TABLE_NAME", "monitoring_logs")
+	config.MySQL.Table.AutoCreate = loggingGetenv("LOGGING_MYSQL_TABLE_AUTOCREATE") == "true"
+	config.MySQL.Retention.Days = loggingEnvIntOrDefault("LOGGING_MYSQL_RETENTION_DAYS", 30)
+	config.MySQL.Retention.AutoCleanup = loggingGetenv("LOGGING_MYSQL_RETENTION_AUTO_CLEANUP") == "true"
 
 	// Elasticsearch configuration
-	config.Elasticsearch.Enabled = os.Getenv("LOGGING_ELASTICSEARCH_ENABLED") == "true"
-	config.Elasticsearch.URL = getEnvOrDefault("LOGGING_ELASTICSEARCH_URL", "http://localhost:9200")
-	config.Elasticsearch.Index = getEnvOrDefault("LOGGING_ELASTICSEARCH_INDEX", "mcall-logs")
-	config.Elasticsearch.Username = getEnvOrDefault("LOGGING_ELASTICSEARCH_USERNAME", "")
-	config.Elasticsearch.Password = getEnvOrDefault("LOGGING_ELASTICSEARCH_PASSWORD", "")
+	config.Elasticsearch.Enabled = loggingGetenv("LOGGING_ELASTICSEARCH_ENABLED") == "true"
+	config.Elasticsearch.URL = loggingEnvOrDefault("LOGGING_ELASTICSEARCH_URL", "http://localhost:9200")
+	config.Elasticsearch.Index = loggingEnvOrDefault("LOGGING_ELASTICSEARCH_INDEX", "mcall-logs")
+	config.Elasticsearch.Username = loggingEnvOrDefault("LOGGING_ELASTICSEARCH_USERNAME", "")
+	config.Elasticsearch.Password = loggingEnvOrDefault("LOGGING_ELASTICSEARCH_PASSWORD", "")
+	config.Elasticsearch.IndexDateFormat = loggingGetenv("LOGGING_ELASTICSEARCH_INDEX_DATE_FORMAT")
+	config.Elasticsearch.APIKey = loggingGetenv("LOGGING_ELASTICSEARCH_API_KEY")
+	config.Elasticsearch.Template = loggingEnvOrDefault("LOGGING_ELASTICSEARCH_TEMPLATE", "true") == "true"
+	config.Elasticsearch.ILMPolicy = loggingGetenv("LOGGING_ELASTICSEARCH_ILM_POLICY")
+	config.Elasticsearch.BatchSize = loggingEnvIntOrDefault("LOGGING_ELASTICSEARCH_BATCH_SIZE", 100)
+	config.Elasticsearch.BatchTimeout = time.Duration(loggingEnvIntOrDefault("LOGGING_ELASTICSEARCH_BATCH_TIMEOUT_MS", 1000)) * time.Millisecond
+	config.Elasticsearch.MaxRetries = loggingEnvIntOrDefault("LOGGING_ELASTICSEARCH_MAX_RETRIES", 3)
+	config.Elasticsearch.BufferSize = loggingEnvIntOrDefault("LOGGING_ELASTICSEARCH_BUFFER_SIZE", 10000)
 
 	// Kafka configuration
-	config.Kafka.Enabled = os.Getenv("LOGGING_KAFKA_ENABLED") == "true"
-	config.Kafka.Topic = getEnvOrDefault("LOGGING_KAFKA_TOPIC", "mcall-logs")
-	// Kafka brokers would be parsed from environment variable
-	brokersStr := getEnvOrDefault("LOGGING_KAFKA_BROKERS", "localhost:9092")
+	config.Kafka.Enabled = loggingGetenv("LOGGING_KAFKA_ENABLED") == "true"
+	config.Kafka.Topic = loggingEnvOrDefault("LOGGING_KAFKA_TOPIC", "mcall-logs")
+	brokersStr := loggingEnvOrDefault("LOGGING_KAFKA_BROKERS", "localhost:9092")
 	config.Kafka.Brokers = strings.Split(brokersStr, ",")
+	config.Kafka.TLS.Enabled = loggingGetenv("LOGGING_KAFKA_TLS_ENABLED") == "true"
+	config.Kafka.TLS.CAFile = loggingGetenv("LOGGING_KAFKA_TLS_CA_FILE")
+	config.Kafka.TLS.InsecureSkipVerify = loggingGetenv("LOGGING_KAFKA_TLS_INSECURE_SKIP_VERIFY") == "true"
+	config.Kafka.SASL.Mechanism = strings.ToUpper(loggingGetenv("LOGGING_KAFKA_SASL_MECHANISM"))
+	config.Kafka.SASL.Username = loggingGetenv("LOGGING_KAFKA_SASL_USERNAME")
+	config.Kafka.SASL.Password = loggingGetenv("LOGGING_KAFKA_SASL_PASSWORD")
+	config.Kafka.Compression = strings.ToLower(loggingGetenv("LOGGING_KAFKA_COMPRESSION"))
+	config.Kafka.BatchSize = loggingEnvIntOrDefault("LOGGING_KAFKA_BATCH_SIZE", 100)
+	config.Kafka.BatchTimeout = time.Duration(loggingEnvIntOrDefault("LOGGING_KAFKA_BATCH_TIMEOUT_MS", 1000)) * time.Millisecond
+	config.Kafka.MaxRetries = loggingEnvIntOrDefault("LOGGING_KAFKA_MAX_RETRIES", 3)
+	config.Kafka.BufferSize = loggingEnvIntOrDefault("LOGGING_KAFKA_BUFFER_SIZE", 10000)
+
+	// Grafana Loki configuration
+	config.Loki.Enabled = loggingGetenv("LOGGING_LOKI_ENABLED") == "true"
+	config.Loki.URL = loggingEnvOrDefault("LOGGING_LOKI_URL", "http://localhost:3100")
+	config.Loki.TenantID = loggingEnvOrDefault("LOGGING_LOKI_TENANT_ID", "")
+	config.Loki.Username = loggingEnvOrDefault("LOGGING_LOKI_USERNAME", "")
+	config.Loki.Password = loggingEnvOrDefault("LOGGING_LOKI_PASSWORD", "")
+
+	// AWS CloudWatch Logs configuration
+	config.CloudWatch.Enabled = loggingGetenv("LOGGING_CLOUDWATCH_ENABLED") == "true"
+	config.CloudWatch.LogGroup = loggingEnvOrDefault("LOGGING_CLOUDWATCH_LOG_GROUP", "/mcall/task-results")
+	config.CloudWatch.LogStream = loggingEnvOrDefault("LOGGING_CLOUDWATCH_LOG_STREAM", "mcall-operator")
+	config.CloudWatch.Region = loggingEnvOrDefault("LOGGING_CLOUDWATCH_REGION", "")
+	config.CloudWatch.AutoCreate = loggingEnvOrDefault("LOGGING_CLOUDWATCH_AUTO_CREATE", "true") == "true"
+
+	// Connection pooling configuration
+	config.Pool.MaxOpenConns = loggingEnvIntOrDefault("LOGGING_POOL_MAX_OPEN_CONNS", 10)
+	config.Pool.MaxIdleConns = loggingEnvIntOrDefault("LOGGING_POOL_MAX_IDLE_CONNS", 5)
+	config.Pool.ConnMaxLifetime = time.Duration(loggingEnvIntOrDefault("LOGGING_POOL_CONN_MAX_LIFETIME_SECONDS", 300)) * time.Second
+	config.Pool.HealthCheckInterval = time.Duration(loggingEnvIntOrDefault("LOGGING_HEALTH_CHECK_INTERVAL_SECONDS", 30)) * time.Second
+	if config.Pool.HealthCheckInterval <= 0 {
+		config.Pool.HealthCheckInterval = 30 * time.Second
+	}
+
+	// Logging queue configuration
+	config.Queue.Size = loggingEnvIntOrDefault("LOGGING_QUEUE_SIZE", 1000)
+	config.Queue.BatchSize = loggingEnvIntOrDefault("LOGGING_QUEUE_BATCH_SIZE", 50)
+	config.Queue.FlushInterval = time.Duration(loggingEnvIntOrDefault("LOGGING_QUEUE_FLUSH_INTERVAL_MS", 1000)) * time.Millisecond
+	config.Queue.MaxRetries = loggingEnvIntOrDefault("LOGGING_QUEUE_MAX_RETRIES", 3)
+	config.Queue.RetryBackoff = time.Duration(loggingEnvIntOrDefault("LOGGING_QUEUE_RETRY_BACKOFF_MS", 1000)) * time.Millisecond
+
+	// Retention cleanup configuration
+	config.RetentionInterval = time.Duration(loggingEnvIntOrDefault("LOGGING_RETENTION_INTERVAL_MINUTES", 60)) * time.Minute
+	if config.RetentionInterval <= 0 {
+		config.RetentionInterval = time.Hour
+	}
+
+	// Output compression configuration
+	config.Compression.Algorithm = loggingEnvOrDefault("LOGGING_COMPRESSION_ALGORITHM", "none")
+	config.Compression.Threshold = loggingEnvIntOrDefault("LOGGING_COMPRESSION_THRESHOLD", 1024)
+	config.OutputMaxBytes = loggingEnvIntOrDefault("LOGGING_OUTPUT_MAX_BYTES", 64*1024)
 
 	return config
 }
 
+// debugLevel is the verbosity of the logs of every reconcile and task input, shown with LOG_LEVEL=debug
+const debugLevel = 1
+
 // getEnvOrDefault returns environment variable value or default value
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -402,40 +797,112 @@ func CreateLoggingBackend(config LoggingConfig) (LoggingBackend, error) {
 		return &ElasticsearchBackend{config: config}, nil
 	case "kafka":
 		return &KafkaBackend{config: config}, nil
+	case "loki":
+		return &LokiBackend{config: config}, nil
+	case "cloudwatch":
+		return &CloudWatchBackend{config: config}, nil
 	default:
 		return nil, fmt.Errorf("unsupported logging backend: %s", config.Backend)
 	}
 }
 
-// LogToBackend logs the task execution result to the configured backend
+// loggingBackendNames lists the logging backends LogToBackend fans out to
+var loggingBackendNames = []string{"postgres", "mysql", "elasticsearch", "kafka", "loki", "cloudwatch"}
+
+// enabledLoggingBackends returns the names of the backends whose configuration is enabled
+func enabledLoggingBackends(config LoggingConfig) []string {
+	var names []string
+	for _, name := range loggingBackendNames {
+		config.Backend = name
+		if backend, err := CreateLoggingBackend(config); err == nil && backend.IsEnabled() {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// LogToBackend logs the task execution result to every enabled backend concurrently.
+// A failing backend doesn't keep the entry from the others; their errors are joined.
 func LogToBackend(logEntry LogEntry, config LoggingConfig) error {
 	if !config.Enabled {
 		return nil // Logging disabled
 	}
 
-	// Create backend
-	backend, err := CreateLoggingBackend(config)
+	logEntry, err := prepareLogEntry(logEntry, config)
 	if err != nil {
-		return fmt.Errorf("failed to create logging backend: %w", err)
+		return err
 	}
+	_, err = logToBackends(logEntry, config, enabledLoggingBackends(config))
+	return err
+}
+
+// prepareLogEntry truncates and compresses large outputs before persisting
+func prepareLogEntry(logEntry LogEntry, config LoggingConfig) (LogEntry, error) {
+	if logEntry.OutputEncoding == OutputEncodingNone {
+		var truncated bool
+		logEntry.Output, truncated = truncateOutput(logEntry.Output, config.OutputMaxBytes)
+		logEntry.OutputTruncated = logEntry.OutputTruncated || truncated
 
-	// Check if backend is enabled
-	if !backend.IsEnabled() {
-		return nil // Backend disabled
+		output, encoding, err := compressOutput(logEntry.Output, config.Compression.Algorithm, config.Compression.Threshold)
+		if err != nil {
+			return logEntry, fmt.Errorf("failed to compress output: %w", err)
+		}
+		logEntry.Output = output
+		logEntry.OutputEncoding = encoding
 	}
+	return logEntry, nil
+}
+
+// logToBackends logs an entry to the named backends concurrently and returns the ones that failed
+func logToBackends(logEntry LogEntry, config LoggingConfig, names []string) ([]string, error) {
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					errs[i] = fmt.Errorf("%s: logging backend panicked: %v", name, r)
+				}
+			}()
 
-	// Connect to backend
-	if err := backend.Connect(); err != nil {
-		return fmt.Errorf("failed to connect to logging backend: %w", err)
+			// Reuse the pooled connection of the backend
+			pool := loggingPoolFor(config)
+			backend, err := pool.get(config, name)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: failed to connect to logging backend: %w", name, err)
+				return
+			}
+			if err := backend.Log(logEntry); err != nil {
+				pool.check(name, backend)
+				errs[i] = fmt.Errorf("%s: failed to log entry: %w", name, err)
+			}
+		}(i, name)
 	}
-	defer backend.Close()
+	wg.Wait()
 
-	// Log the entry
-	if err := backend.Log(logEntry); err != nil {
-		return fmt.Errorf("failed to log entry: %w", err)
+	var failed []string
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, names[i])
+		}
 	}
+	return failed, errors.Join(errs...)
+}
 
-	return nil
+// configureLoggingDB applies the connection pool settings to a logging database
+func configureLoggingDB(db *sql.DB, config LoggingConfig) {
+	db.SetMaxOpenConns(config.Pool.MaxOpenConns)
+	db.SetMaxIdleConns(config.Pool.MaxIdleConns)
+	db.SetConnMaxLifetime(config.Pool.ConnMaxLifetime)
+}
+
+// pingLoggingDB checks a logging database connection within a timeout
+func pingLoggingDB(db *sql.DB) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return db.PingContext(ctx)
 }
 
 // getReconcileInterval returns the reconcile interval from environment variable
@@ -468,23 +935,140 @@ func getTaskTimeout() time.Duration {
 	return time.Duration(timeout) * time.Second
 }
 
+// getTaskTimeoutFor returns the task's own timeout, falling back to the TASK_TIMEOUT default
+func getTaskTimeoutFor(task *mcallv1.McallTask) time.Duration {
+	if task.Spec.Timeout > 0 {
+		return time.Duration(task.Spec.Timeout) * time.Second
+	}
+	return getTaskTimeout()
+}
+
+// isFailureInjectionEnabled returns whether failure injection annotations are honored
+func isFailureInjectionEnabled() bool {
+	return os.Getenv("FAILURE_INJECTION_ENABLED") == "true"
+}
+
+// applyFailureInjection applies the failure injection annotation of a task (chaos testing).
+// Delays are applied in place; a non-nil error means execution should be replaced by the injected failure.
+func applyFailureInjection(task *mcallv1.McallTask, timeout time.Duration, logger logr.Logger) error {
+	injectionStr, exists := task.Annotations[mcallv1.FailureInjectionAnnotation]
+	if !exists || injectionStr == "" {
+		return nil
+	}
+	if !isFailureInjectionEnabled() {
+		logger.Info("Failure injection disabled, ignoring annotation", "task", task.Name)
+		return nil
+	}
+
+	var injection mcallv1.FailureInjection
+	if err := json.Unmarshal([]byte(injectionStr), &injection); err != nil {
+		logger.Error(err, "Failed to parse failure injection, ignoring", "task", task.Name)
+		return nil
+	}
+
+	logger.Info("Injecting failure", "task", task.Name, "action", injection.Action)
+
+	switch injection.Action {
+	case mcallv1.FailureInjectionFail:
+		message := injection.Message
+		if message == "" {
+			message = "injected failure"
+		}
+		return fmt.Errorf("%s", message)
+	case mcallv1.FailureInjectionDelay:
+		time.Sleep(time.Duration(injection.DelaySeconds) * time.Second)
+		return nil
+	case mcallv1.FailureInjectionTimeout:
+		time.Sleep(timeout)
+		return fmt.Errorf("command execution timed out (injected)")
+	default:
+		logger.Info("Unknown failure injection action, ignoring", "task", task.Name, "action", injection.Action)
+		return nil
+	}
+}
+
+// commandOptions holds how cmd inputs and scripts are run
+type commandOptions struct {
+	Shell      string   // sh, bash or pwsh (default: bash)
+	WorkingDir string   // Directory the command runs in, empty for the controller's
+	Stdin      string   // Standard input of the command, empty for none
+	Env        []string // NAME=value pairs added to the controller's environment
+}
+
+// commandOptionsFor returns the command options from a task spec and its execution environment
+func commandOptionsFor(task *mcallv1.McallTask, environment map[string]string) commandOptions {
+	opts := commandOptions{Shell: task.Spec.Shell, WorkingDir: task.Spec.WorkingDir}
+	for name, value := range environment {
+		opts.Env = append(opts.Env, name+"="+value)
+	}
+	sort.Strings(opts.Env)
+	return opts
+}
+
+// interpolateEnvironment replaces ${NAME} in an input with the task environment variable
+// NAME. Other placeholders are kept, so the shell can still expand its own variables.
+func interpolateEnvironment(input string, environment map[string]string) string {
+	if len(environment) == 0 {
+		return input
+	}
+	replacements := make([]string, 0, 2*len(environment))
+	for name, value := range environment {
+		replacements = append(replacements, "${"+name+"}", value)
+	}
+	// A single pass, values containing placeholders aren't expanded again
+	return strings.NewReplacer(replacements...).Replace(input)
+}
+
+// shellCommand returns the shell invocation running a command string, and the one running a script file
+func shellCommand(shell string) (command []string, script []string, err error) {
+	switch shell {
+	case "", "bash":
+		return []string{"/bin/bash", "-c"}, []string{"/bin/bash"}, nil
+	case "sh":
+		return []string{"/bin/sh", "-c"}, []string{"/bin/sh"}, nil
+	case "pwsh":
+		pwsh := []string{"pwsh", "-NoLogo", "-NoProfile", "-NonInteractive"}
+		return append(pwsh, "-Command"), append(pwsh, "-File"), nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported shell %q, use sh, bash or pwsh", shell)
+	}
+}
+
 // executeCommand executes a shell command with timeout
-func executeCommand(command string, timeout time.Duration) (string, error) {
+func executeCommand(command string, timeout time.Duration, opts commandOptions) (string, error) {
 	if command == "" {
 		return "", fmt.Errorf("empty command")
 	}
+	shell, _, err := shellCommand(opts.Shell)
+	if err != nil {
+		return "", err
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	// Execute command through bash shell to support:
+	// Execute command through a shell (bash by default) to support:
 	// - Redirections (>, >>, |)
 	// - Variable substitution ($VAR, $(command))
 	// - Multiple commands with && or ;
 	// - Other shell features
-	cmd := exec.CommandContext(ctx, "/bin/bash", "-c", command)
-	output, err := cmd.CombinedOutput()
+	cmd := exec.CommandContext(ctx, shell[0], append(shell[1:], command)...)
+	cmd.Dir = opts.WorkingDir
+	if opts.Stdin != "" {
+		cmd.Stdin = strings.NewReader(opts.Stdin)
+	}
+	return runCommand(ctx, cmd, opts.Env)
+}
 
+// runCommand runs cmd with env added to the controller's environment and returns its
+// combined stdout and stderr; ctx is the context of cmd
+func runCommand(ctx context.Context, cmd *exec.Cmd, env []string) (string, error) {
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	// Don't wait for children of a killed shell that still hold the output pipe
+	cmd.WaitDelay = time.Second
+	output, err := cmd.CombinedOutput()
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
 			return "", fmt.Errorf("command execution timed out")
@@ -495,93 +1079,121 @@ func executeCommand(command string, timeout time.Duration) (string, error) {
 	return string(output), nil
 }
 
+// httpRequestOptions holds the optional parts of an HTTP task request
+type httpRequestOptions struct {
+	Body        string            // Request body for POST
+	ContentType string            // Content-Type of the body (default: text/xml for XML bodies, application/json otherwise)
+	Headers     map[string]string // Request headers, applied after the defaults
+	TLSConfig   *tls.Config       // Client certificate and CA bundle, nil for the Go defaults
+
+	// Redirect policy, nil for the Go default of following up to 10 redirects
+	CheckRedirect func(req *http.Request, via []*http.Request) error
+}
+
+// httpResponse is the status code, headers and body of an HTTP response
+type httpResponse struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       string
+}
+
+// httpRequestOptionsFor returns the request options from a task spec
+func httpRequestOptionsFor(task *mcallv1.McallTask) httpRequestOptions {
+	return httpRequestOptions{
+		Body:          task.Spec.Body,
+		ContentType:   task.Spec.ContentType,
+		Headers:       task.Spec.Headers,
+		CheckRedirect: redirectPolicy(task.Spec.HttpValidation),
+	}
+}
+
 // executeHTTPRequest executes HTTP GET/POST request
 func executeHTTPRequest(url, method string, timeout time.Duration) (string, int, error) {
+	return executeHTTPRequestWithOptions(url, method, timeout, httpRequestOptions{})
+}
+
+// executeHTTPRequestWithOptions executes HTTP GET/POST request with the given body and headers
+func executeHTTPRequestWithOptions(url, method string, timeout time.Duration, opts httpRequestOptions) (string, int, error) {
+	resp, err := doHTTPRequest(url, method, timeout, opts)
+	if err != nil {
+		if resp != nil {
+			return resp.Body, resp.StatusCode, err
+		}
+		return "", 0, err
+	}
+
+	// Check HTTP status code - fail if not 2xx
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.Body, resp.StatusCode, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	// Return body content and status code
+	return resp.Body, resp.StatusCode, nil
+}
+
+// doHTTPRequest executes HTTP GET/POST request and returns the response whatever its status code
+func doHTTPRequest(url, method string, timeout time.Duration, opts httpRequestOptions) (*httpResponse, error) {
 	if url == "" {
-		return "", 0, fmt.Errorf("empty URL")
+		return nil, fmt.Errorf("empty URL")
 	}
 
 	var req *http.Request
 	var err error
 
 	if method == "POST" {
-		// For POST requests, we might need to extract data from the URL
-		// This is a simplified implementation - you might want to enhance it
-		req, err = http.NewRequest("POST", url, nil)
+		req, err = http.NewRequest("POST", url, strings.NewReader(opts.Body))
 		if err != nil {
-			return "", 0, fmt.Errorf("failed to create POST request: %w", err)
+			return nil, fmt.Errorf("failed to create POST request: %w", err)
 		}
-		req.Header.Set("Content-Type", "application/json")
+		contentType := opts.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+			if strings.HasPrefix(strings.TrimSpace(opts.Body), "<") {
+				contentType = "text/xml; charset=utf-8"
+			}
+		}
+		req.Header.Set("Content-Type", contentType)
 	} else {
 		req, err = http.NewRequest("GET", url, nil)
 		if err != nil {
-			return "", 0, fmt.Errorf("failed to create GET request: %w", err)
+			return nil, fmt.Errorf("failed to create GET request: %w", err)
 		}
 	}
 
 	// Set User-Agent header to avoid 403 Forbidden errors
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/139.0.0.0 Safari/537.36")
+	for name, value := range opts.Headers {
+		req.Header.Set(name, value)
+	}
 
 	client := &http.Client{
-		Timeout: timeout,
+		Timeout:       timeout,
+		CheckRedirect: opts.CheckRedirect,
+	}
+	if opts.TLSConfig != nil {
+		transport := cachedTLSTransport(opts.TLSConfig)
+		if transport == nil {
+			transport = newTLSTransport(opts.TLSConfig)
+			defer transport.CloseIdleConnections()
+		}
+		client.Transport = transport
 	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to execute %s request: %w", method, err)
+		return nil, fmt.Errorf("failed to execute %s request: %w", method, err)
 	}
 	defer resp.Body.Close()
 
+	response := &httpResponse{StatusCode: resp.StatusCode, Status: resp.Status, Header: resp.Header}
 	doc, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	// Check HTTP status code - fail if not 2xx
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return string(doc), resp.StatusCode, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	// Return body content and status code
-	return string(doc), resp.StatusCode, nil
-}
-
-// getHTTPStatusCode gets the HTTP status code for expect validation (like mcall.go)
-func getHTTPStatusCode(url, method string, timeout time.Duration) string {
-	if url == "" {
-		return ""
-	}
-
-	var req *http.Request
-	var err error
-
-	if method == "POST" {
-		req, err = http.NewRequest("POST", url, nil)
-		if err != nil {
-			return ""
-		}
-		req.Header.Set("Content-Type", "application/json")
-	} else {
-		req, err = http.NewRequest("GET", url, nil)
-		if err != nil {
-			return ""
-		}
-	}
-
-	// Set User-Agent header to avoid 403 Forbidden errors
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/139.0.0.0 Safari/537.36")
-
-	client := &http.Client{
-		Timeout: timeout,
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return ""
+		return response, fmt.Errorf("failed to read response body: %w", err)
 	}
-	defer resp.Body.Close()
+	response.Body = string(doc)
 
-	return strconv.Itoa(resp.StatusCode)
+	return response, nil
 }
 
 // TaskResult represents the result of a task execution (based on mcall.go FetchedResult)
@@ -591,15 +1203,43 @@ type TaskResult struct {
 	Error   string `json:"errorCode"`
 	Content string `json:"result"`
 	TS      string `json:"ts"`
+
+	// DurationMs is the measured execution time of the input in milliseconds
+	DurationMs int64 `json:"durationMs"`
+
+	// Attempts is the number of HTTP requests made for get/post inputs
+	Attempts int32 `json:"attempts,omitempty"`
 }
 
 // TaskWorker represents a single task execution (based on mcall.go CallFetch)
 type TaskWorker struct {
-	input     string
-	inputType string
-	name      string
-	expect    string // For expect validation (like mcall.go) - supports HTTP status codes and response body text
-	result    chan TaskResult
+	input        string
+	inputType    string
+	name         string
+	expect       string        // For expect validation (like mcall.go) - supports HTTP status codes and response body text
+	timeout      time.Duration // Per-input timeout, overrides the task timeout when set
+	maxLatency   time.Duration // Per-input latency threshold (maxLatencyMs), 0 disables it
+	http         httpRequestOptions
+	command      commandOptions           // Shell and working directory of cmd inputs
+	service      string                   // Service name logged to the logging backend per input, empty to skip
+	logEntry     LogEntry                 // Task and workflow run fields logged with each input
+	logging      LoggingConfig            // Logging configuration of the task namespace
+	retry        *mcallv1.HttpRetry       // Retry of transient HTTP failures, nil to send a single request
+	grpc         *mcallv1.GrpcCall        // Call made by grpc inputs, nil for the health check
+	graphql      *mcallv1.GraphQLRequest  // Operation posted by graphql inputs
+	sql          *mcallv1.SqlQuery        // Database of sql inputs
+	sqlDSN       string                   // Data source name of sql inputs
+	kafka        *mcallv1.KafkaCheck      // Canary message of kafka inputs
+	podExec      *podExecTarget           // Pod kubectl-exec inputs run in
+	sftp         *mcallv1.FileTransfer    // Operation of sftp inputs
+	fileTransfer *fileTransferCredentials // Login of sftp inputs
+	smtp         *mcallv1.SmtpCheck       // Handshake of smtp inputs
+	smtpAuth     *smtpCredentials         // AUTH login of smtp inputs
+	websocket    *mcallv1.WebSocketCheck  // Message exchanged by websocket inputs
+	secrets      []string                 // Values of secretRefs, masked in the result
+	maskPatterns []*regexp.Regexp         // Custom masking rules applied to the result
+	trace        context.Context          // Span of the task execution, parent of the input span
+	result       chan TaskResult
 }
 
 // NewTaskWorker creates a new TaskWorker instance
@@ -618,36 +1258,81 @@ func (tw *TaskWorker) Execute(timeout time.Duration) {
 	var content string
 	var err error
 
+	if tw.timeout > 0 {
+		timeout = tw.timeout
+	}
+	start := time.Now()
+	ctx, span := startInputSpan(tw.trace, tw)
+	defer func() { endInputSpan(span, err) }()
+	if tw.inputType != "cmd" {
+		tw.http.Headers = traceHeaders(ctx, tw.http.Headers)
+	}
+
 	// Execute based on type (like original mcall.go)
-	var statusCode string
-	var httpStatusCode int
+	var resp *httpResponse
+	var attempts int32
 	switch tw.inputType {
 	case "cmd":
-		content, err = executeCommand(tw.input, timeout)
-	case "get":
-		content, httpStatusCode, err = executeHTTPRequest(tw.input, "GET", timeout)
-		if httpStatusCode > 0 {
-			statusCode = fmt.Sprintf("%d", httpStatusCode)
+		content, err = executeCommand(tw.input, timeout, tw.command)
+	case "get", "post":
+		// A single request captures the status code, headers and body used by expect
+		resp, attempts, err = doHTTPRequestWithRetry(tw.input, strings.ToUpper(tw.inputType), timeout, tw.http, tw.retry)
+		if resp != nil {
+			content = resp.Body
 		}
-	case "post":
-		content, httpStatusCode, err = executeHTTPRequest(tw.input, "POST", timeout)
-		if httpStatusCode > 0 {
-			statusCode = fmt.Sprintf("%d", httpStatusCode)
+		// Without expect any non-2xx status fails; with expect the status code is matched instead
+		if err == nil && tw.expect == "" && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+			err = fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		}
+	case "graphql":
+		opts := tw.http
+		if opts.Body, err = graphqlRequestBody(tw.graphql); err != nil {
+			break
+		}
+		opts.ContentType = "application/json"
+		resp, attempts, err = doHTTPRequestWithRetry(tw.input, "POST", timeout, opts, tw.retry)
+		if resp != nil {
+			content = resp.Body
+		}
+		// Without expect any non-2xx status or GraphQL error fails
+		if err == nil && tw.expect == "" {
+			if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+				err = fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+			} else {
+				err = checkGraphQLResponse(content)
+			}
 		}
+	case "grpc":
+		content, err = executeGrpcRequest(tw.input, tw.grpc, timeout, tw.http.TLSConfig)
+	case "tcp":
+		// Read the banner only when expect needs to match it
+		content, err = executeTCPCheck(tw.input, timeout, tw.expect != "")
+	case "sql":
+		content, err = executeSQLQuery(tw.sql, tw.sqlDSN, tw.input, timeout)
+	case "kafka":
+		content, err = executeKafkaCheck(tw.input, tw.kafka, timeout)
+	case "kubectl-exec":
+		content, err = executePodExec(tw.podExec, tw.input, timeout)
+	case "sftp":
+		content, err = executeFileTransfer(tw.input, tw.sftp, tw.fileTransfer, timeout)
+	case "smtp":
+		content, err = executeSMTPCheck(tw.input, tw.smtp, tw.smtpAuth, timeout, tw.http.TLSConfig)
+	case "websocket":
+		content, err = executeWebSocketCheck(tw.input, tw.websocket, tw.http.Headers, timeout, tw.http.TLSConfig)
 	default:
-		content, err = executeCommand(tw.input, timeout)
+		content, err = executeCommand(tw.input, timeout, tw.command)
 	}
 
 	// Validate expect string (like mcall.go checkRslt)
 	if tw.expect != "" && err == nil {
 		var expectContent string
-		if tw.inputType == "cmd" {
+		if resp == nil {
 			// For cmd, check command output
 			expectContent = content
 		} else {
 			// For HTTP requests, check both status code and response body
 			// Format: "statusCode|responseBody" so expect can match either
-			expectContent = statusCode + "|" + content
+			expectContent = strconv.Itoa(resp.StatusCode) + "|" + content
 		}
 
 		if !checkExpect(expectContent, tw.expect) {
@@ -655,10 +1340,20 @@ func (tw *TaskWorker) Execute(timeout time.Duration) {
 		}
 	}
 
+	// A response slower than maxLatencyMs fails the input even when it matched
+	if err == nil && tw.maxLatency > 0 {
+		if elapsed := time.Since(start); elapsed > tw.maxLatency {
+			err = fmt.Errorf("response took %dms, exceeding maxLatencyMs %d", elapsed.Milliseconds(), tw.maxLatency.Milliseconds())
+		}
+	}
+
 	// Set error code (like original mcall.go)
 	var errCode string
 	if err != nil {
 		errCode = "-1" // ErrorCodeFailure
+		if content == "" {
+			content = err.Error() // e.g. timeouts, which have no output
+		}
 	} else {
 		errCode = "0" // ErrorCodeSuccess
 	}
@@ -666,39 +1361,67 @@ func (tw *TaskWorker) Execute(timeout time.Duration) {
 	// Create result (like original mcall.go FetchedResult)
 	now := time.Now().UTC()
 	result := TaskResult{
-		Input:   tw.input,
-		Name:    tw.name,
-		Error:   errCode,
-		Content: content,
-		TS:      now.Format("2006-01-02T15:04:05.000"),
+		Input:      maskOutput(tw.input, tw.secrets, tw.maskPatterns),
+		Name:       tw.name,
+		Error:      errCode,
+		Content:    maskOutput(content, tw.secrets, tw.maskPatterns),
+		TS:         now.Format("2006-01-02T15:04:05.000"),
+		DurationMs: now.Sub(start).Milliseconds(),
+		Attempts:   attempts,
 	}
 
 	tw.result <- result
 }
 
+// logResult logs a single input result to the configured logging backend
+func (tw *TaskWorker) logResult(result TaskResult) error {
+	config := tw.logging
+	if tw.service == "" || !config.Enabled {
+		return nil
+	}
+
+	entry := tw.logEntry
+	entry.ServiceName = tw.service
+	entry.ServiceType = tw.inputType
+	entry.Status = "UP"
+	entry.ErrorCode = result.Error
+	entry.ResponseTime = result.DurationMs
+	entry.Timestamp = time.Now()
+	entry.Output = result.Content
+	if result.Error == "-1" {
+		entry.Status = "DOWN"
+		entry.Error = result.Content
+	}
+	return EnqueueLog(entry, config)
+}
+
 // executeWorkersSequential executes workers sequentially
 func executeWorkersSequential(workers []*TaskWorker, timeout time.Duration, logger logr.Logger, taskName string, failFast bool) []string {
 	var results []string
 
 	for i, worker := range workers {
-		logger.Info("Executing input",
+		logger.V(debugLevel).Info("Executing input",
 			"task", taskName,
 			"input", i+1,
 			"type", worker.inputType,
-			"command", worker.input)
+			"command", maskOutput(worker.input, worker.secrets, worker.maskPatterns))
 
 		// Execute worker
 		worker.Execute(timeout)
 
 		// Get result
 		result := <-worker.result
+		if err := worker.logResult(result); err != nil {
+			logger.Error(err, "Failed to log input to backend", "task", taskName, "input", i+1)
+		}
 
 		// Debug logging
-		logger.Info("TaskWorker result",
+		logger.V(debugLevel).Info("TaskWorker result",
 			"task", taskName,
 			"input", i+1,
 			"type", worker.inputType,
 			"errorCode", result.Error,
+			"durationMs", result.DurationMs,
 			"content", result.Content)
 
 		// Format result
@@ -720,7 +1443,7 @@ func executeWorkersSequential(workers []*TaskWorker, timeout time.Duration, logg
 			}
 		} else {
 			results = append(results, result.Content)
-			logger.Info("Input execution succeeded",
+			logger.V(debugLevel).Info("Input execution succeeded",
 				"task", taskName,
 				"input", i+1,
 				"type", worker.inputType)
@@ -760,24 +1483,28 @@ func executeWorkersParallel(workers []*TaskWorker, timeout time.Duration, logger
 			default:
 			}
 
-			logger.Info("Executing input",
+			logger.V(debugLevel).Info("Executing input",
 				"task", taskName,
 				"input", index+1,
 				"type", w.inputType,
-				"command", w.input)
+				"command", maskOutput(w.input, w.secrets, w.maskPatterns))
 
 			// Execute worker
 			w.Execute(timeout)
 
 			// Get result
 			result := <-w.result
+			if err := w.logResult(result); err != nil {
+				logger.Error(err, "Failed to log input to backend", "task", taskName, "input", index+1)
+			}
 
 			// Debug logging
-			logger.Info("TaskWorker result",
+			logger.V(debugLevel).Info("TaskWorker result",
 				"task", taskName,
 				"input", index+1,
 				"type", w.inputType,
 				"errorCode", result.Error,
+				"durationMs", result.DurationMs,
 				"content", result.Content)
 
 			// Format result and store in correct position
@@ -801,7 +1528,7 @@ func executeWorkersParallel(workers []*TaskWorker, timeout time.Duration, logger
 				}
 			} else {
 				results[index] = result.Content
-				logger.Info("Input execution succeeded",
+				logger.V(debugLevel).Info("Input execution succeeded",
 					"task", taskName,
 					"input", index+1,
 					"type", w.inputType)
@@ -845,6 +1572,84 @@ func parseJSONInputs(inputStr string) ([]map[string]interface{}, error) {
 	return inputs, nil
 }
 
+// parseInputHTTPOptions reads the HTTP request options of a JSON input.
+// The body may be a string or any JSON value, which is sent as JSON.
+// Header values that aren't strings are formatted with fmt.Sprint.
+func parseInputHTTPOptions(input map[string]interface{}) httpRequestOptions {
+	var opts httpRequestOptions
+	switch body := input["body"].(type) {
+	case nil:
+	case string:
+		opts.Body = body
+	default:
+		if bodyJSON, err := json.Marshal(body); err == nil {
+			opts.Body = string(bodyJSON)
+		}
+	}
+	if contentType, ok := input["contentType"].(string); ok {
+		opts.ContentType = contentType
+	}
+	if headers, ok := input["headers"].(map[string]interface{}); ok {
+		opts.Headers = make(map[string]string, len(headers))
+		for name, value := range headers {
+			opts.Headers[name] = fmt.Sprint(value)
+		}
+	}
+	return opts
+}
+
+// resolveInputStdin replaces the stdinFrom of JSON inputs with the stdin taken from the task
+// environment, where InputSources put their values, so large outputs aren't templated into JSON
+func resolveInputStdin(inputs []map[string]interface{}, environment map[string]string) error {
+	for i, input := range inputs {
+		from, exists := input["stdinFrom"]
+		if !exists {
+			continue
+		}
+		name, _ := from.(string)
+		value, exists := environment[name]
+		if !exists {
+			return fmt.Errorf("input %d: stdinFrom %q is not an environment variable or input source", i+1, from)
+		}
+		input["stdin"] = value
+		delete(input, "stdinFrom")
+	}
+	return nil
+}
+
+// parseInputStdin reads the standard input of a JSON input. It may be a string or any
+// JSON value, which is passed as JSON.
+func parseInputStdin(input map[string]interface{}) string {
+	switch stdin := input["stdin"].(type) {
+	case nil:
+		return ""
+	case string:
+		return stdin
+	default:
+		stdinJSON, _ := json.Marshal(stdin)
+		return string(stdinJSON)
+	}
+}
+
+// parseInputGrpcCall reads the gRPC call of a JSON input (method, request, service, tls).
+// The request may be a JSON string or object.
+func parseInputGrpcCall(input map[string]interface{}) *mcallv1.GrpcCall {
+	call := &mcallv1.GrpcCall{}
+	call.Method, _ = input["method"].(string)
+	call.Service, _ = input["service"].(string)
+	call.TLS, _ = input["tls"].(bool)
+	switch request := input["request"].(type) {
+	case nil:
+	case string:
+		call.Request = request
+	default:
+		if requestJSON, err := json.Marshal(request); err == nil {
+			call.Request = string(requestJSON)
+		}
+	}
+	return call
+}
+
 // getMapKeys returns the keys of a map as a slice of strings
 func getMapKeys(m map[string]interface{}) []string {
 	keys := make([]string, 0, len(m))
@@ -854,49 +1659,55 @@ func getMapKeys(m map[string]interface{}) []string {
 	return keys
 }
 
-// checkExpect validates result against expect string (based on mcall.go checkRslt)
 // checkExpect validates content against expect string (based on mcall.go checkRslt)
 func checkExpect(content, expect string) bool {
 	if expect == "" {
 		return true
 	}
 
-	// Split expect by "|" (OR condition like mcall.go)
-	expectArray := strings.Split(expect, "|")
-
-	for _, expectItem := range expectArray {
-		// Check for count-based validation (like mcall.go)
-		if strings.Contains(expectItem, "$count < ") || strings.Contains(expectItem, " > $count") ||
-			strings.Contains(expectItem, "$count > ") || strings.Contains(expectItem, " < $count") {
-			// Handle count validation (simplified version for now)
-			continue
-		} else {
-			// Simple string contains check (like mcall.go)
-			if strings.Contains(content, strings.TrimSpace(expectItem)) {
-				return true // Any match is success
-			}
-		}
-	}
-
-	return false // No matches found
+	// "|" separated alternatives, any match is success (like mcall.go)
+	matched, _ := assertion.Any(content, assertion.ParseExpect(expect))
+	return matched
 }
 
 // McallTaskReconciler reconciles a McallTask object
 type McallTaskReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// MaxConcurrentReconciles is the number of tasks reconciled in parallel (default 1)
+	MaxConcurrentReconciles int
+
+	// Config is the REST config used by kubectl-exec tasks to call the pods/exec API
+	Config *rest.Config
+
+	// Recorder emits the Events of task lifecycle transitions
+	Recorder record.EventRecorder
+
+	// Dynamic watches for waitFor resource kinds
+	controller   controller.Controller
+	cache        cache.Cache
+	watchMu      sync.Mutex
+	watchedKinds map[schema.GroupVersionKind]bool
 }
 
 //+kubebuilder:rbac:groups=mcall.tz.io,resources=mcalltasks,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=mcall.tz.io,resources=mcalltasks/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=mcall.tz.io,resources=mcalltasks/finalizers,verbs=update
+//+kubebuilder:rbac:groups=mcall.tz.io,resources=mcalltaskruns,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=mcall.tz.io,resources=mcalltaskruns/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=mcall.tz.io,resources=mcallloggingconfigs,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=pods/exec,verbs=get;create
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop
 func (r *McallTaskReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
-	log.Info("=== RECONCILE START ===", "task", req.NamespacedName)
+	log.V(debugLevel).Info("Reconciling task", "task", req.NamespacedName)
 
 	// Fetch the McallTask instance
 	var mcallTask mcallv1.McallTask
@@ -905,23 +1716,25 @@ func (r *McallTaskReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	log.Info("Fetched McallTask", "task", mcallTask.Name, "currentPhase", mcallTask.Status.Phase, "phaseLength", len(mcallTask.Status.Phase))
+	log.V(debugLevel).Info("Fetched McallTask", "task", mcallTask.Name, "currentPhase", mcallTask.Status.Phase)
+
+	// Run history copies are kept for inspection only, never executed
+	if _, isHistory := mcallTask.Labels["mcall.tz.io/history-of"]; isHistory {
+		return ctrl.Result{}, nil
+	}
 
 	// Initialize status if not set (before any other processing)
 	if len(mcallTask.Status.Phase) == 0 {
-		log.Info("*** STATUS PHASE IS EMPTY - INITIALIZING TO PENDING ***", "task", mcallTask.Name)
 		mcallTask.Status.Phase = mcallv1.McallTaskPhasePending
-		log.Info("About to update status", "task", mcallTask.Name, "newPhase", mcallTask.Status.Phase)
+		setTaskConditions(&mcallTask)
 		if err := r.Status().Update(ctx, &mcallTask); err != nil {
-			log.Error(err, "*** FAILED TO INITIALIZE STATUS PHASE ***", "task", mcallTask.Name, "error", err.Error())
+			log.Error(err, "Failed to initialize status phase", "task", mcallTask.Name)
 			return ctrl.Result{}, err
 		}
-		log.Info("*** SUCCESSFULLY INITIALIZED STATUS PHASE ***", "task", mcallTask.Name, "phase", mcallTask.Status.Phase)
+		log.V(debugLevel).Info("Initialized status phase", "task", mcallTask.Name, "phase", mcallTask.Status.Phase)
 		return ctrl.Result{RequeueAfter: 1 * time.Second}, nil
 	}
 
-	log.Info("Status phase already set", "task", mcallTask.Name, "phase", mcallTask.Status.Phase)
-
 	// Handle deletion
 	if !mcallTask.DeletionTimestamp.IsZero() {
 		return r.handleDeletion(ctx, &mcallTask)
@@ -935,6 +1748,13 @@ func (r *McallTaskReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		}
 	}
 
+	// Templates with a warm-up run in the sandbox namespace before they execute or are used by workflows
+	if mcallTask.Spec.WarmUp != nil {
+		if validated, result, err := r.reconcileWarmUp(ctx, &mcallTask); !validated {
+			return result, err
+		}
+	}
+
 	// Reconcile based on current status
 	switch mcallTask.Status.Phase {
 	case mcallv1.McallTaskPhasePending:
@@ -943,6 +1763,8 @@ func (r *McallTaskReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return r.handleRunning(ctx, &mcallTask)
 	case mcallv1.McallTaskPhaseSucceeded, mcallv1.McallTaskPhaseFailed:
 		return r.handleCompleted(ctx, &mcallTask)
+	case mcallv1.McallTaskPhaseBlocked:
+		return r.handleBlocked(ctx, &mcallTask)
 	default:
 		log.Info("Unknown phase", "phase", mcallTask.Status.Phase)
 		return ctrl.Result{}, nil
@@ -1013,13 +1835,14 @@ func (r *McallTaskReconciler) checkTaskCondition(ctx context.Context, task *mcal
 			actualValue = string(depTask.Status.Phase)
 		case "output":
 			if depTask.Status.Result != nil {
-				actualValue = depTask.Status.Result.Output
+				actualValue = displayOutput(depTask.Status.Result)
 			}
 		default:
 			return false, fmt.Errorf("unknown field for condition: %s", condition.FieldEquals.Field)
 		}
 
-		if actualValue != condition.FieldEquals.Value {
+		fieldEquals := assertion.Assertion{Operator: assertion.Equals, Value: condition.FieldEquals.Value}
+		if matched, _ := fieldEquals.Evaluate(actualValue); !matched {
 			logger.Info("Task condition not met: field value mismatch",
 				"task", task.Name,
 				"field", condition.FieldEquals.Field,
@@ -1030,13 +1853,56 @@ func (r *McallTaskReconciler) checkTaskCondition(ctx context.Context, task *mcal
 	}
 
 	// Check OutputContains condition
+	var depOutput string
+	if depTask.Status.Result != nil {
+		depOutput = displayOutput(depTask.Status.Result)
+	}
 	if condition.OutputContains != "" {
-		if depTask.Status.Result == nil ||
-			!strings.Contains(depTask.Status.Result.Output, condition.OutputContains) {
+		outputContains := assertion.Assertion{Operator: assertion.Contains, Value: condition.OutputContains}
+		if matched, _ := outputContains.Evaluate(depOutput); depTask.Status.Result == nil || !matched {
 			logger.Info("Task condition not met: output does not contain expected string",
 				"task", task.Name,
 				"expected", condition.OutputContains,
-				"output", truncateString(depTask.Status.Result.Output, 100))
+				"output", truncateString(depOutput, 100))
+			return false, nil
+		}
+	}
+
+	// Check assertion rules on the dependent task output
+	if len(condition.Assertions) > 0 {
+		matched, failed, err := assertion.All(depOutput, toAssertions(condition.Assertions))
+		if err != nil {
+			return false, fmt.Errorf("invalid condition rule %s: %w", failed, err)
+		}
+		if !matched {
+			logger.Info("Task condition not met: assertion failed",
+				"task", task.Name,
+				"assertion", failed.String(),
+				"output", truncateString(depOutput, 100))
+			return false, nil
+		}
+	}
+
+	// Check the CEL expression against the dependent task and the workflow parameters
+	if condition.CELExpression != "" {
+		params, err := r.workflowParams(ctx, task)
+		if err != nil {
+			return false, err
+		}
+		matched, err := evaluateConditionCEL(condition.CELExpression, &depTask, depOutput, params)
+		if err != nil {
+			// The dependent task is done, so an expression failing on its output never matches
+			logger.Info("Task condition not met: CEL expression failed",
+				"task", task.Name,
+				"expression", condition.CELExpression,
+				"error", err.Error())
+			return false, nil
+		}
+		if !matched {
+			logger.Info("Task condition not met: CEL expression is false",
+				"task", task.Name,
+				"expression", condition.CELExpression,
+				"output", truncateString(depOutput, 100))
 			return false, nil
 		}
 	}
@@ -1047,9 +1913,94 @@ func (r *McallTaskReconciler) checkTaskCondition(ctx context.Context, task *mcal
 	return true, nil
 }
 
+// checkTaskConditions checks if a task should run based on combined conditions: all AllOf and
+// at least one AnyOf condition must be met. It waits for all dependent tasks to complete.
+func (r *McallTaskReconciler) checkTaskConditions(ctx context.Context, task *mcallv1.McallTask, conditions *mcallv1.TaskConditions) (bool, error) {
+	allOf := true
+	for i := range conditions.AllOf {
+		met, err := r.checkTaskCondition(ctx, task, &conditions.AllOf[i])
+		if err != nil {
+			return false, err
+		}
+		allOf = allOf && met
+	}
+
+	anyOf := len(conditions.AnyOf) == 0
+	for i := range conditions.AnyOf {
+		met, err := r.checkTaskCondition(ctx, task, &conditions.AnyOf[i])
+		if err != nil {
+			return false, err
+		}
+		anyOf = anyOf || met
+	}
+	return allOf && anyOf, nil
+}
+
+// workflowParams returns the environment and parameters of the workflow of a task, nil for
+// standalone tasks
+func (r *McallTaskReconciler) workflowParams(ctx context.Context, task *mcallv1.McallTask) (map[string]string, error) {
+	workflowName, inWorkflow := task.Labels["mcall.tz.io/workflow"]
+	if !inWorkflow {
+		return nil, nil
+	}
+	var workflow mcallv1.McallWorkflow
+	if err := r.Get(ctx, types.NamespacedName{Name: workflowName, Namespace: task.Namespace}, &workflow); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// The parameters of the current run, including its trigger overrides
+	parameters := workflow.Status.Parameters
+	if parameters == nil {
+		var err error
+		if parameters, err = resolveWorkflowParameters(&workflow); err != nil {
+			return nil, err
+		}
+	}
+
+	params := make(map[string]string, len(workflow.Spec.Environment)+len(parameters))
+	for name, value := range workflow.Spec.Environment {
+		params[name] = value
+	}
+	for name, value := range parameters {
+		params[name] = value
+	}
+	return params, nil
+}
+
 func (r *McallTaskReconciler) handlePending(ctx context.Context, task *mcallv1.McallTask) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
 
+	// Check the kill switch before anything else
+	if blocked, err := r.blockByKillSwitch(ctx, task); err != nil {
+		log.Error(err, "Failed to block task by kill switch", "task", task.Name)
+		return ctrl.Result{}, err
+	} else if blocked {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	// Check pause annotation (incident response)
+	switch task.Annotations[mcallv1.PausedAnnotation] {
+	case "true":
+		log.Info("Task paused, keeping it Pending", "task", task.Name)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	case "skip":
+		log.Info("Task paused with skip, skipping", "task", task.Name)
+		task.Status.Phase = mcallv1.McallTaskPhaseSkipped
+		task.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+		task.Status.Result = &mcallv1.McallTaskResult{
+			ErrorCode:    "0",
+			ErrorMessage: fmt.Sprintf("Skipped due to %s annotation", mcallv1.PausedAnnotation),
+		}
+		setTaskConditions(task)
+		if err := r.Status().Update(ctx, task); err != nil {
+			return ctrl.Result{}, err
+		}
+		recordEvent(r.Recorder, task, corev1.EventTypeNormal, EventReasonSkipped, "%s", task.Status.Result.ErrorMessage)
+		return ctrl.Result{}, nil
+	}
+
 	// Check condition if present (from workflow annotation)
 	if conditionStr, exists := task.Annotations["mcall.tz.io/condition"]; exists && conditionStr != "" {
 		var condition mcallv1.TaskCondition
@@ -1079,9 +2030,46 @@ func (r *McallTaskReconciler) handlePending(ctx context.Context, task *mcallv1.M
 				ErrorCode:    "0",
 				ErrorMessage: fmt.Sprintf("Skipped due to condition: when=%s", condition.When),
 			}
+			setTaskConditions(task)
+			if err := r.Status().Update(ctx, task); err != nil {
+				return ctrl.Result{}, err
+			}
+			recordEvent(r.Recorder, task, corev1.EventTypeNormal, EventReasonSkipped, "%s", task.Status.Result.ErrorMessage)
+			return ctrl.Result{}, nil
+		}
+	}
+
+	// Check combined conditions if present (from workflow annotation)
+	if conditionsStr, exists := task.Annotations["mcall.tz.io/conditions"]; exists && conditionsStr != "" {
+		var conditions mcallv1.TaskConditions
+		if err := json.Unmarshal([]byte(conditionsStr), &conditions); err != nil {
+			log.Error(err, "Failed to parse task conditions", "task", task.Name)
+			return ctrl.Result{}, err
+		}
+
+		shouldRun, err := r.checkTaskConditions(ctx, task, &conditions)
+		if err != nil {
+			if strings.Contains(err.Error(), "not completed yet") {
+				log.Info("Waiting for dependent tasks to complete", "task", task.Name)
+				return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+			}
+			log.Error(err, "Failed to check task conditions", "task", task.Name)
+			return ctrl.Result{}, err
+		}
+
+		if !shouldRun {
+			log.Info("Task conditions not met, skipping", "task", task.Name, "conditions", conditions)
+			task.Status.Phase = mcallv1.McallTaskPhaseSkipped
+			task.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+			task.Status.Result = &mcallv1.McallTaskResult{
+				ErrorCode:    "0",
+				ErrorMessage: "Skipped due to conditions: allOf/anyOf not met",
+			}
+			setTaskConditions(task)
 			if err := r.Status().Update(ctx, task); err != nil {
 				return ctrl.Result{}, err
 			}
+			recordEvent(r.Recorder, task, corev1.EventTypeNormal, EventReasonSkipped, "%s", task.Status.Result.ErrorMessage)
 			return ctrl.Result{}, nil
 		}
 	}
@@ -1098,8 +2086,69 @@ func (r *McallTaskReconciler) handlePending(ctx context.Context, task *mcallv1.M
 		}
 	}
 
-	// Check if task should be scheduled
-	if task.Spec.Schedule != "" {
+	// Expanded tasks run as one instance per item
+	fanOut, err := taskFanOut(task)
+	if err != nil {
+		log.Error(err, "Failed to parse task fan-out", "task", task.Name)
+		return ctrl.Result{}, err
+	}
+	if fanOut != nil {
+		return r.startFanOut(ctx, task, fanOut)
+	}
+
+	// Wait for external resource state
+	if task.Spec.WaitFor != nil {
+		r.ensureResourceWatch(ctx, task.Spec.WaitFor)
+
+		ready, message, err := r.checkResourceCondition(ctx, task)
+		if err != nil {
+			log.Error(err, "Failed to check waitFor condition", "task", task.Name)
+			return ctrl.Result{}, err
+		}
+		if !ready {
+			// The timeout counts from the first check of the run, not from the task creation
+			if task.Status.WaitStartTime == nil {
+				task.Status.WaitStartTime = &metav1.Time{Time: time.Now()}
+				setTaskConditions(task)
+				if err := r.Status().Update(ctx, task); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+			timeout := time.Duration(task.Spec.WaitFor.TimeoutSeconds) * time.Second
+			if timeout > 0 && time.Since(task.Status.WaitStartTime.Time) > timeout {
+				log.Info("waitFor condition timed out", "task", task.Name, "state", message)
+				task.Status.Phase = mcallv1.McallTaskPhaseFailed
+				task.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+				task.Status.WaitStartTime = nil
+				task.Status.Result = &mcallv1.McallTaskResult{
+					ErrorCode:    "-1",
+					ErrorMessage: fmt.Sprintf("waitFor timed out after %s: %s", timeout, message),
+				}
+				setTaskConditions(task)
+				if err := r.Status().Update(ctx, task); err != nil {
+					return ctrl.Result{}, err
+				}
+				recordEvent(r.Recorder, task, corev1.EventTypeWarning, EventReasonTimedOut, "%s", task.Status.Result.ErrorMessage)
+				r.notifyTaskResult(ctx, task)
+				return ctrl.Result{}, nil
+			}
+
+			log.Info("Waiting for resource condition", "task", task.Name, "state", message)
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+	}
+
+	// Wait for the backoff of a pending retry
+	retrying := task.Status.NextRetryTime != nil
+	if retrying {
+		if wait := time.Until(task.Status.NextRetryTime.Time); wait > 0 {
+			log.Info("Waiting for retry backoff", "task", task.Name, "retry", task.Status.RetryCount, "wait", wait)
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+	}
+
+	// Check if task should be scheduled (retries run regardless of the schedule)
+	if task.Spec.Schedule != "" && !retrying {
 		shouldRun, err := r.shouldRunScheduledTask(ctx, task)
 		if err != nil {
 			return ctrl.Result{}, err
@@ -1109,6 +2158,33 @@ func (r *McallTaskReconciler) handlePending(ctx context.Context, task *mcallv1.M
 		}
 	}
 
+	// Serialize executions sharing the same concurrency key
+	if task.Spec.ConcurrencyKey != "" {
+		position, err := r.getConcurrencyQueuePosition(ctx, task)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if position > 0 {
+			log.Info("Waiting for concurrency key", "task", task.Name, "concurrencyKey", task.Spec.ConcurrencyKey, "queuePosition", position)
+			if task.Status.QueuePosition != position {
+				task.Status.QueuePosition = position
+				setTaskConditions(task)
+				if err := r.Status().Update(ctx, task); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		}
+	}
+
+	// Space out task starts within a workflow run
+	if staggerWait, err := r.getStaggerWait(ctx, task); err != nil {
+		return ctrl.Result{}, err
+	} else if staggerWait > 0 {
+		log.Info("Waiting for stagger interval", "task", task.Name, "wait", staggerWait)
+		return ctrl.Result{RequeueAfter: staggerWait}, nil
+	}
+
 	// Create execution pod
 	if err := r.createExecutionPod(ctx, task); err != nil {
 		return ctrl.Result{}, err
@@ -1132,7 +2208,16 @@ func (r *McallTaskReconciler) handlePending(ctx context.Context, task *mcallv1.M
 		// Apply changes to latest version
 		latest.Status.Phase = mcallv1.McallTaskPhaseRunning
 		latest.Status.StartTime = &metav1.Time{Time: time.Now()}
+		latest.Status.QueuePosition = 0
+		latest.Status.WaitStartTime = nil
+		if retrying {
+			latest.Status.LastRetryTime = &metav1.Time{Time: time.Now()}
+			latest.Status.NextRetryTime = nil
+		} else {
+			latest.Status.RetryCount = 0 // New execution, retries start over
+		}
 
+		setTaskConditions(latest)
 		return r.Status().Update(ctx, latest)
 	})
 
@@ -1141,6 +2226,12 @@ func (r *McallTaskReconciler) handlePending(ctx context.Context, task *mcallv1.M
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 	}
 
+	if retrying {
+		recordEvent(r.Recorder, task, corev1.EventTypeNormal, EventReasonStarted, "Started %s task, retry %d/%d",
+			task.Spec.Type, task.Status.RetryCount, task.Spec.RetryCount)
+	} else {
+		recordEvent(r.Recorder, task, corev1.EventTypeNormal, EventReasonStarted, "Started %s task", task.Spec.Type)
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -1152,11 +2243,21 @@ func (r *McallTaskReconciler) processInputSources(ctx context.Context, task *mca
 
 	for _, source := range task.Spec.InputSources {
 		// Get referenced task
+		namespace := source.Namespace
+		if namespace == "" {
+			namespace = task.Namespace
+		}
 		var refTask mcallv1.McallTask
-		err := r.Get(ctx, types.NamespacedName{
-			Name:      source.TaskRef,
-			Namespace: task.Namespace,
-		}, &refTask)
+		var err error
+		if outcome, exists := task.Annotations[mcallv1.WorkflowOutcomeAnnotation]; exists && workflowOutcomeSource(source) {
+			// Exit tasks receive the outcome of their workflow run
+			err = json.Unmarshal([]byte(outcome), &refTask.Status)
+		} else {
+			err = r.Get(ctx, types.NamespacedName{
+				Name:      source.TaskRef,
+				Namespace: namespace,
+			}, &refTask)
+		}
 
 		if err != nil {
 			// Task not found - use default if available
@@ -1187,7 +2288,9 @@ func (r *McallTaskReconciler) processInputSources(ctx context.Context, task *mca
 		switch source.Field {
 		case "output":
 			if refTask.Status.Result != nil {
-				value = refTask.Status.Result.Output
+				if value, err = r.fullOutput(ctx, refTask.Namespace, refTask.Status.Result); err != nil {
+					return "", nil, fmt.Errorf("failed to read the output of task %s: %w", source.TaskRef, err)
+				}
 
 				// Apply JSONPath if specified
 				if source.JSONPath != "" {
@@ -1206,6 +2309,24 @@ func (r *McallTaskReconciler) processInputSources(ctx context.Context, task *mca
 						value = extracted
 					}
 				}
+
+				// Apply the jq program if specified
+				if source.JQ != "" {
+					transformed, err := applyJQ(value, source.JQ)
+					if err != nil {
+						logger.Error(err, "Failed to apply jq program",
+							"task", task.Name,
+							"sourceTask", source.TaskRef,
+							"jq", source.JQ)
+						if source.Default != "" {
+							value = source.Default
+						} else {
+							return "", nil, fmt.Errorf("failed to apply jq program %s: %w", source.JQ, err)
+						}
+					} else {
+						value = transformed
+					}
+				}
 			}
 		case "errorCode":
 			if refTask.Status.Result != nil {
@@ -1237,7 +2358,11 @@ func (r *McallTaskReconciler) processInputSources(ctx context.Context, task *mca
 				allData["completionTime"] = refTask.Status.CompletionTime.Format(time.RFC3339)
 			}
 			if refTask.Status.Result != nil {
-				allData["output"] = refTask.Status.Result.Output
+				output, err := r.fullOutput(ctx, refTask.Namespace, refTask.Status.Result)
+				if err != nil {
+					return "", nil, fmt.Errorf("failed to read the output of task %s: %w", source.TaskRef, err)
+				}
+				allData["output"] = output
 				allData["errorCode"] = refTask.Status.Result.ErrorCode
 				allData["errorMessage"] = refTask.Status.Result.ErrorMessage
 			}
@@ -1250,21 +2375,21 @@ func (r *McallTaskReconciler) processInputSources(ctx context.Context, task *mca
 		inputData[source.Name] = value
 		envVars[source.Name] = value
 
-		logger.Info("Extracted data from source task",
+		logger.V(debugLevel).Info("Extracted data from source task",
 			"task", task.Name,
 			"sourceTask", source.TaskRef,
 			"field", source.Field,
 			"varName", source.Name,
-			"valuePreview", truncateString(value, 100))
+			"valuePreview", truncateString(maskOutput(value, nil, nil), 100))
 	}
 
 	// Render input template if specified
 	if task.Spec.InputTemplate != "" {
 		renderedInput := renderTemplate(task.Spec.InputTemplate, inputData)
-		logger.Info("Rendered input template",
+		logger.V(debugLevel).Info("Rendered input template",
 			"task", task.Name,
 			"template", truncateString(task.Spec.InputTemplate, 100),
-			"rendered", truncateString(renderedInput, 100))
+			"rendered", truncateString(maskOutput(renderedInput, nil, nil), 100))
 		return renderedInput, envVars, nil
 	}
 
@@ -1279,13 +2404,20 @@ func (r *McallTaskReconciler) processInputSources(ctx context.Context, task *mca
 
 func (r *McallTaskReconciler) handleRunning(ctx context.Context, task *mcallv1.McallTask) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
-	taskTimeout := getTaskTimeout()
+	taskTimeout := getTaskTimeoutFor(task)
 
 	// Check if task has already been executed
 	if task.Status.Phase == mcallv1.McallTaskPhaseSucceeded || task.Status.Phase == mcallv1.McallTaskPhaseFailed {
 		return ctrl.Result{}, nil
 	}
 
+	// Expanded tasks complete with their instances
+	if fanOut, err := taskFanOut(task); err != nil {
+		return ctrl.Result{}, err
+	} else if fanOut != nil {
+		return r.completeFanOut(ctx, task, fanOut)
+	}
+
 	// Process InputSources if present
 	if len(task.Spec.InputSources) > 0 {
 		processedInput, envVars, err := r.processInputSources(ctx, task)
@@ -1324,6 +2456,7 @@ func (r *McallTaskReconciler) handleRunning(ctx context.Context, task *mcallv1.M
 					ErrorMessage: fmt.Sprintf("Failed to process input sources: %v", err),
 				}
 
+				setTaskConditions(latest)
 				return r.Status().Update(ctx, latest)
 			})
 
@@ -1336,9 +2469,9 @@ func (r *McallTaskReconciler) handleRunning(ctx context.Context, task *mcallv1.M
 		// Use processed input if InputTemplate was specified
 		if task.Spec.InputTemplate != "" {
 			task.Spec.Input = processedInput
-			logger.Info("Using processed input from template",
+			logger.V(debugLevel).Info("Using processed input from template",
 				"task", task.Name,
-				"input", truncateString(processedInput, 200))
+				"input", truncateString(maskOutput(processedInput, nil, nil), 200))
 		}
 
 		// Merge environment variables
@@ -1355,39 +2488,438 @@ func (r *McallTaskReconciler) handleRunning(ctx context.Context, task *mcallv1.M
 			"envVars", len(envVars))
 	}
 
+	// The kill switch may have been turned on since the task started, and its rendered input may
+	// run other types
+	if blocked, err := r.blockByKillSwitch(ctx, task); err != nil {
+		return ctrl.Result{}, err
+	} else if blocked {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
 	// Execute the actual task based on type
 	var output string
 	var errCode string
 	var errMsg string
 	var execErr error
 
-	logger.Info("Executing task",
-		"task", task.Name,
-		"type", task.Spec.Type,
-		"input", task.Spec.Input)
+	// Trace the execution, within the trace of its workflow run
+	ctx, span := startTaskSpan(ctx, task)
+	defer func() { endTaskSpan(span, task, execErr) }()
 
-	switch task.Spec.Type {
-	case "cmd":
-		// Parse JSON inputs (like original mcall.go)
-		jsonInputs, err := parseJSONInputs(task.Spec.Input)
+	logger.Info("Executing task", "task", task.Name, "type", task.Spec.Type)
+	logger.V(debugLevel).Info("Task input", "task", task.Name, "input", maskOutput(task.Spec.Input, nil, nil))
+
+	// Resolve HTTP credentials from their Secret at execution time
+	execTask := task
+	if task.Spec.HttpAuth != nil && (task.Spec.Type == "get" || task.Spec.Type == "post" || task.Spec.Type == "graphql" || task.Spec.Type == "websocket") {
+		authHeaders, err := r.resolveHttpAuth(ctx, task)
 		if err != nil {
-			logger.Error(err, "Failed to parse JSON inputs", "task", task.Name)
 			execErr = err
-			output = fmt.Sprintf("Error parsing inputs: %v", err)
+			output = fmt.Sprintf("Error: %s", err.Error())
 		} else {
-			// Process inputs using mcall structure
-			logger.Info("Processing inputs", "task", task.Name, "count", len(jsonInputs))
-
-			var results []string
-			var hasErrors bool
+			execTask = withAuthHeaders(task, authHeaders)
+		}
+	}
 
-			// Create TaskWorkers for each input (like original mcall.go)
-			workers := make([]*TaskWorker, 0, len(jsonInputs))
-			for i, input := range jsonInputs {
-				inputStr, ok := input["input"].(string)
-				if !ok {
-					continue
-				}
+	// Read the custom masking rules applied to the output
+	var env executionEnv
+	maskPatterns, err := r.resolveMaskingRules(ctx, task.Namespace)
+	if err != nil && execErr == nil {
+		execErr = err
+		output = fmt.Sprintf("Error: %s", err.Error())
+	}
+	env.maskPatterns = maskPatterns
+	env.trace = ctx
+
+	// Resolve the logging configuration of the task namespace; logging never fails the task
+	env.logging, err = resolveLoggingConfig(ctx, r, task.Namespace)
+	if err != nil {
+		logger.Error(err, "Failed to resolve the logging configuration, using the environment", "task", task.Name)
+		env.logging = GetLoggingConfig()
+	}
+
+	// Load the client certificate and CA bundle for HTTP requests
+	tlsConfig, err := r.resolveTLSConfig(ctx, task)
+	if err != nil && execErr == nil {
+		execErr = err
+		output = fmt.Sprintf("Error: %s", err.Error())
+	}
+	env.tlsConfig = tlsConfig
+
+	// Read the data source name of sql tasks from their Secret
+	if task.Spec.Type == "sql" && execErr == nil {
+		if env.sqlDSN, err = r.resolveSQLDSN(ctx, task); err != nil {
+			execErr = err
+			output = fmt.Sprintf("Error: %s", err.Error())
+		}
+	}
+
+	// Read the login of sftp tasks from their Secret
+	if task.Spec.Type == "sftp" && execErr == nil {
+		if env.fileTransfer, err = r.resolveFileTransferCredentials(ctx, task); err != nil {
+			execErr = err
+			output = fmt.Sprintf("Error: %s", err.Error())
+		}
+	}
+
+	// Read the environment variables of secretRefs just before executing
+	if len(task.Spec.SecretRefs) > 0 && execErr == nil {
+		if env.secretEnv, env.secrets, err = r.resolveSecretRefs(ctx, task); err != nil {
+			execErr = err
+			output = fmt.Sprintf("Error: %s", err.Error())
+		}
+	}
+
+	// Read the script of cmd tasks from their ConfigMap
+	if task.Spec.ScriptRef != nil && execErr == nil {
+		if env.script, err = r.resolveScript(ctx, task); err != nil {
+			execErr = err
+			output = fmt.Sprintf("Error: %s", err.Error())
+		}
+	}
+
+	// Enforce the command policy on the commands as they will run
+	if execErr == nil {
+		if err = r.checkCommandPolicy(ctx, task, env); err != nil {
+			execErr = err
+			output = fmt.Sprintf("Error: %s", err.Error())
+		}
+	}
+
+	// Read the AUTH login of smtp tasks from their Secret
+	if task.Spec.Type == "smtp" && execErr == nil {
+		if env.smtpAuth, err = r.resolveSmtpCredentials(ctx, task); err != nil {
+			execErr = err
+			output = fmt.Sprintf("Error: %s", err.Error())
+		}
+	}
+
+	// Select the pod kubectl-exec tasks run their command in
+	if task.Spec.Type == "kubectl-exec" && execErr == nil {
+		if env.podExec, err = r.resolvePodExec(ctx, task); err != nil {
+			execErr = err
+			output = fmt.Sprintf("Error: %s", err.Error())
+		}
+	}
+
+	// Execute on the controller-wide executor pool
+	var executionTime time.Duration
+	var httpAttempts int32
+	if err := getExecutorPool().Run(ctx, func() {
+		if execErr != nil {
+			return
+		}
+		executionStart := time.Now()
+		defer func() { executionTime = time.Since(executionStart) }()
+
+		// Apply failure injection (chaos testing) before executing
+		if injectedErr := applyFailureInjection(task, taskTimeout, logger); injectedErr != nil {
+			execErr = injectedErr
+			output = fmt.Sprintf("Error: %s", injectedErr.Error())
+		} else {
+			output, httpAttempts, execErr = executeTaskWithEnv(execTask, taskTimeout, logger, env)
+			task.Status.HTTPStatusCode = execTask.Status.HTTPStatusCode
+		}
+	}); err != nil {
+		logger.Info("Task execution not started, requeueing", "task", task.Name, "reason", err.Error())
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+	// The inputs of cmd tasks report their errors in the output
+	timedOut := isTimeoutError(execErr) || (execErr != nil && strings.Contains(output, "Error: command execution timed out"))
+
+	// Contract check: compare the JSON response against the golden document
+	var responseDiff []mcallv1.ResponseDiff
+	if execErr == nil && (task.Spec.Type == "get" || task.Spec.Type == "post") &&
+		task.Spec.HttpValidation != nil && task.Spec.HttpValidation.GoldenResponse != nil {
+		responseDiff, execErr = r.validateGoldenResponse(ctx, task, output)
+		if execErr == nil && len(responseDiff) > 0 {
+			execErr = fmt.Errorf("response differs from golden document in %d field(s)", len(responseDiff))
+		}
+	}
+
+	// Check the output against the task's validation rules
+	if execErr == nil {
+		if execErr = validateTaskOutput(task, output); execErr != nil {
+			recordTaskValidationFailure(task)
+		}
+	}
+
+	// Latency threshold: slow responses fail the check or mark the task Degraded
+	var latencyExceeded bool
+	if execErr == nil && (task.Spec.Type == "get" || task.Spec.Type == "post") {
+		latencyExceeded, execErr = checkLatency(task.Spec.HttpValidation, executionTime)
+	}
+
+	// Mask the values of secretRefs and sensitive data before the result is logged or stored
+	output = maskOutput(output, env.secrets, env.maskPatterns)
+	if execErr != nil {
+		execErr = errors.New(maskOutput(execErr.Error(), env.secrets, env.maskPatterns))
+	}
+
+	// Set result based on execution
+	if execErr != nil {
+		errCode = "-1"
+		errMsg = execErr.Error()
+		logger.Error(execErr, "Task execution failed", "task", task.Name)
+	} else {
+		errCode = "0"
+		errMsg = ""
+		logger.Info("Task execution completed successfully", "task", task.Name)
+	}
+
+	// Log to configured backend if logging is enabled
+	loggingConfig := env.logging
+	if loggingConfig.Enabled {
+		logEntry := taskLogEntry(task)
+		logEntry.ServiceName = task.Name
+		logEntry.ServiceType = task.Spec.Type
+		logEntry.Status = "UP"
+		if execErr != nil {
+			logEntry.Status = "DOWN"
+		}
+		logEntry.ErrorCode = errCode
+		logEntry.Error = errMsg
+		logEntry.ResponseTime = executionTime.Milliseconds()
+		logEntry.Timestamp = time.Now()
+		logEntry.Output = output
+
+		if err := EnqueueLog(logEntry, loggingConfig); err != nil {
+			logger.Error(err, "Failed to queue log entry", "task", task.Name, "backends", enabledLoggingBackends(loggingConfig))
+		} else {
+			logger.V(debugLevel).Info("Queued log entry", "task", task.Name, "status", logEntry.Status, "backends", enabledLoggingBackends(loggingConfig))
+		}
+	}
+
+	// Update task status
+	if execErr != nil {
+		task.Status.Phase = mcallv1.McallTaskPhaseFailed
+	} else {
+		task.Status.Phase = mcallv1.McallTaskPhaseSucceeded
+	}
+	recordTaskExecution(task, task.Status.Phase, executionTime)
+
+	completionTime := time.Now()
+	task.Status.CompletionTime = &metav1.Time{Time: completionTime}
+
+	// Record the wall time of the execution itself, excluding executor queueing and input resolution
+	task.Status.ExecutionTimeMs = executionTime.Milliseconds()
+
+	// Bound the output kept in etcd; the full output may overflow to a ConfigMap
+	result := newTaskResult(task, output)
+	result.ErrorCode = errCode
+	result.ErrorMessage = errMsg
+	result.ResponseDiff = responseDiff
+	result.Attempts = httpAttempts
+	task.Status.Result = result
+	if config := getArtifactStoreConfig(); config.Type != "" && output != "" && (result.OutputTruncated || config.AllOutputs) {
+		if result.OutputURL, err = uploadArtifact(ctx, config, artifactKey(config, task), []byte(output)); err != nil {
+			logger.Error(err, "Failed to upload output to the artifact store", "task", task.Name)
+		}
+	}
+	if result.OutputConfigMap != "" {
+		if err := r.storeOutputConfigMap(ctx, task, result.OutputConfigMap, output); err != nil {
+			logger.Error(err, "Failed to store full output", "task", task.Name, "configMap", result.OutputConfigMap)
+			result.OutputConfigMap = ""
+		}
+	}
+
+	// Record this execution as a McallTaskRun
+	taskRunName, err := r.createTaskRun(ctx, task)
+	if err != nil {
+		logger.Error(err, "Failed to record task run", "task", task.Name)
+	}
+
+	// Retry failed executions with exponential backoff before marking the task Failed
+	var nextRetry time.Duration
+	if execErr != nil && task.Status.RetryCount < task.Spec.RetryCount {
+		nextRetry = retryDelay(task.Spec.RetryBackoff, task.Status.RetryCount)
+		logger.Info("Task execution failed, scheduling retry",
+			"task", task.Name,
+			"retry", task.Status.RetryCount+1,
+			"retryCount", task.Spec.RetryCount,
+			"delay", nextRetry)
+	}
+
+	// Update with retry on conflict
+	updateErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// Get the latest version
+		latest := &mcallv1.McallTask{}
+		if err := r.Get(ctx, types.NamespacedName{
+			Name:      task.Name,
+			Namespace: task.Namespace,
+		}, latest); err != nil {
+			return err
+		}
+
+		// Apply changes to latest version
+		latest.Status.Phase = task.Status.Phase
+		latest.Status.CompletionTime = task.Status.CompletionTime
+		latest.Status.ExecutionTimeMs = task.Status.ExecutionTimeMs
+		latest.Status.HTTPStatusCode = task.Status.HTTPStatusCode
+		latest.Status.Result = result.DeepCopy()
+		if taskRunName != "" {
+			latest.Status.LastTaskRun = taskRunName
+		}
+		if validation := task.Spec.HttpValidation; validation != nil && validation.LatencyAction == "degrade" {
+			setDegradedCondition(latest, latencyExceeded, validation.MaxLatencyMs)
+		}
+		if nextRetry > 0 {
+			latest.Status.Phase = mcallv1.McallTaskPhasePending
+			latest.Status.RetryCount = task.Status.RetryCount + 1
+			latest.Status.NextRetryTime = &metav1.Time{Time: time.Now().Add(nextRetry)}
+		}
+
+		setTaskConditions(latest)
+		return r.Status().Update(ctx, latest)
+	})
+
+	if updateErr != nil {
+		logger.Error(updateErr, "Failed to update task status after retries", "task", task.Name)
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
+	if nextRetry > 0 {
+		recordEvent(r.Recorder, task, corev1.EventTypeWarning, EventReasonRetrying, "Execution failed, retry %d/%d in %s: %s",
+			task.Status.RetryCount+1, task.Spec.RetryCount, nextRetry, truncateString(errMsg, eventMessageMaxLength-128))
+		return ctrl.Result{RequeueAfter: nextRetry}, nil
+	}
+	if execErr != nil {
+		recordFailureEvent(r.Recorder, task, timedOut, errMsg)
+	} else {
+		recordEvent(r.Recorder, task, corev1.EventTypeNormal, EventReasonSucceeded, "Execution succeeded in %s", executionTime.Round(time.Millisecond))
+	}
+
+	logger.Info("Task status updated",
+		"task", task.Name,
+		"phase", task.Status.Phase,
+		"errorCode", errCode)
+
+	r.notifyTaskResult(ctx, task)
+
+	return ctrl.Result{}, nil
+}
+
+// notifyTaskResult sends the failure notification of a failed task, or its recovery once the
+// task succeeds, if notifiers are configured. Recoveries from a known downtime are also logged.
+func (r *McallTaskReconciler) notifyTaskResult(ctx context.Context, task *mcallv1.McallTask) {
+	logger := log.FromContext(ctx)
+
+	// The state of the task is recorded without notifiers too, for the recovery log entries
+	config, err := notifierConfigFor(ctx, r.Client, task)
+	if err != nil {
+		logger.Error(err, "Failed to get notifier configuration", "task", task.Name)
+		config.Enabled = false
+	}
+
+	notification := taskFailureNotification(task)
+	switch task.Status.Phase {
+	case mcallv1.McallTaskPhaseFailed:
+		if err := NotifyFailure(notification, config); err != nil {
+			logger.Error(err, "Failed to send task failure notification", "task", task.Name, "notifiers", config.Types)
+		} else if config.Enabled {
+			logger.Info("Sent task failure notification", "task", task.Name, "notifiers", config.Types)
+		}
+	case mcallv1.McallTaskPhaseSucceeded:
+		downtime, err := NotifyRecovery(notification, config)
+		if err != nil {
+			logger.Error(err, "Failed to send task recovery notification", "task", task.Name, "notifiers", config.Types)
+		}
+		if downtime > 0 {
+			logger.Info("Task recovered", "task", task.Name, "downtime", downtime)
+			r.logTaskRecovery(ctx, task, downtime)
+		}
+	}
+}
+
+// logTaskRecovery logs the recovery of a task to the logging backends, with its downtime
+func (r *McallTaskReconciler) logTaskRecovery(ctx context.Context, task *mcallv1.McallTask, downtime time.Duration) {
+	logger := log.FromContext(ctx)
+
+	loggingConfig, err := resolveLoggingConfig(ctx, r, task.Namespace)
+	if err != nil {
+		logger.Error(err, "Failed to resolve logging configuration, using the operator's", "task", task.Name)
+		loggingConfig = GetLoggingConfig()
+	}
+	if !loggingConfig.Enabled {
+		return
+	}
+
+	logEntry := taskLogEntry(task)
+	logEntry.ServiceName = task.Name
+	logEntry.ServiceType = task.Spec.Type
+	logEntry.Status = "RECOVERED"
+	logEntry.ErrorCode = "0"
+	logEntry.Timestamp = time.Now()
+	logEntry.Output = fmt.Sprintf("Recovered after %s of downtime", downtime.Round(time.Second))
+	if err := EnqueueLog(logEntry, loggingConfig); err != nil {
+		logger.Error(err, "Failed to queue recovery log entry", "task", task.Name, "backends", enabledLoggingBackends(loggingConfig))
+	}
+}
+
+// executeTask executes the task input based on its type and returns the output
+func executeTask(task *mcallv1.McallTask, taskTimeout time.Duration, logger logr.Logger) (string, error) {
+	output, _, err := executeTaskWithEnv(task, taskTimeout, logger, executionEnv{})
+	return output, err
+}
+
+// executionEnv holds what the reconciler resolves from the cluster before an execution
+type executionEnv struct {
+	tlsConfig    *tls.Config              // Client certificate and CA bundle for HTTP and gRPC requests
+	sqlDSN       string                   // Data source name of sql tasks, read from their Secret
+	podExec      *podExecTarget           // Pod selected for kubectl-exec tasks
+	fileTransfer *fileTransferCredentials // Login of sftp tasks, read from their Secret
+	smtpAuth     *smtpCredentials         // AUTH login of smtp tasks, nil without smtp.secretName
+	script       string                   // Script of cmd tasks with scriptRef, read from their ConfigMap
+	secretEnv    map[string]string        // Environment variables from secretRefs
+	secrets      []string                 // Values read for secretRefs, masked in the output
+	maskPatterns []*regexp.Regexp         // Custom masking rules of the operator and task namespace
+	logging      LoggingConfig            // Logging configuration of the task namespace
+	trace        context.Context          // Span of the task execution, parent of the input spans
+}
+
+// executeTaskWithEnv executes the task input with the resolved execution environment.
+// It also returns the number of HTTP requests made by get/post tasks.
+func executeTaskWithEnv(task *mcallv1.McallTask, taskTimeout time.Duration, logger logr.Logger, env executionEnv) (string, int32, error) {
+	var output string
+	var execErr error
+	var attempts int32
+
+	// The command, URL or target with ${NAME} environment variables substituted; JSON inputs
+	// are interpolated one by one and sql queries not at all, so variables can't inject SQL
+	environment := taskEnvironment(task, env)
+	taskInput := interpolateEnvironment(task.Spec.Input, environment)
+
+	switch task.Spec.Type {
+	case "cmd":
+		// A script from a ConfigMap takes the input as its arguments
+		if task.Spec.ScriptRef != nil {
+			output, execErr = executeScript(env.script, strings.Fields(taskInput), taskTimeout, commandOptionsFor(task, environment))
+			break
+		}
+
+		// Parse JSON inputs (like original mcall.go)
+		jsonInputs, err := parseJSONInputs(task.Spec.Input)
+		if err == nil {
+			err = resolveInputStdin(jsonInputs, task.Spec.Environment)
+		}
+		if err != nil {
+			logger.Error(err, "Failed to parse JSON inputs", "task", task.Name)
+			execErr = err
+			output = fmt.Sprintf("Error parsing inputs: %v", err)
+		} else {
+			// Process inputs using mcall structure
+			logger.V(debugLevel).Info("Processing inputs", "task", task.Name, "count", len(jsonInputs))
+
+			var results []string
+			var hasErrors bool
+
+			// Create TaskWorkers for each input (like original mcall.go)
+			workers := make([]*TaskWorker, 0, len(jsonInputs))
+			for i, input := range jsonInputs {
+				inputStr, ok := input["input"].(string)
+				if !ok {
+					continue
+				}
 
 				inputType := "cmd"
 				if t, exists := input["type"].(string); exists {
@@ -1411,16 +2943,47 @@ func (r *McallTaskReconciler) handleRunning(ctx context.Context, task *mcallv1.M
 				if er, exists := input["expectedResponse"]; exists {
 					if erMap, ok := er.(map[string]interface{}); ok {
 						expectedResponse = erMap
-						logger.Info("Found expectedResponse", "task", task.Name, "input", i+1, "expectedResponse", expectedResponse)
+						logger.V(debugLevel).Info("Found expectedResponse", "task", task.Name, "input", i+1, "expectedResponse", expectedResponse)
 					} else {
-						logger.Info("expectedResponse exists but not a map", "task", task.Name, "input", i+1, "type", fmt.Sprintf("%T", er), "value", er)
+						logger.Info("expectedResponse is not a map, ignoring it", "task", task.Name, "input", i+1, "type", fmt.Sprintf("%T", er))
 					}
 				} else {
-					logger.Info("No expectedResponse found", "task", task.Name, "input", i+1, "available_keys", getMapKeys(input))
+					logger.V(debugLevel).Info("No expectedResponse found", "task", task.Name, "input", i+1, "available_keys", getMapKeys(input))
+				}
+
+				// Each input is interpolated after parsing, so values can't break the JSON
+				if inputType != "sql" {
+					inputStr = interpolateEnvironment(inputStr, environment)
 				}
 
 				// Create worker with expect validation (like mcall.go)
 				worker := NewTaskWorker(inputStr, inputType, name, expect)
+				if t, exists := input["timeout"].(float64); exists && t > 0 {
+					worker.timeout = time.Duration(t * float64(time.Second))
+				}
+				worker.http = parseInputHTTPOptions(input)
+				worker.command = commandOptionsFor(task, environment)
+				worker.secrets, worker.maskPatterns = env.secrets, env.maskPatterns
+				worker.command.Stdin = parseInputStdin(input)
+				if ms, exists := input["maxLatencyMs"].(float64); exists && ms > 0 {
+					worker.maxLatency = time.Duration(ms) * time.Millisecond
+				}
+				worker.http.TLSConfig = env.tlsConfig
+				worker.retry = task.Spec.HttpRetry
+				worker.grpc = parseInputGrpcCall(input)
+				worker.sql, worker.sqlDSN = task.Spec.Sql, env.sqlDSN
+				worker.kafka = task.Spec.Kafka
+				worker.graphql = task.Spec.GraphQL
+				worker.podExec = env.podExec
+				worker.sftp, worker.fileTransfer = task.Spec.Sftp, env.fileTransfer
+				worker.smtp, worker.smtpAuth = task.Spec.Smtp, env.smtpAuth
+				worker.websocket = task.Spec.WebSocket
+				worker.trace = env.trace
+				if len(jsonInputs) > 1 {
+					// Log each input of a multi-input task as its own service
+					worker.service = task.Name + "/" + name
+					worker.logEntry, worker.logging = taskLogEntry(task), env.logging
+				}
 				workers = append(workers, worker)
 			}
 
@@ -1463,120 +3026,72 @@ func (r *McallTaskReconciler) handleRunning(ctx context.Context, task *mcallv1.M
 			}
 		}
 
-	case "get":
-		var httpStatus int
-		output, httpStatus, execErr = executeHTTPRequest(task.Spec.Input, "GET", taskTimeout)
-		task.Status.HTTPStatusCode = httpStatus
-
-	case "post":
-		var httpStatus int
-		output, httpStatus, execErr = executeHTTPRequest(task.Spec.Input, "POST", taskTimeout)
-		task.Status.HTTPStatusCode = httpStatus
-
-	default:
-		// Default to cmd execution
-		output, execErr = executeCommand(task.Spec.Input, taskTimeout)
-	}
-
-	// Set result based on execution
-	if execErr != nil {
-		errCode = "-1"
-		errMsg = execErr.Error()
-		logger.Error(execErr, "Task execution failed", "task", task.Name)
-	} else {
-		errCode = "0"
-		errMsg = ""
-		logger.Info("Task execution completed successfully", "task", task.Name)
-	}
-
-	// Log to configured backend if logging is enabled
-	loggingConfig := GetLoggingConfig()
-	if loggingConfig.Enabled {
-		logEntry := LogEntry{
-			ServiceName: task.Name,
-			ServiceType: task.Spec.Type,
-			Status: func() string {
-				if execErr != nil {
-					return "DOWN"
-				}
-				return "UP"
-			}(),
-			Error: errMsg,
-			ResponseTime: func() int64 {
-				// Calculate response time (simplified - you might want to measure actual execution time)
-				if execErr != nil {
-					return 5000 // Default error response time
-				}
-				return 150 // Default success response time
-			}(),
-			Timestamp: time.Now(),
+	case "get", "post", "graphql":
+		opts := httpRequestOptionsFor(task)
+		opts.TLSConfig = env.tlsConfig
+		opts.Headers = traceHeaders(env.trace, opts.Headers)
+		method := strings.ToUpper(task.Spec.Type)
+		if task.Spec.Type == "graphql" {
+			body, err := graphqlRequestBody(task.Spec.GraphQL)
+			if err != nil {
+				execErr = err
+				break
+			}
+			method, opts.Body, opts.ContentType = "POST", body, "application/json"
+		}
+		timeout := taskTimeout
+		if validation := task.Spec.HttpValidation; validation != nil && validation.ResponseTimeout > 0 {
+			timeout = time.Duration(validation.ResponseTimeout) * time.Second
 		}
 
-		if err := LogToBackend(logEntry, loggingConfig); err != nil {
-			logger.Error(err, "Failed to log to backend", "task", task.Name, "backend", loggingConfig.Backend)
-		} else {
-			logger.Info("Successfully logged to backend", "task", task.Name, "status", logEntry.Status, "backend", loggingConfig.Backend)
+		// Clear the status code of the previous execution in case this request gets no response
+		task.Status.HTTPStatusCode = 0
+		var resp *httpResponse
+		var err error
+		resp, attempts, err = doHTTPRequestWithRetry(taskInput, method, timeout, opts, task.Spec.HttpRetry)
+		if resp != nil {
+			output = resp.Body
+			task.Status.HTTPStatusCode = resp.StatusCode
+		}
+		execErr = err
+		if execErr == nil {
+			execErr = checkHTTPResponse(task.Spec.HttpValidation, resp)
+		}
+		if execErr == nil && task.Spec.Type == "graphql" {
+			execErr = checkGraphQLResponse(resp.Body)
 		}
-	}
 
-	// Update task status
-	if execErr != nil {
-		task.Status.Phase = mcallv1.McallTaskPhaseFailed
-	} else {
-		task.Status.Phase = mcallv1.McallTaskPhaseSucceeded
-	}
+	case "grpc":
+		output, execErr = executeGrpcRequest(taskInput, task.Spec.Grpc, taskTimeout, env.tlsConfig)
 
-	completionTime := time.Now()
-	task.Status.CompletionTime = &metav1.Time{Time: completionTime}
+	case "sql":
+		output, execErr = executeSQLQuery(task.Spec.Sql, env.sqlDSN, task.Spec.Input, taskTimeout)
 
-	// Calculate execution time in milliseconds
-	if task.Status.StartTime != nil {
-		executionDuration := completionTime.Sub(task.Status.StartTime.Time)
-		task.Status.ExecutionTimeMs = executionDuration.Milliseconds()
-	}
+	case "kafka":
+		output, execErr = executeKafkaCheck(taskInput, task.Spec.Kafka, taskTimeout)
 
-	task.Status.Result = &mcallv1.McallTaskResult{
-		Output:       output,
-		ErrorCode:    errCode,
-		ErrorMessage: errMsg,
-	}
+	case "kubectl-exec":
+		output, execErr = executePodExec(env.podExec, taskInput, taskTimeout)
 
-	// Update with retry on conflict
-	updateErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		// Get the latest version
-		latest := &mcallv1.McallTask{}
-		if err := r.Get(ctx, types.NamespacedName{
-			Name:      task.Name,
-			Namespace: task.Namespace,
-		}, latest); err != nil {
-			return err
-		}
+	case "sftp":
+		output, execErr = executeFileTransfer(taskInput, task.Spec.Sftp, env.fileTransfer, taskTimeout)
 
-		// Apply changes to latest version
-		latest.Status.Phase = task.Status.Phase
-		latest.Status.CompletionTime = task.Status.CompletionTime
-		latest.Status.ExecutionTimeMs = task.Status.ExecutionTimeMs
-		latest.Status.HTTPStatusCode = task.Status.HTTPStatusCode
-		latest.Status.Result = &mcallv1.McallTaskResult{
-			Output:       output,
-			ErrorCode:    errCode,
-			ErrorMessage: errMsg,
-		}
+	case "smtp":
+		output, execErr = executeSMTPCheck(taskInput, task.Spec.Smtp, env.smtpAuth, taskTimeout, env.tlsConfig)
 
-		return r.Status().Update(ctx, latest)
-	})
+	case "websocket":
+		output, execErr = executeWebSocketCheck(taskInput, task.Spec.WebSocket, task.Spec.Headers, taskTimeout, env.tlsConfig)
 
-	if updateErr != nil {
-		logger.Error(updateErr, "Failed to update task status after retries", "task", task.Name)
-		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
-	}
+	case "tcp":
+		// Read the banner only when outputValidation needs to match it
+		output, execErr = executeTCPCheck(taskInput, taskTimeout, task.Spec.OutputValidation != nil)
 
-	logger.Info("Task status updated",
-		"task", task.Name,
-		"phase", task.Status.Phase,
-		"errorCode", errCode)
+	default:
+		// Default to cmd execution
+		output, execErr = executeCommand(taskInput, taskTimeout, commandOptionsFor(task, environment))
+	}
 
-	return ctrl.Result{}, nil
+	return output, attempts, execErr
 }
 
 func (r *McallTaskReconciler) handleCompleted(ctx context.Context, task *mcallv1.McallTask) (ctrl.Result, error) {
@@ -1584,6 +3099,28 @@ func (r *McallTaskReconciler) handleCompleted(ctx context.Context, task *mcallv1
 	return ctrl.Result{}, nil
 }
 
+// handleBlocked returns a blocked task to Pending once its type is enabled again
+func (r *McallTaskReconciler) handleBlocked(ctx context.Context, task *mcallv1.McallTask) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	disabledTypes, err := getDisabledTaskTypes(ctx, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if disabledTaskType(task, disabledTypes) != "" {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	log.Info("Task type enabled again, unblocking task", "task", task.Name, "type", task.Spec.Type)
+	task.Status.Phase = mcallv1.McallTaskPhasePending
+	task.Status.Result = nil
+	setTaskConditions(task)
+	if err := r.Status().Update(ctx, task); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
 func (r *McallTaskReconciler) handleDeletion(ctx context.Context, task *mcallv1.McallTask) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
 	log.Info("Handling deletion", "task", task.Name)
@@ -1600,6 +3137,11 @@ func (r *McallTaskReconciler) handleDeletion(ctx context.Context, task *mcallv1.
 		return ctrl.Result{}, err
 	}
 
+	// Workflow task instances are recreated with the same name on every run, keep their series
+	if _, inWorkflow := task.Labels["mcall.tz.io/workflow"]; !inWorkflow {
+		deleteTaskMetrics(task)
+	}
+
 	// Remove finalizer to allow deletion
 	task.Finalizers = removeString(task.Finalizers, mcallv1.McallTaskFinalizer)
 	if err := r.Update(ctx, task); err != nil {
@@ -1611,6 +3153,7 @@ func (r *McallTaskReconciler) handleDeletion(ctx context.Context, task *mcallv1.
 	return ctrl.Result{}, nil
 }
 
+// checkDependencies reports whether all dependencies of a task succeeded or failed with continueOn: failure
 func (r *McallTaskReconciler) checkDependencies(ctx context.Context, task *mcallv1.McallTask) (bool, error) {
 	for _, depName := range task.Spec.Dependencies {
 		var depTask mcallv1.McallTask
@@ -1620,17 +3163,106 @@ func (r *McallTaskReconciler) checkDependencies(ctx context.Context, task *mcall
 		}, &depTask); err != nil {
 			return false, err
 		}
-		if depTask.Status.Phase != mcallv1.McallTaskPhaseSucceeded {
+		if depTask.Status.Phase != mcallv1.McallTaskPhaseSucceeded && !toleratedFailure(&depTask) {
 			return false, nil
 		}
 	}
 	return true, nil
 }
 
+// getConcurrencyQueuePosition returns the queue position of a task for its concurrency key.
+// 0 means no other task with the same key is running or queued ahead, so the task may start.
+func (r *McallTaskReconciler) getConcurrencyQueuePosition(ctx context.Context, task *mcallv1.McallTask) (int32, error) {
+	// Concurrency keys are cluster-wide, so list tasks in all namespaces
+	var tasks mcallv1.McallTaskList
+	if err := r.List(ctx, &tasks); err != nil {
+		return 0, err
+	}
+
+	running := false
+	var ahead int32
+	for _, other := range tasks.Items {
+		if other.Spec.ConcurrencyKey != task.Spec.ConcurrencyKey ||
+			(other.Namespace == task.Namespace && other.Name == task.Name) {
+			continue
+		}
+
+		switch other.Status.Phase {
+		case mcallv1.McallTaskPhaseRunning:
+			running = true
+		case mcallv1.McallTaskPhasePending:
+			// Only tasks already waiting in queue and queued before this one are ahead of it
+			if other.Status.QueuePosition > 0 && queuedBefore(&other, task) {
+				ahead++
+			}
+		}
+	}
+
+	if !running && ahead == 0 {
+		return 0, nil
+	}
+	return ahead + 1, nil
+}
+
+// getStaggerWait returns how long a workflow task must wait before starting so that
+// starts are spaced by the workflow's staggerSeconds (0 when it may start now)
+func (r *McallTaskReconciler) getStaggerWait(ctx context.Context, task *mcallv1.McallTask) (time.Duration, error) {
+	staggerStr, exists := task.Annotations["mcall.tz.io/stagger-seconds"]
+	workflowName, inWorkflow := task.Labels["mcall.tz.io/workflow"]
+	if !exists || !inWorkflow {
+		return 0, nil
+	}
+
+	staggerSeconds, err := strconv.Atoi(staggerStr)
+	if err != nil || staggerSeconds <= 0 {
+		return 0, nil
+	}
+
+	var tasks mcallv1.McallTaskList
+	if err := r.List(ctx, &tasks, client.InNamespace(task.Namespace),
+		client.MatchingLabels{"mcall.tz.io/workflow": workflowName}); err != nil {
+		return 0, err
+	}
+
+	// Find the most recent start among the other tasks of the workflow
+	var lastStart time.Time
+	for _, other := range tasks.Items {
+		if other.Name == task.Name || other.Status.StartTime == nil {
+			continue
+		}
+		if other.Status.StartTime.After(lastStart) {
+			lastStart = other.Status.StartTime.Time
+		}
+	}
+	if lastStart.IsZero() {
+		return 0, nil
+	}
+
+	return time.Until(lastStart.Add(time.Duration(staggerSeconds) * time.Second)), nil
+}
+
+// queuedBefore reports whether task a was created before task b (name breaks ties)
+func queuedBefore(a, b *mcallv1.McallTask) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Namespace+"/"+a.Name < b.Namespace+"/"+b.Name
+}
+
 func (r *McallTaskReconciler) shouldRunScheduledTask(ctx context.Context, task *mcallv1.McallTask) (bool, error) {
-	// Implement cron schedule checking logic
-	// For now, always return true
-	return true, nil
+	// First run of a scheduled task
+	if task.Status.CompletionTime == nil {
+		return true, nil
+	}
+
+	// Share next run computation with workflows
+	scheduler := NewCronScheduler(r.Client)
+	nextRun, err := scheduler.NextRunTime(task.Spec.Schedule, task.Status.CompletionTime.Time)
+	if err != nil {
+		return false, err
+	}
+
+	return !time.Now().Before(nextRun), nil
 }
 
 func (r *McallTaskReconciler) createExecutionPod(ctx context.Context, task *mcallv1.McallTask) error {
@@ -1680,6 +3312,42 @@ func (r *McallTaskReconciler) cleanupAssociatedResources(ctx context.Context, ta
 		}
 	}
 
+	// Clean up task runs of standalone tasks. Workflow task instances are recreated
+	// under the same name on every run, so their task runs are kept as history.
+	if _, inWorkflow := task.Labels["mcall.tz.io/workflow"]; !inWorkflow {
+		if err := r.DeleteAllOf(ctx, &corev1.ConfigMap{}, client.InNamespace(task.Namespace),
+			client.MatchingLabels{"mcall.tz.io/output-of": task.Name}); err != nil {
+			log.Error(err, "Failed to delete output configmaps", "task", task.Name)
+			return err
+		}
+
+		var taskRuns mcallv1.McallTaskRunList
+		if err := r.List(ctx, &taskRuns, client.InNamespace(task.Namespace),
+			client.MatchingLabels{"mcall.tz.io/task-run-of": task.Name}); err != nil {
+			return err
+		}
+
+		for _, taskRun := range taskRuns.Items {
+			log.Info("Deleting task run", "taskRun", taskRun.Name, "task", task.Name)
+			if err := r.Delete(ctx, &taskRun); err != nil && !apierrors.IsNotFound(err) {
+				log.Error(err, "Failed to delete task run", "taskRun", taskRun.Name, "task", task.Name)
+				return err
+			}
+		}
+	}
+
+	// Clean up a warm-up copy still running in the sandbox namespace
+	if task.Spec.WarmUp != nil {
+		warmUp := &mcallv1.McallTask{ObjectMeta: metav1.ObjectMeta{
+			Name:      warmUpTaskName(task),
+			Namespace: task.Spec.WarmUp.Namespace,
+		}}
+		if err := r.Delete(ctx, warmUp); err != nil && !apierrors.IsNotFound(err) {
+			log.Error(err, "Failed to delete warm-up task", "warmUp", warmUp.Name, "task", task.Name)
+			return err
+		}
+	}
+
 	// Add cleanup for other resource types as needed (secrets, services, etc.)
 
 	return nil
@@ -1717,9 +3385,18 @@ func (r *McallTaskReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		log.Error(nil, "CRDs not available after maximum retries", "maxRetries", maxRetries)
 	}()
 
-	return ctrl.NewControllerManagedBy(mgr).
+	c, err := ctrl.NewControllerManagedBy(mgr).
 		For(&mcallv1.McallTask{}).
-		Complete(r)
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
+		Build(r)
+	if err != nil {
+		return err
+	}
+
+	// Keep the controller to add watches for waitFor resource kinds on demand
+	r.controller = c
+	r.cache = mgr.GetCache()
+	return nil
 }
 
 // Helper functions
@@ -1755,80 +3432,10 @@ func renderTemplate(template string, data map[string]interface{}) string {
 	return result
 }
 
-// extractJSONPath extracts value from JSON string using simple path expression
-// Supports simple paths like: $.field, $.nested.field
-// For complex JSONPath, consider using github.com/oliveagle/jsonpath library
+// extractJSONPath extracts value from JSON string using a JSONPath expression, shared with
+// the output validation assertions
 func extractJSONPath(jsonStr string, path string) (string, error) {
-	var data interface{}
-	if err := json.Unmarshal([]byte(jsonStr), &data); err != nil {
-		return "", fmt.Errorf("invalid JSON: %w", err)
-	}
-
-	// Remove leading "$."
-	path = strings.TrimPrefix(path, "$.")
-	if path == "$" || path == "" {
-		// Return whole JSON
-		jsonBytes, _ := json.Marshal(data)
-		return string(jsonBytes), nil
-	}
-
-	// Split path by "."
-	fields := strings.Split(path, ".")
-	current := data
-
-	for _, field := range fields {
-		// Check for array index like "items[0]"
-		if strings.Contains(field, "[") {
-			re := regexp.MustCompile(`^([^\[]+)\[(\d+)\]$`)
-			matches := re.FindStringSubmatch(field)
-			if len(matches) == 3 {
-				fieldName := matches[1]
-				index, _ := strconv.Atoi(matches[2])
-
-				// Get field
-				if m, ok := current.(map[string]interface{}); ok {
-					current = m[fieldName]
-				} else {
-					return "", fmt.Errorf("field %s not found", fieldName)
-				}
-
-				// Get array element
-				if arr, ok := current.([]interface{}); ok {
-					if index < len(arr) {
-						current = arr[index]
-					} else {
-						return "", fmt.Errorf("array index %d out of bounds", index)
-					}
-				} else {
-					return "", fmt.Errorf("field %s is not an array", fieldName)
-				}
-				continue
-			}
-		}
-
-		// Regular field access
-		if m, ok := current.(map[string]interface{}); ok {
-			if val, exists := m[field]; exists {
-				current = val
-			} else {
-				return "", fmt.Errorf("field %s not found in JSON", field)
-			}
-		} else {
-			return "", fmt.Errorf("cannot access field %s in non-object", field)
-		}
-	}
-
-	// Convert result to string
-	switch v := current.(type) {
-	case string:
-		return v, nil
-	case float64, int, int64, bool:
-		return fmt.Sprintf("%v", v), nil
-	default:
-		// Object or array - return as JSON
-		jsonBytes, _ := json.Marshal(v)
-		return string(jsonBytes), nil
-	}
+	return assertion.ExtractJSONPath(jsonStr, path)
 }
 
 // truncateString truncates a string for logging
@@ -1839,17 +3446,37 @@ func truncateString(s string, maxLen int) string {
 	return s[:maxLen] + "..."
 }
 
-// maskSensitiveData masks potentially sensitive information
+// maskSensitiveData masks potentially sensitive information. Only the values are replaced,
+// the keys keep their separators so masked JSON outputs stay valid JSON.
 var sensitivePatterns = []*regexp.Regexp{
-	regexp.MustCompile(`(?i)(password|passwd|pwd)["\s:=]+([^\s"]+)`),
-	regexp.MustCompile(`(?i)(api[-_]?key|apikey)["\s:=]+([^\s"]+)`),
-	regexp.MustCompile(`(?i)(token|secret)["\s:=]+([^\s"]+)`),
+	regexp.MustCompile(`(?i)((?:password|passwd|pwd)["\s:=]+)([^\s",}]+)`),
+	regexp.MustCompile(`(?i)((?:api[-_]?key|apikey)["\s:=]+)([^\s",}]+)`),
+	regexp.MustCompile(`(?i)((?:token|secret)["\s:=]+)([^\s",}]+)`),
 }
 
 func maskSensitiveData(output string) string {
 	masked := output
 	for _, pattern := range sensitivePatterns {
-		masked = pattern.ReplaceAllString(masked, "$1=***MASKED***")
+		masked = pattern.ReplaceAllString(masked, "${1}***MASKED***")
 	}
 	return masked
 }
+
+// sensitiveDataMaskingEnabled reports whether outputs go through maskSensitiveData;
+// MASK_SENSITIVE_DATA=false opts out, e.g. when outputs are already sanitized
+func sensitiveDataMaskingEnabled() bool {
+	return getEnvOrDefault("MASK_SENSITIVE_DATA", "true") != "false"
+}
+
+// maskOutput masks the values of secretRefs, sensitive data unless opted out, and the matches
+// of custom masking rules in an output before it is stored or sent to the logs and logging backend
+func maskOutput(s string, secrets []string, patterns []*regexp.Regexp) string {
+	s = maskSecrets(s, secrets)
+	if sensitiveDataMaskingEnabled() {
+		s = maskSensitiveData(s)
+	}
+	for _, pattern := range patterns {
+		s = pattern.ReplaceAllLiteralString(s, "***MASKED***")
+	}
+	return s
+}
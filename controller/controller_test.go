@@ -1,12 +1,26 @@
 package controller
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
 )
 
 // Test structures for monitoring and alerting
@@ -86,7 +100,7 @@ func TestExecuteCommand(t *testing.T) {
 			debugLog(t, "Command: %s, Timeout: %v, Expected Error: %v, Contains: %s",
 				tt.command, tt.timeout, tt.wantErr, tt.contains)
 
-			output, err := executeCommand(tt.command, tt.timeout)
+			output, err := executeCommand(tt.command, tt.timeout, commandOptions{})
 
 			debugStep(t, 4, "Analyzing command execution result")
 			debugLog(t, "Execution result - Output: %q, Error: %v", output, err)
@@ -120,6 +134,91 @@ func TestExecuteCommand(t *testing.T) {
 	debugStep(t, 7, "All test cases completed")
 }
 
+// TestExecuteCommandShell tests running commands and scripts with spec.shell and spec.workingDir
+func TestExecuteCommandShell(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name       string
+		command    string
+		script     bool
+		opts       commandOptions
+		wantOutput string
+		wantErr    string
+	}{
+		{name: "bash by default", command: `[[ -n $BASH_VERSION ]] && echo bash`, wantOutput: "bash\n"},
+		{name: "sh", command: `echo "${BASH_VERSION:-posix}"`, opts: commandOptions{Shell: "sh"}, wantOutput: "posix\n"},
+		{name: "working directory", command: "pwd", opts: commandOptions{WorkingDir: dir}, wantOutput: dir + "\n"},
+		{name: "script with sh in working directory", command: "echo \"${BASH_VERSION:-posix} $(pwd)\"\n", script: true, opts: commandOptions{Shell: "sh", WorkingDir: dir}, wantOutput: "posix " + dir + "\n"},
+		{name: "missing working directory", command: "pwd", opts: commandOptions{WorkingDir: dir + "/missing"}, wantErr: "command failed"},
+		{name: "unsupported shell", command: "echo", opts: commandOptions{Shell: "zsh"}, wantErr: `unsupported shell "zsh"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output string
+			var err error
+			if tt.script {
+				output, err = executeScript(tt.command, nil, 5*time.Second, tt.opts)
+			} else {
+				output, err = executeCommand(tt.command, 5*time.Second, tt.opts)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v, output = %q", err, output)
+			}
+			if output != tt.wantOutput {
+				t.Errorf("output = %q, want %q", output, tt.wantOutput)
+			}
+		})
+	}
+}
+
+// TestInputStdin tests feeding cmd inputs with stdin and stdinFrom
+func TestInputStdin(t *testing.T) {
+	environment := map[string]string{"REPORT": "line 1\nline \"2\"\n"}
+
+	tests := []struct {
+		name       string
+		input      string
+		wantOutput string
+		wantErr    string
+	}{
+		{name: "string", input: `[{"input": "tr a-z A-Z", "stdin": "hello"}]`, wantOutput: "HELLO"},
+		{name: "JSON value", input: `[{"input": "cat", "stdin": {"name": "api"}}]`, wantOutput: `{"name":"api"}`},
+		{name: "from input source", input: `[{"input": "wc -l", "stdinFrom": "REPORT"}]`, wantOutput: "2"},
+		{name: "no stdin", input: `[{"input": "cat"}]`, wantOutput: ""},
+		{name: "unknown stdinFrom", input: `[{"input": "cat", "stdinFrom": "MISSING"}]`, wantErr: `stdinFrom "MISSING"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{Name: "stdin", Namespace: "default"},
+				Spec:       mcallv1.McallTaskSpec{Type: "cmd", Input: tt.input, Environment: environment},
+			}
+			output, err := executeTask(task, 5*time.Second, logr.Discard())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("executeTask() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("executeTask() error = %v, output = %s", err, output)
+			}
+			if strings.TrimSpace(output) != tt.wantOutput {
+				t.Errorf("output = %q, want %q", output, tt.wantOutput)
+			}
+		})
+	}
+}
+
 func TestExecuteHTTPRequest(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -157,7 +256,7 @@ func TestExecuteHTTPRequest(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			output, err := executeHTTPRequest(tt.url, tt.method, tt.timeout)
+			output, _, err := executeHTTPRequest(tt.url, tt.method, tt.timeout)
 
 			if tt.wantErr {
 				if err == nil {
@@ -1587,6 +1686,232 @@ func TestKafkaBackend(t *testing.T) {
 	t.Logf("Kafka backend test completed successfully!")
 }
 
+// TestLokiBackend tests pushing log entries to Loki with labels, tenant and basic auth
+func TestLokiBackend(t *testing.T) {
+	var pushed struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][]string        `json:"values"`
+		} `json:"streams"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+		if r.URL.Path != "/loki/api/v1/push" || r.Header.Get("X-Scope-OrgID") != "team-a" || username != "mcall" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte("no org id"))
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&pushed); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	t.Setenv("LOGGING_ENABLED", "true")
+	t.Setenv("LOGGING_BACKEND", "loki")
+	t.Setenv("LOGGING_LOKI_ENABLED", "true")
+	t.Setenv("LOGGING_LOKI_URL", server.URL+"/")
+	t.Setenv("LOGGING_LOKI_TENANT_ID", "team-a")
+	t.Setenv("LOGGING_LOKI_USERNAME", "mcall")
+	t.Setenv("LOGGING_LOKI_PASSWORD", "secret")
+	config := GetLoggingConfig()
+
+	timestamp := time.Unix(1700000000, 5)
+	entry := LogEntry{
+		ServiceName:  "health-check",
+		ServiceType:  "get",
+		Status:       "DOWN",
+		Error:        "connection refused",
+		ResponseTime: 12,
+		Timestamp:    timestamp,
+		Namespace:    "default",
+		Workflow:     "nightly",
+		Output:       "connection refused",
+	}
+	if err := LogToBackend(entry, config); err != nil {
+		t.Fatalf("LogToBackend() error = %v", err)
+	}
+
+	if len(pushed.Streams) != 1 || len(pushed.Streams[0].Values) != 1 {
+		t.Fatalf("pushed = %+v, want one stream with one line", pushed)
+	}
+	wantLabels := map[string]string{
+		"job": "mcall-operator", "task": "health-check", "namespace": "default",
+		"workflow": "nightly", "type": "get", "status": "DOWN",
+	}
+	for name, value := range wantLabels {
+		if got := pushed.Streams[0].Stream[name]; got != value {
+			t.Errorf("label %s = %q, want %q", name, got, value)
+		}
+	}
+	value := pushed.Streams[0].Values[0]
+	if value[0] != "1700000000000000005" || !strings.Contains(value[1], `"error_message":"connection refused"`) {
+		t.Errorf("line = %v", value)
+	}
+
+	config.Loki.TenantID = ""
+	if err := LogToBackend(entry, config); err == nil || !strings.Contains(err.Error(), "status 401: no org id") {
+		t.Errorf("LogToBackend() error = %v, want the push failure", err)
+	}
+}
+
+// TestCloudWatchBackend tests putting log events, creating the log group and stream on first use
+func TestCloudWatchBackend(t *testing.T) {
+	var targets []string
+	streams := map[string]bool{}
+	var events []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := r.Header.Get("X-Amz-Target")
+		targets = append(targets, target)
+		if !strings.Contains(r.Header.Get("Authorization"), "Credential=AKIDLOGS/") ||
+			!strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/logs/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var input map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		name := fmt.Sprintf("%v/%v", input["logGroupName"], input["logStreamName"])
+		switch target {
+		case "Logs_20140328.CreateLogGroup":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceAlreadyExistsException","message":"The specified log group already exists"}`))
+		case "Logs_20140328.CreateLogStream":
+			streams[name] = true
+			_, _ = w.Write([]byte(`{}`))
+		case "Logs_20140328.PutLogEvents":
+			if !streams[name] {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"The specified log stream does not exist."}`))
+				return
+			}
+			for _, event := range input["logEvents"].([]interface{}) {
+				events = append(events, event.(map[string]interface{}))
+			}
+			_, _ = w.Write([]byte(`{"nextSequenceToken":"1"}`))
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_ENDPOINT_URL", server.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDLOGS")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "logs-secret")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("LOGGING_ENABLED", "true")
+	t.Setenv("LOGGING_BACKEND", "cloudwatch")
+	t.Setenv("LOGGING_CLOUDWATCH_ENABLED", "true")
+	config := GetLoggingConfig()
+
+	for _, service := range []string{"api", "db"} {
+		entry := LogEntry{ServiceName: service, ServiceType: "get", Status: "UP", Timestamp: time.UnixMilli(1700000000123), Namespace: "default"}
+		if err := LogToBackend(entry, config); err != nil {
+			t.Fatalf("LogToBackend() error = %v", err)
+		}
+	}
+
+	wantTargets := []string{
+		"Logs_20140328.PutLogEvents", "Logs_20140328.CreateLogGroup", "Logs_20140328.CreateLogStream",
+		"Logs_20140328.PutLogEvents", "Logs_20140328.PutLogEvents",
+	}
+	if strings.Join(targets, ",") != strings.Join(wantTargets, ",") {
+		t.Errorf("targets = %v, want %v", targets, wantTargets)
+	}
+	if !streams["/mcall/task-results/mcall-operator"] || len(events) != 2 {
+		t.Fatalf("streams = %v, events = %v", streams, events)
+	}
+	if events[1]["timestamp"] != float64(1700000000123) || !strings.Contains(events[1]["message"].(string), `"service_name":"db"`) {
+		t.Errorf("event = %v", events[1])
+	}
+
+	config.CloudWatch.AutoCreate = false
+	config.CloudWatch.LogStream = "other"
+	if err := LogToBackend(LogEntry{ServiceName: "api", Timestamp: time.Now()}, config); err == nil ||
+		!strings.Contains(err.Error(), "log stream /mcall/task-results/other not found") {
+		t.Errorf("LogToBackend() error = %v, want a missing log stream", err)
+	}
+}
+
+// TestLogToBackendFanOut tests logging to every enabled backend, isolating a failing one
+func TestLogToBackendFanOut(t *testing.T) {
+	var indexed atomic.Int32
+	elasticsearch := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/_bulk" {
+			body, _ := io.ReadAll(r.Body)
+			indexed.Add(int32(bytes.Count(body, []byte("\n")) / 2))
+			w.Write([]byte(`{"errors":false,"items":[]}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer elasticsearch.Close()
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer loki.Close()
+
+	t.Setenv("LOGGING_ENABLED", "true")
+	t.Setenv("LOGGING_BACKEND", "elasticsearch")
+	t.Setenv("LOGGING_ELASTICSEARCH_ENABLED", "true")
+	t.Setenv("LOGGING_ELASTICSEARCH_URL", elasticsearch.URL)
+	t.Setenv("LOGGING_ELASTICSEARCH_BATCH_TIMEOUT_MS", "10")
+	t.Setenv("LOGGING_LOKI_ENABLED", "true")
+	t.Setenv("LOGGING_LOKI_URL", loki.URL)
+	config := GetLoggingConfig()
+
+	if names := enabledLoggingBackends(config); strings.Join(names, ",") != "elasticsearch,loki" {
+		t.Errorf("enabledLoggingBackends() = %v", names)
+	}
+
+	err := LogToBackend(LogEntry{ServiceName: "api", Status: "UP", Timestamp: time.Now()}, config)
+	if err == nil || !strings.Contains(err.Error(), "loki: failed to log entry: Loki push failed with status 503") {
+		t.Errorf("LogToBackend() error = %v, want the Loki failure", err)
+	}
+	if strings.Contains(err.Error(), "elasticsearch") {
+		t.Errorf("LogToBackend() error = %v, want Elasticsearch to succeed", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for indexed.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if indexed.Load() != 1 {
+		t.Errorf("Elasticsearch indexed %d documents, want 1", indexed.Load())
+	}
+}
+
+// TestTaskLogEntry tests the workflow run fields and truncated outputs of log entries
+func TestTaskLogEntry(t *testing.T) {
+	task := &mcallv1.McallTask{ObjectMeta: metav1.ObjectMeta{
+		Name:      "nightly-build",
+		Namespace: "ci",
+		Labels:    map[string]string{"mcall.tz.io/workflow": "nightly", "mcall.tz.io/run": "nightly-42", "team": "platform"},
+	}}
+	entry := taskLogEntry(task)
+	if entry.Namespace != "ci" || entry.Workflow != "nightly" || entry.RunID != "nightly-42" || entry.Labels["team"] != "platform" {
+		t.Errorf("taskLogEntry() = %+v", entry)
+	}
+
+	entry.Output = strings.Repeat("x", 100)
+	entry, err := prepareLogEntry(entry, LoggingConfig{OutputMaxBytes: 10})
+	if err != nil {
+		t.Fatalf("prepareLogEntry() error = %v", err)
+	}
+	if entry.Output != strings.Repeat("x", 10) || !entry.OutputTruncated {
+		t.Errorf("prepareLogEntry() output = %q, truncated %v", entry.Output, entry.OutputTruncated)
+	}
+
+	fields := logEntryFields(entry)
+	if fields["run_id"] != "nightly-42" || fields["output_truncated"] != true {
+		t.Errorf("logEntryFields() = %v", fields)
+	}
+	if labels := logEntryLabels(entry); labels != `{"mcall.tz.io/run":"nightly-42","mcall.tz.io/workflow":"nightly","team":"platform"}` {
+		t.Errorf("logEntryLabels() = %v", labels)
+	}
+	if labels := logEntryLabels(LogEntry{}); labels != nil {
+		t.Errorf("logEntryLabels() without labels = %v, want nil", labels)
+	}
+}
+
 // TestLoggingBackendFactory tests the logging backend factory
 func TestLoggingBackendFactory(t *testing.T) {
 	tests := []struct {
@@ -1614,6 +1939,16 @@ func TestLoggingBackendFactory(t *testing.T) {
 			backend: "kafka",
 			wantErr: false,
 		},
+		{
+			name:    "loki_backend",
+			backend: "loki",
+			wantErr: false,
+		},
+		{
+			name:    "cloudwatch_backend",
+			backend: "cloudwatch",
+			wantErr: false,
+		},
 		{
 			name:    "unsupported_backend",
 			backend: "unsupported",
@@ -1652,3 +1987,613 @@ func TestLoggingBackendFactory(t *testing.T) {
 
 	t.Logf("Logging backend factory test completed successfully!")
 }
+
+// TestApplyFailureInjection tests failure injection annotations for chaos testing
+func TestApplyFailureInjection(t *testing.T) {
+	logger := logr.Discard()
+
+	newTask := func(annotation string) *mcallv1.McallTask {
+		task := &mcallv1.McallTask{}
+		task.Name = "chaos-task"
+		if annotation != "" {
+			task.Annotations = map[string]string{mcallv1.FailureInjectionAnnotation: annotation}
+		}
+		return task
+	}
+
+	tests := []struct {
+		name    string
+		enabled bool
+		value   string
+		wantErr string
+	}{
+		{name: "no annotation", enabled: true, value: "", wantErr: ""},
+		{name: "disabled by operator", enabled: false, value: `{"action":"fail"}`, wantErr: ""},
+		{name: "fail with default message", enabled: true, value: `{"action":"fail"}`, wantErr: "injected failure"},
+		{name: "fail with custom message", enabled: true, value: `{"action":"fail","message":"db down"}`, wantErr: "db down"},
+		{name: "timeout", enabled: true, value: `{"action":"timeout"}`, wantErr: "timed out"},
+		{name: "delay", enabled: true, value: `{"action":"delay","delaySeconds":0}`, wantErr: ""},
+		{name: "invalid json", enabled: true, value: `not-json`, wantErr: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.enabled {
+				t.Setenv("FAILURE_INJECTION_ENABLED", "true")
+			} else {
+				t.Setenv("FAILURE_INJECTION_ENABLED", "false")
+			}
+
+			err := applyFailureInjection(newTask(tt.value), 10*time.Millisecond, logger)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("applyFailureInjection() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("applyFailureInjection() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestKillSwitch tests blocking and unblocking tasks by type through the kill switch ConfigMap
+func TestKillSwitch(t *testing.T) {
+	t.Setenv("KILL_SWITCH_CONFIGMAP", "mcall-kill-switch")
+	t.Setenv("NAMESPACE", "mcall-system")
+
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "cmd-task", Namespace: "default"}
+	task := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Spec:       mcallv1.McallTaskSpec{Type: "cmd", Input: "echo ok"},
+		Status:     mcallv1.McallTaskStatus{Phase: mcallv1.McallTaskPhasePending},
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "mcall-kill-switch", Namespace: "mcall-system"},
+		Data:       map[string]string{"disabledTaskTypes": "post, cmd"},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&mcallv1.McallTask{}).
+		WithObjects(task, configMap).
+		Build()
+	r := &McallTaskReconciler{Client: fakeClient, Scheme: scheme}
+
+	// Disabled type is blocked instead of executed
+	if _, err := r.handlePending(ctx, task); err != nil {
+		t.Fatalf("handlePending() error = %v", err)
+	}
+	var blocked mcallv1.McallTask
+	if err := fakeClient.Get(ctx, key, &blocked); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if blocked.Status.Phase != mcallv1.McallTaskPhaseBlocked {
+		t.Fatalf("phase = %s, want %s", blocked.Status.Phase, mcallv1.McallTaskPhaseBlocked)
+	}
+	if blocked.Status.Result == nil || !strings.Contains(blocked.Status.Result.ErrorMessage, "kill switch") {
+		t.Errorf("result = %+v, want kill switch message", blocked.Status.Result)
+	}
+
+	// Still blocked while the type stays disabled
+	if _, err := r.handleBlocked(ctx, &blocked); err != nil {
+		t.Fatalf("handleBlocked() error = %v", err)
+	}
+	if blocked.Status.Phase != mcallv1.McallTaskPhaseBlocked {
+		t.Fatalf("phase = %s, want %s", blocked.Status.Phase, mcallv1.McallTaskPhaseBlocked)
+	}
+
+	// Re-enabling the type returns the task to Pending
+	configMap.Data["disabledTaskTypes"] = "post"
+	if err := fakeClient.Update(ctx, configMap); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if _, err := r.handleBlocked(ctx, &blocked); err != nil {
+		t.Fatalf("handleBlocked() error = %v", err)
+	}
+	var unblocked mcallv1.McallTask
+	if err := fakeClient.Get(ctx, key, &unblocked); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if unblocked.Status.Phase != mcallv1.McallTaskPhasePending || unblocked.Status.Result != nil {
+		t.Errorf("status = %+v, want Pending without result", unblocked.Status)
+	}
+}
+
+// TestDisabledTaskType tests the types the kill switch checks for a task: unknown types run as
+// cmd and the JSON inputs of cmd tasks are checked by their own type
+func TestDisabledTaskType(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     mcallv1.McallTaskSpec
+		disabled string
+		want     string
+	}{
+		{name: "disabled type", spec: mcallv1.McallTaskSpec{Type: "post"}, disabled: "post", want: "post"},
+		{name: "enabled type", spec: mcallv1.McallTaskSpec{Type: "get"}, disabled: "post", want: ""},
+		{name: "unknown type runs as cmd", spec: mcallv1.McallTaskSpec{Type: "shell", Input: "rm -rf /tmp/x"}, disabled: "cmd", want: "cmd"},
+		{name: "misspelled type runs as cmd", spec: mcallv1.McallTaskSpec{Type: "Get", Input: "curl http://api"}, disabled: "cmd", want: "cmd"},
+		{
+			name:     "kubectl-exec input of a cmd task",
+			spec:     mcallv1.McallTaskSpec{Type: "cmd", Input: `[{"name": "shell", "type": "kubectl-exec", "input": "ls"}]`},
+			disabled: "kubectl-exec",
+			want:     "kubectl-exec",
+		},
+		{
+			name:     "sql input of a cmd task",
+			spec:     mcallv1.McallTaskSpec{Type: "cmd", Input: `{"inputs": [{"name": "ping", "type": "get", "input": "http://api"}, {"name": "rows", "type": "sql", "input": "SELECT 1"}]}`},
+			disabled: "sql,kafka",
+			want:     "sql",
+		},
+		{
+			name:     "unknown input type runs as cmd",
+			spec:     mcallv1.McallTaskSpec{Type: "cmd", Input: `[{"name": "x", "type": "bash", "input": "ls"}]`},
+			disabled: "cmd",
+			want:     "cmd",
+		},
+		{
+			name:     "inputs of enabled types",
+			spec:     mcallv1.McallTaskSpec{Type: "cmd", Input: `[{"name": "ping", "type": "get", "input": "http://api"}]`},
+			disabled: "sql",
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &mcallv1.McallTask{Spec: tt.spec}
+			if got := disabledTaskType(task, parseTaskTypeList(tt.disabled)); got != tt.want {
+				t.Errorf("disabledTaskType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestKillSwitchRunningTask tests that the kill switch is checked again right before a running task executes
+func TestKillSwitchRunningTask(t *testing.T) {
+	t.Setenv("KILL_SWITCH_CONFIGMAP", "mcall-kill-switch")
+	t.Setenv("NAMESPACE", "mcall-system")
+
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "exec-task", Namespace: "default"}
+	task := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Spec:       mcallv1.McallTaskSpec{Type: "cmd", Input: `[{"name": "shell", "type": "kubectl-exec", "input": "ls"}]`},
+		Status:     mcallv1.McallTaskStatus{Phase: mcallv1.McallTaskPhaseRunning},
+	}
+	// Turned on after the task started
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "mcall-kill-switch", Namespace: "mcall-system"},
+		Data:       map[string]string{"disabledTaskTypes": "kubectl-exec"},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&mcallv1.McallTask{}).
+		WithObjects(task, configMap).
+		Build()
+	r := &McallTaskReconciler{Client: fakeClient, Scheme: scheme}
+
+	if _, err := r.handleRunning(ctx, task); err != nil {
+		t.Fatalf("handleRunning() error = %v", err)
+	}
+	var blocked mcallv1.McallTask
+	if err := fakeClient.Get(ctx, key, &blocked); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if blocked.Status.Phase != mcallv1.McallTaskPhaseBlocked {
+		t.Fatalf("phase = %s, want %s", blocked.Status.Phase, mcallv1.McallTaskPhaseBlocked)
+	}
+	if blocked.Status.Result == nil || !strings.Contains(blocked.Status.Result.ErrorMessage, `"kubectl-exec"`) {
+		t.Errorf("result = %+v, want kill switch message for kubectl-exec", blocked.Status.Result)
+	}
+}
+
+// TestOutputCompression tests compression of persisted outputs and decompression on read
+func TestOutputCompression(t *testing.T) {
+	large := strings.Repeat("HTTP/1.1 200 OK\n", 200)
+
+	tests := []struct {
+		name         string
+		output       string
+		algorithm    string
+		threshold    int
+		wantEncoding string
+		wantErr      bool
+	}{
+		{name: "disabled", output: large, algorithm: "none", threshold: 0, wantEncoding: OutputEncodingNone},
+		{name: "below threshold", output: "ok", algorithm: "gzip", threshold: 1024, wantEncoding: OutputEncodingNone},
+		{name: "gzip", output: large, algorithm: "gzip", threshold: 1024, wantEncoding: OutputEncodingGzip},
+		{name: "zstd", output: large, algorithm: "zstd", threshold: 1024, wantEncoding: OutputEncodingZstd},
+		{name: "unsupported", output: large, algorithm: "lz4", threshold: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, encoding, err := compressOutput(tt.output, tt.algorithm, tt.threshold)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("compressOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if encoding != tt.wantEncoding {
+				t.Errorf("compressOutput() encoding = %q, want %q", encoding, tt.wantEncoding)
+			}
+			if encoding != OutputEncodingNone && len(payload) >= len(tt.output) {
+				t.Errorf("compressOutput() payload size = %d, want smaller than %d", len(payload), len(tt.output))
+			}
+
+			restored, err := DecompressOutput(payload, encoding)
+			if err != nil {
+				t.Fatalf("DecompressOutput() error = %v", err)
+			}
+			if restored != tt.output {
+				t.Errorf("DecompressOutput() did not restore the original output")
+			}
+		})
+	}
+}
+
+// TestPausedAnnotation tests holding and skipping tasks with the paused annotation
+func TestPausedAnnotation(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+
+	tests := []struct {
+		name      string
+		paused    string
+		wantPhase mcallv1.McallTaskPhase
+	}{
+		{name: "paused stays pending", paused: "true", wantPhase: mcallv1.McallTaskPhasePending},
+		{name: "skip marks skipped", paused: "skip", wantPhase: mcallv1.McallTaskPhaseSkipped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			key := types.NamespacedName{Name: "paused-task", Namespace: "default"}
+			task := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        key.Name,
+					Namespace:   key.Namespace,
+					Annotations: map[string]string{mcallv1.PausedAnnotation: tt.paused},
+				},
+				Spec:   mcallv1.McallTaskSpec{Type: "cmd", Input: "echo ok"},
+				Status: mcallv1.McallTaskStatus{Phase: mcallv1.McallTaskPhasePending},
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&mcallv1.McallTask{}).
+				WithObjects(task).
+				Build()
+			r := &McallTaskReconciler{Client: fakeClient, Scheme: scheme}
+
+			if _, err := r.handlePending(ctx, task); err != nil {
+				t.Fatalf("handlePending() error = %v", err)
+			}
+
+			var updated mcallv1.McallTask
+			if err := fakeClient.Get(ctx, key, &updated); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if updated.Status.Phase != tt.wantPhase {
+				t.Errorf("phase = %s, want %s", updated.Status.Phase, tt.wantPhase)
+			}
+		})
+	}
+}
+
+// TestGetTaskTimeoutFor tests resolving the per-task timeout with the TASK_TIMEOUT fallback
+func TestGetTaskTimeoutFor(t *testing.T) {
+	t.Setenv("TASK_TIMEOUT", "7")
+
+	tests := []struct {
+		name    string
+		timeout int32
+		want    time.Duration
+	}{
+		{name: "task timeout", timeout: 30, want: 30 * time.Second},
+		{name: "unset falls back to env", timeout: 0, want: 7 * time.Second},
+		{name: "negative falls back to env", timeout: -1, want: 7 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &mcallv1.McallTask{Spec: mcallv1.McallTaskSpec{Timeout: tt.timeout}}
+			if got := getTaskTimeoutFor(task); got != tt.want {
+				t.Errorf("getTaskTimeoutFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPerInputTimeout tests that each JSON input can override the task timeout
+func TestPerInputTimeout(t *testing.T) {
+	task := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: "per-input-timeout"},
+		Spec: mcallv1.McallTaskSpec{
+			Type:          "cmd",
+			ExecutionMode: mcallv1.ExecutionModeParallel,
+			Input: `{"inputs": [
+				{"type": "cmd", "input": "sleep 3", "name": "slow", "timeout": 1},
+				{"type": "cmd", "input": "sleep 2 && echo done", "name": "default"}
+			]}`,
+		},
+	}
+
+	start := time.Now()
+	output, err := executeTask(task, 5*time.Second, logr.Discard())
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatalf("executeTask() expected error from timed out input, output = %s", output)
+	}
+	if !strings.Contains(output, "done") {
+		t.Errorf("input without timeout should use the task timeout, output = %s", output)
+	}
+	if elapsed >= 3*time.Second {
+		t.Errorf("slow input ran for %v, want it cut off by its 1s timeout", elapsed)
+	}
+}
+
+// TestHTTPRequestBody tests that post tasks send the spec and per-input body with its content type
+func TestHTTPRequestBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "%s %s %s", r.Method, r.Header.Get("Content-Type"), body)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		task *mcallv1.McallTask
+		want string
+	}{
+		{
+			name: "spec body with default content type",
+			task: &mcallv1.McallTask{Spec: mcallv1.McallTaskSpec{
+				Type: "post", Input: server.URL, Body: `{"name":"test"}`,
+			}},
+			want: `POST application/json {"name":"test"}`,
+		},
+		{
+			name: "spec body with content type",
+			task: &mcallv1.McallTask{Spec: mcallv1.McallTaskSpec{
+				Type: "post", Input: server.URL, Body: "a=1&b=2", ContentType: "application/x-www-form-urlencoded",
+			}},
+			want: "POST application/x-www-form-urlencoded a=1&b=2",
+		},
+		{
+			name: "per-input object body",
+			task: &mcallv1.McallTask{Spec: mcallv1.McallTaskSpec{
+				Type:  "cmd",
+				Input: fmt.Sprintf(`{"inputs": [{"type": "post", "input": %q, "name": "create", "body": {"id": 1}}]}`, server.URL),
+			}},
+			want: `POST application/json {"id":1}`,
+		},
+		{
+			name: "per-input string body with content type",
+			task: &mcallv1.McallTask{Spec: mcallv1.McallTaskSpec{
+				Type:  "cmd",
+				Input: fmt.Sprintf(`{"inputs": [{"type": "post", "input": %q, "name": "create", "body": "hello", "contentType": "text/plain"}]}`, server.URL),
+			}},
+			want: "POST text/plain hello",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output string
+			var err error
+			if tt.task.Spec.Type == "post" {
+				output, _, err = executeHTTPRequestWithOptions(tt.task.Spec.Input, "POST", 5*time.Second, httpRequestOptionsFor(tt.task))
+			} else {
+				output, err = executeTask(tt.task, 5*time.Second, logr.Discard())
+			}
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if !strings.Contains(output, tt.want) {
+				t.Errorf("output = %q, want it to contain %q", output, tt.want)
+			}
+		})
+	}
+}
+
+// TestHTTPRequestHeaders tests that spec and per-input headers are sent and override the default User-Agent
+func TestHTTPRequestHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "auth=%s tenant=%s agent=%s", r.Header.Get("Authorization"), r.Header.Get("X-Tenant"), r.Header.Get("User-Agent"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		task *mcallv1.McallTask
+		want []string
+	}{
+		{
+			name: "spec headers",
+			task: &mcallv1.McallTask{Spec: mcallv1.McallTaskSpec{
+				Type: "get", Input: server.URL,
+				Headers: map[string]string{"Authorization": "Bearer abc", "X-Tenant": "acme"},
+			}},
+			want: []string{"auth=Bearer abc", "tenant=acme", "agent=Mozilla/5.0"},
+		},
+		{
+			name: "spec User-Agent replaces default",
+			task: &mcallv1.McallTask{Spec: mcallv1.McallTaskSpec{
+				Type: "get", Input: server.URL,
+				Headers: map[string]string{"User-Agent": "mcall-probe"},
+			}},
+			want: []string{"agent=mcall-probe"},
+		},
+		{
+			name: "per-input headers",
+			task: &mcallv1.McallTask{Spec: mcallv1.McallTaskSpec{
+				Type:  "cmd",
+				Input: fmt.Sprintf(`{"inputs": [{"type": "get", "input": %q, "name": "probe", "headers": {"X-Tenant": 42}}]}`, server.URL),
+			}},
+			want: []string{"tenant=42"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output string
+			var err error
+			if tt.task.Spec.Type == "get" {
+				output, _, err = executeHTTPRequestWithOptions(tt.task.Spec.Input, "GET", 5*time.Second, httpRequestOptionsFor(tt.task))
+			} else {
+				output, err = executeTask(tt.task, 5*time.Second, logr.Discard())
+			}
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("output = %q, want it to contain %q", output, want)
+				}
+			}
+		})
+	}
+}
+
+// TestTaskWorkerSingleHTTPRequest tests that HTTP inputs with expect are sent once and match the status code of that request
+func TestTaskWorkerSingleHTTPRequest(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "not here")
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		expect    string
+		wantError string
+	}{
+		{name: "expect status code", expect: "404", wantError: "0"},
+		{name: "expect body", expect: "not here", wantError: "0"},
+		{name: "expect mismatch", expect: "200", wantError: "-1"},
+		{name: "no expect fails on non-2xx", expect: "", wantError: "-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+			worker := NewTaskWorker(server.URL, "get", "single-request", tt.expect)
+			worker.Execute(5 * time.Second)
+			result := <-worker.result
+
+			if result.Error != tt.wantError {
+				t.Errorf("Error = %s, want %s (content: %s)", result.Error, tt.wantError, result.Content)
+			}
+			if got := atomic.LoadInt32(&requests); got != 1 {
+				t.Errorf("server received %d requests, want 1", got)
+			}
+		})
+	}
+}
+
+// TestExecutionStatusFields tests that a run records its HTTP status code and execution wall time
+func TestExecutionStatusFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	tests := []struct {
+		name     string
+		httpAuth *mcallv1.HttpAuth
+	}{
+		{name: "plain request"},
+		{name: "authenticated request", httpAuth: &mcallv1.HttpAuth{Type: "bearer", SecretName: "api-token"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			key := types.NamespacedName{Name: "timed-task", Namespace: "default"}
+			task := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec:       mcallv1.McallTaskSpec{Type: "get", Input: server.URL, HttpAuth: tt.httpAuth},
+				Status: mcallv1.McallTaskStatus{
+					Phase:          mcallv1.McallTaskPhaseRunning,
+					StartTime:      &metav1.Time{Time: time.Now().Add(-time.Hour)},
+					HTTPStatusCode: 500,
+				},
+			}
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "api-token", Namespace: key.Namespace},
+				Data:       map[string][]byte{"token": []byte("abc")},
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&mcallv1.McallTask{}).
+				WithObjects(task, secret).
+				Build()
+			r := &McallTaskReconciler{Client: fakeClient, Scheme: scheme}
+
+			if _, err := r.handleRunning(ctx, task); err != nil {
+				t.Fatalf("handleRunning() error = %v", err)
+			}
+
+			var updated mcallv1.McallTask
+			if err := fakeClient.Get(ctx, key, &updated); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if updated.Status.HTTPStatusCode != http.StatusAccepted {
+				t.Errorf("HTTPStatusCode = %d, want %d", updated.Status.HTTPStatusCode, http.StatusAccepted)
+			}
+			// The wall time covers the request, not the hour since StartTime
+			if ms := updated.Status.ExecutionTimeMs; ms < 50 || ms > 5000 {
+				t.Errorf("ExecutionTimeMs = %d, want the request duration", ms)
+			}
+		})
+	}
+}
+
+// TestTaskWorkerDuration tests that each input result carries its measured execution time
+func TestTaskWorkerDuration(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		minMs int64
+		maxMs int64
+	}{
+		{name: "fast command", input: "echo ok", minMs: 0, maxMs: 1000},
+		{name: "slow command", input: "sleep 0.2", minMs: 200, maxMs: 2000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worker := NewTaskWorker(tt.input, "cmd", tt.name, "")
+			worker.Execute(5 * time.Second)
+			result := <-worker.result
+
+			if result.DurationMs < tt.minMs || result.DurationMs > tt.maxMs {
+				t.Errorf("DurationMs = %d, want between %d and %d", result.DurationMs, tt.minMs, tt.maxMs)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// conversionCRDs are the CRDs served at v1 and v1beta1, converted through the hub version v1
+var conversionCRDs = []string{"mcalltasks.mcall.tz.io", "mcallworkflows.mcall.tz.io"}
+
+// ConversionWebhookConfig is the Service the API server calls to convert between API versions
+type ConversionWebhookConfig struct {
+	ServiceName string
+	Namespace   string
+	Port        int32
+	CertDir     string
+}
+
+// GetConversionWebhookConfig reads the conversion webhook Service from the environment. An empty
+// ServiceName leaves the CRDs without conversion webhook.
+func GetConversionWebhookConfig() ConversionWebhookConfig {
+	return ConversionWebhookConfig{
+		ServiceName: os.Getenv("CONVERSION_WEBHOOK_SERVICE"),
+		Namespace:   getEnvOrDefault("NAMESPACE", "mcall-system"),
+		Port:        int32(getEnvIntOrDefault("WEBHOOK_SERVICE_PORT", 443)),
+		CertDir:     getEnvOrDefault("WEBHOOK_CERT_DIR", "/tmp/k8s-webhook-server/serving-certs"),
+	}
+}
+
+// caBundle returns the CA of the webhook serving certificate, or the certificate itself when
+// it is self-signed
+func (c ConversionWebhookConfig) caBundle() ([]byte, error) {
+	for _, name := range []string{"ca.crt", "tls.crt"} {
+		data, err := os.ReadFile(filepath.Join(c.CertDir, name))
+		if err == nil && len(data) > 0 {
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("no CA bundle in %s", c.CertDir)
+}
+
+// EnableCRDConversion points the conversion of the McallTask and McallWorkflow CRDs to the
+// /convert endpoint of the webhook server. The CRDs are generated without conversion webhook
+// since its Service and CA are only known at install time.
+func EnableCRDConversion(ctx context.Context, c client.Client, config ConversionWebhookConfig) error {
+	caBundle, err := config.caBundle()
+	if err != nil {
+		return err
+	}
+
+	path := "/convert"
+	port := config.Port
+	for _, name := range conversionCRDs {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, crd); err != nil {
+			return fmt.Errorf("failed to get CRD %s: %w", name, err)
+		}
+		patch := client.MergeFrom(crd.DeepCopy())
+		crd.Spec.Conversion = &apiextensionsv1.CustomResourceConversion{
+			Strategy: apiextensionsv1.WebhookConverter,
+			Webhook: &apiextensionsv1.WebhookConversion{
+				ClientConfig: &apiextensionsv1.WebhookClientConfig{
+					Service: &apiextensionsv1.ServiceReference{
+						Namespace: config.Namespace,
+						Name:      config.ServiceName,
+						Path:      &path,
+						Port:      &port,
+					},
+					CABundle: caBundle,
+				},
+				ConversionReviewVersions: []string{"v1"},
+			},
+		}
+		if err := c.Patch(ctx, crd, patch); err != nil {
+			return fmt.Errorf("failed to enable conversion of CRD %s: %w", name, err)
+		}
+	}
+	return nil
+}
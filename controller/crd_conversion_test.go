@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+	mcallv1beta1 "github.com/doohee323/tz-mcall-operator/api/v1beta1"
+)
+
+// TestCRDConversion tests pointing the CRDs to the conversion webhook and converting between versions
+func TestCRDConversion(t *testing.T) {
+	certDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(certDir, "tls.crt"), []byte("cert"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	scheme := runtime.NewScheme()
+	if err := apiextensionsv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, name := range conversionCRDs {
+		builder = builder.WithObjects(&apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	c := builder.Build()
+
+	config := ConversionWebhookConfig{ServiceName: "mcall-operator-webhook", Namespace: "mcall-system", Port: 443, CertDir: certDir}
+	if err := EnableCRDConversion(context.Background(), c, config); err != nil {
+		t.Fatalf("EnableCRDConversion() error = %v", err)
+	}
+	for _, name := range conversionCRDs {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := c.Get(context.Background(), client.ObjectKey{Name: name}, crd); err != nil {
+			t.Fatal(err)
+		}
+		conversion := crd.Spec.Conversion
+		if conversion == nil || conversion.Strategy != apiextensionsv1.WebhookConverter {
+			t.Fatalf("CRD %s conversion = %+v, want Webhook", name, conversion)
+		}
+		service := conversion.Webhook.ClientConfig.Service
+		if service.Name != "mcall-operator-webhook" || service.Namespace != "mcall-system" || *service.Path != "/convert" {
+			t.Errorf("CRD %s conversion service = %+v", name, service)
+		}
+		if string(conversion.Webhook.ClientConfig.CABundle) != "cert" {
+			t.Errorf("CRD %s CA bundle = %q, want %q", name, conversion.Webhook.ClientConfig.CABundle, "cert")
+		}
+	}
+
+	if err := EnableCRDConversion(context.Background(), c, ConversionWebhookConfig{CertDir: t.TempDir()}); err == nil {
+		t.Error("EnableCRDConversion() without CA bundle error = nil")
+	}
+
+	// v1 -> v1beta1 -> v1 keeps the whole object
+	task := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: "health", Namespace: "default", Labels: map[string]string{"app": "web"}},
+		Spec:       mcallv1.McallTaskSpec{Type: "get", Input: "http://web/health", Timeout: 5},
+		Status:     mcallv1.McallTaskStatus{Phase: mcallv1.McallTaskPhaseSucceeded},
+	}
+	spoke := &mcallv1beta1.McallTask{}
+	if err := spoke.ConvertFrom(task); err != nil {
+		t.Fatal(err)
+	}
+	hub := &mcallv1.McallTask{}
+	if err := spoke.ConvertTo(hub); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(hub, task) {
+		t.Errorf("McallTask round trip = %+v, want %+v", hub, task)
+	}
+
+	workflow := &mcallv1.McallWorkflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "checks", Namespace: "default"},
+		Spec: mcallv1.McallWorkflowSpec{Tasks: []mcallv1.WorkflowTaskRef{
+			{Name: "a"},
+			{Name: "b", Dependencies: []string{"a"}},
+		}},
+	}
+	workflowSpoke := &mcallv1beta1.McallWorkflow{}
+	if err := workflowSpoke.ConvertFrom(workflow); err != nil {
+		t.Fatal(err)
+	}
+	workflowHub := &mcallv1.McallWorkflow{}
+	if err := workflowSpoke.ConvertTo(workflowHub); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(workflowHub, workflow) {
+		t.Errorf("McallWorkflow round trip = %+v, want %+v", workflowHub, workflow)
+	}
+}
@@ -3,10 +3,9 @@ package controller
 import (
 	"context"
 	"fmt"
-	"strconv"
-	"strings"
 	"time"
 
+	"github.com/robfig/cron/v3"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -14,6 +13,13 @@ import (
 	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
 )
 
+// maxMissedRuns bounds the search for the latest missed schedule time
+const maxMissedRuns = 10000
+
+// cronParser accepts standard 5-field cron expressions and descriptors
+// such as @hourly, @daily and @every 5m
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
 // CronScheduler handles cron schedule parsing and execution
 type CronScheduler struct {
 	client.Client
@@ -26,31 +32,23 @@ func NewCronScheduler(client client.Client) *CronScheduler {
 	}
 }
 
-// CronExpression represents a parsed cron expression
-type CronExpression struct {
-	Minute  string
-	Hour    string
-	Day     string
-	Month   string
-	Weekday string
-	LastRun time.Time
-	NextRun time.Time
-}
-
 // ParseCronExpression parses a cron expression
-func (cs *CronScheduler) ParseCronExpression(expr string) (*CronExpression, error) {
-	parts := strings.Fields(expr)
-	if len(parts) != 5 {
-		return nil, fmt.Errorf("invalid cron expression: expected 5 fields, got %d", len(parts))
+// Supports ranges with steps ("1-30/5"), names ("Mon", "Jan") and macros ("@hourly", "@every 1h")
+func (cs *CronScheduler) ParseCronExpression(expr string) (cron.Schedule, error) {
+	schedule, err := cronParser.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
 	}
+	return schedule, nil
+}
 
-	return &CronExpression{
-		Minute:  parts[0],
-		Hour:    parts[1],
-		Day:     parts[2],
-		Month:   parts[3],
-		Weekday: parts[4],
-	}, nil
+// NextRunTime returns the first time after the given time that matches the schedule
+func (cs *CronScheduler) NextRunTime(schedule string, after time.Time) (time.Time, error) {
+	cronSchedule, err := cs.ParseCronExpression(schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cronSchedule.Next(after), nil
 }
 
 // ShouldRun checks if a workflow should run based on its cron schedule
@@ -62,29 +60,42 @@ func (cs *CronScheduler) ShouldRun(ctx context.Context, workflow *mcallv1.McallW
 		return true, nil
 	}
 
-	// Parse cron expression
-	cron, err := cs.ParseCronExpression(workflow.Spec.Schedule)
-	if err != nil {
+	// Validate cron expression
+	if _, err := cs.ParseCronExpression(workflow.Spec.Schedule); err != nil {
 		log.Error(err, "Failed to parse cron expression", "workflow", workflow.Name, "schedule", workflow.Spec.Schedule)
 		return false, err
 	}
 
+	lastRun := scheduleBase(workflow)
+
 	// Check if this is the first run
-	if workflow.Status.LastRunTime == nil {
+	if lastRun == nil {
 		log.Info("First run of scheduled workflow", "workflow", workflow.Name)
 		return true, nil
 	}
 
 	// Calculate next run time
 	now := time.Now()
-	nextRun, err := cs.calculateNextRun(cron, workflow.Status.LastRunTime.Time)
+	nextRun, err := cs.NextRunTime(workflow.Spec.Schedule, lastRun.Time)
 	if err != nil {
 		log.Error(err, "Failed to calculate next run time", "workflow", workflow.Name)
 		return false, err
 	}
 
 	// Check if it's time to run
-	shouldRun := now.After(nextRun) || now.Equal(nextRun)
+	shouldRun := !now.Before(nextRun)
+
+	// Skip missed runs that are older than the starting deadline
+	if shouldRun && workflow.Spec.StartingDeadlineSeconds != nil {
+		cronSchedule, _ := cs.ParseCronExpression(workflow.Spec.Schedule)
+		missedRun := latestScheduledRun(cronSchedule, nextRun, now)
+		deadline := time.Duration(*workflow.Spec.StartingDeadlineSeconds) * time.Second
+		if now.Sub(missedRun) > deadline {
+			log.Info("Missed scheduled run is past starting deadline, waiting for next window",
+				"workflow", workflow.Name, "missedRun", missedRun, "startingDeadlineSeconds", *workflow.Spec.StartingDeadlineSeconds)
+			return false, nil
+		}
+	}
 
 	if shouldRun {
 		log.Info("Workflow scheduled to run", "workflow", workflow.Name, "nextRun", nextRun, "now", now)
@@ -93,109 +104,42 @@ func (cs *CronScheduler) ShouldRun(ctx context.Context, workflow *mcallv1.McallW
 	return shouldRun, nil
 }
 
-// calculateNextRun calculates the next run time based on cron expression
-func (cs *CronScheduler) calculateNextRun(cron *CronExpression, lastRun time.Time) (time.Time, error) {
-	now := time.Now()
-
-	// Start from the last run time or now, whichever is later
-	start := lastRun
-	if now.After(lastRun) {
-		start = now
-	}
-
-	// Add 1 minute to start checking from the next minute
-	start = start.Add(1 * time.Minute).Truncate(time.Minute)
-
-	// Check the next 24 hours for a match
-	for i := 0; i < 24*60; i++ {
-		checkTime := start.Add(time.Duration(i) * time.Minute)
-
-		if cs.matchesCron(cron, checkTime) {
-			return checkTime, nil
-		}
-	}
-
-	// If no match found in 24 hours, return next day at the same time
-	return start.Add(24 * time.Hour), nil
-}
-
-// matchesCron checks if a time matches the cron expression
-func (cs *CronScheduler) matchesCron(cron *CronExpression, t time.Time) bool {
-	// Check minute
-	if !cs.matchesField(cron.Minute, t.Minute()) {
-		return false
-	}
-
-	// Check hour
-	if !cs.matchesField(cron.Hour, t.Hour()) {
-		return false
-	}
-
-	// Check day of month
-	if !cs.matchesField(cron.Day, t.Day()) {
-		return false
+// scheduleBase returns the time the next run of a workflow is scheduled after: its last run,
+// or its resume time as runs missed while suspended are not backfilled. Nil before the first run.
+func scheduleBase(workflow *mcallv1.McallWorkflow) *metav1.Time {
+	lastRun := workflow.Status.LastRunTime
+	if resumed := workflow.Status.ResumedTime; resumed != nil && (lastRun == nil || resumed.After(lastRun.Time)) {
+		lastRun = resumed
 	}
-
-	// Check month
-	if !cs.matchesField(cron.Month, int(t.Month())) {
-		return false
-	}
-
-	// Check weekday (0 = Sunday, 1 = Monday, ..., 6 = Saturday)
-	if !cs.matchesField(cron.Weekday, int(t.Weekday())) {
-		return false
-	}
-
-	return true
+	return lastRun
 }
 
-// matchesField checks if a value matches a cron field
-func (cs *CronScheduler) matchesField(field string, value int) bool {
-	// Handle wildcard
-	if field == "*" {
-		return true
-	}
-
-	// Handle comma-separated values
-	if strings.Contains(field, ",") {
-		parts := strings.Split(field, ",")
-		for _, part := range parts {
-			if cs.matchesField(strings.TrimSpace(part), value) {
-				return true
-			}
-		}
-		return false
-	}
-
-	// Handle range (e.g., "1-5")
-	if strings.Contains(field, "-") {
-		parts := strings.Split(field, "-")
-		if len(parts) != 2 {
-			return false
+// missedScheduledRuns returns the number of schedule times after the last run that passed
+// without a run, not counting the latest one, which is due
+func missedScheduledRuns(schedule cron.Schedule, lastRun, now time.Time) int {
+	missed := 0
+	next := schedule.Next(lastRun)
+	for missed < maxMissedRuns {
+		next = schedule.Next(next)
+		if next.After(now) {
+			break
 		}
-		start, err1 := strconv.Atoi(parts[0])
-		end, err2 := strconv.Atoi(parts[1])
-		if err1 != nil || err2 != nil {
-			return false
-		}
-		return value >= start && value <= end
+		missed++
 	}
+	return missed
+}
 
-	// Handle step (e.g., "*/5")
-	if strings.HasPrefix(field, "*/") {
-		step, err := strconv.Atoi(field[2:])
-		if err != nil {
-			return false
+// latestScheduledRun returns the latest schedule time not after now, starting from a known schedule time
+func latestScheduledRun(schedule cron.Schedule, first, now time.Time) time.Time {
+	latest := first
+	for i := 0; i < maxMissedRuns; i++ {
+		next := schedule.Next(latest)
+		if next.After(now) {
+			break
 		}
-		return value%step == 0
-	}
-
-	// Handle exact value
-	exact, err := strconv.Atoi(field)
-	if err != nil {
-		return false
+		latest = next
 	}
-	return value == exact
+	return latest
 }
 
 // UpdateLastRunTime updates the last run time for a workflow
@@ -0,0 +1,290 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// elasticsearchBulkTimeout bounds each delivery attempt of a batch of log entries
+const elasticsearchBulkTimeout = 30 * time.Second
+
+var (
+	elasticsearchLogDroppedEntries = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "mcall_logging_elasticsearch_dropped_entries_total",
+		Help: "Number of log entries dropped because the Elasticsearch buffer was full, delivery failed or the document was rejected",
+	})
+
+	elasticsearchBulkIndexersMu sync.Mutex
+	elasticsearchBulkIndexers   = make(map[string]*elasticsearchBulkIndexer)
+)
+
+func init() {
+	metrics.Registry.MustRegister(elasticsearchLogDroppedEntries)
+}
+
+// elasticsearchLogMappings are the mappings of the fields of the log entries
+var elasticsearchLogMappings = map[string]interface{}{
+	"properties": map[string]interface{}{
+		"service_name":     map[string]string{"type": "keyword"},
+		"service_type":     map[string]string{"type": "keyword"},
+		"namespace":        map[string]string{"type": "keyword"},
+		"workflow":         map[string]string{"type": "keyword"},
+		"run_id":           map[string]string{"type": "keyword"},
+		"labels":           map[string]string{"type": "flattened"},
+		"status":           map[string]string{"type": "keyword"},
+		"error_code":       map[string]string{"type": "keyword"},
+		"error_message":    map[string]string{"type": "text"},
+		"response_time_ms": map[string]string{"type": "long"},
+		"timestamp":        map[string]string{"type": "date"},
+		"output":           map[string]string{"type": "text"},
+		"output_encoding":  map[string]string{"type": "keyword"},
+		"output_truncated": map[string]string{"type": "boolean"},
+	},
+}
+
+// setElasticsearchAuth authenticates a request with the API key, or the username and password
+func setElasticsearchAuth(req *http.Request, config LoggingConfig) {
+	if config.Elasticsearch.APIKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+config.Elasticsearch.APIKey)
+	} else if config.Elasticsearch.Username != "" {
+		req.SetBasicAuth(config.Elasticsearch.Username, config.Elasticsearch.Password)
+	}
+}
+
+// elasticsearchIndexName returns the index of an entry: the configured index, suffixed with the
+// date of the entry when an index date format is set
+func elasticsearchIndexName(config LoggingConfig, timestamp time.Time) string {
+	if config.Elasticsearch.IndexDateFormat == "" {
+		return config.Elasticsearch.Index
+	}
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	return config.Elasticsearch.Index + "-" + timestamp.UTC().Format(config.Elasticsearch.IndexDateFormat)
+}
+
+// putElasticsearchIndexTemplate puts the index template of the index and its dated indices,
+// holding the mappings of the entries and the ILM policy of new indices
+func putElasticsearchIndexTemplate(client *http.Client, config LoggingConfig) error {
+	index := config.Elasticsearch.Index
+	template := map[string]interface{}{"mappings": elasticsearchLogMappings}
+	if config.Elasticsearch.ILMPolicy != "" {
+		template["settings"] = map[string]interface{}{"index.lifecycle.name": config.Elasticsearch.ILMPolicy}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"index_patterns": []string{index, index + "-*"},
+		"template":       template,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal index template: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/_index_template/%s", config.Elasticsearch.URL, index), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setElasticsearchAuth(req, config)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to put Elasticsearch index template: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to put Elasticsearch index template %s: status %d: %s", index, resp.StatusCode, message)
+	}
+	return nil
+}
+
+// elasticsearchDocument is a log entry waiting to be indexed
+type elasticsearchDocument struct {
+	index  string
+	source []byte
+}
+
+// elasticsearchBulkIndexer batches log entries and indexes them with the bulk API in the
+// background. It is shared by the Elasticsearch backends of all entries.
+type elasticsearchBulkIndexer struct {
+	config    LoggingConfig
+	client    *http.Client
+	documents chan elasticsearchDocument
+	stop      chan struct{} // Closed when the logging configuration is reloaded
+}
+
+// getElasticsearchBulkIndexer returns the bulk indexer of the configured URL and index, starting it on first use
+func getElasticsearchBulkIndexer(config LoggingConfig) *elasticsearchBulkIndexer {
+	key := config.Elasticsearch.URL + "/" + config.Elasticsearch.Index
+
+	elasticsearchBulkIndexersMu.Lock()
+	defer elasticsearchBulkIndexersMu.Unlock()
+	if indexer, exists := elasticsearchBulkIndexers[key]; exists {
+		return indexer
+	}
+
+	indexer := &elasticsearchBulkIndexer{
+		config:    config,
+		client:    &http.Client{Timeout: elasticsearchBulkTimeout},
+		documents: make(chan elasticsearchDocument, max(config.Elasticsearch.BufferSize, 1)),
+		stop:      make(chan struct{}),
+	}
+	go indexer.run()
+	elasticsearchBulkIndexers[key] = indexer
+	return indexer
+}
+
+// stopElasticsearchBulkIndexers stops the bulk indexers after delivering their buffered entries,
+// so the Elasticsearch backends start new ones with the reloaded configuration
+func stopElasticsearchBulkIndexers() {
+	elasticsearchBulkIndexersMu.Lock()
+	defer elasticsearchBulkIndexersMu.Unlock()
+	for key, indexer := range elasticsearchBulkIndexers {
+		close(indexer.stop)
+		delete(elasticsearchBulkIndexers, key)
+	}
+}
+
+// enqueue buffers a document for the next batch, dropping it when the buffer is full
+func (b *elasticsearchBulkIndexer) enqueue(document elasticsearchDocument) error {
+	select {
+	case b.documents <- document:
+		return nil
+	default:
+		elasticsearchLogDroppedEntries.Inc()
+		return fmt.Errorf("elasticsearch log buffer is full (%d entries), dropping the entry", cap(b.documents))
+	}
+}
+
+// run sends a batch when it reaches the batch size or the batch timeout elapses
+func (b *elasticsearchBulkIndexer) run() {
+	ticker := time.NewTicker(max(b.config.Elasticsearch.BatchTimeout, 10*time.Millisecond))
+	defer ticker.Stop()
+
+	var batch []elasticsearchDocument
+	for {
+		select {
+		case document := <-b.documents:
+			batch = append(batch, document)
+			if len(batch) < b.config.Elasticsearch.BatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		case <-b.stop:
+			for len(b.documents) > 0 {
+				batch = append(batch, <-b.documents)
+			}
+			if len(batch) > 0 {
+				b.deliver(batch)
+			}
+			return
+		}
+		b.deliver(batch)
+		batch = nil
+	}
+}
+
+// deliver indexes a batch, retrying the failed and throttled documents with exponential backoff.
+// Batches that still fail are dropped and reported.
+func (b *elasticsearchBulkIndexer) deliver(documents []elasticsearchDocument) {
+	var err error
+	for attempt := 0; attempt <= b.config.Elasticsearch.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(1<<(attempt-1)) * 100 * time.Millisecond)
+		}
+		if documents, err = b.bulk(documents); err == nil {
+			return
+		}
+	}
+	elasticsearchLogDroppedEntries.Add(float64(len(documents)))
+	ctrl.Log.WithName("elasticsearch-logging").Error(err, "Failed to deliver log entries to Elasticsearch",
+		"index", b.config.Elasticsearch.Index, "entries", len(documents), "attempts", b.config.Elasticsearch.MaxRetries+1)
+}
+
+// bulk sends a batch to the bulk API and returns the documents to retry: all of them when the
+// request failed, the throttled and failed ones otherwise. Documents Elasticsearch rejects,
+// e.g. for a mapping conflict, are dropped and reported.
+func (b *elasticsearchBulkIndexer) bulk(documents []elasticsearchDocument) ([]elasticsearchDocument, error) {
+	var body bytes.Buffer
+	for _, document := range documents {
+		action, _ := json.Marshal(map[string]interface{}{"index": map[string]string{"_index": document.index}})
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(document.source)
+		body.WriteByte('\n')
+	}
+
+	req, err := http.NewRequest(http.MethodPost, b.config.Elasticsearch.URL+"/_bulk", &body)
+	if err != nil {
+		return documents, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	setElasticsearchAuth(req, b.config)
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return documents, fmt.Errorf("failed to send to Elasticsearch: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return documents, fmt.Errorf("Elasticsearch bulk request failed with status: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int `json:"status"`
+			Error  struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return documents, fmt.Errorf("failed to decode Elasticsearch bulk response: %w", err)
+	}
+	if !result.Errors {
+		return nil, nil
+	}
+
+	var retry []elasticsearchDocument
+	var rejected int
+	var reason string
+	for i, item := range result.Items {
+		if i >= len(documents) {
+			break
+		}
+		for _, action := range item {
+			switch {
+			case action.Status == http.StatusTooManyRequests || action.Status >= 500:
+				retry = append(retry, documents[i])
+			case action.Status >= 400:
+				rejected++
+				if reason == "" {
+					reason = action.Error.Type + ": " + strings.TrimSpace(action.Error.Reason)
+				}
+			}
+		}
+	}
+	if rejected > 0 {
+		elasticsearchLogDroppedEntries.Add(float64(rejected))
+		ctrl.Log.WithName("elasticsearch-logging").Error(fmt.Errorf("%s", reason), "Elasticsearch rejected log entries",
+			"index", b.config.Elasticsearch.Index, "entries", rejected)
+	}
+	if len(retry) > 0 {
+		return retry, fmt.Errorf("Elasticsearch throttled or failed %d of %d documents", len(retry), len(documents))
+	}
+	return nil, nil
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestElasticsearchBulkIndexer tests the index template, API key auth, dated indices and bulk
+// delivery of the Elasticsearch backend, retrying throttled documents and dropping rejected ones
+func TestElasticsearchBulkIndexer(t *testing.T) {
+	var mu sync.Mutex
+	var template map[string]interface{}
+	var authorizations []string
+	var indices []string
+	bulkRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/_index_template/mcall-logs":
+			json.NewDecoder(r.Body).Decode(&template)
+		case r.URL.Path == "/_bulk":
+			bulkRequests++
+			var items []string
+			scanner := bufio.NewScanner(r.Body)
+			for scanner.Scan() {
+				var action struct {
+					Index struct {
+						Index string `json:"_index"`
+					} `json:"index"`
+				}
+				json.Unmarshal(scanner.Bytes(), &action)
+				indices = append(indices, action.Index.Index)
+				scanner.Scan() // Document
+				// The first request throttles the first document and rejects the second
+				switch {
+				case bulkRequests == 1 && len(items) == 0:
+					items = append(items, `{"index":{"status":429,"error":{"type":"es_rejected_execution_exception"}}}`)
+				case bulkRequests == 1:
+					items = append(items, `{"index":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"bad"}}}`)
+				default:
+					items = append(items, `{"index":{"status":201}}`)
+				}
+			}
+			w.Write([]byte(`{"errors":true,"items":[` + strings.Join(items, ",") + `]}`))
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("LOGGING_ELASTICSEARCH_ENABLED", "true")
+	t.Setenv("LOGGING_ELASTICSEARCH_URL", server.URL)
+	t.Setenv("LOGGING_ELASTICSEARCH_API_KEY", "a2V5OnNlY3JldA==")
+	t.Setenv("LOGGING_ELASTICSEARCH_INDEX_DATE_FORMAT", "2006.01.02")
+	t.Setenv("LOGGING_ELASTICSEARCH_ILM_POLICY", "mcall-logs-30d")
+	t.Setenv("LOGGING_ELASTICSEARCH_BATCH_SIZE", "2")
+	t.Setenv("LOGGING_ELASTICSEARCH_BATCH_TIMEOUT_MS", "10")
+	config := GetLoggingConfig()
+	config.Backend = "elasticsearch"
+	defer stopElasticsearchBulkIndexers()
+
+	backend, err := CreateLoggingBackend(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	dropped := testutil.ToFloat64(elasticsearchLogDroppedEntries)
+	timestamp := time.Date(2026, 3, 14, 23, 30, 0, 0, time.FixedZone("KST", 9*3600))
+	for _, name := range []string{"api", "db"} {
+		if err := backend.Log(LogEntry{ServiceName: name, Status: "UP", Timestamp: timestamp}); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		done := bulkRequests == 2
+		mu.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if bulkRequests != 2 || len(indices) != 3 {
+		t.Fatalf("got %d bulk requests of %d documents, want 2 of 3", bulkRequests, len(indices))
+	}
+	for _, index := range indices {
+		if index != "mcall-logs-2026.03.14" {
+			t.Errorf("indexed into %q, want mcall-logs-2026.03.14", index)
+		}
+	}
+	for _, authorization := range authorizations {
+		if authorization != "ApiKey a2V5OnNlY3JldA==" {
+			t.Errorf("Authorization = %q, want the API key", authorization)
+		}
+	}
+	settings, _ := template["template"].(map[string]interface{})["settings"].(map[string]interface{})
+	if settings["index.lifecycle.name"] != "mcall-logs-30d" {
+		t.Errorf("index template = %v, want the ILM policy", template)
+	}
+	if got := testutil.ToFloat64(elasticsearchLogDroppedEntries) - dropped; got != 1 {
+		t.Errorf("dropped %v entries, want the rejected one", got)
+	}
+}
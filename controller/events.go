@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// Reasons of the Events emitted on tasks and workflows
+const (
+	EventReasonStarted   = "Started"
+	EventReasonSucceeded = "Succeeded"
+	EventReasonFailed    = "Failed"
+	EventReasonRetrying  = "Retrying"
+	EventReasonSkipped   = "Skipped"
+	EventReasonTimedOut  = "TimedOut"
+	EventReasonCompleted = "Completed"
+	EventReasonRestarted = "Restarted"
+)
+
+// eventMessageMaxLength bounds the error messages in Events, the API server rejects messages over 1KiB
+const eventMessageMaxLength = 1024
+
+// recordEvent emits an Event on a task or workflow, if the reconciler has a recorder
+func recordEvent(recorder record.EventRecorder, object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	if recorder == nil {
+		return
+	}
+	recorder.Eventf(object, eventType, reason, messageFmt, args...)
+}
+
+// recordFailureEvent emits the Warning Event of a failed execution, TimedOut when it timed out
+func recordFailureEvent(recorder record.EventRecorder, object runtime.Object, timedOut bool, message string) {
+	reason := EventReasonFailed
+	if timedOut {
+		reason = EventReasonTimedOut
+	}
+	recordEvent(recorder, object, corev1.EventTypeWarning, reason, "Execution failed: %s", truncateString(message, eventMessageMaxLength-64))
+}
+
+// isTimeoutError reports whether an execution error is a timeout
+func isTimeoutError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return true
+	}
+	return strings.Contains(err.Error(), "timed out")
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestTaskEvents tests the Events of a task that starts, times out and is retried
+func TestTaskEvents(t *testing.T) {
+	t.Setenv("TASK_RUN_HISTORY_LIMIT", "0")
+
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "slow-task", Namespace: "default"}
+	task := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Spec: mcallv1.McallTaskSpec{
+			Type:         "cmd",
+			Input:        "sleep 5",
+			Timeout:      1,
+			RetryCount:   1,
+			RetryBackoff: &mcallv1.RetryBackoff{InitialDelaySeconds: 30},
+		},
+		Status: mcallv1.McallTaskStatus{Phase: mcallv1.McallTaskPhasePending},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&mcallv1.McallTask{}).
+		WithObjects(task).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &McallTaskReconciler{Client: fakeClient, Scheme: scheme, Recorder: recorder}
+
+	get := func() *mcallv1.McallTask {
+		var latest mcallv1.McallTask
+		if err := fakeClient.Get(ctx, key, &latest); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		return &latest
+	}
+	if _, err := r.handlePending(ctx, get()); err != nil {
+		t.Fatalf("handlePending() error = %v", err)
+	}
+	if _, err := r.handleRunning(ctx, get()); err != nil {
+		t.Fatalf("handleRunning() error = %v", err)
+	}
+	latest := get()
+	latest.Status.NextRetryTime = &metav1.Time{Time: time.Now().Add(-time.Second)}
+	if err := fakeClient.Status().Update(ctx, latest); err != nil {
+		t.Fatalf("Status().Update() error = %v", err)
+	}
+	if _, err := r.handlePending(ctx, get()); err != nil {
+		t.Fatalf("handlePending() error = %v", err)
+	}
+	if _, err := r.handleRunning(ctx, get()); err != nil {
+		t.Fatalf("handleRunning() error = %v", err)
+	}
+
+	want := []string{
+		"Normal Started Started cmd task",
+		"Warning Retrying Execution failed, retry 1/1 in ",
+		"Normal Started Started cmd task, retry 1/1",
+		"Warning TimedOut Execution failed: ",
+	}
+	for _, event := range want {
+		select {
+		case got := <-recorder.Events:
+			if !strings.HasPrefix(got, event) {
+				t.Errorf("event = %q, want %q", got, event)
+			}
+		default:
+			t.Fatalf("missing event %q", event)
+		}
+	}
+}
+
+// TestIsTimeoutError tests detecting the timeouts of executions
+func TestIsTimeoutError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "deadline exceeded", err: fmt.Errorf("request failed: %w", context.DeadlineExceeded), want: true},
+		{name: "network timeout", err: &net.OpError{Op: "dial", Err: timeoutError{}}, want: true},
+		{name: "command timeout", err: fmt.Errorf("command execution timed out"), want: true},
+		{name: "failure", err: fmt.Errorf("exit status 1"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTimeoutError(tt.err); got != tt.want {
+				t.Errorf("isTimeoutError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// timeoutError is a network error reporting a timeout
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
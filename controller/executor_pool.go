@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	executorQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "mcall_executor_queue_depth",
+		Help: "Number of task executions waiting for a free executor",
+	})
+	executorActive = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "mcall_executor_active",
+		Help: "Number of task executions currently running",
+	})
+	executorPoolSize = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "mcall_executor_pool_size",
+		Help: "Maximum number of task executions running at once",
+	})
+
+	sharedExecutorPool     *executorPool
+	sharedExecutorPoolOnce sync.Once
+)
+
+func init() {
+	metrics.Registry.MustRegister(executorQueueDepth, executorActive, executorPoolSize)
+}
+
+// executorPool runs task executions on a fixed number of workers, so a burst of
+// tasks queues up instead of exhausting CPU and file descriptors
+type executorPool struct {
+	jobs chan func()
+}
+
+// getExecutorPoolSize returns the number of executor workers from environment variable
+func getExecutorPoolSize() int {
+	size := getEnvIntOrDefault("EXECUTOR_POOL_SIZE", 10)
+	if size <= 0 {
+		return 10
+	}
+	return size
+}
+
+// getExecutorPool returns the controller-wide executor pool, starting it on first use
+func getExecutorPool() *executorPool {
+	sharedExecutorPoolOnce.Do(func() {
+		sharedExecutorPool = newExecutorPool(getExecutorPoolSize())
+	})
+	return sharedExecutorPool
+}
+
+// newExecutorPool starts an executor pool with the given number of workers
+func newExecutorPool(size int) *executorPool {
+	pool := &executorPool{jobs: make(chan func())}
+	for i := 0; i < size; i++ {
+		go pool.worker()
+	}
+	executorPoolSize.Set(float64(size))
+	return pool
+}
+
+// worker runs submitted jobs one at a time
+func (p *executorPool) worker() {
+	for job := range p.jobs {
+		executorActive.Inc()
+		job()
+		executorActive.Dec()
+	}
+}
+
+// Run submits fn to the pool and waits for it to complete.
+// It returns ctx.Err() without running fn if ctx ends while waiting for a free worker.
+func (p *executorPool) Run(ctx context.Context, fn func()) error {
+	done := make(chan struct{})
+	job := func() {
+		defer close(done)
+		fn()
+	}
+
+	executorQueueDepth.Inc()
+	select {
+	case p.jobs <- job:
+		executorQueueDepth.Dec()
+	case <-ctx.Done():
+		executorQueueDepth.Dec()
+		return ctx.Err()
+	}
+
+	<-done
+	return nil
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestExecutorPool tests that the pool bounds concurrent executions
+func TestExecutorPool(t *testing.T) {
+	pool := newExecutorPool(2)
+
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := pool.Run(context.Background(), func() {
+				current := atomic.AddInt32(&running, 1)
+				for {
+					max := atomic.LoadInt32(&maxRunning)
+					if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+			})
+			if err != nil {
+				t.Errorf("Run() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxRunning != 2 {
+		t.Errorf("max concurrent executions = %d, want 2", maxRunning)
+	}
+}
+
+// TestExecutorPoolContextCancelled tests that queued work is dropped when its context ends
+func TestExecutorPoolContextCancelled(t *testing.T) {
+	pool := newExecutorPool(1)
+
+	release := make(chan struct{})
+	go func() {
+		_ = pool.Run(context.Background(), func() { <-release })
+	}()
+	defer close(release)
+
+	// Wait until the only worker is busy
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	ran := false
+	if err := pool.Run(ctx, func() { ran = true }); err == nil {
+		t.Errorf("Run() error = nil, want context deadline")
+	}
+	if ran {
+		t.Errorf("queued job ran after its context ended")
+	}
+}
@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ftpClient is a plain FTP session using passive mode
+type ftpClient struct {
+	text     *textproto.Conn
+	host     string
+	deadline time.Time // End of the task, applied to the control and data connections
+}
+
+// dialFTP connects to an FTP server and logs in
+func dialFTP(addr string, credentials *fileTransferCredentials, timeout time.Duration) (*ftpClient, error) {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "21")
+	}
+	host, _, _ := net.SplitHostPort(addr)
+
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	// The deadline bounds the whole task, including the transfers
+	deadline := time.Now().Add(timeout)
+	conn.SetDeadline(deadline)
+
+	client := &ftpClient{text: textproto.NewConn(conn), host: host, deadline: deadline}
+	if _, _, err := client.text.ReadResponse(220); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("ftp server %s not ready: %w", addr, err)
+	}
+
+	code, _, err := client.command(0, "USER %s", credentials.username)
+	if err == nil && code == 331 {
+		code, _, err = client.command(0, "PASS %s", credentials.password)
+	}
+	if err == nil && code != 230 {
+		err = fmt.Errorf("ftp login as %s failed with code %d", credentials.username, code)
+	}
+	if err == nil {
+		_, _, err = client.command(200, "TYPE I")
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// Close logs out
+func (c *ftpClient) Close() error {
+	c.command(0, "QUIT")
+	return c.text.Close()
+}
+
+// List returns the names in a directory; NLST listings have no sizes
+func (c *ftpClient) List(dir string) ([]remoteFile, error) {
+	data, err := c.transfer(fmt.Sprintf("NLST %s", dir), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []remoteFile{}
+	for _, line := range strings.Split(string(data), "\n") {
+		name := path.Base(strings.TrimSpace(line))
+		if name == "" || name == "." || name == ".." || name == "/" {
+			continue
+		}
+		files = append(files, remoteFile{Name: name, Size: -1})
+	}
+	return files, nil
+}
+
+// Size returns the size of a file
+func (c *ftpClient) Size(file string) (int64, error) {
+	_, message, err := c.command(213, "SIZE %s", file)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(message), 10, 64)
+}
+
+// Download reads a file to the end and returns its size
+func (c *ftpClient) Download(file string) (int64, error) {
+	data, err := c.transfer(fmt.Sprintf("RETR %s", file), nil)
+	return int64(len(data)), err
+}
+
+// Upload stores data in a file
+func (c *ftpClient) Upload(file string, data []byte) error {
+	_, err := c.transfer(fmt.Sprintf("STOR %s", file), data)
+	return err
+}
+
+// Remove deletes a file
+func (c *ftpClient) Remove(file string) error {
+	_, _, err := c.command(250, "DELE %s", file)
+	return err
+}
+
+// command sends a command and checks the reply code (0 accepts any code)
+func (c *ftpClient) command(expectCode int, format string, args ...interface{}) (int, string, error) {
+	line := fmt.Sprintf(format, args...)
+	if _, err := c.text.Cmd("%s", line); err != nil {
+		return 0, "", fmt.Errorf("ftp command failed: %w", err)
+	}
+	code, message, err := c.text.ReadResponse(expectCode)
+	if err != nil {
+		// Only the verb is reported, PASS arguments must not reach the task status
+		return code, message, fmt.Errorf("ftp %s: %w", strings.Fields(line)[0], err)
+	}
+	return code, message, nil
+}
+
+// transfer runs a command over a passive data connection, uploading data or returning what was downloaded
+func (c *ftpClient) transfer(command string, upload []byte) ([]byte, error) {
+	dataConn, err := c.passive()
+	if err != nil {
+		return nil, err
+	}
+	defer dataConn.Close()
+
+	// 125/150: the server opens the transfer
+	if _, _, err := c.command(1, command); err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if upload != nil {
+		_, err = dataConn.Write(upload)
+	} else {
+		data, err = io.ReadAll(dataConn)
+	}
+	// Closing the data connection ends an upload
+	dataConn.Close()
+	if err != nil {
+		return data, fmt.Errorf("ftp data transfer failed: %w", err)
+	}
+
+	// 226/250: the transfer completed
+	if _, _, err := c.text.ReadResponse(2); err != nil {
+		return data, fmt.Errorf("ftp %s: %w", strings.Fields(command)[0], err)
+	}
+	return data, nil
+}
+
+// passive opens the data connection announced by EPSV, or PASV when EPSV isn't supported
+func (c *ftpClient) passive() (net.Conn, error) {
+	var addr string
+	if _, message, err := c.command(229, "EPSV"); err == nil {
+		// 229 Entering Extended Passive Mode (|||port|)
+		start, end := strings.Index(message, "(|||"), strings.LastIndex(message, "|)")
+		if start < 0 || end <= start+4 {
+			return nil, fmt.Errorf("invalid EPSV reply %q", message)
+		}
+		addr = net.JoinHostPort(c.host, message[start+4:end])
+	} else {
+		_, message, err := c.command(227, "PASV")
+		if err != nil {
+			return nil, err
+		}
+		// 227 Entering Passive Mode (h1,h2,h3,h4,p1,p2); the host is ignored like EPSV does
+		start, end := strings.Index(message, "("), strings.LastIndex(message, ")")
+		if start < 0 || end <= start {
+			return nil, fmt.Errorf("invalid PASV reply %q", message)
+		}
+		fields := strings.Split(message[start+1:end], ",")
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("invalid PASV reply %q", message)
+		}
+		p1, err1 := strconv.Atoi(strings.TrimSpace(fields[4]))
+		p2, err2 := strconv.Atoi(strings.TrimSpace(fields[5]))
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("invalid PASV reply %q", message)
+		}
+		addr = net.JoinHostPort(c.host, strconv.Itoa(p1*256+p2))
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, time.Until(c.deadline))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ftp data connection to %s: %w", addr, err)
+	}
+	conn.SetDeadline(c.deadline)
+	return conn, nil
+}
@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// defaultGoldenKey is the ConfigMap key of the golden document when none is set
+const defaultGoldenKey = "response.json"
+
+// validateGoldenResponse compares a JSON response against the task's golden document
+func (r *McallTaskReconciler) validateGoldenResponse(ctx context.Context, task *mcallv1.McallTask, response string) ([]mcallv1.ResponseDiff, error) {
+	golden := task.Spec.HttpValidation.GoldenResponse
+
+	key := golden.Key
+	if key == "" {
+		key = defaultGoldenKey
+	}
+
+	var configMap corev1.ConfigMap
+	if err := r.Get(ctx, types.NamespacedName{Name: golden.ConfigMapName, Namespace: task.Namespace}, &configMap); err != nil {
+		return nil, fmt.Errorf("failed to get golden ConfigMap %s: %w", golden.ConfigMapName, err)
+	}
+
+	document, exists := configMap.Data[key]
+	if !exists {
+		return nil, fmt.Errorf("golden ConfigMap %s has no key %q", golden.ConfigMapName, key)
+	}
+
+	return diffJSON(document, response, golden.IgnoreFields)
+}
+
+// diffJSON compares two JSON documents after normalization and returns their differences
+func diffJSON(expectedJSON, actualJSON string, ignoreFields []string) ([]mcallv1.ResponseDiff, error) {
+	var expected, actual interface{}
+	if err := json.Unmarshal([]byte(expectedJSON), &expected); err != nil {
+		return nil, fmt.Errorf("invalid golden JSON: %w", err)
+	}
+	if err := json.Unmarshal([]byte(actualJSON), &actual); err != nil {
+		return nil, fmt.Errorf("response is not valid JSON: %w", err)
+	}
+
+	ignore := make([][]string, 0, len(ignoreFields))
+	for _, field := range ignoreFields {
+		ignore = append(ignore, strings.Split(field, "."))
+	}
+
+	var diffs []mcallv1.ResponseDiff
+	compareJSON(nil, expected, actual, ignore, &diffs)
+	return diffs, nil
+}
+
+// compareJSON recursively compares normalized JSON values
+func compareJSON(path []string, expected, actual interface{}, ignore [][]string, diffs *[]mcallv1.ResponseDiff) {
+	if isIgnoredPath(path, ignore) {
+		return
+	}
+
+	switch expectedValue := expected.(type) {
+	case map[string]interface{}:
+		actualValue, ok := actual.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		keys := make(map[string]bool)
+		for key := range expectedValue {
+			keys[key] = true
+		}
+		for key := range actualValue {
+			keys[key] = true
+		}
+		sortedKeys := make([]string, 0, len(keys))
+		for key := range keys {
+			sortedKeys = append(sortedKeys, key)
+		}
+		sort.Strings(sortedKeys)
+
+		for _, key := range sortedKeys {
+			childPath := append(append([]string{}, path...), key)
+			expectedChild, inExpected := expectedValue[key]
+			actualChild, inActual := actualValue[key]
+			switch {
+			case !inActual:
+				if !isIgnoredPath(childPath, ignore) {
+					*diffs = append(*diffs, mcallv1.ResponseDiff{Path: strings.Join(childPath, "."), Expected: jsonString(expectedChild)})
+				}
+			case !inExpected:
+				if !isIgnoredPath(childPath, ignore) {
+					*diffs = append(*diffs, mcallv1.ResponseDiff{Path: strings.Join(childPath, "."), Actual: jsonString(actualChild)})
+				}
+			default:
+				compareJSON(childPath, expectedChild, actualChild, ignore, diffs)
+			}
+		}
+		return
+	case []interface{}:
+		actualValue, ok := actual.([]interface{})
+		if !ok {
+			break
+		}
+
+		length := len(expectedValue)
+		if len(actualValue) > length {
+			length = len(actualValue)
+		}
+		for i := 0; i < length; i++ {
+			childPath := append(append([]string{}, path...), strconv.Itoa(i))
+			switch {
+			case i >= len(actualValue):
+				if !isIgnoredPath(childPath, ignore) {
+					*diffs = append(*diffs, mcallv1.ResponseDiff{Path: strings.Join(childPath, "."), Expected: jsonString(expectedValue[i])})
+				}
+			case i >= len(expectedValue):
+				if !isIgnoredPath(childPath, ignore) {
+					*diffs = append(*diffs, mcallv1.ResponseDiff{Path: strings.Join(childPath, "."), Actual: jsonString(actualValue[i])})
+				}
+			default:
+				compareJSON(childPath, expectedValue[i], actualValue[i], ignore, diffs)
+			}
+		}
+		return
+	default:
+		if jsonString(expected) == jsonString(actual) {
+			return
+		}
+	}
+
+	*diffs = append(*diffs, mcallv1.ResponseDiff{
+		Path:     strings.Join(path, "."),
+		Expected: jsonString(expected),
+		Actual:   jsonString(actual),
+	})
+}
+
+// isIgnoredPath reports whether a path matches one of the ignore patterns
+func isIgnoredPath(path []string, ignore [][]string) bool {
+	for _, pattern := range ignore {
+		if len(pattern) != len(path) {
+			continue
+		}
+		matched := true
+		for i, segment := range pattern {
+			if segment != "*" && segment != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonString renders a normalized JSON value for diff output
+func jsonString(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
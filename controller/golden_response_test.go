@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestDiffJSON tests comparing responses against a golden document
+func TestDiffJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		expected     string
+		actual       string
+		ignoreFields []string
+		wantDiffs    []mcallv1.ResponseDiff
+		wantErr      bool
+	}{
+		{
+			name:     "equal documents with different key order",
+			expected: `{"status":"ok","items":[1,2]}`,
+			actual:   `{"items":[1,2],"status":"ok"}`,
+		},
+		{
+			name:     "changed value",
+			expected: `{"status":"ok","meta":{"version":1}}`,
+			actual:   `{"status":"ok","meta":{"version":2}}`,
+			wantDiffs: []mcallv1.ResponseDiff{
+				{Path: "meta.version", Expected: "1", Actual: "2"},
+			},
+		},
+		{
+			name:     "missing and unexpected fields",
+			expected: `{"id":1,"name":"a"}`,
+			actual:   `{"id":1,"extra":true}`,
+			wantDiffs: []mcallv1.ResponseDiff{
+				{Path: "extra", Actual: "true"},
+				{Path: "name", Expected: `"a"`},
+			},
+		},
+		{
+			name:         "ignored fields with wildcard",
+			expected:     `{"timestamp":"t1","items":[{"id":1,"updatedAt":"x"}]}`,
+			actual:       `{"timestamp":"t2","items":[{"id":1,"updatedAt":"y"}]}`,
+			ignoreFields: []string{"timestamp", "items.*.updatedAt"},
+		},
+		{
+			name:     "array length mismatch",
+			expected: `[1,2]`,
+			actual:   `[1]`,
+			wantDiffs: []mcallv1.ResponseDiff{
+				{Path: "1", Expected: "2"},
+			},
+		},
+		{
+			name:     "invalid response",
+			expected: `{"status":"ok"}`,
+			actual:   `not json`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs, err := diffJSON(tt.expected, tt.actual, tt.ignoreFields)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("diffJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(diffs, tt.wantDiffs) {
+				t.Errorf("diffJSON() = %+v, want %+v", diffs, tt.wantDiffs)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// graphqlRequestBody encodes the operation of a graphql task as the JSON request body
+func graphqlRequestBody(request *mcallv1.GraphQLRequest) (string, error) {
+	if request == nil || request.Query == "" {
+		return "", fmt.Errorf("graphql task requires spec.graphql.query")
+	}
+
+	body := map[string]interface{}{"query": request.Query}
+	if request.Variables != "" {
+		var variables map[string]interface{}
+		if err := json.Unmarshal([]byte(request.Variables), &variables); err != nil {
+			return "", fmt.Errorf("graphql variables must be a JSON object: %w", err)
+		}
+		body["variables"] = variables
+	}
+	if request.OperationName != "" {
+		body["operationName"] = request.OperationName
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// checkGraphQLResponse fails responses that aren't GraphQL results or that carry errors.
+// GraphQL servers report failed operations with HTTP 200, so the status code isn't enough.
+func checkGraphQLResponse(body string) error {
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		return fmt.Errorf("invalid GraphQL response: %w", err)
+	}
+
+	if len(result.Errors) > 0 {
+		messages := make([]string, 0, len(result.Errors))
+		for _, graphqlErr := range result.Errors {
+			messages = append(messages, graphqlErr.Message)
+		}
+		return fmt.Errorf("GraphQL errors: %s", strings.Join(messages, "; "))
+	}
+	if len(result.Data) == 0 || string(result.Data) == "null" {
+		return fmt.Errorf("GraphQL response has no data")
+	}
+	return nil
+}
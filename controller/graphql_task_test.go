@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestGraphQLTask tests posting GraphQL operations and checking the errors array
+func TestGraphQLTask(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Query         string                 `json:"query"`
+			Variables     map[string]interface{} `json:"variables"`
+			OperationName string                 `json:"operationName"`
+		}
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		switch {
+		case strings.Contains(request.Query, "broken"):
+			w.Write([]byte(`{"data":null,"errors":[{"message":"Cannot query field \"broken\""},{"message":"second"}]}`))
+		case strings.Contains(request.Query, "html"):
+			w.Write([]byte(`<html>maintenance</html>`))
+		default:
+			w.Write([]byte(`{"data":{"user":{"id":"` + request.Variables["id"].(string) + `","operation":"` + request.OperationName + `"}}}`))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		request    *mcallv1.GraphQLRequest
+		wantOutput string
+		wantErr    string
+	}{
+		{
+			name:       "data",
+			request:    &mcallv1.GraphQLRequest{Query: "query GetUser($id: ID!) { user(id: $id) { id } }", Variables: `{"id": "42"}`, OperationName: "GetUser"},
+			wantOutput: `{"data":{"user":{"id":"42","operation":"GetUser"}}}`,
+		},
+		{name: "errors", request: &mcallv1.GraphQLRequest{Query: "{ broken }"}, wantErr: `GraphQL errors: Cannot query field "broken"; second`},
+		{name: "not graphql", request: &mcallv1.GraphQLRequest{Query: "{ html }"}, wantErr: "invalid GraphQL response"},
+		{name: "invalid variables", request: &mcallv1.GraphQLRequest{Query: "{ user }", Variables: "[1]"}, wantErr: "must be a JSON object"},
+		{name: "no query", wantErr: "requires spec.graphql.query"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &mcallv1.McallTask{Spec: mcallv1.McallTaskSpec{Type: "graphql", Input: server.URL, GraphQL: tt.request}}
+			output, err := executeTask(task, 5*time.Second, logr.Discard())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("executeTask() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("executeTask() error = %v", err)
+			}
+			if output != tt.wantOutput {
+				t.Errorf("output = %s, want %s", output, tt.wantOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// executeGrpcRequest calls a gRPC target and returns the JSON response.
+// Without a method the standard health check is called and anything but SERVING fails.
+func executeGrpcRequest(target string, call *mcallv1.GrpcCall, timeout time.Duration, tlsConfig *tls.Config) (string, error) {
+	if target == "" {
+		return "", fmt.Errorf("empty gRPC target")
+	}
+	if call == nil {
+		call = &mcallv1.GrpcCall{}
+	}
+
+	creds := insecure.NewCredentials()
+	if call.TLS {
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to %s: %w", target, err)
+	}
+	defer conn.Close()
+
+	if call.Method == "" {
+		resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: call.Service})
+		if err != nil {
+			return "", fmt.Errorf("health check failed: %w", err)
+		}
+		output, _ := protojson.Marshal(resp)
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			return string(output), fmt.Errorf("health check status %s", resp.Status)
+		}
+		return string(output), nil
+	}
+
+	method, err := resolveGrpcMethod(ctx, conn, call.Method)
+	if err != nil {
+		return "", err
+	}
+
+	request := dynamicpb.NewMessage(method.Input())
+	if call.Request != "" {
+		if err := protojson.Unmarshal([]byte(call.Request), request); err != nil {
+			return "", fmt.Errorf("invalid request for %s: %w", call.Method, err)
+		}
+	}
+	response := dynamicpb.NewMessage(method.Output())
+	fullMethod := fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name())
+	if err := conn.Invoke(ctx, fullMethod, request, response); err != nil {
+		return "", fmt.Errorf("call %s failed: %w", call.Method, err)
+	}
+
+	output, err := protojson.Marshal(response)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode response: %w", err)
+	}
+	return string(output), nil
+}
+
+// resolveGrpcMethod looks up a unary method ("package.Service/Method") through server reflection
+func resolveGrpcMethod(ctx context.Context, conn *grpc.ClientConn, name string) (protoreflect.MethodDescriptor, error) {
+	service, methodName, found := strings.Cut(strings.TrimPrefix(name, "/"), "/")
+	if !found {
+		return nil, fmt.Errorf("invalid gRPC method %q, want package.Service/Method", name)
+	}
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("server reflection unavailable: %w", err)
+	}
+	defer stream.CloseSend()
+
+	if err := stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service},
+	}); err != nil {
+		return nil, fmt.Errorf("server reflection failed: %w", err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, fmt.Errorf("server reflection failed: %w", err)
+	}
+	if errResp := resp.GetErrorResponse(); errResp != nil {
+		return nil, fmt.Errorf("service %s not found: %s", service, errResp.ErrorMessage)
+	}
+
+	files, err := buildFileRegistry(resp.GetFileDescriptorResponse().GetFileDescriptorProto())
+	if err != nil {
+		return nil, err
+	}
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("service %s not found: %w", service, err)
+	}
+	serviceDescriptor, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", service)
+	}
+
+	method := serviceDescriptor.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return nil, fmt.Errorf("method %s not found in service %s", methodName, service)
+	}
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return nil, fmt.Errorf("method %s is streaming, only unary methods are supported", name)
+	}
+	return method, nil
+}
+
+// buildFileRegistry builds a registry from reflected file descriptors, which may arrive in any order.
+// Dependencies missing from the reflection response are looked up in the linked-in descriptors.
+func buildFileRegistry(encoded [][]byte) (*protoregistry.Files, error) {
+	pending := make([]*descriptorpb.FileDescriptorProto, 0, len(encoded))
+	for _, data := range encoded {
+		file := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(data, file); err != nil {
+			return nil, fmt.Errorf("invalid file descriptor: %w", err)
+		}
+		pending = append(pending, file)
+	}
+
+	files := &protoregistry.Files{}
+	resolver := registryResolver{files}
+	for len(pending) > 0 {
+		var remaining []*descriptorpb.FileDescriptorProto
+		var lastErr error
+		for _, file := range pending {
+			if _, err := files.FindFileByPath(file.GetName()); err == nil {
+				continue
+			}
+			descriptor, err := protodesc.NewFile(file, resolver)
+			if err != nil {
+				remaining, lastErr = append(remaining, file), err
+				continue
+			}
+			if err := files.RegisterFile(descriptor); err != nil {
+				return nil, err
+			}
+		}
+		if len(remaining) == len(pending) {
+			return nil, fmt.Errorf("failed to resolve file descriptors: %w", lastErr)
+		}
+		pending = remaining
+	}
+	return files, nil
+}
+
+// registryResolver resolves descriptors from reflected files, falling back to the linked-in ones
+type registryResolver struct {
+	files *protoregistry.Files
+}
+
+func (r registryResolver) FindFileByPath(path string) (protoreflect.FileDescriptor, error) {
+	if file, err := r.files.FindFileByPath(path); err == nil {
+		return file, nil
+	}
+	return protoregistry.GlobalFiles.FindFileByPath(path)
+}
+
+func (r registryResolver) FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error) {
+	if descriptor, err := r.files.FindDescriptorByName(name); err == nil {
+		return descriptor, nil
+	}
+	return protoregistry.GlobalFiles.FindDescriptorByName(name)
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestExecuteGrpcRequest tests health checks and reflection-based unary calls against a local server
+func TestExecuteGrpcRequest(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("orders", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	target := listener.Addr().String()
+
+	tests := []struct {
+		name       string
+		call       *mcallv1.GrpcCall
+		wantOutput string
+		wantErr    string
+	}{
+		{name: "health check", wantOutput: `"SERVING"`},
+		{name: "service not serving", call: &mcallv1.GrpcCall{Service: "orders"}, wantOutput: `"NOT_SERVING"`, wantErr: "health check status NOT_SERVING"},
+		{
+			name:       "unary call through reflection",
+			call:       &mcallv1.GrpcCall{Method: "grpc.health.v1.Health/Check", Request: `{"service": "orders"}`},
+			wantOutput: `"NOT_SERVING"`,
+		},
+		{name: "unknown method", call: &mcallv1.GrpcCall{Method: "grpc.health.v1.Health/Missing"}, wantErr: "method Missing not found"},
+		{name: "streaming method", call: &mcallv1.GrpcCall{Method: "grpc.health.v1.Health/Watch"}, wantErr: "only unary methods"},
+		{name: "invalid method name", call: &mcallv1.GrpcCall{Method: "Check"}, wantErr: "want package.Service/Method"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := executeGrpcRequest(target, tt.call, 5*time.Second, nil)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("executeGrpcRequest() unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("executeGrpcRequest() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if !strings.Contains(output, tt.wantOutput) {
+				t.Errorf("output = %q, want it to contain %q", output, tt.wantOutput)
+			}
+		})
+	}
+
+	// JSON inputs support grpc with expect against the JSON response
+	task := &mcallv1.McallTask{Spec: mcallv1.McallTaskSpec{
+		Type: "cmd",
+		Input: fmt.Sprintf(`{"inputs": [
+			{"type": "grpc", "input": %q, "name": "health", "expect": "SERVING"},
+			{"type": "grpc", "input": %q, "name": "orders", "method": "grpc.health.v1.Health/Check", "request": {"service": "orders"}, "expect": "NOT_SERVING"}
+		]}`, target, target),
+	}}
+	if output, err := executeTask(task, 5*time.Second, logr.Discard()); err != nil {
+		t.Errorf("executeTask() error = %v, output = %s", err, output)
+	}
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// defaultAPIKeyHeader is the header carrying the API key when none is set
+const defaultAPIKeyHeader = "X-API-Key"
+
+// resolveHttpAuth reads the task's credentials from its Secret and returns the headers to send.
+// The Secret is read on every execution so that rotated credentials are picked up.
+func (r *McallTaskReconciler) resolveHttpAuth(ctx context.Context, task *mcallv1.McallTask) (map[string]string, error) {
+	auth := task.Spec.HttpAuth
+
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Name: auth.SecretName, Namespace: task.Namespace}, &secret); err != nil {
+		return nil, fmt.Errorf("failed to get auth Secret %s: %w", auth.SecretName, err)
+	}
+
+	value := func(key, defaultKey string) (string, error) {
+		if key == "" {
+			key = defaultKey
+		}
+		data, exists := secret.Data[key]
+		if !exists {
+			return "", fmt.Errorf("auth Secret %s has no key %q", auth.SecretName, key)
+		}
+		return string(data), nil
+	}
+
+	switch auth.Type {
+	case "basic":
+		username, err := value(auth.UsernameKey, "username")
+		if err != nil {
+			return nil, err
+		}
+		password, err := value(auth.Key, "password")
+		if err != nil {
+			return nil, err
+		}
+		credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		return map[string]string{"Authorization": "Basic " + credentials}, nil
+	case "bearer":
+		token, err := value(auth.Key, "token")
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"Authorization": "Bearer " + token}, nil
+	case "apiKey":
+		apiKey, err := value(auth.Key, "apiKey")
+		if err != nil {
+			return nil, err
+		}
+		header := auth.HeaderName
+		if header == "" {
+			header = defaultAPIKeyHeader
+		}
+		return map[string]string{header: apiKey}, nil
+	default:
+		return nil, fmt.Errorf("unsupported httpAuth type %q", auth.Type)
+	}
+}
+
+// withAuthHeaders returns a copy of the task whose headers include the auth headers.
+// The copy is only used for execution, so credentials never reach the stored spec.
+func withAuthHeaders(task *mcallv1.McallTask, authHeaders map[string]string) *mcallv1.McallTask {
+	execTask := task.DeepCopy()
+	if execTask.Spec.Headers == nil {
+		execTask.Spec.Headers = make(map[string]string, len(authHeaders))
+	}
+	for name, value := range authHeaders {
+		execTask.Spec.Headers[name] = value
+	}
+	return execTask
+}
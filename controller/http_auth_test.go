@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestResolveHttpAuth tests building the auth headers from the credentials Secret
+func TestResolveHttpAuth(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-credentials", Namespace: "default"},
+		Data: map[string][]byte{
+			"username": []byte("admin"),
+			"password": []byte("s3cret"),
+			"token":    []byte("abc123"),
+			"apiKey":   []byte("key-1"),
+			"altKey":   []byte("key-2"),
+		},
+	}
+	r := &McallTaskReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build(),
+		Scheme: scheme,
+	}
+
+	tests := []struct {
+		name    string
+		auth    mcallv1.HttpAuth
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "basic",
+			auth: mcallv1.HttpAuth{Type: "basic", SecretName: "api-credentials"},
+			want: map[string]string{"Authorization": "Basic YWRtaW46czNjcmV0"},
+		},
+		{
+			name: "bearer",
+			auth: mcallv1.HttpAuth{Type: "bearer", SecretName: "api-credentials"},
+			want: map[string]string{"Authorization": "Bearer abc123"},
+		},
+		{
+			name: "apiKey with default header",
+			auth: mcallv1.HttpAuth{Type: "apiKey", SecretName: "api-credentials"},
+			want: map[string]string{"X-API-Key": "key-1"},
+		},
+		{
+			name: "apiKey with custom key and header",
+			auth: mcallv1.HttpAuth{Type: "apiKey", SecretName: "api-credentials", Key: "altKey", HeaderName: "X-Token"},
+			want: map[string]string{"X-Token": "key-2"},
+		},
+		{
+			name:    "missing key",
+			auth:    mcallv1.HttpAuth{Type: "bearer", SecretName: "api-credentials", Key: "missing"},
+			wantErr: true,
+		},
+		{
+			name:    "missing secret",
+			auth:    mcallv1.HttpAuth{Type: "bearer", SecretName: "missing"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{Name: "protected-health", Namespace: "default"},
+				Spec:       mcallv1.McallTaskSpec{Type: "get", Input: "https://api.example.com/health", HttpAuth: &tt.auth},
+			}
+
+			got, err := r.resolveHttpAuth(context.Background(), task)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveHttpAuth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("resolveHttpAuth() = %v, want %v", got, tt.want)
+			}
+			for name, value := range tt.want {
+				if got[name] != value {
+					t.Errorf("header %s = %q, want %q", name, got[name], value)
+				}
+			}
+
+			if err == nil {
+				execTask := withAuthHeaders(task, got)
+				if len(task.Spec.Headers) != 0 {
+					t.Errorf("withAuthHeaders() modified the task spec: %v", task.Spec.Headers)
+				}
+				if len(execTask.Spec.Headers) != len(got) {
+					t.Errorf("withAuthHeaders() headers = %v, want %v", execTask.Spec.Headers, got)
+				}
+			}
+		})
+	}
+}
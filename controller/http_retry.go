@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"syscall"
+	"time"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// HTTP retry defaults used when the task doesn't set them
+const (
+	defaultHTTPRetryAttempts     = 3
+	defaultHTTPRetryInitialDelay = 200 * time.Millisecond
+	defaultHTTPRetryMaxDelay     = 5 * time.Second
+)
+
+// isTransientHTTPFailure reports whether a request may succeed when sent again:
+// 429 and 5xx responses, refused or reset connections
+func isTransientHTTPFailure(resp *httpResponse, err error) bool {
+	if err != nil {
+		return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+			errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+	}
+	return resp.StatusCode == 429 || resp.StatusCode >= 500
+}
+
+// doHTTPRequestWithRetry executes HTTP GET/POST request, retrying transient failures with
+// exponential backoff. It returns the last response and the number of requests made.
+func doHTTPRequestWithRetry(url, method string, timeout time.Duration, opts httpRequestOptions, policy *mcallv1.HttpRetry) (*httpResponse, int32, error) {
+	if policy == nil {
+		resp, err := doHTTPRequest(url, method, timeout, opts)
+		return resp, 1, err
+	}
+
+	maxAttempts, delay, maxDelay := int32(defaultHTTPRetryAttempts), defaultHTTPRetryInitialDelay, defaultHTTPRetryMaxDelay
+	if policy.MaxAttempts > 0 {
+		maxAttempts = policy.MaxAttempts
+	}
+	if policy.InitialDelayMs > 0 {
+		delay = time.Duration(policy.InitialDelayMs) * time.Millisecond
+	}
+	if policy.MaxDelayMs > 0 {
+		maxDelay = time.Duration(policy.MaxDelayMs) * time.Millisecond
+	}
+
+	var attempt int32
+	for {
+		attempt++
+		resp, err := doHTTPRequest(url, method, timeout, opts)
+		if attempt >= maxAttempts || !isTransientHTTPFailure(resp, err) {
+			return resp, attempt, err
+		}
+
+		time.Sleep(delay)
+		if delay *= 2; delay > maxDelay {
+			delay = maxDelay
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestDoHTTPRequestWithRetry tests retrying 429/5xx responses within one execution
+func TestDoHTTPRequestWithRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int // Status returned per request, the last one repeats
+		policy       *mcallv1.HttpRetry
+		wantStatus   int
+		wantAttempts int32
+	}{
+		{name: "no policy", statuses: []int{503, 200}, wantStatus: 503, wantAttempts: 1},
+		{name: "recovers after 5xx", statuses: []int{503, 502, 200}, policy: &mcallv1.HttpRetry{InitialDelayMs: 1}, wantStatus: 200, wantAttempts: 3},
+		{name: "recovers after 429", statuses: []int{429, 200}, policy: &mcallv1.HttpRetry{InitialDelayMs: 1}, wantStatus: 200, wantAttempts: 2},
+		{name: "attempts exhausted", statuses: []int{500}, policy: &mcallv1.HttpRetry{MaxAttempts: 2, InitialDelayMs: 1}, wantStatus: 500, wantAttempts: 2},
+		{name: "client error not retried", statuses: []int{404, 200}, policy: &mcallv1.HttpRetry{InitialDelayMs: 1}, wantStatus: 404, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				i := int(atomic.AddInt32(&requests, 1)) - 1
+				if i >= len(tt.statuses) {
+					i = len(tt.statuses) - 1
+				}
+				w.WriteHeader(tt.statuses[i])
+			}))
+			defer server.Close()
+
+			resp, attempts, err := doHTTPRequestWithRetry(server.URL, "GET", 5*time.Second, httpRequestOptions{}, tt.policy)
+			if err != nil {
+				t.Fatalf("doHTTPRequestWithRetry() error = %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus || attempts != tt.wantAttempts {
+				t.Errorf("status = %d, attempts = %d, want %d, %d", resp.StatusCode, attempts, tt.wantStatus, tt.wantAttempts)
+			}
+			if got := atomic.LoadInt32(&requests); got != tt.wantAttempts {
+				t.Errorf("server received %d requests, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+// TestDoHTTPRequestWithRetryConnectionRefused tests retrying requests to a closed port
+func TestDoHTTPRequestWithRetryConnectionRefused(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	_, attempts, err := doHTTPRequestWithRetry(url, "GET", 5*time.Second, httpRequestOptions{}, &mcallv1.HttpRetry{MaxAttempts: 3, InitialDelayMs: 1})
+	if err == nil {
+		t.Fatal("doHTTPRequestWithRetry() expected connection error")
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+const (
+	// defaultCABundleKey is the ConfigMap key of the CA bundle when none is set
+	defaultCABundleKey = "ca.crt"
+
+	// httpIdleConnTimeout closes the idle connections of the TLS transports, as http.DefaultTransport does
+	httpIdleConnTimeout = 90 * time.Second
+)
+
+// tlsClient is a resolved TLS configuration with the HTTP transport of the requests using it
+type tlsClient struct {
+	version   string // UIDs and resourceVersions of the Secret and ConfigMap it was built from
+	config    *tls.Config
+	transport *http.Transport
+}
+
+// tlsClients caches the TLS configurations by their settings, so executions share the
+// connections of a transport until the Secret or ConfigMap changes
+var (
+	tlsClientsMu sync.Mutex
+	tlsClients   = make(map[string]*tlsClient)
+)
+
+// newTLSTransport returns an HTTP transport with the TLS configuration
+func newTLSTransport(config *tls.Config) *http.Transport {
+	return &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: config,
+		IdleConnTimeout: httpIdleConnTimeout,
+	}
+}
+
+// cachedTLSTransport returns the transport of a TLS configuration resolveTLSConfig returned,
+// or nil when the configuration is not cached (anymore)
+func cachedTLSTransport(config *tls.Config) *http.Transport {
+	tlsClientsMu.Lock()
+	defer tlsClientsMu.Unlock()
+	for _, client := range tlsClients {
+		if client.config == config {
+			return client.transport
+		}
+	}
+	return nil
+}
+
+// getDefaultTLSConfig returns the controller-wide TLS settings and the namespace they are read from.
+// Tasks without their own tls block use these defaults.
+func getDefaultTLSConfig() (*mcallv1.TLSConfig, string) {
+	config := &mcallv1.TLSConfig{
+		ClientCertSecretName:  os.Getenv("HTTP_TLS_CLIENT_CERT_SECRET"),
+		CABundleConfigMapName: os.Getenv("HTTP_TLS_CA_BUNDLE_CONFIGMAP"),
+		CABundleKey:           os.Getenv("HTTP_TLS_CA_BUNDLE_KEY"),
+		InsecureSkipVerify:    os.Getenv("HTTP_TLS_INSECURE_SKIP_VERIFY") == "true",
+	}
+	if config.ClientCertSecretName == "" && config.CABundleConfigMapName == "" && !config.InsecureSkipVerify {
+		return nil, ""
+	}
+	return config, getEnvOrDefault("NAMESPACE", "mcall-system")
+}
+
+// resolveTLSConfig builds the TLS configuration of a task from its Secret and ConfigMap.
+// It returns nil when neither the task nor the controller configures TLS. The configuration
+// is reused while the Secret and ConfigMap keep their resourceVersions.
+func (r *McallTaskReconciler) resolveTLSConfig(ctx context.Context, task *mcallv1.McallTask) (*tls.Config, error) {
+	config, namespace := task.Spec.TLS, task.Namespace
+	if config == nil {
+		config, namespace = getDefaultTLSConfig()
+		if config == nil {
+			return nil, nil
+		}
+	}
+
+	key := fmt.Sprintf("%s/%s/%s/%s/%t", namespace, config.ClientCertSecretName, config.CABundleConfigMapName, config.CABundleKey, config.InsecureSkipVerify)
+	var secret corev1.Secret
+	var configMap corev1.ConfigMap
+	if config.ClientCertSecretName != "" {
+		if err := r.Get(ctx, types.NamespacedName{Name: config.ClientCertSecretName, Namespace: namespace}, &secret); err != nil {
+			return nil, fmt.Errorf("failed to get client certificate Secret %s: %w", config.ClientCertSecretName, err)
+		}
+	}
+	if config.CABundleConfigMapName != "" {
+		if err := r.Get(ctx, types.NamespacedName{Name: config.CABundleConfigMapName, Namespace: namespace}, &configMap); err != nil {
+			return nil, fmt.Errorf("failed to get CA bundle ConfigMap %s: %w", config.CABundleConfigMapName, err)
+		}
+	}
+	version := fmt.Sprintf("%s/%s/%s/%s", secret.UID, secret.ResourceVersion, configMap.UID, configMap.ResourceVersion)
+
+	tlsClientsMu.Lock()
+	defer tlsClientsMu.Unlock()
+	cached := tlsClients[key]
+	if cached != nil && cached.version == version {
+		return cached.config, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if config.ClientCertSecretName != "" {
+		cert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate in Secret %s: %w", config.ClientCertSecretName, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if config.CABundleConfigMapName != "" {
+		caKey := config.CABundleKey
+		if caKey == "" {
+			caKey = defaultCABundleKey
+		}
+		bundle, exists := configMap.Data[caKey]
+		if !exists {
+			return nil, fmt.Errorf("CA bundle ConfigMap %s has no key %q", config.CABundleConfigMapName, caKey)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(bundle)) {
+			return nil, fmt.Errorf("no valid certificates in CA bundle ConfigMap %s", config.CABundleConfigMapName)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cached != nil {
+		// Requests still running keep their connections, the idle ones use the old certificates
+		cached.transport.CloseIdleConnections()
+	}
+	tlsClients[key] = &tlsClient{version: version, config: tlsConfig, transport: newTLSTransport(tlsConfig)}
+	return tlsConfig, nil
+}
@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// newClientCertificate returns a self-signed client certificate and key in PEM format
+func newClientCertificate(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// TestResolveTLSConfig tests calling a TLS server with a custom CA bundle, client certificate and insecureSkipVerify
+func TestResolveTLSConfig(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "client certificates: %d", len(r.TLS.PeerCertificates))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	certPEM, keyPEM := newClientCertificate(t)
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	r := &McallTaskReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "client-cert", Namespace: "default"},
+				Type:       corev1.SecretTypeTLS,
+				Data:       map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM},
+			},
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "internal-ca", Namespace: "default"},
+				Data:       map[string]string{"ca.crt": string(caPEM), "invalid": "not a certificate"},
+			},
+		).Build(),
+		Scheme: scheme,
+	}
+
+	tests := []struct {
+		name       string
+		tls        *mcallv1.TLSConfig
+		wantErr    bool
+		wantCallOK bool
+	}{
+		{name: "no tls config", tls: nil, wantCallOK: false},
+		{name: "CA bundle without client certificate", tls: &mcallv1.TLSConfig{CABundleConfigMapName: "internal-ca"}, wantCallOK: false},
+		{name: "CA bundle and client certificate", tls: &mcallv1.TLSConfig{CABundleConfigMapName: "internal-ca", ClientCertSecretName: "client-cert"}, wantCallOK: true},
+		{name: "insecureSkipVerify and client certificate", tls: &mcallv1.TLSConfig{InsecureSkipVerify: true, ClientCertSecretName: "client-cert"}, wantCallOK: true},
+		{name: "missing client certificate Secret", tls: &mcallv1.TLSConfig{ClientCertSecretName: "missing"}, wantErr: true},
+		{name: "missing CA bundle key", tls: &mcallv1.TLSConfig{CABundleConfigMapName: "internal-ca", CABundleKey: "missing"}, wantErr: true},
+		{name: "invalid CA bundle", tls: &mcallv1.TLSConfig{CABundleConfigMapName: "internal-ca", CABundleKey: "invalid"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{Name: "internal-health", Namespace: "default"},
+				Spec:       mcallv1.McallTaskSpec{Type: "get", Input: server.URL, TLS: tt.tls},
+			}
+
+			tlsConfig, err := r.resolveTLSConfig(context.Background(), task)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveTLSConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			output, _, err := executeHTTPRequestWithOptions(server.URL, "GET", 5*time.Second, httpRequestOptions{TLSConfig: tlsConfig})
+			if (err == nil) != tt.wantCallOK {
+				t.Errorf("request error = %v, want success %v", err, tt.wantCallOK)
+			}
+			if tt.wantCallOK && output != "client certificates: 1" {
+				t.Errorf("output = %q, want the client certificate to be presented", output)
+			}
+		})
+	}
+}
+
+// TestResolveTLSConfigCache tests reusing the TLS configuration and its transport until the CA bundle changes
+func TestResolveTLSConfigCache(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cached-ca", Namespace: "default"},
+		Data:       map[string]string{"ca.crt": string(caPEM)},
+	}
+	r := &McallTaskReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build(), Scheme: scheme}
+	task := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: "internal-health", Namespace: "default"},
+		Spec:       mcallv1.McallTaskSpec{Type: "get", Input: server.URL, TLS: &mcallv1.TLSConfig{CABundleConfigMapName: "cached-ca"}},
+	}
+
+	first, err := r.resolveTLSConfig(context.Background(), task)
+	if err != nil {
+		t.Fatalf("resolveTLSConfig() error = %v", err)
+	}
+	transport := cachedTLSTransport(first)
+	if transport == nil || transport.IdleConnTimeout != httpIdleConnTimeout {
+		t.Fatalf("cachedTLSTransport() = %+v, want a transport with an idle connection timeout", transport)
+	}
+	for i := 0; i < 2; i++ {
+		if _, _, err := executeHTTPRequestWithOptions(server.URL, "GET", 5*time.Second, httpRequestOptions{TLSConfig: first}); err != nil {
+			t.Fatalf("request error = %v", err)
+		}
+	}
+	if again, _ := r.resolveTLSConfig(context.Background(), task); again != first {
+		t.Errorf("resolveTLSConfig() built a new configuration for unchanged resources")
+	}
+
+	configMap.Data["ca.crt"] = string(caPEM) + "\n"
+	if err := r.Update(context.Background(), configMap); err != nil {
+		t.Fatalf("failed to update ConfigMap: %v", err)
+	}
+	updated, err := r.resolveTLSConfig(context.Background(), task)
+	if err != nil {
+		t.Fatalf("resolveTLSConfig() error = %v", err)
+	}
+	if updated == first || cachedTLSTransport(updated) == transport {
+		t.Errorf("resolveTLSConfig() reused the configuration of the old CA bundle")
+	}
+	if cachedTLSTransport(first) != nil {
+		t.Errorf("the transport of the old CA bundle is still cached")
+	}
+}
@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/itchyny/gojq"
+)
+
+// compileJQ compiles the jq program of an input source
+func compileJQ(program string) (*gojq.Code, error) {
+	query, err := gojq.Parse(program)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jq program %q: %w", program, err)
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jq program %q: %w", program, err)
+	}
+	return code, nil
+}
+
+// applyJQ runs a jq program over a JSON value, e.g. `[.items[].name] | join(",")`. Like
+// jq -r, strings are returned raw and other results as JSON, several results one per line.
+func applyJQ(value string, program string) (string, error) {
+	code, err := compileJQ(program)
+	if err != nil {
+		return "", err
+	}
+
+	var input interface{}
+	if err := json.Unmarshal([]byte(value), &input); err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	var results []string
+	iter := code.Run(input)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := v.(error); ok {
+			return "", fmt.Errorf("failed to run jq program %q: %w", program, err)
+		}
+		if s, ok := v.(string); ok {
+			results = append(results, s)
+			continue
+		}
+		jsonBytes, err := gojq.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode jq result: %w", err)
+		}
+		results = append(results, string(jsonBytes))
+	}
+	return strings.Join(results, "\n"), nil
+}
@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// kafkaLogRequestTimeout bounds each delivery attempt of a batch of log entries
+const kafkaLogRequestTimeout = 10 * time.Second
+
+var (
+	kafkaLogDroppedEntries = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "mcall_logging_kafka_dropped_entries_total",
+		Help: "Number of log entries dropped because the Kafka buffer was full or delivery failed",
+	})
+
+	kafkaLogProducersMu sync.Mutex
+	kafkaLogProducers   = make(map[string]*kafkaLogProducer)
+)
+
+func init() {
+	metrics.Registry.MustRegister(kafkaLogDroppedEntries)
+}
+
+// kafkaLogProducer produces log entries to the logging topic with an asynchronous kafka-go
+// Writer, which batches them, balances them round-robin over the partitions, follows partition
+// leaders and retries retriable errors. It is shared by the Kafka backends of all entries.
+type kafkaLogProducer struct {
+	config  LoggingConfig
+	writer  *kafka.Writer
+	pending atomic.Int64 // Entries handed to the writer and not delivered or dropped yet
+}
+
+// getKafkaLogProducer returns the producer of the configured brokers and topic, starting it on first use
+func getKafkaLogProducer(config LoggingConfig) (*kafkaLogProducer, error) {
+	key := strings.Join(config.Kafka.Brokers, ",") + "/" + config.Kafka.Topic
+
+	kafkaLogProducersMu.Lock()
+	defer kafkaLogProducersMu.Unlock()
+	if producer, exists := kafkaLogProducers[key]; exists {
+		return producer, nil
+	}
+
+	transport, err := newKafkaTransport(config)
+	if err != nil {
+		return nil, err
+	}
+	var compression kafka.Compression
+	if config.Kafka.Compression != "" {
+		if err := compression.UnmarshalText([]byte(config.Kafka.Compression)); err != nil {
+			return nil, fmt.Errorf("unsupported kafka compression: %s", config.Kafka.Compression)
+		}
+	}
+
+	producer := &kafkaLogProducer{config: config}
+	producer.writer = &kafka.Writer{
+		Addr:         kafka.TCP(config.Kafka.Brokers...),
+		Topic:        config.Kafka.Topic,
+		Balancer:     &kafka.RoundRobin{},
+		MaxAttempts:  config.Kafka.MaxRetries + 1,
+		BatchSize:    max(config.Kafka.BatchSize, 1),
+		BatchTimeout: max(config.Kafka.BatchTimeout, 10*time.Millisecond),
+		WriteTimeout: kafkaLogRequestTimeout,
+		RequiredAcks: kafka.RequireAll,
+		Compression:  compression,
+		Async:        true,
+		Completion:   producer.completed,
+		Transport:    transport,
+	}
+	kafkaLogProducers[key] = producer
+	return producer, nil
+}
+
+// newKafkaTransport returns the kafka-go transport of the logging brokers, with the TLS and
+// SASL settings of the configuration
+func newKafkaTransport(config LoggingConfig) (*kafka.Transport, error) {
+	transport := &kafka.Transport{ClientID: kafkaClientID, DialTimeout: kafkaLogRequestTimeout}
+
+	if config.Kafka.TLS.Enabled {
+		transport.TLS = &tls.Config{InsecureSkipVerify: config.Kafka.TLS.InsecureSkipVerify}
+		if config.Kafka.TLS.CAFile != "" {
+			caBundle, err := os.ReadFile(config.Kafka.TLS.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read kafka CA file: %w", err)
+			}
+			transport.TLS.RootCAs = x509.NewCertPool()
+			if !transport.TLS.RootCAs.AppendCertsFromPEM(caBundle) {
+				return nil, fmt.Errorf("kafka CA file %s has no PEM certificates", config.Kafka.TLS.CAFile)
+			}
+		}
+	}
+
+	var mechanism sasl.Mechanism
+	var err error
+	switch sasl := config.Kafka.SASL; sasl.Mechanism {
+	case "":
+	case "PLAIN":
+		mechanism = plain.Mechanism{Username: sasl.Username, Password: sasl.Password}
+	case "SCRAM-SHA-256":
+		mechanism, err = scram.Mechanism(scram.SHA256, sasl.Username, sasl.Password)
+	case "SCRAM-SHA-512":
+		mechanism, err = scram.Mechanism(scram.SHA512, sasl.Username, sasl.Password)
+	default:
+		return nil, fmt.Errorf("unsupported kafka SASL mechanism: %s", sasl.Mechanism)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid kafka SASL credentials: %w", err)
+	}
+	transport.SASL = mechanism
+	return transport, nil
+}
+
+// stopKafkaLogProducers stops the producers after delivering their buffered entries, so the
+// Kafka backends start new ones with the reloaded configuration
+func stopKafkaLogProducers() {
+	kafkaLogProducersMu.Lock()
+	defer kafkaLogProducersMu.Unlock()
+	for key, producer := range kafkaLogProducers {
+		// Close delivers the pending batches, which may take until their retries are exhausted
+		go producer.writer.Close()
+		delete(kafkaLogProducers, key)
+	}
+}
+
+// enqueue hands a message to the writer, dropping it when the buffer is full
+func (p *kafkaLogProducer) enqueue(message []byte) error {
+	if p.pending.Add(1) > int64(max(p.config.Kafka.BufferSize, 1)) {
+		p.pending.Add(-1)
+		kafkaLogDroppedEntries.Inc()
+		return fmt.Errorf("kafka log buffer is full (%d entries), dropping the entry", p.config.Kafka.BufferSize)
+	}
+	// Asynchronous writes only wait for the partitions of the topic, the first time
+	ctx, cancel := context.WithTimeout(context.Background(), kafkaLogRequestTimeout)
+	defer cancel()
+	if err := p.writer.WriteMessages(ctx, kafka.Message{Value: message}); err != nil {
+		p.pending.Add(-1)
+		kafkaLogDroppedEntries.Inc()
+		return fmt.Errorf("failed to produce log entry to kafka: %w", err)
+	}
+	return nil
+}
+
+// completed is called by the writer once a batch was delivered, or dropped after its retries
+func (p *kafkaLogProducer) completed(messages []kafka.Message, err error) {
+	p.pending.Add(-int64(len(messages)))
+	if err == nil {
+		return
+	}
+	kafkaLogDroppedEntries.Add(float64(len(messages)))
+	ctrl.Log.WithName("kafka-logging").Error(err, "Failed to deliver log entries to Kafka",
+		"topic", p.config.Kafka.Topic, "entries", len(messages), "attempts", p.config.Kafka.MaxRetries+1)
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestKafkaLogProducer tests batching log entries and producing them with SASL PLAIN authentication
+func TestKafkaLogProducer(t *testing.T) {
+	broker := newFakeKafkaBroker(t)
+	broker.saslPlain = "\x00mcall\x00secret"
+	addr := broker.listener.Addr().String()
+
+	tests := []struct {
+		name      string
+		password  string
+		wantCount int
+		wantErr   string
+	}{
+		{name: "authenticated", password: "secret", wantCount: 3},
+		{name: "wrong password", password: "wrong", wantErr: "SASL Authentication Failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LOGGING_ENABLED", "true")
+			t.Setenv("LOGGING_BACKEND", "kafka")
+			t.Setenv("LOGGING_KAFKA_ENABLED", "true")
+			t.Setenv("LOGGING_KAFKA_BROKERS", addr)
+			t.Setenv("LOGGING_KAFKA_TOPIC", "mcall-logs")
+			t.Setenv("LOGGING_KAFKA_SASL_MECHANISM", "plain")
+			t.Setenv("LOGGING_KAFKA_SASL_USERNAME", "mcall")
+			t.Setenv("LOGGING_KAFKA_SASL_PASSWORD", tt.password)
+			t.Setenv("LOGGING_KAFKA_BATCH_SIZE", "3")
+			t.Setenv("LOGGING_KAFKA_MAX_RETRIES", "0")
+			config := GetLoggingConfig()
+			t.Cleanup(stopKafkaLogProducers)
+			broker.mu.Lock()
+			broker.produced = nil
+			broker.mu.Unlock()
+
+			for _, service := range []string{"api", "db", "cache"} {
+				err := LogToBackend(LogEntry{ServiceName: service, Status: "UP", Timestamp: time.Now()}, config)
+				if tt.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+						t.Fatalf("LogToBackend() error = %v, want %q", err, tt.wantErr)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("LogToBackend() error = %v", err)
+				}
+			}
+
+			// The batch is delivered in the background
+			time.Sleep(200 * time.Millisecond)
+			broker.mu.Lock()
+			produced := broker.produced
+			broker.mu.Unlock()
+			if len(produced) != tt.wantCount {
+				t.Fatalf("produced %d records, want %d", len(produced), tt.wantCount)
+			}
+			for i, value := range produced {
+				var message map[string]interface{}
+				if err := json.Unmarshal(value, &message); err != nil || message["service_name"] != []string{"api", "db", "cache"}[i] {
+					t.Errorf("record %d = %s", i, value)
+				}
+			}
+		})
+	}
+}
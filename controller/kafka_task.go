@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+const (
+	kafkaClientID  = "mcall-operator"
+	kafkaCanaryKey = "mcall-canary"
+
+	// kafkaFetchMaxBytes bounds the records read back by the consume check
+	kafkaFetchMaxBytes = 1 << 20
+)
+
+// executeKafkaCheck produces a canary message to the topic partition and, when
+// configured, consumes it back, checking the brokers end-to-end within the timeout
+func executeKafkaCheck(brokers string, check *mcallv1.KafkaCheck, timeout time.Duration) (string, error) {
+	if check == nil || check.Topic == "" {
+		return "", fmt.Errorf("kafka task requires spec.kafka.topic")
+	}
+	var addrs []string
+	for _, addr := range strings.Split(brokers, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	if len(addrs) == 0 {
+		return "", fmt.Errorf("empty kafka bootstrap brokers")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// The transport only asks the brokers for the metadata of the checked topic, and routes
+	// produce and fetch requests to the leader of the partition
+	transport := &kafka.Transport{ClientID: kafkaClientID, DialTimeout: timeout, MetadataTopics: []string{check.Topic}}
+	defer transport.CloseIdleConnections()
+	client := &kafka.Client{Addr: kafka.TCP(addrs...), Timeout: timeout, Transport: transport}
+
+	metadata, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{check.Topic}})
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to kafka brokers %v: %w", addrs, err)
+	}
+	if err := kafkaPartitionError(metadata, check.Topic, check.Partition); err != nil {
+		return "", err
+	}
+
+	value := []byte(fmt.Sprintf("mcall-canary-%d", time.Now().UnixNano()))
+	produced, err := client.Produce(ctx, &kafka.ProduceRequest{
+		Topic:        check.Topic,
+		Partition:    int(check.Partition),
+		RequiredAcks: kafka.RequireAll,
+		Records:      kafka.NewRecordReader(kafka.Record{Key: kafka.NewBytes([]byte(kafkaCanaryKey)), Value: kafka.NewBytes(value)}),
+	})
+	if err != nil {
+		return "", err
+	}
+	if produced.Error != nil {
+		return "", fmt.Errorf("produce to %s/%d: %w", check.Topic, check.Partition, produced.Error)
+	}
+	offset := produced.BaseOffset
+	output := fmt.Sprintf("Produced canary message to %s/%d at offset %d", check.Topic, check.Partition, offset)
+	if !check.Consume {
+		return output, nil
+	}
+
+	// The message may not be readable until it is replicated, so poll until the deadline
+	for ctx.Err() == nil {
+		found, err := fetchKafkaValue(ctx, client, check, offset, value)
+		var netErr net.Error
+		if ctx.Err() != nil || errors.As(err, &netErr) && netErr.Timeout() {
+			break
+		}
+		if err != nil {
+			return output, err
+		}
+		if found {
+			return output + fmt.Sprintf("\nConsumed canary message at offset %d", offset), nil
+		}
+	}
+	return output, fmt.Errorf("canary message at offset %d was not consumed within %v", offset, timeout)
+}
+
+// kafkaPartitionError returns the error of the topic partition in the metadata, if it is missing or unavailable
+func kafkaPartitionError(metadata *kafka.MetadataResponse, topic string, partition int32) error {
+	for _, t := range metadata.Topics {
+		if t.Name != topic {
+			continue
+		}
+		if t.Error != nil {
+			return fmt.Errorf("topic %s: %w", topic, t.Error)
+		}
+		for _, p := range t.Partitions {
+			if p.ID != int(partition) {
+				continue
+			}
+			if p.Error != nil {
+				return fmt.Errorf("partition %s/%d: %w", topic, partition, p.Error)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("partition %s/%d not found", topic, partition)
+}
+
+// fetchKafkaValue reads the topic partition from the offset and reports whether the record with the value is there
+func fetchKafkaValue(ctx context.Context, client *kafka.Client, check *mcallv1.KafkaCheck, offset int64, value []byte) (bool, error) {
+	fetched, err := client.Fetch(ctx, &kafka.FetchRequest{
+		Topic:     check.Topic,
+		Partition: int(check.Partition),
+		Offset:    offset,
+		MinBytes:  1,
+		MaxBytes:  kafkaFetchMaxBytes,
+		MaxWait:   time.Second,
+	})
+	if err != nil {
+		return false, err
+	}
+	if fetched.Error != nil {
+		return false, fmt.Errorf("fetch from %s/%d: %w", check.Topic, check.Partition, fetched.Error)
+	}
+
+	for {
+		record, err := fetched.Records.ReadRecord()
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if record.Offset != offset {
+			continue
+		}
+		recordValue, err := kafka.ReadAll(record.Value)
+		if err != nil {
+			return false, err
+		}
+		return bytes.Equal(recordValue, value), nil
+	}
+}
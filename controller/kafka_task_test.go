@@ -0,0 +1,234 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+	"github.com/segmentio/kafka-go/protocol/apiversions"
+	"github.com/segmentio/kafka-go/protocol/fetch"
+	"github.com/segmentio/kafka-go/protocol/metadata"
+	"github.com/segmentio/kafka-go/protocol/produce"
+	"github.com/segmentio/kafka-go/protocol/saslauthenticate"
+	"github.com/segmentio/kafka-go/protocol/saslhandshake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// fakeKafkaBroker is a single broker leading partition 0 of the "canary" and "mcall-logs" topics.
+// Produced records are stored at offset 42 and returned by fetches.
+type fakeKafkaBroker struct {
+	listener net.Listener
+	// dropFetches: fetches only return the records before offset 42, as if the message was never replicated
+	dropFetches bool
+	// saslPlain: the PLAIN authentication bytes connections must send
+	saslPlain string
+
+	mu       sync.Mutex
+	produced [][]byte // Values of all produced records
+}
+
+func newFakeKafkaBroker(t *testing.T) *fakeKafkaBroker {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	broker := &fakeKafkaBroker{listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go broker.serve(conn)
+		}
+	}()
+	return broker
+}
+
+func (f *fakeKafkaBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	var stored [][]byte // Values of the last produced records
+	for {
+		apiVersion, correlationID, _, request, err := protocol.ReadRequest(conn)
+		if err != nil {
+			return
+		}
+
+		var response protocol.Message
+		switch request := request.(type) {
+		case *apiversions.Request:
+			response = &apiversions.Response{ApiKeys: []apiversions.ApiKeyResponse{
+				{ApiKey: int16(protocol.Produce), MaxVersion: 3},
+				{ApiKey: int16(protocol.Fetch), MaxVersion: 4},
+				{ApiKey: int16(protocol.Metadata), MaxVersion: 4},
+				{ApiKey: int16(protocol.SaslHandshake), MaxVersion: 1},
+				{ApiKey: int16(protocol.ApiVersions), MaxVersion: 0},
+				{ApiKey: int16(protocol.SaslAuthenticate), MaxVersion: 0},
+			}}
+		case *saslhandshake.Request:
+			handshake := &saslhandshake.Response{Mechanisms: []string{"PLAIN"}}
+			if request.Mechanism != "PLAIN" {
+				handshake.ErrorCode = int16(kafka.UnsupportedSASLMechanism)
+			}
+			response = handshake
+		case *saslauthenticate.Request:
+			authenticate := &saslauthenticate.Response{}
+			if string(request.AuthBytes) != f.saslPlain {
+				authenticate.ErrorCode = int16(kafka.SASLAuthenticationFailed)
+				authenticate.ErrorMessage = "Authentication failed: Invalid username or password"
+			}
+			response = authenticate
+		case *metadata.Request:
+			host, port, _ := net.SplitHostPort(f.listener.Addr().String())
+			portNumber, _ := strconv.Atoi(port)
+			topics := &metadata.Response{
+				Brokers:      []metadata.ResponseBroker{{NodeID: 1, Host: host, Port: int32(portNumber)}},
+				ControllerID: 1,
+			}
+			names := request.TopicNames
+			if names == nil {
+				names = []string{"canary", "mcall-logs"}
+			}
+			for _, topic := range names {
+				if topic != "canary" && topic != "mcall-logs" {
+					topics.Topics = append(topics.Topics, metadata.ResponseTopic{Name: topic, ErrorCode: int16(kafka.UnknownTopicOrPartition)})
+					continue
+				}
+				topics.Topics = append(topics.Topics, metadata.ResponseTopic{Name: topic, Partitions: []metadata.ResponsePartition{
+					{PartitionIndex: 0, LeaderID: 1, ReplicaNodes: []int32{1}, IsrNodes: []int32{1}},
+				}})
+			}
+			response = topics
+		case *produce.Request:
+			topic, partition := request.Topics[0].Topic, request.Topics[0].Partitions[0]
+			stored = nil
+			for {
+				record, err := partition.RecordSet.Records.ReadRecord()
+				if err != nil {
+					break
+				}
+				value, _ := kafka.ReadAll(record.Value)
+				stored = append(stored, value)
+				f.mu.Lock()
+				f.produced = append(f.produced, value)
+				f.mu.Unlock()
+			}
+			response = &produce.Response{Topics: []produce.ResponseTopic{{Topic: topic, Partitions: []produce.ResponsePartition{
+				{Partition: partition.Partition, BaseOffset: 42, LogAppendTime: -1},
+			}}}}
+		case *fetch.Request:
+			if f.dropFetches {
+				time.Sleep(100 * time.Millisecond) // max_wait_ms
+			}
+			response = &fetch.Response{Topics: []fetch.ResponseTopic{{Topic: request.Topics[0].Topic, Partitions: []fetch.ResponsePartition{
+				{HighWatermark: 43, LastStableOffset: 43, RecordSet: fakeKafkaRecordSet(stored)},
+			}}}}
+		default:
+			return
+		}
+
+		var buffer bytes.Buffer
+		if err := protocol.WriteResponse(&buffer, apiVersion, correlationID, response); err != nil {
+			return
+		}
+		if _, isFetch := response.(*fetch.Response); isFetch {
+			// kafka-go encodes record batches at base offset 0: move the batch, which ends
+			// the response, to offset 42, or before it when fetches are dropped
+			var set bytes.Buffer
+			records := fakeKafkaRecordSet(stored)
+			records.WriteTo(&set)
+			baseOffset := uint64(42)
+			if f.dropFetches {
+				baseOffset = 41
+			}
+			binary.BigEndian.PutUint64(buffer.Bytes()[buffer.Len()-set.Len()+4:], baseOffset)
+		}
+		if _, err := conn.Write(buffer.Bytes()); err != nil {
+			return
+		}
+	}
+}
+
+// fakeKafkaRecordSet returns a record batch of the values
+func fakeKafkaRecordSet(values [][]byte) protocol.RecordSet {
+	records := make([]kafka.Record, len(values))
+	for i, value := range values {
+		records[i] = kafka.Record{Value: kafka.NewBytes(value)}
+	}
+	return protocol.RecordSet{Version: 2, Records: kafka.NewRecordReader(records...)}
+}
+
+// TestExecuteKafkaCheck tests producing and consuming canary messages
+func TestExecuteKafkaCheck(t *testing.T) {
+	broker := newFakeKafkaBroker(t)
+	dropping := newFakeKafkaBroker(t)
+	dropping.dropFetches = true
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	closedAddr := closed.Addr().String()
+	closed.Close()
+
+	addr := broker.listener.Addr().String()
+	tests := []struct {
+		name       string
+		brokers    string
+		check      *mcallv1.KafkaCheck
+		wantOutput string
+		wantErr    string
+	}{
+		{
+			name:       "produce",
+			brokers:    addr,
+			check:      &mcallv1.KafkaCheck{Topic: "canary"},
+			wantOutput: "Produced canary message to canary/0 at offset 42",
+		},
+		{
+			name:       "produce and consume",
+			brokers:    closedAddr + "," + addr,
+			check:      &mcallv1.KafkaCheck{Topic: "canary", Consume: true},
+			wantOutput: "Consumed canary message at offset 42",
+		},
+		{
+			name:    "not consumed",
+			brokers: dropping.listener.Addr().String(),
+			check:   &mcallv1.KafkaCheck{Topic: "canary", Consume: true},
+			wantErr: "was not consumed",
+		},
+		{name: "unknown topic", brokers: addr, check: &mcallv1.KafkaCheck{Topic: "missing"}, wantErr: "Unknown Topic Or Partition"},
+		{name: "unknown partition", brokers: addr, check: &mcallv1.KafkaCheck{Topic: "canary", Partition: 3}, wantErr: "not found"},
+		{name: "brokers down", brokers: closedAddr, check: &mcallv1.KafkaCheck{Topic: "canary"}, wantErr: "failed to connect"},
+		{name: "no topic", brokers: addr, wantErr: "requires spec.kafka.topic"},
+		{name: "no brokers", brokers: " , ", check: &mcallv1.KafkaCheck{Topic: "canary"}, wantErr: "empty kafka bootstrap brokers"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := executeKafkaCheck(tt.brokers, tt.check, time.Second)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("executeKafkaCheck() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("executeKafkaCheck() error = %v", err)
+			}
+			if !strings.Contains(output, tt.wantOutput) {
+				t.Errorf("output = %q, want it to contain %q", output, tt.wantOutput)
+			}
+		})
+	}
+}
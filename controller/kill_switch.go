@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// killSwitchKey is the ConfigMap key listing the disabled task types (comma-separated)
+const killSwitchKey = "disabledTaskTypes"
+
+// getKillSwitchConfigMap returns the name and namespace of the kill switch ConfigMap
+func getKillSwitchConfigMap() (string, string) {
+	return os.Getenv("KILL_SWITCH_CONFIGMAP"), getEnvOrDefault("NAMESPACE", "mcall-system")
+}
+
+// getDisabledTaskTypes returns the task types disabled by the kill switch.
+// The ConfigMap is read on every call so that changes take effect without restarting the operator.
+func getDisabledTaskTypes(ctx context.Context, c client.Reader) (map[string]bool, error) {
+	name, namespace := getKillSwitchConfigMap()
+	if name == "" {
+		return nil, nil // Kill switch not configured
+	}
+
+	var configMap corev1.ConfigMap
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return parseTaskTypeList(configMap.Data[killSwitchKey]), nil
+}
+
+// parseTaskTypeList parses a comma-separated list of task types
+func parseTaskTypeList(list string) map[string]bool {
+	taskTypes := make(map[string]bool)
+	for _, taskType := range strings.Split(list, ",") {
+		taskType = strings.TrimSpace(taskType)
+		if taskType != "" {
+			taskTypes[taskType] = true
+		}
+	}
+	return taskTypes
+}
+
+// killSwitchMessage returns the message recorded on tasks blocked by the kill switch
+func killSwitchMessage(taskType string) string {
+	return fmt.Sprintf("Blocked: task type %q is disabled by the operator kill switch", taskType)
+}
+
+// killSwitchType returns the type a task or input runs as: unknown types run their input with
+// the shell, so the kill switch treats them as cmd
+func killSwitchType(taskType string) string {
+	if nonCommandTaskTypes[taskType] || taskType == "kubectl-exec" {
+		return taskType
+	}
+	return "cmd"
+}
+
+// disabledTaskType returns the first type a task runs that is disabled by the kill switch, ""
+// when none is: the type of the task and, for cmd tasks, the types of its JSON inputs
+func disabledTaskType(task *mcallv1.McallTask, disabledTypes map[string]bool) string {
+	if len(disabledTypes) == 0 {
+		return ""
+	}
+	taskType := killSwitchType(task.Spec.Type)
+	if disabledTypes[taskType] {
+		return taskType
+	}
+	if taskType != "cmd" || task.Spec.ScriptRef != nil {
+		return ""
+	}
+	inputs, _ := parseJSONInputs(task.Spec.Input)
+	for _, input := range inputs {
+		inputType, _ := input["type"].(string)
+		if inputType = killSwitchType(inputType); disabledTypes[inputType] {
+			return inputType
+		}
+	}
+	return ""
+}
+
+// blockByKillSwitch moves a task that runs a type disabled by the kill switch to Blocked and
+// reports whether it did. It is checked when the task starts and again right before it executes.
+func (r *McallTaskReconciler) blockByKillSwitch(ctx context.Context, task *mcallv1.McallTask) (bool, error) {
+	disabledTypes, err := getDisabledTaskTypes(ctx, r.Client)
+	if err != nil {
+		return false, fmt.Errorf("failed to read kill switch: %w", err)
+	}
+	taskType := disabledTaskType(task, disabledTypes)
+	if taskType == "" {
+		return false, nil
+	}
+
+	log.FromContext(ctx).Info("Task type disabled by kill switch, blocking task", "task", task.Name, "type", taskType)
+	task.Status.Phase = mcallv1.McallTaskPhaseBlocked
+	task.Status.Result = &mcallv1.McallTaskResult{
+		ErrorCode:    "-1",
+		ErrorMessage: killSwitchMessage(taskType),
+	}
+	setTaskConditions(task)
+	return true, r.Status().Update(ctx, task)
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Output encodings stored alongside persisted outputs
+const (
+	OutputEncodingNone = ""
+	OutputEncodingGzip = "gzip"
+	OutputEncodingZstd = "zstd"
+)
+
+// compressOutput compresses an output payload with the configured algorithm when it exceeds the threshold.
+// Compressed payloads are base64 encoded so they can be stored in text columns and JSON documents.
+// Returns the payload to store and its encoding.
+func compressOutput(output string, algorithm string, threshold int) (string, string, error) {
+	if output == "" || len(output) < threshold {
+		return output, OutputEncodingNone, nil
+	}
+
+	var buf bytes.Buffer
+	switch algorithm {
+	case "", "none":
+		return output, OutputEncodingNone, nil
+	case OutputEncodingGzip:
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write([]byte(output)); err != nil {
+			return "", "", fmt.Errorf("failed to gzip output: %w", err)
+		}
+		if err := writer.Close(); err != nil {
+			return "", "", fmt.Errorf("failed to gzip output: %w", err)
+		}
+	case OutputEncodingZstd:
+		encoder, err := zstd.NewWriter(nil)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to create zstd encoder: %w", err)
+		}
+		buf.Write(encoder.EncodeAll([]byte(output), nil))
+		encoder.Close()
+	default:
+		return "", "", fmt.Errorf("unsupported compression algorithm: %s", algorithm)
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), algorithm, nil
+}
+
+// DecompressOutput restores an output payload read back from a logging backend
+func DecompressOutput(payload string, encoding string) (string, error) {
+	if encoding == OutputEncodingNone {
+		return payload, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode %s output: %w", encoding, err)
+	}
+
+	switch encoding {
+	case OutputEncodingGzip:
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return "", fmt.Errorf("failed to gunzip output: %w", err)
+		}
+		defer reader.Close()
+
+		output, err := io.ReadAll(reader)
+		if err != nil {
+			return "", fmt.Errorf("failed to gunzip output: %w", err)
+		}
+		return string(output), nil
+	case OutputEncodingZstd:
+		decoder, err := zstd.NewReader(nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to create zstd decoder: %w", err)
+		}
+		defer decoder.Close()
+
+		output, err := decoder.DecodeAll(data, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to decompress zstd output: %w", err)
+		}
+		return string(output), nil
+	default:
+		return "", fmt.Errorf("unsupported output encoding: %s", encoding)
+	}
+}
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// getLoggingConfigName returns the name of the McallLoggingConfigs and the operator namespace
+func getLoggingConfigName() (string, string) {
+	return getEnvOrDefault("LOGGING_CONFIG_NAME", "default"), getEnvOrDefault("NAMESPACE", "mcall-system")
+}
+
+// resolveLoggingConfig returns the logging configuration of a task namespace: the LOGGING_*
+// environment variables, overridden by the McallLoggingConfig of the operator namespace, then
+// by the one of the task namespace. The McallLoggingConfigs are read from the informer cache,
+// so changes apply without restarting the operator.
+func resolveLoggingConfig(ctx context.Context, c client.Reader, namespace string) (LoggingConfig, error) {
+	config := GetLoggingConfig()
+	name, operatorNamespace := getLoggingConfigName()
+
+	namespaces := []string{operatorNamespace}
+	if namespace != "" && namespace != operatorNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	for _, ns := range namespaces {
+		var loggingConfig mcallv1.McallLoggingConfig
+		if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: ns}, &loggingConfig); err != nil {
+			if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				continue // Not configured, or the CRD isn't installed
+			}
+			return config, fmt.Errorf("failed to get McallLoggingConfig %s/%s: %w", ns, name, err)
+		}
+		if err := applyLoggingConfig(ctx, c, &config, &loggingConfig); err != nil {
+			return config, fmt.Errorf("McallLoggingConfig %s/%s: %w", ns, name, err)
+		}
+		if ns != operatorNamespace {
+			config.Scope = ns // Namespace backends get their own connections
+		}
+	}
+	return config, nil
+}
+
+// applyLoggingConfig overrides a logging configuration with the fields set in a McallLoggingConfig
+func applyLoggingConfig(ctx context.Context, c client.Reader, config *LoggingConfig, loggingConfig *mcallv1.McallLoggingConfig) error {
+	spec := loggingConfig.Spec
+	credentials := func(secretName string, username, password *string) error {
+		if secretName == "" {
+			return nil
+		}
+		var secret corev1.Secret
+		if err := c.Get(ctx, types.NamespacedName{Name: secretName, Namespace: loggingConfig.Namespace}, &secret); err != nil {
+			return fmt.Errorf("failed to get Secret %s: %w", secretName, err)
+		}
+		*username, *password = string(secret.Data["username"]), string(secret.Data["password"])
+		return nil
+	}
+	setString := func(target *string, value string) {
+		if value != "" {
+			*target = value
+		}
+	}
+	setBool := func(target *bool, value *bool) {
+		if value != nil {
+			*target = *value
+		}
+	}
+
+	setBool(&config.Enabled, spec.Enabled)
+	if backend := spec.PostgreSQL; backend != nil {
+		setBool(&config.PostgreSQL.Enabled, backend.Enabled)
+		setString(&config.PostgreSQL.Host, backend.Host)
+		if backend.Port != 0 {
+			config.PostgreSQL.Port = int(backend.Port)
+		}
+		setString(&config.PostgreSQL.Database, backend.Database)
+		setString(&config.PostgreSQL.SSLMode, backend.SSLMode)
+		setString(&config.PostgreSQL.Table.Name, backend.Table)
+		if err := credentials(backend.SecretName, &config.PostgreSQL.Username, &config.PostgreSQL.Password); err != nil {
+			return err
+		}
+	}
+	if backend := spec.MySQL; backend != nil {
+		setBool(&config.MySQL.Enabled, backend.Enabled)
+		setString(&config.MySQL.Host, backend.Host)
+		if backend.Port != 0 {
+			config.MySQL.Port = int(backend.Port)
+		}
+		setString(&config.MySQL.Database, backend.Database)
+		setString(&config.MySQL.Table.Name, backend.Table)
+		if err := credentials(backend.SecretName, &config.MySQL.Username, &config.MySQL.Password); err != nil {
+			return err
+		}
+	}
+	if backend := spec.Elasticsearch; backend != nil {
+		setBool(&config.Elasticsearch.Enabled, backend.Enabled)
+		setString(&config.Elasticsearch.URL, backend.URL)
+		setString(&config.Elasticsearch.Index, backend.Index)
+		if err := credentials(backend.SecretName, &config.Elasticsearch.Username, &config.Elasticsearch.Password); err != nil {
+			return err
+		}
+	}
+	if backend := spec.Kafka; backend != nil {
+		setBool(&config.Kafka.Enabled, backend.Enabled)
+		if len(backend.Brokers) > 0 {
+			config.Kafka.Brokers = backend.Brokers
+		}
+		setString(&config.Kafka.Topic, backend.Topic)
+		setString(&config.Kafka.SASL.Mechanism, backend.SASLMechanism)
+		if err := credentials(backend.SecretName, &config.Kafka.SASL.Username, &config.Kafka.SASL.Password); err != nil {
+			return err
+		}
+	}
+	if backend := spec.Loki; backend != nil {
+		setBool(&config.Loki.Enabled, backend.Enabled)
+		setString(&config.Loki.URL, backend.URL)
+		setString(&config.Loki.TenantID, backend.TenantID)
+		if err := credentials(backend.SecretName, &config.Loki.Username, &config.Loki.Password); err != nil {
+			return err
+		}
+	}
+	if backend := spec.CloudWatch; backend != nil {
+		setBool(&config.CloudWatch.Enabled, backend.Enabled)
+		setString(&config.CloudWatch.LogGroup, backend.LogGroup)
+		setString(&config.CloudWatch.LogStream, backend.LogStream)
+		setString(&config.CloudWatch.Region, backend.Region)
+	}
+	return nil
+}
+
+// loggingConfigSecretNames returns the Secrets holding the credentials of a McallLoggingConfig
+func loggingConfigSecretNames(spec mcallv1.McallLoggingConfigSpec) []string {
+	var names []string
+	if spec.PostgreSQL != nil && spec.PostgreSQL.SecretName != "" {
+		names = append(names, spec.PostgreSQL.SecretName)
+	}
+	if spec.MySQL != nil && spec.MySQL.SecretName != "" {
+		names = append(names, spec.MySQL.SecretName)
+	}
+	if spec.Elasticsearch != nil && spec.Elasticsearch.SecretName != "" {
+		names = append(names, spec.Elasticsearch.SecretName)
+	}
+	if spec.Kafka != nil && spec.Kafka.SecretName != "" {
+		names = append(names, spec.Kafka.SecretName)
+	}
+	if spec.Loki != nil && spec.Loki.SecretName != "" {
+		names = append(names, spec.Loki.SecretName)
+	}
+	return names
+}
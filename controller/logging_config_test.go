@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestResolveLoggingConfig tests overriding the environment with the operator and namespace McallLoggingConfigs
+func TestResolveLoggingConfig(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	enabled, disabled := true, false
+	operatorConfig := &mcallv1.McallLoggingConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "mcall-system"},
+		Spec: mcallv1.McallLoggingConfigSpec{
+			Enabled:       &enabled,
+			Elasticsearch: &mcallv1.ElasticsearchLoggingBackend{Enabled: &enabled, URL: "http://elasticsearch:9200"},
+		},
+	}
+	teamConfig := &mcallv1.McallLoggingConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "team-a"},
+		Spec: mcallv1.McallLoggingConfigSpec{
+			Elasticsearch: &mcallv1.ElasticsearchLoggingBackend{Index: "team-a-logs", SecretName: "es-login"},
+			Loki:          &mcallv1.LokiLoggingBackend{Enabled: &enabled, URL: "http://loki:3100", TenantID: "team-a"},
+			PostgreSQL:    &mcallv1.SQLLoggingBackend{Enabled: &disabled},
+		},
+	}
+	brokenConfig := &mcallv1.McallLoggingConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "team-b"},
+		Spec:       mcallv1.McallLoggingConfigSpec{Loki: &mcallv1.LokiLoggingBackend{SecretName: "missing"}},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "es-login", Namespace: "team-a"},
+		Data:       map[string][]byte{"username": []byte("team-a"), "password": []byte("s3cret")},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(operatorConfig, teamConfig, brokenConfig, secret).Build()
+
+	t.Setenv("NAMESPACE", "mcall-system")
+	t.Setenv("LOGGING_ENABLED", "false")
+	t.Setenv("LOGGING_POSTGRESQL_ENABLED", "true")
+	t.Setenv("LOGGING_ELASTICSEARCH_INDEX", "mcall-logs")
+	ctx := context.Background()
+
+	config, err := resolveLoggingConfig(ctx, c, "default")
+	if err != nil {
+		t.Fatalf("resolveLoggingConfig() error = %v", err)
+	}
+	if !config.Enabled || config.Scope != "" || config.Elasticsearch.URL != "http://elasticsearch:9200" || config.Elasticsearch.Index != "mcall-logs" {
+		t.Errorf("operator config = enabled %v, scope %q, elasticsearch %+v", config.Enabled, config.Scope, config.Elasticsearch)
+	}
+	if names := strings.Join(enabledLoggingBackends(config), ","); names != "postgres,elasticsearch" {
+		t.Errorf("operator backends = %s", names)
+	}
+
+	config, err = resolveLoggingConfig(ctx, c, "team-a")
+	if err != nil {
+		t.Fatalf("resolveLoggingConfig() error = %v", err)
+	}
+	if config.Scope != "team-a" || config.Elasticsearch.Index != "team-a-logs" ||
+		config.Elasticsearch.Username != "team-a" || config.Elasticsearch.Password != "s3cret" || config.Loki.TenantID != "team-a" {
+		t.Errorf("namespace config = scope %q, elasticsearch %+v, loki %+v", config.Scope, config.Elasticsearch, config.Loki)
+	}
+	if names := strings.Join(enabledLoggingBackends(config), ","); names != "elasticsearch,loki" {
+		t.Errorf("namespace backends = %s", names)
+	}
+
+	if _, err := resolveLoggingConfig(ctx, c, "team-b"); err == nil || !strings.Contains(err.Error(), "failed to get Secret missing") {
+		t.Errorf("resolveLoggingConfig() error = %v, want the missing Secret", err)
+	}
+}
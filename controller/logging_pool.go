@@ -0,0 +1,229 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// loggingBackendPinger is implemented by logging backends whose connection can be health checked
+type loggingBackendPinger interface {
+	Ping() error
+}
+
+// loggingBackendPool keeps the connected logging backends so entries reuse their connections.
+// Backends are connected on first use and dropped when their health check fails, so the next
+// entry reconnects.
+type loggingBackendPool struct {
+	mu       sync.Mutex
+	config   LoggingConfig
+	backends map[string]LoggingBackend // Connected backends by name
+}
+
+var sharedLoggingPool = &loggingBackendPool{backends: make(map[string]LoggingBackend)}
+
+// Pools of the namespaces overriding the operator logging configuration with a McallLoggingConfig,
+// so their backends don't reset the operator pool
+var (
+	namespaceLoggingPoolsMu sync.Mutex
+	namespaceLoggingPools   = make(map[string]*loggingBackendPool)
+)
+
+// loggingPoolFor returns the pool of the backends of a logging configuration
+func loggingPoolFor(config LoggingConfig) *loggingBackendPool {
+	if config.Scope == "" {
+		return sharedLoggingPool
+	}
+	namespaceLoggingPoolsMu.Lock()
+	defer namespaceLoggingPoolsMu.Unlock()
+	pool, exists := namespaceLoggingPools[config.Scope]
+	if !exists {
+		pool = &loggingBackendPool{backends: make(map[string]LoggingBackend)}
+		namespaceLoggingPools[config.Scope] = pool
+	}
+	return pool
+}
+
+// get returns the connected backend, connecting it when it isn't pooled yet. A configuration
+// change closes the pooled backends first.
+func (p *loggingBackendPool) get(config LoggingConfig, name string) (LoggingBackend, error) {
+	p.mu.Lock()
+	if !reflect.DeepEqual(config, p.config) {
+		p.closeAll()
+		p.config = config
+	}
+	if backend, exists := p.backends[name]; exists {
+		p.mu.Unlock()
+		return backend, nil
+	}
+	p.mu.Unlock()
+
+	// Connect without holding the lock, so an unreachable backend doesn't block the others
+	backendConfig := config
+	backendConfig.Backend = name
+	backend, err := CreateLoggingBackend(backendConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := backend.Connect(); err != nil {
+		backend.Close()
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if pooled, exists := p.backends[name]; exists {
+		// Another entry connected it meanwhile
+		backend.Close()
+		return pooled, nil
+	}
+	if !reflect.DeepEqual(config, p.config) {
+		backend.Close()
+		return nil, fmt.Errorf("logging configuration changed while connecting")
+	}
+	p.backends[name] = backend
+	return backend, nil
+}
+
+// reload replaces the configuration of the pool, closing the backends and reconnecting the
+// enabled ones when it changed. It reports whether the configuration changed.
+func (p *loggingBackendPool) reload(config LoggingConfig) bool {
+	p.mu.Lock()
+	changed := !reflect.DeepEqual(config, p.config)
+	if changed {
+		p.closeAll()
+		p.config = config
+	}
+	p.mu.Unlock()
+
+	if changed && config.Enabled {
+		p.checkHealth(config)
+	}
+	return changed
+}
+
+// check health checks a backend after a failure and drops it when it's unhealthy.
+// Backends that can't be health checked are always dropped.
+func (p *loggingBackendPool) check(name string, backend LoggingBackend) {
+	if pinger, ok := backend.(loggingBackendPinger); ok && pinger.Ping() == nil {
+		return
+	}
+	p.drop(name, backend)
+}
+
+// drop removes a backend from the pool and closes it
+func (p *loggingBackendPool) drop(name string, backend LoggingBackend) {
+	p.mu.Lock()
+	if p.backends[name] == backend {
+		delete(p.backends, name)
+	}
+	p.mu.Unlock()
+	backend.Close()
+}
+
+// checkHealth pings the pooled backends, dropping unhealthy ones, and connects the enabled
+// backends that aren't pooled
+func (p *loggingBackendPool) checkHealth(config LoggingConfig) {
+	logger := ctrl.Log.WithName("logging")
+
+	p.mu.Lock()
+	pooled := make(map[string]LoggingBackend, len(p.backends))
+	for name, backend := range p.backends {
+		pooled[name] = backend
+	}
+	p.mu.Unlock()
+
+	for name, backend := range pooled {
+		if pinger, ok := backend.(loggingBackendPinger); ok {
+			if err := pinger.Ping(); err != nil {
+				logger.Error(err, "Logging backend health check failed, reconnecting", "backend", name)
+				p.drop(name, backend)
+			}
+		}
+	}
+	for _, name := range enabledLoggingBackends(config) {
+		if _, err := p.get(config, name); err != nil {
+			logger.Error(err, "Failed to connect to logging backend", "backend", name)
+		}
+	}
+}
+
+// closeAll closes the pooled backends; the caller holds the lock
+func (p *loggingBackendPool) closeAll() {
+	for name, backend := range p.backends {
+		backend.Close()
+		delete(p.backends, name)
+	}
+}
+
+// namespacePools returns the pools of the namespace logging configurations
+func namespacePools() []*loggingBackendPool {
+	namespaceLoggingPoolsMu.Lock()
+	defer namespaceLoggingPoolsMu.Unlock()
+	pools := make([]*loggingBackendPool, 0, len(namespaceLoggingPools))
+	for _, pool := range namespaceLoggingPools {
+		pools = append(pools, pool)
+	}
+	return pools
+}
+
+// RunLoggingBackends returns the runnable connecting the enabled logging backends at controller
+// start and health checking them every LOGGING_HEALTH_CHECK_INTERVAL_SECONDS until the context is
+// done, then closing them. It returns an error, stopping the manager, when the controller lacks
+// the privileges to auto-create a log table.
+func RunLoggingBackends(c client.Reader) manager.RunnableFunc {
+	return func(ctx context.Context) error {
+		logger := ctrl.Log.WithName("logging")
+		operatorConfig := func() LoggingConfig {
+			config, err := resolveLoggingConfig(ctx, c, "")
+			if err != nil {
+				logger.Error(err, "Failed to resolve the logging configuration, using the environment")
+				return GetLoggingConfig()
+			}
+			return config
+		}
+
+		// Fail fast when a log table can't be created, rather than failing every entry
+		config := operatorConfig()
+		if config.Enabled {
+			for _, name := range enabledLoggingBackends(config) {
+				if _, err := sharedLoggingPool.get(config, name); errors.Is(err, errLogTablePermission) {
+					return fmt.Errorf("logging backend %s: %w", name, err)
+				}
+			}
+			sharedLoggingPool.checkHealth(config)
+		}
+
+		ticker := time.NewTicker(config.Pool.HealthCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				for _, pool := range append(namespacePools(), sharedLoggingPool) {
+					pool.mu.Lock()
+					pool.closeAll()
+					pool.mu.Unlock()
+				}
+				return nil
+			case <-ticker.C:
+				if config := operatorConfig(); config.Enabled {
+					sharedLoggingPool.checkHealth(config)
+				}
+				// Namespace pools are checked with the configuration they were last used with
+				for _, pool := range namespacePools() {
+					pool.mu.Lock()
+					config := pool.config
+					pool.mu.Unlock()
+					pool.checkHealth(config)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestLoggingBackendPool tests reusing backend connections and reconnecting after a failed health check
+func TestLoggingBackendPool(t *testing.T) {
+	var pings, indexed atomic.Int32
+	var healthy atomic.Bool
+	healthy.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet:
+			pings.Add(1)
+		case r.URL.Path == "/_bulk":
+			body, _ := io.ReadAll(r.Body)
+			indexed.Add(int32(bytes.Count(body, []byte("\n")) / 2))
+			w.Write([]byte(`{"errors":false,"items":[]}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Setenv("LOGGING_ENABLED", "true")
+	t.Setenv("LOGGING_ELASTICSEARCH_ENABLED", "true")
+	t.Setenv("LOGGING_ELASTICSEARCH_URL", server.URL)
+	t.Setenv("LOGGING_ELASTICSEARCH_BATCH_TIMEOUT_MS", "10")
+	config := GetLoggingConfig()
+	waitIndexed := func(want int32) {
+		deadline := time.Now().Add(5 * time.Second)
+		for indexed.Load() < want && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	entry := LogEntry{ServiceName: "api", Status: "UP", Timestamp: time.Now()}
+
+	for i := 0; i < 3; i++ {
+		if err := LogToBackend(entry, config); err != nil {
+			t.Fatalf("LogToBackend() error = %v", err)
+		}
+	}
+	waitIndexed(3)
+	if pings.Load() != 1 || indexed.Load() != 3 {
+		t.Fatalf("pings = %d, indexed = %d, want one connection for 3 entries", pings.Load(), indexed.Load())
+	}
+
+	// An unhealthy backend is dropped, and reconnected once it's back
+	healthy.Store(false)
+	sharedLoggingPool.checkHealth(config)
+	if _, pooled := sharedLoggingPool.backends["elasticsearch"]; pooled {
+		t.Fatalf("unhealthy backend is still pooled")
+	}
+	if err := LogToBackend(entry, config); err == nil {
+		t.Errorf("LogToBackend() succeeded with Elasticsearch down")
+	}
+
+	healthy.Store(true)
+	sharedLoggingPool.checkHealth(config)
+	if err := LogToBackend(entry, config); err != nil {
+		t.Fatalf("LogToBackend() error = %v", err)
+	}
+	waitIndexed(4)
+	if pings.Load() != 2 || indexed.Load() != 4 {
+		t.Errorf("pings = %d, indexed = %d, want a reconnection", pings.Load(), indexed.Load())
+	}
+}
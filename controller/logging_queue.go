@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	loggingQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "mcall_logging_queue_depth",
+		Help: "Number of log entries waiting to be logged to the backends",
+	})
+	loggingDroppedEntries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcall_logging_dropped_entries_total",
+		Help: "Number of log entries dropped because the queue was full or the backends kept failing",
+	}, []string{"reason"})
+
+	sharedLoggingQueue   *loggingQueue
+	sharedLoggingQueueMu sync.Mutex
+)
+
+func init() {
+	metrics.Registry.MustRegister(loggingQueueDepth, loggingDroppedEntries)
+}
+
+// queuedLogEntry is a log entry waiting in the logging queue
+type queuedLogEntry struct {
+	entry    LogEntry
+	config   LoggingConfig
+	backends []string // Backends the entry still has to be logged to
+	attempt  int
+}
+
+// loggingQueue logs entries to the backends in batches from a background worker,
+// retrying the failed backends of an entry with exponential backoff
+type loggingQueue struct {
+	config  LoggingConfig
+	entries chan queuedLogEntry
+	done    chan struct{} // Closed when the queue was replaced by a reload
+}
+
+// enqueueLogEntry adds an entry to the controller-wide logging queue, starting it on first use
+func enqueueLogEntry(item queuedLogEntry) {
+	sharedLoggingQueueMu.Lock()
+	defer sharedLoggingQueueMu.Unlock()
+	if sharedLoggingQueue == nil {
+		sharedLoggingQueue = newLoggingQueue(item.config)
+	}
+	sharedLoggingQueue.enqueue(item)
+}
+
+// reloadLoggingQueue replaces the controller-wide logging queue when its settings changed, so a
+// reload of the logging configuration resizes it. The replaced queue logs the entries it holds
+// and stops; its retries go to the new queue. It reports whether the queue was replaced.
+func reloadLoggingQueue(config LoggingConfig) bool {
+	sharedLoggingQueueMu.Lock()
+	defer sharedLoggingQueueMu.Unlock()
+	if sharedLoggingQueue == nil || sharedLoggingQueue.config.Queue == config.Queue {
+		return false
+	}
+	close(sharedLoggingQueue.done)
+	sharedLoggingQueue = newLoggingQueue(config)
+	return true
+}
+
+// newLoggingQueue starts a logging queue with the queue settings of the configuration
+func newLoggingQueue(config LoggingConfig) *loggingQueue {
+	q := &loggingQueue{
+		config:  config,
+		entries: make(chan queuedLogEntry, max(config.Queue.Size, 1)),
+		done:    make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// EnqueueLog queues the task execution result to be logged to every enabled backend in the
+// background, so a slow backend doesn't slow reconciliation down
+func EnqueueLog(logEntry LogEntry, config LoggingConfig) error {
+	if !config.Enabled {
+		return nil // Logging disabled
+	}
+
+	logEntry, err := prepareLogEntry(logEntry, config)
+	if err != nil {
+		return err
+	}
+	backends := enabledLoggingBackends(config)
+	if len(backends) == 0 {
+		return nil
+	}
+	enqueueLogEntry(queuedLogEntry{entry: logEntry, config: config, backends: backends})
+	return nil
+}
+
+// enqueue adds an entry to the queue, dropping it when the queue is full
+func (q *loggingQueue) enqueue(item queuedLogEntry) {
+	select {
+	case q.entries <- item:
+		loggingQueueDepth.Inc()
+	default:
+		loggingDroppedEntries.WithLabelValues("buffer_full").Inc()
+		ctrl.Log.WithName("logging").Info("Logging queue is full, dropping entry",
+			"service", item.entry.ServiceName, "queueSize", cap(q.entries))
+	}
+}
+
+// requeue queues a retried entry again, on the queue that replaced this one after a reload
+func (q *loggingQueue) requeue(item queuedLogEntry) {
+	sharedLoggingQueueMu.Lock()
+	defer sharedLoggingQueueMu.Unlock()
+	select {
+	case <-q.done:
+		sharedLoggingQueue.enqueue(item)
+	default:
+		q.enqueue(item)
+	}
+}
+
+// run logs a batch when it reaches the batch size or the flush interval elapses, until the
+// queue is replaced
+func (q *loggingQueue) run() {
+	ticker := time.NewTicker(max(q.config.Queue.FlushInterval, 10*time.Millisecond))
+	defer ticker.Stop()
+
+	var batch []queuedLogEntry
+	for {
+		select {
+		case item := <-q.entries:
+			loggingQueueDepth.Dec()
+			batch = append(batch, item)
+			if len(batch) < q.config.Queue.BatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		case <-q.done:
+			// No entry is added once the queue was replaced, log the remaining ones
+			for len(q.entries) > 0 {
+				loggingQueueDepth.Dec()
+				batch = append(batch, <-q.entries)
+			}
+			q.flush(batch)
+			return
+		}
+		q.flush(batch)
+		batch = nil
+	}
+}
+
+// flush logs a batch of entries concurrently. Entries with failed backends are queued again
+// after their backoff, without blocking the worker, or dropped after the last retry.
+func (q *loggingQueue) flush(batch []queuedLogEntry) {
+	logger := ctrl.Log.WithName("logging")
+
+	var wg sync.WaitGroup
+	for _, item := range batch {
+		wg.Add(1)
+		go func(item queuedLogEntry) {
+			defer wg.Done()
+			failed, err := logToBackends(item.entry, item.config, item.backends)
+			if err == nil {
+				return
+			}
+			if item.attempt >= q.config.Queue.MaxRetries {
+				loggingDroppedEntries.WithLabelValues("delivery_failed").Inc()
+				logger.Error(err, "Failed to log entry, dropping it", "service", item.entry.ServiceName,
+					"backends", failed, "attempts", item.attempt+1)
+				return
+			}
+
+			item.attempt++
+			item.backends = failed
+			backoff := q.config.Queue.RetryBackoff * time.Duration(1<<(item.attempt-1))
+			logger.Info("Failed to log entry, retrying", "service", item.entry.ServiceName,
+				"backends", failed, "retryIn", backoff.String(), "error", err.Error())
+			time.AfterFunc(backoff, func() { q.requeue(item) })
+		}(item)
+	}
+	wg.Wait()
+}
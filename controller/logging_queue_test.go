@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestLoggingQueue tests that queued entries are retried after failures, dropped when the
+// queue is full and still logged when a reload resizes the queue
+func TestLoggingQueue(t *testing.T) {
+	var requests, indexed atomic.Int32
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail the first request so the entry is retried
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		indexed.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer loki.Close()
+
+	t.Setenv("LOGGING_ENABLED", "true")
+	t.Setenv("LOGGING_BACKEND", "loki")
+	t.Setenv("LOGGING_LOKI_ENABLED", "true")
+	t.Setenv("LOGGING_LOKI_URL", loki.URL)
+	t.Setenv("LOGGING_QUEUE_SIZE", "1")
+	t.Setenv("LOGGING_QUEUE_BATCH_SIZE", "1")
+	t.Setenv("LOGGING_QUEUE_FLUSH_INTERVAL_MS", "10")
+	t.Setenv("LOGGING_QUEUE_RETRY_BACKOFF_MS", "10")
+	config := GetLoggingConfig()
+
+	t.Run("retry", func(t *testing.T) {
+		q := newLoggingQueue(config)
+		q.enqueue(queuedLogEntry{entry: LogEntry{ServiceName: "api", Status: "UP", Timestamp: time.Now()},
+			config: config, backends: enabledLoggingBackends(config)})
+
+		deadline := time.Now().Add(5 * time.Second)
+		for indexed.Load() == 0 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if requests.Load() != 2 || indexed.Load() != 1 {
+			t.Errorf("got %d requests and %d pushed entries, want 2 and 1", requests.Load(), indexed.Load())
+		}
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		defer func() { sharedLoggingQueue = nil }()
+
+		// A queue holding an entry when the reload resizes it
+		old := &loggingQueue{config: config, entries: make(chan queuedLogEntry, 1), done: make(chan struct{})}
+		old.enqueue(queuedLogEntry{entry: LogEntry{ServiceName: "held", Status: "UP", Timestamp: time.Now()},
+			config: config, backends: enabledLoggingBackends(config)})
+		sharedLoggingQueue = old
+
+		if reloadLoggingQueue(config) {
+			t.Fatal("reloadLoggingQueue() replaced the queue without a change of its settings")
+		}
+		resized := config
+		resized.Queue.Size = 5
+		if !reloadLoggingQueue(resized) {
+			t.Fatal("reloadLoggingQueue() kept the queue after its size changed")
+		}
+		if sharedLoggingQueue == old || cap(sharedLoggingQueue.entries) != 5 {
+			t.Errorf("queue size = %d, want a new queue of 5", cap(sharedLoggingQueue.entries))
+		}
+
+		// The replaced queue logs the entry it holds and stops
+		stopped := make(chan struct{})
+		go func() {
+			old.run()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(5 * time.Second):
+			t.Fatal("replaced queue didn't stop")
+		}
+		if indexed.Load() != 2 {
+			t.Errorf("got %d pushed entries, want 2", indexed.Load())
+		}
+	})
+
+	t.Run("buffer_full", func(t *testing.T) {
+		// A queue without a worker stays full after the first entry
+		q := &loggingQueue{config: config, entries: make(chan queuedLogEntry, 1)}
+		dropped := testutil.ToFloat64(loggingDroppedEntries.WithLabelValues("buffer_full"))
+		q.enqueue(queuedLogEntry{entry: LogEntry{ServiceName: "first"}})
+		q.enqueue(queuedLogEntry{entry: LogEntry{ServiceName: "second"}})
+
+		if len(q.entries) != 1 {
+			t.Errorf("queue holds %d entries, want 1", len(q.entries))
+		}
+		if got := testutil.ToFloat64(loggingDroppedEntries.WithLabelValues("buffer_full")) - dropped; got != 1 {
+			t.Errorf("dropped %v entries, want 1", got)
+		}
+	})
+}
@@ -0,0 +1,199 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// loggingSettings holds the LOGGING_* keys of the watched logging ConfigMap and Secret. They take
+// precedence over the environment, which only holds their values at controller start.
+var loggingSettings atomic.Pointer[map[string]string]
+
+// loggingGetenv returns a logging setting from the watched ConfigMap and Secret, or the environment
+func loggingGetenv(key string) string {
+	if settings := loggingSettings.Load(); settings != nil {
+		if value, exists := (*settings)[key]; exists {
+			return value
+		}
+	}
+	return os.Getenv(key)
+}
+
+// loggingEnvOrDefault returns a logging setting or a default value
+func loggingEnvOrDefault(key, defaultValue string) string {
+	if value := loggingGetenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// loggingEnvIntOrDefault returns a logging setting as int or a default value
+func loggingEnvIntOrDefault(key string, defaultValue int) int {
+	if value := loggingGetenv(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+// getLoggingSettingsObjects returns the names of the logging ConfigMap and Secret in the operator namespace
+func getLoggingSettingsObjects() (string, string) {
+	return os.Getenv("LOGGING_CONFIGMAP"), os.Getenv("LOGGING_SECRET")
+}
+
+// LoggingConfigReconciler reloads the logging configuration when the logging ConfigMap or
+// Secret or a McallLoggingConfig changes, and reconnects the backends whose configuration
+// changed, so backends can be switched and passwords rotated without restarting the controller
+type LoggingConfigReconciler struct {
+	client.Client
+}
+
+// loggingReloadRequest is the single request every watched change is mapped to
+func loggingReloadRequest() reconcile.Request {
+	name, namespace := getLoggingConfigName()
+	return reconcile.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}}
+}
+
+func (r *LoggingConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if err := r.loadLoggingSettings(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	config, err := resolveLoggingConfig(ctx, r, "")
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	changed := sharedLoggingPool.reload(config)
+	if reloadLoggingQueue(config) {
+		logger.Info("Resized the logging queue", "size", config.Queue.Size, "batchSize", config.Queue.BatchSize,
+			"flushInterval", config.Queue.FlushInterval.String())
+	}
+
+	// Namespace pools are reloaded with their McallLoggingConfig, or closed once it's deleted
+	namespaceLoggingPoolsMu.Lock()
+	namespaces := make(map[string]*loggingBackendPool, len(namespaceLoggingPools))
+	for namespace, pool := range namespaceLoggingPools {
+		namespaces[namespace] = pool
+	}
+	namespaceLoggingPoolsMu.Unlock()
+	for namespace, pool := range namespaces {
+		config, err := resolveLoggingConfig(ctx, r, namespace)
+		if err != nil {
+			logger.Error(err, "Failed to reload the namespace logging configuration", "namespace", namespace)
+			continue
+		}
+		if config.Scope == "" {
+			namespaceLoggingPoolsMu.Lock()
+			delete(namespaceLoggingPools, namespace)
+			namespaceLoggingPoolsMu.Unlock()
+			pool.reload(LoggingConfig{})
+			changed = true
+			continue
+		}
+		changed = pool.reload(config) || changed
+	}
+
+	if changed {
+		// Producers and bulk indexers are rebuilt with the new settings when the backends reconnect
+		stopKafkaLogProducers()
+		stopElasticsearchBulkIndexers()
+		logger.Info("Reloaded the logging configuration", "enabled", config.Enabled, "backends", enabledLoggingBackends(config))
+	}
+	return ctrl.Result{}, nil
+}
+
+// loadLoggingSettings reads the LOGGING_* keys of the logging ConfigMap and Secret
+func (r *LoggingConfigReconciler) loadLoggingSettings(ctx context.Context) error {
+	configMapName, secretName := getLoggingSettingsObjects()
+	_, namespace := getLoggingConfigName()
+	settings := make(map[string]string)
+
+	if configMapName != "" {
+		var configMap corev1.ConfigMap
+		err := r.Get(ctx, types.NamespacedName{Name: configMapName, Namespace: namespace}, &configMap)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		for key, value := range configMap.Data {
+			if strings.HasPrefix(key, "LOGGING_") {
+				settings[key] = value
+			}
+		}
+	}
+	if secretName != "" {
+		var secret corev1.Secret
+		err := r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: namespace}, &secret)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		for key, value := range secret.Data {
+			if strings.HasPrefix(key, "LOGGING_") {
+				settings[key] = string(value)
+			}
+		}
+	}
+
+	loggingSettings.Store(&settings)
+	return nil
+}
+
+// loggingSecretReferenced reports whether a Secret holds credentials of a McallLoggingConfig
+func (r *LoggingConfigReconciler) loggingSecretReferenced(ctx context.Context, secret client.Object) bool {
+	var loggingConfigs mcallv1.McallLoggingConfigList
+	if err := r.List(ctx, &loggingConfigs, client.InNamespace(secret.GetNamespace())); err != nil {
+		return false
+	}
+	for _, loggingConfig := range loggingConfigs.Items {
+		for _, name := range loggingConfigSecretNames(loggingConfig.Spec) {
+			if name == secret.GetName() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *LoggingConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	_, namespace := getLoggingConfigName()
+	configMapName, secretName := getLoggingSettingsObjects()
+	reload := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{loggingReloadRequest()}
+	})
+	// Secrets reload the configuration when they are the logging Secret or hold McallLoggingConfig credentials
+	reloadSecret := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, secret client.Object) []reconcile.Request {
+		if (secret.GetName() == secretName && secret.GetNamespace() == namespace) || r.loggingSecretReferenced(ctx, secret) {
+			return []reconcile.Request{loggingReloadRequest()}
+		}
+		return nil
+	})
+	configMap := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return configMapName != "" && object.GetName() == configMapName && object.GetNamespace() == namespace
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("logging-config").
+		Watches(&mcallv1.McallLoggingConfig{}, reload).
+		Watches(&corev1.ConfigMap{}, reload, builder.WithPredicates(configMap)).
+		Watches(&corev1.Secret{}, reloadSecret).
+		Complete(r)
+}
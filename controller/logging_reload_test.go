@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestLoggingConfigReload tests switching backends and rotating passwords through the logging ConfigMap and Secret
+func TestLoggingConfigReload(t *testing.T) {
+	var mu sync.Mutex
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorization = r.Header.Get("Authorization")
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "logging-config", Namespace: "mcall-system"},
+		Data: map[string]string{
+			"LOGGING_ENABLED":                "true",
+			"LOGGING_ELASTICSEARCH_ENABLED":  "true",
+			"LOGGING_ELASTICSEARCH_URL":      server.URL,
+			"LOGGING_ELASTICSEARCH_USERNAME": "mcall",
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "logging-secret", Namespace: "mcall-system"},
+		Data:       map[string][]byte{"LOGGING_ELASTICSEARCH_PASSWORD": []byte("old")},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap, secret).Build()
+	r := &LoggingConfigReconciler{Client: c}
+
+	t.Setenv("NAMESPACE", "mcall-system")
+	t.Setenv("LOGGING_ENABLED", "false")
+	t.Setenv("LOGGING_CONFIGMAP", "logging-config")
+	t.Setenv("LOGGING_SECRET", "logging-secret")
+	t.Cleanup(func() {
+		loggingSettings.Store(nil)
+		sharedLoggingPool.reload(LoggingConfig{})
+	})
+	ctx := context.Background()
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	config := GetLoggingConfig()
+	if !config.Enabled || config.Elasticsearch.Password != "old" {
+		t.Fatalf("reloaded config = enabled %v, elasticsearch %+v", config.Enabled, config.Elasticsearch)
+	}
+	if _, pooled := sharedLoggingPool.backends["elasticsearch"]; !pooled {
+		t.Fatalf("elasticsearch backend wasn't connected on reload")
+	}
+
+	// A rotated password reconnects the backend with it
+	secret.Data["LOGGING_ELASTICSEARCH_PASSWORD"] = []byte("new")
+	if err := c.Update(ctx, secret); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if err := LogToBackend(LogEntry{ServiceName: "api", Status: "UP"}, GetLoggingConfig()); err != nil {
+		t.Fatalf("LogToBackend() error = %v", err)
+	}
+	mu.Lock()
+	if want := "Basic bWNhbGw6bmV3"; authorization != want { // mcall:new
+		t.Errorf("Authorization = %q, want %q", authorization, want)
+	}
+	mu.Unlock()
+
+	// Switching to Loki closes the Elasticsearch backend
+	configMap.Data["LOGGING_ELASTICSEARCH_ENABLED"] = "false"
+	configMap.Data["LOGGING_LOKI_ENABLED"] = "true"
+	configMap.Data["LOGGING_LOKI_URL"] = server.URL
+	if err := c.Update(ctx, configMap); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if _, pooled := sharedLoggingPool.backends["elasticsearch"]; pooled {
+		t.Errorf("elasticsearch backend is still pooled after switching to Loki")
+	}
+	if _, pooled := sharedLoggingPool.backends["loki"]; !pooled {
+		t.Errorf("loki backend wasn't connected on reload")
+	}
+}
@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// logRetentionBatchSize bounds the entries deleted per statement, so the cleanup doesn't lock
+// the log table for long
+const logRetentionBatchSize = 10000
+
+var loggingRetentionDeleted = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "mcall_logging_retention_deleted_entries_total",
+	Help: "Number of log entries deleted from the SQL backends after their retention period",
+}, []string{"backend"})
+
+func init() {
+	metrics.Registry.MustRegister(loggingRetentionDeleted)
+}
+
+// postgresLogRetentionStatement deletes a batch of entries older than $1
+func postgresLogRetentionStatement(table string, batchSize int) string {
+	return fmt.Sprintf("DELETE FROM %s WHERE id IN (SELECT id FROM %s WHERE timestamp < $1 LIMIT %d)", table, table, batchSize)
+}
+
+// mysqlLogRetentionStatement deletes a batch of entries older than ?
+func mysqlLogRetentionStatement(table string, batchSize int) string {
+	return fmt.Sprintf("DELETE FROM %s WHERE timestamp < ? LIMIT %d", table, batchSize)
+}
+
+// deleteExpiredLogs runs a retention statement until it deletes less than a batch, and returns
+// the number of deleted entries
+func deleteExpiredLogs(ctx context.Context, db *sql.DB, statement string, cutoff time.Time, batchSize int) (int64, error) {
+	var deleted int64
+	for {
+		result, err := db.ExecContext(ctx, statement, cutoff)
+		if err != nil {
+			return deleted, err
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return deleted, err
+		}
+		deleted += rows
+		if rows < int64(batchSize) {
+			return deleted, nil
+		}
+	}
+}
+
+// cleanupLogTables deletes the entries of the SQL backends older than their retention period
+func cleanupLogTables(ctx context.Context, config LoggingConfig) {
+	logger := ctrl.Log.WithName("logging")
+	now := time.Now()
+
+	for _, name := range enabledLoggingBackends(config) {
+		var (
+			db        *sql.DB
+			statement string
+			days      int
+		)
+		switch name {
+		case "postgres":
+			if !config.PostgreSQL.Retention.AutoCleanup || config.PostgreSQL.Retention.Days <= 0 {
+				continue
+			}
+			backend, err := sharedLoggingPool.get(config, name)
+			if err != nil {
+				logger.Error(err, "Failed to connect to logging backend for retention cleanup", "backend", name)
+				continue
+			}
+			db = backend.(*PostgreSQLBackend).db
+			statement = postgresLogRetentionStatement(config.PostgreSQL.Table.Name, logRetentionBatchSize)
+			days = config.PostgreSQL.Retention.Days
+		case "mysql":
+			if !config.MySQL.Retention.AutoCleanup || config.MySQL.Retention.Days <= 0 {
+				continue
+			}
+			backend, err := sharedLoggingPool.get(config, name)
+			if err != nil {
+				logger.Error(err, "Failed to connect to logging backend for retention cleanup", "backend", name)
+				continue
+			}
+			db = backend.(*MySQLBackend).db
+			statement = mysqlLogRetentionStatement(config.MySQL.Table.Name, logRetentionBatchSize)
+			days = config.MySQL.Retention.Days
+		default:
+			continue
+		}
+
+		cutoff := now.AddDate(0, 0, -days)
+		deleted, err := deleteExpiredLogs(ctx, db, statement, cutoff, logRetentionBatchSize)
+		loggingRetentionDeleted.WithLabelValues(name).Add(float64(deleted))
+		if err != nil {
+			logger.Error(err, "Failed to delete expired log entries", "backend", name, "deleted", deleted)
+			continue
+		}
+		if deleted > 0 {
+			logger.Info("Deleted expired log entries", "backend", name, "deleted", deleted, "before", cutoff)
+		}
+	}
+}
+
+// RunLogRetention returns the runnable deleting the entries of the PostgreSQL and MySQL backends
+// older than LOGGING_<BACKEND>_RETENTION_DAYS every LOGGING_RETENTION_INTERVAL_MINUTES, for the
+// backends with LOGGING_<BACKEND>_RETENTION_AUTO_CLEANUP set. It only runs on the leader.
+func RunLogRetention(c client.Reader) manager.RunnableFunc {
+	return func(ctx context.Context) error {
+		logger := ctrl.Log.WithName("logging")
+		operatorConfig := func() LoggingConfig {
+			config, err := resolveLoggingConfig(ctx, c, "")
+			if err != nil {
+				logger.Error(err, "Failed to resolve the logging configuration, using the environment")
+				return GetLoggingConfig()
+			}
+			return config
+		}
+
+		config := operatorConfig()
+		timer := time.NewTimer(config.RetentionInterval)
+		defer timer.Stop()
+		for {
+			if config.Enabled {
+				cleanupLogTables(ctx, config)
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-timer.C:
+				config = operatorConfig()
+				timer.Reset(config.RetentionInterval)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestDeleteExpiredLogs tests deleting expired log entries in batches
+func TestDeleteExpiredLogs(t *testing.T) {
+	tests := []struct {
+		name           string
+		statement      string
+		affected       []int64
+		err            error
+		wantDeleted    int64
+		wantStatements int
+		wantErr        bool
+	}{
+		{
+			name:           "postgres",
+			statement:      postgresLogRetentionStatement("monitoring_logs", 100),
+			affected:       []int64{100, 100, 42},
+			wantDeleted:    242,
+			wantStatements: 3,
+		},
+		{
+			name:           "mysql",
+			statement:      mysqlLogRetentionStatement("monitoring_logs", 100),
+			affected:       []int64{100, 0},
+			wantDeleted:    100,
+			wantStatements: 2,
+		},
+		{
+			name:           "nothing_expired",
+			statement:      postgresLogRetentionStatement("monitoring_logs", 100),
+			wantStatements: 1,
+		},
+		{
+			name:      "error",
+			statement: mysqlLogRetentionStatement("monitoring_logs", 100),
+			err:       errors.New("connection reset"),
+			wantErr:   true,
+		},
+	}
+
+	db, err := sql.Open("fakeddl", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeDDL.mu.Lock()
+			fakeDDL.statements, fakeDDL.affected, fakeDDL.err = nil, tt.affected, tt.err
+			fakeDDL.mu.Unlock()
+			defer func() {
+				fakeDDL.mu.Lock()
+				fakeDDL.affected, fakeDDL.err = nil, nil
+				fakeDDL.mu.Unlock()
+			}()
+
+			deleted, err := deleteExpiredLogs(context.Background(), db, tt.statement, time.Now().AddDate(0, 0, -30), 100)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("deleteExpiredLogs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if deleted != tt.wantDeleted {
+				t.Errorf("deleteExpiredLogs() = %d, want %d", deleted, tt.wantDeleted)
+			}
+			fakeDDL.mu.Lock()
+			defer fakeDDL.mu.Unlock()
+			if len(fakeDDL.statements) != tt.wantStatements {
+				t.Errorf("executed %d statements, want %d", len(fakeDDL.statements), tt.wantStatements)
+			}
+			for _, statement := range fakeDDL.statements {
+				if statement != tt.statement {
+					t.Errorf("executed %q, want %q", statement, tt.statement)
+				}
+			}
+		})
+	}
+}
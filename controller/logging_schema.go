@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// errLogTablePermission reports that the logging database user can't create the log table
+var errLogTablePermission = errors.New("insufficient privileges to create the log table")
+
+// logTableColumn is a column added to the log table after its first version
+type logTableColumn struct {
+	name       string
+	definition string
+}
+
+// postgresLogTableColumns are the columns added to existing PostgreSQL log tables
+var postgresLogTableColumns = []logTableColumn{
+	{"output", "TEXT"},
+	{"output_encoding", "VARCHAR(10)"},
+	{"namespace", "VARCHAR(253)"},
+	{"workflow", "VARCHAR(253)"},
+	{"run_id", "VARCHAR(253)"},
+	{"labels", "JSONB"},
+	{"error_code", "VARCHAR(10)"},
+	{"output_truncated", "BOOLEAN DEFAULT FALSE"},
+}
+
+// mysqlLogTableColumns are the columns added to existing MySQL log tables
+var mysqlLogTableColumns = []logTableColumn{
+	{"output", "MEDIUMTEXT"},
+	{"output_encoding", "VARCHAR(10)"},
+	{"namespace", "VARCHAR(253)"},
+	{"workflow", "VARCHAR(253)"},
+	{"run_id", "VARCHAR(253)"},
+	{"labels", "JSON"},
+	{"error_code", "VARCHAR(10)"},
+	{"output_truncated", "BOOLEAN DEFAULT FALSE"},
+}
+
+// postgresLogTableStatements returns the statements creating the PostgreSQL log table and its
+// indexes when they are missing, and adding the columns missing from older tables
+func postgresLogTableStatements(table string) []string {
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			id SERIAL PRIMARY KEY,
+			service_name VARCHAR(255) NOT NULL,
+			service_type VARCHAR(50) NOT NULL,
+			status VARCHAR(10) NOT NULL CHECK (status IN ('UP', 'DOWN')),
+			error_message TEXT,
+			response_time_ms BIGINT,
+			timestamp TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)`, table),
+	}
+	for _, column := range postgresLogTableColumns {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s", table, column.name, column.definition))
+	}
+	for _, column := range []string{"service_name", "timestamp", "status", "workflow", "run_id"} {
+		statements = append(statements, fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_%s ON %s(%s)", table, column, table, column))
+	}
+	return statements
+}
+
+// mysqlLogTableStatements returns the statement creating the MySQL log table when it is missing.
+// MySQL has no CREATE INDEX IF NOT EXISTS, so the indexes are created with the table, and
+// addMySQLLogTableColumns migrates older tables.
+func mysqlLogTableStatements(table string) []string {
+	return []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			id BIGINT AUTO_INCREMENT PRIMARY KEY,
+			service_name VARCHAR(255) NOT NULL,
+			service_type VARCHAR(50) NOT NULL,
+			namespace VARCHAR(253),
+			workflow VARCHAR(253),
+			run_id VARCHAR(253),
+			labels JSON,
+			status VARCHAR(10) NOT NULL,
+			error_code VARCHAR(10),
+			error_message TEXT,
+			response_time_ms BIGINT,
+			timestamp DATETIME(3) DEFAULT CURRENT_TIMESTAMP(3),
+			created_at DATETIME(3) DEFAULT CURRENT_TIMESTAMP(3),
+			output MEDIUMTEXT,
+			output_encoding VARCHAR(10),
+			output_truncated BOOLEAN DEFAULT FALSE,
+			INDEX idx_%s_service_name (service_name),
+			INDEX idx_%s_timestamp (timestamp),
+			INDEX idx_%s_status (status),
+			INDEX idx_%s_workflow (workflow),
+			INDEX idx_%s_run_id (run_id)
+		) DEFAULT CHARSET=utf8mb4`, table, table, table, table, table, table),
+	}
+}
+
+// addMySQLLogTableColumns adds the columns missing from a MySQL log table created by an older version
+func addMySQLLogTableColumns(db *sql.DB, table string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, column := range mysqlLogTableColumns {
+		var count int
+		err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM information_schema.columns
+			WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?`, table, column.name).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("failed to check the columns of log table %s: %w", table, err)
+		}
+		if count > 0 {
+			continue
+		}
+		if err := createLogTable(db, table, []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column.name, column.definition)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createLogTable runs the statements creating or migrating a log table. Permission errors wrap
+// errLogTablePermission, so the controller fails at startup instead of on every entry.
+func createLogTable(db *sql.DB, table string, statements []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, statement := range statements {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			if isPermissionError(err) {
+				return fmt.Errorf("%w %s: grant CREATE on the logging database or disable table auto-creation: %v",
+					errLogTablePermission, table, err)
+			}
+			return fmt.Errorf("failed to create log table %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// isPermissionError reports whether a database error is a missing privilege
+func isPermissionError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "42501" // insufficient_privilege
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1044, 1142: // ER_DBACCESS_DENIED_ERROR, ER_TABLEACCESS_DENIED_ERROR
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// fakeDDLDriver records the statements executed on its connections, or fails them with err.
+// Column queries find the existing columns. Statements affect the rows queued in affected.
+type fakeDDLDriver struct {
+	mu         sync.Mutex
+	statements []string
+	columns    map[string]bool
+	affected   []int64
+	err        error
+}
+
+func (d *fakeDDLDriver) Open(string) (driver.Conn, error) { return &fakeDDLConn{driver: d}, nil }
+
+type fakeDDLConn struct{ driver *fakeDDLDriver }
+
+func (c *fakeDDLConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (c *fakeDDLConn) Close() error              { return nil }
+func (c *fakeDDLConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func (c *fakeDDLConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	if c.driver.err != nil {
+		return nil, c.driver.err
+	}
+	c.driver.statements = append(c.driver.statements, query)
+	var affected int64
+	if len(c.driver.affected) > 0 {
+		affected, c.driver.affected = c.driver.affected[0], c.driver.affected[1:]
+	}
+	return driver.RowsAffected(affected), nil
+}
+
+func (c *fakeDDLConn) QueryContext(_ context.Context, _ string, args []driver.NamedValue) (driver.Rows, error) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	var count int64
+	if c.driver.columns[args[1].Value.(string)] {
+		count = 1
+	}
+	return &fakeDDLRows{count: count}, nil
+}
+
+// fakeDDLRows returns a single count
+type fakeDDLRows struct {
+	count int64
+	done  bool
+}
+
+func (*fakeDDLRows) Columns() []string { return []string{"count"} }
+func (*fakeDDLRows) Close() error      { return nil }
+
+func (r *fakeDDLRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.count
+	return nil
+}
+
+var fakeDDL = &fakeDDLDriver{}
+
+func init() {
+	sql.Register("fakeddl", fakeDDL)
+}
+
+// TestCreateLogTable tests creating the log tables and reporting missing privileges
+func TestCreateLogTable(t *testing.T) {
+	tests := []struct {
+		name           string
+		statements     []string
+		err            error
+		wantStatements []string
+		wantPermission bool
+		wantErr        bool
+	}{
+		{
+			name:       "postgres",
+			statements: postgresLogTableStatements("monitoring_logs"),
+			wantStatements: []string{
+				"CREATE TABLE IF NOT EXISTS monitoring_logs",
+				"ALTER TABLE monitoring_logs ADD COLUMN IF NOT EXISTS run_id VARCHAR(253)",
+				"CREATE INDEX IF NOT EXISTS idx_monitoring_logs_timestamp ON monitoring_logs(timestamp)",
+			},
+		},
+		{
+			name:           "mysql",
+			statements:     mysqlLogTableStatements("monitoring_logs"),
+			wantStatements: []string{"CREATE TABLE IF NOT EXISTS monitoring_logs", "INDEX idx_monitoring_logs_status (status)"},
+		},
+		{
+			name:           "postgres_permission_denied",
+			statements:     postgresLogTableStatements("monitoring_logs"),
+			err:            &pq.Error{Code: "42501", Message: "permission denied for schema public"},
+			wantPermission: true,
+			wantErr:        true,
+		},
+		{
+			name:           "mysql_permission_denied",
+			statements:     mysqlLogTableStatements("monitoring_logs"),
+			err:            &mysql.MySQLError{Number: 1142, Message: "CREATE command denied"},
+			wantPermission: true,
+			wantErr:        true,
+		},
+		{
+			name:       "other_error",
+			statements: postgresLogTableStatements("monitoring_logs"),
+			err:        errors.New("connection reset"),
+			wantErr:    true,
+		},
+	}
+
+	db, err := sql.Open("fakeddl", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeDDL.mu.Lock()
+			fakeDDL.statements, fakeDDL.err = nil, tt.err
+			fakeDDL.mu.Unlock()
+
+			err := createLogTable(db, "monitoring_logs", tt.statements)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("createLogTable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, errLogTablePermission) != tt.wantPermission {
+				t.Errorf("createLogTable() error = %v, want permission error %v", err, tt.wantPermission)
+			}
+
+			executed := strings.Join(fakeDDL.statements, "\n")
+			if !tt.wantErr && len(fakeDDL.statements) != len(tt.statements) {
+				t.Errorf("executed %d statements, want %d", len(fakeDDL.statements), len(tt.statements))
+			}
+			for _, want := range tt.wantStatements {
+				if !strings.Contains(executed, want) {
+					t.Errorf("executed statements don't contain %q:\n%s", want, executed)
+				}
+			}
+		})
+	}
+}
+
+// TestAddMySQLLogTableColumns tests adding the columns missing from an older MySQL log table
+func TestAddMySQLLogTableColumns(t *testing.T) {
+	db, err := sql.Open("fakeddl", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	fakeDDL.mu.Lock()
+	fakeDDL.statements, fakeDDL.err = nil, nil
+	fakeDDL.columns = map[string]bool{"output": true, "output_encoding": true}
+	fakeDDL.mu.Unlock()
+	defer func() { fakeDDL.columns = nil }()
+
+	if err := addMySQLLogTableColumns(db, "monitoring_logs"); err != nil {
+		t.Fatalf("addMySQLLogTableColumns() error = %v", err)
+	}
+	if len(fakeDDL.statements) != len(mysqlLogTableColumns)-2 {
+		t.Errorf("executed %v, want the %d missing columns added", fakeDDL.statements, len(mysqlLogTableColumns)-2)
+	}
+	for _, statement := range fakeDDL.statements {
+		if strings.Contains(statement, "ADD COLUMN output ") || strings.Contains(statement, "ADD COLUMN output_encoding ") {
+			t.Errorf("executed %q for an existing column", statement)
+		}
+	}
+	if want := "ALTER TABLE monitoring_logs ADD COLUMN labels JSON"; !strings.Contains(strings.Join(fakeDDL.statements, "\n"), want) {
+		t.Errorf("executed %v, want %q", fakeDDL.statements, want)
+	}
+}
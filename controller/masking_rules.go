@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// maskingRulesKey is the ConfigMap key holding one masking regular expression per line
+const maskingRulesKey = "patterns"
+
+// getMaskingRulesConfigMaps returns the name and namespace of the operator-wide masking rules
+// ConfigMap, and the name of the masking rules ConfigMap looked up in each task namespace
+func getMaskingRulesConfigMaps() (string, string, string) {
+	return os.Getenv("MASKING_RULES_CONFIGMAP"), getEnvOrDefault("NAMESPACE", "mcall-system"),
+		os.Getenv("NAMESPACE_MASKING_RULES_CONFIGMAP")
+}
+
+// resolveMaskingRules returns the custom masking rules of the operator and of the task namespace.
+// The ConfigMaps are read at every execution so that new rules apply without restarting the operator.
+func (r *McallTaskReconciler) resolveMaskingRules(ctx context.Context, namespace string) ([]*regexp.Regexp, error) {
+	operatorName, operatorNamespace, namespaceName := getMaskingRulesConfigMaps()
+
+	var patterns []*regexp.Regexp
+	for _, ref := range []types.NamespacedName{
+		{Name: operatorName, Namespace: operatorNamespace},
+		{Name: namespaceName, Namespace: namespace},
+	} {
+		if ref.Name == "" {
+			continue
+		}
+		var configMap corev1.ConfigMap
+		if err := r.Get(ctx, ref, &configMap); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get masking rules ConfigMap %s/%s: %w", ref.Namespace, ref.Name, err)
+		}
+		rules, err := parsePatternList(configMap.Data[maskingRulesKey])
+		if err != nil {
+			return nil, fmt.Errorf("masking rules ConfigMap %s/%s: %w", ref.Namespace, ref.Name, err)
+		}
+		patterns = append(patterns, rules...)
+	}
+	return patterns, nil
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestResolveMaskingRules tests combining the operator-wide and namespace masking rules
+func TestResolveMaskingRules(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	r := &McallTaskReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "masking-rules", Namespace: "mcall-system"},
+				Data:       map[string]string{maskingRulesKey: "# Issued API keys\nACME-[0-9A-F]{8}\n"},
+			},
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "mcall-masking-rules", Namespace: "payments"},
+				Data:       map[string]string{maskingRulesKey: `\b4[0-9]{15}\b`},
+			},
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "mcall-masking-rules", Namespace: "broken"},
+				Data:       map[string]string{maskingRulesKey: "[a-"},
+			},
+		).Build(),
+		Scheme: scheme,
+	}
+	t.Setenv("NAMESPACE", "mcall-system")
+	t.Setenv("MASKING_RULES_CONFIGMAP", "masking-rules")
+	t.Setenv("NAMESPACE_MASKING_RULES_CONFIGMAP", "mcall-masking-rules")
+
+	output := "key ACME-0A1B2C3D card 4111111111111111"
+	tests := []struct {
+		namespace string
+		want      string
+		wantErr   string
+	}{
+		{namespace: "payments", want: "key ***MASKED*** card ***MASKED***"},
+		{namespace: "default", want: "key ***MASKED*** card 4111111111111111"},
+		{namespace: "broken", wantErr: `masking rules ConfigMap broken/mcall-masking-rules: invalid pattern "[a-"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			patterns, err := r.resolveMaskingRules(context.Background(), tt.namespace)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveMaskingRules() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveMaskingRules() error = %v", err)
+			}
+			if got := maskOutput(output, nil, patterns); got != tt.want {
+				t.Errorf("maskOutput() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Custom rules still apply when the built-in patterns are opted out
+	t.Setenv("MASK_SENSITIVE_DATA", "false")
+	patterns, _ := r.resolveMaskingRules(context.Background(), "default")
+	if got := maskOutput("password=x ACME-0A1B2C3D", nil, patterns); got != "password=x ***MASKED***" {
+		t.Errorf("maskOutput() = %q", got)
+	}
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// McallTaskValidator rejects McallTasks whose commands violate the command policy at admission.
+// The policy is checked again before each execution, once secretRefs and scripts are read.
+type McallTaskValidator struct {
+	Client client.Reader
+}
+
+// SetupWebhookWithManager serves the validating webhook at /validate-mcall-tz-io-v1-mcalltask
+func (v *McallTaskValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&mcallv1.McallTask{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate checks the commands of a new task
+func (v *McallTaskValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(ctx, obj)
+}
+
+// ValidateUpdate checks the commands of an updated task
+func (v *McallTaskValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(ctx, newObj)
+}
+
+// ValidateDelete allows every deletion
+func (v *McallTaskValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate checks the commands of a task with its spec.environment substituted
+func (v *McallTaskValidator) validate(ctx context.Context, obj runtime.Object) error {
+	task, ok := obj.(*mcallv1.McallTask)
+	if !ok {
+		return fmt.Errorf("expected a McallTask, got %T", obj)
+	}
+	policy, err := getCommandPolicy(ctx, v.Client)
+	if err != nil {
+		return fmt.Errorf("failed to read command policy: %w", err)
+	}
+	return policy.checkTask(task, task.Spec.Environment, "")
+}
@@ -4,16 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
@@ -23,6 +28,12 @@ import (
 type McallWorkflowReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// MaxConcurrentReconciles is the number of workflows reconciled in parallel (default 1)
+	MaxConcurrentReconciles int
+
+	// Recorder emits the Events of workflow lifecycle transitions
+	Recorder record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=mcall.tz.io,resources=mcallworkflows,verbs=get;list;watch;create;update;patch;delete
@@ -30,11 +41,13 @@ type McallWorkflowReconciler struct {
 //+kubebuilder:rbac:groups=mcall.tz.io,resources=mcallworkflows/finalizers,verbs=update
 //+kubebuilder:rbac:groups=mcall.tz.io,resources=mcalltasks,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=mcall.tz.io,resources=mcalltasks/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=mcall.tz.io,resources=mcallworkflowruns,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=mcall.tz.io,resources=mcallworkflowruns/status,verbs=get;update;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop
 func (r *McallWorkflowReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
-	log.Info("=== WORKFLOW RECONCILE START ===", "workflow", req.NamespacedName)
+	log.V(debugLevel).Info("Reconciling workflow", "workflow", req.NamespacedName)
 
 	// Fetch the McallWorkflow instance
 	var mcallWorkflow mcallv1.McallWorkflow
@@ -43,20 +56,25 @@ func (r *McallWorkflowReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	log.Info("Fetched McallWorkflow", "workflow", mcallWorkflow.Name, "currentPhase", mcallWorkflow.Status.Phase)
+	log.V(debugLevel).Info("Fetched McallWorkflow", "workflow", mcallWorkflow.Name, "currentPhase", mcallWorkflow.Status.Phase)
 
 	// Initialize status if not set
 	if len(mcallWorkflow.Status.Phase) == 0 {
-		log.Info("*** WORKFLOW STATUS PHASE IS EMPTY - INITIALIZING TO PENDING ***", "workflow", mcallWorkflow.Name)
 		mcallWorkflow.Status.Phase = mcallv1.McallWorkflowPhasePending
+		setWorkflowConditions(&mcallWorkflow)
 		if err := r.Status().Update(ctx, &mcallWorkflow); err != nil {
-			log.Error(err, "*** FAILED TO INITIALIZE WORKFLOW STATUS PHASE ***", "workflow", mcallWorkflow.Name, "error", err.Error())
+			log.Error(err, "Failed to initialize workflow status phase", "workflow", mcallWorkflow.Name)
 			return ctrl.Result{}, err
 		}
-		log.Info("*** SUCCESSFULLY INITIALIZED WORKFLOW STATUS PHASE ***", "workflow", mcallWorkflow.Name, "phase", mcallWorkflow.Status.Phase)
+		log.V(debugLevel).Info("Initialized workflow status phase", "workflow", mcallWorkflow.Name, "phase", mcallWorkflow.Status.Phase)
 		return ctrl.Result{RequeueAfter: 1 * time.Second}, nil
 	}
 
+	// A restart discards the current or last run, whatever its phase
+	if workflowRestartRequested(&mcallWorkflow) {
+		return r.restartWorkflow(ctx, &mcallWorkflow)
+	}
+
 	// Handle different phases
 	switch mcallWorkflow.Status.Phase {
 	case mcallv1.McallWorkflowPhasePending:
@@ -73,33 +91,193 @@ func (r *McallWorkflowReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 func (r *McallWorkflowReconciler) handleWorkflowPending(ctx context.Context, workflow *mcallv1.McallWorkflow) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
 
-	// Check if workflow should be scheduled
-	if workflow.Spec.Schedule != "" {
+	// Suspended workflows don't start new runs
+	if workflow.Spec.Suspend {
+		if workflow.Status.SuspendedTime == nil {
+			log.Info("Workflow suspended", "workflow", workflow.Name)
+			workflow.Status.SuspendedTime = &metav1.Time{Time: time.Now()}
+			workflow.Status.NextRunTime = nil
+			setWorkflowConditions(workflow)
+			if err := r.Status().Update(ctx, workflow); err != nil {
+				return ctrl.Result{}, err
+			}
+			recordWorkflowSchedule(workflow)
+		}
+		return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
+	}
+	if workflow.Status.SuspendedTime != nil {
+		log.Info("Workflow resumed", "workflow", workflow.Name, "backfill", workflow.Spec.BackfillOnResume)
+		workflow.Status.SuspendedTime = nil
+		if !workflow.Spec.BackfillOnResume {
+			workflow.Status.ResumedTime = &metav1.Time{Time: time.Now()}
+		}
+		setWorkflowConditions(workflow)
+		if err := r.Status().Update(ctx, workflow); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 1 * time.Second}, nil
+	}
+
+	// Reject dependency cycles and undefined tasks, whose runs would never complete
+	if err := validateWorkflowDependencies(workflow.Spec.Tasks, workflow.Spec.OnExit); err != nil {
+		if workflow.Status.Reason != invalidDependenciesReason || workflow.Status.Message != err.Error() {
+			log.Info("Workflow rejected for invalid dependencies", "workflow", workflow.Name, "error", err.Error())
+			workflow.Status.Reason = invalidDependenciesReason
+			workflow.Status.Message = err.Error()
+			setWorkflowConditions(workflow)
+			if err := r.Status().Update(ctx, workflow); err != nil {
+				return ctrl.Result{}, err
+			}
+			recordEvent(r.Recorder, workflow, corev1.EventTypeWarning, invalidDependenciesReason, "%s", truncateString(err.Error(), eventMessageMaxLength))
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Reject parameter values that don't parse or aren't declared
+	if _, err := resolveWorkflowParameters(workflow); err != nil {
+		if workflow.Status.Reason != invalidParametersReason || workflow.Status.Message != err.Error() {
+			log.Info("Workflow rejected for invalid parameters", "workflow", workflow.Name, "error", err.Error())
+			workflow.Status.Reason = invalidParametersReason
+			workflow.Status.Message = err.Error()
+			setWorkflowConditions(workflow)
+			if err := r.Status().Update(ctx, workflow); err != nil {
+				return ctrl.Result{}, err
+			}
+			recordEvent(r.Recorder, workflow, corev1.EventTypeWarning, invalidParametersReason, "%s", truncateString(err.Error(), eventMessageMaxLength))
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Reject pathological specs before creating any tasks; a spec update triggers a new reconcile
+	if err := validateWorkflowLimits(&workflow.Spec, getWorkflowLimits()); err != nil {
+		if workflow.Status.Reason != workflowLimitReason || workflow.Status.Message != err.Error() {
+			log.Info("Workflow rejected by size limits", "workflow", workflow.Name, "error", err.Error())
+			workflow.Status.Reason = workflowLimitReason
+			workflow.Status.Message = err.Error()
+			setWorkflowConditions(workflow)
+			if err := r.Status().Update(ctx, workflow); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+	if err := r.checkTemplatesValidated(ctx, workflow); err != nil {
+		if workflow.Status.Reason != templateNotValidatedReason || workflow.Status.Message != err.Error() {
+			log.Info("Workflow waiting for template warm-up", "workflow", workflow.Name, "error", err.Error())
+			workflow.Status.Reason = templateNotValidatedReason
+			workflow.Status.Message = err.Error()
+			setWorkflowConditions(workflow)
+			if err := r.Status().Update(ctx, workflow); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+	if workflowBlockedReason(workflow.Status.Reason) {
+		workflow.Status.Reason = ""
+		workflow.Status.Message = ""
+		setWorkflowConditions(workflow)
+		if err := r.Status().Update(ctx, workflow); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 1 * time.Second}, nil
+	}
+
+	// Check if workflow should be scheduled, triggered runs start right away
+	if workflow.Spec.Schedule != "" && !workflowTriggered(workflow) {
 		shouldRun, err := r.shouldRunScheduledWorkflow(ctx, workflow)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
 		if !shouldRun {
 			log.Info("Workflow not scheduled to run yet", "workflow", workflow.Name)
+
+			// Keep the next run time in status up to date for `kubectl get -o wide`
+			if workflow.Status.LastRunTime != nil {
+				nextRun := r.scheduledNextRunTime(ctx, workflow, workflow.Status.LastRunTime.Time)
+				if nextRun != nil && nextRun.Time.Before(time.Now()) {
+					// The missed run was skipped (starting deadline), report the next window instead
+					nextRun = r.scheduledNextRunTime(ctx, workflow, time.Now())
+				}
+				if !nextRun.Equal(workflow.Status.NextRunTime) {
+					workflow.Status.NextRunTime = nextRun
+					setWorkflowConditions(workflow)
+					if err := r.Status().Update(ctx, workflow); err != nil {
+						return ctrl.Result{}, err
+					}
+				}
+				recordWorkflowSchedule(workflow)
+			}
 			return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
 		}
 	}
 
+	// Start time identifies the run, set it before the task instances are labeled with it
+	now := time.Now()
+	workflow.Status.StartTime = &metav1.Time{Time: now}
+	workflow.Status.OnExitStartTime = nil
+
+	// Parameters were checked above; trigger overrides apply to this run only
+	workflow.Status.Parameters, _ = resolveWorkflowParameters(workflow)
+
+	// Trace the run; its task instances carry the trace context in an annotation
+	ctx = startWorkflowRunSpan(ctx, workflow)
+
 	// Create McallTask resources for each task in the workflow
-	if err := r.createWorkflowTasks(ctx, workflow); err != nil {
+	if err := r.createWorkflowTasks(ctx, workflow, workflow.Spec.Tasks, nil); err != nil {
 		return ctrl.Result{}, err
 	}
 
 	// Update status to Running
 	workflow.Status.Phase = mcallv1.McallWorkflowPhaseRunning
-	workflow.Status.StartTime = &metav1.Time{Time: time.Now()}
+	var missedRuns int
+	if workflow.Spec.Schedule != "" {
+		if lastRun := scheduleBase(workflow); lastRun != nil {
+			if schedule, err := cronParser.Parse(workflow.Spec.Schedule); err == nil {
+				missedRuns = missedScheduledRuns(schedule, lastRun.Time, now)
+			}
+		}
+		workflow.Status.LastRunTime = &metav1.Time{Time: now}
+		workflow.Status.NextRunTime = r.scheduledNextRunTime(ctx, workflow, now)
+	}
+	setWorkflowConditions(workflow)
 	if err := r.Status().Update(ctx, workflow); err != nil {
 		return ctrl.Result{}, err
 	}
+	if workflow.Spec.Schedule != "" {
+		if missedRuns > 0 {
+			log.Info("Scheduled runs were missed", "workflow", workflow.Name, "missedRuns", missedRuns)
+		}
+		workflowMissedRuns.WithLabelValues(workflow.Namespace, workflow.Name).Add(float64(missedRuns))
+		recordWorkflowSchedule(workflow)
+	}
+
+	recordEvent(r.Recorder, workflow, corev1.EventTypeNormal, EventReasonStarted, "Started run with %d task(s)", len(workflow.Spec.Tasks))
+
+	// Record this trigger as a McallWorkflowRun
+	if err := r.createWorkflowRun(ctx, workflow); err != nil {
+		log.Error(err, "Failed to create workflow run", "workflow", workflow.Name)
+	}
+
+	// The trigger started this run, later runs use the parameters annotation again
+	if err := r.consumeTrigger(ctx, workflow); err != nil {
+		log.Error(err, "Failed to remove trigger annotation", "workflow", workflow.Name)
+	}
 
 	return ctrl.Result{}, nil
 }
 
+// consumeTrigger removes the trigger annotation of a workflow whose run started. A trigger set
+// again meanwhile conflicts and is kept, starting another run.
+func (r *McallWorkflowReconciler) consumeTrigger(ctx context.Context, workflow *mcallv1.McallWorkflow) error {
+	if !workflowTriggered(workflow) {
+		return nil
+	}
+	patch := client.MergeFromWithOptions(workflow.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	delete(workflow.Annotations, mcallv1.TriggerAnnotation)
+	return r.Patch(ctx, workflow, patch)
+}
+
 func (r *McallWorkflowReconciler) handleWorkflowRunning(ctx context.Context, workflow *mcallv1.McallWorkflow) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
 
@@ -115,6 +293,37 @@ func (r *McallWorkflowReconciler) handleWorkflowRunning(ctx context.Context, wor
 		return ctrl.Result{}, err
 	}
 
+	// Fail fast: once a task failed, the tasks that haven't started are skipped
+	if workflow.Spec.FailFast && hasFailedTasks && !allTasksCompleted {
+		if err := r.skipPendingTasks(ctx, workflow); err != nil {
+			return ctrl.Result{}, err
+		}
+		if allTasksCompleted, hasFailedTasks, err = r.checkWorkflowTasksStatus(ctx, workflow); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Tasks whose dependencies failed or were skipped never start, they are skipped
+	if !allTasksCompleted {
+		skipped, err := r.skipUnreachableTasks(ctx, workflow)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if skipped {
+			if allTasksCompleted, hasFailedTasks, err = r.checkWorkflowTasksStatus(ctx, workflow); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	// Exit tasks start once all tasks completed, the run completes with them
+	if allTasksCompleted && len(workflow.Spec.OnExit) > 0 && workflow.Status.OnExitStartTime == nil {
+		if err := r.startExitTasks(ctx, workflow, hasFailedTasks); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
 	if allTasksCompleted {
 		if hasFailedTasks {
 			workflow.Status.Phase = mcallv1.McallWorkflowPhaseFailed
@@ -122,59 +331,67 @@ func (r *McallWorkflowReconciler) handleWorkflowRunning(ctx context.Context, wor
 			workflow.Status.Phase = mcallv1.McallWorkflowPhaseSucceeded
 		}
 		workflow.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+		if workflow.Status.StartTime != nil {
+			workflow.Status.LastRunDuration = formatDuration(workflow.Status.CompletionTime.Sub(workflow.Status.StartTime.Time))
+		}
 
 		// Build final DAG state
 		if err := r.buildWorkflowDAG(ctx, workflow); err != nil {
 			log.Error(err, "Failed to build final workflow DAG", "workflow", workflow.Name)
 		}
 
+		setWorkflowConditions(workflow)
 		if err := r.Status().Update(ctx, workflow); err != nil {
 			return ctrl.Result{}, err
 		}
 		log.Info("Workflow completed", "workflow", workflow.Name, "phase", workflow.Status.Phase)
+		endWorkflowRunSpan(workflow)
+		if hasFailedTasks {
+			recordEvent(r.Recorder, workflow, corev1.EventTypeWarning, EventReasonFailed, "Run failed after %s", workflow.Status.LastRunDuration)
+		} else if workflow.Status.ToleratedFailures > 0 {
+			recordEvent(r.Recorder, workflow, corev1.EventTypeNormal, EventReasonCompleted, "Run succeeded in %s, %d task failure(s) tolerated",
+				workflow.Status.LastRunDuration, workflow.Status.ToleratedFailures)
+		} else {
+			recordEvent(r.Recorder, workflow, corev1.EventTypeNormal, EventReasonCompleted, "Run succeeded in %s", workflow.Status.LastRunDuration)
+		}
+
+		// Record the results of this run before the task instances are cleaned up
+		if err := r.recordWorkflowRun(ctx, workflow); err != nil {
+			log.Error(err, "Failed to record workflow run", "workflow", workflow.Name)
+		}
+		recordWorkflowRunMetrics(workflow)
+
+		// Export run results to the configured report sink
+		r.exportWorkflowReport(ctx, workflow)
+
+		r.notifyWorkflowResult(ctx, workflow)
 		return ctrl.Result{}, nil
 	}
 
 	// Update status with DAG (fetch latest version to avoid conflicts)
-	log.Info("🔄 Starting DAG Status Update", "workflow", workflow.Name, "dagNodes", len(workflow.Status.DAG.Nodes), "dagEdges", len(workflow.Status.DAG.Edges))
-
 	updateErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		log.Info("🔄 RetryOnConflict attempt - fetching latest workflow", "workflow", workflow.Name)
-
 		// Get the latest version of the workflow
 		latest := &mcallv1.McallWorkflow{}
 		if err := r.Get(ctx, types.NamespacedName{
 			Name:      workflow.Name,
 			Namespace: workflow.Namespace,
 		}, latest); err != nil {
-			log.Error(err, "❌ Failed to get latest workflow version", "workflow", workflow.Name)
 			return err
 		}
 
-		log.Info("✅ Got latest workflow version", "workflow", workflow.Name, "currentDAG", latest.Status.DAG != nil)
-
 		// Update the DAG on the latest version
 		latest.Status.DAG = workflow.Status.DAG
-
-		log.Info("🔄 Setting DAG on latest version", "workflow", workflow.Name, "dagNodes", len(latest.Status.DAG.Nodes), "dagEdges", len(latest.Status.DAG.Edges))
-
-		// Update the status
-		log.Info("🔄 Calling Status().Update", "workflow", workflow.Name)
-		updateErr := r.Status().Update(ctx, latest)
-		if updateErr != nil {
-			log.Error(updateErr, "❌ Status().Update failed", "workflow", workflow.Name, "error", updateErr.Error())
-		} else {
-			log.Info("✅ Status().Update succeeded", "workflow", workflow.Name)
-		}
-		return updateErr
+		setWorkflowConditions(latest)
+		return r.Status().Update(ctx, latest)
 	})
 
 	if updateErr != nil {
-		log.Error(updateErr, "❌ Failed to update workflow status with DAG after retries", "workflow", workflow.Name, "retries", retry.DefaultRetry.Steps)
+		log.Error(updateErr, "Failed to update workflow status with DAG after retries", "workflow", workflow.Name, "retries", retry.DefaultRetry.Steps)
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 	}
 
-	log.Info("✅ DAG Status Update completed successfully", "workflow", workflow.Name)
+	log.V(debugLevel).Info("Updated workflow DAG status", "workflow", workflow.Name,
+		"dagNodes", len(workflow.Status.DAG.Nodes), "dagEdges", len(workflow.Status.DAG.Edges))
 
 	// Continue monitoring
 	return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
@@ -183,8 +400,14 @@ func (r *McallWorkflowReconciler) handleWorkflowRunning(ctx context.Context, wor
 func (r *McallWorkflowReconciler) handleWorkflowCompleted(ctx context.Context, workflow *mcallv1.McallWorkflow) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
 
-	// For scheduled workflows, clean up completed tasks and reset to Pending for next run
-	if workflow.Spec.Schedule != "" {
+	// A failed run requested to resume keeps its task instances
+	if retryFailedRequested(workflow) {
+		return r.retryFailedTasks(ctx, workflow)
+	}
+
+	// For scheduled and triggered workflows, clean up completed tasks and reset to Pending for next run
+	triggered := workflowTriggered(workflow)
+	if workflow.Spec.Schedule != "" || triggered {
 		log.Info("Cleaning up completed scheduled workflow", "workflow", workflow.Name, "phase", workflow.Status.Phase)
 
 		// Build final DAG before cleanup
@@ -192,6 +415,14 @@ func (r *McallWorkflowReconciler) handleWorkflowCompleted(ctx context.Context, w
 			log.Error(err, "Failed to build final DAG before cleanup", "workflow", workflow.Name)
 		}
 
+		// Keep copies of this run's task instances if run history is enabled
+		if err := r.archiveWorkflowRun(ctx, workflow); err != nil {
+			log.Error(err, "Failed to archive workflow run history", "workflow", workflow.Name)
+		}
+		if err := r.pruneRunHistory(ctx, workflow); err != nil {
+			log.Error(err, "Failed to prune workflow run history", "workflow", workflow.Name)
+		}
+
 		// Delete workflow-specific task instances (not template tasks)
 		if err := r.deleteWorkflowTasks(ctx, workflow); err != nil {
 			log.Error(err, "Failed to delete workflow tasks", "workflow", workflow.Name)
@@ -216,6 +447,7 @@ func (r *McallWorkflowReconciler) handleWorkflowCompleted(ctx context.Context, w
 			latest.Status.CompletionTime = nil
 			// latest.Status.DAG = nil // Don't clear DAG - keep last run data for UI
 
+			setWorkflowConditions(latest)
 			return r.Status().Update(ctx, latest)
 		})
 
@@ -224,8 +456,11 @@ func (r *McallWorkflowReconciler) handleWorkflowCompleted(ctx context.Context, w
 			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
 		}
 
-		log.Info("Workflow reset to Pending for next scheduled run",
-			"workflow", workflow.Name)
+		log.Info("Workflow reset to Pending for next run",
+			"workflow", workflow.Name, "triggered", triggered)
+		if triggered {
+			return ctrl.Result{RequeueAfter: 1 * time.Second}, nil
+		}
 		return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
 	}
 
@@ -238,11 +473,49 @@ func (r *McallWorkflowReconciler) shouldRunScheduledWorkflow(ctx context.Context
 	return scheduler.ShouldRun(ctx, workflow)
 }
 
-func (r *McallWorkflowReconciler) createWorkflowTasks(ctx context.Context, workflow *mcallv1.McallWorkflow) error {
+// scheduledNextRunTime returns the next scheduled run after the given time, or nil if it cannot be computed
+func (r *McallWorkflowReconciler) scheduledNextRunTime(ctx context.Context, workflow *mcallv1.McallWorkflow, after time.Time) *metav1.Time {
+	log := log.FromContext(ctx)
+
+	scheduler := NewCronScheduler(r.Client)
+	nextRun, err := scheduler.NextRunTime(workflow.Spec.Schedule, after)
+	if err != nil {
+		log.Error(err, "Failed to calculate next run time", "workflow", workflow.Name, "schedule", workflow.Spec.Schedule)
+		return nil
+	}
+	return &metav1.Time{Time: nextRun}
+}
+
+// createWorkflowTasks creates the instances of tasks of a workflow for the current run. Exit
+// task instances are created with the outcome of the run.
+func (r *McallWorkflowReconciler) createWorkflowTasks(ctx context.Context, workflow *mcallv1.McallWorkflow, taskSpecs []mcallv1.WorkflowTaskRef, outcome *mcallv1.McallTaskStatus) error {
 	log := log.FromContext(ctx)
 
 	// Create tasks in dependency order
-	tasksToCreate := r.sortTasksByDependencies(workflow.Spec.Tasks)
+	tasksToCreate := r.sortTasksByDependencies(withFailureHandlers(taskSpecs))
+
+	// onFailure handlers keyed by their task name
+	handlerOf := make(map[string]string)
+	for _, taskSpec := range taskSpecs {
+		if taskSpec.OnFailure != nil {
+			handlerOf[taskSpec.Name+"-on-failure"] = taskSpec.Name
+		}
+	}
+
+	params := workflow.Status.Parameters
+
+	// Failure injections (chaos testing) keyed by workflow task name
+	injections := make(map[string]mcallv1.FailureInjection)
+	if injectionStr, exists := workflow.Annotations[mcallv1.FailureInjectionAnnotation]; exists && injectionStr != "" {
+		var injectionList []mcallv1.FailureInjection
+		if err := json.Unmarshal([]byte(injectionStr), &injectionList); err != nil {
+			log.Error(err, "Failed to parse failure injection annotation", "workflow", workflow.Name)
+			return err
+		}
+		for _, injection := range injectionList {
+			injections[injection.Task] = injection
+		}
+	}
 
 	for _, taskSpec := range tasksToCreate {
 		// Get the referenced McallTask
@@ -269,12 +542,25 @@ func (r *McallWorkflowReconciler) createWorkflowTasks(ctx context.Context, workf
 					"mcall.tz.io/workflow":      workflow.Name,
 					"mcall.tz.io/task":          taskSpec.Name,
 					"mcall.tz.io/original-task": taskRef.Name,
+					"mcall.tz.io/run":           workflowRunID(workflow),
 				},
 				Annotations: make(map[string]string),
 			},
 			Spec: *referencedTask.Spec.DeepCopy(),
 		}
 
+		// Warm-up validates the template only, instances run directly
+		task.Spec.WarmUp = nil
+
+		if failedTask, isHandler := handlerOf[taskSpec.Name]; isHandler {
+			task.Labels[failureHandlerLabel] = failedTask
+		}
+
+		// Failures of optional tasks don't fail the run
+		if taskSpec.ContinueOn != "" {
+			task.Annotations[continueOnAnnotation] = taskSpec.ContinueOn
+		}
+
 		// Update dependencies to use workflow task names
 		task.Spec.Dependencies = r.convertDependencies(workflow.Name, taskSpec.Dependencies)
 
@@ -291,24 +577,78 @@ func (r *McallWorkflowReconciler) createWorkflowTasks(ctx context.Context, workf
 			}
 			task.Annotations["mcall.tz.io/condition"] = string(conditionJSON)
 
-			log.Info("Set task condition",
+			log.V(debugLevel).Info("Set task condition",
 				"workflow", workflow.Name,
 				"task", taskSpec.Name,
 				"condition", condition)
 		}
 
+		// Set Conditions annotation if specified
+		if taskSpec.Conditions != nil {
+			// Update the DependentTasks to use workflow task names
+			conditions := mcallv1.TaskConditions{
+				AllOf: r.convertConditions(workflow.Name, taskSpec.Conditions.AllOf),
+				AnyOf: r.convertConditions(workflow.Name, taskSpec.Conditions.AnyOf),
+			}
+
+			conditionsJSON, err := json.Marshal(conditions)
+			if err != nil {
+				log.Error(err, "Failed to marshal task conditions", "workflow", workflow.Name, "task", taskSpec.Name)
+				return err
+			}
+			task.Annotations["mcall.tz.io/conditions"] = string(conditionsJSON)
+		}
+
+		// Set failure injection annotation if specified
+		if injection, exists := injections[taskSpec.Name]; exists {
+			injectionJSON, err := json.Marshal(injection)
+			if err != nil {
+				log.Error(err, "Failed to marshal failure injection", "workflow", workflow.Name, "task", taskSpec.Name)
+				return err
+			}
+			task.Annotations[mcallv1.FailureInjectionAnnotation] = string(injectionJSON)
+
+			log.Info("Set task failure injection",
+				"workflow", workflow.Name,
+				"task", taskSpec.Name,
+				"action", injection.Action)
+		}
+
+		// Pausing a template task also pauses its workflow instances
+		if paused, exists := referencedTask.Annotations[mcallv1.PausedAnnotation]; exists {
+			task.Annotations[mcallv1.PausedAnnotation] = paused
+		}
+
+		// Task failures are notified like the workflow's
+		for _, annotation := range []string{mcallv1.NotifyAnnotation, mcallv1.NotifySecretAnnotation} {
+			if value, exists := workflow.Annotations[annotation]; exists {
+				task.Annotations[annotation] = value
+			}
+		}
+
+		// Executions of the task instance are traced within the run
+		injectTraceParent(ctx, task.Annotations)
+
+		// Set stagger annotation to space out task starts
+		if workflow.Spec.StaggerSeconds > 0 {
+			task.Annotations["mcall.tz.io/stagger-seconds"] = strconv.Itoa(int(workflow.Spec.StaggerSeconds))
+		}
+
 		// Set InputSources if specified
 		if len(taskSpec.InputSources) > 0 {
 			// Convert task references to use workflow task names
 			inputSources := make([]mcallv1.TaskInputSource, len(taskSpec.InputSources))
 			for i, source := range taskSpec.InputSources {
 				inputSources[i] = source
-				// Convert TaskRef to workflow task name
-				inputSources[i].TaskRef = fmt.Sprintf("%s-%s", workflow.Name, source.TaskRef)
+				// Convert TaskRef to workflow task name, unless it references a task outside the workflow
+				// or the outcome of the run
+				if !externalInputSource(source) && !(outcome != nil && workflowOutcomeSource(source)) {
+					inputSources[i].TaskRef = fmt.Sprintf("%s-%s", workflow.Name, source.TaskRef)
+				}
 			}
 			task.Spec.InputSources = inputSources
 
-			log.Info("Set task input sources",
+			log.V(debugLevel).Info("Set task input sources",
 				"workflow", workflow.Name,
 				"task", taskSpec.Name,
 				"sourceCount", len(inputSources))
@@ -318,12 +658,48 @@ func (r *McallWorkflowReconciler) createWorkflowTasks(ctx context.Context, workf
 		if taskSpec.InputTemplate != "" {
 			task.Spec.InputTemplate = taskSpec.InputTemplate
 
-			log.Info("Set task input template",
+			log.V(debugLevel).Info("Set task input template",
 				"workflow", workflow.Name,
 				"task", taskSpec.Name,
 				"template", taskSpec.InputTemplate)
 		}
 
+		// Exit tasks receive the outcome of the run
+		if outcome != nil {
+			outcomeJSON, err := json.Marshal(outcome)
+			if err != nil {
+				log.Error(err, "Failed to marshal workflow outcome", "workflow", workflow.Name, "task", taskSpec.Name)
+				return err
+			}
+			task.Labels[exitTaskLabel] = "true"
+			task.Annotations[mcallv1.WorkflowOutcomeAnnotation] = string(outcomeJSON)
+		}
+
+		// Substitute the parameters of this run
+		applyWorkflowParameters(task, params)
+
+		// Set fan-out annotation if specified, the withParam task is waited for like a dependency
+		if len(taskSpec.WithItems) > 0 || taskSpec.WithParam != nil {
+			fanOut := mcallv1.FanOut{}
+			for _, item := range taskSpec.WithItems {
+				fanOut.Items = append(fanOut.Items, substituteParameters(item, params))
+			}
+			if taskSpec.WithParam != nil && len(fanOut.Items) == 0 {
+				fanOut.Param = &mcallv1.TaskParamSource{
+					TaskRef:  fmt.Sprintf("%s-%s", workflow.Name, taskSpec.WithParam.TaskRef),
+					JSONPath: taskSpec.WithParam.JSONPath,
+				}
+				task.Spec.Dependencies = append(task.Spec.Dependencies, fanOut.Param.TaskRef)
+			}
+
+			fanOutJSON, err := json.Marshal(fanOut)
+			if err != nil {
+				log.Error(err, "Failed to marshal task fan-out", "workflow", workflow.Name, "task", taskSpec.Name)
+				return err
+			}
+			task.Annotations[mcallv1.FanOutAnnotation] = string(fanOutJSON)
+		}
+
 		if err := r.Create(ctx, task); err != nil {
 			if apierrors.IsAlreadyExists(err) {
 				// Task already exists, delete and recreate with updated specs
@@ -381,13 +757,13 @@ func (r *McallWorkflowReconciler) createWorkflowTasks(ctx context.Context, workf
 					return createErr
 				}
 
-				log.Info("Recreated task for workflow", "workflow", workflow.Name, "task", taskSpec.Name)
+				log.V(debugLevel).Info("Recreated task for workflow", "workflow", workflow.Name, "task", taskSpec.Name)
 			} else {
 				log.Error(err, "Failed to create task", "workflow", workflow.Name, "task", taskSpec.Name)
 				return err
 			}
 		} else {
-			log.Info("Created task for workflow", "workflow", workflow.Name, "task", taskSpec.Name, "originalTask", taskRef.Name, "dependencies", taskSpec.Dependencies)
+			log.V(debugLevel).Info("Created task for workflow", "workflow", workflow.Name, "task", taskSpec.Name, "originalTask", taskRef.Name, "dependencies", taskSpec.Dependencies)
 		}
 	}
 
@@ -420,7 +796,7 @@ func (r *McallWorkflowReconciler) deleteWorkflowTasks(ctx context.Context, workf
 		}
 
 		tasksToDelete = append(tasksToDelete, task.Name)
-		log.Info("Deleted workflow task", "workflow", workflow.Name, "task", task.Name)
+		log.V(debugLevel).Info("Deleted workflow task", "workflow", workflow.Name, "task", task.Name)
 	}
 
 	// Wait for all tasks to be fully deleted
@@ -461,6 +837,255 @@ func (r *McallWorkflowReconciler) deleteWorkflowTasks(ctx context.Context, workf
 	return nil
 }
 
+// runHistoryLimit returns the number of runs to keep for the given workflow outcome
+func runHistoryLimit(workflow *mcallv1.McallWorkflow, phase mcallv1.McallWorkflowPhase) int {
+	limit := workflow.Spec.SuccessfulRunsHistoryLimit
+	if phase == mcallv1.McallWorkflowPhaseFailed {
+		limit = workflow.Spec.FailedRunsHistoryLimit
+	}
+	if limit == nil || *limit < 0 {
+		return 0
+	}
+	return int(*limit)
+}
+
+// archiveWorkflowRun copies the task instances of a completed run into run history.
+// History copies are labeled with mcall.tz.io/history-of instead of mcall.tz.io/workflow,
+// so they are ignored by the workflow and never executed by the task controller.
+func (r *McallWorkflowReconciler) archiveWorkflowRun(ctx context.Context, workflow *mcallv1.McallWorkflow) error {
+	log := log.FromContext(ctx)
+
+	if runHistoryLimit(workflow, workflow.Status.Phase) == 0 || workflow.Status.StartTime == nil {
+		return nil
+	}
+
+	var tasks mcallv1.McallTaskList
+	if err := r.List(ctx, &tasks,
+		client.InNamespace(workflow.Namespace),
+		client.MatchingLabels{"mcall.tz.io/workflow": workflow.Name}); err != nil {
+		return err
+	}
+
+	runID := workflowRunID(workflow)
+	for _, task := range tasks.Items {
+		archived := &mcallv1.McallTask{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%s", task.Name, runID),
+				Namespace: task.Namespace,
+				Labels: map[string]string{
+					"mcall.tz.io/history-of": workflow.Name,
+					"mcall.tz.io/run":        runID,
+					"mcall.tz.io/run-phase":  string(workflow.Status.Phase),
+					"mcall.tz.io/task":       task.Labels["mcall.tz.io/task"],
+				},
+				Annotations: task.Annotations,
+			},
+			Spec: *task.Spec.DeepCopy(),
+		}
+
+		if err := r.Create(ctx, archived); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				continue
+			}
+			return err
+		}
+
+		// Status is a subresource, copy it after creation
+		archived.Status = *task.Status.DeepCopy()
+		if err := r.Status().Update(ctx, archived); err != nil {
+			return err
+		}
+	}
+
+	log.Info("Archived workflow run", "workflow", workflow.Name, "run", runID, "tasks", len(tasks.Items))
+	return nil
+}
+
+// workflowRunID returns the identifier of the current run, derived from its start time
+func workflowRunID(workflow *mcallv1.McallWorkflow) string {
+	return workflow.Status.StartTime.UTC().Format("20060102-150405")
+}
+
+// workflowRunName returns the name of the McallWorkflowRun for a workflow run ID
+func workflowRunName(workflowName, runID string) string {
+	return fmt.Sprintf("%s-%s", workflowName, runID)
+}
+
+// createWorkflowRun creates the McallWorkflowRun recording the run that just started
+func (r *McallWorkflowReconciler) createWorkflowRun(ctx context.Context, workflow *mcallv1.McallWorkflow) error {
+	log := log.FromContext(ctx)
+
+	if workflow.Status.StartTime == nil {
+		return nil
+	}
+
+	trigger := "manual"
+	if workflow.Spec.Schedule != "" && !workflowTriggered(workflow) {
+		trigger = "schedule"
+	}
+
+	runID := workflowRunID(workflow)
+	run := &mcallv1.McallWorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      workflowRunName(workflow.Name, runID),
+			Namespace: workflow.Namespace,
+			Labels: map[string]string{
+				"mcall.tz.io/workflow": workflow.Name,
+				"mcall.tz.io/run":      runID,
+			},
+		},
+		Spec: mcallv1.McallWorkflowRunSpec{
+			WorkflowRef: workflow.Name,
+			RunID:       runID,
+			Trigger:     trigger,
+		},
+	}
+
+	if err := r.Create(ctx, run); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	}
+
+	// Status is a subresource, set it after creation
+	run.Status = mcallv1.McallWorkflowRunStatus{
+		Phase:      mcallv1.McallWorkflowPhaseRunning,
+		StartTime:  workflow.Status.StartTime,
+		Parameters: workflow.Status.Parameters,
+	}
+	if err := r.Status().Update(ctx, run); err != nil {
+		return err
+	}
+
+	log.Info("Created workflow run", "workflow", workflow.Name, "run", run.Name, "trigger", trigger)
+	return nil
+}
+
+// recordWorkflowRun stores the final phase, per-task results and DAG snapshot of a completed run
+func (r *McallWorkflowReconciler) recordWorkflowRun(ctx context.Context, workflow *mcallv1.McallWorkflow) error {
+	log := log.FromContext(ctx)
+
+	if workflow.Status.StartTime == nil {
+		return nil
+	}
+
+	var tasks mcallv1.McallTaskList
+	if err := r.List(ctx, &tasks, client.InNamespace(workflow.Namespace), client.MatchingLabels{"mcall.tz.io/workflow": workflow.Name}); err != nil {
+		return err
+	}
+	results := buildWorkflowRunTaskResults(tasks.Items)
+
+	runName := workflowRunName(workflow.Name, workflowRunID(workflow))
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		run := &mcallv1.McallWorkflowRun{}
+		if err := r.Get(ctx, types.NamespacedName{Name: runName, Namespace: workflow.Namespace}, run); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			// Run started before McallWorkflowRun existed, create it now
+			if err := r.createWorkflowRun(ctx, workflow); err != nil {
+				return err
+			}
+			if err := r.Get(ctx, types.NamespacedName{Name: runName, Namespace: workflow.Namespace}, run); err != nil {
+				return err
+			}
+		}
+
+		run.Status.Phase = workflow.Status.Phase
+		run.Status.StartTime = workflow.Status.StartTime
+		run.Status.CompletionTime = workflow.Status.CompletionTime
+		run.Status.Duration = workflow.Status.LastRunDuration
+		run.Status.TaskResults = results
+		run.Status.DAG = workflow.Status.DAG
+
+		return r.Status().Update(ctx, run)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info("Recorded workflow run", "workflow", workflow.Name, "run", runName, "phase", workflow.Status.Phase, "tasks", len(results))
+	return nil
+}
+
+// buildWorkflowRunTaskResults converts workflow task instances into run task results
+func buildWorkflowRunTaskResults(tasks []mcallv1.McallTask) []mcallv1.WorkflowRunTaskResult {
+	results := make([]mcallv1.WorkflowRunTaskResult, 0, len(tasks))
+	for _, task := range tasks {
+		name := task.Name
+		if taskName, exists := task.Labels["mcall.tz.io/task"]; exists {
+			name = taskName
+		}
+
+		result := mcallv1.WorkflowRunTaskResult{
+			Name:            name,
+			Phase:           task.Status.Phase,
+			StartTime:       task.Status.StartTime,
+			CompletionTime:  task.Status.CompletionTime,
+			ExecutionTimeMs: task.Status.ExecutionTimeMs,
+			HTTPStatusCode:  task.Status.HTTPStatusCode,
+			Tolerated:       toleratedFailure(&task),
+		}
+		if task.Status.Result != nil {
+			result.ErrorCode = task.Status.Result.ErrorCode
+			result.ErrorMessage = task.Status.Result.ErrorMessage
+			result.Output = truncateForUI(displayOutput(task.Status.Result), 500)
+		}
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
+
+// pruneRunHistory deletes archived runs beyond the successful/failed history limits
+func (r *McallWorkflowReconciler) pruneRunHistory(ctx context.Context, workflow *mcallv1.McallWorkflow) error {
+	log := log.FromContext(ctx)
+
+	var tasks mcallv1.McallTaskList
+	if err := r.List(ctx, &tasks,
+		client.InNamespace(workflow.Namespace),
+		client.MatchingLabels{"mcall.tz.io/history-of": workflow.Name}); err != nil {
+		return err
+	}
+
+	// Group archived task instances by run, per run outcome
+	runsByPhase := make(map[mcallv1.McallWorkflowPhase][]string)
+	tasksByRun := make(map[string][]mcallv1.McallTask)
+	for _, task := range tasks.Items {
+		runID := task.Labels["mcall.tz.io/run"]
+		if _, seen := tasksByRun[runID]; !seen {
+			phase := mcallv1.McallWorkflowPhase(task.Labels["mcall.tz.io/run-phase"])
+			runsByPhase[phase] = append(runsByPhase[phase], runID)
+		}
+		tasksByRun[runID] = append(tasksByRun[runID], task)
+	}
+
+	for phase, runIDs := range runsByPhase {
+		// Run IDs are timestamps, newest first
+		sort.Sort(sort.Reverse(sort.StringSlice(runIDs)))
+
+		limit := runHistoryLimit(workflow, phase)
+		if len(runIDs) <= limit {
+			continue
+		}
+
+		for _, runID := range runIDs[limit:] {
+			for _, task := range tasksByRun[runID] {
+				if err := r.Delete(ctx, &task); err != nil && !apierrors.IsNotFound(err) {
+					return err
+				}
+			}
+			log.Info("Pruned workflow run history", "workflow", workflow.Name, "run", runID, "phase", phase)
+		}
+	}
+
+	return nil
+}
+
 // sortTasksByDependencies sorts tasks by their dependencies (topological sort)
 func (r *McallWorkflowReconciler) sortTasksByDependencies(tasks []mcallv1.WorkflowTaskRef) []mcallv1.WorkflowTaskRef {
 	// Create a map of task names to tasks
@@ -515,6 +1140,16 @@ func (r *McallWorkflowReconciler) convertDependencies(workflowName string, depen
 	return converted
 }
 
+// convertConditions returns copies of the conditions depending on the workflow task names
+func (r *McallWorkflowReconciler) convertConditions(workflowName string, conditions []mcallv1.TaskCondition) []mcallv1.TaskCondition {
+	var converted []mcallv1.TaskCondition
+	for _, condition := range conditions {
+		condition.DependentTask = fmt.Sprintf("%s-%s", workflowName, condition.DependentTask)
+		converted = append(converted, condition)
+	}
+	return converted
+}
+
 func (r *McallWorkflowReconciler) checkWorkflowTasksStatus(ctx context.Context, workflow *mcallv1.McallWorkflow) (bool, bool, error) {
 	log := log.FromContext(ctx)
 
@@ -526,27 +1161,230 @@ func (r *McallWorkflowReconciler) checkWorkflowTasksStatus(ctx context.Context,
 
 	allCompleted := true
 	hasFailed := false
+	var tolerated int32
 
 	for _, task := range tasks.Items {
 		switch task.Status.Phase {
 		case mcallv1.McallTaskPhasePending, mcallv1.McallTaskPhaseRunning:
 			allCompleted = false
-		case mcallv1.McallTaskPhaseFailed:
-			hasFailed = true
+		case mcallv1.McallTaskPhaseFailed, mcallv1.McallTaskPhaseBlocked:
+			if toleratedFailure(&task) {
+				tolerated++
+			} else {
+				hasFailed = true
+			}
 		case mcallv1.McallTaskPhaseSucceeded:
 			// Task completed successfully
 		}
 	}
+	workflow.Status.ToleratedFailures = tolerated
 
-	log.Info("Workflow tasks status", "workflow", workflow.Name, "totalTasks", len(tasks.Items), "allCompleted", allCompleted, "hasFailed", hasFailed)
+	log.V(debugLevel).Info("Workflow tasks status", "workflow", workflow.Name, "totalTasks", len(tasks.Items), "allCompleted", allCompleted, "hasFailed", hasFailed, "tolerated", tolerated)
+	recordWorkflowRunningTasks(workflow, tasks.Items)
 
 	return allCompleted, hasFailed, nil
 }
 
+// continueOnAnnotation holds the continueOn setting of a workflow task instance
+const continueOnAnnotation = "mcall.tz.io/continue-on"
+
+// toleratedFailure reports whether a task instance failed with continueOn: failure
+func toleratedFailure(task *mcallv1.McallTask) bool {
+	return (task.Status.Phase == mcallv1.McallTaskPhaseFailed || task.Status.Phase == mcallv1.McallTaskPhaseBlocked) &&
+		task.Annotations[continueOnAnnotation] == "failure"
+}
+
+// skipPendingTasks skips the Pending task instances of a failed run of a failFast workflow.
+// onFailure handlers and exit tasks still run.
+func (r *McallWorkflowReconciler) skipPendingTasks(ctx context.Context, workflow *mcallv1.McallWorkflow) error {
+	log := log.FromContext(ctx)
+
+	var tasks mcallv1.McallTaskList
+	if err := r.List(ctx, &tasks, client.InNamespace(workflow.Namespace), client.MatchingLabels{"mcall.tz.io/workflow": workflow.Name}); err != nil {
+		return err
+	}
+
+	var failed []string
+	for _, task := range tasks.Items {
+		if (task.Status.Phase == mcallv1.McallTaskPhaseFailed || task.Status.Phase == mcallv1.McallTaskPhaseBlocked) && !toleratedFailure(&task) {
+			failed = append(failed, task.Name)
+		}
+	}
+	sort.Strings(failed)
+	message := fmt.Sprintf("Skipped due to failFast: %s failed", strings.Join(failed, ", "))
+
+	for i := range tasks.Items {
+		task := &tasks.Items[i]
+		if task.Status.Phase != mcallv1.McallTaskPhasePending {
+			continue
+		}
+		if _, isHandler := task.Labels[failureHandlerLabel]; isHandler {
+			continue
+		}
+		if _, isExit := task.Labels[exitTaskLabel]; isExit {
+			continue
+		}
+
+		if skipped, err := r.skipTask(ctx, task, message); err != nil {
+			return err
+		} else if skipped {
+			log.Info("Skipped task of failed run", "workflow", workflow.Name, "task", task.Name, "failed", failed)
+		}
+	}
+	return nil
+}
+
+// skipUnreachableTasks skips the Pending task instances of a run that can no longer start because
+// a dependency failed or was skipped, so the run and its exit tasks complete without failFast.
+// It reports whether any task was skipped.
+func (r *McallWorkflowReconciler) skipUnreachableTasks(ctx context.Context, workflow *mcallv1.McallWorkflow) (bool, error) {
+	log := log.FromContext(ctx)
+
+	var tasks mcallv1.McallTaskList
+	if err := r.List(ctx, &tasks, client.InNamespace(workflow.Namespace), client.MatchingLabels{"mcall.tz.io/workflow": workflow.Name}); err != nil {
+		return false, err
+	}
+
+	phases := make(map[string]mcallv1.McallTaskPhase, len(tasks.Items))
+	tolerated := make(map[string]bool)
+	for _, task := range tasks.Items {
+		phases[task.Name] = task.Status.Phase
+		tolerated[task.Name] = toleratedFailure(&task)
+	}
+
+	// Skipping a task makes its own dependents unreachable, until none is left
+	messages := make(map[string]string)
+	for changed := true; changed; {
+		changed = false
+		for _, task := range tasks.Items {
+			if phases[task.Name] != mcallv1.McallTaskPhasePending {
+				continue
+			}
+			if _, isHandler := task.Labels[failureHandlerLabel]; isHandler {
+				continue
+			}
+			if _, isExit := task.Labels[exitTaskLabel]; isExit {
+				continue
+			}
+			for _, dep := range task.Spec.Dependencies {
+				switch phase := phases[dep]; {
+				case (phase == mcallv1.McallTaskPhaseFailed || phase == mcallv1.McallTaskPhaseBlocked) && !tolerated[dep]:
+					messages[task.Name] = fmt.Sprintf("Skipped: dependency %s failed", dep)
+				case phase == mcallv1.McallTaskPhaseSkipped:
+					messages[task.Name] = fmt.Sprintf("Skipped: dependency %s was skipped", dep)
+				default:
+					continue
+				}
+				phases[task.Name] = mcallv1.McallTaskPhaseSkipped
+				changed = true
+				break
+			}
+		}
+	}
+
+	skippedAny := false
+	for i := range tasks.Items {
+		task := &tasks.Items[i]
+		message, unreachable := messages[task.Name]
+		if !unreachable {
+			continue
+		}
+		skipped, err := r.skipTask(ctx, task, message)
+		if err != nil {
+			return skippedAny, err
+		}
+		if skipped {
+			log.Info("Skipped unreachable task", "workflow", workflow.Name, "task", task.Name, "reason", message)
+			skippedAny = true
+		}
+	}
+	return skippedAny, nil
+}
+
+// skipTask marks a Pending task instance Skipped with a message. A task that changed in the
+// meantime isn't skipped, it is checked again on the next reconcile.
+func (r *McallWorkflowReconciler) skipTask(ctx context.Context, task *mcallv1.McallTask, message string) (bool, error) {
+	task.Status.Phase = mcallv1.McallTaskPhaseSkipped
+	task.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	task.Status.Result = &mcallv1.McallTaskResult{ErrorCode: "0", ErrorMessage: message}
+	setTaskConditions(task)
+	if err := r.Status().Update(ctx, task); err != nil {
+		if apierrors.IsConflict(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	recordEvent(r.Recorder, task, corev1.EventTypeNormal, EventReasonSkipped, "%s", message)
+	return true, nil
+}
+
+// exportWorkflowReport exports the results of a completed workflow run if a report sink is configured
+func (r *McallWorkflowReconciler) exportWorkflowReport(ctx context.Context, workflow *mcallv1.McallWorkflow) {
+	log := log.FromContext(ctx)
+
+	config := GetReportSinkConfig()
+	if !config.Enabled {
+		return
+	}
+
+	var tasks mcallv1.McallTaskList
+	if err := r.List(ctx, &tasks, client.InNamespace(workflow.Namespace), client.MatchingLabels{"mcall.tz.io/workflow": workflow.Name}); err != nil {
+		log.Error(err, "Failed to list workflow tasks for report", "workflow", workflow.Name)
+		return
+	}
+
+	report := buildWorkflowReport(workflow, tasks.Items)
+	if err := ExportReport(report, config); err != nil {
+		log.Error(err, "Failed to export workflow report", "workflow", workflow.Name, "sink", config.Type)
+		return
+	}
+
+	log.Info("Exported workflow report", "workflow", workflow.Name, "sink", config.Type, "rows", len(report.Rows))
+}
+
+// notifyWorkflowResult sends the failure notification of a failed workflow run, or its recovery
+// once a run succeeds, if notifiers are configured
+func (r *McallWorkflowReconciler) notifyWorkflowResult(ctx context.Context, workflow *mcallv1.McallWorkflow) {
+	log := log.FromContext(ctx)
+
+	config, err := notifierConfigFor(ctx, r.Client, workflow)
+	if err != nil {
+		log.Error(err, "Failed to get notifier configuration", "workflow", workflow.Name)
+		return
+	}
+	if !config.Enabled {
+		return
+	}
+
+	if workflow.Status.Phase != mcallv1.McallWorkflowPhaseFailed {
+		downtime, err := NotifyRecovery(workflowFailureNotification(workflow, nil), config)
+		if err != nil {
+			log.Error(err, "Failed to send workflow recovery notification", "workflow", workflow.Name, "notifiers", config.Types)
+		} else if downtime > 0 {
+			log.Info("Sent workflow recovery notification", "workflow", workflow.Name, "downtime", downtime, "notifiers", config.Types)
+		}
+		return
+	}
+
+	var tasks mcallv1.McallTaskList
+	if err := r.List(ctx, &tasks, client.InNamespace(workflow.Namespace), client.MatchingLabels{"mcall.tz.io/workflow": workflow.Name}); err != nil {
+		log.Error(err, "Failed to list workflow tasks for notification", "workflow", workflow.Name)
+		return
+	}
+
+	if err := NotifyFailure(workflowFailureNotification(workflow, tasks.Items), config); err != nil {
+		log.Error(err, "Failed to send workflow failure notification", "workflow", workflow.Name, "notifiers", config.Types)
+		return
+	}
+
+	log.Info("Sent workflow failure notification", "workflow", workflow.Name, "notifiers", config.Types)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *McallWorkflowReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&mcallv1.McallWorkflow{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
 
@@ -638,7 +1476,7 @@ func (r *McallWorkflowReconciler) buildWorkflowDAG(ctx context.Context, workflow
 
 			// Task result
 			if task.Status.Result != nil {
-				node.Output = truncateForUI(task.Status.Result.Output, 500)
+				node.Output = truncateForUI(displayOutput(task.Status.Result), 500)
 				node.ErrorCode = task.Status.Result.ErrorCode
 				node.ErrorMessage = task.Status.Result.ErrorMessage
 			}
@@ -652,7 +1490,7 @@ func (r *McallWorkflowReconciler) buildWorkflowDAG(ctx context.Context, workflow
 			switch node.Phase {
 			case mcallv1.McallTaskPhaseSucceeded:
 				dag.Metadata.SuccessCount++
-			case mcallv1.McallTaskPhaseFailed:
+			case mcallv1.McallTaskPhaseFailed, mcallv1.McallTaskPhaseBlocked:
 				dag.Metadata.FailureCount++
 			case mcallv1.McallTaskPhaseRunning:
 				dag.Metadata.RunningCount++
@@ -681,29 +1519,41 @@ func (r *McallWorkflowReconciler) buildWorkflowDAG(ctx context.Context, workflow
 
 			// Add condition information
 			if taskSpec.Condition != nil {
-				edge.Type = taskSpec.Condition.When
-				edge.Condition = taskSpec.Condition.When
-				switch taskSpec.Condition.When {
-				case "success":
-					edge.Label = "✓"
-				case "failure":
-					edge.Label = "✗"
-				case "always":
-					edge.Label = "*"
-				default:
-					edge.Label = taskSpec.Condition.When
+				setConditionEdge(&edge, taskSpec.Condition.When)
+			}
+			if taskSpec.Conditions != nil {
+				for _, condition := range append(append([]mcallv1.TaskCondition{}, taskSpec.Conditions.AllOf...), taskSpec.Conditions.AnyOf...) {
+					if condition.DependentTask == dep {
+						setConditionEdge(&edge, condition.When)
+					}
 				}
 			}
 
 			dag.Edges = append(dag.Edges, edge)
 			dag.Metadata.TotalEdges++
 		}
+
+		// Data flow edges from InputSources, tasks outside the workflow have no node
+		for _, source := range taskSpec.InputSources {
+			if externalInputSource(source) {
+				continue
+			}
+			dag.Edges = append(dag.Edges, dataFlowEdge(source, taskSpec.Name))
+			dag.Metadata.TotalEdges++
+		}
+
+		// Data flow edge of the items of a fan-out
+		if taskSpec.WithParam != nil {
+			source := mcallv1.TaskInputSource{Name: "item", TaskRef: taskSpec.WithParam.TaskRef, Field: "output", JSONPath: taskSpec.WithParam.JSONPath}
+			dag.Edges = append(dag.Edges, dataFlowEdge(source, taskSpec.Name))
+			dag.Metadata.TotalEdges++
+		}
 	}
 
 	// Update workflow status
 	workflow.Status.DAG = dag
 
-	log.Info("🎨 Built workflow DAG",
+	log.V(debugLevel).Info("Built workflow DAG",
 		"workflow", workflow.Name,
 		"nodes", len(dag.Nodes),
 		"edges", len(dag.Edges),
@@ -714,7 +1564,7 @@ func (r *McallWorkflowReconciler) buildWorkflowDAG(ctx context.Context, workflow
 
 	// Log detailed edge information
 	for i, edge := range dag.Edges {
-		log.Info("🔗 DAG Edge",
+		log.V(debugLevel).Info("DAG edge",
 			"workflow", workflow.Name,
 			"edgeIndex", i,
 			"source", edge.Source,
@@ -727,6 +1577,38 @@ func (r *McallWorkflowReconciler) buildWorkflowDAG(ctx context.Context, workflow
 	return nil
 }
 
+// setConditionEdge marks a dependency edge with the when value of the condition of its target
+func setConditionEdge(edge *mcallv1.DAGEdge, when string) {
+	edge.Type = when
+	edge.Condition = when
+	switch when {
+	case "success":
+		edge.Label = "✓"
+	case "failure":
+		edge.Label = "✗"
+	case "always":
+		edge.Label = "*"
+	default:
+		edge.Label = when
+	}
+}
+
+// dataFlowEdge builds the DAG edge for data passed from an InputSource task to the consuming task
+func dataFlowEdge(source mcallv1.TaskInputSource, target string) mcallv1.DAGEdge {
+	field := source.Field
+	if source.JSONPath != "" {
+		field = fmt.Sprintf("%s %s", field, source.JSONPath)
+	}
+
+	return mcallv1.DAGEdge{
+		ID:     fmt.Sprintf("%s-%s-data-%s", source.TaskRef, target, source.Name),
+		Source: source.TaskRef,
+		Target: target,
+		Type:   "dataflow",
+		Label:  fmt.Sprintf("%s ← %s", source.Name, field),
+	}
+}
+
 // calculateNodePositions calculates positions for nodes in a simple layered layout
 func (r *McallWorkflowReconciler) calculateNodePositions(workflow *mcallv1.McallWorkflow) map[string]mcallv1.NodePosition {
 	positions := make(map[string]mcallv1.NodePosition)
@@ -7,6 +7,8 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -50,6 +52,7 @@ var _ = Describe("McallWorkflow Controller", func() {
 			WithScheme(scheme).
 			WithStatusSubresource(&mcallv1.McallWorkflow{}).
 			WithStatusSubresource(&mcallv1.McallTask{}).
+			WithStatusSubresource(&mcallv1.McallWorkflowRun{}).
 			Build()
 
 		// Setup reconciler
@@ -314,6 +317,10 @@ var _ = Describe("McallWorkflow Controller", func() {
 				Namespace: "default",
 			}, &updatedWorkflow)).To(Succeed())
 			Expect(updatedWorkflow.Status.Phase).To(Equal(mcallv1.McallWorkflowPhaseRunning))
+			Expect(updatedWorkflow.Status.LastRunTime).ToNot(BeNil())
+			Expect(updatedWorkflow.Status.NextRunTime).ToNot(BeNil())
+			Expect(updatedWorkflow.Status.NextRunTime.Hour()).To(Equal(2))
+			Expect(updatedWorkflow.Status.NextRunTime.Minute()).To(Equal(0))
 		})
 	})
 
@@ -451,6 +458,830 @@ var _ = Describe("McallWorkflow Controller", func() {
 		})
 	})
 
+	Context("Tolerated Failures", func() {
+		It("should succeed when only continueOn failure tasks failed", func() {
+			startTime := metav1.NewTime(time.Now().Add(-time.Minute))
+			workflow := &mcallv1.McallWorkflow{
+				ObjectMeta: metav1.ObjectMeta{Name: "optional-workflow", Namespace: "default"},
+				Spec: mcallv1.McallWorkflowSpec{
+					Tasks: []mcallv1.WorkflowTaskRef{
+						{Name: "check", TaskRef: mcallv1.TaskRef{Name: "check-ref"}},
+						{Name: "flaky", TaskRef: mcallv1.TaskRef{Name: "flaky-ref"}, ContinueOn: "failure"},
+					},
+				},
+				Status: mcallv1.McallWorkflowStatus{Phase: mcallv1.McallWorkflowPhaseRunning, StartTime: &startTime},
+			}
+			Expect(mockClient.Create(ctx, workflow)).To(Succeed())
+
+			for name, phase := range map[string]mcallv1.McallTaskPhase{
+				"check": mcallv1.McallTaskPhaseSucceeded,
+				"flaky": mcallv1.McallTaskPhaseFailed,
+			} {
+				task := &mcallv1.McallTask{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "optional-workflow-" + name,
+						Namespace: "default",
+						Labels:    map[string]string{"mcall.tz.io/workflow": "optional-workflow", "mcall.tz.io/task": name},
+					},
+					Spec:   mcallv1.McallTaskSpec{Type: "cmd", Input: "echo " + name},
+					Status: mcallv1.McallTaskStatus{Phase: phase},
+				}
+				if name == "flaky" {
+					task.Annotations = map[string]string{continueOnAnnotation: "failure"}
+				}
+				Expect(mockClient.Create(ctx, task)).To(Succeed())
+			}
+
+			request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "optional-workflow", Namespace: "default"}}
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(mockClient.Get(ctx, request.NamespacedName, workflow)).To(Succeed())
+			Expect(workflow.Status.Phase).To(Equal(mcallv1.McallWorkflowPhaseSucceeded))
+			Expect(workflow.Status.ToleratedFailures).To(Equal(int32(1)))
+
+			var run mcallv1.McallWorkflowRun
+			Expect(mockClient.Get(ctx, types.NamespacedName{Name: workflowRunName("optional-workflow", workflowRunID(workflow)), Namespace: "default"}, &run)).To(Succeed())
+			Expect(run.Status.TaskResults).To(HaveLen(2))
+			Expect(run.Status.TaskResults[0].Tolerated).To(BeFalse())
+			Expect(run.Status.TaskResults[1].Name).To(Equal("flaky"))
+			Expect(run.Status.TaskResults[1].Tolerated).To(BeTrue())
+		})
+	})
+
+	Context("Tolerated Failure Dependencies", func() {
+		It("should run the dependents of a continueOn failure task and finish the workflow", func() {
+			startTime := metav1.NewTime(time.Now().Add(-time.Minute))
+			workflow := &mcallv1.McallWorkflow{
+				ObjectMeta: metav1.ObjectMeta{Name: "tolerant-workflow", Namespace: "default"},
+				Spec: mcallv1.McallWorkflowSpec{
+					Tasks: []mcallv1.WorkflowTaskRef{
+						{Name: "a", TaskRef: mcallv1.TaskRef{Name: "a-ref"}, ContinueOn: "failure"},
+						{Name: "b", TaskRef: mcallv1.TaskRef{Name: "b-ref"}, Dependencies: []string{"a"}},
+					},
+				},
+				Status: mcallv1.McallWorkflowStatus{Phase: mcallv1.McallWorkflowPhaseRunning, StartTime: &startTime},
+			}
+			Expect(mockClient.Create(ctx, workflow)).To(Succeed())
+
+			a := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "tolerant-workflow-a",
+					Namespace:   "default",
+					Labels:      map[string]string{"mcall.tz.io/workflow": "tolerant-workflow", "mcall.tz.io/task": "a"},
+					Annotations: map[string]string{continueOnAnnotation: "failure"},
+				},
+				Spec: mcallv1.McallTaskSpec{Type: "cmd", Input: "false"},
+				Status: mcallv1.McallTaskStatus{
+					Phase:  mcallv1.McallTaskPhaseFailed,
+					Result: &mcallv1.McallTaskResult{ErrorCode: "-1", ErrorMessage: "exit status 1"},
+				},
+			}
+			b := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "tolerant-workflow-b",
+					Namespace: "default",
+					Labels:    map[string]string{"mcall.tz.io/workflow": "tolerant-workflow", "mcall.tz.io/task": "b"},
+				},
+				Spec:   mcallv1.McallTaskSpec{Type: "cmd", Input: "echo b", Dependencies: []string{"tolerant-workflow-a"}},
+				Status: mcallv1.McallTaskStatus{Phase: mcallv1.McallTaskPhasePending},
+			}
+			Expect(mockClient.Create(ctx, a)).To(Succeed())
+			Expect(mockClient.Create(ctx, b)).To(Succeed())
+
+			// The tolerated failure satisfies the dependency of b, which starts
+			taskReconciler := &McallTaskReconciler{Client: mockClient, Scheme: scheme}
+			_, err := taskReconciler.handlePending(ctx, b)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mockClient.Get(ctx, types.NamespacedName{Name: b.Name, Namespace: "default"}, b)).To(Succeed())
+			Expect(b.Status.Phase).ToNot(Equal(mcallv1.McallTaskPhasePending))
+
+			b.Status.Phase = mcallv1.McallTaskPhaseSucceeded
+			Expect(mockClient.Status().Update(ctx, b)).To(Succeed())
+
+			request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "tolerant-workflow", Namespace: "default"}}
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(mockClient.Get(ctx, request.NamespacedName, workflow)).To(Succeed())
+			Expect(workflow.Status.Phase).To(Equal(mcallv1.McallWorkflowPhaseSucceeded))
+			Expect(workflow.Status.ToleratedFailures).To(Equal(int32(1)))
+		})
+	})
+
+	Context("Retry Failed", func() {
+		It("should run the failed and skipped tasks of a failed run again and keep the succeeded ones", func() {
+			startTime := metav1.NewTime(time.Now().Add(-time.Minute))
+			completionTime := metav1.Now()
+			workflow := &mcallv1.McallWorkflow{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "retry-workflow",
+					Namespace:   "default",
+					Annotations: map[string]string{mcallv1.RetryFailedAnnotation: ""},
+				},
+				Spec: mcallv1.McallWorkflowSpec{
+					Tasks: []mcallv1.WorkflowTaskRef{
+						{Name: "fetch", TaskRef: mcallv1.TaskRef{Name: "fetch-ref"}},
+						{Name: "process", TaskRef: mcallv1.TaskRef{Name: "process-ref"}, Dependencies: []string{"fetch"},
+							OnFailure: &mcallv1.TaskRef{Name: "alert-ref"}},
+						{Name: "publish", TaskRef: mcallv1.TaskRef{Name: "publish-ref"}, Dependencies: []string{"process"}},
+					},
+					OnExit: []mcallv1.WorkflowTaskRef{
+						{Name: "cleanup", TaskRef: mcallv1.TaskRef{Name: "cleanup-ref"}},
+					},
+				},
+				Status: mcallv1.McallWorkflowStatus{
+					Phase:           mcallv1.McallWorkflowPhaseFailed,
+					StartTime:       &startTime,
+					CompletionTime:  &completionTime,
+					OnExitStartTime: &completionTime,
+				},
+			}
+			Expect(mockClient.Create(ctx, workflow)).To(Succeed())
+
+			for name, phase := range map[string]mcallv1.McallTaskPhase{
+				"fetch":              mcallv1.McallTaskPhaseSucceeded,
+				"process":            mcallv1.McallTaskPhaseFailed,
+				"process-on-failure": mcallv1.McallTaskPhaseSucceeded,
+				"publish":            mcallv1.McallTaskPhaseSkipped,
+				"cleanup":            mcallv1.McallTaskPhaseSucceeded,
+			} {
+				task := &mcallv1.McallTask{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "retry-workflow-" + name,
+						Namespace: "default",
+						Labels:    map[string]string{"mcall.tz.io/workflow": "retry-workflow", "mcall.tz.io/task": name},
+					},
+					Spec: mcallv1.McallTaskSpec{Type: "cmd", Input: "echo " + name},
+					Status: mcallv1.McallTaskStatus{
+						Phase:  phase,
+						Result: &mcallv1.McallTaskResult{ErrorCode: "0", Output: name},
+					},
+				}
+				switch name {
+				case "process-on-failure":
+					task.Labels[failureHandlerLabel] = "process"
+				case "cleanup":
+					task.Labels[exitTaskLabel] = "true"
+				}
+				Expect(mockClient.Create(ctx, task)).To(Succeed())
+			}
+
+			request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "retry-workflow", Namespace: "default"}}
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(mockClient.Get(ctx, request.NamespacedName, workflow)).To(Succeed())
+			Expect(workflow.Status.Phase).To(Equal(mcallv1.McallWorkflowPhaseRunning))
+			Expect(workflow.Status.CompletionTime).To(BeNil())
+			Expect(workflow.Status.OnExitStartTime).To(BeNil())
+			Expect(workflow.Status.RetryCount).To(Equal(int32(1)))
+			Expect(workflow.Status.StartTime.Time.Unix()).To(Equal(startTime.Time.Unix()))
+			Expect(workflow.Annotations).ToNot(HaveKey(mcallv1.RetryFailedAnnotation))
+
+			for name, phase := range map[string]mcallv1.McallTaskPhase{
+				"fetch":              mcallv1.McallTaskPhaseSucceeded,
+				"process":            mcallv1.McallTaskPhasePending,
+				"process-on-failure": mcallv1.McallTaskPhasePending,
+				"publish":            mcallv1.McallTaskPhasePending,
+			} {
+				var task mcallv1.McallTask
+				Expect(mockClient.Get(ctx, types.NamespacedName{Name: "retry-workflow-" + name, Namespace: "default"}, &task)).To(Succeed())
+				Expect(task.Status.Phase).To(Equal(phase), name)
+				if phase == mcallv1.McallTaskPhasePending {
+					Expect(task.Status.Result).To(BeNil(), name)
+				} else {
+					Expect(task.Status.Result.Output).To(Equal(name))
+				}
+			}
+
+			// Exit tasks run again with the new outcome of the run
+			var cleanup mcallv1.McallTask
+			err = mockClient.Get(ctx, types.NamespacedName{Name: "retry-workflow-cleanup", Namespace: "default"}, &cleanup)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should ignore a retry of a run that succeeded", func() {
+			startTime := metav1.NewTime(time.Now().Add(-time.Minute))
+			workflow := &mcallv1.McallWorkflow{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "retry-succeeded-workflow",
+					Namespace:   "default",
+					Annotations: map[string]string{mcallv1.RetryFailedAnnotation: ""},
+				},
+				Spec: mcallv1.McallWorkflowSpec{
+					Tasks: []mcallv1.WorkflowTaskRef{{Name: "check", TaskRef: mcallv1.TaskRef{Name: "check-ref"}}},
+				},
+				Status: mcallv1.McallWorkflowStatus{Phase: mcallv1.McallWorkflowPhaseSucceeded, StartTime: &startTime},
+			}
+			Expect(mockClient.Create(ctx, workflow)).To(Succeed())
+
+			request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "retry-succeeded-workflow", Namespace: "default"}}
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(mockClient.Get(ctx, request.NamespacedName, workflow)).To(Succeed())
+			Expect(workflow.Status.Phase).To(Equal(mcallv1.McallWorkflowPhaseSucceeded))
+			Expect(workflow.Status.RetryCount).To(BeZero())
+			Expect(workflow.Annotations).ToNot(HaveKey(mcallv1.RetryFailedAnnotation))
+		})
+	})
+
+	Context("Restart", func() {
+		It("should stop the running run and start a new one regardless of the schedule", func() {
+			Expect(mockClient.Create(ctx, &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{Name: "check-ref", Namespace: "default"},
+				Spec:       mcallv1.McallTaskSpec{Type: "cmd", Input: "echo ok"},
+			})).To(Succeed())
+
+			startTime := metav1.NewTime(time.Now().Add(-time.Minute))
+			workflow := &mcallv1.McallWorkflow{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "restart-workflow",
+					Namespace:   "default",
+					Annotations: map[string]string{mcallv1.RestartAnnotation: "true"},
+				},
+				Spec: mcallv1.McallWorkflowSpec{
+					Schedule: "0 0 1 1 *",
+					Tasks:    []mcallv1.WorkflowTaskRef{{Name: "check", TaskRef: mcallv1.TaskRef{Name: "check-ref"}}},
+				},
+				Status: mcallv1.McallWorkflowStatus{
+					Phase:       mcallv1.McallWorkflowPhaseRunning,
+					StartTime:   &startTime,
+					LastRunTime: &startTime,
+				},
+			}
+			Expect(mockClient.Create(ctx, workflow)).To(Succeed())
+			Expect(mockClient.Create(ctx, &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "restart-workflow-check",
+					Namespace: "default",
+					Labels:    map[string]string{"mcall.tz.io/workflow": "restart-workflow", "mcall.tz.io/task": "check"},
+				},
+				Spec:   mcallv1.McallTaskSpec{Type: "cmd", Input: "echo ok"},
+				Status: mcallv1.McallTaskStatus{Phase: mcallv1.McallTaskPhaseRunning},
+			})).To(Succeed())
+			previousRunName := workflowRunName("restart-workflow", workflowRunID(workflow))
+
+			request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "restart-workflow", Namespace: "default"}}
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(mockClient.Get(ctx, request.NamespacedName, workflow)).To(Succeed())
+			Expect(workflow.Status.Phase).To(Equal(mcallv1.McallWorkflowPhasePending))
+			Expect(workflow.Status.StartTime).To(BeNil())
+			Expect(workflow.Status.RestartCount).To(Equal(int32(1)))
+			Expect(workflow.Status.LastRestartTime).ToNot(BeNil())
+			Expect(workflow.Annotations).ToNot(HaveKey(mcallv1.RestartAnnotation))
+			Expect(workflow.Annotations).To(HaveKey(mcallv1.TriggerAnnotation))
+
+			var tasks mcallv1.McallTaskList
+			Expect(mockClient.List(ctx, &tasks, client.MatchingLabels{"mcall.tz.io/workflow": "restart-workflow"})).To(Succeed())
+			Expect(tasks.Items).To(BeEmpty())
+
+			// The stopped run is recorded as Failed
+			var previousRun mcallv1.McallWorkflowRun
+			Expect(mockClient.Get(ctx, types.NamespacedName{Name: previousRunName, Namespace: "default"}, &previousRun)).To(Succeed())
+			Expect(previousRun.Status.Phase).To(Equal(mcallv1.McallWorkflowPhaseFailed))
+
+			// The new run starts right away as a manual run
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(mockClient.Get(ctx, request.NamespacedName, workflow)).To(Succeed())
+			Expect(workflow.Status.Phase).To(Equal(mcallv1.McallWorkflowPhaseRunning))
+			Expect(workflow.Status.StartTime.Time).To(BeTemporally(">", startTime.Time))
+			Expect(workflow.Annotations).ToNot(HaveKey(mcallv1.TriggerAnnotation))
+			Expect(mockClient.List(ctx, &tasks, client.MatchingLabels{"mcall.tz.io/workflow": "restart-workflow"})).To(Succeed())
+			Expect(tasks.Items).To(HaveLen(1))
+
+			var run mcallv1.McallWorkflowRun
+			Expect(mockClient.Get(ctx, types.NamespacedName{Name: workflowRunName("restart-workflow", workflowRunID(workflow)), Namespace: "default"}, &run)).To(Succeed())
+			Expect(run.Spec.Trigger).To(Equal("manual"))
+		})
+	})
+
+	Context("Run History", func() {
+		It("should keep task instances of recent runs up to the history limit", func() {
+			limit := int32(1)
+			startTime := metav1.NewTime(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+			workflow := &mcallv1.McallWorkflow{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "history-workflow",
+					Namespace: "default",
+				},
+				Spec: mcallv1.McallWorkflowSpec{
+					Schedule:                   "@every 1m",
+					SuccessfulRunsHistoryLimit: &limit,
+					Tasks: []mcallv1.WorkflowTaskRef{
+						{Name: "check", TaskRef: mcallv1.TaskRef{Name: "check-ref", Namespace: "default"}},
+					},
+				},
+			}
+			Expect(mockClient.Create(ctx, workflow)).To(Succeed())
+			workflow.Status = mcallv1.McallWorkflowStatus{
+				Phase:     mcallv1.McallWorkflowPhaseSucceeded,
+				StartTime: &startTime,
+			}
+			Expect(mockClient.Status().Update(ctx, workflow)).To(Succeed())
+
+			// Task instance of the run that just completed
+			Expect(mockClient.Create(ctx, &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "history-workflow-check",
+					Namespace: "default",
+					Labels: map[string]string{
+						"mcall.tz.io/workflow": "history-workflow",
+						"mcall.tz.io/task":     "check",
+					},
+				},
+				Spec: mcallv1.McallTaskSpec{Type: "cmd", Input: "echo ok"},
+			})).To(Succeed())
+
+			// Archived task instance of an older successful run
+			Expect(mockClient.Create(ctx, &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "history-workflow-check-20250101-000000",
+					Namespace: "default",
+					Labels: map[string]string{
+						"mcall.tz.io/history-of": "history-workflow",
+						"mcall.tz.io/run":        "20250101-000000",
+						"mcall.tz.io/run-phase":  "Succeeded",
+						"mcall.tz.io/task":       "check",
+					},
+				},
+				Spec: mcallv1.McallTaskSpec{Type: "cmd", Input: "echo ok"},
+			})).To(Succeed())
+
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: "history-workflow", Namespace: "default"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			var history mcallv1.McallTaskList
+			Expect(mockClient.List(ctx, &history, client.MatchingLabels{"mcall.tz.io/history-of": "history-workflow"})).To(Succeed())
+			Expect(history.Items).To(HaveLen(1))
+			Expect(history.Items[0].Name).To(Equal("history-workflow-check-20250102-030405"))
+
+			var current mcallv1.McallTaskList
+			Expect(mockClient.List(ctx, &current, client.MatchingLabels{"mcall.tz.io/workflow": "history-workflow"})).To(Succeed())
+			Expect(current.Items).To(BeEmpty())
+		})
+	})
+
+	Context("Suspend", func() {
+		It("should not start runs while suspended and record the resume time", func() {
+			workflow := &mcallv1.McallWorkflow{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "suspended-workflow",
+					Namespace: "default",
+				},
+				Spec: mcallv1.McallWorkflowSpec{
+					Schedule: "@every 1m",
+					Suspend:  true,
+					Tasks: []mcallv1.WorkflowTaskRef{
+						{Name: "check", TaskRef: mcallv1.TaskRef{Name: "check-ref", Namespace: "default"}},
+					},
+				},
+			}
+			Expect(mockClient.Create(ctx, workflow)).To(Succeed())
+			workflow.Status.Phase = mcallv1.McallWorkflowPhasePending
+			Expect(mockClient.Status().Update(ctx, workflow)).To(Succeed())
+
+			key := types.NamespacedName{Name: "suspended-workflow", Namespace: "default"}
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			Expect(err).ToNot(HaveOccurred())
+
+			var updated mcallv1.McallWorkflow
+			Expect(mockClient.Get(ctx, key, &updated)).To(Succeed())
+			Expect(updated.Status.Phase).To(Equal(mcallv1.McallWorkflowPhasePending))
+			Expect(updated.Status.SuspendedTime).ToNot(BeNil())
+
+			var tasks mcallv1.McallTaskList
+			Expect(mockClient.List(ctx, &tasks, client.MatchingLabels{"mcall.tz.io/workflow": "suspended-workflow"})).To(Succeed())
+			Expect(tasks.Items).To(BeEmpty())
+
+			// Resume without backfill
+			updated.Spec.Suspend = false
+			Expect(mockClient.Update(ctx, &updated)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(mockClient.Get(ctx, key, &updated)).To(Succeed())
+			Expect(updated.Status.SuspendedTime).To(BeNil())
+			Expect(updated.Status.ResumedTime).ToNot(BeNil())
+		})
+
+		It("should not start runs of workflows with dependency cycles", func() {
+			workflow := &mcallv1.McallWorkflow{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cyclic-workflow",
+					Namespace: "default",
+				},
+				Spec: mcallv1.McallWorkflowSpec{
+					Tasks: []mcallv1.WorkflowTaskRef{
+						{Name: "a", TaskRef: mcallv1.TaskRef{Name: "a-ref", Namespace: "default"}, Dependencies: []string{"b"}},
+						{Name: "b", TaskRef: mcallv1.TaskRef{Name: "b-ref", Namespace: "default"}, Dependencies: []string{"a"}},
+					},
+				},
+			}
+			Expect(mockClient.Create(ctx, workflow)).To(Succeed())
+			workflow.Status.Phase = mcallv1.McallWorkflowPhasePending
+			Expect(mockClient.Status().Update(ctx, workflow)).To(Succeed())
+
+			key := types.NamespacedName{Name: "cyclic-workflow", Namespace: "default"}
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			Expect(err).ToNot(HaveOccurred())
+
+			var updated mcallv1.McallWorkflow
+			Expect(mockClient.Get(ctx, key, &updated)).To(Succeed())
+			Expect(updated.Status.Phase).To(Equal(mcallv1.McallWorkflowPhasePending))
+			Expect(updated.Status.Reason).To(Equal(invalidDependenciesReason))
+			Expect(updated.Status.Message).To(Equal("dependency cycle: a -> b -> a"))
+			ready := meta.FindStatusCondition(updated.Status.Conditions, mcallv1.ReadyCondition)
+			Expect(ready).ToNot(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+
+			var tasks mcallv1.McallTaskList
+			Expect(mockClient.List(ctx, &tasks, client.MatchingLabels{"mcall.tz.io/workflow": "cyclic-workflow"})).To(Succeed())
+			Expect(tasks.Items).To(BeEmpty())
+		})
+	})
+
+	Context("Workflow Runs", func() {
+		It("should record the results of a completed run in a McallWorkflowRun", func() {
+			startTime := metav1.NewTime(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+			workflow := &mcallv1.McallWorkflow{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "run-workflow",
+					Namespace: "default",
+				},
+				Spec: mcallv1.McallWorkflowSpec{
+					Schedule: "@every 1m",
+					Tasks: []mcallv1.WorkflowTaskRef{
+						{Name: "check", TaskRef: mcallv1.TaskRef{Name: "check-ref", Namespace: "default"}},
+					},
+				},
+			}
+			Expect(mockClient.Create(ctx, workflow)).To(Succeed())
+			workflow.Status = mcallv1.McallWorkflowStatus{
+				Phase:     mcallv1.McallWorkflowPhaseRunning,
+				StartTime: &startTime,
+			}
+			Expect(mockClient.Status().Update(ctx, workflow)).To(Succeed())
+
+			task := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "run-workflow-check",
+					Namespace: "default",
+					Labels: map[string]string{
+						"mcall.tz.io/workflow": "run-workflow",
+						"mcall.tz.io/task":     "check",
+					},
+				},
+				Spec: mcallv1.McallTaskSpec{Type: "cmd", Input: "echo ok"},
+			}
+			Expect(mockClient.Create(ctx, task)).To(Succeed())
+			task.Status = mcallv1.McallTaskStatus{
+				Phase:  mcallv1.McallTaskPhaseSucceeded,
+				Result: &mcallv1.McallTaskResult{Output: "ok", ErrorCode: "0"},
+			}
+			Expect(mockClient.Status().Update(ctx, task)).To(Succeed())
+
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: "run-workflow", Namespace: "default"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			var run mcallv1.McallWorkflowRun
+			Expect(mockClient.Get(ctx, types.NamespacedName{Name: "run-workflow-20250102-030405", Namespace: "default"}, &run)).To(Succeed())
+			Expect(run.Spec.WorkflowRef).To(Equal("run-workflow"))
+			Expect(run.Spec.Trigger).To(Equal("schedule"))
+			Expect(run.Status.Phase).To(Equal(mcallv1.McallWorkflowPhaseSucceeded))
+			Expect(run.Status.CompletionTime).ToNot(BeNil())
+			Expect(run.Status.DAG).ToNot(BeNil())
+			Expect(run.Status.TaskResults).To(HaveLen(1))
+			Expect(run.Status.TaskResults[0].Name).To(Equal("check"))
+			Expect(run.Status.TaskResults[0].Output).To(Equal("ok"))
+		})
+
+		It("should start a triggered run with the parameter overrides of the trigger", func() {
+			template := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{Name: "probe-ref", Namespace: "default"},
+				Spec:       mcallv1.McallTaskSpec{Type: "get", Input: "https://${params.region}.example.com${params.path}"},
+			}
+			Expect(mockClient.Create(ctx, template)).To(Succeed())
+
+			startTime := metav1.NewTime(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+			workflow := &mcallv1.McallWorkflow{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "trigger-workflow",
+					Namespace: "default",
+					Annotations: map[string]string{
+						mcallv1.ParametersAnnotation: `{"path": "/ready"}`,
+						mcallv1.TriggerAnnotation:    `{"region": "eu"}`,
+					},
+				},
+				Spec: mcallv1.McallWorkflowSpec{
+					Parameters: []mcallv1.WorkflowParameter{
+						{Name: "region", Default: "us"},
+						{Name: "path", Default: "/health"},
+					},
+					Tasks: []mcallv1.WorkflowTaskRef{
+						{Name: "probe", TaskRef: mcallv1.TaskRef{Name: "probe-ref", Namespace: "default"}},
+					},
+				},
+			}
+			Expect(mockClient.Create(ctx, workflow)).To(Succeed())
+			// The last run completed, the trigger starts a new one
+			workflow.Status = mcallv1.McallWorkflowStatus{
+				Phase:          mcallv1.McallWorkflowPhaseSucceeded,
+				StartTime:      &startTime,
+				CompletionTime: &startTime,
+			}
+			Expect(mockClient.Status().Update(ctx, workflow)).To(Succeed())
+
+			request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "trigger-workflow", Namespace: "default"}}
+			for i := 0; i < 2; i++ {
+				_, err := reconciler.Reconcile(ctx, request)
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			Expect(mockClient.Get(ctx, request.NamespacedName, workflow)).To(Succeed())
+			Expect(workflow.Status.Phase).To(Equal(mcallv1.McallWorkflowPhaseRunning))
+			Expect(workflow.Status.Parameters).To(Equal(map[string]string{"region": "eu", "path": "/ready"}))
+			Expect(workflow.Annotations).ToNot(HaveKey(mcallv1.TriggerAnnotation))
+			Expect(workflow.Annotations).To(HaveKey(mcallv1.ParametersAnnotation))
+
+			var task mcallv1.McallTask
+			Expect(mockClient.Get(ctx, types.NamespacedName{Name: "trigger-workflow-probe", Namespace: "default"}, &task)).To(Succeed())
+			Expect(task.Spec.Input).To(Equal("https://eu.example.com/ready"))
+
+			var run mcallv1.McallWorkflowRun
+			Expect(mockClient.Get(ctx, types.NamespacedName{Name: workflowRunName("trigger-workflow", workflowRunID(workflow)), Namespace: "default"}, &run)).To(Succeed())
+			Expect(run.Spec.Trigger).To(Equal("manual"))
+			Expect(run.Status.Parameters).To(Equal(map[string]string{"region": "eu", "path": "/ready"}))
+		})
+	})
+
+	Context("Exit Tasks", func() {
+		It("should run the exit tasks with the outcome of the run before completing it", func() {
+			template := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{Name: "notify-ref", Namespace: "default"},
+				Spec:       mcallv1.McallTaskSpec{Type: "cmd", Input: "echo notify"},
+			}
+			Expect(mockClient.Create(ctx, template)).To(Succeed())
+
+			startTime := metav1.NewTime(time.Now().Add(-time.Minute))
+			workflow := &mcallv1.McallWorkflow{
+				ObjectMeta: metav1.ObjectMeta{Name: "exit-workflow", Namespace: "default"},
+				Spec: mcallv1.McallWorkflowSpec{
+					Tasks: []mcallv1.WorkflowTaskRef{
+						{Name: "check", TaskRef: mcallv1.TaskRef{Name: "check-ref", Namespace: "default"}},
+					},
+					OnExit: []mcallv1.WorkflowTaskRef{
+						{
+							Name:    "notify",
+							TaskRef: mcallv1.TaskRef{Name: "notify-ref", Namespace: "default"},
+							InputSources: []mcallv1.TaskInputSource{
+								{Name: "OUTCOME", TaskRef: mcallv1.WorkflowOutcomeTaskRef, Field: "phase"},
+								{Name: "CHECK", TaskRef: "check", Field: "errorCode"},
+							},
+						},
+					},
+				},
+				Status: mcallv1.McallWorkflowStatus{Phase: mcallv1.McallWorkflowPhaseRunning, StartTime: &startTime},
+			}
+			Expect(mockClient.Create(ctx, workflow)).To(Succeed())
+
+			check := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "exit-workflow-check",
+					Namespace: "default",
+					Labels:    map[string]string{"mcall.tz.io/workflow": "exit-workflow", "mcall.tz.io/task": "check"},
+				},
+				Spec: mcallv1.McallTaskSpec{Type: "cmd", Input: "false"},
+				Status: mcallv1.McallTaskStatus{
+					Phase:  mcallv1.McallTaskPhaseFailed,
+					Result: &mcallv1.McallTaskResult{ErrorCode: "-1", ErrorMessage: "exit status 1"},
+				},
+			}
+			Expect(mockClient.Create(ctx, check)).To(Succeed())
+
+			request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "exit-workflow", Namespace: "default"}}
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+
+			// The run waits for its exit task
+			Expect(mockClient.Get(ctx, request.NamespacedName, workflow)).To(Succeed())
+			Expect(workflow.Status.Phase).To(Equal(mcallv1.McallWorkflowPhaseRunning))
+			Expect(workflow.Status.OnExitStartTime).ToNot(BeNil())
+
+			var notify mcallv1.McallTask
+			Expect(mockClient.Get(ctx, types.NamespacedName{Name: "exit-workflow-notify", Namespace: "default"}, &notify)).To(Succeed())
+			Expect(notify.Labels).To(HaveKeyWithValue(exitTaskLabel, "true"))
+			Expect(notify.Spec.InputSources[0].TaskRef).To(Equal(mcallv1.WorkflowOutcomeTaskRef))
+			Expect(notify.Spec.InputSources[1].TaskRef).To(Equal("exit-workflow-check"))
+
+			// The exit task receives the outcome through its input sources
+			taskReconciler := &McallTaskReconciler{Client: mockClient, Scheme: scheme}
+			_, env, err := taskReconciler.processInputSources(ctx, &notify)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(env).To(HaveKeyWithValue("OUTCOME", "Failed"))
+			Expect(env).To(HaveKeyWithValue("CHECK", "-1"))
+			Expect(notify.Annotations[mcallv1.WorkflowOutcomeAnnotation]).To(ContainSubstring("failed tasks: check"))
+
+			// The run completes once its exit task completed
+			notify.Status.Phase = mcallv1.McallTaskPhaseSucceeded
+			Expect(mockClient.Status().Update(ctx, &notify)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(mockClient.Get(ctx, request.NamespacedName, workflow)).To(Succeed())
+			Expect(workflow.Status.Phase).To(Equal(mcallv1.McallWorkflowPhaseFailed))
+		})
+
+		It("should skip the dependents of a failed task and run the exit tasks without failFast", func() {
+			template := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{Name: "cleanup-ref", Namespace: "default"},
+				Spec:       mcallv1.McallTaskSpec{Type: "cmd", Input: "echo cleanup"},
+			}
+			Expect(mockClient.Create(ctx, template)).To(Succeed())
+
+			startTime := metav1.NewTime(time.Now().Add(-time.Minute))
+			workflow := &mcallv1.McallWorkflow{
+				ObjectMeta: metav1.ObjectMeta{Name: "unreachable-workflow", Namespace: "default"},
+				Spec: mcallv1.McallWorkflowSpec{
+					Tasks: []mcallv1.WorkflowTaskRef{
+						{Name: "build", TaskRef: mcallv1.TaskRef{Name: "build-ref"}},
+						{Name: "deploy", TaskRef: mcallv1.TaskRef{Name: "deploy-ref"}, Dependencies: []string{"build"}},
+						{Name: "verify", TaskRef: mcallv1.TaskRef{Name: "verify-ref"}, Dependencies: []string{"deploy"}},
+					},
+					OnExit: []mcallv1.WorkflowTaskRef{
+						{Name: "cleanup", TaskRef: mcallv1.TaskRef{Name: "cleanup-ref", Namespace: "default"}},
+					},
+				},
+				Status: mcallv1.McallWorkflowStatus{Phase: mcallv1.McallWorkflowPhaseRunning, StartTime: &startTime},
+			}
+			Expect(mockClient.Create(ctx, workflow)).To(Succeed())
+
+			for _, task := range []*mcallv1.McallTask{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "unreachable-workflow-build", Namespace: "default",
+						Labels: map[string]string{"mcall.tz.io/workflow": "unreachable-workflow", "mcall.tz.io/task": "build"}},
+					Spec:   mcallv1.McallTaskSpec{Type: "cmd", Input: "false"},
+					Status: mcallv1.McallTaskStatus{Phase: mcallv1.McallTaskPhaseFailed},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "unreachable-workflow-deploy", Namespace: "default",
+						Labels: map[string]string{"mcall.tz.io/workflow": "unreachable-workflow", "mcall.tz.io/task": "deploy"}},
+					Spec:   mcallv1.McallTaskSpec{Type: "cmd", Input: "echo deploy", Dependencies: []string{"unreachable-workflow-build"}},
+					Status: mcallv1.McallTaskStatus{Phase: mcallv1.McallTaskPhasePending},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "unreachable-workflow-verify", Namespace: "default",
+						Labels: map[string]string{"mcall.tz.io/workflow": "unreachable-workflow", "mcall.tz.io/task": "verify"}},
+					Spec:   mcallv1.McallTaskSpec{Type: "cmd", Input: "echo verify", Dependencies: []string{"unreachable-workflow-deploy"}},
+					Status: mcallv1.McallTaskStatus{Phase: mcallv1.McallTaskPhasePending},
+				},
+			} {
+				Expect(mockClient.Create(ctx, task)).To(Succeed())
+			}
+
+			request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "unreachable-workflow", Namespace: "default"}}
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+
+			// The dependents of build can't run and are skipped, transitively
+			for name, message := range map[string]string{
+				"deploy": "Skipped: dependency unreachable-workflow-build failed",
+				"verify": "Skipped: dependency unreachable-workflow-deploy was skipped",
+			} {
+				var task mcallv1.McallTask
+				Expect(mockClient.Get(ctx, types.NamespacedName{Name: "unreachable-workflow-" + name, Namespace: "default"}, &task)).To(Succeed())
+				Expect(task.Status.Phase).To(Equal(mcallv1.McallTaskPhaseSkipped))
+				Expect(task.Status.Result.ErrorMessage).To(Equal(message))
+			}
+
+			// The exit task starts, and the run fails once it completed
+			Expect(mockClient.Get(ctx, request.NamespacedName, workflow)).To(Succeed())
+			Expect(workflow.Status.OnExitStartTime).ToNot(BeNil())
+			var cleanup mcallv1.McallTask
+			Expect(mockClient.Get(ctx, types.NamespacedName{Name: "unreachable-workflow-cleanup", Namespace: "default"}, &cleanup)).To(Succeed())
+
+			cleanup.Status.Phase = mcallv1.McallTaskPhaseSucceeded
+			Expect(mockClient.Status().Update(ctx, &cleanup)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(mockClient.Get(ctx, request.NamespacedName, workflow)).To(Succeed())
+			Expect(workflow.Status.Phase).To(Equal(mcallv1.McallWorkflowPhaseFailed))
+		})
+	})
+
+	Context("Failure Handlers", func() {
+		It("should create the onFailure handler of a task to run on its failure", func() {
+			for _, name := range []string{"check-ref", "diagnose-ref"} {
+				template := &mcallv1.McallTask{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+					Spec:       mcallv1.McallTaskSpec{Type: "cmd", Input: "echo " + name},
+				}
+				Expect(mockClient.Create(ctx, template)).To(Succeed())
+			}
+
+			workflow := &mcallv1.McallWorkflow{
+				ObjectMeta: metav1.ObjectMeta{Name: "handler-workflow", Namespace: "default"},
+				Spec: mcallv1.McallWorkflowSpec{
+					Tasks: []mcallv1.WorkflowTaskRef{
+						{
+							Name:      "check",
+							TaskRef:   mcallv1.TaskRef{Name: "check-ref", Namespace: "default"},
+							OnFailure: &mcallv1.TaskRef{Name: "diagnose-ref"},
+						},
+					},
+				},
+			}
+			Expect(mockClient.Create(ctx, workflow)).To(Succeed())
+
+			request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "handler-workflow", Namespace: "default"}}
+			for i := 0; i < 2; i++ {
+				_, err := reconciler.Reconcile(ctx, request)
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			var handler mcallv1.McallTask
+			Expect(mockClient.Get(ctx, types.NamespacedName{Name: "handler-workflow-check-on-failure", Namespace: "default"}, &handler)).To(Succeed())
+			Expect(handler.Spec.Input).To(Equal("echo diagnose-ref"))
+			Expect(handler.Spec.Dependencies).To(BeEmpty())
+			Expect(handler.Labels).To(HaveKeyWithValue("mcall.tz.io/task", "check-on-failure"))
+			Expect(handler.Annotations).To(HaveKeyWithValue("mcall.tz.io/condition", `{"dependentTask":"handler-workflow-check","when":"failure"}`))
+		})
+	})
+
+	Context("Fail Fast", func() {
+		It("should skip the tasks that haven't started once a task failed", func() {
+			workflow := &mcallv1.McallWorkflow{
+				ObjectMeta: metav1.ObjectMeta{Name: "fast-workflow", Namespace: "default"},
+				Spec: mcallv1.McallWorkflowSpec{
+					FailFast: true,
+					Tasks: []mcallv1.WorkflowTaskRef{
+						{Name: "a", TaskRef: mcallv1.TaskRef{Name: "a-ref"}, OnFailure: &mcallv1.TaskRef{Name: "diagnose-ref"}},
+						{Name: "b", TaskRef: mcallv1.TaskRef{Name: "b-ref"}},
+						{Name: "c", TaskRef: mcallv1.TaskRef{Name: "c-ref"}},
+					},
+				},
+				Status: mcallv1.McallWorkflowStatus{Phase: mcallv1.McallWorkflowPhaseRunning},
+			}
+			Expect(mockClient.Create(ctx, workflow)).To(Succeed())
+
+			newTask := func(name string, phase mcallv1.McallTaskPhase, labels map[string]string) *mcallv1.McallTask {
+				task := &mcallv1.McallTask{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "fast-workflow-" + name,
+						Namespace: "default",
+						Labels:    map[string]string{"mcall.tz.io/workflow": "fast-workflow", "mcall.tz.io/task": name},
+					},
+					Spec:   mcallv1.McallTaskSpec{Type: "cmd", Input: "echo " + name},
+					Status: mcallv1.McallTaskStatus{Phase: phase},
+				}
+				for key, value := range labels {
+					task.Labels[key] = value
+				}
+				Expect(mockClient.Create(ctx, task)).To(Succeed())
+				return task
+			}
+			newTask("a", mcallv1.McallTaskPhaseFailed, nil)
+			newTask("b", mcallv1.McallTaskPhasePending, nil)
+			c := newTask("c", mcallv1.McallTaskPhaseRunning, nil)
+			handler := newTask("a-on-failure", mcallv1.McallTaskPhasePending, map[string]string{failureHandlerLabel: "a"})
+
+			request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "fast-workflow", Namespace: "default"}}
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+
+			var b mcallv1.McallTask
+			Expect(mockClient.Get(ctx, types.NamespacedName{Name: "fast-workflow-b", Namespace: "default"}, &b)).To(Succeed())
+			Expect(b.Status.Phase).To(Equal(mcallv1.McallTaskPhaseSkipped))
+			Expect(b.Status.Result.ErrorMessage).To(Equal("Skipped due to failFast: fast-workflow-a failed"))
+			Expect(mockClient.Get(ctx, types.NamespacedName{Name: c.Name, Namespace: "default"}, c)).To(Succeed())
+			Expect(c.Status.Phase).To(Equal(mcallv1.McallTaskPhaseRunning))
+			Expect(mockClient.Get(ctx, types.NamespacedName{Name: handler.Name, Namespace: "default"}, handler)).To(Succeed())
+			Expect(handler.Status.Phase).To(Equal(mcallv1.McallTaskPhasePending))
+
+			// The run fails once the running task and the handler completed
+			Expect(mockClient.Get(ctx, request.NamespacedName, workflow)).To(Succeed())
+			Expect(workflow.Status.Phase).To(Equal(mcallv1.McallWorkflowPhaseRunning))
+			c.Status.Phase = mcallv1.McallTaskPhaseSucceeded
+			Expect(mockClient.Status().Update(ctx, c)).To(Succeed())
+			handler.Status.Phase = mcallv1.McallTaskPhaseSucceeded
+			Expect(mockClient.Status().Update(ctx, handler)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mockClient.Get(ctx, request.NamespacedName, workflow)).To(Succeed())
+			Expect(workflow.Status.Phase).To(Equal(mcallv1.McallWorkflowPhaseFailed))
+		})
+	})
+
 	Context("Dependency Sorting", func() {
 		It("should sort tasks by dependencies correctly", func() {
 			tasks := []mcallv1.WorkflowTaskRef{
@@ -540,44 +1371,82 @@ var _ = Describe("CronScheduler", func() {
 
 	Context("Cron Expression Parsing", func() {
 		It("should parse valid cron expressions", func() {
-			expr, err := scheduler.ParseCronExpression("0 2 * * *")
+			_, err := scheduler.ParseCronExpression("0 2 * * *")
 			Expect(err).ToNot(HaveOccurred())
-			Expect(expr.Minute).To(Equal("0"))
-			Expect(expr.Hour).To(Equal("2"))
-			Expect(expr.Day).To(Equal("*"))
-			Expect(expr.Month).To(Equal("*"))
-			Expect(expr.Weekday).To(Equal("*"))
 		})
 
 		It("should reject invalid cron expressions", func() {
 			_, err := scheduler.ParseCronExpression("0 2 *")
 			Expect(err).To(HaveOccurred())
 		})
+
+		It("should parse names, ranges with steps and descriptors", func() {
+			for _, expr := range []string{"0 9 * Jan Mon-Fri", "1-30/5 * * * *", "@hourly", "@every 90s"} {
+				_, err := scheduler.ParseCronExpression(expr)
+				Expect(err).ToNot(HaveOccurred(), expr)
+			}
+		})
 	})
 
 	Context("Cron Field Matching", func() {
-		It("should match wildcard fields", func() {
-			Expect(scheduler.matchesField("*", 5)).To(BeTrue())
-		})
+		after := time.Date(2025, 1, 1, 10, 7, 0, 0, time.UTC) // Wednesday
 
-		It("should match exact values", func() {
-			Expect(scheduler.matchesField("5", 5)).To(BeTrue())
-			Expect(scheduler.matchesField("5", 3)).To(BeFalse())
+		It("should match step values", func() {
+			nextRun, err := scheduler.NextRunTime("*/5 * * * *", after)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nextRun).To(Equal(time.Date(2025, 1, 1, 10, 10, 0, 0, time.UTC)))
 		})
 
-		It("should match ranges", func() {
-			Expect(scheduler.matchesField("1-5", 3)).To(BeTrue())
-			Expect(scheduler.matchesField("1-5", 7)).To(BeFalse())
+		It("should match ranges with steps", func() {
+			nextRun, err := scheduler.NextRunTime("0 1-23/6 * * *", after)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nextRun).To(Equal(time.Date(2025, 1, 1, 13, 0, 0, 0, time.UTC)))
 		})
 
-		It("should match step values", func() {
-			Expect(scheduler.matchesField("*/5", 10)).To(BeTrue())
-			Expect(scheduler.matchesField("*/5", 7)).To(BeFalse())
+		It("should match weekday names", func() {
+			nextRun, err := scheduler.NextRunTime("0 9 * * Mon", after)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nextRun).To(Equal(time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)))
 		})
 
 		It("should match comma-separated values", func() {
-			Expect(scheduler.matchesField("1,3,5", 3)).To(BeTrue())
-			Expect(scheduler.matchesField("1,3,5", 2)).To(BeFalse())
+			nextRun, err := scheduler.NextRunTime("3,30 * * * *", after)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nextRun).To(Equal(time.Date(2025, 1, 1, 10, 30, 0, 0, time.UTC)))
+		})
+
+		It("should support @hourly and @every descriptors", func() {
+			nextRun, err := scheduler.NextRunTime("@hourly", after)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nextRun).To(Equal(time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC)))
+
+			nextRun, err = scheduler.NextRunTime("@every 15m", after)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nextRun).To(Equal(after.Add(15 * time.Minute)))
+		})
+	})
+
+	Context("Next Run Calculation", func() {
+		It("should calculate the next run after the given time", func() {
+			after := time.Date(2025, 1, 1, 3, 30, 0, 0, time.UTC)
+			nextRun, err := scheduler.NextRunTime("0 2 * * *", after)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nextRun).To(Equal(time.Date(2025, 1, 2, 2, 0, 0, 0, time.UTC)))
+		})
+
+		It("should reject invalid schedules", func() {
+			_, err := scheduler.NextRunTime("0 2 *", time.Now())
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should count the schedule times missed since the last run", func() {
+			schedule, err := scheduler.ParseCronExpression("@every 10m")
+			Expect(err).ToNot(HaveOccurred())
+			lastRun := time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC)
+
+			// At 3:12 the run due at 3:10 is due, at 3:35 the ones due at 3:10 and 3:20 were missed
+			Expect(missedScheduledRuns(schedule, lastRun, lastRun.Add(12*time.Minute))).To(Equal(0))
+			Expect(missedScheduledRuns(schedule, lastRun, lastRun.Add(35*time.Minute))).To(Equal(2))
 		})
 	})
 
@@ -598,6 +1467,74 @@ var _ = Describe("CronScheduler", func() {
 			Expect(shouldRun).To(BeTrue())
 		})
 
+		It("should run a missed schedule within the starting deadline", func() {
+			deadline := int64(300)
+			workflow := &mcallv1.McallWorkflow{
+				ObjectMeta: metav1.ObjectMeta{Name: "deadline-workflow", Namespace: "default"},
+				Spec: mcallv1.McallWorkflowSpec{
+					Schedule:                "@every 10m",
+					StartingDeadlineSeconds: &deadline,
+				},
+				Status: mcallv1.McallWorkflowStatus{
+					// Scheduled run was due 1 minute ago
+					LastRunTime: &metav1.Time{Time: time.Now().Add(-11 * time.Minute)},
+				},
+			}
+
+			shouldRun, err := scheduler.ShouldRun(context.Background(), workflow)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shouldRun).To(BeTrue())
+		})
+
+		It("should skip a missed schedule past the starting deadline", func() {
+			deadline := int64(60)
+			workflow := &mcallv1.McallWorkflow{
+				ObjectMeta: metav1.ObjectMeta{Name: "deadline-workflow", Namespace: "default"},
+				Spec: mcallv1.McallWorkflowSpec{
+					Schedule:                "@every 10m",
+					StartingDeadlineSeconds: &deadline,
+				},
+				Status: mcallv1.McallWorkflowStatus{
+					// Latest scheduled run was due 5 minutes ago
+					LastRunTime: &metav1.Time{Time: time.Now().Add(-25 * time.Minute)},
+				},
+			}
+
+			shouldRun, err := scheduler.ShouldRun(context.Background(), workflow)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shouldRun).To(BeFalse())
+
+			// Without a deadline the missed run still starts late
+			workflow.Spec.StartingDeadlineSeconds = nil
+			shouldRun, err = scheduler.ShouldRun(context.Background(), workflow)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shouldRun).To(BeTrue())
+		})
+
+		It("should not backfill runs missed while suspended", func() {
+			workflow := &mcallv1.McallWorkflow{
+				ObjectMeta: metav1.ObjectMeta{Name: "resumed-workflow", Namespace: "default"},
+				Spec: mcallv1.McallWorkflowSpec{
+					Schedule: "@every 10m",
+				},
+				Status: mcallv1.McallWorkflowStatus{
+					// Runs were missed while suspended, resumed 1 minute ago
+					LastRunTime: &metav1.Time{Time: time.Now().Add(-1 * time.Hour)},
+					ResumedTime: &metav1.Time{Time: time.Now().Add(-1 * time.Minute)},
+				},
+			}
+
+			shouldRun, err := scheduler.ShouldRun(context.Background(), workflow)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shouldRun).To(BeFalse())
+
+			// Resumed with backfill, the missed run starts right away
+			workflow.Status.ResumedTime = nil
+			shouldRun, err = scheduler.ShouldRun(context.Background(), workflow)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shouldRun).To(BeTrue())
+		})
+
 		It("should run workflow with schedule on first run", func() {
 			workflow := &mcallv1.McallWorkflow{
 				ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// McallWorkflowValidator rejects McallWorkflows with dependency cycles, references to undefined
+// tasks, invalid parameters, CEL conditions, jq programs or fan-outs at admission. The dependencies and
+// parameters are checked again before each run.
+type McallWorkflowValidator struct{}
+
+// SetupWebhookWithManager serves the validating webhook at /validate-mcall-tz-io-v1-mcallworkflow
+func (v *McallWorkflowValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&mcallv1.McallWorkflow{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate checks the dependencies of a new workflow
+func (v *McallWorkflowValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(obj)
+}
+
+// ValidateUpdate checks the dependencies of an updated workflow
+func (v *McallWorkflowValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(newObj)
+}
+
+// ValidateDelete allows every deletion
+func (v *McallWorkflowValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate checks the dependencies, conditions and input sources of the tasks of a workflow
+func (v *McallWorkflowValidator) validate(obj runtime.Object) error {
+	workflow, ok := obj.(*mcallv1.McallWorkflow)
+	if !ok {
+		return fmt.Errorf("expected a McallWorkflow, got %T", obj)
+	}
+	if err := validateWorkflowDependencies(workflow.Spec.Tasks, workflow.Spec.OnExit); err != nil {
+		return err
+	}
+	if _, err := resolveWorkflowParameters(workflow); err != nil {
+		return err
+	}
+	for _, task := range allWorkflowTasks(&workflow.Spec) {
+		if len(task.WithItems) > 0 && task.WithParam != nil {
+			return fmt.Errorf("task %s sets both withItems and withParam", task.Name)
+		}
+		for _, condition := range taskConditions(task) {
+			if condition.CELExpression == "" {
+				continue
+			}
+			if _, err := compileConditionCEL(condition.CELExpression); err != nil {
+				return fmt.Errorf("condition of task %s: %w", task.Name, err)
+			}
+		}
+		for _, source := range task.InputSources {
+			if source.JQ == "" {
+				continue
+			}
+			if _, err := compileJQ(source.JQ); err != nil {
+				return fmt.Errorf("input source %s of task %s: %w", source.Name, task.Name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,786 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// FailureNotification describes a failed task or workflow run
+type FailureNotification struct {
+	Kind      string // "McallTask" or "McallWorkflow"
+	Name      string
+	Namespace string
+	Workflow  string // Workflow of a task, empty for standalone tasks
+	ErrorCode string
+	Error     string
+	Duration  time.Duration
+	URL       string            // Link back to the resource, empty without NOTIFY_RESOURCE_URL
+	Labels    map[string]string // Labels of the resource, e.g. mcall.tz.io/priority
+	Timestamp time.Time         // When the run completed
+
+	// DownSince is set on recoveries to when the resource went down, the completion of the
+	// failed run that followed its last success. It is zero when that isn't known.
+	DownSince time.Time
+}
+
+// Downtime returns how long the resource of a recovery was down, zero when it isn't known
+func (n FailureNotification) Downtime() time.Duration {
+	if n.DownSince.IsZero() {
+		return 0
+	}
+	return n.Timestamp.Sub(n.DownSince)
+}
+
+// DedupKey identifies the incidents of a resource, so repeated failures update a single incident
+func (n FailureNotification) DedupKey() string {
+	return fmt.Sprintf("mcall/%s/%s/%s", n.Namespace, strings.ToLower(n.Kind), n.Name)
+}
+
+// Notifier defines the interface for failure notification targets
+type Notifier interface {
+	Notify(notification FailureNotification) error
+}
+
+// recoveryNotifier is implemented by notifiers that resolve their notification once the
+// resource succeeds again
+type recoveryNotifier interface {
+	Resolve(notification FailureNotification) error
+}
+
+// When the failing resources went down by dedup key, and the zero time for the resources known
+// to be up. Resources missing after a controller restart are resolved on their first success.
+var (
+	resourcesDownSinceMu sync.Mutex
+	resourcesDownSince   = make(map[string]time.Time)
+)
+
+// NotifierConfig represents the failure notification configuration
+type NotifierConfig struct {
+	Enabled bool
+	Types   []string // "slack", "pagerduty", "opsgenie", "teams", "discord"
+
+	// Slack incoming webhook configuration
+	Slack struct {
+		WebhookURL string
+		Channel    string // Overrides the channel of the webhook when set
+		Username   string
+	}
+
+	// Microsoft Teams incoming webhook configuration
+	Teams struct {
+		WebhookURL string
+	}
+
+	// Discord webhook configuration
+	Discord struct {
+		WebhookURL string
+		Username   string
+	}
+
+	// PagerDuty Events API v2 configuration
+	PagerDuty struct {
+		URL        string
+		RoutingKey string // Integration key of the service incidents are triggered on
+		Severity   string // "critical", "error", "warning", "info"
+	}
+
+	// Opsgenie Alert API configuration
+	Opsgenie struct {
+		URL      string // https://api.opsgenie.com, or https://api.eu.opsgenie.com for the EU instance
+		APIKey   string
+		Priority string // Priority of alerts without an mcall.tz.io/priority label, "P1" to "P5"
+	}
+
+	// ResourceURL is the link to a failed resource, with {kind}, {namespace} and {name} replaced
+	ResourceURL string
+}
+
+// GetNotifierConfig returns the failure notification configuration from environment variables.
+// The notifiers are loaded even when disabled, as workflows may enable them with annotations.
+func GetNotifierConfig() NotifierConfig {
+	config := NotifierConfig{}
+
+	config.Enabled = os.Getenv("NOTIFY_ENABLED") == "true"
+	config.Types = parseNotifierTypes(getEnvOrDefault("NOTIFY_TYPE", "slack"))
+
+	// Slack configuration
+	config.Slack.WebhookURL = getEnvOrDefault("NOTIFY_SLACK_WEBHOOK_URL", "")
+	config.Slack.Channel = getEnvOrDefault("NOTIFY_SLACK_CHANNEL", "")
+	config.Slack.Username = getEnvOrDefault("NOTIFY_SLACK_USERNAME", "mcall-operator")
+
+	// Microsoft Teams configuration
+	config.Teams.WebhookURL = getEnvOrDefault("NOTIFY_TEAMS_WEBHOOK_URL", "")
+
+	// Discord configuration
+	config.Discord.WebhookURL = getEnvOrDefault("NOTIFY_DISCORD_WEBHOOK_URL", "")
+	config.Discord.Username = getEnvOrDefault("NOTIFY_DISCORD_USERNAME", "mcall-operator")
+
+	// PagerDuty configuration
+	config.PagerDuty.URL = getEnvOrDefault("NOTIFY_PAGERDUTY_URL", "https://events.pagerduty.com/v2/enqueue")
+	config.PagerDuty.RoutingKey = getEnvOrDefault("NOTIFY_PAGERDUTY_ROUTING_KEY", "")
+	config.PagerDuty.Severity = getEnvOrDefault("NOTIFY_PAGERDUTY_SEVERITY", "error")
+
+	// Opsgenie configuration
+	config.Opsgenie.URL = getEnvOrDefault("NOTIFY_OPSGENIE_URL", "https://api.opsgenie.com")
+	config.Opsgenie.APIKey = getEnvOrDefault("NOTIFY_OPSGENIE_API_KEY", "")
+	config.Opsgenie.Priority = getEnvOrDefault("NOTIFY_OPSGENIE_PRIORITY", "P3")
+
+	config.ResourceURL = getEnvOrDefault("NOTIFY_RESOURCE_URL", "")
+
+	return config
+}
+
+// parseNotifierTypes parses a comma-separated list of notifier types
+func parseNotifierTypes(value string) []string {
+	var types []string
+	for _, notifierType := range strings.Split(value, ",") {
+		if notifierType = strings.TrimSpace(notifierType); notifierType != "" {
+			types = append(types, notifierType)
+		}
+	}
+	return types
+}
+
+// notifierConfigFor returns the notifier configuration of a workflow or task: the operator's,
+// overridden by its mcall.tz.io/notify and mcall.tz.io/notify-secret annotations
+func notifierConfigFor(ctx context.Context, c client.Reader, object client.Object) (NotifierConfig, error) {
+	config := GetNotifierConfig()
+	annotations := object.GetAnnotations()
+
+	if value, exists := annotations[mcallv1.NotifyAnnotation]; exists {
+		config.Types = parseNotifierTypes(value)
+		config.Enabled = len(config.Types) > 0 && strings.TrimSpace(value) != "none"
+	}
+	if secretName := annotations[mcallv1.NotifySecretAnnotation]; secretName != "" && config.Enabled {
+		var secret corev1.Secret
+		if err := c.Get(ctx, types.NamespacedName{Name: secretName, Namespace: object.GetNamespace()}, &secret); err != nil {
+			return config, fmt.Errorf("failed to get notification Secret %s: %w", secretName, err)
+		}
+		for key, webhookURL := range map[string]*string{
+			"slack":   &config.Slack.WebhookURL,
+			"teams":   &config.Teams.WebhookURL,
+			"discord": &config.Discord.WebhookURL,
+		} {
+			if value, exists := secret.Data[key]; exists {
+				*webhookURL = string(value)
+			}
+		}
+	}
+	return config, nil
+}
+
+// CreateNotifier creates the notifier of a type
+func CreateNotifier(config NotifierConfig, notifierType string) (Notifier, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	switch notifierType {
+	case "slack":
+		if config.Slack.WebhookURL == "" {
+			return nil, fmt.Errorf("slack notifier requires a webhook URL")
+		}
+		return &SlackNotifier{config: config, client: client}, nil
+	case "teams":
+		if config.Teams.WebhookURL == "" {
+			return nil, fmt.Errorf("teams notifier requires a webhook URL")
+		}
+		return &TeamsNotifier{config: config, client: client}, nil
+	case "discord":
+		if config.Discord.WebhookURL == "" {
+			return nil, fmt.Errorf("discord notifier requires a webhook URL")
+		}
+		return &DiscordNotifier{config: config, client: client}, nil
+	case "pagerduty":
+		if config.PagerDuty.RoutingKey == "" {
+			return nil, fmt.Errorf("pagerduty notifier requires a routing key")
+		}
+		return &PagerDutyNotifier{config: config, client: client}, nil
+	case "opsgenie":
+		if config.Opsgenie.APIKey == "" {
+			return nil, fmt.Errorf("opsgenie notifier requires an API key")
+		}
+		return &OpsgenieNotifier{config: config, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unsupported notifier: %s", notifierType)
+	}
+}
+
+// resourceURL returns the link back to the resource of a notification
+func resourceURL(config NotifierConfig, notification FailureNotification) string {
+	if config.ResourceURL == "" {
+		return ""
+	}
+	return strings.NewReplacer(
+		"{kind}", url.PathEscape(strings.ToLower(notification.Kind)),
+		"{namespace}", url.PathEscape(notification.Namespace),
+		"{name}", url.PathEscape(notification.Name),
+	).Replace(config.ResourceURL)
+}
+
+// NotifyFailure records the resource as down and sends the failure notification to every
+// configured notifier; a failing notifier doesn't keep the notification from the others,
+// their errors are joined
+func NotifyFailure(notification FailureNotification, config NotifierConfig) error {
+	if notification.Timestamp.IsZero() {
+		notification.Timestamp = time.Now()
+	}
+	key := notification.DedupKey()
+	resourcesDownSinceMu.Lock()
+	if resourcesDownSince[key].IsZero() {
+		resourcesDownSince[key] = notification.Timestamp
+	}
+	resourcesDownSinceMu.Unlock()
+
+	if !config.Enabled {
+		return nil // Notifications disabled
+	}
+
+	if notification.URL == "" {
+		notification.URL = resourceURL(config, notification)
+	}
+
+	var errs []error
+	for _, notifierType := range config.Types {
+		notifier, err := CreateNotifier(config, notifierType)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create notifier: %w", err))
+			continue
+		}
+		if err := notifier.Notify(notification); err != nil {
+			errs = append(errs, fmt.Errorf("%s: failed to send notification: %w", notifierType, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NotifyRecovery records a resource that succeeded again as up and, when it was down, sends the
+// recovery with its downtime to every configured notifier. It returns the downtime, zero when
+// the resource wasn't known to be down. The notifiers resolving alerts also resolve them when
+// the state of the resource is unknown, e.g. after a restart.
+func NotifyRecovery(notification FailureNotification, config NotifierConfig) (time.Duration, error) {
+	if notification.Timestamp.IsZero() {
+		notification.Timestamp = time.Now()
+	}
+	key := notification.DedupKey()
+	resourcesDownSinceMu.Lock()
+	downSince, known := resourcesDownSince[key]
+	resourcesDownSince[key] = time.Time{}
+	resourcesDownSinceMu.Unlock()
+	if known && downSince.IsZero() {
+		return 0, nil // Already up
+	}
+	notification.DownSince = downSince
+
+	if !config.Enabled {
+		return notification.Downtime(), nil // Notifications disabled
+	}
+
+	if notification.URL == "" {
+		notification.URL = resourceURL(config, notification)
+	}
+
+	var errs []error
+	for _, notifierType := range config.Types {
+		notifier, err := CreateNotifier(config, notifierType)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create notifier: %w", err))
+			continue
+		}
+		if resolver, ok := notifier.(recoveryNotifier); ok {
+			if err := resolver.Resolve(notification); err != nil {
+				errs = append(errs, fmt.Errorf("%s: failed to send recovery: %w", notifierType, err))
+			}
+		}
+	}
+	return notification.Downtime(), errors.Join(errs...)
+}
+
+// recoveryText describes the downtime of a recovered resource
+func recoveryText(notification FailureNotification) string {
+	return fmt.Sprintf("Down for %s since %s", notification.Downtime().Round(time.Second),
+		notification.DownSince.UTC().Format(time.RFC3339))
+}
+
+// taskFailureNotification builds the notification of a failed task from its status
+func taskFailureNotification(task *mcallv1.McallTask) FailureNotification {
+	notification := FailureNotification{
+		Kind:      "McallTask",
+		Name:      task.Name,
+		Namespace: task.Namespace,
+		Workflow:  task.Labels["mcall.tz.io/workflow"],
+		Duration:  time.Duration(task.Status.ExecutionTimeMs) * time.Millisecond,
+		Labels:    task.Labels,
+	}
+	if result := task.Status.Result; result != nil {
+		notification.ErrorCode = result.ErrorCode
+		notification.Error = result.ErrorMessage
+	}
+	if task.Status.CompletionTime != nil {
+		notification.Timestamp = task.Status.CompletionTime.Time
+	}
+	if notification.Duration == 0 && task.Status.StartTime != nil && task.Status.CompletionTime != nil {
+		notification.Duration = task.Status.CompletionTime.Sub(task.Status.StartTime.Time)
+	}
+	return notification
+}
+
+// workflowFailureNotification builds the notification of a failed workflow run, listing its failed tasks
+func workflowFailureNotification(workflow *mcallv1.McallWorkflow, tasks []mcallv1.McallTask) FailureNotification {
+	notification := FailureNotification{
+		Kind:      "McallWorkflow",
+		Name:      workflow.Name,
+		Namespace: workflow.Namespace,
+		Workflow:  workflow.Name,
+		Labels:    workflow.Labels,
+	}
+	if workflow.Status.CompletionTime != nil {
+		notification.Timestamp = workflow.Status.CompletionTime.Time
+	}
+	if workflow.Status.StartTime != nil && workflow.Status.CompletionTime != nil {
+		notification.Duration = workflow.Status.CompletionTime.Sub(workflow.Status.StartTime.Time)
+	}
+
+	var failed []string
+	for _, task := range tasks {
+		if task.Status.Phase != mcallv1.McallTaskPhaseFailed && task.Status.Phase != mcallv1.McallTaskPhaseBlocked {
+			continue
+		}
+		taskName := task.Name
+		if name, exists := task.Labels["mcall.tz.io/task"]; exists {
+			taskName = name
+		}
+		if task.Status.Result != nil && task.Status.Result.ErrorMessage != "" {
+			taskName += ": " + task.Status.Result.ErrorMessage
+		}
+		failed = append(failed, taskName)
+	}
+	notification.Error = fmt.Sprintf("%d task(s) failed", len(failed))
+	if len(failed) > 0 {
+		notification.Error += "\n" + strings.Join(failed, "\n")
+	}
+	return notification
+}
+
+// SlackNotifier posts failure notifications to a Slack incoming webhook
+type SlackNotifier struct {
+	config NotifierConfig
+	client *http.Client
+}
+
+func (s *SlackNotifier) Notify(notification FailureNotification) error {
+	title := fmt.Sprintf("%s %s/%s failed", notification.Kind, notification.Namespace, notification.Name)
+	fields := []map[string]interface{}{
+		{"title": "Duration", "value": notification.Duration.Round(time.Millisecond).String(), "short": true},
+	}
+	if notification.Workflow != "" && notification.Kind != "McallWorkflow" {
+		fields = append(fields, map[string]interface{}{"title": "Workflow", "value": notification.Workflow, "short": true})
+	}
+	if notification.ErrorCode != "" {
+		fields = append(fields, map[string]interface{}{"title": "Error code", "value": notification.ErrorCode, "short": true})
+	}
+
+	// Long error messages are cut, Slack folds attachments over 700 characters anyway
+	text, _ := truncateOutput(notification.Error, 2000)
+	attachment := map[string]interface{}{
+		"color":    "danger",
+		"fallback": title,
+		"title":    title,
+		"text":     text,
+		"fields":   fields,
+		"ts":       time.Now().Unix(),
+	}
+	if notification.URL != "" {
+		attachment["title_link"] = notification.URL
+	}
+	payload := map[string]interface{}{
+		"text":        title,
+		"attachments": []map[string]interface{}{attachment},
+	}
+	if s.config.Slack.Channel != "" {
+		payload["channel"] = s.config.Slack.Channel
+	}
+	if s.config.Slack.Username != "" {
+		payload["username"] = s.config.Slack.Username
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Slack message: %w", err)
+	}
+
+	resp, err := s.client.Post(s.config.Slack.WebhookURL, "application/json", bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to post Slack message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("Slack webhook failed with status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Resolve posts the recovery of a resource known to have been down
+func (s *SlackNotifier) Resolve(notification FailureNotification) error {
+	if notification.DownSince.IsZero() {
+		return nil // Not known to have been down, e.g. after a restart
+	}
+
+	title := fmt.Sprintf("%s %s/%s recovered", notification.Kind, notification.Namespace, notification.Name)
+	attachment := map[string]interface{}{
+		"color":    "good",
+		"fallback": title,
+		"title":    title,
+		"text":     recoveryText(notification),
+		"ts":       time.Now().Unix(),
+	}
+	if notification.URL != "" {
+		attachment["title_link"] = notification.URL
+	}
+	payload := map[string]interface{}{
+		"text":        title,
+		"attachments": []map[string]interface{}{attachment},
+	}
+	if s.config.Slack.Channel != "" {
+		payload["channel"] = s.config.Slack.Channel
+	}
+	if s.config.Slack.Username != "" {
+		payload["username"] = s.config.Slack.Username
+	}
+	return postWebhook(s.client, s.config.Slack.WebhookURL, "Slack", payload)
+}
+
+// notificationFacts returns the facts shown in the cards of chat notifiers
+func notificationFacts(notification FailureNotification) [][2]string {
+	facts := [][2]string{{"Duration", notification.Duration.Round(time.Millisecond).String()}}
+	if notification.Workflow != "" && notification.Kind != "McallWorkflow" {
+		facts = append(facts, [2]string{"Workflow", notification.Workflow})
+	}
+	if notification.ErrorCode != "" {
+		facts = append(facts, [2]string{"Error code", notification.ErrorCode})
+	}
+	return facts
+}
+
+// postWebhook posts a JSON payload to a chat webhook
+func postWebhook(client *http.Client, webhookURL, name string, payload interface{}) error {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s message: %w", name, err)
+	}
+
+	resp, err := client.Post(webhookURL, "application/json", bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to post %s message: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("%s webhook failed with status: %d", name, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// TeamsNotifier posts failure notifications as Adaptive Cards to a Microsoft Teams incoming webhook
+type TeamsNotifier struct {
+	config NotifierConfig
+	client *http.Client
+}
+
+func (t *TeamsNotifier) Notify(notification FailureNotification) error {
+	title := fmt.Sprintf("%s %s/%s failed", notification.Kind, notification.Namespace, notification.Name)
+	text, _ := truncateOutput(notification.Error, 2000)
+
+	var facts []map[string]string
+	for _, fact := range notificationFacts(notification) {
+		facts = append(facts, map[string]string{"title": fact[0], "value": fact[1]})
+	}
+	card := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body": []map[string]interface{}{
+			{"type": "TextBlock", "text": title, "weight": "Bolder", "size": "Medium", "color": "Attention", "wrap": true},
+			{"type": "TextBlock", "text": text, "wrap": true},
+			{"type": "FactSet", "facts": facts},
+		},
+	}
+	if notification.URL != "" {
+		card["actions"] = []map[string]string{{"type": "Action.OpenUrl", "title": "Open " + notification.Kind, "url": notification.URL}}
+	}
+
+	return postWebhook(t.client, t.config.Teams.WebhookURL, "Teams", map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{"contentType": "application/vnd.microsoft.card.adaptive", "content": card},
+		},
+	})
+}
+
+// Resolve posts the recovery of a resource known to have been down
+func (t *TeamsNotifier) Resolve(notification FailureNotification) error {
+	if notification.DownSince.IsZero() {
+		return nil // Not known to have been down, e.g. after a restart
+	}
+
+	title := fmt.Sprintf("%s %s/%s recovered", notification.Kind, notification.Namespace, notification.Name)
+	card := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body": []map[string]interface{}{
+			{"type": "TextBlock", "text": title, "weight": "Bolder", "size": "Medium", "color": "Good", "wrap": true},
+			{"type": "TextBlock", "text": recoveryText(notification), "wrap": true},
+		},
+	}
+	if notification.URL != "" {
+		card["actions"] = []map[string]string{{"type": "Action.OpenUrl", "title": "Open " + notification.Kind, "url": notification.URL}}
+	}
+
+	return postWebhook(t.client, t.config.Teams.WebhookURL, "Teams", map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{"contentType": "application/vnd.microsoft.card.adaptive", "content": card},
+		},
+	})
+}
+
+// DiscordNotifier posts failure notifications as embeds to a Discord webhook
+type DiscordNotifier struct {
+	config NotifierConfig
+	client *http.Client
+}
+
+func (d *DiscordNotifier) Notify(notification FailureNotification) error {
+	// Embed descriptions are limited to 4096 characters
+	description, _ := truncateOutput(notification.Error, 4096)
+
+	var fields []map[string]interface{}
+	for _, fact := range notificationFacts(notification) {
+		fields = append(fields, map[string]interface{}{"name": fact[0], "value": fact[1], "inline": true})
+	}
+	embed := map[string]interface{}{
+		"title":       fmt.Sprintf("%s %s/%s failed", notification.Kind, notification.Namespace, notification.Name),
+		"description": description,
+		"color":       0xE74C3C, // Red
+		"fields":      fields,
+		"timestamp":   time.Now().UTC().Format(time.RFC3339),
+	}
+	if notification.URL != "" {
+		embed["url"] = notification.URL
+	}
+	payload := map[string]interface{}{"embeds": []map[string]interface{}{embed}}
+	if d.config.Discord.Username != "" {
+		payload["username"] = d.config.Discord.Username
+	}
+
+	return postWebhook(d.client, d.config.Discord.WebhookURL, "Discord", payload)
+}
+
+// Resolve posts the recovery of a resource known to have been down
+func (d *DiscordNotifier) Resolve(notification FailureNotification) error {
+	if notification.DownSince.IsZero() {
+		return nil // Not known to have been down, e.g. after a restart
+	}
+
+	embed := map[string]interface{}{
+		"title":       fmt.Sprintf("%s %s/%s recovered", notification.Kind, notification.Namespace, notification.Name),
+		"description": recoveryText(notification),
+		"color":       0x2ECC71, // Green
+		"timestamp":   time.Now().UTC().Format(time.RFC3339),
+	}
+	if notification.URL != "" {
+		embed["url"] = notification.URL
+	}
+	payload := map[string]interface{}{"embeds": []map[string]interface{}{embed}}
+	if d.config.Discord.Username != "" {
+		payload["username"] = d.config.Discord.Username
+	}
+
+	return postWebhook(d.client, d.config.Discord.WebhookURL, "Discord", payload)
+}
+
+// PagerDutyNotifier triggers PagerDuty incidents with the Events API v2 and resolves them on
+// recovery. Incidents are deduplicated by resource, so repeated failures don't open new ones.
+type PagerDutyNotifier struct {
+	config NotifierConfig
+	client *http.Client
+}
+
+func (p *PagerDutyNotifier) Notify(notification FailureNotification) error {
+	details := map[string]interface{}{
+		"error":    notification.Error,
+		"duration": notification.Duration.Round(time.Millisecond).String(),
+	}
+	if notification.ErrorCode != "" {
+		details["error_code"] = notification.ErrorCode
+	}
+	if notification.Workflow != "" {
+		details["workflow"] = notification.Workflow
+	}
+
+	// Summaries are limited to 1024 characters
+	summary, _ := truncateOutput(fmt.Sprintf("%s %s/%s failed: %s", notification.Kind, notification.Namespace,
+		notification.Name, strings.SplitN(notification.Error, "\n", 2)[0]), 1024)
+	event := map[string]interface{}{
+		"routing_key":  p.config.PagerDuty.RoutingKey,
+		"event_action": "trigger",
+		"dedup_key":    notification.DedupKey(),
+		"payload": map[string]interface{}{
+			"summary":        summary,
+			"source":         notification.Namespace + "/" + notification.Name,
+			"severity":       p.config.PagerDuty.Severity,
+			"component":      notification.Name,
+			"group":          notification.Workflow,
+			"class":          notification.Kind,
+			"custom_details": details,
+		},
+	}
+	if notification.URL != "" {
+		event["links"] = []map[string]string{{"href": notification.URL, "text": notification.Kind + " " + notification.Name}}
+	}
+	return p.send(event)
+}
+
+func (p *PagerDutyNotifier) Resolve(notification FailureNotification) error {
+	return p.send(map[string]interface{}{
+		"routing_key":  p.config.PagerDuty.RoutingKey,
+		"event_action": "resolve",
+		"dedup_key":    notification.DedupKey(),
+	})
+}
+
+// send enqueues an event with the Events API v2
+func (p *PagerDutyNotifier) send(event map[string]interface{}) error {
+	jsonData, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal PagerDuty event: %w", err)
+	}
+
+	resp, err := p.client.Post(p.config.PagerDuty.URL, "application/json", bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to send PagerDuty event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("PagerDuty event failed with status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// opsgeniePriorities maps the values of the mcall.tz.io/priority label to Opsgenie priorities
+var opsgeniePriorities = map[string]string{
+	"p1": "P1", "critical": "P1",
+	"p2": "P2", "high": "P2",
+	"p3": "P3", "moderate": "P3", "medium": "P3",
+	"p4": "P4", "low": "P4",
+	"p5": "P5", "informational": "P5", "info": "P5",
+}
+
+// OpsgenieNotifier creates Opsgenie alerts with the Alert API and closes them on recovery.
+// Alerts are deduplicated by resource with their alias, so repeated failures don't open new ones.
+type OpsgenieNotifier struct {
+	config NotifierConfig
+	client *http.Client
+}
+
+// priority returns the Opsgenie priority of a notification from the mcall.tz.io/priority label
+// of its resource, or the configured default
+func (o *OpsgenieNotifier) priority(notification FailureNotification) string {
+	if priority, exists := opsgeniePriorities[strings.ToLower(notification.Labels["mcall.tz.io/priority"])]; exists {
+		return priority
+	}
+	return o.config.Opsgenie.Priority
+}
+
+func (o *OpsgenieNotifier) Notify(notification FailureNotification) error {
+	details := map[string]string{
+		"kind":      notification.Kind,
+		"namespace": notification.Namespace,
+		"duration":  notification.Duration.Round(time.Millisecond).String(),
+	}
+	if notification.ErrorCode != "" {
+		details["errorCode"] = notification.ErrorCode
+	}
+	if notification.Workflow != "" {
+		details["workflow"] = notification.Workflow
+	}
+	if notification.URL != "" {
+		details["url"] = notification.URL
+	}
+
+	// Messages are limited to 130 characters and descriptions to 15000
+	message, _ := truncateOutput(fmt.Sprintf("%s %s/%s failed", notification.Kind, notification.Namespace, notification.Name), 130)
+	description, _ := truncateOutput(notification.Error, 15000)
+	tags := []string{"mcall", strings.ToLower(notification.Kind)}
+	if notification.Workflow != "" {
+		tags = append(tags, "workflow:"+notification.Workflow)
+	}
+	return o.send(o.config.Opsgenie.URL+"/v2/alerts", map[string]interface{}{
+		"message":     message,
+		"alias":       notification.DedupKey(),
+		"description": description,
+		"priority":    o.priority(notification),
+		"source":      "mcall-operator",
+		"entity":      notification.Namespace + "/" + notification.Name,
+		"tags":        tags,
+		"details":     details,
+	})
+}
+
+func (o *OpsgenieNotifier) Resolve(notification FailureNotification) error {
+	note := fmt.Sprintf("%s %s/%s succeeded", notification.Kind, notification.Namespace, notification.Name)
+	if !notification.DownSince.IsZero() {
+		note += ". " + recoveryText(notification)
+	}
+	closeURL := fmt.Sprintf("%s/v2/alerts/%s/close?identifierType=alias", o.config.Opsgenie.URL, url.PathEscape(notification.DedupKey()))
+	return o.send(closeURL, map[string]interface{}{
+		"source": "mcall-operator",
+		"note":   note,
+	})
+}
+
+// send posts a request to the Alert API, which processes it asynchronously
+func (o *OpsgenieNotifier) send(targetURL string, body map[string]interface{}) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Opsgenie request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, targetURL, bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+o.config.Opsgenie.APIKey)
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send Opsgenie request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("Opsgenie request failed with status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
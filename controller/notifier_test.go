@@ -0,0 +1,358 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestSlackNotifier tests the Slack message of a failed task, with the link back to it
+func TestSlackNotifier(t *testing.T) {
+	var message struct {
+		Text        string `json:"text"`
+		Channel     string `json:"channel"`
+		Attachments []struct {
+			Title     string `json:"title"`
+			TitleLink string `json:"title_link"`
+			Text      string `json:"text"`
+			Fields    []struct {
+				Title string `json:"title"`
+				Value string `json:"value"`
+			} `json:"fields"`
+		} `json:"attachments"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("failed to decode Slack message: %v", err)
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	t.Setenv("NOTIFY_ENABLED", "true")
+	t.Setenv("NOTIFY_SLACK_WEBHOOK_URL", server.URL)
+	t.Setenv("NOTIFY_SLACK_CHANNEL", "#alerts")
+	t.Setenv("NOTIFY_RESOURCE_URL", "https://mcall.example.com/{namespace}/{kind}/{name}")
+	config := GetNotifierConfig()
+
+	task := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: "health-api", Namespace: "team-a", Labels: map[string]string{"mcall.tz.io/workflow": "health"}},
+		Status: mcallv1.McallTaskStatus{
+			Phase:           mcallv1.McallTaskPhaseFailed,
+			ExecutionTimeMs: 1500,
+			Result:          &mcallv1.McallTaskResult{ErrorCode: "-1", ErrorMessage: "HTTP 503"},
+		},
+	}
+	if err := NotifyFailure(taskFailureNotification(task), config); err != nil {
+		t.Fatalf("NotifyFailure() error = %v", err)
+	}
+
+	if message.Text != "McallTask team-a/health-api failed" || message.Channel != "#alerts" || len(message.Attachments) != 1 {
+		t.Fatalf("unexpected Slack message: %+v", message)
+	}
+	attachment := message.Attachments[0]
+	if attachment.TitleLink != "https://mcall.example.com/team-a/mcalltask/health-api" || attachment.Text != "HTTP 503" {
+		t.Errorf("attachment = %+v", attachment)
+	}
+	fields := make(map[string]string)
+	for _, field := range attachment.Fields {
+		fields[field.Title] = field.Value
+	}
+	if fields["Duration"] != "1.5s" || fields["Workflow"] != "health" || fields["Error code"] != "-1" {
+		t.Errorf("fields = %v", fields)
+	}
+
+	config.Slack.WebhookURL = server.URL + "/missing"
+	server.Config.Handler = http.NotFoundHandler()
+	if err := NotifyFailure(taskFailureNotification(task), config); err == nil || !strings.Contains(err.Error(), "status: 404") {
+		t.Errorf("NotifyFailure() error = %v, want the webhook status", err)
+	}
+}
+
+// TestWorkflowFailureNotification tests listing the failed tasks of a workflow run
+func TestWorkflowFailureNotification(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	workflow := &mcallv1.McallWorkflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "health", Namespace: "default"},
+		Status: mcallv1.McallWorkflowStatus{
+			Phase:          mcallv1.McallWorkflowPhaseFailed,
+			StartTime:      &metav1.Time{Time: start},
+			CompletionTime: &metav1.Time{Time: start.Add(90 * time.Second)},
+		},
+	}
+	tasks := []mcallv1.McallTask{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "health-api", Labels: map[string]string{"mcall.tz.io/task": "api"}},
+			Status: mcallv1.McallTaskStatus{
+				Phase:  mcallv1.McallTaskPhaseFailed,
+				Result: &mcallv1.McallTaskResult{ErrorMessage: "HTTP 503"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "health-db", Labels: map[string]string{"mcall.tz.io/task": "db"}},
+			Status:     mcallv1.McallTaskStatus{Phase: mcallv1.McallTaskPhaseSucceeded},
+		},
+	}
+
+	notification := workflowFailureNotification(workflow, tasks)
+	if notification.Kind != "McallWorkflow" || notification.Duration != 90*time.Second {
+		t.Errorf("notification = %+v", notification)
+	}
+	if notification.Error != "1 task(s) failed\napi: HTTP 503" {
+		t.Errorf("Error = %q", notification.Error)
+	}
+}
+
+// TestPagerDutyNotifier tests triggering deduplicated incidents on failures and resolving them on recovery
+func TestPagerDutyNotifier(t *testing.T) {
+	var mu sync.Mutex
+	var events []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&event)
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	t.Setenv("NOTIFY_ENABLED", "true")
+	t.Setenv("NOTIFY_TYPE", "pagerduty")
+	t.Setenv("NOTIFY_PAGERDUTY_URL", server.URL)
+	t.Setenv("NOTIFY_PAGERDUTY_ROUTING_KEY", "routing-key")
+	config := GetNotifierConfig()
+
+	notification := FailureNotification{Kind: "McallWorkflow", Name: "nightly", Namespace: "pd-test", Error: "1 task(s) failed\napi: HTTP 503"}
+	for i := 0; i < 2; i++ {
+		if err := NotifyFailure(notification, config); err != nil {
+			t.Fatalf("NotifyFailure() error = %v", err)
+		}
+	}
+	// Only the first success after a failure resolves the incident
+	for i := 0; i < 2; i++ {
+		if _, err := NotifyRecovery(notification, config); err != nil {
+			t.Fatalf("NotifyRecovery() error = %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	wantActions := []string{"trigger", "trigger", "resolve"}
+	if len(events) != len(wantActions) {
+		t.Fatalf("sent %d events, want %d", len(events), len(wantActions))
+	}
+	for i, event := range events {
+		if event["event_action"] != wantActions[i] || event["dedup_key"] != "mcall/pd-test/mcallworkflow/nightly" ||
+			event["routing_key"] != "routing-key" {
+			t.Errorf("event %d = %v", i, event)
+		}
+	}
+	payload, _ := events[0]["payload"].(map[string]interface{})
+	if payload["summary"] != "McallWorkflow pd-test/nightly failed: 1 task(s) failed" || payload["severity"] != "error" {
+		t.Errorf("payload = %v", payload)
+	}
+}
+
+// TestOpsgenieNotifier tests alert priorities from the task labels and closing alerts on recovery
+func TestOpsgenieNotifier(t *testing.T) {
+	type request struct {
+		path, authorization string
+		body                map[string]interface{}
+	}
+	var mu sync.Mutex
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		requests = append(requests, request{r.URL.EscapedPath() + "?" + r.URL.RawQuery, r.Header.Get("Authorization"), body})
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	t.Setenv("NOTIFY_ENABLED", "true")
+	t.Setenv("NOTIFY_TYPE", "opsgenie")
+	t.Setenv("NOTIFY_OPSGENIE_URL", server.URL)
+	t.Setenv("NOTIFY_OPSGENIE_API_KEY", "genie")
+	config := GetNotifierConfig()
+
+	critical := FailureNotification{Kind: "McallTask", Name: "api", Namespace: "og-test", Error: "HTTP 503",
+		Labels: map[string]string{"mcall.tz.io/priority": "critical"}}
+	unlabeled := FailureNotification{Kind: "McallTask", Name: "db", Namespace: "og-test", Error: "timeout"}
+	for _, notification := range []FailureNotification{critical, unlabeled} {
+		if err := NotifyFailure(notification, config); err != nil {
+			t.Fatalf("NotifyFailure() error = %v", err)
+		}
+	}
+	if _, err := NotifyRecovery(critical, config); err != nil {
+		t.Fatalf("NotifyRecovery() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 3 {
+		t.Fatalf("sent %d requests, want 3", len(requests))
+	}
+	for _, request := range requests {
+		if request.authorization != "GenieKey genie" {
+			t.Errorf("Authorization = %q", request.authorization)
+		}
+	}
+	if requests[0].path != "/v2/alerts?" || requests[0].body["priority"] != "P1" || requests[0].body["alias"] != "mcall/og-test/mcalltask/api" {
+		t.Errorf("critical alert = %+v", requests[0])
+	}
+	if requests[1].body["priority"] != "P3" {
+		t.Errorf("unlabeled alert priority = %v, want the default P3", requests[1].body["priority"])
+	}
+	if requests[2].path != "/v2/alerts/mcall%2Fog-test%2Fmcalltask%2Fapi/close?identifierType=alias" {
+		t.Errorf("close request = %+v", requests[2])
+	}
+}
+
+// TestWorkflowNotifierAnnotations tests the Teams and Discord cards of a workflow routed by its
+// notify annotations, with the webhook URLs of its notify Secret
+func TestWorkflowNotifierAnnotations(t *testing.T) {
+	var mu sync.Mutex
+	payloads := make(map[string]map[string]interface{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		payloads[r.URL.Path] = payload
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a-webhooks", Namespace: "chat-test"},
+		Data: map[string][]byte{
+			"teams":   []byte(server.URL + "/teams"),
+			"discord": []byte(server.URL + "/discord"),
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+	// The operator notifies Slack only when enabled, which the annotations override
+	t.Setenv("NOTIFY_ENABLED", "false")
+	t.Setenv("NOTIFY_TYPE", "slack")
+
+	disabled := &mcallv1.McallWorkflow{ObjectMeta: metav1.ObjectMeta{Name: "quiet", Namespace: "chat-test",
+		Annotations: map[string]string{mcallv1.NotifyAnnotation: "none"}}}
+	config, err := notifierConfigFor(context.Background(), c, disabled)
+	if err != nil || config.Enabled {
+		t.Fatalf("notifierConfigFor(none) = enabled %v, error %v", config.Enabled, err)
+	}
+
+	workflow := &mcallv1.McallWorkflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "chat-test", Annotations: map[string]string{
+			mcallv1.NotifyAnnotation:       "teams, discord",
+			mcallv1.NotifySecretAnnotation: "team-a-webhooks",
+		}},
+		Status: mcallv1.McallWorkflowStatus{Phase: mcallv1.McallWorkflowPhaseFailed},
+	}
+	config, err = notifierConfigFor(context.Background(), c, workflow)
+	if err != nil {
+		t.Fatalf("notifierConfigFor() error = %v", err)
+	}
+	if !config.Enabled || strings.Join(config.Types, ",") != "teams,discord" {
+		t.Fatalf("notifierConfigFor() = enabled %v, types %v", config.Enabled, config.Types)
+	}
+
+	notification := FailureNotification{Kind: "McallWorkflow", Name: "nightly", Namespace: "chat-test",
+		Error: "1 task(s) failed\napi: HTTP 503", ErrorCode: "-1", Duration: 2 * time.Second, URL: "https://mcall.example.com/nightly"}
+	if err := NotifyFailure(notification, config); err != nil {
+		t.Fatalf("NotifyFailure() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	teams, err := json.Marshal(payloads["/teams"])
+	if err != nil || !strings.Contains(string(teams), `"contentType":"application/vnd.microsoft.card.adaptive"`) ||
+		!strings.Contains(string(teams), `"text":"McallWorkflow chat-test/nightly failed"`) ||
+		!strings.Contains(string(teams), `"url":"https://mcall.example.com/nightly"`) {
+		t.Errorf("Teams payload = %s", teams)
+	}
+	discord, err := json.Marshal(payloads["/discord"])
+	if err != nil || !strings.Contains(string(discord), `"title":"McallWorkflow chat-test/nightly failed"`) ||
+		!strings.Contains(string(discord), `"description":"1 task(s) failed\napi: HTTP 503"`) ||
+		!strings.Contains(string(discord), `"name":"Error code","value":"-1"`) {
+		t.Errorf("Discord payload = %s", discord)
+	}
+}
+
+// TestNotifyRecovery tests the recovery message of a task that succeeds again, with its downtime
+// since the failure that took it down
+func TestNotifyRecovery(t *testing.T) {
+	var mu sync.Mutex
+	var messages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message struct {
+			Attachments []struct {
+				Title string `json:"title"`
+				Text  string `json:"text"`
+				Color string `json:"color"`
+			} `json:"attachments"`
+		}
+		json.NewDecoder(r.Body).Decode(&message)
+		mu.Lock()
+		messages = append(messages, message.Attachments[0].Color+": "+message.Attachments[0].Title+": "+message.Attachments[0].Text)
+		mu.Unlock()
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	t.Setenv("NOTIFY_ENABLED", "true")
+	t.Setenv("NOTIFY_SLACK_WEBHOOK_URL", server.URL)
+	config := GetNotifierConfig()
+
+	down := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	notification := FailureNotification{Kind: "McallTask", Name: "api", Namespace: "recovery-test", Error: "HTTP 503"}
+	for i := 0; i < 2; i++ {
+		notification.Timestamp = down.Add(time.Duration(i) * time.Minute)
+		if err := NotifyFailure(notification, config); err != nil {
+			t.Fatalf("NotifyFailure() error = %v", err)
+		}
+	}
+
+	// The downtime runs from the failure that took the task down
+	notification.Timestamp = down.Add(5 * time.Minute)
+	downtime, err := NotifyRecovery(notification, config)
+	if err != nil || downtime != 5*time.Minute {
+		t.Fatalf("NotifyRecovery() = %v, %v, want 5m0s", downtime, err)
+	}
+	// Successes of a task known to be up and of unknown tasks aren't recoveries
+	unknown := FailureNotification{Kind: "McallTask", Name: "db", Namespace: "recovery-test"}
+	for _, success := range []FailureNotification{notification, unknown} {
+		if downtime, err := NotifyRecovery(success, config); err != nil || downtime != 0 {
+			t.Errorf("NotifyRecovery(%s) = %v, %v, want no downtime", success.Name, downtime, err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{
+		"danger: McallTask recovery-test/api failed: HTTP 503",
+		"danger: McallTask recovery-test/api failed: HTTP 503",
+		"good: McallTask recovery-test/api recovered: Down for 5m0s since 2026-01-02T03:00:00Z",
+	}
+	if strings.Join(messages, "\n") != strings.Join(want, "\n") {
+		t.Errorf("messages = %q, want %q", messages, want)
+	}
+}
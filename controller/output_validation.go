@@ -0,0 +1,223 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+	"github.com/doohee323/tz-mcall-operator/assertion"
+)
+
+// validateTaskOutput checks the execution output against the task's OutputValidation (cmd)
+// or HttpValidation (get/post/graphql) rules
+func validateTaskOutput(task *mcallv1.McallTask, output string) error {
+	var rules []assertion.Assertion
+	var err error
+
+	switch task.Spec.Type {
+	case "get", "post", "graphql":
+		validation := task.Spec.HttpValidation
+		if validation == nil {
+			return nil
+		}
+		if len(validation.ExpectedStatusCodes) > 0 && !containsInt(validation.ExpectedStatusCodes, task.Status.HTTPStatusCode) {
+			return fmt.Errorf("HTTP status %d not in expected status codes %v", task.Status.HTTPStatusCode, validation.ExpectedStatusCodes)
+		}
+		rules, err = httpValidationAssertions(validation)
+	default:
+		if task.Spec.OutputValidation == nil {
+			return nil
+		}
+		rules, err = outputValidationAssertions(task.Spec.OutputValidation)
+	}
+	if err != nil {
+		return err
+	}
+
+	ok, failed, err := assertion.All(output, rules)
+	if err != nil {
+		return fmt.Errorf("validation rule %s: %w", failed, err)
+	}
+	if !ok {
+		return fmt.Errorf("validation failed: expected %s, got %q", failed, truncateString(output, 200))
+	}
+	return nil
+}
+
+// checkHTTPResponse checks the status code and headers of an HTTP response against HttpValidation.
+// Without expectedStatusCodes any 2xx status is a success.
+func checkHTTPResponse(validation *mcallv1.HttpValidation, resp *httpResponse) error {
+	if validation == nil || len(validation.ExpectedStatusCodes) == 0 {
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		}
+	} else if !containsInt(validation.ExpectedStatusCodes, resp.StatusCode) {
+		return fmt.Errorf("HTTP status %d not in expected status codes %v", resp.StatusCode, validation.ExpectedStatusCodes)
+	}
+	if validation == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(validation.ResponseHeaders))
+	for name := range validation.ResponseHeaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		expected := validation.ResponseHeaders[name]
+		values, exists := resp.Header[http.CanonicalHeaderKey(name)]
+		if !exists {
+			return fmt.Errorf("response header %s missing (expected %q)", name, expected)
+		}
+		if actual := strings.Join(values, ", "); !strings.Contains(actual, expected) {
+			return fmt.Errorf("response header %s = %q, expected it to contain %q", name, actual, expected)
+		}
+	}
+	return nil
+}
+
+// checkLatency compares the execution time with HttpValidation.MaxLatencyMs. It reports whether
+// the threshold was exceeded and, unless latencyAction is "degrade", returns an error for it.
+func checkLatency(validation *mcallv1.HttpValidation, latency time.Duration) (bool, error) {
+	if validation == nil || validation.MaxLatencyMs <= 0 || latency.Milliseconds() <= validation.MaxLatencyMs {
+		return false, nil
+	}
+	if validation.LatencyAction == "degrade" {
+		return true, nil
+	}
+	return true, fmt.Errorf("response took %dms, exceeding maxLatencyMs %d", latency.Milliseconds(), validation.MaxLatencyMs)
+}
+
+// redirectPolicy returns the redirect policy of an HttpValidation: redirects are only
+// followed when followRedirects is set, up to maxRedirects (default: 10)
+func redirectPolicy(validation *mcallv1.HttpValidation) func(req *http.Request, via []*http.Request) error {
+	if validation == nil {
+		return nil
+	}
+	if !validation.FollowRedirects {
+		return func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	maxRedirects := int(validation.MaxRedirects)
+	if maxRedirects <= 0 {
+		maxRedirects = 10
+	}
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		return nil
+	}
+}
+
+// outputValidationAssertions builds the assertion rules of a command OutputValidation
+func outputValidationAssertions(validation *mcallv1.OutputValidation) ([]assertion.Assertion, error) {
+	ignoreCase := !validation.CaseSensitive
+	var rules []assertion.Assertion
+
+	if validation.ExpectedOutput != "" {
+		operator, err := matchOperator(validation.OutputMatch)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, assertion.Assertion{Operator: operator, Value: validation.ExpectedOutput, IgnoreCase: ignoreCase})
+	}
+	if validation.OutputPattern != "" {
+		pattern := validation.OutputPattern
+		if validation.Multiline {
+			pattern = "(?m)" + pattern
+		}
+		rules = append(rules, assertion.Assertion{Operator: assertion.Regex, Value: pattern, IgnoreCase: ignoreCase})
+	}
+	if validation.JsonPath != "" {
+		if validation.ExpectedJsonValue != "" {
+			rules = append(rules, assertion.Assertion{Operator: assertion.Equals, Path: validation.JsonPath, Value: validation.ExpectedJsonValue})
+		} else {
+			rules = append(rules, assertion.Assertion{Operator: assertion.Exists, Path: validation.JsonPath})
+		}
+	}
+	if validation.ExpectedFailureOutput != "" {
+		rules = append(rules, assertion.Assertion{Operator: assertion.NotContains, Value: validation.ExpectedFailureOutput, IgnoreCase: ignoreCase})
+	}
+
+	return append(rules, toAssertions(validation.Assertions)...), nil
+}
+
+// httpValidationAssertions builds the assertion rules for the body of an HTTP response
+func httpValidationAssertions(validation *mcallv1.HttpValidation) ([]assertion.Assertion, error) {
+	var rules []assertion.Assertion
+
+	if validation.ExpectedResponseBody != "" {
+		operator, err := matchOperator(validation.ResponseBodyMatch)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, assertion.Assertion{Operator: operator, Value: validation.ExpectedResponseBody})
+	}
+	if validation.ResponseBodyPattern != "" {
+		rules = append(rules, assertion.Assertion{Operator: assertion.Regex, Value: validation.ResponseBodyPattern})
+	}
+
+	return append(rules, toAssertions(validation.Assertions)...), nil
+}
+
+// matchOperator maps an outputMatch/responseBodyMatch mode to an assertion operator
+func matchOperator(mode string) (string, error) {
+	switch mode {
+	case "", "contains":
+		return assertion.Contains, nil
+	case "exact", "equals":
+		return assertion.Equals, nil
+	case "regex":
+		return assertion.Regex, nil
+	case "notContains":
+		return assertion.NotContains, nil
+	default:
+		return "", fmt.Errorf("unknown match mode: %s", mode)
+	}
+}
+
+// toAssertions converts API assertion rules to the assertion engine type
+func toAssertions(rules []mcallv1.Assertion) []assertion.Assertion {
+	result := make([]assertion.Assertion, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, assertion.Assertion(rule))
+	}
+	return result
+}
+
+// containsInt checks if an int slice contains a value
+func containsInt(slice []int, value int) bool {
+	for _, item := range slice {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// setDegradedCondition records whether the last response exceeded the latency threshold
+func setDegradedCondition(task *mcallv1.McallTask, exceeded bool, maxLatencyMs int64) {
+	condition := metav1.Condition{
+		Type:               mcallv1.DegradedCondition,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: task.Generation,
+		Reason:             "LatencyWithinThreshold",
+		Message:            fmt.Sprintf("Response time within %dms", maxLatencyMs),
+	}
+	if exceeded {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "LatencyExceeded"
+		condition.Message = fmt.Sprintf("Response took %dms, exceeding maxLatencyMs %d", task.Status.ExecutionTimeMs, maxLatencyMs)
+	}
+	meta.SetStatusCondition(&task.Status.Conditions, condition)
+}
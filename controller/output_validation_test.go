@@ -0,0 +1,342 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestValidateTaskOutput tests OutputValidation and HttpValidation rules through the assertion engine
+func TestValidateTaskOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    mcallv1.McallTaskSpec
+		status  int
+		output  string
+		wantErr bool
+	}{
+		{
+			name:   "no validation",
+			spec:   mcallv1.McallTaskSpec{Type: "cmd"},
+			output: "anything",
+		},
+		{
+			name:   "expected output case insensitive by default",
+			spec:   mcallv1.McallTaskSpec{Type: "cmd", OutputValidation: &mcallv1.OutputValidation{ExpectedOutput: "linux"}},
+			output: "Linux 6.1",
+		},
+		{
+			name: "expected output case sensitive",
+			spec: mcallv1.McallTaskSpec{Type: "cmd", OutputValidation: &mcallv1.OutputValidation{
+				ExpectedOutput: "linux", CaseSensitive: true,
+			}},
+			output:  "Linux 6.1",
+			wantErr: true,
+		},
+		{
+			name: "failure output present",
+			spec: mcallv1.McallTaskSpec{Type: "cmd", OutputValidation: &mcallv1.OutputValidation{
+				ExpectedFailureOutput: "error",
+			}},
+			output:  "Error: disk full",
+			wantErr: true,
+		},
+		{
+			name: "json path value",
+			spec: mcallv1.McallTaskSpec{Type: "cmd", OutputValidation: &mcallv1.OutputValidation{
+				JsonPath: "$.status", ExpectedJsonValue: "healthy",
+			}},
+			output: `{"status":"healthy"}`,
+		},
+		{
+			name: "output assertions",
+			spec: mcallv1.McallTaskSpec{Type: "cmd", OutputValidation: &mcallv1.OutputValidation{
+				Assertions: []mcallv1.Assertion{{Operator: "lt", Path: "$.usage", Value: "90"}},
+			}},
+			output:  `{"usage":95}`,
+			wantErr: true,
+		},
+		{
+			name:    "unexpected status code",
+			spec:    mcallv1.McallTaskSpec{Type: "get", HttpValidation: &mcallv1.HttpValidation{ExpectedStatusCodes: []int{200}}},
+			status:  204,
+			output:  "",
+			wantErr: true,
+		},
+		{
+			name: "response body regex",
+			spec: mcallv1.McallTaskSpec{Type: "get", HttpValidation: &mcallv1.HttpValidation{
+				ExpectedStatusCodes: []int{200}, ResponseBodyPattern: `"version":"v\d+"`,
+			}},
+			status: 200,
+			output: `{"version":"v2"}`,
+		},
+		{
+			name: "unknown match mode",
+			spec: mcallv1.McallTaskSpec{Type: "get", HttpValidation: &mcallv1.HttpValidation{
+				ExpectedResponseBody: "OK", ResponseBodyMatch: "fuzzy",
+			}},
+			status:  200,
+			output:  "OK",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &mcallv1.McallTask{Spec: tt.spec, Status: mcallv1.McallTaskStatus{HTTPStatusCode: tt.status}}
+			err := validateTaskOutput(task, tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateTaskOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestHttpValidationExecution tests status codes, headers and redirects of HttpValidation against a live server
+func TestHttpValidationExecution(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		case "/missing":
+			http.NotFound(w, r)
+		default:
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			fmt.Fprint(w, `{"status":"ok"}`)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		path       string
+		validation *mcallv1.HttpValidation
+		wantStatus int
+		wantErr    string
+	}{
+		{name: "no validation follows redirects", path: "/redirect", wantStatus: 200},
+		{name: "not found fails by default", path: "/missing", wantStatus: 404, wantErr: "HTTP 404"},
+		{
+			name: "expected not found", path: "/missing", wantStatus: 404,
+			validation: &mcallv1.HttpValidation{ExpectedStatusCodes: []int{404}},
+		},
+		{
+			name: "unexpected status", path: "/ok", wantStatus: 200,
+			validation: &mcallv1.HttpValidation{ExpectedStatusCodes: []int{201}},
+			wantErr:    "HTTP status 200 not in expected status codes [201]",
+		},
+		{
+			name: "response header contains value", path: "/ok", wantStatus: 200,
+			validation: &mcallv1.HttpValidation{ResponseHeaders: map[string]string{"content-type": "application/json"}},
+		},
+		{
+			name: "response header mismatch", path: "/ok", wantStatus: 200,
+			validation: &mcallv1.HttpValidation{ResponseHeaders: map[string]string{"Content-Type": "text/html"}},
+			wantErr:    `response header Content-Type = "application/json; charset=utf-8", expected it to contain "text/html"`,
+		},
+		{
+			name: "response header missing", path: "/ok", wantStatus: 200,
+			validation: &mcallv1.HttpValidation{ResponseHeaders: map[string]string{"X-Request-Id": ""}},
+			wantErr:    "response header X-Request-Id missing",
+		},
+		{
+			name: "redirect not followed", path: "/redirect", wantStatus: 302,
+			validation: &mcallv1.HttpValidation{ExpectedStatusCodes: []int{302}},
+		},
+		{
+			name: "redirect followed", path: "/redirect", wantStatus: 200,
+			validation: &mcallv1.HttpValidation{FollowRedirects: true, MaxRedirects: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &mcallv1.McallTask{Spec: mcallv1.McallTaskSpec{
+				Type: "get", Input: server.URL + tt.path, HttpValidation: tt.validation,
+			}}
+
+			_, err := executeTask(task, 5*time.Second, logr.Discard())
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("executeTask() unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("executeTask() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if task.Status.HTTPStatusCode != tt.wantStatus {
+				t.Errorf("HTTPStatusCode = %d, want %d", task.Status.HTTPStatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+// TestSOAPValidation tests posting SOAP envelopes and validating responses with XPath
+func TestSOAPValidation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "text/xml; charset=utf-8" || r.Header.Get("SOAPAction") != "GetStatus" {
+			http.Error(w, "unsupported request", http.StatusUnsupportedMediaType)
+			return
+		}
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		fmt.Fprint(w, `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body><GetStatusResponse><status>UP</status><errors>0</errors></GetStatusResponse></soap:Body>
+</soap:Envelope>`)
+	}))
+	defer server.Close()
+
+	envelope := `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><GetStatus/></soap:Body></soap:Envelope>`
+
+	tests := []struct {
+		name       string
+		assertions []mcallv1.Assertion
+		wantErr    string
+	}{
+		{
+			name: "status up",
+			assertions: []mcallv1.Assertion{
+				{Operator: "equals", XPath: "/Envelope/Body/GetStatusResponse/status", Value: "UP"},
+				{Operator: "lte", XPath: "//errors", Value: "0"},
+			},
+		},
+		{
+			name:       "status mismatch",
+			assertions: []mcallv1.Assertion{{Operator: "equals", XPath: "//status", Value: "DOWN"}},
+			wantErr:    `validation failed: expected //status equals "DOWN"`,
+		},
+		{
+			name:       "no fault",
+			assertions: []mcallv1.Assertion{{Operator: "notEquals", XPath: "//Fault/faultcode", Value: "soap:Server"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &mcallv1.McallTask{Spec: mcallv1.McallTaskSpec{
+				Type: "post", Input: server.URL, Body: envelope,
+				Headers:        map[string]string{"SOAPAction": "GetStatus"},
+				HttpValidation: &mcallv1.HttpValidation{Assertions: tt.assertions},
+			}}
+
+			output, err := executeTask(task, 5*time.Second, logr.Discard())
+			if err != nil {
+				t.Fatalf("executeTask() error = %v", err)
+			}
+			err = validateTaskOutput(task, output)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("validateTaskOutput() unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("validateTaskOutput() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestLatencyThreshold tests failing or degrading tasks and inputs that respond slower than maxLatencyMs
+func TestLatencyThreshold(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprint(w, "OK")
+	}))
+	defer server.Close()
+
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+
+	tests := []struct {
+		name          string
+		validation    *mcallv1.HttpValidation
+		wantPhase     mcallv1.McallTaskPhase
+		wantCondition metav1.ConditionStatus
+	}{
+		{
+			name:       "within threshold",
+			validation: &mcallv1.HttpValidation{MaxLatencyMs: 5000},
+			wantPhase:  mcallv1.McallTaskPhaseSucceeded,
+		},
+		{
+			name:       "exceeded fails",
+			validation: &mcallv1.HttpValidation{MaxLatencyMs: 10},
+			wantPhase:  mcallv1.McallTaskPhaseFailed,
+		},
+		{
+			name:          "exceeded degrades",
+			validation:    &mcallv1.HttpValidation{MaxLatencyMs: 10, LatencyAction: "degrade"},
+			wantPhase:     mcallv1.McallTaskPhaseSucceeded,
+			wantCondition: metav1.ConditionTrue,
+		},
+		{
+			name:          "within threshold not degraded",
+			validation:    &mcallv1.HttpValidation{MaxLatencyMs: 5000, LatencyAction: "degrade"},
+			wantPhase:     mcallv1.McallTaskPhaseSucceeded,
+			wantCondition: metav1.ConditionFalse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			key := types.NamespacedName{Name: "latency-task", Namespace: "default"}
+			task := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec:       mcallv1.McallTaskSpec{Type: "get", Input: server.URL, HttpValidation: tt.validation},
+				Status:     mcallv1.McallTaskStatus{Phase: mcallv1.McallTaskPhaseRunning},
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&mcallv1.McallTask{}).
+				WithObjects(task).
+				Build()
+			r := &McallTaskReconciler{Client: fakeClient, Scheme: scheme}
+
+			if _, err := r.handleRunning(ctx, task); err != nil {
+				t.Fatalf("handleRunning() error = %v", err)
+			}
+
+			var updated mcallv1.McallTask
+			if err := fakeClient.Get(ctx, key, &updated); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if updated.Status.Phase != tt.wantPhase {
+				t.Errorf("phase = %s, want %s", updated.Status.Phase, tt.wantPhase)
+			}
+			condition := meta.FindStatusCondition(updated.Status.Conditions, mcallv1.DegradedCondition)
+			switch {
+			case tt.wantCondition == "" && condition != nil:
+				t.Errorf("unexpected Degraded condition %+v", condition)
+			case tt.wantCondition != "" && (condition == nil || condition.Status != tt.wantCondition):
+				t.Errorf("Degraded condition = %+v, want status %s", condition, tt.wantCondition)
+			}
+		})
+	}
+
+	// Per-input thresholds fail only the slow input
+	task := &mcallv1.McallTask{Spec: mcallv1.McallTaskSpec{
+		Type: "cmd",
+		Input: fmt.Sprintf(`{"inputs": [
+			{"type": "get", "input": %q, "name": "slow", "maxLatencyMs": 10},
+			{"type": "get", "input": %q, "name": "relaxed", "maxLatencyMs": 5000}
+		]}`, server.URL, server.URL),
+	}}
+	output, err := executeTask(task, 5*time.Second, logr.Discard())
+	if err == nil {
+		t.Fatalf("executeTask() expected the slow input to fail, output = %s", output)
+	}
+	if !strings.Contains(output, "Error: OK") || strings.Count(output, "OK") != 2 {
+		t.Errorf("output = %q, want only the first input to fail", output)
+	}
+}
@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// Channels of the v4.channel.k8s.io exec protocol: each websocket message starts with its channel
+const (
+	execStdoutChannel = 1
+	execStderrChannel = 2
+	execErrorChannel  = 3
+)
+
+// podExecTarget is the pod and container a kubectl-exec task runs its command in
+type podExecTarget struct {
+	config    *rest.Config
+	namespace string
+	pod       string
+	container string
+}
+
+// resolvePodExec selects the first running pod matching the task's label selector
+func (r *McallTaskReconciler) resolvePodExec(ctx context.Context, task *mcallv1.McallTask) (*podExecTarget, error) {
+	spec := task.Spec.Exec
+	if spec == nil || spec.Selector == "" {
+		return nil, fmt.Errorf("kubectl-exec task requires spec.exec.selector")
+	}
+	if r.Config == nil {
+		return nil, fmt.Errorf("kubectl-exec tasks require the operator REST config")
+	}
+
+	selector, err := labels.Parse(spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exec selector %q: %w", spec.Selector, err)
+	}
+	namespace := spec.Namespace
+	if namespace == "" {
+		namespace = task.Namespace
+	}
+
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		container := spec.Container
+		if container == "" {
+			container = pod.Spec.Containers[0].Name
+		}
+		return &podExecTarget{config: r.Config, namespace: namespace, pod: pod.Name, container: container}, nil
+	}
+	return nil, fmt.Errorf("no running pod in namespace %s matches %q", namespace, spec.Selector)
+}
+
+// executePodExec runs a shell command in the target pod through the pods/exec API
+// and returns its combined stdout and stderr
+func executePodExec(target *podExecTarget, command string, timeout time.Duration) (string, error) {
+	if target == nil {
+		return "", fmt.Errorf("no pod selected for kubectl-exec task")
+	}
+	if command == "" {
+		return "", fmt.Errorf("empty command")
+	}
+
+	config, err := execWebsocketConfig(target, command, timeout)
+	if err != nil {
+		return "", err
+	}
+	ws, err := websocket.DialConfig(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to exec in pod %s/%s: %w", target.namespace, target.pod, err)
+	}
+	defer ws.Close()
+	ws.SetDeadline(time.Now().Add(timeout))
+
+	var output strings.Builder
+	for {
+		var message []byte
+		if err := websocket.Message.Receive(ws, &message); err != nil {
+			if err == io.EOF {
+				return output.String(), fmt.Errorf("exec stream closed without a status")
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return output.String(), fmt.Errorf("command execution timed out")
+			}
+			return output.String(), fmt.Errorf("exec stream failed: %w", err)
+		}
+		if len(message) == 0 {
+			continue
+		}
+
+		switch message[0] {
+		case execStdoutChannel, execStderrChannel:
+			output.Write(message[1:])
+		case execErrorChannel:
+			return output.String(), execStatusError(message[1:])
+		}
+	}
+}
+
+// execWebsocketConfig builds the websocket request of the pods/exec API
+func execWebsocketConfig(target *podExecTarget, command string, timeout time.Duration) (*websocket.Config, error) {
+	host, err := url.Parse(target.config.Host)
+	if err != nil || host.Host == "" {
+		if host, err = url.Parse("https://" + target.config.Host); err != nil {
+			return nil, fmt.Errorf("invalid API server host %q: %w", target.config.Host, err)
+		}
+	}
+
+	query := url.Values{}
+	for _, arg := range []string{"/bin/sh", "-c", command} {
+		query.Add("command", arg)
+	}
+	query.Set("container", target.container)
+	query.Set("stdout", "true")
+	query.Set("stderr", "true")
+
+	location := *host
+	location.Scheme = "wss"
+	if host.Scheme == "http" {
+		location.Scheme = "ws"
+	}
+	location.Path = strings.TrimSuffix(host.Path, "/") + fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/exec", target.namespace, target.pod)
+	location.RawQuery = query.Encode()
+
+	config, err := websocket.NewConfig(location.String(), host.String())
+	if err != nil {
+		return nil, err
+	}
+	config.Protocol = []string{"v4.channel.k8s.io"}
+	config.Dialer = &net.Dialer{Timeout: timeout}
+	if config.TlsConfig, err = rest.TLSConfigFor(target.config); err != nil {
+		return nil, fmt.Errorf("invalid API server TLS config: %w", err)
+	}
+
+	token := target.config.BearerToken
+	if token == "" && target.config.BearerTokenFile != "" {
+		data, err := os.ReadFile(target.config.BearerTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read API server token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		config.Header.Set("Authorization", "Bearer "+token)
+	}
+	return config, nil
+}
+
+// execStatusError converts the status sent on the error channel into the command result
+func execStatusError(data []byte) error {
+	var status metav1.Status
+	if err := json.Unmarshal(data, &status); err != nil {
+		return fmt.Errorf("invalid exec status: %w", err)
+	}
+	if status.Status == metav1.StatusSuccess {
+		return nil
+	}
+	if status.Reason == "NonZeroExitCode" && status.Details != nil {
+		for _, cause := range status.Details.Causes {
+			if cause.Type == "ExitCode" {
+				return fmt.Errorf("command failed: exit status %s", cause.Message)
+			}
+		}
+	}
+	return fmt.Errorf("command failed: %s", status.Message)
+}
@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// newFakeExecServer serves the pods/exec API of pod default/api-0: "echo" commands
+// write their argument to stdout, every other command exits with status 2
+func newFakeExecServer(t *testing.T) *httptest.Server {
+	handler := websocket.Server{Handshake: func(config *websocket.Config, request *http.Request) error {
+		if request.URL.Path != "/api/v1/namespaces/default/pods/api-0/exec" || request.Header.Get("Authorization") != "Bearer token" {
+			return fmt.Errorf("forbidden")
+		}
+		return nil
+	}, Handler: func(ws *websocket.Conn) {
+		command := ws.Request().URL.Query()["command"]
+		status := metav1.Status{Status: metav1.StatusSuccess}
+		if len(command) == 3 && command[0] == "/bin/sh" && strings.HasPrefix(command[2], "echo ") {
+			websocket.Message.Send(ws, append([]byte{execStdoutChannel}, strings.TrimPrefix(command[2], "echo ")+"\n"...))
+		} else {
+			websocket.Message.Send(ws, append([]byte{execStderrChannel}, "not found\n"...))
+			status = metav1.Status{
+				Status:  metav1.StatusFailure,
+				Reason:  "NonZeroExitCode",
+				Message: "command terminated with non-zero exit code",
+				Details: &metav1.StatusDetails{Causes: []metav1.StatusCause{{Type: "ExitCode", Message: "2"}}},
+			}
+		}
+		data, _ := json.Marshal(status)
+		websocket.Message.Send(ws, append([]byte{execErrorChannel}, data...))
+	}}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestExecutePodExec tests running commands through the exec websocket protocol
+func TestExecutePodExec(t *testing.T) {
+	server := newFakeExecServer(t)
+
+	tests := []struct {
+		name       string
+		token      string
+		command    string
+		wantOutput string
+		wantErr    string
+	}{
+		{name: "success", token: "token", command: "echo ready", wantOutput: "ready\n"},
+		{name: "non-zero exit", token: "token", command: "false", wantOutput: "not found\n", wantErr: "exit status 2"},
+		{name: "unauthorized", token: "other", command: "echo ready", wantErr: "failed to exec in pod default/api-0"},
+		{name: "empty command", token: "token", wantErr: "empty command"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &podExecTarget{
+				config:    &rest.Config{Host: server.URL, BearerToken: tt.token},
+				namespace: "default",
+				pod:       "api-0",
+				container: "api",
+			}
+			output, err := executePodExec(target, tt.command, 2*time.Second)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("executePodExec() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("executePodExec() error = %v", err)
+			}
+			if output != tt.wantOutput {
+				t.Errorf("output = %q, want %q", output, tt.wantOutput)
+			}
+		})
+	}
+}
+
+// TestResolvePodExec tests selecting the pod by namespace and label selector
+func TestResolvePodExec(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name, namespace string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{"app": "api"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "api"}, {Name: "sidecar"}}},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	r := &McallTaskReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			newPod("api-0", "default", corev1.PodPending),
+			newPod("api-2", "default", corev1.PodRunning),
+			newPod("api-1", "default", corev1.PodRunning),
+			newPod("api-0", "prod", corev1.PodRunning),
+		).Build(),
+		Scheme: scheme,
+		Config: &rest.Config{Host: "https://kubernetes.default.svc"},
+	}
+
+	tests := []struct {
+		name          string
+		exec          *mcallv1.PodExec
+		wantNamespace string
+		wantPod       string
+		wantContainer string
+		wantErr       bool
+	}{
+		{name: "first running pod", exec: &mcallv1.PodExec{Selector: "app=api"}, wantNamespace: "default", wantPod: "api-1", wantContainer: "api"},
+		{name: "namespace and container", exec: &mcallv1.PodExec{Namespace: "prod", Selector: "app=api", Container: "sidecar"}, wantNamespace: "prod", wantPod: "api-0", wantContainer: "sidecar"},
+		{name: "no match", exec: &mcallv1.PodExec{Selector: "app=web"}, wantErr: true},
+		{name: "invalid selector", exec: &mcallv1.PodExec{Selector: "app in ("}, wantErr: true},
+		{name: "no exec spec", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{Name: "diagnose", Namespace: "default"},
+				Spec:       mcallv1.McallTaskSpec{Type: "kubectl-exec", Input: "uptime", Exec: tt.exec},
+			}
+			target, err := r.resolvePodExec(context.Background(), task)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolvePodExec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if target.namespace != tt.wantNamespace || target.pod != tt.wantPod || target.container != tt.wantContainer {
+				t.Errorf("target = %s/%s/%s, want %s/%s/%s", target.namespace, target.pod, target.container,
+					tt.wantNamespace, tt.wantPod, tt.wantContainer)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// WorkflowReport represents the aggregated results of a workflow run in tabular form
+type WorkflowReport struct {
+	Workflow       string     `json:"workflow"`
+	Namespace      string     `json:"namespace"`
+	Phase          string     `json:"phase"`
+	StartTime      string     `json:"startTime,omitempty"`
+	CompletionTime string     `json:"completionTime,omitempty"`
+	Columns        []string   `json:"columns"`
+	Rows           [][]string `json:"rows"`
+}
+
+// reportColumns are the columns of each task row in a WorkflowReport
+var reportColumns = []string{"timestamp", "workflow", "task", "type", "phase", "errorCode", "executionTimeMs", "httpStatusCode", "errorMessage"}
+
+// ReportSink defines the interface for report export targets
+type ReportSink interface {
+	Export(report WorkflowReport) error
+}
+
+// ReportSinkConfig represents the report export configuration
+type ReportSinkConfig struct {
+	Enabled bool
+	Type    string // "webhook", "googlesheets"
+
+	// Generic webhook configuration
+	Webhook struct {
+		URL string
+	}
+
+	// Google Sheets configuration
+	GoogleSheets struct {
+		BaseURL       string
+		SpreadsheetID string
+		Range         string
+		AccessToken   string
+	}
+}
+
+// GetReportSinkConfig returns the report sink configuration from environment variables
+func GetReportSinkConfig() ReportSinkConfig {
+	config := ReportSinkConfig{}
+
+	config.Enabled = os.Getenv("REPORT_SINK_ENABLED") == "true"
+	if !config.Enabled {
+		return config
+	}
+
+	config.Type = getEnvOrDefault("REPORT_SINK_TYPE", "webhook")
+
+	// Webhook configuration
+	config.Webhook.URL = getEnvOrDefault("REPORT_SINK_WEBHOOK_URL", "")
+
+	// Google Sheets configuration
+	config.GoogleSheets.BaseURL = getEnvOrDefault("REPORT_SINK_GOOGLE_SHEETS_BASE_URL", "https://sheets.googleapis.com")
+	config.GoogleSheets.SpreadsheetID = getEnvOrDefault("REPORT_SINK_GOOGLE_SHEETS_SPREADSHEET_ID", "")
+	config.GoogleSheets.Range = getEnvOrDefault("REPORT_SINK_GOOGLE_SHEETS_RANGE", "Sheet1!A1")
+	config.GoogleSheets.AccessToken = getEnvOrDefault("REPORT_SINK_GOOGLE_SHEETS_TOKEN", "")
+
+	return config
+}
+
+// CreateReportSink creates the appropriate report sink based on configuration
+func CreateReportSink(config ReportSinkConfig) (ReportSink, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	switch config.Type {
+	case "webhook":
+		if config.Webhook.URL == "" {
+			return nil, fmt.Errorf("webhook report sink requires a URL")
+		}
+		return &WebhookReportSink{config: config, client: client}, nil
+	case "googlesheets":
+		if config.GoogleSheets.SpreadsheetID == "" {
+			return nil, fmt.Errorf("google sheets report sink requires a spreadsheet ID")
+		}
+		return &GoogleSheetsReportSink{config: config, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unsupported report sink: %s", config.Type)
+	}
+}
+
+// ExportReport exports the workflow report to the configured sink
+func ExportReport(report WorkflowReport, config ReportSinkConfig) error {
+	if !config.Enabled {
+		return nil // Export disabled
+	}
+
+	sink, err := CreateReportSink(config)
+	if err != nil {
+		return fmt.Errorf("failed to create report sink: %w", err)
+	}
+
+	if err := sink.Export(report); err != nil {
+		return fmt.Errorf("failed to export report: %w", err)
+	}
+
+	return nil
+}
+
+// buildWorkflowReport aggregates the task results of a workflow run into a report
+func buildWorkflowReport(workflow *mcallv1.McallWorkflow, tasks []mcallv1.McallTask) WorkflowReport {
+	report := WorkflowReport{
+		Workflow:  workflow.Name,
+		Namespace: workflow.Namespace,
+		Phase:     string(workflow.Status.Phase),
+		Columns:   reportColumns,
+		Rows:      [][]string{},
+	}
+	if workflow.Status.StartTime != nil {
+		report.StartTime = workflow.Status.StartTime.Format(time.RFC3339)
+	}
+
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	if workflow.Status.CompletionTime != nil {
+		report.CompletionTime = workflow.Status.CompletionTime.Format(time.RFC3339)
+		timestamp = report.CompletionTime
+	}
+
+	for _, task := range tasks {
+		taskName := task.Name
+		if name, exists := task.Labels["mcall.tz.io/task"]; exists {
+			taskName = name
+		}
+
+		var errorCode, errorMessage string
+		if task.Status.Result != nil {
+			errorCode = task.Status.Result.ErrorCode
+			errorMessage = task.Status.Result.ErrorMessage
+		}
+
+		report.Rows = append(report.Rows, []string{
+			timestamp,
+			workflow.Name,
+			taskName,
+			task.Spec.Type,
+			string(task.Status.Phase),
+			errorCode,
+			fmt.Sprintf("%d", task.Status.ExecutionTimeMs),
+			fmt.Sprintf("%d", task.Status.HTTPStatusCode),
+			errorMessage,
+		})
+	}
+
+	return report
+}
+
+// WebhookReportSink posts the report as tabular JSON to a generic webhook
+type WebhookReportSink struct {
+	config ReportSinkConfig
+	client *http.Client
+}
+
+func (w *WebhookReportSink) Export(report WorkflowReport) error {
+	jsonData, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	return postJSON(w.client, w.config.Webhook.URL, "", jsonData)
+}
+
+// GoogleSheetsReportSink appends the report rows to a Google Sheets range
+type GoogleSheetsReportSink struct {
+	config ReportSinkConfig
+	client *http.Client
+}
+
+func (g *GoogleSheetsReportSink) Export(report WorkflowReport) error {
+	body := map[string]interface{}{
+		"values": report.Rows,
+	}
+
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report rows: %w", err)
+	}
+
+	// https://developers.google.com/sheets/api/reference/rest/v4/spreadsheets.values/append
+	appendURL := fmt.Sprintf("%s/v4/spreadsheets/%s/values/%s:append?valueInputOption=USER_ENTERED&insertDataOption=INSERT_ROWS",
+		g.config.GoogleSheets.BaseURL,
+		url.PathEscape(g.config.GoogleSheets.SpreadsheetID),
+		url.PathEscape(g.config.GoogleSheets.Range),
+	)
+
+	return postJSON(g.client, appendURL, g.config.GoogleSheets.AccessToken, jsonData)
+}
+
+// postJSON sends a JSON payload with an optional bearer token
+func postJSON(client *http.Client, targetURL, bearerToken string, jsonData []byte) error {
+	req, err := http.NewRequest("POST", targetURL, bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+bearerToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send report: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("report request failed with status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestBuildWorkflowReport tests aggregation of task results into report rows
+func TestBuildWorkflowReport(t *testing.T) {
+	workflow := &mcallv1.McallWorkflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "health", Namespace: "default"},
+		Status:     mcallv1.McallWorkflowStatus{Phase: mcallv1.McallWorkflowPhaseFailed},
+	}
+	tasks := []mcallv1.McallTask{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "health-api", Labels: map[string]string{"mcall.tz.io/task": "api"}},
+			Spec:       mcallv1.McallTaskSpec{Type: "get"},
+			Status: mcallv1.McallTaskStatus{
+				Phase:           mcallv1.McallTaskPhaseFailed,
+				ExecutionTimeMs: 120,
+				HTTPStatusCode:  503,
+				Result:          &mcallv1.McallTaskResult{ErrorCode: "-1", ErrorMessage: "HTTP 503"},
+			},
+		},
+	}
+
+	report := buildWorkflowReport(workflow, tasks)
+
+	if report.Workflow != "health" || report.Phase != "Failed" {
+		t.Errorf("unexpected report header: %+v", report)
+	}
+	if len(report.Rows) != 1 {
+		t.Fatalf("expected 1 row, got %d", len(report.Rows))
+	}
+	row := report.Rows[0]
+	if len(row) != len(report.Columns) {
+		t.Fatalf("row has %d values for %d columns", len(row), len(report.Columns))
+	}
+	expected := map[string]string{"task": "api", "phase": "Failed", "errorCode": "-1", "httpStatusCode": "503", "executionTimeMs": "120"}
+	for i, column := range report.Columns {
+		if want, exists := expected[column]; exists && row[i] != want {
+			t.Errorf("column %s = %q, want %q", column, row[i], want)
+		}
+	}
+}
+
+// TestReportSinks tests webhook and Google Sheets report export
+func TestReportSinks(t *testing.T) {
+	report := WorkflowReport{
+		Workflow: "health",
+		Columns:  reportColumns,
+		Rows:     [][]string{{"2025-01-01T00:00:00Z", "health", "api", "get", "Succeeded", "0", "10", "200", ""}},
+	}
+
+	var gotPath, gotAuth string
+	var gotBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &gotBody)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Run("webhook", func(t *testing.T) {
+		config := ReportSinkConfig{Enabled: true, Type: "webhook"}
+		config.Webhook.URL = server.URL + "/reports"
+
+		if err := ExportReport(report, config); err != nil {
+			t.Fatalf("ExportReport() error = %v", err)
+		}
+		if gotPath != "/reports" {
+			t.Errorf("unexpected path %s", gotPath)
+		}
+		if gotBody["workflow"] != "health" || gotBody["rows"] == nil {
+			t.Errorf("unexpected webhook body: %v", gotBody)
+		}
+	})
+
+	t.Run("googlesheets", func(t *testing.T) {
+		config := ReportSinkConfig{Enabled: true, Type: "googlesheets"}
+		config.GoogleSheets.BaseURL = server.URL
+		config.GoogleSheets.SpreadsheetID = "sheet-id"
+		config.GoogleSheets.Range = "Runs"
+		config.GoogleSheets.AccessToken = "token"
+
+		if err := ExportReport(report, config); err != nil {
+			t.Fatalf("ExportReport() error = %v", err)
+		}
+		if gotPath != "/v4/spreadsheets/sheet-id/values/Runs:append" {
+			t.Errorf("unexpected path %s", gotPath)
+		}
+		if gotAuth != "Bearer token" {
+			t.Errorf("unexpected authorization header %q", gotAuth)
+		}
+		if values, ok := gotBody["values"].([]interface{}); !ok || len(values) != 1 {
+			t.Errorf("unexpected sheets body: %v", gotBody)
+		}
+	})
+
+	t.Run("unsupported sink", func(t *testing.T) {
+		config := ReportSinkConfig{Enabled: true, Type: "ftp"}
+		if err := ExportReport(report, config); err == nil {
+			t.Errorf("expected error for unsupported sink")
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// outputConfigMapKey is the key of the full output in output ConfigMaps
+const outputConfigMapKey = "output"
+
+// maxOutputConfigMapBytes keeps output ConfigMaps under the 1 MiB object size limit
+const maxOutputConfigMapBytes = 1000 * 1000
+
+// getResultOutputMaxBytes returns the size outputs are truncated at in the status (0 disables it)
+func getResultOutputMaxBytes() int {
+	return getEnvIntOrDefault("RESULT_OUTPUT_MAX_BYTES", 32*1024)
+}
+
+// resultOutputOverflowEnabled reports whether truncated outputs are written in full to ConfigMaps
+func resultOutputOverflowEnabled() bool {
+	return os.Getenv("RESULT_OUTPUT_OVERFLOW_CONFIGMAP") == "true"
+}
+
+// getResultOutputCompression returns the algorithm ("gzip", "zstd" or "none") and the size in bytes
+// from which outputs stored in the status are compressed
+func getResultOutputCompression() (string, int) {
+	return getEnvOrDefault("RESULT_OUTPUT_COMPRESSION", "none"), getEnvIntOrDefault("RESULT_OUTPUT_COMPRESSION_THRESHOLD", 4096)
+}
+
+// truncateOutput returns the beginning of an output up to limit bytes, without splitting
+// a UTF-8 character, and whether it was truncated
+func truncateOutput(output string, limit int) (string, bool) {
+	if limit <= 0 || len(output) <= limit {
+		return output, false
+	}
+	for limit > 0 && !utf8.RuneStart(output[limit]) {
+		limit--
+	}
+	return output[:limit], true
+}
+
+// newTaskResult returns the result stored in the status, with the output bounded. Large outputs
+// are compressed when compression is enabled and the compressed output fits, and truncated
+// otherwise. When the output is truncated and overflow is enabled, it references the ConfigMap
+// of the full output.
+func newTaskResult(task *mcallv1.McallTask, output string) *mcallv1.McallTaskResult {
+	result := &mcallv1.McallTaskResult{}
+	algorithm, threshold := getResultOutputCompression()
+	compressed, encoding, err := compressOutput(output, algorithm, threshold)
+	if maxBytes := getResultOutputMaxBytes(); err == nil && encoding != OutputEncodingNone &&
+		len(compressed) < len(output) && (maxBytes <= 0 || len(compressed) <= maxBytes) {
+		result.Output, result.OutputEncoding, result.OutputBytes = compressed, encoding, len(output)
+		return result
+	}
+
+	result.Output, result.OutputTruncated = truncateOutput(output, getResultOutputMaxBytes())
+	if !result.OutputTruncated {
+		return result
+	}
+	result.OutputBytes = len(output)
+	if resultOutputOverflowEnabled() {
+		// One ConfigMap per McallTaskRun, or the latest output without task run history
+		if name := newTaskRunName(task); name != "" && getTaskRunHistoryLimit() > 0 {
+			result.OutputConfigMap = taskRunOutputConfigMapName(name)
+		} else {
+			result.OutputConfigMap = taskRunOutputConfigMapName(task.Name)
+		}
+	}
+	return result
+}
+
+// taskRunOutputConfigMapName returns the name of the output ConfigMap of a task run
+func taskRunOutputConfigMapName(taskRunName string) string {
+	return taskRunName + "-output"
+}
+
+// storeOutputConfigMap writes the full output of an execution to its ConfigMap. It is labeled
+// with the task, so it is deleted with the task runs of standalone tasks.
+func (r *McallTaskReconciler) storeOutputConfigMap(ctx context.Context, task *mcallv1.McallTask, name, output string) error {
+	output, _ = truncateOutput(output, maxOutputConfigMapBytes)
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: task.Namespace,
+			Labels:    map[string]string{"mcall.tz.io/output-of": task.Name},
+		},
+		Data: map[string]string{outputConfigMapKey: output},
+	}
+	err := r.Create(ctx, configMap)
+	if apierrors.IsAlreadyExists(err) {
+		err = r.Update(ctx, configMap)
+	}
+	return err
+}
+
+// deleteOutputConfigMap deletes the output ConfigMap of a task run, if any
+func (r *McallTaskReconciler) deleteOutputConfigMap(ctx context.Context, namespace, name string) error {
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if err := r.Delete(ctx, configMap); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// resultOutput returns the output stored in a result, decompressed
+func resultOutput(result *mcallv1.McallTaskResult) (string, error) {
+	return DecompressOutput(result.Output, result.OutputEncoding)
+}
+
+// displayOutput returns the output stored in a result for display, or the raw output when it
+// cannot be decompressed
+func displayOutput(result *mcallv1.McallTaskResult) string {
+	output, err := resultOutput(result)
+	if err != nil {
+		return result.Output
+	}
+	return output
+}
+
+// fullOutput returns the whole output of a result: the stored output, or when it was truncated,
+// the copy in the artifact store or output ConfigMap
+func (r *McallTaskReconciler) fullOutput(ctx context.Context, namespace string, result *mcallv1.McallTaskResult) (string, error) {
+	switch {
+	case !result.OutputTruncated:
+		return resultOutput(result)
+	case result.OutputURL != "":
+		data, err := downloadArtifact(ctx, getArtifactStoreConfig(), result.OutputURL)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case result.OutputConfigMap != "":
+		var configMap corev1.ConfigMap
+		if err := r.Get(ctx, types.NamespacedName{Name: result.OutputConfigMap, Namespace: namespace}, &configMap); err != nil {
+			return "", fmt.Errorf("failed to get output ConfigMap %s: %w", result.OutputConfigMap, err)
+		}
+		return configMap.Data[outputConfigMapKey], nil
+	}
+	return result.Output, nil
+}
@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestTruncateOutput tests bounding outputs without splitting UTF-8 characters
+func TestTruncateOutput(t *testing.T) {
+	tests := []struct {
+		output        string
+		limit         int
+		want          string
+		wantTruncated bool
+	}{
+		{output: "short", limit: 10, want: "short"},
+		{output: "0123456789abc", limit: 10, want: "0123456789", wantTruncated: true},
+		{output: "ab한글", limit: 4, want: "ab", wantTruncated: true},
+		{output: "unbounded", limit: 0, want: "unbounded"},
+	}
+	for _, tt := range tests {
+		got, truncated := truncateOutput(tt.output, tt.limit)
+		if got != tt.want || truncated != tt.wantTruncated {
+			t.Errorf("truncateOutput(%q, %d) = %q, %v, want %q, %v", tt.output, tt.limit, got, truncated, tt.want, tt.wantTruncated)
+		}
+	}
+}
+
+// TestResultOutputOverflow tests truncating the stored output and keeping it whole in a ConfigMap
+func TestResultOutputOverflow(t *testing.T) {
+	t.Setenv("RESULT_OUTPUT_MAX_BYTES", "100")
+	t.Setenv("RESULT_OUTPUT_OVERFLOW_CONFIGMAP", "true")
+
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	start := metav1.NewTime(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	key := types.NamespacedName{Name: "report", Namespace: "default"}
+	task := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Spec:       mcallv1.McallTaskSpec{Type: "cmd", Input: "seq 1 1000"},
+		Status:     mcallv1.McallTaskStatus{Phase: mcallv1.McallTaskPhaseRunning, StartTime: &start},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&mcallv1.McallTask{}, &mcallv1.McallTaskRun{}).
+		WithObjects(task).
+		Build()
+	r := &McallTaskReconciler{Client: fakeClient, Scheme: scheme}
+
+	if _, err := r.handleRunning(ctx, task); err != nil {
+		t.Fatalf("handleRunning() error = %v", err)
+	}
+
+	var updated mcallv1.McallTask
+	if err := fakeClient.Get(ctx, key, &updated); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	result := updated.Status.Result
+	if result == nil || len(result.Output) != 100 || !result.OutputTruncated || result.OutputBytes != 3893 {
+		t.Fatalf("result = %+v, want 100 of 3893 bytes", result)
+	}
+	if result.OutputConfigMap != "report-20250102-030405-output" {
+		t.Errorf("OutputConfigMap = %q", result.OutputConfigMap)
+	}
+
+	var configMap corev1.ConfigMap
+	if err := fakeClient.Get(ctx, types.NamespacedName{Name: result.OutputConfigMap, Namespace: "default"}, &configMap); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if output := configMap.Data[outputConfigMapKey]; len(output) != 3893 || !strings.HasSuffix(output, "999\n1000\n") {
+		t.Errorf("ConfigMap output has %d bytes", len(output))
+	}
+
+	var taskRun mcallv1.McallTaskRun
+	if err := fakeClient.Get(ctx, types.NamespacedName{Name: updated.Status.LastTaskRun, Namespace: "default"}, &taskRun); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if taskRun.Status.Result == nil || taskRun.Status.Result.OutputConfigMap != result.OutputConfigMap {
+		t.Errorf("task run result = %+v, want the output ConfigMap", taskRun.Status.Result)
+	}
+}
+
+// TestResultOutputCompression tests storing large outputs compressed and reading them back whole
+func TestResultOutputCompression(t *testing.T) {
+	t.Setenv("RESULT_OUTPUT_MAX_BYTES", "2048")
+	t.Setenv("RESULT_OUTPUT_COMPRESSION", "gzip")
+	t.Setenv("RESULT_OUTPUT_COMPRESSION_THRESHOLD", "1024")
+
+	task := &mcallv1.McallTask{ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "default"}}
+	verbose := strings.Repeat("INFO request served in 12ms\n", 500)
+	random := make([]byte, 4096)
+	source := rand.New(rand.NewSource(1))
+	for i := range random {
+		random[i] = byte('!' + source.Intn(94))
+	}
+
+	tests := []struct {
+		name          string
+		output        string
+		wantEncoding  string
+		wantTruncated bool
+	}{
+		{name: "below threshold", output: "ok\n"},
+		{name: "compressed", output: verbose, wantEncoding: OutputEncodingGzip},
+		{name: "too large compressed", output: string(random), wantTruncated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := newTaskResult(task, tt.output)
+			if result.OutputEncoding != tt.wantEncoding || result.OutputTruncated != tt.wantTruncated {
+				t.Fatalf("result encoding = %q, truncated = %v, want %q, %v",
+					result.OutputEncoding, result.OutputTruncated, tt.wantEncoding, tt.wantTruncated)
+			}
+			if len(result.Output) > 2048 {
+				t.Errorf("stored output has %d bytes", len(result.Output))
+			}
+			if tt.wantTruncated {
+				return
+			}
+			r := &McallTaskReconciler{}
+			if output, err := r.fullOutput(context.Background(), "default", result); err != nil || output != tt.output {
+				t.Errorf("fullOutput() = %d bytes, %v, want %d bytes", len(output), err, len(tt.output))
+			}
+			if got := displayOutput(result); got != tt.output {
+				t.Errorf("displayOutput() has %d bytes", len(got))
+			}
+		})
+	}
+}
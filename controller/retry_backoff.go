@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"math/rand"
+	"time"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// Retry backoff defaults used when the task doesn't set them
+const (
+	defaultRetryInitialDelay = 5 * time.Second
+	defaultRetryMaxDelay     = 5 * time.Minute
+	defaultRetryJitter       = 10
+)
+
+// retryDelay returns the delay before retry number attempt+1: the initial delay doubled
+// per previous attempt, capped at the maximum, with random jitter applied
+func retryDelay(backoff *mcallv1.RetryBackoff, attempt int32) time.Duration {
+	initial, maxDelay, jitter := defaultRetryInitialDelay, defaultRetryMaxDelay, int32(defaultRetryJitter)
+	if backoff != nil {
+		if backoff.InitialDelaySeconds > 0 {
+			initial = time.Duration(backoff.InitialDelaySeconds) * time.Second
+		}
+		if backoff.MaxDelaySeconds > 0 {
+			maxDelay = time.Duration(backoff.MaxDelaySeconds) * time.Second
+		}
+		if backoff.JitterPercent != nil {
+			jitter = *backoff.JitterPercent
+		}
+	}
+
+	delay := initial
+	for i := int32(0); i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	if jitter > 0 {
+		spread := int64(delay) * int64(jitter) / 100
+		delay += time.Duration(rand.Int63n(2*spread+1) - spread)
+	}
+	return delay
+}
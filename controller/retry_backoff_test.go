@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestRetryDelay tests the exponential backoff between task retries
+func TestRetryDelay(t *testing.T) {
+	noJitter := int32(0)
+
+	tests := []struct {
+		name    string
+		backoff *mcallv1.RetryBackoff
+		attempt int32
+		min     time.Duration
+		max     time.Duration
+	}{
+		{name: "default first retry with jitter", attempt: 0, min: 4500 * time.Millisecond, max: 5500 * time.Millisecond},
+		{name: "doubles per attempt", backoff: &mcallv1.RetryBackoff{InitialDelaySeconds: 2, JitterPercent: &noJitter}, attempt: 3, min: 16 * time.Second, max: 16 * time.Second},
+		{name: "capped at max delay", backoff: &mcallv1.RetryBackoff{InitialDelaySeconds: 10, MaxDelaySeconds: 30, JitterPercent: &noJitter}, attempt: 5, min: 30 * time.Second, max: 30 * time.Second},
+		{name: "default cap for many attempts", backoff: &mcallv1.RetryBackoff{JitterPercent: &noJitter}, attempt: 100, min: 5 * time.Minute, max: 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				if got := retryDelay(tt.backoff, tt.attempt); got < tt.min || got > tt.max {
+					t.Fatalf("retryDelay() = %v, want between %v and %v", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+// TestTaskRetry tests that a failed execution is retried before the task is marked Failed
+func TestTaskRetry(t *testing.T) {
+	t.Setenv("TASK_RUN_HISTORY_LIMIT", "0")
+
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "flaky-task", Namespace: "default"}
+	task := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Spec: mcallv1.McallTaskSpec{
+			Type:         "cmd",
+			Input:        "exit 1",
+			RetryCount:   1,
+			RetryBackoff: &mcallv1.RetryBackoff{InitialDelaySeconds: 30},
+		},
+		Status: mcallv1.McallTaskStatus{
+			Phase:     mcallv1.McallTaskPhaseRunning,
+			StartTime: &metav1.Time{Time: time.Now()},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&mcallv1.McallTask{}).
+		WithObjects(task).
+		Build()
+	r := &McallTaskReconciler{Client: fakeClient, Scheme: scheme}
+
+	get := func() *mcallv1.McallTask {
+		var latest mcallv1.McallTask
+		if err := fakeClient.Get(ctx, key, &latest); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		return &latest
+	}
+
+	// First failure schedules a retry
+	result, err := r.handleRunning(ctx, get())
+	if err != nil {
+		t.Fatalf("handleRunning() error = %v", err)
+	}
+	latest := get()
+	if latest.Status.Phase != mcallv1.McallTaskPhasePending || latest.Status.RetryCount != 1 || latest.Status.NextRetryTime == nil {
+		t.Fatalf("after first failure status = %+v, want Pending with retry scheduled", latest.Status)
+	}
+	if result.RequeueAfter <= 0 {
+		t.Errorf("RequeueAfter = %v, want the retry delay", result.RequeueAfter)
+	}
+
+	// The retry waits for its backoff
+	if result, err := r.handlePending(ctx, latest); err != nil || result.RequeueAfter <= 0 {
+		t.Fatalf("handlePending() = %v, %v, want to wait for the backoff", result, err)
+	}
+	if get().Status.Phase != mcallv1.McallTaskPhasePending {
+		t.Fatalf("task started before its backoff elapsed")
+	}
+
+	// Once due, the retry runs and its failure marks the task Failed
+	latest.Status.NextRetryTime = &metav1.Time{Time: time.Now().Add(-time.Second)}
+	if err := fakeClient.Status().Update(ctx, latest); err != nil {
+		t.Fatalf("Status().Update() error = %v", err)
+	}
+	if _, err := r.handlePending(ctx, get()); err != nil {
+		t.Fatalf("handlePending() error = %v", err)
+	}
+	latest = get()
+	if latest.Status.Phase != mcallv1.McallTaskPhaseRunning || latest.Status.LastRetryTime == nil || latest.Status.RetryCount != 1 {
+		t.Fatalf("retry status = %+v, want Running with lastRetryTime", latest.Status)
+	}
+
+	if _, err := r.handleRunning(ctx, latest); err != nil {
+		t.Fatalf("handleRunning() error = %v", err)
+	}
+	if phase := get().Status.Phase; phase != mcallv1.McallTaskPhaseFailed {
+		t.Errorf("phase after retries exhausted = %s, want Failed", phase)
+	}
+}
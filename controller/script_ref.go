@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// resolveScript reads the script of a cmd task from the ConfigMap of its scriptRef
+func (r *McallTaskReconciler) resolveScript(ctx context.Context, task *mcallv1.McallTask) (string, error) {
+	ref := task.Spec.ScriptRef
+	if task.Spec.Type != "cmd" {
+		return "", fmt.Errorf("scriptRef is only supported by cmd tasks, not %s", task.Spec.Type)
+	}
+
+	var configMap corev1.ConfigMap
+	if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: task.Namespace}, &configMap); err != nil {
+		return "", fmt.Errorf("failed to get script ConfigMap %s: %w", ref.Name, err)
+	}
+	script, exists := configMap.Data[ref.Key]
+	if !exists {
+		return "", fmt.Errorf("script ConfigMap %s has no key %q", ref.Name, ref.Key)
+	}
+	if strings.TrimSpace(script) == "" {
+		return "", fmt.Errorf("script %s/%s is empty", ref.Name, ref.Key)
+	}
+	return script, nil
+}
+
+// executeScript writes the script to a temporary file and runs it with the interpreter
+// of its #! line, or the task's shell, passing args as the script arguments
+func executeScript(script string, args []string, timeout time.Duration, opts commandOptions) (string, error) {
+	if strings.TrimSpace(script) == "" {
+		return "", fmt.Errorf("empty script")
+	}
+	_, interpreter, err := shellCommand(opts.Shell)
+	if err != nil {
+		return "", err
+	}
+
+	// pwsh -File only runs .ps1 files
+	pattern := "mcall-script-*"
+	if opts.Shell == "pwsh" {
+		pattern += ".ps1"
+	}
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create script file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(script)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write script file: %w", err)
+	}
+
+	// The interpreter runs the file, so it doesn't have to be executable
+	if shebang, _, _ := strings.Cut(script, "\n"); strings.HasPrefix(shebang, "#!") {
+		if interpreter = strings.Fields(shebang[2:]); len(interpreter) == 0 {
+			return "", fmt.Errorf("invalid #! line %q", shebang)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	commandArgs := append(append(interpreter[1:], file.Name()), args...)
+	cmd := exec.CommandContext(ctx, interpreter[0], commandArgs...)
+	cmd.Dir = opts.WorkingDir
+	if opts.Stdin != "" {
+		cmd.Stdin = strings.NewReader(opts.Stdin)
+	}
+	return runCommand(ctx, cmd, opts.Env)
+}
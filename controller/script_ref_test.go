@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestResolveScript tests reading the script of a cmd task from its ConfigMap
+func TestResolveScript(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	r := &McallTaskReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "scripts", Namespace: "default"},
+			Data:       map[string]string{"check.sh": "echo ok\n", "empty.sh": "\n"},
+		}).Build(),
+		Scheme: scheme,
+	}
+
+	tests := []struct {
+		name     string
+		taskType string
+		ref      mcallv1.ScriptRef
+		want     string
+		wantErr  string
+	}{
+		{name: "script", taskType: "cmd", ref: mcallv1.ScriptRef{Name: "scripts", Key: "check.sh"}, want: "echo ok\n"},
+		{name: "missing key", taskType: "cmd", ref: mcallv1.ScriptRef{Name: "scripts", Key: "other.sh"}, wantErr: `has no key "other.sh"`},
+		{name: "empty script", taskType: "cmd", ref: mcallv1.ScriptRef{Name: "scripts", Key: "empty.sh"}, wantErr: "is empty"},
+		{name: "missing ConfigMap", taskType: "cmd", ref: mcallv1.ScriptRef{Name: "missing", Key: "check.sh"}, wantErr: "failed to get script ConfigMap missing"},
+		{name: "not a cmd task", taskType: "get", ref: mcallv1.ScriptRef{Name: "scripts", Key: "check.sh"}, wantErr: "only supported by cmd tasks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref := tt.ref
+			task := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{Name: "check", Namespace: "default"},
+				Spec:       mcallv1.McallTaskSpec{Type: tt.taskType, ScriptRef: &ref},
+			}
+			script, err := r.resolveScript(context.Background(), task)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveScript() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveScript() error = %v", err)
+			}
+			if script != tt.want {
+				t.Errorf("resolveScript() = %q, want %q", script, tt.want)
+			}
+		})
+	}
+}
+
+// TestExecuteScript tests running scripts with bash or their #! interpreter
+func TestExecuteScript(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		args       []string
+		timeout    time.Duration
+		wantOutput string
+		wantErr    string
+	}{
+		{name: "bash by default", script: "set -e\nfor arg in \"$@\"; do\n  echo \"arg: $arg\"\ndone\n[[ $# -eq 2 ]] && echo bash\n", args: []string{"a", "b"}, wantOutput: "arg: a\narg: b\nbash\n"},
+		{name: "shebang", script: "#!/bin/sh -e\necho \"sh $1\"\n", args: []string{"x"}, wantOutput: "sh x\n"},
+		{name: "failing script", script: "echo broken >&2\nexit 3\n", wantOutput: "broken\n", wantErr: "exit status 3"},
+		{name: "timeout", script: "sleep 5\n", timeout: 200 * time.Millisecond, wantErr: "timed out"},
+		{name: "empty script", script: " \n", wantErr: "empty script"},
+		{name: "invalid shebang", script: "#!\necho\n", wantErr: "invalid #! line"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = 5 * time.Second
+			}
+			output, err := executeScript(tt.script, tt.args, timeout, commandOptions{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("executeScript() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("executeScript() error = %v", err)
+			}
+			if output != tt.wantOutput {
+				t.Errorf("output = %q, want %q", output, tt.wantOutput)
+			}
+		})
+	}
+
+	// A cmd task with scriptRef passes its input as the arguments
+	task := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: "check", Namespace: "default"},
+		Spec: mcallv1.McallTaskSpec{
+			Type:      "cmd",
+			Input:     "--region eu",
+			ScriptRef: &mcallv1.ScriptRef{Name: "scripts", Key: "check.sh"},
+		},
+	}
+	output, _, err := executeTaskWithEnv(task, 5*time.Second, logr.Discard(), executionEnv{script: "echo \"$2\"\n"})
+	if err != nil || output != "eu\n" {
+		t.Errorf("executeTaskWithEnv() = %q, %v, want %q", output, err, "eu\n")
+	}
+}
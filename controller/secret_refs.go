@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// secretMask replaces the values of secretRefs in outputs and logs
+const secretMask = "****"
+
+// resolveSecretRefs reads the secretRefs of a task into environment variables. It also
+// returns the values read from Secrets, Vault and AWS, which must be masked; default values aren't secret.
+func (r *McallTaskReconciler) resolveSecretRefs(ctx context.Context, task *mcallv1.McallTask) (map[string]string, []string, error) {
+	environment := make(map[string]string, len(task.Spec.SecretRefs))
+	var secrets []string
+	sources := &secretSources{r: r, namespace: task.Namespace}
+
+	for _, ref := range task.Spec.SecretRefs {
+		data, source, err := sources.read(ctx, ref)
+		if err != nil {
+			return nil, nil, fmt.Errorf("secretRef %s: %w", ref.Name, err)
+		}
+
+		value, exists := data[ref.Key]
+		switch {
+		case exists:
+			environment[ref.Name] = value
+			secrets = append(secrets, value)
+		case ref.DefaultValue != "":
+			environment[ref.Name] = ref.DefaultValue
+		case data == nil:
+			return nil, nil, fmt.Errorf("secretRef %s: %s not found and no defaultValue", ref.Name, source)
+		default:
+			return nil, nil, fmt.Errorf("secretRef %s: %s has no key %q and no defaultValue", ref.Name, source, ref.Key)
+		}
+	}
+	return environment, secrets, nil
+}
+
+// secretSources reads the Secrets, Vault secrets and AWS secrets of secretRefs, each once per execution
+type secretSources struct {
+	r         *McallTaskReconciler
+	namespace string
+	cache     map[string]map[string]string
+	vault     map[string]*vaultClient
+	aws       *awsClient
+}
+
+// read returns the data of the source of a secretRef and its description; the data is nil
+// when the source doesn't exist
+func (s *secretSources) read(ctx context.Context, ref mcallv1.SecretEnvRef) (map[string]string, string, error) {
+	sources := 0
+	for _, set := range []bool{ref.SecretName != "", ref.Vault != nil, ref.AWS != nil} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return nil, "", fmt.Errorf("set exactly one of secretName, vault and aws")
+	}
+	if ref.Key == "" && ref.AWS == nil {
+		return nil, "", fmt.Errorf("key is required")
+	}
+	if s.cache == nil {
+		s.cache = make(map[string]map[string]string)
+	}
+
+	if ref.AWS != nil {
+		return s.readAWS(ctx, ref.AWS)
+	}
+
+	if ref.Vault != nil {
+		config := getVaultConfig()
+		role := ref.Vault.Role
+		if role == "" {
+			role = config.Role
+		}
+		source := "Vault secret " + ref.Vault.Path
+		cacheKey := "vault/" + role + "/" + ref.Vault.Path
+		if data, cached := s.cache[cacheKey]; cached {
+			return data, source, nil
+		}
+		// One login per role and execution, so tokens never outlive the execution
+		client, exists := s.vault[role]
+		if !exists {
+			var err error
+			if client, err = newVaultClient(ctx, config, role); err != nil {
+				return nil, "", err
+			}
+			if s.vault == nil {
+				s.vault = make(map[string]*vaultClient)
+			}
+			s.vault[role] = client
+		}
+		data, err := client.read(ctx, ref.Vault.Path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read %s: %w", source, err)
+		}
+		s.cache[cacheKey] = data
+		return data, source, nil
+	}
+
+	source := "Secret " + ref.SecretName
+	cacheKey := "secret/" + ref.SecretName
+	if data, cached := s.cache[cacheKey]; cached {
+		return data, source, nil
+	}
+	var secret corev1.Secret
+	if err := s.r.Get(ctx, types.NamespacedName{Name: ref.SecretName, Namespace: s.namespace}, &secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, "", fmt.Errorf("failed to get %s: %w", source, err)
+		}
+		s.cache[cacheKey] = nil
+		return nil, source, nil
+	}
+	data := make(map[string]string, len(secret.Data))
+	for key, value := range secret.Data {
+		data[key] = string(value)
+	}
+	s.cache[cacheKey] = data
+	return data, source, nil
+}
+
+// readAWS returns the data of an AWS secret or parameter and its description
+func (s *secretSources) readAWS(ctx context.Context, ref *mcallv1.AWSSecretRef) (map[string]string, string, error) {
+	if (ref.SecretID == "") == (ref.Parameter == "") {
+		return nil, "", fmt.Errorf("set exactly one of aws.secretId and aws.parameter")
+	}
+	source, cacheKey := "AWS secret "+ref.SecretID, "aws/"+ref.Region+"/secret/"+ref.SecretID
+	if ref.Parameter != "" {
+		source, cacheKey = "SSM parameter "+ref.Parameter, "aws/"+ref.Region+"/parameter/"+ref.Parameter
+	}
+	if data, cached := s.cache[cacheKey]; cached {
+		return data, source, nil
+	}
+	// Credentials are requested once per execution, like Vault tokens
+	if s.aws == nil {
+		client, err := newAWSClient(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+		s.aws = client
+	}
+	data, err := s.aws.read(ctx, ref)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", source, err)
+	}
+	s.cache[cacheKey] = data
+	return data, source, nil
+}
+
+// taskEnvironment returns the environment of an execution: spec.environment and the secretRefs
+func taskEnvironment(task *mcallv1.McallTask, env executionEnv) map[string]string {
+	if len(env.secretEnv) == 0 {
+		return task.Spec.Environment
+	}
+	environment := make(map[string]string, len(task.Spec.Environment)+len(env.secretEnv))
+	for name, value := range task.Spec.Environment {
+		environment[name] = value
+	}
+	for name, value := range env.secretEnv {
+		environment[name] = value
+	}
+	return environment
+}
+
+// maskSecrets replaces every secret value in s with a mask
+func maskSecrets(s string, secrets []string) string {
+	if len(secrets) == 0 || s == "" {
+		return s
+	}
+	// Longer values first, so a value containing another one is masked whole
+	sorted := append([]string(nil), secrets...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	for _, secret := range sorted {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, secretMask)
+		}
+	}
+	return s
+}
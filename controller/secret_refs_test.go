@@ -0,0 +1,340 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestResolveSecretRefs tests reading secretRefs with their defaultValue fallback
+func TestResolveSecretRefs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	r := &McallTaskReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Data:       map[string][]byte{"token": []byte("t0ken-value"), "user": []byte("svc")},
+		}).Build(),
+		Scheme: scheme,
+	}
+
+	tests := []struct {
+		name        string
+		refs        []mcallv1.SecretEnvRef
+		wantEnv     map[string]string
+		wantSecrets []string
+		wantErr     string
+	}{
+		{
+			name: "values",
+			refs: []mcallv1.SecretEnvRef{
+				{Name: "API_TOKEN", SecretName: "api", Key: "token"},
+				{Name: "API_USER", SecretName: "api", Key: "user"},
+			},
+			wantEnv:     map[string]string{"API_TOKEN": "t0ken-value", "API_USER": "svc"},
+			wantSecrets: []string{"t0ken-value", "svc"},
+		},
+		{
+			name: "default values",
+			refs: []mcallv1.SecretEnvRef{
+				{Name: "REGION", SecretName: "api", Key: "region", DefaultValue: "eu"},
+				{Name: "PASSWORD", SecretName: "missing", Key: "password", DefaultValue: "none"},
+			},
+			wantEnv: map[string]string{"REGION": "eu", "PASSWORD": "none"},
+		},
+		{
+			name:    "missing key",
+			refs:    []mcallv1.SecretEnvRef{{Name: "REGION", SecretName: "api", Key: "region"}},
+			wantErr: `secretRef REGION: Secret api has no key "region"`,
+		},
+		{
+			name:    "missing Secret",
+			refs:    []mcallv1.SecretEnvRef{{Name: "PASSWORD", SecretName: "missing", Key: "password"}},
+			wantErr: "secretRef PASSWORD: Secret missing not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{Name: "check", Namespace: "default"},
+				Spec:       mcallv1.McallTaskSpec{Type: "cmd", SecretRefs: tt.refs},
+			}
+			environment, secrets, err := r.resolveSecretRefs(context.Background(), task)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveSecretRefs() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveSecretRefs() error = %v", err)
+			}
+			if !reflect.DeepEqual(environment, tt.wantEnv) {
+				t.Errorf("environment = %v, want %v", environment, tt.wantEnv)
+			}
+			if !reflect.DeepEqual(secrets, tt.wantSecrets) {
+				t.Errorf("secrets = %v, want %v", secrets, tt.wantSecrets)
+			}
+		})
+	}
+}
+
+// newFakeVaultServer returns a Vault API serving kubernetes auth logins for the role "mcall"
+// and the secrets "secret/data/api" (KV v2) and "kv/legacy" (KV v1)
+func newFakeVaultServer(t *testing.T) *httptest.Server {
+	logins := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/auth/kubernetes/login" {
+			var login struct{ Role, JWT string }
+			_ = json.NewDecoder(r.Body).Decode(&login)
+			if login.Role != "mcall" || login.JWT != "sa-jwt" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"errors":["invalid role name"]}`))
+				return
+			}
+			logins++
+			_, _ = w.Write([]byte(`{"auth":{"client_token":"s.token"}}`))
+			return
+		}
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/api":
+			_, _ = w.Write([]byte(`{"data":{"data":{"token":"v4ult-token","port":5432},"metadata":{"version":3}}}`))
+		case "/v1/kv/legacy":
+			_, _ = w.Write([]byte(`{"data":{"password":"legacy-pass"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	t.Cleanup(func() {
+		server.Close()
+		if logins > 1 {
+			t.Errorf("%d Vault logins, want at most one per resolution", logins)
+		}
+	})
+	return server
+}
+
+// TestResolveSecretRefsVault tests reading secretRefs from Vault with kubernetes auth
+func TestResolveSecretRefsVault(t *testing.T) {
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenPath, []byte("sa-jwt\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VAULT_SERVICE_ACCOUNT_TOKEN_PATH", tokenPath)
+	t.Setenv("VAULT_ROLE", "mcall")
+	r := &McallTaskReconciler{}
+
+	tests := []struct {
+		name        string
+		address     bool
+		refs        []mcallv1.SecretEnvRef
+		wantEnv     map[string]string
+		wantSecrets []string
+		wantErr     string
+	}{
+		{
+			name:    "KV v2 and v1",
+			address: true,
+			refs: []mcallv1.SecretEnvRef{
+				{Name: "API_TOKEN", Vault: &mcallv1.VaultSecretRef{Path: "secret/data/api"}, Key: "token"},
+				{Name: "DB_PORT", Vault: &mcallv1.VaultSecretRef{Path: "secret/data/api"}, Key: "port"},
+				{Name: "PASSWORD", Vault: &mcallv1.VaultSecretRef{Path: "kv/legacy", Role: "mcall"}, Key: "password"},
+			},
+			wantEnv:     map[string]string{"API_TOKEN": "v4ult-token", "DB_PORT": "5432", "PASSWORD": "legacy-pass"},
+			wantSecrets: []string{"v4ult-token", "5432", "legacy-pass"},
+		},
+		{
+			name:    "default value",
+			address: true,
+			refs:    []mcallv1.SecretEnvRef{{Name: "REGION", Vault: &mcallv1.VaultSecretRef{Path: "secret/data/missing"}, Key: "region", DefaultValue: "eu"}},
+			wantEnv: map[string]string{"REGION": "eu"},
+		},
+		{
+			name:    "missing secret",
+			address: true,
+			refs:    []mcallv1.SecretEnvRef{{Name: "REGION", Vault: &mcallv1.VaultSecretRef{Path: "secret/data/missing"}, Key: "region"}},
+			wantErr: "secretRef REGION: Vault secret secret/data/missing not found",
+		},
+		{
+			name:    "login failure",
+			address: true,
+			refs:    []mcallv1.SecretEnvRef{{Name: "API_TOKEN", Vault: &mcallv1.VaultSecretRef{Path: "secret/data/api", Role: "other"}, Key: "token"}},
+			wantErr: "Vault login with role other failed: status 400: invalid role name",
+		},
+		{
+			name:    "no address",
+			refs:    []mcallv1.SecretEnvRef{{Name: "API_TOKEN", Vault: &mcallv1.VaultSecretRef{Path: "secret/data/api"}, Key: "token"}},
+			wantErr: "VAULT_ADDR is not set",
+		},
+		{
+			name:    "secretName and vault",
+			address: true,
+			refs:    []mcallv1.SecretEnvRef{{Name: "API_TOKEN", SecretName: "api", Vault: &mcallv1.VaultSecretRef{Path: "secret/data/api"}, Key: "token"}},
+			wantErr: "set exactly one of secretName, vault and aws",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address := ""
+			if tt.address {
+				address = newFakeVaultServer(t).URL
+			}
+			t.Setenv("VAULT_ADDR", address)
+
+			task := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{Name: "check", Namespace: "default"},
+				Spec:       mcallv1.McallTaskSpec{Type: "cmd", SecretRefs: tt.refs},
+			}
+			environment, secrets, err := r.resolveSecretRefs(context.Background(), task)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveSecretRefs() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveSecretRefs() error = %v", err)
+			}
+			if !reflect.DeepEqual(environment, tt.wantEnv) {
+				t.Errorf("environment = %v, want %v", environment, tt.wantEnv)
+			}
+			if !reflect.DeepEqual(secrets, tt.wantSecrets) {
+				t.Errorf("secrets = %v, want %v", secrets, tt.wantSecrets)
+			}
+		})
+	}
+}
+
+// TestMaskSecrets tests masking secret values in outputs, longest values first
+func TestMaskSecrets(t *testing.T) {
+	secrets := []string{"abc", "abcdef", ""}
+	if got := maskSecrets("token=abcdef user=abc", secrets); got != "token=**** user=****" {
+		t.Errorf("maskSecrets() = %q", got)
+	}
+	if got := maskSecrets("nothing secret", nil); got != "nothing secret" {
+		t.Errorf("maskSecrets() = %q", got)
+	}
+}
+
+// TestMaskOutput tests masking secretRefs and sensitive data, and opting out of the latter
+func TestMaskOutput(t *testing.T) {
+	output := `{"user":"svc","password":"hunter2"} bearer t0ken-value`
+	if got := maskOutput(output, []string{"t0ken-value"}, nil); got != `{"user":"svc","password":"***MASKED***"} bearer ****` {
+		t.Errorf("maskOutput() = %q", got)
+	}
+
+	// A worker result is stored and sent to the logging backend masked
+	worker := NewTaskWorker(`echo "api_key: abc123"`, "cmd", "input-1", "")
+	worker.Execute(5 * time.Second)
+	if result := <-worker.result; strings.Contains(result.Content, "abc123") || strings.Contains(result.Input, "abc123") {
+		t.Errorf("result = %+v, want the API key masked", result)
+	}
+
+	t.Setenv("MASK_SENSITIVE_DATA", "false")
+	if got := maskOutput(output, []string{"t0ken-value"}, nil); got != `{"user":"svc","password":"hunter2"} bearer ****` {
+		t.Errorf("maskOutput() with MASK_SENSITIVE_DATA=false = %q", got)
+	}
+}
+
+// TestMaskedJSONOutput tests that masked JSON outputs stay valid and usable by JSONPath input sources
+func TestMaskedJSONOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{name: "compact", output: `{"user":"svc","password":"hunter2","count":3}`, want: `{"user":"svc","password":"***MASKED***","count":3}`},
+		{name: "indented", output: "{\n  \"api_key\": \"abc123\",\n  \"count\": 3\n}", want: "{\n  \"api_key\": \"***MASKED***\",\n  \"count\": 3\n}"},
+		{name: "key=value", output: "login user=svc password=hunter2 ok", want: "login user=svc password=***MASKED*** ok"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maskOutput(tt.output, nil, nil); got != tt.want {
+				t.Errorf("maskOutput() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	masked := maskOutput(`{"token":"t0ken-value","items":{"count":3}}`, nil, nil)
+	if !json.Valid([]byte(masked)) {
+		t.Fatalf("masked output %q is not valid JSON", masked)
+	}
+	refTask := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: "login", Namespace: "default"},
+		Status: mcallv1.McallTaskStatus{
+			Phase:  mcallv1.McallTaskPhaseSucceeded,
+			Result: &mcallv1.McallTaskResult{Output: masked, ErrorCode: "0"},
+		},
+	}
+	currentTask := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "default"},
+		Spec: mcallv1.McallTaskSpec{InputSources: []mcallv1.TaskInputSource{
+			{Name: "COUNT", TaskRef: "login", Field: "output", JSONPath: "$.items.count"},
+		}},
+	}
+	reconciler := &McallTaskReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(refTask, currentTask).Build(),
+		Scheme: scheme,
+	}
+	_, envVars, err := reconciler.processInputSources(context.Background(), currentTask)
+	if err != nil {
+		t.Fatalf("processInputSources() error = %v", err)
+	}
+	if envVars["COUNT"] != "3" {
+		t.Errorf("envVars[COUNT] = %q, want %q", envVars["COUNT"], "3")
+	}
+}
+
+// TestSecretRefsExecution tests exporting secretRefs to commands with their values masked
+func TestSecretRefsExecution(t *testing.T) {
+	task := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: "check", Namespace: "default"},
+		Spec: mcallv1.McallTaskSpec{
+			Type:        "cmd",
+			Input:       `echo "env $API_TOKEN, input ${API_TOKEN}, region $REGION"`,
+			Environment: map[string]string{"REGION": "eu", "API_TOKEN": "overridden"},
+		},
+	}
+	env := executionEnv{
+		secretEnv: map[string]string{"API_TOKEN": "t0ken-value"},
+		secrets:   []string{"t0ken-value"},
+	}
+
+	output, _, err := executeTaskWithEnv(task, 5*time.Second, logr.Discard(), env)
+	if err != nil {
+		t.Fatalf("executeTaskWithEnv() error = %v", err)
+	}
+	if strings.TrimSpace(output) != "env ****, input ****, region eu" {
+		t.Errorf("output = %q", output)
+	}
+	if strings.Contains(task.Spec.Environment["API_TOKEN"], "t0ken") {
+		t.Errorf("secret value stored in spec.environment")
+	}
+}
@@ -0,0 +1,454 @@
+package controller
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// SFTP version 3 packet types used by sftp tasks
+const (
+	sftpInit     = 1
+	sftpVersion  = 2
+	sftpOpen     = 3
+	sftpClose    = 4
+	sftpRead     = 5
+	sftpWrite    = 6
+	sftpOpendir  = 11
+	sftpReaddir  = 12
+	sftpRemove   = 13
+	sftpStat     = 17
+	sftpStatus   = 101
+	sftpHandle   = 102
+	sftpData     = 103
+	sftpName     = 104
+	sftpAttrs    = 105
+	sftpStatusOK = 0
+	sftpEOF      = 1
+
+	sftpOpenRead  = 0x01
+	sftpOpenWrite = 0x02
+	sftpOpenCreat = 0x08
+	sftpOpenTrunc = 0x10
+
+	sftpAttrSize        = 0x01
+	sftpAttrUIDGID      = 0x02
+	sftpAttrPermissions = 0x04
+	sftpAttrACModTime   = 0x08
+	sftpAttrExtended    = 0x80000000
+
+	sftpChunkSize = 32 * 1024
+)
+
+// sftpClient speaks SFTP version 3 over the "sftp" subsystem of an SSH session
+type sftpClient struct {
+	ssh       *ssh.Client
+	w         io.WriteCloser
+	r         io.Reader
+	requestID uint32
+}
+
+// dialSFTP logs in to an SSH server and starts the sftp subsystem
+func dialSFTP(addr string, transfer *mcallv1.FileTransfer, credentials *fileTransferCredentials, timeout time.Duration) (*sftpClient, error) {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "22")
+	}
+
+	config := &ssh.ClientConfig{User: credentials.username, Timeout: timeout}
+	switch {
+	case transfer.HostKey != "":
+		hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(transfer.HostKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid sftp hostKey: %w", err)
+		}
+		config.HostKeyCallback = ssh.FixedHostKey(hostKey)
+	case transfer.InsecureIgnoreHostKey:
+		config.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+	default:
+		return nil, fmt.Errorf("sftp task requires spec.sftp.hostKey or insecureIgnoreHostKey")
+	}
+	if len(credentials.privateKey) > 0 {
+		signer, err := ssh.ParsePrivateKey(credentials.privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sftp privateKey: %w", err)
+		}
+		config.Auth = append(config.Auth, ssh.PublicKeys(signer))
+	}
+	if credentials.password != "" {
+		config.Auth = append(config.Auth, ssh.Password(credentials.password))
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	// The deadline bounds the whole task, including the transfers
+	conn.SetDeadline(time.Now().Add(timeout))
+	sshConn, channels, requests, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("ssh login to %s failed: %w", addr, err)
+	}
+	sshClient := ssh.NewClient(sshConn, channels, requests)
+
+	client, err := newSFTPClient(sshClient)
+	if err != nil {
+		sshClient.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// newSFTPClient starts the sftp subsystem and negotiates the protocol version
+func newSFTPClient(sshClient *ssh.Client) (*sftpClient, error) {
+	session, err := sshClient.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ssh session: %w", err)
+	}
+	w, err := session.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	r, err := session.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		return nil, fmt.Errorf("sftp subsystem unavailable: %w", err)
+	}
+
+	client := &sftpClient{ssh: sshClient, w: w, r: r}
+	var init sftpPacket
+	init.uint32(3)
+	if err := client.send(sftpInit, init.Bytes()); err != nil {
+		return nil, err
+	}
+	packetType, _, err := client.receive()
+	if err != nil {
+		return nil, err
+	}
+	if packetType != sftpVersion {
+		return nil, fmt.Errorf("unexpected sftp packet %d, expected version", packetType)
+	}
+	return client, nil
+}
+
+// Close ends the session
+func (c *sftpClient) Close() error {
+	c.w.Close()
+	return c.ssh.Close()
+}
+
+// List returns the entries of a directory, without "." and ".."
+func (c *sftpClient) List(dir string) ([]remoteFile, error) {
+	handle, err := c.openHandle(sftpOpendir, func(p *sftpPacket) { p.string(dir) })
+	if err != nil {
+		return nil, err
+	}
+	defer c.closeHandle(handle)
+
+	files := []remoteFile{}
+	for {
+		packetType, payload, err := c.request(sftpReaddir, func(p *sftpPacket) { p.string(handle) })
+		if err != nil {
+			return nil, err
+		}
+		if packetType == sftpStatus {
+			if err := sftpStatusError(payload); err != io.EOF {
+				return nil, err
+			}
+			return files, nil
+		}
+		if packetType != sftpName {
+			return nil, fmt.Errorf("unexpected sftp packet %d, expected name", packetType)
+		}
+
+		d := &sftpDecoder{buf: payload}
+		for i := d.uint32(); i > 0 && d.err == nil; i-- {
+			name := d.string()
+			d.string() // longname
+			size, permissions := d.attrs()
+			if name == "." || name == ".." {
+				continue
+			}
+			files = append(files, remoteFile{Name: name, Size: size, Dir: permissions&0o170000 == 0o040000})
+		}
+		if d.err != nil {
+			return nil, d.err
+		}
+	}
+}
+
+// Size returns the size of a file
+func (c *sftpClient) Size(file string) (int64, error) {
+	packetType, payload, err := c.request(sftpStat, func(p *sftpPacket) { p.string(file) })
+	if err != nil {
+		return 0, err
+	}
+	if packetType == sftpStatus {
+		return 0, sftpStatusError(payload)
+	}
+	if packetType != sftpAttrs {
+		return 0, fmt.Errorf("unexpected sftp packet %d, expected attrs", packetType)
+	}
+	d := &sftpDecoder{buf: payload}
+	size, _ := d.attrs()
+	return size, d.err
+}
+
+// Download reads a file to the end and returns its size
+func (c *sftpClient) Download(file string) (int64, error) {
+	handle, err := c.openHandle(sftpOpen, func(p *sftpPacket) {
+		p.string(file)
+		p.uint32(sftpOpenRead)
+		p.uint32(0) // attrs
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer c.closeHandle(handle)
+
+	var size int64
+	for {
+		packetType, payload, err := c.request(sftpRead, func(p *sftpPacket) {
+			p.string(handle)
+			p.uint64(uint64(size))
+			p.uint32(sftpChunkSize)
+		})
+		if err != nil {
+			return size, err
+		}
+		if packetType == sftpStatus {
+			if err := sftpStatusError(payload); err != io.EOF {
+				return size, err
+			}
+			return size, nil
+		}
+		if packetType != sftpData {
+			return size, fmt.Errorf("unexpected sftp packet %d, expected data", packetType)
+		}
+		d := &sftpDecoder{buf: payload}
+		size += int64(len(d.string()))
+		if d.err != nil {
+			return size, d.err
+		}
+	}
+}
+
+// Upload creates or truncates a file and writes data to it
+func (c *sftpClient) Upload(file string, data []byte) error {
+	handle, err := c.openHandle(sftpOpen, func(p *sftpPacket) {
+		p.string(file)
+		p.uint32(sftpOpenWrite | sftpOpenCreat | sftpOpenTrunc)
+		p.uint32(0) // attrs
+	})
+	if err != nil {
+		return err
+	}
+
+	for offset := 0; offset < len(data); offset += sftpChunkSize {
+		chunk := data[offset:min(offset+sftpChunkSize, len(data))]
+		if err := c.statusRequest(sftpWrite, func(p *sftpPacket) {
+			p.string(handle)
+			p.uint64(uint64(offset))
+			p.string(string(chunk))
+		}); err != nil {
+			c.closeHandle(handle)
+			return err
+		}
+	}
+	// The close reports write errors of servers that buffer writes
+	return c.closeHandle(handle)
+}
+
+// Remove deletes a file
+func (c *sftpClient) Remove(file string) error {
+	return c.statusRequest(sftpRemove, func(p *sftpPacket) { p.string(file) })
+}
+
+// openHandle sends an open or opendir request and returns the handle
+func (c *sftpClient) openHandle(packetType byte, build func(*sftpPacket)) (string, error) {
+	responseType, payload, err := c.request(packetType, build)
+	if err != nil {
+		return "", err
+	}
+	if responseType == sftpStatus {
+		return "", sftpStatusError(payload)
+	}
+	if responseType != sftpHandle {
+		return "", fmt.Errorf("unexpected sftp packet %d, expected handle", responseType)
+	}
+	d := &sftpDecoder{buf: payload}
+	handle := d.string()
+	return handle, d.err
+}
+
+// closeHandle closes a file or directory handle
+func (c *sftpClient) closeHandle(handle string) error {
+	return c.statusRequest(sftpClose, func(p *sftpPacket) { p.string(handle) })
+}
+
+// statusRequest sends a request answered with a status and returns the status as an error
+func (c *sftpClient) statusRequest(packetType byte, build func(*sftpPacket)) error {
+	responseType, payload, err := c.request(packetType, build)
+	if err != nil {
+		return err
+	}
+	if responseType != sftpStatus {
+		return fmt.Errorf("unexpected sftp packet %d, expected status", responseType)
+	}
+	return sftpStatusError(payload)
+}
+
+// request sends a request and returns the response payload after its request id
+func (c *sftpClient) request(packetType byte, build func(*sftpPacket)) (byte, []byte, error) {
+	c.requestID++
+	var p sftpPacket
+	p.uint32(c.requestID)
+	build(&p)
+	if err := c.send(packetType, p.Bytes()); err != nil {
+		return 0, nil, err
+	}
+
+	responseType, payload, err := c.receive()
+	if err != nil {
+		return 0, nil, err
+	}
+	d := &sftpDecoder{buf: payload}
+	if id := d.uint32(); d.err != nil || id != c.requestID {
+		return 0, nil, fmt.Errorf("sftp response has request id %d, expected %d", id, c.requestID)
+	}
+	return responseType, d.buf, nil
+}
+
+func (c *sftpClient) send(packetType byte, payload []byte) error {
+	header := make([]byte, 5)
+	binary.BigEndian.PutUint32(header, uint32(len(payload)+1))
+	header[4] = packetType
+	if _, err := c.w.Write(append(header, payload...)); err != nil {
+		return fmt.Errorf("sftp request failed: %w", err)
+	}
+	return nil
+}
+
+func (c *sftpClient) receive() (byte, []byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(c.r, header); err != nil {
+		return 0, nil, fmt.Errorf("sftp response failed: %w", err)
+	}
+	length := binary.BigEndian.Uint32(header)
+	if length < 1 || length > 1<<20 {
+		return 0, nil, fmt.Errorf("invalid sftp packet length %d", length)
+	}
+	payload := make([]byte, length-1)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return 0, nil, fmt.Errorf("sftp response failed: %w", err)
+	}
+	return header[4], payload, nil
+}
+
+// sftpStatusError converts a status payload into nil (OK), io.EOF or an error
+func sftpStatusError(payload []byte) error {
+	d := &sftpDecoder{buf: payload}
+	code := d.uint32()
+	message := d.string()
+	switch {
+	case d.err != nil:
+		return d.err
+	case code == sftpStatusOK:
+		return nil
+	case code == sftpEOF:
+		return io.EOF
+	case message != "":
+		return fmt.Errorf("sftp error %d: %s", code, message)
+	default:
+		return fmt.Errorf("sftp error %d", code)
+	}
+}
+
+// sftpPacket builds the payload of an SFTP packet
+type sftpPacket struct {
+	buf []byte
+}
+
+func (p *sftpPacket) Bytes() []byte { return p.buf }
+
+func (p *sftpPacket) uint32(v uint32) { p.buf = binary.BigEndian.AppendUint32(p.buf, v) }
+
+func (p *sftpPacket) uint64(v uint64) { p.buf = binary.BigEndian.AppendUint64(p.buf, v) }
+
+func (p *sftpPacket) string(v string) {
+	p.uint32(uint32(len(v)))
+	p.buf = append(p.buf, v...)
+}
+
+// sftpDecoder reads an SFTP payload; the first error is kept and later reads return zero values
+type sftpDecoder struct {
+	buf []byte
+	err error
+}
+
+func (d *sftpDecoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.buf) {
+		d.err = fmt.Errorf("malformed sftp packet")
+		return nil
+	}
+	v := d.buf[:n]
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *sftpDecoder) uint32() uint32 {
+	if v := d.next(4); v != nil {
+		return binary.BigEndian.Uint32(v)
+	}
+	return 0
+}
+
+func (d *sftpDecoder) uint64() uint64 {
+	if v := d.next(8); v != nil {
+		return binary.BigEndian.Uint64(v)
+	}
+	return 0
+}
+
+func (d *sftpDecoder) string() string {
+	return string(d.next(int(d.uint32())))
+}
+
+// attrs reads file attributes and returns the size (-1 when absent) and permissions
+func (d *sftpDecoder) attrs() (int64, uint32) {
+	flags := d.uint32()
+	size := int64(-1)
+	var permissions uint32
+	if flags&sftpAttrSize != 0 {
+		size = int64(d.uint64())
+	}
+	if flags&sftpAttrUIDGID != 0 {
+		d.uint32()
+		d.uint32()
+	}
+	if flags&sftpAttrPermissions != 0 {
+		permissions = d.uint32()
+	}
+	if flags&sftpAttrACModTime != 0 {
+		d.uint32()
+		d.uint32()
+	}
+	if flags&sftpAttrExtended != 0 {
+		for i := d.uint32(); i > 0 && d.err == nil; i-- {
+			d.string()
+			d.string()
+		}
+	}
+	return size, permissions
+}
@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// fileTransferCredentials are the login of an sftp task, read from its Secret
+type fileTransferCredentials struct {
+	username   string
+	password   string
+	privateKey []byte
+}
+
+// remoteFile is a directory entry of an sftp or ftp listing
+type remoteFile struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"` // -1 when the listing has no sizes (ftp)
+	Dir  bool   `json:"dir,omitempty"`
+}
+
+// fileTransferClient is a connected sftp or ftp session
+type fileTransferClient interface {
+	List(dir string) ([]remoteFile, error)
+	Size(file string) (int64, error)
+	// Download reads the whole file and returns its size
+	Download(file string) (int64, error)
+	Upload(file string, data []byte) error
+	Remove(file string) error
+	Close() error
+}
+
+// resolveFileTransferCredentials reads the login of an sftp task from its Secret
+func (r *McallTaskReconciler) resolveFileTransferCredentials(ctx context.Context, task *mcallv1.McallTask) (*fileTransferCredentials, error) {
+	transfer := task.Spec.Sftp
+	if transfer == nil {
+		return nil, fmt.Errorf("sftp task requires spec.sftp")
+	}
+
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Name: transfer.SecretName, Namespace: task.Namespace}, &secret); err != nil {
+		return nil, fmt.Errorf("failed to get sftp Secret %s: %w", transfer.SecretName, err)
+	}
+	credentials := &fileTransferCredentials{
+		username:   string(secret.Data["username"]),
+		password:   string(secret.Data["password"]),
+		privateKey: secret.Data["privateKey"],
+	}
+	if credentials.username == "" {
+		return nil, fmt.Errorf("sftp Secret %s has no key %q", transfer.SecretName, "username")
+	}
+	return credentials, nil
+}
+
+// executeFileTransfer connects to the sftp:// or ftp:// URL and runs the task's operation
+func executeFileTransfer(target string, transfer *mcallv1.FileTransfer, credentials *fileTransferCredentials, timeout time.Duration) (string, error) {
+	if transfer == nil || credentials == nil {
+		return "", fmt.Errorf("sftp task requires spec.sftp")
+	}
+	location, err := url.Parse(target)
+	if err != nil || location.Host == "" {
+		return "", fmt.Errorf("invalid sftp URL %q", target)
+	}
+	dir := location.Path
+	if dir == "" {
+		dir = "/"
+	}
+
+	var client fileTransferClient
+	switch location.Scheme {
+	case "sftp":
+		client, err = dialSFTP(location.Host, transfer, credentials, timeout)
+	case "ftp":
+		client, err = dialFTP(location.Host, credentials, timeout)
+	default:
+		return "", fmt.Errorf("unsupported scheme %q, use sftp:// or ftp://", location.Scheme)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	switch transfer.Operation {
+	case "", "list":
+		return listRemoteDir(client, dir, transfer)
+	case "download":
+		file := dir
+		if transfer.File != "" {
+			file = path.Join(dir, transfer.File)
+		}
+		size, err := client.Download(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to download %s: %w", file, err)
+		}
+		return fileTransferOutput(map[string]interface{}{"path": file, "size": size}), checkMinSize(file, size, transfer.MinSize)
+	case "upload":
+		name := transfer.File
+		if name == "" {
+			name = fmt.Sprintf(".mcall-probe-%d", time.Now().Unix())
+		}
+		file := path.Join(dir, name)
+		return uploadProbeFile(client, file)
+	default:
+		return "", fmt.Errorf("unsupported sftp operation %q", transfer.Operation)
+	}
+}
+
+// listRemoteDir lists a directory and checks that the expected file is in it
+func listRemoteDir(client fileTransferClient, dir string, transfer *mcallv1.FileTransfer) (string, error) {
+	files, err := client.List(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+	output := fileTransferOutput(map[string]interface{}{"path": dir, "files": files})
+	if transfer.File == "" {
+		return output, nil
+	}
+
+	for _, file := range files {
+		if file.Name != transfer.File {
+			continue
+		}
+		size := file.Size
+		if size < 0 && transfer.MinSize > 0 {
+			if size, err = client.Size(path.Join(dir, file.Name)); err != nil {
+				return output, fmt.Errorf("failed to get the size of %s: %w", file.Name, err)
+			}
+		}
+		return output, checkMinSize(path.Join(dir, file.Name), size, transfer.MinSize)
+	}
+	return output, fmt.Errorf("file %s not found in %s", transfer.File, dir)
+}
+
+// uploadProbeFile writes a probe file, reads it back and removes it
+func uploadProbeFile(client fileTransferClient, file string) (string, error) {
+	data := []byte(fmt.Sprintf("mcall probe %s\n", time.Now().UTC().Format(time.RFC3339)))
+	if err := client.Upload(file, data); err != nil {
+		return "", fmt.Errorf("failed to upload %s: %w", file, err)
+	}
+	size, err := client.Download(file)
+	if err == nil && size != int64(len(data)) {
+		err = fmt.Errorf("read back %d bytes, wrote %d", size, len(data))
+	}
+	// Remove the probe even when reading it back failed
+	if removeErr := client.Remove(file); removeErr != nil && err == nil {
+		err = fmt.Errorf("failed to remove %s: %w", file, removeErr)
+	}
+	if err != nil {
+		return "", fmt.Errorf("uploaded probe %s: %w", file, err)
+	}
+	return fileTransferOutput(map[string]interface{}{"path": file, "size": size}), nil
+}
+
+// checkMinSize fails files smaller than minSize
+func checkMinSize(file string, size, minSize int64) error {
+	if minSize > 0 && size < minSize {
+		return fmt.Errorf("file %s is %d bytes, expected at least %d", file, size, minSize)
+	}
+	return nil
+}
+
+// fileTransferOutput encodes the result of an sftp task as JSON
+func fileTransferOutput(result map[string]interface{}) string {
+	output, _ := json.Marshal(result)
+	return string(output)
+}
@@ -0,0 +1,478 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// memFS is the file store of the fake sftp and ftp servers; all files are in /data
+type memFS struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func newMemFS() *memFS {
+	return &memFS{files: map[string][]byte{
+		"/data/report.csv": make([]byte, 2048),
+		"/data/empty.txt":  {},
+	}}
+}
+
+func (fs *memFS) names() []string {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	var names []string
+	for name := range fs.files {
+		names = append(names, path.Base(name))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (fs *memFS) get(name string) ([]byte, bool) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	data, exists := fs.files[name]
+	return data, exists
+}
+
+func (fs *memFS) put(name string, data []byte) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.files[name] = data
+}
+
+func (fs *memFS) remove(name string) bool {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	_, exists := fs.files[name]
+	delete(fs.files, name)
+	return exists
+}
+
+// newFakeSFTPServer serves fs over SFTP for user "probe" with password "s3cret"
+// and returns its address and host key
+func newFakeSFTPServer(t *testing.T, fs *memFS) (string, ssh.PublicKey) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate host key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "probe" && string(password) == "s3cret" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("invalid password")
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, channels, requests, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(requests)
+				for newChannel := range channels {
+					channel, channelRequests, err := newChannel.Accept()
+					if err != nil {
+						return
+					}
+					go func() {
+						for request := range channelRequests {
+							isSFTP := request.Type == "subsystem" && string(request.Payload[4:]) == "sftp"
+							request.Reply(isSFTP, nil)
+							if isSFTP {
+								go serveFakeSFTP(channel, fs)
+							}
+						}
+					}()
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String(), signer.PublicKey()
+}
+
+// serveFakeSFTP answers the SFTP requests used by sftp tasks
+func serveFakeSFTP(channel io.ReadWriteCloser, fs *memFS) {
+	defer channel.Close()
+	listed := make(map[string]bool)
+	for {
+		header := make([]byte, 5)
+		if _, err := io.ReadFull(channel, header); err != nil {
+			return
+		}
+		payload := make([]byte, binary.BigEndian.Uint32(header)-1)
+		if _, err := io.ReadFull(channel, payload); err != nil {
+			return
+		}
+
+		d := &sftpDecoder{buf: payload}
+		var p sftpPacket
+		respond := func(packetType byte) {
+			out := make([]byte, 5)
+			binary.BigEndian.PutUint32(out, uint32(len(p.Bytes())+1))
+			out[4] = packetType
+			channel.Write(append(out, p.Bytes()...))
+		}
+		if header[4] == sftpInit {
+			p.uint32(3)
+			respond(sftpVersion)
+			continue
+		}
+		p.uint32(d.uint32()) // request id
+		status := func(code uint32, message string) {
+			p.uint32(code)
+			p.string(message)
+			p.string("")
+			respond(sftpStatus)
+		}
+
+		switch header[4] {
+		case sftpOpendir:
+			if dir := d.string(); dir != "/data" {
+				status(2, "No such file")
+				continue
+			}
+			p.string("dir")
+			respond(sftpHandle)
+		case sftpReaddir:
+			handle := d.string()
+			if listed[handle] {
+				status(sftpEOF, "")
+				continue
+			}
+			listed[handle] = true
+			names := append([]string{".", ".."}, fs.names()...)
+			p.uint32(uint32(len(names)))
+			for _, name := range names {
+				p.string(name)
+				p.string(name)
+				data, _ := fs.get("/data/" + name)
+				p.uint32(sftpAttrSize | sftpAttrPermissions)
+				p.uint64(uint64(len(data)))
+				if name == "." || name == ".." {
+					p.uint32(0o040755)
+				} else {
+					p.uint32(0o100644)
+				}
+			}
+			respond(sftpName)
+		case sftpOpen:
+			name := d.string()
+			flags := d.uint32()
+			if flags&sftpOpenWrite != 0 {
+				fs.put(name, nil)
+			} else if _, exists := fs.get(name); !exists {
+				status(2, "No such file")
+				continue
+			}
+			p.string(name)
+			respond(sftpHandle)
+		case sftpRead:
+			data, _ := fs.get(d.string())
+			offset := d.uint64()
+			length := uint64(d.uint32())
+			if offset >= uint64(len(data)) {
+				status(sftpEOF, "")
+				continue
+			}
+			p.string(string(data[offset:min(offset+length, uint64(len(data)))]))
+			respond(sftpData)
+		case sftpWrite:
+			name := d.string()
+			offset := d.uint64()
+			data, _ := fs.get(name)
+			fs.put(name, append(data[:offset], d.string()...))
+			status(sftpStatusOK, "")
+		case sftpStat:
+			data, exists := fs.get(d.string())
+			if !exists {
+				status(2, "No such file")
+				continue
+			}
+			p.uint32(sftpAttrSize)
+			p.uint64(uint64(len(data)))
+			respond(sftpAttrs)
+		case sftpRemove:
+			if !fs.remove(d.string()) {
+				status(2, "No such file")
+				continue
+			}
+			status(sftpStatusOK, "")
+		default:
+			status(sftpStatusOK, "")
+		}
+	}
+}
+
+// newFakeFTPServer serves fs over FTP for user "probe" with password "s3cret"
+func newFakeFTPServer(t *testing.T, fs *memFS) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeFTP(conn, fs)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func serveFakeFTP(conn net.Conn, fs *memFS) {
+	text := textproto.NewConn(conn)
+	defer text.Close()
+	text.PrintfLine("220 fake ftp ready")
+
+	var user string
+	var data net.Listener
+	// transfer accepts the passive data connection and runs fn on it
+	transfer := func(fn func(net.Conn)) {
+		if data == nil {
+			text.PrintfLine("425 use EPSV first")
+			return
+		}
+		text.PrintfLine("150 opening data connection")
+		dataConn, err := data.Accept()
+		data.Close()
+		data = nil
+		if err != nil {
+			return
+		}
+		fn(dataConn)
+		dataConn.Close()
+		text.PrintfLine("226 transfer complete")
+	}
+
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch verb {
+		case "USER":
+			user = arg
+			text.PrintfLine("331 password required")
+		case "PASS":
+			if user == "probe" && arg == "s3cret" {
+				text.PrintfLine("230 logged in")
+			} else {
+				text.PrintfLine("530 login incorrect")
+			}
+		case "TYPE":
+			text.PrintfLine("200 type set")
+		case "EPSV":
+			data, _ = net.Listen("tcp", "127.0.0.1:0")
+			_, port, _ := net.SplitHostPort(data.Addr().String())
+			text.PrintfLine("229 Entering Extended Passive Mode (|||%s|)", port)
+		case "NLST":
+			if arg != "/data" {
+				text.PrintfLine("550 no such directory")
+				continue
+			}
+			transfer(func(c net.Conn) {
+				for _, name := range fs.names() {
+					fmt.Fprintf(c, "%s\r\n", name)
+				}
+			})
+		case "SIZE":
+			content, exists := fs.get(arg)
+			if !exists {
+				text.PrintfLine("550 no such file")
+				continue
+			}
+			text.PrintfLine("213 %d", len(content))
+		case "RETR":
+			content, exists := fs.get(arg)
+			if !exists {
+				text.PrintfLine("550 no such file")
+				continue
+			}
+			transfer(func(c net.Conn) { c.Write(content) })
+		case "STOR":
+			transfer(func(c net.Conn) {
+				content, _ := io.ReadAll(bufio.NewReader(c))
+				fs.put(arg, content)
+			})
+		case "DELE":
+			if !fs.remove(arg) {
+				text.PrintfLine("550 no such file")
+				continue
+			}
+			text.PrintfLine("250 deleted")
+		case "QUIT":
+			text.PrintfLine("221 bye")
+			return
+		default:
+			text.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+// TestExecuteFileTransfer tests listing, downloading and uploading over sftp and ftp
+func TestExecuteFileTransfer(t *testing.T) {
+	sftpFS, ftpFS := newMemFS(), newMemFS()
+	sftpAddr, hostKey := newFakeSFTPServer(t, sftpFS)
+	ftpAddr := newFakeFTPServer(t, ftpFS)
+	authorizedKey := string(ssh.MarshalAuthorizedKey(hostKey))
+	login := &fileTransferCredentials{username: "probe", password: "s3cret"}
+
+	tests := []struct {
+		name        string
+		path        string
+		transfer    mcallv1.FileTransfer
+		credentials *fileTransferCredentials
+		wantOutput  string
+		wantErr     string
+	}{
+		{name: "list", path: "/data", wantOutput: `"name":"report.csv"`},
+		{name: "file present", path: "/data", transfer: mcallv1.FileTransfer{File: "report.csv", MinSize: 1024}},
+		{name: "file missing", path: "/data", transfer: mcallv1.FileTransfer{File: "missing.csv"}, wantErr: "file missing.csv not found in /data"},
+		{name: "file too small", path: "/data", transfer: mcallv1.FileTransfer{File: "empty.txt", MinSize: 1}, wantErr: "is 0 bytes, expected at least 1"},
+		{name: "missing directory", path: "/other", wantErr: "failed to list /other"},
+		{name: "download", path: "/data/report.csv", transfer: mcallv1.FileTransfer{Operation: "download"}, wantOutput: `{"path":"/data/report.csv","size":2048}`},
+		{name: "download file in directory", path: "/data", transfer: mcallv1.FileTransfer{Operation: "download", File: "report.csv", MinSize: 4096}, wantErr: "expected at least 4096"},
+		{name: "upload", path: "/data", transfer: mcallv1.FileTransfer{Operation: "upload", File: "probe.txt"}, wantOutput: `"path":"/data/probe.txt"`},
+		{name: "wrong password", path: "/data", credentials: &fileTransferCredentials{username: "probe", password: "wrong"}, wantErr: "fail"},
+	}
+
+	for _, scheme := range []string{"sftp", "ftp"} {
+		addr, fs := sftpAddr, sftpFS
+		if scheme == "ftp" {
+			addr, fs = ftpAddr, ftpFS
+		}
+		for _, tt := range tests {
+			t.Run(scheme+" "+tt.name, func(t *testing.T) {
+				transfer := tt.transfer
+				transfer.HostKey = authorizedKey
+				credentials := tt.credentials
+				if credentials == nil {
+					credentials = login
+				}
+
+				output, err := executeFileTransfer(scheme+"://"+addr+tt.path, &transfer, credentials, 5*time.Second)
+				if tt.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+						t.Fatalf("executeFileTransfer() error = %v, want %q", err, tt.wantErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("executeFileTransfer() error = %v", err)
+				}
+				if !strings.Contains(output, tt.wantOutput) {
+					t.Errorf("output = %s, want it to contain %s", output, tt.wantOutput)
+				}
+			})
+		}
+		// The upload probe is removed again
+		if _, exists := fs.get("/data/probe.txt"); exists {
+			t.Errorf("%s upload left /data/probe.txt behind", scheme)
+		}
+	}
+
+	// SSH host keys are verified
+	_, otherKey := newFakeSFTPServer(t, newMemFS())
+	for name, transfer := range map[string]*mcallv1.FileTransfer{
+		"host key mismatch": {HostKey: string(ssh.MarshalAuthorizedKey(otherKey))},
+		"no host key":       {},
+	} {
+		if _, err := executeFileTransfer("sftp://"+sftpAddr+"/data", transfer, login, 5*time.Second); err == nil {
+			t.Errorf("%s: executeFileTransfer() expected an error", name)
+		}
+	}
+	if _, err := executeFileTransfer("sftp://"+sftpAddr+"/data", &mcallv1.FileTransfer{InsecureIgnoreHostKey: true}, login, 5*time.Second); err != nil {
+		t.Errorf("insecureIgnoreHostKey: executeFileTransfer() error = %v", err)
+	}
+	if _, err := executeFileTransfer("http://"+sftpAddr+"/data", &mcallv1.FileTransfer{}, login, time.Second); err == nil {
+		t.Errorf("http scheme: executeFileTransfer() expected an error")
+	}
+}
+
+// TestResolveFileTransferCredentials tests reading the sftp login from the task's Secret
+func TestResolveFileTransferCredentials(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	r := &McallTaskReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "sftp-login", Namespace: "default"},
+				Data:       map[string][]byte{"username": []byte("probe"), "privateKey": []byte("key")},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "no-user", Namespace: "default"},
+				Data:       map[string][]byte{"password": []byte("s3cret")},
+			},
+		).Build(),
+		Scheme: scheme,
+	}
+
+	for _, tt := range []struct {
+		secretName string
+		wantErr    bool
+	}{
+		{secretName: "sftp-login"},
+		{secretName: "no-user", wantErr: true},
+		{secretName: "missing", wantErr: true},
+	} {
+		task := &mcallv1.McallTask{
+			ObjectMeta: metav1.ObjectMeta{Name: "files", Namespace: "default"},
+			Spec:       mcallv1.McallTaskSpec{Type: "sftp", Sftp: &mcallv1.FileTransfer{SecretName: tt.secretName}},
+		}
+		credentials, err := r.resolveFileTransferCredentials(context.Background(), task)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: resolveFileTransferCredentials() error = %v, wantErr %v", tt.secretName, err, tt.wantErr)
+		}
+		if !tt.wantErr && (credentials.username != "probe" || string(credentials.privateKey) != "key") {
+			t.Errorf("%s: credentials = %+v", tt.secretName, credentials)
+		}
+	}
+}
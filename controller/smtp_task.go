@@ -0,0 +1,227 @@
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// smtpCredentials is the AUTH PLAIN login of an smtp task, read from its Secret
+type smtpCredentials struct {
+	username string
+	password string
+}
+
+// smtpResult is the output of an smtp task
+type smtpResult struct {
+	Banner        string   `json:"banner"`
+	Extensions    []string `json:"extensions"`
+	TLS           bool     `json:"tls"`
+	Authenticated bool     `json:"authenticated"`
+	Sent          bool     `json:"sent"`
+}
+
+// resolveSmtpCredentials reads the login of an smtp task from its Secret, nil without spec.smtp.secretName
+func (r *McallTaskReconciler) resolveSmtpCredentials(ctx context.Context, task *mcallv1.McallTask) (*smtpCredentials, error) {
+	check := task.Spec.Smtp
+	if check == nil || check.SecretName == "" {
+		return nil, nil
+	}
+
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Name: check.SecretName, Namespace: task.Namespace}, &secret); err != nil {
+		return nil, fmt.Errorf("failed to get smtp Secret %s: %w", check.SecretName, err)
+	}
+	credentials := &smtpCredentials{
+		username: string(secret.Data["username"]),
+		password: string(secret.Data["password"]),
+	}
+	if credentials.username == "" {
+		return nil, fmt.Errorf("smtp Secret %s has no key %q", check.SecretName, "username")
+	}
+	return credentials, nil
+}
+
+// executeSMTPCheck connects to the mail server at host:port and runs the handshake of the task
+func executeSMTPCheck(target string, check *mcallv1.SmtpCheck, credentials *smtpCredentials, timeout time.Duration, tlsConfig *tls.Config) (string, error) {
+	if check == nil {
+		check = &mcallv1.SmtpCheck{}
+	}
+	host, _, err := net.SplitHostPort(target)
+	if err != nil {
+		return "", fmt.Errorf("invalid smtp target %q, want host:port: %w", target, err)
+	}
+
+	conn, err := net.DialTimeout("tcp", target, timeout)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to %s: %w", target, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	var result smtpResult
+	session := &smtpSession{text: textproto.NewConn(conn)}
+	err = session.run(conn, host, check, credentials, tlsConfig, &result)
+	if err == nil {
+		session.command(0, "QUIT")
+	}
+	output, _ := json.Marshal(result)
+	return string(output), err
+}
+
+// smtpSession is the command connection of an smtp task
+type smtpSession struct {
+	text *textproto.Conn
+}
+
+// run checks the greeting, then sends EHLO, STARTTLS, AUTH and the test message as configured
+func (s *smtpSession) run(conn net.Conn, host string, check *mcallv1.SmtpCheck, credentials *smtpCredentials, tlsConfig *tls.Config, result *smtpResult) error {
+	_, banner, err := s.text.ReadResponse(220)
+	if err != nil {
+		return fmt.Errorf("smtp greeting: %w", err)
+	}
+	result.Banner = banner
+	if check.Banner != "" && !strings.Contains(banner, check.Banner) {
+		return fmt.Errorf("smtp greeting %q does not contain %q", banner, check.Banner)
+	}
+
+	hello := check.Hello
+	if hello == "" {
+		hello = "mcall"
+	}
+	if result.Extensions, err = s.ehlo(hello); err != nil {
+		return err
+	}
+
+	if check.StartTLS {
+		if !hasSMTPExtension(result.Extensions, "STARTTLS") {
+			return fmt.Errorf("smtp server does not offer STARTTLS")
+		}
+		if _, _, err := s.command(220, "STARTTLS"); err != nil {
+			return err
+		}
+		config := &tls.Config{MinVersion: tls.VersionTLS12}
+		if tlsConfig != nil {
+			config = tlsConfig.Clone()
+		}
+		if config.ServerName == "" {
+			config.ServerName = host
+		}
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.Handshake(); err != nil {
+			return fmt.Errorf("smtp TLS handshake failed: %w", err)
+		}
+		s.text = textproto.NewConn(tlsConn)
+		result.TLS = true
+		// The extensions offered before STARTTLS are discarded
+		if result.Extensions, err = s.ehlo(hello); err != nil {
+			return err
+		}
+	}
+
+	if credentials != nil {
+		// The password is only sent encrypted
+		if !result.TLS {
+			return fmt.Errorf("smtp AUTH requires startTLS")
+		}
+		token := base64.StdEncoding.EncodeToString([]byte("\x00" + credentials.username + "\x00" + credentials.password))
+		if _, _, err := s.command(235, "AUTH PLAIN %s", token); err != nil {
+			return err
+		}
+		result.Authenticated = true
+	}
+
+	if len(check.To) > 0 {
+		if err := s.send(check); err != nil {
+			return err
+		}
+		result.Sent = true
+	}
+	return nil
+}
+
+// ehlo greets the server and returns the extensions it offers
+func (s *smtpSession) ehlo(hello string) ([]string, error) {
+	_, message, err := s.command(250, "EHLO %s", hello)
+	if err != nil {
+		return nil, err
+	}
+	// The first line is the server's greeting, the others are extensions
+	extensions := []string{}
+	for _, line := range strings.Split(message, "\n")[1:] {
+		if line = strings.TrimSpace(line); line != "" {
+			extensions = append(extensions, line)
+		}
+	}
+	return extensions, nil
+}
+
+// send delivers the test message to the recipients
+func (s *smtpSession) send(check *mcallv1.SmtpCheck) error {
+	if check.From == "" {
+		return fmt.Errorf("smtp.from is required to send a test message")
+	}
+	if _, _, err := s.command(250, "MAIL FROM:<%s>", check.From); err != nil {
+		return err
+	}
+	for _, to := range check.To {
+		// 250 or 251 (user not local; will forward)
+		if _, _, err := s.command(25, "RCPT TO:<%s>", to); err != nil {
+			return err
+		}
+	}
+	if _, _, err := s.command(354, "DATA"); err != nil {
+		return err
+	}
+
+	subject := check.Subject
+	if subject == "" {
+		subject = "mcall smtp check"
+	}
+	now := time.Now()
+	writer := s.text.DotWriter()
+	fmt.Fprintf(writer, "From: <%s>\r\nTo: <%s>\r\nSubject: %s\r\nDate: %s\r\n\r\nmcall smtp check sent at %s\r\n",
+		check.From, strings.Join(check.To, ">, <"), subject, now.Format(time.RFC1123Z), now.UTC().Format(time.RFC3339))
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to write the smtp message: %w", err)
+	}
+	if _, _, err := s.text.ReadResponse(250); err != nil {
+		return fmt.Errorf("smtp message not accepted: %w", err)
+	}
+	return nil
+}
+
+// command sends a command and checks the reply code (0 accepts any code)
+func (s *smtpSession) command(expectCode int, format string, args ...interface{}) (int, string, error) {
+	line := fmt.Sprintf(format, args...)
+	if _, err := s.text.Cmd("%s", line); err != nil {
+		return 0, "", fmt.Errorf("smtp command failed: %w", err)
+	}
+	code, message, err := s.text.ReadResponse(expectCode)
+	if err != nil {
+		// Only the verb is reported, AUTH arguments must not reach the task status
+		return code, message, fmt.Errorf("smtp %s: %w", strings.Fields(line)[0], err)
+	}
+	return code, message, nil
+}
+
+// hasSMTPExtension reports whether the EHLO extensions include keyword
+func hasSMTPExtension(extensions []string, keyword string) bool {
+	for _, extension := range extensions {
+		if name, _, _ := strings.Cut(extension, " "); strings.EqualFold(name, keyword) {
+			return true
+		}
+	}
+	return false
+}
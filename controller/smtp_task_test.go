@@ -0,0 +1,242 @@
+package controller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"math/big"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// fakeSMTPServer accepts AUTH PLAIN for "probe"/"s3cret" and mail to @example.com
+type fakeSMTPServer struct {
+	addr      string
+	tlsConfig *tls.Config // Offered with STARTTLS when set
+	mu        sync.Mutex
+	messages  []string
+}
+
+// newFakeSMTPServer starts a fake mail server, with STARTTLS when certificate is set
+func newFakeSMTPServer(t *testing.T, certificate *tls.Certificate) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeSMTPServer{addr: listener.Addr().String()}
+	if certificate != nil {
+		server.tlsConfig = &tls.Config{Certificates: []tls.Certificate{*certificate}}
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	text := textproto.NewConn(conn)
+	defer func() { text.Close() }()
+	text.PrintfLine("220 mail.example.com ESMTP fake")
+
+	secure := false
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			text.PrintfLine("250-mail.example.com greets %s", arg)
+			if s.tlsConfig != nil && !secure {
+				text.PrintfLine("250-STARTTLS")
+			}
+			if secure {
+				text.PrintfLine("250-AUTH PLAIN")
+			}
+			text.PrintfLine("250 8BITMIME")
+		case "STARTTLS":
+			text.PrintfLine("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, text, secure = tlsConn, textproto.NewConn(tlsConn), true
+		case "AUTH":
+			token, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(arg, "PLAIN "))
+			if string(token) == "\x00probe\x00s3cret" {
+				text.PrintfLine("235 authenticated")
+			} else {
+				text.PrintfLine("535 authentication failed")
+			}
+		case "MAIL":
+			text.PrintfLine("250 sender ok")
+		case "RCPT":
+			if strings.HasSuffix(arg, "@example.com>") {
+				text.PrintfLine("250 recipient ok")
+			} else {
+				text.PrintfLine("550 no such user")
+			}
+		case "DATA":
+			text.PrintfLine("354 end data with <CR><LF>.<CR><LF>")
+			lines, err := text.ReadDotLines()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, strings.Join(lines, "\n"))
+			s.mu.Unlock()
+			text.PrintfLine("250 queued")
+		case "QUIT":
+			text.PrintfLine("221 bye")
+			return
+		default:
+			text.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+// newServerCertificate returns a self-signed certificate for 127.0.0.1 and a pool trusting it
+func newServerCertificate(t *testing.T) (*tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(certificate)
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+// TestExecuteSMTPCheck tests the greeting, STARTTLS, AUTH and test message of smtp tasks
+func TestExecuteSMTPCheck(t *testing.T) {
+	certificate, pool := newServerCertificate(t)
+	server := newFakeSMTPServer(t, certificate)
+	plainServer := newFakeSMTPServer(t, nil)
+	login := &smtpCredentials{username: "probe", password: "s3cret"}
+
+	tests := []struct {
+		name        string
+		addr        string
+		check       *mcallv1.SmtpCheck
+		credentials *smtpCredentials
+		wantOutput  string
+		wantErr     string
+	}{
+		{name: "greeting", addr: plainServer.addr, wantOutput: `"banner":"mail.example.com ESMTP fake","extensions":["8BITMIME"]`},
+		{name: "banner matches", addr: server.addr, check: &mcallv1.SmtpCheck{Banner: "ESMTP"}, wantOutput: `"extensions":["STARTTLS","8BITMIME"]`},
+		{name: "banner mismatch", addr: server.addr, check: &mcallv1.SmtpCheck{Banner: "Postfix"}, wantErr: `does not contain "Postfix"`},
+		{name: "starttls", addr: server.addr, check: &mcallv1.SmtpCheck{StartTLS: true}, wantOutput: `"extensions":["AUTH PLAIN","8BITMIME"],"tls":true`},
+		{name: "starttls not offered", addr: plainServer.addr, check: &mcallv1.SmtpCheck{StartTLS: true}, wantErr: "does not offer STARTTLS"},
+		{name: "auth", addr: server.addr, check: &mcallv1.SmtpCheck{StartTLS: true}, credentials: login, wantOutput: `"authenticated":true`},
+		{name: "auth without tls", addr: server.addr, check: &mcallv1.SmtpCheck{}, credentials: login, wantErr: "requires startTLS"},
+		{name: "wrong password", addr: server.addr, check: &mcallv1.SmtpCheck{StartTLS: true}, credentials: &smtpCredentials{username: "probe", password: "wrong"}, wantErr: "smtp AUTH: 535"},
+		{name: "send", addr: server.addr, check: &mcallv1.SmtpCheck{StartTLS: true, From: "mcall@example.com", To: []string{"ops@example.com"}, Subject: "canary"}, credentials: login, wantOutput: `"sent":true`},
+		{name: "recipient rejected", addr: server.addr, check: &mcallv1.SmtpCheck{From: "mcall@example.com", To: []string{"ops@other.com"}}, wantErr: "smtp RCPT: 550"},
+		{name: "send without from", addr: server.addr, check: &mcallv1.SmtpCheck{To: []string{"ops@example.com"}}, wantErr: "smtp.from is required"},
+		{name: "invalid target", addr: "mail.example.com", wantErr: "want host:port"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := executeSMTPCheck(tt.addr, tt.check, tt.credentials, 5*time.Second, &tls.Config{RootCAs: pool})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("executeSMTPCheck() error = %v, want %q", err, tt.wantErr)
+				}
+				if strings.Contains(err.Error(), "s3cret") || strings.Contains(err.Error(), base64.StdEncoding.EncodeToString([]byte("\x00probe\x00wrong"))) {
+					t.Errorf("executeSMTPCheck() error leaks the password: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("executeSMTPCheck() error = %v", err)
+			}
+			if !strings.Contains(output, tt.wantOutput) {
+				t.Errorf("output = %s, want it to contain %s", output, tt.wantOutput)
+			}
+		})
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.messages) != 1 || !strings.Contains(server.messages[0], "Subject: canary") {
+		t.Errorf("messages = %q, want one message with Subject: canary", server.messages)
+	}
+}
+
+// TestResolveSmtpCredentials tests reading the AUTH login of smtp tasks from their Secret
+func TestResolveSmtpCredentials(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	r := &McallTaskReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "smtp-login", Namespace: "default"},
+			Data:       map[string][]byte{"username": []byte("probe"), "password": []byte("s3cret")},
+		}).Build(),
+		Scheme: scheme,
+	}
+
+	for _, tt := range []struct {
+		name    string
+		check   *mcallv1.SmtpCheck
+		want    *smtpCredentials
+		wantErr bool
+	}{
+		{name: "no smtp spec"},
+		{name: "no secret", check: &mcallv1.SmtpCheck{StartTLS: true}},
+		{name: "secret", check: &mcallv1.SmtpCheck{SecretName: "smtp-login"}, want: &smtpCredentials{username: "probe", password: "s3cret"}},
+		{name: "missing secret", check: &mcallv1.SmtpCheck{SecretName: "missing"}, wantErr: true},
+	} {
+		task := &mcallv1.McallTask{
+			ObjectMeta: metav1.ObjectMeta{Name: "mail", Namespace: "default"},
+			Spec:       mcallv1.McallTaskSpec{Type: "smtp", Smtp: tt.check},
+		}
+		credentials, err := r.resolveSmtpCredentials(context.Background(), task)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: resolveSmtpCredentials() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if (credentials == nil) != (tt.want == nil) || (credentials != nil && *credentials != *tt.want) {
+			t.Errorf("%s: credentials = %+v, want %+v", tt.name, credentials, tt.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// Defaults of sql tasks
+const (
+	defaultSQLDSNKey  = "dsn"
+	defaultSQLMaxRows = 100
+)
+
+// sqlQueryResult is the JSON output of a sql task
+type sqlQueryResult struct {
+	RowCount int                      `json:"rowCount"`
+	Rows     []map[string]interface{} `json:"rows"`
+}
+
+// resolveSQLDSN reads the data source name of a sql task from its Secret
+func (r *McallTaskReconciler) resolveSQLDSN(ctx context.Context, task *mcallv1.McallTask) (string, error) {
+	query := task.Spec.Sql
+	if query == nil {
+		return "", fmt.Errorf("sql task requires spec.sql")
+	}
+
+	key := query.Key
+	if key == "" {
+		key = defaultSQLDSNKey
+	}
+
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Name: query.SecretName, Namespace: task.Namespace}, &secret); err != nil {
+		return "", fmt.Errorf("failed to get sql Secret %s: %w", query.SecretName, err)
+	}
+	dsn, exists := secret.Data[key]
+	if !exists {
+		return "", fmt.Errorf("sql Secret %s has no key %q", query.SecretName, key)
+	}
+	return string(dsn), nil
+}
+
+// executeSQLQuery runs a query in a read-only transaction and returns its rows as JSON
+func executeSQLQuery(query *mcallv1.SqlQuery, dsn, statement string, timeout time.Duration) (string, error) {
+	if query == nil {
+		return "", fmt.Errorf("sql task requires spec.sql")
+	}
+	if statement == "" {
+		return "", fmt.Errorf("empty query")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	db, err := sql.Open(query.Driver, dsn)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s database: %w", query.Driver, err)
+	}
+	defer db.Close()
+
+	// The transaction is never committed, so a query can't change data
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to %s database: %w", query.Driver, err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, statement)
+	if err != nil {
+		return "", fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	maxRows := defaultSQLMaxRows
+	if query.MaxRows > 0 {
+		maxRows = int(query.MaxRows)
+	}
+
+	result := sqlQueryResult{Rows: []map[string]interface{}{}}
+	for rows.Next() {
+		result.RowCount++
+		if len(result.Rows) >= maxRows {
+			continue
+		}
+
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return "", fmt.Errorf("failed to read row: %w", err)
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			if bytes, ok := values[i].([]byte); ok {
+				row[column] = string(bytes)
+			} else {
+				row[column] = values[i]
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("query failed: %w", err)
+	}
+
+	output, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode rows: %w", err)
+	}
+	return string(output), nil
+}
@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// fakeSQLDriver serves fixed rows for "SELECT" queries and fails everything else.
+// It rejects transactions that are not read-only.
+type fakeSQLDriver struct{}
+
+type fakeSQLConn struct{}
+
+type fakeSQLStmt struct{ query string }
+
+type fakeSQLRows struct{ next int }
+
+func init() {
+	sql.Register("fakesql", fakeSQLDriver{})
+}
+
+func (fakeSQLDriver) Open(dsn string) (driver.Conn, error) {
+	if dsn != "fake://db" {
+		return nil, fmt.Errorf("unknown database %q", dsn)
+	}
+	return fakeSQLConn{}, nil
+}
+
+func (fakeSQLConn) Prepare(query string) (driver.Stmt, error) { return fakeSQLStmt{query: query}, nil }
+func (fakeSQLConn) Close() error                              { return nil }
+func (fakeSQLConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("read-only transaction required")
+}
+func (fakeSQLConn) Commit() error   { return nil }
+func (fakeSQLConn) Rollback() error { return nil }
+
+func (c fakeSQLConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if !opts.ReadOnly {
+		return nil, fmt.Errorf("read-only transaction required")
+	}
+	return c, nil
+}
+
+func (fakeSQLStmt) Close() error  { return nil }
+func (fakeSQLStmt) NumInput() int { return 0 }
+func (fakeSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, fmt.Errorf("exec not supported")
+}
+
+func (s fakeSQLStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if !strings.HasPrefix(s.query, "SELECT") {
+		return nil, fmt.Errorf("syntax error at %q", s.query)
+	}
+	return &fakeSQLRows{}, nil
+}
+
+func (*fakeSQLRows) Columns() []string { return []string{"id", "name"} }
+func (*fakeSQLRows) Close() error      { return nil }
+
+func (r *fakeSQLRows) Next(dest []driver.Value) error {
+	if r.next >= 3 {
+		return io.EOF
+	}
+	r.next++
+	dest[0] = int64(r.next)
+	dest[1] = []byte(fmt.Sprintf("user-%d", r.next))
+	return nil
+}
+
+// TestExecuteSQLQuery tests running queries and encoding their rows as JSON
+func TestExecuteSQLQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		dsn        string
+		query      string
+		maxRows    int32
+		wantOutput string
+		wantErr    bool
+	}{
+		{
+			name:       "all rows",
+			dsn:        "fake://db",
+			query:      "SELECT id, name FROM users",
+			wantOutput: `{"rowCount":3,"rows":[{"id":1,"name":"user-1"},{"id":2,"name":"user-2"},{"id":3,"name":"user-3"}]}`,
+		},
+		{
+			name:       "max rows",
+			dsn:        "fake://db",
+			query:      "SELECT id, name FROM users",
+			maxRows:    1,
+			wantOutput: `{"rowCount":3,"rows":[{"id":1,"name":"user-1"}]}`,
+		},
+		{name: "query error", dsn: "fake://db", query: "DELETE FROM users", wantErr: true},
+		{name: "empty query", dsn: "fake://db", wantErr: true},
+		{name: "connection error", dsn: "fake://other", query: "SELECT 1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := &mcallv1.SqlQuery{Driver: "fakesql", SecretName: "db", MaxRows: tt.maxRows}
+			output, err := executeSQLQuery(query, tt.dsn, tt.query, 2*time.Second)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("executeSQLQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if output != tt.wantOutput {
+				t.Errorf("output = %s, want %s", output, tt.wantOutput)
+			}
+		})
+	}
+
+	// The row count and values can be checked with the JSONPath validation
+	output, _ := executeSQLQuery(&mcallv1.SqlQuery{Driver: "fakesql"}, "fake://db", "SELECT id, name FROM users", 2*time.Second)
+	task := &mcallv1.McallTask{Spec: mcallv1.McallTaskSpec{Type: "sql", OutputValidation: &mcallv1.OutputValidation{
+		JsonPath:          "$.rowCount",
+		ExpectedJsonValue: "3",
+		Assertions:        []mcallv1.Assertion{{Operator: "equals", Path: "$.rows[1].name", Value: "user-2"}},
+	}}}
+	if err := validateTaskOutput(task, output); err != nil {
+		t.Errorf("validateTaskOutput() error = %v", err)
+	}
+}
+
+// TestResolveSQLDSN tests reading the data source name from the task's Secret
+func TestResolveSQLDSN(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Data: map[string][]byte{
+			"dsn":     []byte("postgres://reader@db/app"),
+			"replica": []byte("postgres://reader@replica/app"),
+		},
+	}
+	r := &McallTaskReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build(),
+		Scheme: scheme,
+	}
+
+	tests := []struct {
+		name    string
+		query   *mcallv1.SqlQuery
+		want    string
+		wantErr bool
+	}{
+		{name: "default key", query: &mcallv1.SqlQuery{Driver: "postgres", SecretName: "db"}, want: "postgres://reader@db/app"},
+		{name: "custom key", query: &mcallv1.SqlQuery{Driver: "postgres", SecretName: "db", Key: "replica"}, want: "postgres://reader@replica/app"},
+		{name: "missing key", query: &mcallv1.SqlQuery{Driver: "postgres", SecretName: "db", Key: "primary"}, wantErr: true},
+		{name: "missing secret", query: &mcallv1.SqlQuery{Driver: "postgres", SecretName: "other"}, wantErr: true},
+		{name: "no sql spec", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{Name: "query", Namespace: "default"},
+				Spec:       mcallv1.McallTaskSpec{Type: "sql", Input: "SELECT 1", Sql: tt.query},
+			}
+			dsn, err := r.resolveSQLDSN(context.Background(), task)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveSQLDSN() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if dsn != tt.want {
+				t.Errorf("dsn = %q, want %q", dsn, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// setTaskConditions sets the Ready and Succeeded conditions and the observed generation of a
+// task from its phase. Pending tasks keep the conditions of their last execution.
+func setTaskConditions(task *mcallv1.McallTask) {
+	generation := task.Generation
+	task.Status.ObservedGeneration = generation
+	set := func(conditionType string, status metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&task.Status.Conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             status,
+			ObservedGeneration: generation,
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+
+	var message string
+	if task.Status.Result != nil {
+		message = truncateString(task.Status.Result.ErrorMessage, 1024)
+	}
+	switch task.Status.Phase {
+	case mcallv1.McallTaskPhasePending:
+		if meta.FindStatusCondition(task.Status.Conditions, mcallv1.ReadyCondition) == nil {
+			set(mcallv1.ReadyCondition, metav1.ConditionUnknown, "Pending", "Waiting for the first execution")
+		}
+	case mcallv1.McallTaskPhaseRunning:
+		if ready := meta.FindStatusCondition(task.Status.Conditions, mcallv1.ReadyCondition); ready == nil || ready.Status == metav1.ConditionUnknown {
+			set(mcallv1.ReadyCondition, metav1.ConditionUnknown, "Running", "First execution in progress")
+		}
+		set(mcallv1.SucceededCondition, metav1.ConditionUnknown, "Running", "Execution in progress")
+	case mcallv1.McallTaskPhaseSucceeded:
+		set(mcallv1.ReadyCondition, metav1.ConditionTrue, EventReasonSucceeded, "Last execution succeeded")
+		set(mcallv1.SucceededCondition, metav1.ConditionTrue, EventReasonSucceeded, "Last execution succeeded")
+	case mcallv1.McallTaskPhaseFailed:
+		reason := EventReasonFailed
+		if strings.Contains(message, "timed out") {
+			reason = EventReasonTimedOut
+		}
+		set(mcallv1.ReadyCondition, metav1.ConditionFalse, reason, message)
+		set(mcallv1.SucceededCondition, metav1.ConditionFalse, reason, message)
+	case mcallv1.McallTaskPhaseSkipped:
+		set(mcallv1.ReadyCondition, metav1.ConditionTrue, EventReasonSkipped, message)
+		set(mcallv1.SucceededCondition, metav1.ConditionFalse, EventReasonSkipped, message)
+	case mcallv1.McallTaskPhaseBlocked:
+		set(mcallv1.ReadyCondition, metav1.ConditionFalse, "Blocked", message)
+		set(mcallv1.SucceededCondition, metav1.ConditionFalse, "Blocked", message)
+	}
+}
+
+// workflowBlockedReason reports whether a status reason keeps a workflow from starting runs
+func workflowBlockedReason(reason string) bool {
+	return reason == invalidDependenciesReason || reason == invalidParametersReason ||
+		reason == workflowLimitReason || reason == templateNotValidatedReason
+}
+
+// setWorkflowConditions sets the Ready, Succeeded and SchedulingActive conditions and the
+// observed generation of a workflow from its phase and spec. Pending workflows keep the
+// conditions of their last run.
+func setWorkflowConditions(workflow *mcallv1.McallWorkflow) {
+	generation := workflow.Generation
+	workflow.Status.ObservedGeneration = generation
+	set := func(conditionType string, status metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&workflow.Status.Conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             status,
+			ObservedGeneration: generation,
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+
+	switch workflow.Status.Phase {
+	case mcallv1.McallWorkflowPhasePending, "":
+		// Rejected specs and workflows waiting for template warm-up aren't ready
+		if reason := workflow.Status.Reason; workflowBlockedReason(reason) {
+			set(mcallv1.ReadyCondition, metav1.ConditionFalse, reason, truncateString(workflow.Status.Message, 1024))
+		} else if ready := meta.FindStatusCondition(workflow.Status.Conditions, mcallv1.ReadyCondition); ready == nil ||
+			workflowBlockedReason(ready.Reason) {
+			set(mcallv1.ReadyCondition, metav1.ConditionUnknown, "Pending", "Waiting for the first run")
+		}
+	case mcallv1.McallWorkflowPhaseRunning:
+		if ready := meta.FindStatusCondition(workflow.Status.Conditions, mcallv1.ReadyCondition); ready == nil || ready.Status == metav1.ConditionUnknown {
+			set(mcallv1.ReadyCondition, metav1.ConditionUnknown, "Running", "First run in progress")
+		}
+		set(mcallv1.SucceededCondition, metav1.ConditionUnknown, "Running", "Run in progress")
+	case mcallv1.McallWorkflowPhaseSucceeded:
+		set(mcallv1.ReadyCondition, metav1.ConditionTrue, EventReasonSucceeded, "Last run succeeded")
+		set(mcallv1.SucceededCondition, metav1.ConditionTrue, EventReasonSucceeded, "Last run succeeded")
+	case mcallv1.McallWorkflowPhaseFailed:
+		set(mcallv1.ReadyCondition, metav1.ConditionFalse, EventReasonFailed, "Last run failed")
+		set(mcallv1.SucceededCondition, metav1.ConditionFalse, EventReasonFailed, "Last run failed")
+	}
+
+	switch {
+	case workflow.Spec.Schedule == "":
+		meta.RemoveStatusCondition(&workflow.Status.Conditions, mcallv1.SchedulingActiveCondition)
+	case workflow.Spec.Suspend:
+		set(mcallv1.SchedulingActiveCondition, metav1.ConditionFalse, "Suspended", "Runs are not scheduled while suspended")
+	default:
+		set(mcallv1.SchedulingActiveCondition, metav1.ConditionTrue, "Scheduled", "Runs are scheduled by "+workflow.Spec.Schedule)
+	}
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestSetTaskConditions tests the Ready and Succeeded conditions of a task through its executions
+func TestSetTaskConditions(t *testing.T) {
+	task := &mcallv1.McallTask{ObjectMeta: metav1.ObjectMeta{Name: "api", Generation: 3}}
+
+	steps := []struct {
+		phase         mcallv1.McallTaskPhase
+		errorMessage  string
+		wantReady     metav1.ConditionStatus
+		wantSucceeded metav1.ConditionStatus
+		wantReason    string
+	}{
+		{phase: mcallv1.McallTaskPhasePending, wantReady: metav1.ConditionUnknown, wantReason: "Pending"},
+		{phase: mcallv1.McallTaskPhaseRunning, wantReady: metav1.ConditionUnknown, wantSucceeded: metav1.ConditionUnknown, wantReason: "Running"},
+		{phase: mcallv1.McallTaskPhaseFailed, errorMessage: "command execution timed out",
+			wantReady: metav1.ConditionFalse, wantSucceeded: metav1.ConditionFalse, wantReason: EventReasonTimedOut},
+		// The next execution keeps Ready from the failed one until it completes
+		{phase: mcallv1.McallTaskPhaseRunning, wantReady: metav1.ConditionFalse, wantSucceeded: metav1.ConditionUnknown, wantReason: EventReasonTimedOut},
+		{phase: mcallv1.McallTaskPhaseSucceeded, wantReady: metav1.ConditionTrue, wantSucceeded: metav1.ConditionTrue, wantReason: EventReasonSucceeded},
+		{phase: mcallv1.McallTaskPhaseSkipped, errorMessage: "Skipped due to mcall.tz.io/paused annotation",
+			wantReady: metav1.ConditionTrue, wantSucceeded: metav1.ConditionFalse, wantReason: EventReasonSkipped},
+	}
+
+	for _, step := range steps {
+		task.Status.Phase = step.phase
+		task.Status.Result = &mcallv1.McallTaskResult{ErrorMessage: step.errorMessage}
+		setTaskConditions(task)
+
+		ready := meta.FindStatusCondition(task.Status.Conditions, mcallv1.ReadyCondition)
+		if ready == nil || ready.Status != step.wantReady || ready.Reason != step.wantReason || ready.ObservedGeneration != 3 {
+			t.Errorf("%s: Ready = %+v, want %s (%s)", step.phase, ready, step.wantReady, step.wantReason)
+		}
+		succeeded := meta.FindStatusCondition(task.Status.Conditions, mcallv1.SucceededCondition)
+		if step.wantSucceeded == "" {
+			if succeeded != nil {
+				t.Errorf("%s: Succeeded = %+v, want none", step.phase, succeeded)
+			}
+		} else if succeeded == nil || succeeded.Status != step.wantSucceeded {
+			t.Errorf("%s: Succeeded = %+v, want %s", step.phase, succeeded, step.wantSucceeded)
+		}
+	}
+	if task.Status.ObservedGeneration != 3 {
+		t.Errorf("observedGeneration = %d, want 3", task.Status.ObservedGeneration)
+	}
+}
+
+// TestSetWorkflowConditions tests the conditions of a scheduled workflow
+func TestSetWorkflowConditions(t *testing.T) {
+	workflow := &mcallv1.McallWorkflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Generation: 2},
+		Spec:       mcallv1.McallWorkflowSpec{Schedule: "0 2 * * *"},
+		Status:     mcallv1.McallWorkflowStatus{Phase: mcallv1.McallWorkflowPhasePending, Reason: workflowLimitReason, Message: "too many tasks"},
+	}
+
+	setWorkflowConditions(workflow)
+	if ready := meta.FindStatusCondition(workflow.Status.Conditions, mcallv1.ReadyCondition); ready == nil ||
+		ready.Status != metav1.ConditionFalse || ready.Reason != workflowLimitReason {
+		t.Errorf("rejected Ready = %+v, want False (%s)", ready, workflowLimitReason)
+	}
+	if !meta.IsStatusConditionTrue(workflow.Status.Conditions, mcallv1.SchedulingActiveCondition) {
+		t.Errorf("SchedulingActive isn't True for a scheduled workflow")
+	}
+
+	// Once accepted, the workflow waits for its first run
+	workflow.Status.Reason, workflow.Status.Message = "", ""
+	setWorkflowConditions(workflow)
+	if ready := meta.FindStatusCondition(workflow.Status.Conditions, mcallv1.ReadyCondition); ready.Status != metav1.ConditionUnknown {
+		t.Errorf("accepted Ready = %+v, want Unknown", ready)
+	}
+
+	workflow.Status.Phase = mcallv1.McallWorkflowPhaseFailed
+	setWorkflowConditions(workflow)
+	if !meta.IsStatusConditionFalse(workflow.Status.Conditions, mcallv1.SucceededCondition) ||
+		!meta.IsStatusConditionFalse(workflow.Status.Conditions, mcallv1.ReadyCondition) {
+		t.Errorf("failed run conditions = %+v", workflow.Status.Conditions)
+	}
+
+	// Reset for the next run, suspended
+	workflow.Status.Phase = mcallv1.McallWorkflowPhasePending
+	workflow.Spec.Suspend = true
+	setWorkflowConditions(workflow)
+	if !meta.IsStatusConditionFalse(workflow.Status.Conditions, mcallv1.ReadyCondition) {
+		t.Errorf("Ready of the failed run wasn't kept while pending")
+	}
+	if active := meta.FindStatusCondition(workflow.Status.Conditions, mcallv1.SchedulingActiveCondition); active.Status != metav1.ConditionFalse || active.Reason != "Suspended" {
+		t.Errorf("suspended SchedulingActive = %+v", active)
+	}
+	if workflow.Status.ObservedGeneration != 2 {
+		t.Errorf("observedGeneration = %d, want 2", workflow.Status.ObservedGeneration)
+	}
+}
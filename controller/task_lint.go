@@ -0,0 +1,270 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// lintNamespace is the namespace of the task and samples of a lint
+const lintNamespace = "lint"
+
+// lintRequestMaxBytes bounds the body of a lint request
+const lintRequestMaxBytes = 1 << 20
+
+// TaskLintRequest is a task spec checked against sample results, without executing it
+type TaskLintRequest struct {
+	// Task is the spec of the McallTask to check
+	Task mcallv1.McallTaskSpec `json:"task"`
+
+	// Condition of the task in a workflow, checked against the sample of its dependent task
+	Condition *mcallv1.TaskCondition `json:"condition,omitempty"`
+
+	// Conditions of the task in a workflow (allOf/anyOf), checked against the samples of their dependent tasks
+	Conditions *mcallv1.TaskConditions `json:"conditions,omitempty"`
+
+	// Output is a sample output of the task and its inputs, checked against their expect and the
+	// outputValidation or httpValidation of the task
+	Output string `json:"output,omitempty"`
+
+	// Outputs are sample outputs of the inputs of the task by name, overriding Output
+	Outputs map[string]string `json:"outputs,omitempty"`
+
+	// HTTPStatusCode is the sample status code of the task and of its get and post inputs
+	HTTPStatusCode int `json:"httpStatusCode,omitempty"`
+
+	// Samples are the results of the tasks referenced by the condition and input sources, by name
+	Samples map[string]TaskLintSample `json:"samples,omitempty"`
+}
+
+// TaskLintSample is a sample result of a task referenced by the checked task
+type TaskLintSample struct {
+	// Phase of the task (default: Succeeded)
+	Phase mcallv1.McallTaskPhase `json:"phase,omitempty"`
+
+	// Output of the task
+	Output string `json:"output,omitempty"`
+
+	// ErrorCode of the task (default: "0" when it succeeded, "-1" otherwise)
+	ErrorCode string `json:"errorCode,omitempty"`
+
+	// ErrorMessage of the task
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// TaskLintReport reports what the checked task would do with the samples
+type TaskLintReport struct {
+	// Passed is true when all checks passed
+	Passed bool `json:"passed"`
+
+	// Expect: whether the sample outputs match the expect of each input of the task
+	Expect []TaskLintCheck `json:"expect,omitempty"`
+
+	// Condition: whether the condition is met by the sample of its dependent task
+	Condition *TaskLintCheck `json:"condition,omitempty"`
+
+	// Conditions: whether the combined conditions are met by the samples of their dependent tasks
+	Conditions *TaskLintCheck `json:"conditions,omitempty"`
+
+	// Validation: whether the sample output passes the outputValidation or httpValidation of the task
+	Validation *TaskLintCheck `json:"validation,omitempty"`
+
+	// Input: the input the task would run with, after its input sources and input template
+	Input *TaskLintCheck `json:"input,omitempty"`
+}
+
+// TaskLintCheck is the result of a single check of a lint
+type TaskLintCheck struct {
+	// Name of the checked input, for expect checks
+	Name string `json:"name,omitempty"`
+
+	// Passed is true when the check matched
+	Passed bool `json:"passed"`
+
+	// Message explains why the check didn't pass
+	Message string `json:"message,omitempty"`
+
+	// Value is the value the check produced, e.g. the rendered input
+	Value string `json:"value,omitempty"`
+}
+
+// lintSampleTask returns the McallTask holding the sample result of a referenced task
+func lintSampleTask(name string, sample TaskLintSample) *mcallv1.McallTask {
+	phase := sample.Phase
+	if phase == "" {
+		phase = mcallv1.McallTaskPhaseSucceeded
+	}
+	errorCode := sample.ErrorCode
+	if errorCode == "" {
+		errorCode = "0"
+		if phase != mcallv1.McallTaskPhaseSucceeded {
+			errorCode = "-1"
+		}
+	}
+	return &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: lintNamespace},
+		Status: mcallv1.McallTaskStatus{
+			Phase: phase,
+			Result: &mcallv1.McallTaskResult{
+				Output:       sample.Output,
+				ErrorCode:    errorCode,
+				ErrorMessage: sample.ErrorMessage,
+			},
+		},
+	}
+}
+
+// LintTask evaluates the conditions, input template, expect and validation of a task against sample results,
+// with the checks the controller runs, and reports what would match. Nothing is executed.
+func LintTask(ctx context.Context, request *TaskLintRequest) (*TaskLintReport, error) {
+	// The checks log as they would while reconciling, which is noise here
+	ctx = log.IntoContext(ctx, logr.Discard())
+
+	scheme := runtime.NewScheme()
+	if err := mcallv1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for name, sample := range request.Samples {
+		builder = builder.WithObjects(lintSampleTask(name, sample))
+	}
+	reconciler := &McallTaskReconciler{Client: builder.Build(), Scheme: scheme}
+
+	task := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: "lint", Namespace: lintNamespace},
+		Spec:       *request.Task.DeepCopy(),
+	}
+	report := &TaskLintReport{Passed: true}
+
+	if request.Condition != nil {
+		met, err := reconciler.checkTaskCondition(ctx, task, request.Condition)
+		report.Condition = &TaskLintCheck{Passed: met && err == nil}
+		if err != nil {
+			report.Condition.Message = err.Error()
+		} else if !met {
+			report.Condition.Message = fmt.Sprintf("condition not met by the sample of %s, the task would be skipped", request.Condition.DependentTask)
+		}
+	}
+
+	if request.Conditions != nil {
+		met, err := reconciler.checkTaskConditions(ctx, task, request.Conditions)
+		report.Conditions = &TaskLintCheck{Passed: met && err == nil}
+		if err != nil {
+			report.Conditions.Message = err.Error()
+		} else if !met {
+			report.Conditions.Message = "conditions not met by the samples, the task would be skipped"
+		}
+	}
+
+	if task.Spec.OutputValidation != nil || task.Spec.HttpValidation != nil {
+		task.Status.HTTPStatusCode = request.HTTPStatusCode
+		report.Validation = &TaskLintCheck{Passed: true}
+		if err := validateTaskOutput(task, request.Output); err != nil {
+			report.Validation = &TaskLintCheck{Passed: false, Message: err.Error()}
+		}
+	}
+
+	if len(task.Spec.InputSources) > 0 {
+		input, _, err := reconciler.processInputSources(ctx, task)
+		report.Input = &TaskLintCheck{Passed: err == nil, Value: input}
+		if err != nil {
+			report.Input.Message = err.Error()
+		}
+	}
+
+	// The inputs run with their expect, once the input template was rendered
+	input := task.Spec.Input
+	if report.Input != nil && report.Input.Passed && task.Spec.InputTemplate != "" {
+		input = report.Input.Value
+	}
+	inputs, err := parseJSONInputs(input)
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range inputs {
+		expect, _ := item["expect"].(string)
+		if expect == "" {
+			continue
+		}
+		name := fmt.Sprintf("input-%d", i+1)
+		if n, exists := item["name"].(string); exists {
+			name = n
+		}
+		inputType := "cmd"
+		if t, exists := item["type"].(string); exists {
+			inputType = t
+		}
+
+		output, exists := request.Outputs[name]
+		if !exists {
+			output = request.Output
+		}
+		// HTTP inputs match the status code or the response body, like TaskWorker.Execute
+		content := output
+		if inputType != "cmd" {
+			var statusCode string
+			if request.HTTPStatusCode > 0 {
+				statusCode = fmt.Sprintf("%d", request.HTTPStatusCode)
+			}
+			content = statusCode + "|" + output
+		}
+
+		check := TaskLintCheck{Name: name, Passed: checkExpect(content, expect)}
+		if !check.Passed {
+			check.Message = fmt.Sprintf("expect validation failed: expected %s in %s", expect, truncateString(content, 200))
+			report.Passed = false
+		}
+		report.Expect = append(report.Expect, check)
+	}
+
+	for _, check := range []*TaskLintCheck{report.Condition, report.Conditions, report.Validation, report.Input} {
+		if check != nil && !check.Passed {
+			report.Passed = false
+		}
+	}
+	return report, nil
+}
+
+// DecodeTaskLintRequest reads a lint request in JSON or YAML
+func DecodeTaskLintRequest(r io.Reader) (*TaskLintRequest, error) {
+	var request TaskLintRequest
+	if err := yaml.NewYAMLOrJSONDecoder(r, 4096).Decode(&request); err != nil {
+		return nil, fmt.Errorf("invalid lint request: %w", err)
+	}
+	return &request, nil
+}
+
+// LintHandler serves LintTask over HTTP: a POST of a lint request in JSON or YAML is answered with
+// its report in JSON
+func LintHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		request, err := DecodeTaskLintRequest(http.MaxBytesReader(w, r.Body, lintRequestMaxBytes))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		report, err := LintTask(r.Context(), request)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,229 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestLintTask tests evaluating the condition, input template and expect of a task against samples
+func TestLintTask(t *testing.T) {
+	expectInput := `{"inputs": [{"name": "health", "type": "cmd", "input": "curl -s localhost/health", "expect": "ok|healthy"}]}`
+
+	tests := []struct {
+		name          string
+		request       TaskLintRequest
+		wantPassed    bool
+		wantExpect    []bool
+		wantCondition *bool
+		wantInput     string
+		wantMessage   string
+	}{
+		{
+			name: "expect matches the sample output",
+			request: TaskLintRequest{
+				Task:   mcallv1.McallTaskSpec{Type: "cmd", Input: expectInput},
+				Output: `{"status": "healthy"}`,
+			},
+			wantPassed: true,
+			wantExpect: []bool{true},
+		},
+		{
+			name: "expect doesn't match the sample output",
+			request: TaskLintRequest{
+				Task:   mcallv1.McallTaskSpec{Type: "cmd", Input: expectInput},
+				Output: `{"status": "down"}`,
+			},
+			wantExpect:  []bool{false},
+			wantMessage: "expect validation failed",
+		},
+		{
+			name: "expect of an HTTP input matches the status code",
+			request: TaskLintRequest{
+				Task:           mcallv1.McallTaskSpec{Type: "cmd", Input: `[{"name": "api", "type": "get", "input": "http://api/health", "expect": "200"}]`},
+				Outputs:        map[string]string{"api": "unavailable"},
+				HTTPStatusCode: 200,
+			},
+			wantPassed: true,
+			wantExpect: []bool{true},
+		},
+		{
+			name: "condition met and input template rendered",
+			request: TaskLintRequest{
+				Task: mcallv1.McallTaskSpec{
+					Type:          "cmd",
+					InputSources:  []mcallv1.TaskInputSource{{Name: "VERSION", TaskRef: "build", Field: "output", JSONPath: "$.version"}},
+					InputTemplate: "deploy --version ${VERSION}",
+				},
+				Condition: &mcallv1.TaskCondition{DependentTask: "build", When: "success", OutputContains: "version"},
+				Samples:   map[string]TaskLintSample{"build": {Output: `{"version": "1.2.3"}`}},
+			},
+			wantPassed:    true,
+			wantCondition: boolPtr(true),
+			wantInput:     "deploy --version 1.2.3",
+		},
+		{
+			name: "condition not met by a failed sample",
+			request: TaskLintRequest{
+				Task:      mcallv1.McallTaskSpec{Type: "cmd", Input: "echo deploy"},
+				Condition: &mcallv1.TaskCondition{DependentTask: "build", When: "success"},
+				Samples:   map[string]TaskLintSample{"build": {Phase: mcallv1.McallTaskPhaseFailed}},
+			},
+			wantCondition: boolPtr(false),
+			wantMessage:   "would be skipped",
+		},
+		{
+			name: "condition without sample",
+			request: TaskLintRequest{
+				Task:      mcallv1.McallTaskSpec{Type: "cmd", Input: "echo deploy"},
+				Condition: &mcallv1.TaskCondition{DependentTask: "build", When: "success"},
+			},
+			wantCondition: boolPtr(false),
+			wantMessage:   "dependent task build not found",
+		},
+		{
+			name: "anyOf conditions met by one sample",
+			request: TaskLintRequest{
+				Task: mcallv1.McallTaskSpec{Type: "cmd", Input: "echo notify"},
+				Conditions: &mcallv1.TaskConditions{AnyOf: []mcallv1.TaskCondition{
+					{DependentTask: "build", When: "success"},
+					{DependentTask: "test", When: "success"},
+				}},
+				Samples: map[string]TaskLintSample{
+					"build": {Phase: mcallv1.McallTaskPhaseFailed},
+					"test":  {},
+				},
+			},
+			wantPassed: true,
+		},
+		{
+			name: "allOf conditions not met by a failed sample",
+			request: TaskLintRequest{
+				Task: mcallv1.McallTaskSpec{Type: "cmd", Input: "echo notify"},
+				Conditions: &mcallv1.TaskConditions{AllOf: []mcallv1.TaskCondition{
+					{DependentTask: "build", When: "success"},
+					{DependentTask: "test", When: "success"},
+				}},
+				Samples: map[string]TaskLintSample{
+					"build": {Phase: mcallv1.McallTaskPhaseFailed},
+					"test":  {},
+				},
+			},
+			wantMessage: "conditions not met by the samples",
+		},
+		{
+			name: "output validation of a cmd task",
+			request: TaskLintRequest{
+				Task: mcallv1.McallTaskSpec{
+					Type:             "cmd",
+					Input:            "cat /health",
+					OutputValidation: &mcallv1.OutputValidation{ExpectedOutput: "healthy", OutputMatch: "contains"},
+				},
+				Output: "service is healthy",
+			},
+			wantPassed: true,
+		},
+		{
+			name: "http validation rejects the sample status code",
+			request: TaskLintRequest{
+				Task: mcallv1.McallTaskSpec{
+					Type:           "get",
+					Input:          "http://api/health",
+					HttpValidation: &mcallv1.HttpValidation{ExpectedStatusCodes: []int{200}},
+				},
+				Output:         "unavailable",
+				HTTPStatusCode: 503,
+			},
+			wantMessage: "HTTP status 503 not in expected status codes",
+		},
+		{
+			name: "input source path missing in the sample",
+			request: TaskLintRequest{
+				Task: mcallv1.McallTaskSpec{
+					Type:          "cmd",
+					InputSources:  []mcallv1.TaskInputSource{{Name: "VERSION", TaskRef: "build", Field: "output", JSONPath: "$.release"}},
+					InputTemplate: "deploy --version ${VERSION}",
+				},
+				Samples: map[string]TaskLintSample{"build": {Output: `{"version": "1.2.3"}`}},
+			},
+			wantMessage: "failed to extract JSONPath",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := LintTask(context.Background(), &tt.request)
+			if err != nil {
+				t.Fatalf("LintTask() unexpected error: %v", err)
+			}
+			if report.Passed != tt.wantPassed {
+				t.Errorf("LintTask() passed = %v, want %v", report.Passed, tt.wantPassed)
+			}
+			if len(report.Expect) != len(tt.wantExpect) {
+				t.Fatalf("LintTask() expect checks = %+v, want %d", report.Expect, len(tt.wantExpect))
+			}
+			for i, want := range tt.wantExpect {
+				if report.Expect[i].Passed != want {
+					t.Errorf("LintTask() expect %s passed = %v, want %v", report.Expect[i].Name, report.Expect[i].Passed, want)
+				}
+			}
+			if tt.wantCondition != nil && (report.Condition == nil || report.Condition.Passed != *tt.wantCondition) {
+				t.Errorf("LintTask() condition = %+v, want passed %v", report.Condition, *tt.wantCondition)
+			}
+			if tt.wantInput != "" && (report.Input == nil || report.Input.Value != tt.wantInput) {
+				t.Errorf("LintTask() input = %+v, want %q", report.Input, tt.wantInput)
+			}
+			if tt.wantMessage != "" {
+				body, _ := json.Marshal(report)
+				if !strings.Contains(string(body), tt.wantMessage) {
+					t.Errorf("LintTask() report = %s, want a message containing %q", body, tt.wantMessage)
+				}
+			}
+		})
+	}
+}
+
+// TestLintHandler tests serving lint requests in YAML and rejecting invalid ones
+func TestLintHandler(t *testing.T) {
+	handler := LintHandler()
+
+	body := `
+task:
+  type: cmd
+  input: '[{"name": "health", "input": "curl -s localhost/health", "expect": "ok"}]'
+output: ok
+`
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/lint", strings.NewReader(body)))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("POST /lint status = %d, body = %s", recorder.Code, recorder.Body.String())
+	}
+	var report TaskLintReport
+	if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil {
+		t.Fatalf("invalid report: %v", err)
+	}
+	if !report.Passed || len(report.Expect) != 1 || report.Expect[0].Name != "health" {
+		t.Errorf("POST /lint report = %+v", report)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/lint", strings.NewReader("task: [")))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("POST /lint with an invalid body status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/lint", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /lint status = %d, want %d", recorder.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
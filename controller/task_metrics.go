@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+var (
+	taskExecutions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcall_task_executions_total",
+		Help: "Number of task executions by type and resulting phase",
+	}, []string{"namespace", "task", "workflow", "type", "phase"})
+	taskExecutionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mcall_task_execution_duration_seconds",
+		Help:    "Wall time of task executions, excluding executor queueing",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300},
+	}, []string{"namespace", "task", "workflow", "type"})
+	taskHTTPResponses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcall_task_http_responses_total",
+		Help: "Number of HTTP responses of task executions by status code",
+	}, []string{"namespace", "task", "workflow", "code"})
+	taskValidationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcall_task_validation_failures_total",
+		Help: "Number of task executions failing their output or HTTP validation rules",
+	}, []string{"namespace", "task", "workflow"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(taskExecutions, taskExecutionDuration, taskHTTPResponses, taskValidationFailures)
+}
+
+// recordTaskExecution records the metrics of a task execution
+func recordTaskExecution(task *mcallv1.McallTask, phase mcallv1.McallTaskPhase, executionTime time.Duration) {
+	workflow := task.Labels["mcall.tz.io/workflow"]
+	taskExecutions.WithLabelValues(task.Namespace, task.Name, workflow, task.Spec.Type, string(phase)).Inc()
+	taskExecutionDuration.WithLabelValues(task.Namespace, task.Name, workflow, task.Spec.Type).Observe(executionTime.Seconds())
+	if task.Status.HTTPStatusCode != 0 {
+		taskHTTPResponses.WithLabelValues(task.Namespace, task.Name, workflow, strconv.Itoa(task.Status.HTTPStatusCode)).Inc()
+	}
+}
+
+// recordTaskValidationFailure records a task execution failing its validation rules
+func recordTaskValidationFailure(task *mcallv1.McallTask) {
+	taskValidationFailures.WithLabelValues(task.Namespace, task.Name, task.Labels["mcall.tz.io/workflow"]).Inc()
+}
+
+// deleteTaskMetrics drops the series of a deleted task
+func deleteTaskMetrics(task *mcallv1.McallTask) {
+	labels := prometheus.Labels{"namespace": task.Namespace, "task": task.Name}
+	taskExecutions.DeletePartialMatch(labels)
+	taskExecutionDuration.DeletePartialMatch(labels)
+	taskHTTPResponses.DeletePartialMatch(labels)
+	taskValidationFailures.DeletePartialMatch(labels)
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestTaskMetrics tests the execution metrics of a task and the success ratio of its workflow
+func TestTaskMetrics(t *testing.T) {
+	task := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly-api", Namespace: "metrics-test", Labels: map[string]string{"mcall.tz.io/workflow": "nightly"}},
+		Spec:       mcallv1.McallTaskSpec{Type: "get"},
+		Status:     mcallv1.McallTaskStatus{HTTPStatusCode: 503},
+	}
+	recordTaskValidationFailure(task)
+	recordTaskExecution(task, mcallv1.McallTaskPhaseFailed, 2*time.Second)
+	task.Status.HTTPStatusCode = 200
+	recordTaskExecution(task, mcallv1.McallTaskPhaseSucceeded, 100*time.Millisecond)
+
+	if got := testutil.ToFloat64(taskExecutions.WithLabelValues("metrics-test", "nightly-api", "nightly", "get", "Failed")); got != 1 {
+		t.Errorf("failed executions = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(taskHTTPResponses.WithLabelValues("metrics-test", "nightly-api", "nightly", "503")); got != 1 {
+		t.Errorf("503 responses = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(taskValidationFailures.WithLabelValues("metrics-test", "nightly-api", "nightly")); got != 1 {
+		t.Errorf("validation failures = %v, want 1", got)
+	}
+
+	workflow := &mcallv1.McallWorkflow{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "metrics-test"}}
+	for _, phase := range []mcallv1.McallWorkflowPhase{mcallv1.McallWorkflowPhaseSucceeded, mcallv1.McallWorkflowPhaseFailed,
+		mcallv1.McallWorkflowPhaseSucceeded, mcallv1.McallWorkflowPhaseSucceeded} {
+		workflow.Status.Phase = phase
+		recordWorkflowRunMetrics(workflow)
+	}
+	if got := testutil.ToFloat64(workflowSuccessRatio.WithLabelValues("metrics-test", "nightly")); got != 0.75 {
+		t.Errorf("workflow success ratio = %v, want 0.75", got)
+	}
+
+	standalone := &mcallv1.McallTask{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "metrics-test"}, Spec: mcallv1.McallTaskSpec{Type: "cmd"}}
+	recordTaskExecution(standalone, mcallv1.McallTaskPhaseSucceeded, time.Second)
+	series := testutil.CollectAndCount(taskExecutions)
+	deleteTaskMetrics(standalone)
+	if got := testutil.CollectAndCount(taskExecutions); got != series-1 {
+		t.Errorf("execution series after deleting the standalone task = %d, want %d", got, series-1)
+	}
+}
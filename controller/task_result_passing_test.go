@@ -2,9 +2,14 @@ package controller
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+	"github.com/go-logr/logr"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -55,6 +60,48 @@ func TestExtractJSONPath(t *testing.T) {
 			expected: "",
 			wantErr:  true,
 		},
+		{
+			name:     "array index",
+			jsonStr:  `{"items": [{"name": "a"}, {"name": "b"}]}`,
+			path:     "$.items[1].name",
+			expected: "b",
+		},
+		{
+			name:     "filter",
+			jsonStr:  `{"items": [{"name": "a", "id": 1}, {"name": "x", "id": 2}]}`,
+			path:     "$.items[?(@.name=='x')].id",
+			expected: "[2]",
+		},
+		{
+			name:     "numeric filter",
+			jsonStr:  `{"items": [{"name": "a", "id": 1}, {"name": "x", "id": 2}]}`,
+			path:     "$.items[?(@.id > 1)].name",
+			expected: `["x"]`,
+		},
+		{
+			name:     "wildcard",
+			jsonStr:  `{"items": [{"name": "a"}, {"name": "b"}]}`,
+			path:     "$.items[*].name",
+			expected: `["a","b"]`,
+		},
+		{
+			name:     "recursive descent",
+			jsonStr:  `{"data": {"user": {"id": "u1"}, "team": {"lead": {"id": "u2"}}}}`,
+			path:     "$..lead.id",
+			expected: `["u2"]`,
+		},
+		{
+			name:     "filter without matches",
+			jsonStr:  `{"items": [{"name": "a"}]}`,
+			path:     "$.items[?(@.name=='x')]",
+			expected: "[]",
+		},
+		{
+			name:    "invalid path",
+			jsonStr: `{"status": "ok"}`,
+			path:    "$.items[?(@.name==",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -127,6 +174,52 @@ func TestRenderTemplate(t *testing.T) {
 	}
 }
 
+// TestInterpolateEnvironment tests exporting spec.environment and substituting ${NAME} into inputs
+func TestInterpolateEnvironment(t *testing.T) {
+	if got := interpolateEnvironment("${HOST}/${PATH_PART}?q=${HOME}&v=${VALUE}", map[string]string{
+		"HOST": "http://api", "PATH_PART": "v1", "VALUE": "${HOST}",
+	}); got != "http://api/v1?q=${HOME}&v=${HOST}" {
+		t.Errorf("interpolateEnvironment() = %q", got)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("path " + r.URL.Path))
+	}))
+	defer server.Close()
+	environment := map[string]string{"REGION": "eu-west", "MESSAGE": `say "hi"`, "API": server.URL}
+
+	tests := []struct {
+		name       string
+		taskType   string
+		input      string
+		wantOutput string
+	}{
+		{name: "exported to the command", taskType: "cmd", input: `echo "$REGION"`, wantOutput: "eu-west"},
+		{name: "interpolated into the command", taskType: "cmd", input: "echo ${REGION}-${UNSET:-default}", wantOutput: "eu-west-default"},
+		{name: "JSON input values stay valid JSON", taskType: "cmd", input: `[{"input": "echo '${MESSAGE}'"}]`, wantOutput: `say "hi"`},
+		{name: "interpolated into the URL", taskType: "get", input: "${API}/regions/${REGION}", wantOutput: "path /regions/eu-west"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{Name: "env", Namespace: "default"},
+				Spec:       mcallv1.McallTaskSpec{Type: tt.taskType, Input: tt.input, Environment: environment},
+			}
+			output, err := executeTask(task, 5*time.Second, logr.Discard())
+			if err != nil {
+				t.Fatalf("executeTask() error = %v, output = %s", err, output)
+			}
+			if strings.TrimSpace(output) != tt.wantOutput {
+				t.Errorf("output = %q, want %q", output, tt.wantOutput)
+			}
+			if task.Spec.Input != tt.input {
+				t.Errorf("task input changed to %q", task.Spec.Input)
+			}
+		})
+	}
+}
+
 // TestCheckTaskCondition tests task execution condition checking
 func TestCheckTaskCondition(t *testing.T) {
 	scheme := runtime.NewScheme()
@@ -269,6 +362,103 @@ func TestCheckTaskCondition(t *testing.T) {
 	}
 }
 
+// TestCheckTaskConditions tests allOf/anyOf conditions over several dependent tasks
+func TestCheckTaskConditions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+
+	newTask := func(name string, phase mcallv1.McallTaskPhase) *mcallv1.McallTask {
+		return &mcallv1.McallTask{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns"},
+			Status:     mcallv1.McallTaskStatus{Phase: phase},
+		}
+	}
+	currentTask := newTask("current-task", mcallv1.McallTaskPhasePending)
+	reconciler := &McallTaskReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			newTask("a", mcallv1.McallTaskPhaseSucceeded),
+			newTask("b", mcallv1.McallTaskPhaseFailed),
+			newTask("c", mcallv1.McallTaskPhaseRunning),
+			currentTask,
+		).Build(),
+		Scheme: scheme,
+	}
+
+	tests := []struct {
+		name       string
+		conditions mcallv1.TaskConditions
+		want       bool
+		wantErr    bool
+	}{
+		{
+			name: "allOf met",
+			conditions: mcallv1.TaskConditions{AllOf: []mcallv1.TaskCondition{
+				{DependentTask: "a", When: "success"},
+				{DependentTask: "b", When: "failure"},
+			}},
+			want: true,
+		},
+		{
+			name: "allOf not met",
+			conditions: mcallv1.TaskConditions{AllOf: []mcallv1.TaskCondition{
+				{DependentTask: "a", When: "success"},
+				{DependentTask: "b", When: "success"},
+			}},
+			want: false,
+		},
+		{
+			name: "anyOf met",
+			conditions: mcallv1.TaskConditions{AnyOf: []mcallv1.TaskCondition{
+				{DependentTask: "a", When: "failure"},
+				{DependentTask: "b", When: "failure"},
+			}},
+			want: true,
+		},
+		{
+			name: "anyOf not met",
+			conditions: mcallv1.TaskConditions{AnyOf: []mcallv1.TaskCondition{
+				{DependentTask: "a", When: "failure"},
+				{DependentTask: "b", When: "success"},
+			}},
+			want: false,
+		},
+		{
+			name: "allOf and anyOf",
+			conditions: mcallv1.TaskConditions{
+				AllOf: []mcallv1.TaskCondition{{DependentTask: "a", When: "success"}},
+				AnyOf: []mcallv1.TaskCondition{{DependentTask: "b", When: "success"}},
+			},
+			want: false,
+		},
+		{
+			name: "dependent task running",
+			conditions: mcallv1.TaskConditions{AnyOf: []mcallv1.TaskCondition{
+				{DependentTask: "a", When: "success"},
+				{DependentTask: "c", When: "success"},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reconciler.checkTaskConditions(context.Background(), currentTask, &tt.conditions)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "not completed yet") {
+					t.Errorf("checkTaskConditions() error = %v, want not completed yet", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkTaskConditions() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("checkTaskConditions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 // TestProcessInputSources tests input sources processing
 func TestProcessInputSources(t *testing.T) {
 	scheme := runtime.NewScheme()
@@ -349,6 +539,67 @@ func TestProcessInputSources(t *testing.T) {
 			expectEnvVars: map[string]string{"STATUS": "unknown"},
 			wantErr:       false,
 		},
+		{
+			name: "jq transform",
+			inputSources: []mcallv1.TaskInputSource{
+				{
+					Name:    "NAMES",
+					TaskRef: "ref-task",
+					Field:   "output",
+					JQ:      `[.items[].name] | join(",")`,
+				},
+			},
+			refTaskOutput: `{"items": [{"name": "a"}, {"name": "b"}]}`,
+			refTaskPhase:  mcallv1.McallTaskPhaseSucceeded,
+			expectEnvVars: map[string]string{"NAMES": "a,b"},
+			expectedInput: `{"NAMES":"a,b"}`,
+		},
+		{
+			name: "jq after jsonPath",
+			inputSources: []mcallv1.TaskInputSource{
+				{
+					Name:     "TOTAL",
+					TaskRef:  "ref-task",
+					Field:    "output",
+					JSONPath: "$.items",
+					JQ:       `map(.count) | add`,
+				},
+			},
+			refTaskOutput: `{"items": [{"count": 2}, {"count": 3}]}`,
+			refTaskPhase:  mcallv1.McallTaskPhaseSucceeded,
+			expectEnvVars: map[string]string{"TOTAL": "5"},
+			expectedInput: `{"TOTAL":"5"}`,
+		},
+		{
+			name: "jq error uses default",
+			inputSources: []mcallv1.TaskInputSource{
+				{
+					Name:    "NAMES",
+					TaskRef: "ref-task",
+					Field:   "output",
+					JQ:      `.items[].name`,
+					Default: "none",
+				},
+			},
+			refTaskOutput: `not json`,
+			refTaskPhase:  mcallv1.McallTaskPhaseSucceeded,
+			expectEnvVars: map[string]string{"NAMES": "none"},
+			expectedInput: `{"NAMES":"none"}`,
+		},
+		{
+			name: "invalid jq program",
+			inputSources: []mcallv1.TaskInputSource{
+				{
+					Name:    "NAMES",
+					TaskRef: "ref-task",
+					Field:   "output",
+					JQ:      `.items[`,
+				},
+			},
+			refTaskOutput: `{"items": []}`,
+			refTaskPhase:  mcallv1.McallTaskPhaseSucceeded,
+			wantErr:       true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -423,6 +674,43 @@ func TestProcessInputSources(t *testing.T) {
 	}
 }
 
+// TestCrossNamespaceInputSources tests input sources referencing tasks in other namespaces
+func TestCrossNamespaceInputSources(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+
+	refTask := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: "inventory-list", Namespace: "shared"},
+		Status: mcallv1.McallTaskStatus{
+			Phase:  mcallv1.McallTaskPhaseSucceeded,
+			Result: &mcallv1.McallTaskResult{Output: `{"count": 3}`, ErrorCode: "0"},
+		},
+	}
+	currentTask := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "test-ns"},
+		Spec: mcallv1.McallTaskSpec{InputSources: []mcallv1.TaskInputSource{
+			{Name: "COUNT", TaskRef: "inventory-list", Namespace: "shared", Field: "output", JSONPath: "$.count"},
+			{Name: "LOCAL", TaskRef: "inventory-list", Raw: true, Field: "phase", Default: "missing"},
+		}},
+	}
+	reconciler := &McallTaskReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(refTask, currentTask).Build(),
+		Scheme: scheme,
+	}
+
+	_, envVars, err := reconciler.processInputSources(context.Background(), currentTask)
+	if err != nil {
+		t.Fatalf("processInputSources() unexpected error: %v", err)
+	}
+	if envVars["COUNT"] != "3" {
+		t.Errorf("processInputSources() envVars[COUNT] = %q, want %q", envVars["COUNT"], "3")
+	}
+	// Without namespace the task is looked up in the namespace of the current task
+	if envVars["LOCAL"] != "missing" {
+		t.Errorf("processInputSources() envVars[LOCAL] = %q, want %q", envVars["LOCAL"], "missing")
+	}
+}
+
 // TestTruncateString tests string truncation utility
 func TestTruncateString(t *testing.T) {
 	tests := []struct {
@@ -466,3 +754,188 @@ func TestTruncateString(t *testing.T) {
 		})
 	}
 }
+
+// TestGetConcurrencyQueuePosition tests queueing of tasks sharing a concurrency key
+func TestGetConcurrencyQueuePosition(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+
+	base := metav1.Now()
+	newTask := func(name, namespace, key string, phase mcallv1.McallTaskPhase, queuePosition int32, createdOffset int) *mcallv1.McallTask {
+		return &mcallv1.McallTask{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(base.Add(time.Duration(createdOffset) * time.Second)),
+			},
+			Spec:   mcallv1.McallTaskSpec{Type: "cmd", Input: "echo", ConcurrencyKey: key},
+			Status: mcallv1.McallTaskStatus{Phase: phase, QueuePosition: queuePosition},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		others   []client.Object
+		expected int32
+	}{
+		{
+			name:     "no other task with key",
+			others:   []client.Object{newTask("other", "default", "other-key", mcallv1.McallTaskPhaseRunning, 0, 0)},
+			expected: 0,
+		},
+		{
+			name:     "task with key running in another namespace",
+			others:   []client.Object{newTask("other", "ops", "restart-api", mcallv1.McallTaskPhaseRunning, 0, 0)},
+			expected: 1,
+		},
+		{
+			name: "running task and earlier queued task",
+			others: []client.Object{
+				newTask("running", "default", "restart-api", mcallv1.McallTaskPhaseRunning, 0, -10),
+				newTask("queued", "default", "restart-api", mcallv1.McallTaskPhasePending, 1, -5),
+			},
+			expected: 2,
+		},
+		{
+			name: "later queued task does not count",
+			others: []client.Object{
+				newTask("running", "default", "restart-api", mcallv1.McallTaskPhaseRunning, 0, -10),
+				newTask("queued", "default", "restart-api", mcallv1.McallTaskPhasePending, 1, 5),
+			},
+			expected: 1,
+		},
+		{
+			name:     "completed task with key",
+			others:   []client.Object{newTask("done", "default", "restart-api", mcallv1.McallTaskPhaseSucceeded, 0, -10)},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := newTask("me", "default", "restart-api", mcallv1.McallTaskPhasePending, 0, 0)
+			objects := append([]client.Object{task}, tt.others...)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			r := &McallTaskReconciler{Client: fakeClient, Scheme: scheme}
+
+			position, err := r.getConcurrencyQueuePosition(context.Background(), task)
+			if err != nil {
+				t.Fatalf("getConcurrencyQueuePosition() error = %v", err)
+			}
+			if position != tt.expected {
+				t.Errorf("getConcurrencyQueuePosition() = %d, want %d", position, tt.expected)
+			}
+		})
+	}
+}
+
+// TestGetStaggerWait tests spacing of task starts within a workflow
+func TestGetStaggerWait(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+
+	newTask := func(name string, stagger string, startedAgo time.Duration) *mcallv1.McallTask {
+		task := &mcallv1.McallTask{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Labels:      map[string]string{"mcall.tz.io/workflow": "burst"},
+				Annotations: map[string]string{},
+			},
+			Status: mcallv1.McallTaskStatus{Phase: mcallv1.McallTaskPhasePending},
+		}
+		if stagger != "" {
+			task.Annotations["mcall.tz.io/stagger-seconds"] = stagger
+		}
+		if startedAgo > 0 {
+			start := metav1.NewTime(time.Now().Add(-startedAgo))
+			task.Status.Phase = mcallv1.McallTaskPhaseRunning
+			task.Status.StartTime = &start
+		}
+		return task
+	}
+
+	tests := []struct {
+		name     string
+		stagger  string
+		others   []client.Object
+		wantWait bool
+	}{
+		{
+			name:     "no stagger",
+			stagger:  "",
+			others:   []client.Object{newTask("other", "", time.Second)},
+			wantWait: false,
+		},
+		{
+			name:     "first task starts immediately",
+			stagger:  "10",
+			others:   []client.Object{newTask("other", "10", 0)},
+			wantWait: false,
+		},
+		{
+			name:     "recent start waits",
+			stagger:  "10",
+			others:   []client.Object{newTask("other", "10", 2*time.Second)},
+			wantWait: true,
+		},
+		{
+			name:     "stagger interval elapsed",
+			stagger:  "10",
+			others:   []client.Object{newTask("other", "10", 30*time.Second)},
+			wantWait: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := newTask("me", tt.stagger, 0)
+			objects := append([]client.Object{task}, tt.others...)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			r := &McallTaskReconciler{Client: fakeClient, Scheme: scheme}
+
+			wait, err := r.getStaggerWait(context.Background(), task)
+			if err != nil {
+				t.Fatalf("getStaggerWait() error = %v", err)
+			}
+			if (wait > 0) != tt.wantWait {
+				t.Errorf("getStaggerWait() = %v, want wait %v", wait, tt.wantWait)
+			}
+		})
+	}
+}
+
+// TestDataFlowEdge tests DAG edges derived from task InputSources
+func TestDataFlowEdge(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   mcallv1.TaskInputSource
+		expected string
+	}{
+		{
+			name:     "field only",
+			source:   mcallv1.TaskInputSource{Name: "HEALTH", TaskRef: "health-check", Field: "output"},
+			expected: "HEALTH ← output",
+		},
+		{
+			name:     "field with JSONPath",
+			source:   mcallv1.TaskInputSource{Name: "STATUS", TaskRef: "health-check", Field: "output", JSONPath: "$.data.status"},
+			expected: "STATUS ← output $.data.status",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edge := dataFlowEdge(tt.source, "notify")
+			if edge.Type != "dataflow" {
+				t.Errorf("dataFlowEdge().Type = %q, want %q", edge.Type, "dataflow")
+			}
+			if edge.Source != "health-check" || edge.Target != "notify" {
+				t.Errorf("dataFlowEdge() = %s -> %s, want health-check -> notify", edge.Source, edge.Target)
+			}
+			if edge.Label != tt.expected {
+				t.Errorf("dataFlowEdge().Label = %q, want %q", edge.Label, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// getTaskRunHistoryLimit returns the number of McallTaskRuns kept per task
+func getTaskRunHistoryLimit() int {
+	return getEnvIntOrDefault("TASK_RUN_HISTORY_LIMIT", 10)
+}
+
+// createTaskRun records the execution that just completed as a McallTaskRun and returns its name.
+// Older runs of the same task beyond the history limit are pruned.
+func (r *McallTaskReconciler) createTaskRun(ctx context.Context, task *mcallv1.McallTask) (string, error) {
+	log := log.FromContext(ctx)
+
+	limit := getTaskRunHistoryLimit()
+	if limit <= 0 {
+		return "", nil // Task run history disabled
+	}
+
+	name := newTaskRunName(task)
+	if name == "" {
+		return "", nil
+	}
+
+	// Link to the triggering workflow run, if any
+	var workflowRunRef string
+	workflowName, inWorkflow := task.Labels["mcall.tz.io/workflow"]
+	if runID, hasRun := task.Labels["mcall.tz.io/run"]; inWorkflow && hasRun {
+		workflowRunRef = workflowRunName(workflowName, runID)
+	}
+
+	taskRun := &mcallv1.McallTaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: task.Namespace,
+			Labels: map[string]string{
+				"mcall.tz.io/task-run-of": task.Name,
+			},
+		},
+		Spec: mcallv1.McallTaskRunSpec{
+			TaskRef:        task.Name,
+			Type:           task.Spec.Type,
+			Input:          task.Spec.Input,
+			WorkflowRunRef: workflowRunRef,
+		},
+	}
+	if inWorkflow {
+		taskRun.Labels["mcall.tz.io/workflow"] = workflowName
+	}
+
+	if err := r.Create(ctx, taskRun); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return taskRun.Name, nil
+		}
+		return "", err
+	}
+
+	// Status is a subresource, set it after creation
+	taskRun.Status = mcallv1.McallTaskRunStatus{
+		Phase:           task.Status.Phase,
+		StartTime:       task.Status.StartTime,
+		CompletionTime:  task.Status.CompletionTime,
+		ExecutionTimeMs: task.Status.ExecutionTimeMs,
+		HTTPStatusCode:  task.Status.HTTPStatusCode,
+	}
+	if task.Status.Result != nil {
+		taskRun.Status.Result = task.Status.Result.DeepCopy()
+	}
+	if err := r.Status().Update(ctx, taskRun); err != nil {
+		return "", err
+	}
+
+	log.Info("Recorded task run", "task", task.Name, "taskRun", taskRun.Name, "workflowRun", workflowRunRef)
+
+	if err := r.pruneTaskRuns(ctx, task, limit); err != nil {
+		log.Error(err, "Failed to prune task runs", "task", task.Name)
+	}
+
+	return taskRun.Name, nil
+}
+
+// newTaskRunName returns the name of the McallTaskRun of the execution that just completed,
+// or "" without start and completion times
+func newTaskRunName(task *mcallv1.McallTask) string {
+	started := task.Status.CompletionTime
+	if task.Status.StartTime != nil {
+		started = task.Status.StartTime
+	}
+	if started == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s-%s", task.Name, started.UTC().Format("20060102-150405"))
+}
+
+// pruneTaskRuns deletes the oldest McallTaskRuns of a task beyond the history limit
+func (r *McallTaskReconciler) pruneTaskRuns(ctx context.Context, task *mcallv1.McallTask, limit int) error {
+	var taskRuns mcallv1.McallTaskRunList
+	if err := r.List(ctx, &taskRuns, client.InNamespace(task.Namespace),
+		client.MatchingLabels{"mcall.tz.io/task-run-of": task.Name}); err != nil {
+		return err
+	}
+	if len(taskRuns.Items) <= limit {
+		return nil
+	}
+
+	// Names end with the start timestamp, newest first
+	sort.Slice(taskRuns.Items, func(i, j int) bool {
+		return taskRuns.Items[i].Name > taskRuns.Items[j].Name
+	})
+
+	for _, taskRun := range taskRuns.Items[limit:] {
+		if err := r.Delete(ctx, &taskRun); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		if result := taskRun.Status.Result; result != nil && result.OutputConfigMap != "" {
+			if err := r.deleteOutputConfigMap(ctx, taskRun.Namespace, result.OutputConfigMap); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
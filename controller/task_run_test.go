@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestCreateTaskRun tests recording executions as McallTaskRuns and pruning old runs
+func TestCreateTaskRun(t *testing.T) {
+	t.Setenv("TASK_RUN_HISTORY_LIMIT", "2")
+
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&mcallv1.McallTaskRun{}).
+		Build()
+	r := &McallTaskReconciler{Client: fakeClient, Scheme: scheme}
+
+	base := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	var names []string
+	for i := 0; i < 3; i++ {
+		start := metav1.NewTime(base.Add(time.Duration(i) * time.Minute))
+		task := &mcallv1.McallTask{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "health-api",
+				Namespace: "default",
+				Labels: map[string]string{
+					"mcall.tz.io/workflow": "health",
+					"mcall.tz.io/run":      "20250102-030405",
+				},
+			},
+			Spec: mcallv1.McallTaskSpec{Type: "get", Input: "http://example.com"},
+			Status: mcallv1.McallTaskStatus{
+				Phase:          mcallv1.McallTaskPhaseSucceeded,
+				StartTime:      &start,
+				CompletionTime: &start,
+				Result:         &mcallv1.McallTaskResult{Output: "ok", ErrorCode: "0"},
+			},
+		}
+
+		name, err := r.createTaskRun(ctx, task)
+		if err != nil {
+			t.Fatalf("createTaskRun() error = %v", err)
+		}
+		names = append(names, name)
+	}
+
+	if names[0] != "health-api-20250102-030405" {
+		t.Errorf("createTaskRun() name = %q, want %q", names[0], "health-api-20250102-030405")
+	}
+
+	var latest mcallv1.McallTaskRun
+	if err := fakeClient.Get(ctx, types.NamespacedName{Name: names[2], Namespace: "default"}, &latest); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if latest.Spec.WorkflowRunRef != "health-20250102-030405" {
+		t.Errorf("WorkflowRunRef = %q, want %q", latest.Spec.WorkflowRunRef, "health-20250102-030405")
+	}
+	if latest.Status.Phase != mcallv1.McallTaskPhaseSucceeded || latest.Status.Result == nil || latest.Status.Result.Output != "ok" {
+		t.Errorf("status = %+v, want Succeeded with output", latest.Status)
+	}
+
+	// Only the newest runs up to the limit are kept
+	var taskRuns mcallv1.McallTaskRunList
+	if err := fakeClient.List(ctx, &taskRuns, client.MatchingLabels{"mcall.tz.io/task-run-of": "health-api"}); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(taskRuns.Items) != 2 {
+		t.Fatalf("task runs = %d, want 2", len(taskRuns.Items))
+	}
+	for _, taskRun := range taskRuns.Items {
+		if taskRun.Name == names[0] {
+			t.Errorf("oldest task run %q was not pruned", names[0])
+		}
+	}
+}
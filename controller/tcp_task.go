@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// executeTCPCheck dials host:port and returns a connection message followed by the banner.
+// When readBanner is set, the first data sent by the server (e.g. an SSH or SMTP greeting)
+// is read until the timeout; a server that sends nothing yields an empty banner.
+func executeTCPCheck(target string, timeout time.Duration, readBanner bool) (string, error) {
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		return "", fmt.Errorf("invalid tcp target %q, want host:port: %w", target, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	conn, err := net.DialTimeout("tcp", target, timeout)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to %s: %w", target, err)
+	}
+	defer conn.Close()
+
+	output := fmt.Sprintf("Connected to %s", target)
+	if !readBanner {
+		return output, nil
+	}
+
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return output, err
+	}
+	buffer := make([]byte, 4096)
+	n, err := conn.Read(buffer)
+	if err != nil && n == 0 && !errors.Is(err, os.ErrDeadlineExceeded) && !errors.Is(err, io.EOF) {
+		return output, fmt.Errorf("failed to read banner from %s: %w", target, err)
+	}
+	if banner := strings.TrimSpace(string(buffer[:n])); banner != "" {
+		output += "\n" + banner
+	}
+	return output, nil
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestExecuteTCPCheck tests port checks with and without reading the server banner
+func TestExecuteTCPCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("SSH-2.0-OpenSSH_9.6\r\n"))
+			conn.Close()
+		}
+	}()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	closedTarget := closed.Addr().String()
+	closed.Close()
+
+	target := listener.Addr().String()
+	tests := []struct {
+		name       string
+		target     string
+		readBanner bool
+		wantOutput string
+		wantErr    bool
+	}{
+		{name: "open port", target: target, wantOutput: "Connected to " + target},
+		{name: "banner", target: target, readBanner: true, wantOutput: "SSH-2.0-OpenSSH_9.6"},
+		{name: "closed port", target: closedTarget, wantErr: true},
+		{name: "invalid target", target: "telnet example.com 80", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := executeTCPCheck(tt.target, 2*time.Second, tt.readBanner)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("executeTCPCheck() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.Contains(output, tt.wantOutput) {
+				t.Errorf("output = %q, want it to contain %q", output, tt.wantOutput)
+			}
+		})
+	}
+}
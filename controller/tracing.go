@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// tracerName is the instrumentation name of the spans of workflow runs, tasks and inputs
+const tracerName = "github.com/doohee323/tz-mcall-operator/controller"
+
+// TracingConfig holds the OTLP export of the spans of workflow runs and task executions
+type TracingConfig struct {
+	Enabled     bool
+	Endpoint    string  // OTLP gRPC endpoint, host:port or an http:// or https:// URL
+	ServiceName string  // service.name of the exported spans
+	SampleRatio float64 // Ratio of the traces sampled, 0 to 1
+}
+
+// GetTracingConfig reads the tracing configuration from environment variables
+func GetTracingConfig() TracingConfig {
+	ratio, err := strconv.ParseFloat(getEnvOrDefault("TRACING_SAMPLE_RATIO", "1"), 64)
+	if err != nil || ratio < 0 || ratio > 1 {
+		ratio = 1
+	}
+	return TracingConfig{
+		Enabled:     getEnvOrDefault("TRACING_ENABLED", "false") == "true",
+		Endpoint:    getEnvOrDefault("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4317"),
+		ServiceName: getEnvOrDefault("OTEL_SERVICE_NAME", "mcall-operator"),
+		SampleRatio: ratio,
+	}
+}
+
+// SetupTracing installs the tracer provider exporting spans over OTLP and the W3C trace context
+// propagator. It returns the function flushing and stopping the export, a no-op when disabled.
+func SetupTracing(ctx context.Context, config TracingConfig) (func(context.Context) error, error) {
+	if !config.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	// Endpoints without https:// are plaintext, like an in-cluster collector
+	endpoint := config.Endpoint
+	opts := []otlptracegrpc.Option{}
+	if strings.HasPrefix(endpoint, "https://") {
+		endpoint = strings.TrimPrefix(endpoint, "https://")
+	} else {
+		endpoint = strings.TrimPrefix(endpoint, "http://")
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, append(opts, otlptracegrpc.WithEndpoint(strings.TrimSuffix(endpoint, "/")))...)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", config.ServiceName)))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// tracer returns the tracer of the global provider, which drops spans unless SetupTracing installed one
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+var (
+	// Spans of the workflow runs in progress by workflow, ended when the run completes
+	workflowRunSpansMu sync.Mutex
+	workflowRunSpans   = make(map[string]trace.Span)
+)
+
+// startWorkflowRunSpan starts the span of a workflow run, the parent of the spans of its tasks
+func startWorkflowRunSpan(ctx context.Context, workflow *mcallv1.McallWorkflow) context.Context {
+	opts := []trace.SpanStartOption{trace.WithAttributes(
+		attribute.String("mcall.namespace", workflow.Namespace),
+		attribute.String("mcall.workflow", workflow.Name),
+		attribute.Int("mcall.workflow.tasks", len(workflow.Spec.Tasks)),
+	)}
+	if workflow.Status.StartTime != nil {
+		opts = append(opts, trace.WithTimestamp(workflow.Status.StartTime.Time))
+	}
+	ctx, span := tracer().Start(ctx, "mcall.workflow.run", opts...)
+
+	key := workflow.Namespace + "/" + workflow.Name
+	workflowRunSpansMu.Lock()
+	// A run that never completed, e.g. when the workflow was restarted
+	if previous, exists := workflowRunSpans[key]; exists {
+		previous.SetStatus(codes.Error, "run superseded by a new run")
+		previous.End()
+	}
+	workflowRunSpans[key] = span
+	workflowRunSpansMu.Unlock()
+	return ctx
+}
+
+// endWorkflowRunSpan ends the span of a completed workflow run. Runs started before the controller
+// restarted have no span.
+func endWorkflowRunSpan(workflow *mcallv1.McallWorkflow) {
+	key := workflow.Namespace + "/" + workflow.Name
+	workflowRunSpansMu.Lock()
+	span, exists := workflowRunSpans[key]
+	delete(workflowRunSpans, key)
+	workflowRunSpansMu.Unlock()
+	if !exists {
+		return
+	}
+
+	span.SetAttributes(attribute.String("mcall.phase", string(workflow.Status.Phase)))
+	if workflow.Status.Phase == mcallv1.McallWorkflowPhaseFailed {
+		span.SetStatus(codes.Error, "run failed")
+	}
+	if workflow.Status.CompletionTime != nil {
+		span.End(trace.WithTimestamp(workflow.Status.CompletionTime.Time))
+	} else {
+		span.End()
+	}
+}
+
+// injectTraceParent records the trace context of ctx in the TraceParentAnnotation of a task
+// instance; nothing is recorded while tracing is disabled
+func injectTraceParent(ctx context.Context, annotations map[string]string) {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if traceParent := carrier.Get("traceparent"); traceParent != "" {
+		annotations[mcallv1.TraceParentAnnotation] = traceParent
+	}
+}
+
+// startTaskSpan starts the span of a task execution, a child of the workflow run of the task
+// instance if any
+func startTaskSpan(ctx context.Context, task *mcallv1.McallTask) (context.Context, trace.Span) {
+	if traceParent := task.Annotations[mcallv1.TraceParentAnnotation]; traceParent != "" {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier{"traceparent": traceParent})
+	}
+	return tracer().Start(ctx, "mcall.task.execute", trace.WithAttributes(
+		attribute.String("mcall.namespace", task.Namespace),
+		attribute.String("mcall.task", task.Name),
+		attribute.String("mcall.workflow", task.Labels["mcall.tz.io/workflow"]),
+		attribute.String("mcall.task.type", task.Spec.Type),
+		attribute.Int("mcall.task.retry", int(task.Status.RetryCount)),
+	))
+}
+
+// endTaskSpan ends the span of a task execution with its result
+func endTaskSpan(span trace.Span, task *mcallv1.McallTask, execErr error) {
+	span.SetAttributes(attribute.String("mcall.phase", string(task.Status.Phase)))
+	if task.Status.HTTPStatusCode != 0 {
+		span.SetAttributes(attribute.Int("http.status_code", task.Status.HTTPStatusCode))
+	}
+	if execErr != nil {
+		span.RecordError(execErr)
+		span.SetStatus(codes.Error, truncateString(execErr.Error(), 256))
+	}
+	span.End()
+}
+
+// startInputSpan starts the span of the execution of one input of a task
+func startInputSpan(ctx context.Context, tw *TaskWorker) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return tracer().Start(ctx, "mcall.task.input", trace.WithAttributes(
+		attribute.String("mcall.input.name", tw.name),
+		attribute.String("mcall.input.type", tw.inputType),
+		attribute.String("mcall.input", truncateString(maskOutput(tw.input, tw.secrets, tw.maskPatterns), 256)),
+	))
+}
+
+// endInputSpan ends the span of an input execution with its error
+func endInputSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, truncateString(err.Error(), 256))
+	}
+	span.End()
+}
+
+// traceHeaders returns the request headers with the trace context of ctx, so the requests of
+// get and post tasks join their trace; headers of the task take precedence
+func traceHeaders(ctx context.Context, headers map[string]string) map[string]string {
+	if ctx == nil {
+		return headers
+	}
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return headers
+	}
+	for name, value := range headers {
+		carrier[name] = value
+	}
+	return carrier
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestTracingSpans tests the spans of a workflow run, its tasks and their inputs, and the trace
+// context sent with HTTP requests
+func TestTracingSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previousPropagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	var mu sync.Mutex
+	var traceParents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		traceParents = append(traceParents, r.Header.Get("Traceparent"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	workflow := &mcallv1.McallWorkflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "tracing-test"},
+		Status:     mcallv1.McallWorkflowStatus{StartTime: &metav1.Time{Time: time.Now()}},
+	}
+	runCtx := startWorkflowRunSpan(context.Background(), workflow)
+	annotations := map[string]string{}
+	injectTraceParent(runCtx, annotations)
+	if annotations[mcallv1.TraceParentAnnotation] == "" {
+		t.Fatalf("task instance annotations = %v, want %s", annotations, mcallv1.TraceParentAnnotation)
+	}
+
+	tasks := []*mcallv1.McallTask{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "nightly-api", Namespace: "tracing-test", Annotations: annotations},
+			Spec:       mcallv1.McallTaskSpec{Type: "get", Input: server.URL},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "nightly-checks", Namespace: "tracing-test", Annotations: annotations},
+			Spec:       mcallv1.McallTaskSpec{Type: "cmd", Input: `[{"input": "echo ok"}, {"input": "` + server.URL + `", "type": "get"}]`},
+		},
+	}
+	for _, task := range tasks {
+		ctx, span := startTaskSpan(context.Background(), task)
+		_, _, err := executeTaskWithEnv(task, 5*time.Second, logr.Discard(), executionEnv{trace: ctx})
+		if err != nil {
+			t.Fatalf("task %s failed: %v", task.Name, err)
+		}
+		task.Status.Phase = mcallv1.McallTaskPhaseSucceeded
+		endTaskSpan(span, task, err)
+	}
+	workflow.Status.Phase = mcallv1.McallWorkflowPhaseSucceeded
+	workflow.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	endWorkflowRunSpan(workflow)
+
+	spans := map[string][]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = append(spans[span.Name()], span)
+	}
+	if len(spans["mcall.workflow.run"]) != 1 || len(spans["mcall.task.execute"]) != 2 || len(spans["mcall.task.input"]) != 2 {
+		t.Fatalf("ended spans = %d run, %d task, %d input, want 1, 2 and 2",
+			len(spans["mcall.workflow.run"]), len(spans["mcall.task.execute"]), len(spans["mcall.task.input"]))
+	}
+	run := spans["mcall.workflow.run"][0].SpanContext()
+	for _, span := range spans["mcall.task.execute"] {
+		if span.Parent().SpanID() != run.SpanID() || span.SpanContext().TraceID() != run.TraceID() {
+			t.Errorf("task span parent = %s, want the workflow run %s", span.Parent().SpanID(), run.SpanID())
+		}
+	}
+	checks := spans["mcall.task.execute"][1].SpanContext()
+	for _, span := range spans["mcall.task.input"] {
+		if span.Parent().SpanID() != checks.SpanID() {
+			t.Errorf("input span parent = %s, want the task %s", span.Parent().SpanID(), checks.SpanID())
+		}
+	}
+
+	// The get task sends its own span, the get input the span of the input
+	want := []string{
+		"00-" + run.TraceID().String() + "-" + spans["mcall.task.execute"][0].SpanContext().SpanID().String() + "-01",
+		"00-" + run.TraceID().String() + "-" + spans["mcall.task.input"][1].SpanContext().SpanID().String() + "-01",
+	}
+	if len(traceParents) != len(want) {
+		t.Fatalf("traceparent headers = %v, want %v", traceParents, want)
+	}
+	for i := range want {
+		if traceParents[i] != want[i] {
+			t.Errorf("traceparent header %d = %q, want %q", i, traceParents[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// vaultRequestTimeout bounds each request to Vault
+const vaultRequestTimeout = 10 * time.Second
+
+// vaultConfig holds the controller-wide Vault settings
+type vaultConfig struct {
+	Address   string
+	Role      string
+	AuthPath  string
+	TokenPath string
+	CACert    string
+}
+
+// getVaultConfig returns the Vault settings of the controller
+func getVaultConfig() vaultConfig {
+	return vaultConfig{
+		Address:   strings.TrimSuffix(os.Getenv("VAULT_ADDR"), "/"),
+		Role:      os.Getenv("VAULT_ROLE"),
+		AuthPath:  strings.Trim(getEnvOrDefault("VAULT_AUTH_PATH", "kubernetes"), "/"),
+		TokenPath: getEnvOrDefault("VAULT_SERVICE_ACCOUNT_TOKEN_PATH", "/var/run/secrets/kubernetes.io/serviceaccount/token"),
+		CACert:    os.Getenv("VAULT_CACERT"),
+	}
+}
+
+// vaultClient reads secrets from Vault with a token from the kubernetes auth method
+type vaultClient struct {
+	address string
+	token   string
+	client  *http.Client
+}
+
+// newVaultClient logs in to Vault with the controller's service account token and a role
+func newVaultClient(ctx context.Context, config vaultConfig, role string) (*vaultClient, error) {
+	if config.Address == "" {
+		return nil, fmt.Errorf("VAULT_ADDR is not set")
+	}
+	if role == "" {
+		return nil, fmt.Errorf("no Vault role: set vault.role or VAULT_ROLE")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.CACert != "" {
+		pem, err := os.ReadFile(config.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Vault CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in Vault CA certificate %s", config.CACert)
+		}
+		transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12, RootCAs: pool}
+	}
+	c := &vaultClient{address: config.Address, client: &http.Client{Timeout: vaultRequestTimeout, Transport: transport}}
+
+	jwt, err := os.ReadFile(config.TokenPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account token: %w", err)
+	}
+	body, _ := json.Marshal(map[string]string{"role": role, "jwt": strings.TrimSpace(string(jwt))})
+
+	var login struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	if _, err := c.do(ctx, http.MethodPost, "auth/"+config.AuthPath+"/login", body, &login); err != nil {
+		return nil, fmt.Errorf("Vault login with role %s failed: %w", role, err)
+	}
+	if login.Auth.ClientToken == "" {
+		return nil, fmt.Errorf("Vault login with role %s returned no token", role)
+	}
+	c.token = login.Auth.ClientToken
+	return c, nil
+}
+
+// read returns the key/value pairs of a secret, or nil when it doesn't exist.
+// Values of KV v2 secrets are under data.data, those of KV v1 and other engines under data.
+func (c *vaultClient) read(ctx context.Context, path string) (map[string]string, error) {
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	found, err := c.do(ctx, http.MethodGet, strings.TrimPrefix(path, "/"), nil, &secret)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	data := secret.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = nested
+		}
+	}
+	values := make(map[string]string, len(data))
+	for key, value := range data {
+		switch value := value.(type) {
+		case string:
+			values[key] = value
+		case nil:
+		default:
+			encoded, _ := json.Marshal(value)
+			values[key] = string(encoded)
+		}
+	}
+	return values, nil
+}
+
+// do sends a request to the Vault API and decodes its response into out.
+// It returns false for 404 responses.
+func (c *vaultClient) do(ctx context.Context, method, path string, body []byte, out interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.address+"/v1/"+path, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("X-Vault-Token", c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	payload, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return false, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(payload, &failure) == nil && len(failure.Errors) > 0 {
+			return false, fmt.Errorf("status %d: %s", resp.StatusCode, strings.Join(failure.Errors, "; "))
+		}
+		return false, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if err := json.Unmarshal(payload, out); err != nil {
+		return false, fmt.Errorf("invalid Vault response: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// waitForGVK returns the GroupVersionKind of a resource condition
+func waitForGVK(condition *mcallv1.ResourceCondition) (schema.GroupVersionKind, error) {
+	gv, err := schema.ParseGroupVersion(condition.APIVersion)
+	if err != nil {
+		return schema.GroupVersionKind{}, fmt.Errorf("invalid waitFor apiVersion %q: %w", condition.APIVersion, err)
+	}
+	return gv.WithKind(condition.Kind), nil
+}
+
+// checkResourceCondition checks whether the waitFor resource(s) of a task reached the expected state.
+// Returns whether the condition is met and a message describing the current state.
+func (r *McallTaskReconciler) checkResourceCondition(ctx context.Context, task *mcallv1.McallTask) (bool, string, error) {
+	condition := task.Spec.WaitFor
+
+	gvk, err := waitForGVK(condition)
+	if err != nil {
+		return false, "", err
+	}
+
+	namespace := condition.Namespace
+	if namespace == "" {
+		namespace = task.Namespace
+	}
+
+	expected := condition.Value
+	if expected == "" {
+		expected = "True"
+	}
+
+	var resources []unstructured.Unstructured
+	switch {
+	case condition.Name != "":
+		resource := unstructured.Unstructured{}
+		resource.SetGroupVersionKind(gvk)
+		if err := r.Get(ctx, types.NamespacedName{Name: condition.Name, Namespace: namespace}, &resource); err != nil {
+			if client.IgnoreNotFound(err) == nil {
+				return false, fmt.Sprintf("%s %s/%s not found", gvk.Kind, namespace, condition.Name), nil
+			}
+			return false, "", err
+		}
+		resources = append(resources, resource)
+	case condition.Selector != "":
+		selector, err := labels.Parse(condition.Selector)
+		if err != nil {
+			return false, "", fmt.Errorf("invalid waitFor selector %q: %w", condition.Selector, err)
+		}
+		list := unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := r.List(ctx, &list, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return false, "", err
+		}
+		if len(list.Items) == 0 {
+			return false, fmt.Sprintf("no %s matching %q in %s", gvk.Kind, condition.Selector, namespace), nil
+		}
+		resources = list.Items
+	default:
+		return false, "", fmt.Errorf("waitFor requires a name or a selector")
+	}
+
+	for _, resource := range resources {
+		value, err := evaluateResourceJSONPath(resource.Object, condition.JSONPath)
+		if err != nil {
+			return false, "", err
+		}
+		if value != expected {
+			return false, fmt.Sprintf("%s %s/%s: %s is %q, waiting for %q",
+				gvk.Kind, resource.GetNamespace(), resource.GetName(), condition.JSONPath, value, expected), nil
+		}
+	}
+
+	return true, "", nil
+}
+
+// evaluateResourceJSONPath evaluates a kubectl-style JSONPath expression on a resource
+func evaluateResourceJSONPath(object map[string]interface{}, path string) (string, error) {
+	// Accept paths without braces, like kubectl's custom-columns
+	if !strings.HasPrefix(path, "{") {
+		path = fmt.Sprintf("{%s}", path)
+	}
+
+	parser := jsonpath.New("waitFor").AllowMissingKeys(true)
+	if err := parser.Parse(path); err != nil {
+		return "", fmt.Errorf("invalid waitFor jsonPath %q: %w", path, err)
+	}
+
+	var buf bytes.Buffer
+	if err := parser.Execute(&buf, object); err != nil {
+		return "", fmt.Errorf("failed to evaluate waitFor jsonPath %q: %w", path, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// ensureResourceWatch starts watching the kind of a waitFor resource, so that tasks
+// waiting on it are reconciled as soon as the resource changes
+func (r *McallTaskReconciler) ensureResourceWatch(ctx context.Context, condition *mcallv1.ResourceCondition) {
+	log := log.FromContext(ctx)
+
+	if r.controller == nil || r.cache == nil {
+		return // Not running under a manager, rely on requeue
+	}
+
+	gvk, err := waitForGVK(condition)
+	if err != nil {
+		return
+	}
+
+	r.watchMu.Lock()
+	defer r.watchMu.Unlock()
+
+	if r.watchedKinds[gvk] {
+		return
+	}
+
+	resource := &unstructured.Unstructured{}
+	resource.SetGroupVersionKind(gvk)
+	if err := r.controller.Watch(source.Kind(r.cache, resource), handler.EnqueueRequestsFromMapFunc(r.mapResourceToWaitingTasks(gvk))); err != nil {
+		log.Error(err, "Failed to watch waitFor resource kind", "gvk", gvk.String())
+		return
+	}
+
+	if r.watchedKinds == nil {
+		r.watchedKinds = make(map[schema.GroupVersionKind]bool)
+	}
+	r.watchedKinds[gvk] = true
+	log.Info("Watching waitFor resource kind", "gvk", gvk.String())
+}
+
+// mapResourceToWaitingTasks maps a resource event to the pending tasks waiting on its kind
+func (r *McallTaskReconciler) mapResourceToWaitingTasks(gvk schema.GroupVersionKind) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var tasks mcallv1.McallTaskList
+		if err := r.List(ctx, &tasks); err != nil {
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, task := range tasks.Items {
+			condition := task.Spec.WaitFor
+			if condition == nil || task.Status.Phase != mcallv1.McallTaskPhasePending {
+				continue
+			}
+			if taskGVK, err := waitForGVK(condition); err != nil || taskGVK != gvk {
+				continue
+			}
+
+			namespace := condition.Namespace
+			if namespace == "" {
+				namespace = task.Namespace
+			}
+			if namespace != obj.GetNamespace() {
+				continue
+			}
+
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: task.Name, Namespace: task.Namespace},
+			})
+		}
+		return requests
+	}
+}
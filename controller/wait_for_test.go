@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestCheckResourceCondition tests waitFor conditions on other Kubernetes resources
+func TestCheckResourceCondition(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+
+	newDeployment := func(name string, available corev1.ConditionStatus) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "payment"}},
+			Status: appsv1.DeploymentStatus{
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentAvailable, Status: available},
+				},
+			},
+		}
+	}
+	availablePath := `{.status.conditions[?(@.type=="Available")].status}`
+
+	tests := []struct {
+		name      string
+		condition mcallv1.ResourceCondition
+		wantReady bool
+		wantErr   bool
+	}{
+		{
+			name:      "deployment available",
+			condition: mcallv1.ResourceCondition{APIVersion: "apps/v1", Kind: "Deployment", Name: "payment-api", JSONPath: availablePath},
+			wantReady: true,
+		},
+		{
+			name:      "deployment not available",
+			condition: mcallv1.ResourceCondition{APIVersion: "apps/v1", Kind: "Deployment", Name: "payment-worker", JSONPath: availablePath},
+			wantReady: false,
+		},
+		{
+			name:      "deployment not found",
+			condition: mcallv1.ResourceCondition{APIVersion: "apps/v1", Kind: "Deployment", Name: "missing", JSONPath: availablePath},
+			wantReady: false,
+		},
+		{
+			name:      "selector requires all matches",
+			condition: mcallv1.ResourceCondition{APIVersion: "apps/v1", Kind: "Deployment", Selector: "app=payment", JSONPath: availablePath},
+			wantReady: false,
+		},
+		{
+			name:      "expected value without braces",
+			condition: mcallv1.ResourceCondition{APIVersion: "apps/v1", Kind: "Deployment", Name: "payment-worker", JSONPath: ".status.conditions[0].status", Value: "False"},
+			wantReady: true,
+		},
+		{
+			name:      "missing name and selector",
+			condition: mcallv1.ResourceCondition{APIVersion: "apps/v1", Kind: "Deployment", JSONPath: availablePath},
+			wantErr:   true,
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(newDeployment("payment-api", corev1.ConditionTrue), newDeployment("payment-worker", corev1.ConditionFalse)).
+		Build()
+	r := &McallTaskReconciler{Client: fakeClient, Scheme: scheme}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition := tt.condition
+			task := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{Name: "smoke-test", Namespace: "default"},
+				Spec:       mcallv1.McallTaskSpec{Type: "get", WaitFor: &condition},
+			}
+
+			ready, message, err := r.checkResourceCondition(context.Background(), task)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkResourceCondition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ready != tt.wantReady {
+				t.Errorf("checkResourceCondition() = %v (%s), want %v", ready, message, tt.wantReady)
+			}
+		})
+	}
+}
+
+// TestWaitForTimeout tests timing out waitFor conditions from the start of the wait, not the task creation
+func TestWaitForTimeout(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	key := types.NamespacedName{Name: "smoke-test", Namespace: "default"}
+	task := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))},
+		Spec: mcallv1.McallTaskSpec{
+			Type:  "cmd",
+			Input: "echo ok",
+			WaitFor: &mcallv1.ResourceCondition{
+				APIVersion: "apps/v1", Kind: "Deployment", Name: "payment-api",
+				JSONPath: `{.status.conditions[?(@.type=="Available")].status}`, TimeoutSeconds: 60,
+			},
+		},
+		Status: mcallv1.McallTaskStatus{Phase: mcallv1.McallTaskPhasePending},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&mcallv1.McallTask{}).WithObjects(task).Build()
+	r := &McallTaskReconciler{Client: fakeClient, Scheme: scheme}
+
+	get := func() *mcallv1.McallTask {
+		var latest mcallv1.McallTask
+		if err := fakeClient.Get(ctx, key, &latest); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		return &latest
+	}
+
+	// A task created an hour ago starts its wait now
+	if _, err := r.handlePending(ctx, get()); err != nil {
+		t.Fatalf("handlePending() error = %v", err)
+	}
+	latest := get()
+	if latest.Status.Phase != mcallv1.McallTaskPhasePending || latest.Status.WaitStartTime == nil {
+		t.Fatalf("status = %+v, want Pending with the wait start time", latest.Status)
+	}
+
+	// The wait ends when the deployment becomes available and the run starts
+	if err := fakeClient.Create(ctx, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "payment-api", Namespace: "default"},
+		Status:     appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}}},
+	}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := r.handlePending(ctx, get()); err != nil {
+		t.Fatalf("handlePending() error = %v", err)
+	}
+	if latest := get(); latest.Status.Phase != mcallv1.McallTaskPhaseRunning || latest.Status.WaitStartTime != nil {
+		t.Fatalf("status = %+v, want Running without wait start time", latest.Status)
+	}
+
+	// A wait longer than the timeout fails the task
+	latest = get()
+	latest.Spec.WaitFor.Name = "missing"
+	if err := fakeClient.Update(ctx, latest); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	latest = get()
+	latest.Status.Phase = mcallv1.McallTaskPhasePending
+	latest.Status.WaitStartTime = &metav1.Time{Time: time.Now().Add(-2 * time.Minute)}
+	if err := fakeClient.Status().Update(ctx, latest); err != nil {
+		t.Fatalf("Status().Update() error = %v", err)
+	}
+	if _, err := r.handlePending(ctx, get()); err != nil {
+		t.Fatalf("handlePending() error = %v", err)
+	}
+	latest = get()
+	if latest.Status.Phase != mcallv1.McallTaskPhaseFailed || latest.Status.WaitStartTime != nil ||
+		latest.Status.Result == nil || !strings.Contains(latest.Status.Result.ErrorMessage, "waitFor timed out after 1m0s") {
+		t.Errorf("status = %+v, want Failed with the waitFor timeout", latest.Status)
+	}
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// warmUpGenerationAnnotation records the template generation a warm-up copy was created for
+const warmUpGenerationAnnotation = "mcall.tz.io/warmup-generation"
+
+// warmUpTaskName returns the name of the warm-up copy of a template task
+func warmUpTaskName(task *mcallv1.McallTask) string {
+	return fmt.Sprintf("%s-%s-warmup", task.Namespace, task.Name)
+}
+
+// isTaskValidated reports whether a template may be used: it has no warm-up,
+// or the warm-up of its current generation passed
+func isTaskValidated(task *mcallv1.McallTask) bool {
+	if task.Spec.WarmUp == nil {
+		return true
+	}
+	condition := meta.FindStatusCondition(task.Status.Conditions, mcallv1.ValidatedCondition)
+	return condition != nil && condition.ObservedGeneration == task.Generation && condition.Status == metav1.ConditionTrue
+}
+
+// reconcileWarmUp runs the warm-up of a template task and records the Validated condition.
+// It returns true once the current generation is validated and the task may execute.
+func (r *McallTaskReconciler) reconcileWarmUp(ctx context.Context, task *mcallv1.McallTask) (bool, ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	condition := meta.FindStatusCondition(task.Status.Conditions, mcallv1.ValidatedCondition)
+	if condition != nil && condition.ObservedGeneration == task.Generation && condition.Status != metav1.ConditionUnknown {
+		// A failed warm-up holds the template until its spec changes
+		return condition.Status == metav1.ConditionTrue, ctrl.Result{}, nil
+	}
+
+	key := types.NamespacedName{Name: warmUpTaskName(task), Namespace: task.Spec.WarmUp.Namespace}
+	generation := strconv.FormatInt(task.Generation, 10)
+
+	var warmUp mcallv1.McallTask
+	if err := r.Get(ctx, key, &warmUp); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, ctrl.Result{}, err
+		}
+
+		log.Info("Starting template warm-up", "task", task.Name, "namespace", key.Namespace, "generation", generation)
+		if err := r.Create(ctx, newWarmUpTask(task, key)); err != nil {
+			return false, ctrl.Result{}, err
+		}
+		if err := r.setValidatedCondition(ctx, task, metav1.ConditionUnknown, "WarmUpRunning",
+			fmt.Sprintf("Warm-up running as %s/%s", key.Namespace, key.Name)); err != nil {
+			return false, ctrl.Result{}, err
+		}
+		return false, ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
+	// The template changed while a warm-up was running, start over with the new spec
+	if warmUp.Annotations[warmUpGenerationAnnotation] != generation {
+		if err := r.Delete(ctx, &warmUp); err != nil && !apierrors.IsNotFound(err) {
+			return false, ctrl.Result{}, err
+		}
+		return false, ctrl.Result{RequeueAfter: 1 * time.Second}, nil
+	}
+
+	var status metav1.ConditionStatus
+	var reason, message string
+	switch warmUp.Status.Phase {
+	case mcallv1.McallTaskPhaseSucceeded:
+		status, reason, message = metav1.ConditionTrue, "WarmUpSucceeded", "Warm-up run succeeded"
+	case mcallv1.McallTaskPhaseFailed, mcallv1.McallTaskPhaseSkipped, mcallv1.McallTaskPhaseBlocked:
+		status, reason = metav1.ConditionFalse, "WarmUpFailed"
+		message = fmt.Sprintf("Warm-up run %s", warmUp.Status.Phase)
+		if warmUp.Status.Result != nil && warmUp.Status.Result.ErrorMessage != "" {
+			message = fmt.Sprintf("%s: %s", message, warmUp.Status.Result.ErrorMessage)
+		}
+	default:
+		return false, ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
+	log.Info("Template warm-up finished", "task", task.Name, "validated", status, "message", message)
+	if err := r.setValidatedCondition(ctx, task, status, reason, message); err != nil {
+		return false, ctrl.Result{}, err
+	}
+	if err := r.Delete(ctx, &warmUp); err != nil && !apierrors.IsNotFound(err) {
+		log.Error(err, "Failed to delete warm-up task", "task", task.Name, "warmUp", key)
+	}
+
+	return status == metav1.ConditionTrue, ctrl.Result{}, nil
+}
+
+// newWarmUpTask builds the sandbox copy of a template task with the warm-up overrides applied
+func newWarmUpTask(task *mcallv1.McallTask, key types.NamespacedName) *mcallv1.McallTask {
+	warmUp := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+			Labels: map[string]string{
+				"mcall.tz.io/warmup-of": task.Name,
+			},
+			Annotations: map[string]string{
+				warmUpGenerationAnnotation: strconv.FormatInt(task.Generation, 10),
+			},
+		},
+		Spec: *task.Spec.DeepCopy(),
+	}
+
+	overrides := task.Spec.WarmUp
+	warmUp.Spec.WarmUp = nil
+	warmUp.Spec.Schedule = "" // Run once
+	if overrides.Input != "" {
+		warmUp.Spec.Input = overrides.Input
+	}
+	if len(overrides.Environment) > 0 {
+		if warmUp.Spec.Environment == nil {
+			warmUp.Spec.Environment = make(map[string]string)
+		}
+		for name, value := range overrides.Environment {
+			warmUp.Spec.Environment[name] = value
+		}
+	}
+
+	return warmUp
+}
+
+// setValidatedCondition records the warm-up result of the current template generation
+func (r *McallTaskReconciler) setValidatedCondition(ctx context.Context, task *mcallv1.McallTask, status metav1.ConditionStatus, reason, message string) error {
+	meta.SetStatusCondition(&task.Status.Conditions, metav1.Condition{
+		Type:               mcallv1.ValidatedCondition,
+		Status:             status,
+		ObservedGeneration: task.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	return r.Status().Update(ctx, task)
+}
+
+// checkTemplatesValidated returns an error naming the first workflow template whose warm-up hasn't passed
+func (r *McallWorkflowReconciler) checkTemplatesValidated(ctx context.Context, workflow *mcallv1.McallWorkflow) error {
+	for _, taskSpec := range withFailureHandlers(allWorkflowTasks(&workflow.Spec)) {
+		namespace := taskSpec.TaskRef.Namespace
+		if namespace == "" {
+			namespace = workflow.Namespace
+		}
+
+		var template mcallv1.McallTask
+		if err := r.Get(ctx, types.NamespacedName{Name: taskSpec.TaskRef.Name, Namespace: namespace}, &template); err != nil {
+			continue // Missing templates are reported when the tasks are created
+		}
+		if !isTaskValidated(&template) {
+			return fmt.Errorf("task %s: template %s/%s has not passed its warm-up", taskSpec.Name, namespace, template.Name)
+		}
+	}
+	return nil
+}
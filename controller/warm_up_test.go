@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestReconcileWarmUp tests validating a template with a warm-up run in the sandbox namespace
+func TestReconcileWarmUp(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+
+	tests := []struct {
+		name          string
+		warmUpPhase   mcallv1.McallTaskPhase
+		wantStatus    metav1.ConditionStatus
+		wantValidated bool
+	}{
+		{name: "warm-up succeeded", warmUpPhase: mcallv1.McallTaskPhaseSucceeded, wantStatus: metav1.ConditionTrue, wantValidated: true},
+		{name: "warm-up failed", warmUpPhase: mcallv1.McallTaskPhaseFailed, wantStatus: metav1.ConditionFalse, wantValidated: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			template := &mcallv1.McallTask{
+				ObjectMeta: metav1.ObjectMeta{Name: "health-api", Namespace: "prod", Generation: 2},
+				Spec: mcallv1.McallTaskSpec{
+					Type:     "get",
+					Input:    "https://api.example.com/health",
+					Schedule: "*/5 * * * *",
+					WarmUp: &mcallv1.WarmUp{
+						Namespace:   "sandbox",
+						Input:       "https://staging.example.com/health",
+						Environment: map[string]string{"TARGET": "staging"},
+					},
+				},
+				Status: mcallv1.McallTaskStatus{Phase: mcallv1.McallTaskPhasePending},
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&mcallv1.McallTask{}).
+				WithObjects(template).
+				Build()
+			r := &McallTaskReconciler{Client: fakeClient, Scheme: scheme}
+
+			// First pass starts the warm-up copy with the overrides applied
+			validated, _, err := r.reconcileWarmUp(ctx, template)
+			if err != nil || validated {
+				t.Fatalf("reconcileWarmUp() = %v, %v, want not validated yet", validated, err)
+			}
+			if isTaskValidated(template) {
+				t.Errorf("isTaskValidated() = true while the warm-up is running")
+			}
+
+			key := types.NamespacedName{Name: "prod-health-api-warmup", Namespace: "sandbox"}
+			var warmUp mcallv1.McallTask
+			if err := fakeClient.Get(ctx, key, &warmUp); err != nil {
+				t.Fatalf("warm-up task not created: %v", err)
+			}
+			if warmUp.Spec.Input != "https://staging.example.com/health" || warmUp.Spec.Environment["TARGET"] != "staging" {
+				t.Errorf("warm-up overrides not applied: %+v", warmUp.Spec)
+			}
+			if warmUp.Spec.WarmUp != nil || warmUp.Spec.Schedule != "" {
+				t.Errorf("warm-up copy should run once without its own warm-up: %+v", warmUp.Spec)
+			}
+
+			// Second pass records the result of the warm-up run
+			warmUp.Status.Phase = tt.warmUpPhase
+			if err := fakeClient.Status().Update(ctx, &warmUp); err != nil {
+				t.Fatalf("Status().Update() error = %v", err)
+			}
+			validated, _, err = r.reconcileWarmUp(ctx, template)
+			if err != nil || validated != tt.wantValidated {
+				t.Fatalf("reconcileWarmUp() = %v, %v, want %v", validated, err, tt.wantValidated)
+			}
+
+			condition := meta.FindStatusCondition(template.Status.Conditions, mcallv1.ValidatedCondition)
+			if condition == nil || condition.Status != tt.wantStatus || condition.ObservedGeneration != 2 {
+				t.Errorf("Validated condition = %+v, want %s for generation 2", condition, tt.wantStatus)
+			}
+			if isTaskValidated(template) != tt.wantValidated {
+				t.Errorf("isTaskValidated() = %v, want %v", !tt.wantValidated, tt.wantValidated)
+			}
+			if err := fakeClient.Get(ctx, key, &warmUp); !apierrors.IsNotFound(err) {
+				t.Errorf("warm-up task not cleaned up: %v", err)
+			}
+
+			// A spec change invalidates the result
+			template.Generation = 3
+			if isTaskValidated(template) {
+				t.Errorf("isTaskValidated() = true for a changed spec")
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// executeWebSocketCheck opens a websocket to the ws:// or wss:// URL, sends the task's
+// message and returns the first message received, or a connection message when the
+// task only checks the upgrade
+func executeWebSocketCheck(target string, check *mcallv1.WebSocketCheck, headers map[string]string, timeout time.Duration, tlsConfig *tls.Config) (string, error) {
+	if check == nil {
+		check = &mcallv1.WebSocketCheck{}
+	}
+	config, err := webSocketConfig(target, check, headers, timeout, tlsConfig)
+	if err != nil {
+		return "", err
+	}
+
+	ws, err := websocket.DialConfig(config)
+	if err != nil {
+		return "", fmt.Errorf("websocket upgrade failed: %w", err)
+	}
+	defer ws.Close()
+	ws.SetDeadline(time.Now().Add(timeout))
+
+	if check.Message != "" {
+		if err := websocket.Message.Send(ws, check.Message); err != nil {
+			return "", fmt.Errorf("failed to send websocket message: %w", err)
+		}
+	}
+	if check.Message == "" && !check.ReadResponse {
+		return fmt.Sprintf("Connected to %s", target), nil
+	}
+
+	var response string
+	if err := websocket.Message.Receive(ws, &response); err != nil {
+		if err == io.EOF {
+			return "", fmt.Errorf("websocket closed before a response was received")
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return "", fmt.Errorf("no websocket response within %s", timeout)
+		}
+		return "", fmt.Errorf("failed to read websocket response: %w", err)
+	}
+	return response, nil
+}
+
+// webSocketConfig builds the upgrade request; the origin is the target's http(s) URL
+// unless an Origin header is given
+func webSocketConfig(target string, check *mcallv1.WebSocketCheck, headers map[string]string, timeout time.Duration, tlsConfig *tls.Config) (*websocket.Config, error) {
+	location, err := url.Parse(target)
+	if err != nil || location.Host == "" {
+		return nil, fmt.Errorf("invalid websocket URL %q", target)
+	}
+	origin := *location
+	switch location.Scheme {
+	case "ws":
+		origin.Scheme = "http"
+	case "wss":
+		origin.Scheme = "https"
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q, use ws:// or wss://", location.Scheme)
+	}
+	origin.Path, origin.RawQuery = "", ""
+
+	config, err := websocket.NewConfig(location.String(), origin.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid websocket URL %q: %w", target, err)
+	}
+	config.Dialer = &net.Dialer{Timeout: timeout}
+	config.TlsConfig = tlsConfig
+	if check.Subprotocol != "" {
+		config.Protocol = []string{check.Subprotocol}
+	}
+	for name, value := range headers {
+		// The handshake sends config.Origin, an Origin header would be sent twice
+		if !strings.EqualFold(name, "Origin") {
+			config.Header.Set(name, value)
+		} else if config.Origin, err = url.Parse(value); err != nil {
+			return nil, fmt.Errorf("invalid Origin header %q: %w", value, err)
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// newWebSocketHandler serves /echo, /greet (sends a greeting), /silent (never replies),
+// /close (closes at once) and /private (requires a bearer token); anything else rejects
+// the upgrade
+func newWebSocketHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/echo", websocket.Handler(func(ws *websocket.Conn) {
+		var message string
+		if websocket.Message.Receive(ws, &message) == nil {
+			websocket.Message.Send(ws, "echo: "+message)
+		}
+	}))
+	mux.Handle("/greet", websocket.Server{
+		// The server agrees to the requested subprotocol
+		Handshake: func(config *websocket.Config, r *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			websocket.Message.Send(ws, fmt.Sprintf(`{"type":"hello","protocol":%q}`, strings.Join(ws.Config().Protocol, ",")))
+		},
+	})
+	mux.Handle("/silent", websocket.Handler(func(ws *websocket.Conn) {
+		io.Copy(io.Discard, ws)
+	}))
+	mux.Handle("/close", websocket.Handler(func(ws *websocket.Conn) {}))
+	mux.Handle("/private", websocket.Server{
+		Handshake: func(config *websocket.Config, r *http.Request) error {
+			if r.Header.Get("Authorization") != "Bearer token" {
+				return fmt.Errorf("unauthorized")
+			}
+			return nil
+		},
+		Handler: func(ws *websocket.Conn) {
+			websocket.Message.Send(ws, "welcome")
+		},
+	})
+	return mux
+}
+
+// TestExecuteWebSocketCheck tests upgrading, sending a message and reading the first response
+func TestExecuteWebSocketCheck(t *testing.T) {
+	server := httptest.NewServer(newWebSocketHandler())
+	defer server.Close()
+	tlsServer := httptest.NewTLSServer(newWebSocketHandler())
+	defer tlsServer.Close()
+
+	ws := "ws" + strings.TrimPrefix(server.URL, "http")
+	wss := "wss" + strings.TrimPrefix(tlsServer.URL, "https")
+	tlsConfig := &tls.Config{RootCAs: tlsServer.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs}
+
+	tests := []struct {
+		name       string
+		url        string
+		check      *mcallv1.WebSocketCheck
+		headers    map[string]string
+		timeout    time.Duration
+		wantOutput string
+		wantErr    string
+	}{
+		{name: "upgrade", url: ws + "/silent", wantOutput: "Connected to " + ws + "/silent"},
+		{name: "echo", url: ws + "/echo", check: &mcallv1.WebSocketCheck{Message: "ping"}, wantOutput: "echo: ping"},
+		{name: "first message", url: ws + "/greet", check: &mcallv1.WebSocketCheck{ReadResponse: true, Subprotocol: "chat"}, wantOutput: `{"type":"hello","protocol":"chat"}`},
+		{name: "no response", url: ws + "/silent", check: &mcallv1.WebSocketCheck{Message: "ping"}, timeout: 300 * time.Millisecond, wantErr: "no websocket response within 300ms"},
+		{name: "closed without response", url: ws + "/close", check: &mcallv1.WebSocketCheck{ReadResponse: true}, wantErr: "closed before a response"},
+		{name: "authorized", url: ws + "/private", check: &mcallv1.WebSocketCheck{ReadResponse: true}, headers: map[string]string{"Authorization": "Bearer token", "Origin": "https://app.example.com"}, wantOutput: "welcome"},
+		{name: "unauthorized", url: ws + "/private", wantErr: "websocket upgrade failed"},
+		{name: "not a websocket", url: ws + "/missing", wantErr: "websocket upgrade failed"},
+		{name: "wss", url: wss + "/echo", check: &mcallv1.WebSocketCheck{Message: "secure"}, wantOutput: "echo: secure"},
+		{name: "http scheme", url: server.URL + "/echo", wantErr: "use ws:// or wss://"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = 5 * time.Second
+			}
+			output, err := executeWebSocketCheck(tt.url, tt.check, tt.headers, timeout, tlsConfig)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("executeWebSocketCheck() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("executeWebSocketCheck() error = %v", err)
+			}
+			if output != tt.wantOutput {
+				t.Errorf("output = %q, want %q", output, tt.wantOutput)
+			}
+		})
+	}
+}
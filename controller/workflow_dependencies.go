@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// invalidDependenciesReason is the status reason of workflows with dependency cycles or
+// references to undefined tasks
+const invalidDependenciesReason = "InvalidDependencies"
+
+// validateWorkflowDependencies rejects duplicate task names, references to tasks not defined in
+// the workflow and dependency cycles, which would keep a run from ever completing. Conditions
+// and input sources wait for their task like dependencies, so they count as dependencies. Exit
+// tasks may reference the tasks of the workflow, but tasks can't reference exit tasks, which
+// only start once they all completed. The onFailure handlers of tasks are checked as tasks.
+func validateWorkflowDependencies(tasks, onExit []mcallv1.WorkflowTaskRef) error {
+	tasks, onExit = withFailureHandlers(tasks), withFailureHandlers(onExit)
+	defined := make(map[string]bool, len(tasks)+len(onExit))
+	exit := make(map[string]bool, len(onExit))
+	all := append(append([]mcallv1.WorkflowTaskRef{}, tasks...), onExit...)
+	for i, task := range all {
+		if defined[task.Name] {
+			return fmt.Errorf("task %s is defined more than once", task.Name)
+		}
+		defined[task.Name] = true
+		exit[task.Name] = i >= len(tasks)
+	}
+
+	var undefined []string
+	for _, task := range all {
+		isDefined := func(name string) bool {
+			return defined[name] && (exit[task.Name] || !exit[name])
+		}
+		for _, dep := range task.Dependencies {
+			if !isDefined(dep) {
+				undefined = append(undefined, fmt.Sprintf("task %s depends on undefined task %s", task.Name, dep))
+			}
+		}
+		for _, condition := range taskConditions(task) {
+			if !isDefined(condition.DependentTask) {
+				undefined = append(undefined, fmt.Sprintf("condition of task %s refers to undefined task %s", task.Name, condition.DependentTask))
+			}
+		}
+		for _, source := range task.InputSources {
+			if exit[task.Name] && workflowOutcomeSource(source) {
+				continue
+			}
+			if !externalInputSource(source) && !isDefined(source.TaskRef) {
+				undefined = append(undefined, fmt.Sprintf("input source %s of task %s refers to undefined task %s", source.Name, task.Name, source.TaskRef))
+			}
+		}
+		if task.WithParam != nil && !isDefined(task.WithParam.TaskRef) {
+			undefined = append(undefined, fmt.Sprintf("withParam of task %s refers to undefined task %s", task.Name, task.WithParam.TaskRef))
+		}
+	}
+	if len(undefined) > 0 {
+		return errors.New(strings.Join(undefined, "; "))
+	}
+
+	if cycle := findDependencyCycle(all); cycle != nil {
+		return fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+	return nil
+}
+
+// taskConditions returns the condition and the allOf and anyOf conditions of a workflow task
+func taskConditions(task mcallv1.WorkflowTaskRef) []mcallv1.TaskCondition {
+	var conditions []mcallv1.TaskCondition
+	if task.Condition != nil {
+		conditions = append(conditions, *task.Condition)
+	}
+	if task.Conditions != nil {
+		conditions = append(conditions, task.Conditions.AllOf...)
+		conditions = append(conditions, task.Conditions.AnyOf...)
+	}
+	return conditions
+}
+
+// taskDependencies returns the tasks a workflow task waits for: its dependencies, the dependent
+// tasks of its conditions, the workflow tasks of its input sources and its withParam task
+func taskDependencies(task mcallv1.WorkflowTaskRef) []string {
+	dependencies := append([]string{}, task.Dependencies...)
+	for _, condition := range taskConditions(task) {
+		dependencies = append(dependencies, condition.DependentTask)
+	}
+	for _, source := range task.InputSources {
+		if !externalInputSource(source) {
+			dependencies = append(dependencies, source.TaskRef)
+		}
+	}
+	if task.WithParam != nil {
+		dependencies = append(dependencies, task.WithParam.TaskRef)
+	}
+	return dependencies
+}
+
+// externalInputSource reports whether an input source references a McallTask outside the
+// workflow, by its raw name or in another namespace
+func externalInputSource(source mcallv1.TaskInputSource) bool {
+	return source.Raw || source.Namespace != ""
+}
+
+// allWorkflowTasks returns the tasks and the exit tasks of a workflow
+func allWorkflowTasks(spec *mcallv1.McallWorkflowSpec) []mcallv1.WorkflowTaskRef {
+	return append(append([]mcallv1.WorkflowTaskRef{}, spec.Tasks...), spec.OnExit...)
+}
+
+// failureHandlerLabel labels the onFailure handler instances with the name of their task
+const failureHandlerLabel = "mcall.tz.io/on-failure-of"
+
+// withFailureHandlers returns workflow tasks followed by the onFailure handlers of the tasks that
+// declare one: a task "<name>-on-failure" run on the failure of the task
+func withFailureHandlers(tasks []mcallv1.WorkflowTaskRef) []mcallv1.WorkflowTaskRef {
+	all := append([]mcallv1.WorkflowTaskRef{}, tasks...)
+	for _, task := range tasks {
+		if task.OnFailure == nil {
+			continue
+		}
+		all = append(all, mcallv1.WorkflowTaskRef{
+			Name:      task.Name + "-on-failure",
+			TaskRef:   *task.OnFailure,
+			Condition: &mcallv1.TaskCondition{DependentTask: task.Name, When: "failure"},
+		})
+	}
+	return all
+}
+
+// workflowOutcomeSource reports whether an input source of an exit task receives the outcome of
+// the run
+func workflowOutcomeSource(source mcallv1.TaskInputSource) bool {
+	return source.TaskRef == mcallv1.WorkflowOutcomeTaskRef && !externalInputSource(source)
+}
+
+// findDependencyCycle returns the first dependency cycle of the tasks, e.g. [a b a], or nil
+func findDependencyCycle(tasks []mcallv1.WorkflowTaskRef) []string {
+	dependencies := make(map[string][]string, len(tasks))
+	for _, task := range tasks {
+		dependencies[task.Name] = taskDependencies(task)
+	}
+
+	visited := make(map[string]bool, len(tasks))
+	var path []string
+	onPath := make(map[string]int)
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		if start, ok := onPath[name]; ok {
+			return append(append([]string{}, path[start:]...), name)
+		}
+		if visited[name] {
+			return nil
+		}
+		visited[name] = true
+		onPath[name] = len(path)
+		path = append(path, name)
+		for _, dep := range dependencies[name] {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		delete(onPath, name)
+		return nil
+	}
+
+	for _, task := range tasks {
+		if cycle := visit(task.Name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
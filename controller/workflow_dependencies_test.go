@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestValidateWorkflowDependencies tests rejecting dependency cycles and undefined tasks
+func TestValidateWorkflowDependencies(t *testing.T) {
+	tests := []struct {
+		name    string
+		tasks   []mcallv1.WorkflowTaskRef
+		onExit  []mcallv1.WorkflowTaskRef
+		wantErr string
+	}{
+		{
+			name: "valid dependencies",
+			tasks: []mcallv1.WorkflowTaskRef{
+				{Name: "a"},
+				{Name: "b", Dependencies: []string{"a"}},
+				{Name: "c", Dependencies: []string{"a", "b"}, Condition: &mcallv1.TaskCondition{DependentTask: "b", When: "success"}},
+			},
+		},
+		{
+			name:    "self dependency",
+			tasks:   []mcallv1.WorkflowTaskRef{{Name: "a", Dependencies: []string{"a"}}},
+			wantErr: "dependency cycle: a -> a",
+		},
+		{
+			name: "cycle",
+			tasks: []mcallv1.WorkflowTaskRef{
+				{Name: "a"},
+				{Name: "b", Dependencies: []string{"a", "d"}},
+				{Name: "c", Dependencies: []string{"b"}},
+				{Name: "d", Dependencies: []string{"c"}},
+			},
+			wantErr: "dependency cycle: b -> d -> c -> b",
+		},
+		{
+			name: "cycle through an input source",
+			tasks: []mcallv1.WorkflowTaskRef{
+				{Name: "a", InputSources: []mcallv1.TaskInputSource{{Name: "B", TaskRef: "b", Field: "output"}}},
+				{Name: "b", Dependencies: []string{"a"}},
+			},
+			wantErr: "dependency cycle: a -> b -> a",
+		},
+		{
+			name: "undefined tasks",
+			tasks: []mcallv1.WorkflowTaskRef{
+				{Name: "a", Dependencies: []string{"missing"}},
+				{Name: "b", Condition: &mcallv1.TaskCondition{DependentTask: "gone", When: "success"}},
+			},
+			wantErr: "task a depends on undefined task missing; condition of task b refers to undefined task gone",
+		},
+		{
+			name: "undefined withParam task",
+			tasks: []mcallv1.WorkflowTaskRef{
+				{Name: "a", WithParam: &mcallv1.TaskParamSource{TaskRef: "hosts"}},
+			},
+			wantErr: "withParam of task a refers to undefined task hosts",
+		},
+		{
+			name: "cycle through withParam",
+			tasks: []mcallv1.WorkflowTaskRef{
+				{Name: "a", WithParam: &mcallv1.TaskParamSource{TaskRef: "b"}},
+				{Name: "b", Dependencies: []string{"a"}},
+			},
+			wantErr: "dependency cycle: a -> b -> a",
+		},
+		{
+			name: "cycle through allOf conditions",
+			tasks: []mcallv1.WorkflowTaskRef{
+				{Name: "a", Conditions: &mcallv1.TaskConditions{AllOf: []mcallv1.TaskCondition{{DependentTask: "b", When: "success"}}}},
+				{Name: "b", Dependencies: []string{"a"}},
+			},
+			wantErr: "dependency cycle: a -> b -> a",
+		},
+		{
+			name: "undefined anyOf task",
+			tasks: []mcallv1.WorkflowTaskRef{
+				{Name: "a"},
+				{Name: "b", Conditions: &mcallv1.TaskConditions{AnyOf: []mcallv1.TaskCondition{{DependentTask: "a", When: "success"}, {DependentTask: "x", When: "failure"}}}},
+			},
+			wantErr: "condition of task b refers to undefined task x",
+		},
+		{
+			name: "input sources outside the workflow",
+			tasks: []mcallv1.WorkflowTaskRef{
+				{Name: "a", InputSources: []mcallv1.TaskInputSource{
+					{Name: "X", TaskRef: "other-workflow-a", Raw: true, Field: "output"},
+					{Name: "Y", TaskRef: "inventory", Namespace: "shared", Field: "output"},
+				}},
+			},
+		},
+		{
+			name:    "duplicate task names",
+			tasks:   []mcallv1.WorkflowTaskRef{{Name: "a"}, {Name: "a"}},
+			wantErr: "task a is defined more than once",
+		},
+		{
+			name:  "exit tasks",
+			tasks: []mcallv1.WorkflowTaskRef{{Name: "a"}},
+			onExit: []mcallv1.WorkflowTaskRef{
+				{Name: "notify", InputSources: []mcallv1.TaskInputSource{
+					{Name: "OUTCOME", TaskRef: mcallv1.WorkflowOutcomeTaskRef, Field: "phase"},
+					{Name: "A", TaskRef: "a", Field: "output"},
+				}},
+				{Name: "cleanup", Dependencies: []string{"notify"}},
+			},
+		},
+		{
+			name:    "task depending on an exit task",
+			tasks:   []mcallv1.WorkflowTaskRef{{Name: "a", Dependencies: []string{"cleanup"}}},
+			onExit:  []mcallv1.WorkflowTaskRef{{Name: "cleanup"}},
+			wantErr: "task a depends on undefined task cleanup",
+		},
+		{
+			name:    "workflow outcome outside exit tasks",
+			tasks:   []mcallv1.WorkflowTaskRef{{Name: "a", InputSources: []mcallv1.TaskInputSource{{Name: "OUTCOME", TaskRef: mcallv1.WorkflowOutcomeTaskRef, Field: "phase"}}}},
+			wantErr: "input source OUTCOME of task a refers to undefined task workflow",
+		},
+		{
+			name: "task named like the onFailure handler of a task",
+			tasks: []mcallv1.WorkflowTaskRef{
+				{Name: "a", OnFailure: &mcallv1.TaskRef{Name: "diagnose"}},
+				{Name: "a-on-failure"},
+			},
+			wantErr: "task a-on-failure is defined more than once",
+		},
+		{
+			name:    "exit task named like a task",
+			tasks:   []mcallv1.WorkflowTaskRef{{Name: "a"}},
+			onExit:  []mcallv1.WorkflowTaskRef{{Name: "a"}},
+			wantErr: "task a is defined more than once",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWorkflowDependencies(tt.tasks, tt.onExit)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateWorkflowDependencies() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateWorkflowDependencies() error = %v, want %q", err, tt.wantErr)
+			}
+
+			// The webhook rejects the same workflows at admission
+			workflow := &mcallv1.McallWorkflow{
+				ObjectMeta: metav1.ObjectMeta{Name: "checks", Namespace: "default"},
+				Spec:       mcallv1.McallWorkflowSpec{Tasks: tt.tasks, OnExit: tt.onExit},
+			}
+			if _, err := (&McallWorkflowValidator{}).ValidateCreate(context.Background(), workflow); err == nil {
+				t.Errorf("ValidateCreate() error = nil, want %q", tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// exitTaskLabel labels the exit task instances of a workflow run
+const exitTaskLabel = "mcall.tz.io/on-exit"
+
+// workflowOutcome returns the outcome exit tasks receive from the completed tasks of a run: its
+// phase, the failed tasks in the error message and the JSON results of the tasks as output
+func workflowOutcome(workflow *mcallv1.McallWorkflow, tasks []mcallv1.McallTask, hasFailed bool) (*mcallv1.McallTaskStatus, error) {
+	results := buildWorkflowRunTaskResults(tasks)
+	output, err := json.Marshal(results)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := &mcallv1.McallTaskStatus{
+		Phase:          mcallv1.McallTaskPhaseSucceeded,
+		StartTime:      workflow.Status.StartTime,
+		CompletionTime: &metav1.Time{Time: time.Now()},
+		Result:         &mcallv1.McallTaskResult{ErrorCode: "0", Output: string(output)},
+	}
+	if hasFailed {
+		var failed []string
+		for _, result := range results {
+			if (result.Phase == mcallv1.McallTaskPhaseFailed || result.Phase == mcallv1.McallTaskPhaseBlocked) && !result.Tolerated {
+				failed = append(failed, result.Name)
+			}
+		}
+		outcome.Phase = mcallv1.McallTaskPhaseFailed
+		outcome.Result.ErrorCode = "-1"
+		outcome.Result.ErrorMessage = fmt.Sprintf("failed tasks: %s", strings.Join(failed, ", "))
+	}
+	return outcome, nil
+}
+
+// startExitTasks creates the exit task instances of a run once all its tasks completed
+func (r *McallWorkflowReconciler) startExitTasks(ctx context.Context, workflow *mcallv1.McallWorkflow, hasFailed bool) error {
+	log := log.FromContext(ctx)
+
+	var tasks mcallv1.McallTaskList
+	if err := r.List(ctx, &tasks, client.InNamespace(workflow.Namespace), client.MatchingLabels{"mcall.tz.io/workflow": workflow.Name}); err != nil {
+		return err
+	}
+	outcome, err := workflowOutcome(workflow, tasks.Items, hasFailed)
+	if err != nil {
+		return err
+	}
+
+	if err := r.createWorkflowTasks(ctx, workflow, workflow.Spec.OnExit, outcome); err != nil {
+		return err
+	}
+
+	workflow.Status.OnExitStartTime = &metav1.Time{Time: time.Now()}
+	setWorkflowConditions(workflow)
+	if err := r.Status().Update(ctx, workflow); err != nil {
+		return err
+	}
+	log.Info("Started exit tasks", "workflow", workflow.Name, "outcome", outcome.Phase, "exitTasks", len(workflow.Spec.OnExit))
+	recordEvent(r.Recorder, workflow, corev1.EventTypeNormal, EventReasonStarted, "Started %d exit task(s) after the tasks %s", len(workflow.Spec.OnExit), strings.ToLower(string(outcome.Phase)))
+	return nil
+}
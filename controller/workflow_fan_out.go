@@ -0,0 +1,280 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// fanOutOfLabel labels the instances of an expanded task with the name of the task
+const fanOutOfLabel = "mcall.tz.io/fan-out-of"
+
+// taskFanOut returns the fan-out of a task, or nil for tasks that are not expanded
+func taskFanOut(task *mcallv1.McallTask) (*mcallv1.FanOut, error) {
+	fanOutStr, exists := task.Annotations[mcallv1.FanOutAnnotation]
+	if !exists || fanOutStr == "" {
+		return nil, nil
+	}
+	var fanOut mcallv1.FanOut
+	if err := json.Unmarshal([]byte(fanOutStr), &fanOut); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", mcallv1.FanOutAnnotation, err)
+	}
+	return &fanOut, nil
+}
+
+// parseFanOutItems returns the elements of a JSON array: strings as is, other values as JSON
+func parseFanOutItems(output string) ([]string, error) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal([]byte(output), &elements); err != nil {
+		return nil, fmt.Errorf("output is not a JSON array: %w", err)
+	}
+	items := make([]string, 0, len(elements))
+	for _, element := range elements {
+		var s string
+		if err := json.Unmarshal(element, &s); err == nil {
+			items = append(items, s)
+			continue
+		}
+		items = append(items, string(element))
+	}
+	return items, nil
+}
+
+// fanOutItems returns the items of an expanded task: its items, or the elements of the JSON
+// array output of its withParam task
+func (r *McallTaskReconciler) fanOutItems(ctx context.Context, task *mcallv1.McallTask, fanOut *mcallv1.FanOut) ([]string, error) {
+	if fanOut.Param == nil {
+		return fanOut.Items, nil
+	}
+
+	var paramTask mcallv1.McallTask
+	if err := r.Get(ctx, types.NamespacedName{Name: fanOut.Param.TaskRef, Namespace: task.Namespace}, &paramTask); err != nil {
+		return nil, fmt.Errorf("withParam task %s not found: %w", fanOut.Param.TaskRef, err)
+	}
+	if paramTask.Status.Result == nil {
+		return nil, fmt.Errorf("withParam task %s has no output", fanOut.Param.TaskRef)
+	}
+	output, err := r.fullOutput(ctx, paramTask.Namespace, paramTask.Status.Result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the output of task %s: %w", fanOut.Param.TaskRef, err)
+	}
+	if fanOut.Param.JSONPath != "" {
+		if output, err = extractJSONPath(output, fanOut.Param.JSONPath); err != nil {
+			return nil, fmt.Errorf("failed to extract JSONPath %s: %w", fanOut.Param.JSONPath, err)
+		}
+	}
+	items, err := parseFanOutItems(output)
+	if err != nil {
+		return nil, fmt.Errorf("withParam task %s: %w", fanOut.Param.TaskRef, err)
+	}
+	return items, nil
+}
+
+// applyFanOutItem substitutes the ${item} placeholders in the input, input template, HTTP body
+// and headers of an instance
+func applyFanOutItem(task *mcallv1.McallTask, item string) {
+	task.Spec.Input = strings.ReplaceAll(task.Spec.Input, "${item}", item)
+	task.Spec.InputTemplate = strings.ReplaceAll(task.Spec.InputTemplate, "${item}", item)
+	task.Spec.Body = strings.ReplaceAll(task.Spec.Body, "${item}", item)
+	for name, value := range task.Spec.Headers {
+		task.Spec.Headers[name] = strings.ReplaceAll(value, "${item}", item)
+	}
+}
+
+// fanOutInstance returns the instance of an expanded task for its item at index. The conditions
+// and dependencies of the task are already met, instances start right away.
+func fanOutInstance(task *mcallv1.McallTask, index int, item string) *mcallv1.McallTask {
+	instance := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-%d", task.Name, index),
+			Namespace:   task.Namespace,
+			Labels:      map[string]string{fanOutOfLabel: task.Name},
+			Annotations: map[string]string{},
+		},
+		Spec: *task.Spec.DeepCopy(),
+	}
+	for key, value := range task.Labels {
+		instance.Labels[key] = value
+	}
+	if taskName, exists := task.Labels["mcall.tz.io/task"]; exists {
+		instance.Labels["mcall.tz.io/task"] = fmt.Sprintf("%s-%d", taskName, index)
+	}
+	for key, value := range task.Annotations {
+		switch key {
+		case mcallv1.FanOutAnnotation, "mcall.tz.io/condition", "mcall.tz.io/conditions":
+		default:
+			instance.Annotations[key] = value
+		}
+	}
+	instance.Spec.Dependencies = nil
+	applyFanOutItem(instance, item)
+	return instance
+}
+
+// fanOutIndex returns the index of the item of an instance of an expanded task
+func fanOutIndex(task, instance *mcallv1.McallTask) int {
+	index, _ := strconv.Atoi(strings.TrimPrefix(instance.Name, task.Name+"-"))
+	return index
+}
+
+// aggregateFanOut returns the phase and output of an expanded task from its instances: it
+// succeeds when all instances succeeded or were skipped, its output is the JSON array of their
+// outputs. The message names the failed instances.
+func aggregateFanOut(instances []mcallv1.McallTask, outputs []string) (mcallv1.McallTaskPhase, string, string) {
+	elements := make([]json.RawMessage, len(outputs))
+	for i, output := range outputs {
+		if json.Valid([]byte(output)) {
+			elements[i] = json.RawMessage(output)
+			continue
+		}
+		elements[i], _ = json.Marshal(output)
+	}
+	outputJSON, _ := json.Marshal(elements)
+
+	var failed []string
+	for _, instance := range instances {
+		if instance.Status.Phase != mcallv1.McallTaskPhaseSucceeded && instance.Status.Phase != mcallv1.McallTaskPhaseSkipped {
+			failed = append(failed, instance.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return mcallv1.McallTaskPhaseFailed, string(outputJSON),
+			fmt.Sprintf("%d of %d instances failed: %s", len(failed), len(instances), strings.Join(failed, ", "))
+	}
+	return mcallv1.McallTaskPhaseSucceeded, string(outputJSON), ""
+}
+
+// startFanOut creates the instances of an expanded task and marks it Running. A task whose
+// items cannot be resolved fails.
+func (r *McallTaskReconciler) startFanOut(ctx context.Context, task *mcallv1.McallTask, fanOut *mcallv1.FanOut) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	items, err := r.fanOutItems(ctx, task, fanOut)
+	if err == nil {
+		if maxFanOut := getWorkflowLimits().MaxFanOut; maxFanOut > 0 && len(items) > maxFanOut {
+			err = fmt.Errorf("task has %d items, maximum is %d", len(items), maxFanOut)
+		}
+	}
+	if err != nil {
+		log.Error(err, "Failed to resolve fan-out items", "task", task.Name)
+		task.Status.Phase = mcallv1.McallTaskPhaseFailed
+		task.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+		task.Status.Result = &mcallv1.McallTaskResult{
+			ErrorCode:    "-1",
+			ErrorMessage: fmt.Sprintf("Failed to expand task: %v", err),
+		}
+		setTaskConditions(task)
+		if err := r.Status().Update(ctx, task); err != nil {
+			return ctrl.Result{}, err
+		}
+		recordEvent(r.Recorder, task, corev1.EventTypeWarning, EventReasonFailed, "%s", truncateString(task.Status.Result.ErrorMessage, eventMessageMaxLength))
+		r.notifyTaskResult(ctx, task)
+		return ctrl.Result{}, nil
+	}
+
+	for i, item := range items {
+		if err := r.Create(ctx, fanOutInstance(task, i, item)); err != nil && !apierrors.IsAlreadyExists(err) {
+			log.Error(err, "Failed to create fan-out instance", "task", task.Name, "index", i)
+			return ctrl.Result{}, err
+		}
+	}
+
+	task.Status.Phase = mcallv1.McallTaskPhaseRunning
+	task.Status.StartTime = &metav1.Time{Time: time.Now()}
+	setTaskConditions(task)
+	if err := r.Status().Update(ctx, task); err != nil {
+		return ctrl.Result{}, err
+	}
+	log.Info("Expanded task into instances", "task", task.Name, "instances", len(items))
+	recordEvent(r.Recorder, task, corev1.EventTypeNormal, EventReasonStarted, "Started %s task with %d instances", task.Spec.Type, len(items))
+	return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+}
+
+// completeFanOut completes an expanded task with the aggregated results of its instances once
+// they all completed
+func (r *McallTaskReconciler) completeFanOut(ctx context.Context, task *mcallv1.McallTask, fanOut *mcallv1.FanOut) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	items, err := r.fanOutItems(ctx, task, fanOut)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	var instanceList mcallv1.McallTaskList
+	if err := r.List(ctx, &instanceList, client.InNamespace(task.Namespace), client.MatchingLabels{fanOutOfLabel: task.Name}); err != nil {
+		return ctrl.Result{}, err
+	}
+	instances := instanceList.Items
+	if len(instances) < len(items) {
+		log.V(debugLevel).Info("Waiting for fan-out instances to be created", "task", task.Name, "instances", len(instances), "items", len(items))
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+	// Aggregate in the order of the items
+	sort.Slice(instances, func(i, j int) bool {
+		return fanOutIndex(task, &instances[i]) < fanOutIndex(task, &instances[j])
+	})
+
+	outputs := make([]string, len(instances))
+	for i, instance := range instances {
+		switch instance.Status.Phase {
+		case mcallv1.McallTaskPhaseSucceeded, mcallv1.McallTaskPhaseFailed, mcallv1.McallTaskPhaseSkipped:
+		default:
+			log.V(debugLevel).Info("Waiting for fan-out instances", "task", task.Name, "instance", instance.Name, "phase", instance.Status.Phase)
+			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		}
+		if instance.Status.Result == nil {
+			continue
+		}
+		output, err := r.fullOutput(ctx, instance.Namespace, instance.Status.Result)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to read the output of task %s: %w", instance.Name, err)
+		}
+		outputs[i] = output
+	}
+
+	phase, output, message := aggregateFanOut(instances, outputs)
+	result := newTaskResult(task, output)
+	result.ErrorCode = "0"
+	if phase == mcallv1.McallTaskPhaseFailed {
+		result.ErrorCode = "-1"
+	}
+	result.ErrorMessage = message
+	if result.OutputConfigMap != "" {
+		if err := r.storeOutputConfigMap(ctx, task, result.OutputConfigMap, output); err != nil {
+			log.Error(err, "Failed to store full output", "task", task.Name, "configMap", result.OutputConfigMap)
+			result.OutputConfigMap = ""
+		}
+	}
+
+	task.Status.Phase = phase
+	task.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	task.Status.Result = result
+	if task.Status.StartTime != nil {
+		task.Status.ExecutionTimeMs = task.Status.CompletionTime.Sub(task.Status.StartTime.Time).Milliseconds()
+	}
+	setTaskConditions(task)
+	if err := r.Status().Update(ctx, task); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	log.Info("Fan-out instances completed", "task", task.Name, "instances", len(instances), "phase", phase)
+	if phase == mcallv1.McallTaskPhaseFailed {
+		recordEvent(r.Recorder, task, corev1.EventTypeWarning, EventReasonFailed, "%s", truncateString(message, eventMessageMaxLength))
+	} else {
+		recordEvent(r.Recorder, task, corev1.EventTypeNormal, EventReasonSucceeded, "All %d instances succeeded", len(instances))
+	}
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestWorkflowFanOut tests expanding a task into one instance per item and aggregating their results
+func TestWorkflowFanOut(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+	ctx := context.Background()
+
+	newTask := func(fanOut string) *mcallv1.McallTask {
+		return &mcallv1.McallTask{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "checks-probe",
+				Namespace: "default",
+				Labels:    map[string]string{"mcall.tz.io/workflow": "checks", "mcall.tz.io/task": "probe"},
+				Annotations: map[string]string{
+					mcallv1.FanOutAnnotation: fanOut,
+					"mcall.tz.io/condition":  `{"dependentTask":"checks-hosts","when":"success"}`,
+				},
+			},
+			Spec: mcallv1.McallTaskSpec{
+				Type:         "cmd",
+				Input:        "ping -c 1 ${item}",
+				Dependencies: []string{"checks-hosts"},
+			},
+			Status: mcallv1.McallTaskStatus{Phase: mcallv1.McallTaskPhasePending},
+		}
+	}
+	hosts := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{Name: "checks-hosts", Namespace: "default"},
+		Status: mcallv1.McallTaskStatus{
+			Phase:  mcallv1.McallTaskPhaseSucceeded,
+			Result: &mcallv1.McallTaskResult{ErrorCode: "0", Output: `{"hosts": ["a.example.com", "b.example.com"]}`},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		fanOut     string
+		maxFanOut  string
+		wantInputs []string
+		wantErr    string
+	}{
+		{
+			name:       "withItems",
+			fanOut:     `{"items": ["x.example.com", "y.example.com", "z.example.com"]}`,
+			wantInputs: []string{"ping -c 1 x.example.com", "ping -c 1 y.example.com", "ping -c 1 z.example.com"},
+		},
+		{
+			name:       "withParam",
+			fanOut:     `{"param": {"taskRef": "checks-hosts", "jsonPath": "$.hosts"}}`,
+			wantInputs: []string{"ping -c 1 a.example.com", "ping -c 1 b.example.com"},
+		},
+		{
+			name:    "withParam output not an array",
+			fanOut:  `{"param": {"taskRef": "checks-hosts"}}`,
+			wantErr: "output is not a JSON array",
+		},
+		{
+			name:      "too many items",
+			fanOut:    `{"param": {"taskRef": "checks-hosts", "jsonPath": "$.hosts"}}`,
+			maxFanOut: "1",
+			wantErr:   "task has 2 items, maximum is 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.maxFanOut != "" {
+				t.Setenv("WORKFLOW_MAX_FAN_OUT", tt.maxFanOut)
+			}
+			task := newTask(tt.fanOut)
+			reconciler := &McallTaskReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme).
+					WithStatusSubresource(&mcallv1.McallTask{}).
+					WithObjects(hosts.DeepCopy(), task).Build(),
+				Scheme: scheme,
+			}
+			fanOut, err := taskFanOut(task)
+			if err != nil {
+				t.Fatalf("taskFanOut() unexpected error: %v", err)
+			}
+
+			if _, err := reconciler.startFanOut(ctx, task, fanOut); err != nil {
+				t.Fatalf("startFanOut() unexpected error: %v", err)
+			}
+			if tt.wantErr != "" {
+				if task.Status.Phase != mcallv1.McallTaskPhaseFailed || !strings.Contains(task.Status.Result.ErrorMessage, tt.wantErr) {
+					t.Errorf("startFanOut() phase = %s, result = %+v, want Failed with %q", task.Status.Phase, task.Status.Result, tt.wantErr)
+				}
+				return
+			}
+			if task.Status.Phase != mcallv1.McallTaskPhaseRunning {
+				t.Errorf("startFanOut() phase = %s, want Running", task.Status.Phase)
+			}
+
+			var instances []*mcallv1.McallTask
+			for i, wantInput := range tt.wantInputs {
+				var instance mcallv1.McallTask
+				name := fmt.Sprintf("%s-%d", task.Name, i)
+				if err := reconciler.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &instance); err != nil {
+					t.Fatalf("instance %s not created: %v", name, err)
+				}
+				if instance.Spec.Input != wantInput {
+					t.Errorf("instance %s input = %q, want %q", name, instance.Spec.Input, wantInput)
+				}
+				if instance.Labels["mcall.tz.io/task"] != fmt.Sprintf("probe-%d", i) || instance.Labels[fanOutOfLabel] != task.Name {
+					t.Errorf("instance %s labels = %v", name, instance.Labels)
+				}
+				if len(instance.Spec.Dependencies) > 0 || instance.Annotations["mcall.tz.io/condition"] != "" {
+					t.Errorf("instance %s keeps the dependencies or condition of the task", name)
+				}
+				instances = append(instances, &instance)
+			}
+
+			// Not completed while an instance runs
+			if _, err := reconciler.completeFanOut(ctx, task, fanOut); err != nil {
+				t.Fatalf("completeFanOut() unexpected error: %v", err)
+			}
+			if task.Status.Phase != mcallv1.McallTaskPhaseRunning {
+				t.Errorf("completeFanOut() phase = %s before the instances completed, want Running", task.Status.Phase)
+			}
+
+			// The last instance fails with a plain text output
+			for i, instance := range instances {
+				instance.Status.Phase = mcallv1.McallTaskPhaseSucceeded
+				instance.Status.Result = &mcallv1.McallTaskResult{ErrorCode: "0", Output: `{"ok": true}`}
+				if i == len(instances)-1 {
+					instance.Status.Phase = mcallv1.McallTaskPhaseFailed
+					instance.Status.Result = &mcallv1.McallTaskResult{ErrorCode: "-1", Output: "timeout"}
+				}
+				if err := reconciler.Status().Update(ctx, instance); err != nil {
+					t.Fatalf("failed to update instance: %v", err)
+				}
+			}
+			if _, err := reconciler.completeFanOut(ctx, task, fanOut); err != nil {
+				t.Fatalf("completeFanOut() unexpected error: %v", err)
+			}
+			if task.Status.Phase != mcallv1.McallTaskPhaseFailed || task.Status.Result.ErrorCode != "-1" {
+				t.Errorf("completeFanOut() phase = %s, errorCode = %s, want Failed", task.Status.Phase, task.Status.Result.ErrorCode)
+			}
+			wantOutput := "[" + strings.Repeat(`{"ok":true},`, len(instances)-1) + `"timeout"]`
+			if task.Status.Result.Output != wantOutput {
+				t.Errorf("completeFanOut() output = %s, want %s", task.Status.Result.Output, wantOutput)
+			}
+			wantMessage := fmt.Sprintf("1 of %d instances failed: %s", len(instances), instances[len(instances)-1].Name)
+			if task.Status.Result.ErrorMessage != wantMessage {
+				t.Errorf("completeFanOut() message = %q, want %q", task.Status.Result.ErrorMessage, wantMessage)
+			}
+		})
+	}
+}
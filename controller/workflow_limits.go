@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"fmt"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// workflowLimitReason is the status reason of workflows rejected by the size limits
+const workflowLimitReason = "LimitExceeded"
+
+// templateNotValidatedReason is the status reason of workflows waiting for a template warm-up
+const templateNotValidatedReason = "TemplateNotValidated"
+
+// workflowLimits holds the size and complexity guardrails for workflow specs (0 disables a limit)
+type workflowLimits struct {
+	MaxTasks           int
+	MaxDependencyDepth int
+	MaxInputsPerTask   int
+	MaxFanOut          int
+}
+
+// getWorkflowLimits loads the workflow guardrails from environment variables
+func getWorkflowLimits() workflowLimits {
+	return workflowLimits{
+		MaxTasks:           getEnvIntOrDefault("WORKFLOW_MAX_TASKS", 100),
+		MaxDependencyDepth: getEnvIntOrDefault("WORKFLOW_MAX_DEPENDENCY_DEPTH", 20),
+		MaxInputsPerTask:   getEnvIntOrDefault("WORKFLOW_MAX_INPUTS_PER_TASK", 20),
+		MaxFanOut:          getEnvIntOrDefault("WORKFLOW_MAX_FAN_OUT", 100),
+	}
+}
+
+// validateWorkflowLimits rejects workflow specs exceeding the configured guardrails
+func validateWorkflowLimits(spec *mcallv1.McallWorkflowSpec, limits workflowLimits) error {
+	tasks := allWorkflowTasks(spec)
+	if limits.MaxTasks > 0 && len(tasks) > limits.MaxTasks {
+		return fmt.Errorf("workflow has %d tasks, maximum is %d", len(tasks), limits.MaxTasks)
+	}
+
+	if limits.MaxInputsPerTask > 0 {
+		for _, task := range tasks {
+			if len(task.InputSources) > limits.MaxInputsPerTask {
+				return fmt.Errorf("task %s has %d input sources, maximum is %d", task.Name, len(task.InputSources), limits.MaxInputsPerTask)
+			}
+		}
+	}
+
+	if limits.MaxFanOut > 0 {
+		for _, task := range tasks {
+			if len(task.WithItems) > limits.MaxFanOut {
+				return fmt.Errorf("task %s has %d items, maximum is %d", task.Name, len(task.WithItems), limits.MaxFanOut)
+			}
+		}
+	}
+
+	if limits.MaxDependencyDepth > 0 {
+		depth, err := workflowDependencyDepth(tasks)
+		if err != nil {
+			return err
+		}
+		if depth > limits.MaxDependencyDepth {
+			return fmt.Errorf("workflow dependency depth is %d, maximum is %d", depth, limits.MaxDependencyDepth)
+		}
+	}
+
+	return nil
+}
+
+// workflowDependencyDepth returns the number of tasks in the longest dependency chain
+func workflowDependencyDepth(tasks []mcallv1.WorkflowTaskRef) (int, error) {
+	dependencies := make(map[string][]string, len(tasks))
+	for _, task := range tasks {
+		dependencies[task.Name] = task.Dependencies
+	}
+
+	depths := make(map[string]int, len(tasks))
+	visiting := make(map[string]bool)
+
+	var visit func(name string) (int, error)
+	visit = func(name string) (int, error) {
+		if depth, ok := depths[name]; ok {
+			return depth, nil
+		}
+		if visiting[name] {
+			return 0, fmt.Errorf("dependency cycle detected at task %s", name)
+		}
+		visiting[name] = true
+
+		depth := 1
+		for _, dep := range dependencies[name] {
+			if _, exists := dependencies[dep]; !exists {
+				continue // Unknown dependencies are reported when the tasks run
+			}
+			depDepth, err := visit(dep)
+			if err != nil {
+				return 0, err
+			}
+			if depDepth+1 > depth {
+				depth = depDepth + 1
+			}
+		}
+
+		visiting[name] = false
+		depths[name] = depth
+		return depth, nil
+	}
+
+	maxDepth := 0
+	for _, task := range tasks {
+		depth, err := visit(task.Name)
+		if err != nil {
+			return 0, err
+		}
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+	}
+	return maxDepth, nil
+}
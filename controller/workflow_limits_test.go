@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestValidateWorkflowLimits tests rejecting workflow specs exceeding the guardrails
+func TestValidateWorkflowLimits(t *testing.T) {
+	limits := workflowLimits{MaxTasks: 3, MaxDependencyDepth: 2, MaxInputsPerTask: 1, MaxFanOut: 2}
+	input := mcallv1.TaskInputSource{Name: "IN", TaskRef: "a", Field: "output"}
+
+	tests := []struct {
+		name    string
+		tasks   []mcallv1.WorkflowTaskRef
+		limits  workflowLimits
+		wantErr string
+	}{
+		{
+			name: "within limits",
+			tasks: []mcallv1.WorkflowTaskRef{
+				{Name: "a"},
+				{Name: "b", Dependencies: []string{"a"}, InputSources: []mcallv1.TaskInputSource{input}},
+			},
+			limits: limits,
+		},
+		{
+			name:    "too many tasks",
+			tasks:   []mcallv1.WorkflowTaskRef{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}},
+			limits:  limits,
+			wantErr: "4 tasks",
+		},
+		{
+			name: "too many inputs",
+			tasks: []mcallv1.WorkflowTaskRef{
+				{Name: "a"},
+				{Name: "b", InputSources: []mcallv1.TaskInputSource{input, input}},
+			},
+			limits:  limits,
+			wantErr: "2 input sources",
+		},
+		{
+			name:    "too many items",
+			tasks:   []mcallv1.WorkflowTaskRef{{Name: "a", WithItems: []string{"x", "y", "z"}}},
+			limits:  limits,
+			wantErr: "3 items",
+		},
+		{
+			name: "dependency chain too deep",
+			tasks: []mcallv1.WorkflowTaskRef{
+				{Name: "a"},
+				{Name: "b", Dependencies: []string{"a"}},
+				{Name: "c", Dependencies: []string{"b"}},
+			},
+			limits:  limits,
+			wantErr: "depth is 3",
+		},
+		{
+			name: "dependency cycle",
+			tasks: []mcallv1.WorkflowTaskRef{
+				{Name: "a", Dependencies: []string{"b"}},
+				{Name: "b", Dependencies: []string{"a"}},
+			},
+			limits:  limits,
+			wantErr: "cycle",
+		},
+		{
+			name:   "limits disabled",
+			tasks:  []mcallv1.WorkflowTaskRef{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}},
+			limits: workflowLimits{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWorkflowLimits(&mcallv1.McallWorkflowSpec{Tasks: tt.tasks}, tt.limits)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateWorkflowLimits() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateWorkflowLimits() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
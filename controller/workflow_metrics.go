@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+var (
+	workflowRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcall_workflow_runs_total",
+		Help: "Number of completed workflow runs by resulting phase",
+	}, []string{"namespace", "workflow", "phase"})
+	workflowSuccessRatio = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mcall_workflow_success_ratio",
+		Help: "Ratio of the workflow runs completed since the controller started that succeeded",
+	}, []string{"namespace", "workflow"})
+	workflowRunDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mcall_workflow_run_duration_seconds",
+		Help:    "Duration of completed workflow runs",
+		Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600, 7200},
+	}, []string{"namespace", "workflow"})
+	workflowLastRun = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mcall_workflow_last_run_timestamp_seconds",
+		Help: "Unix time of the last run of scheduled workflows",
+	}, []string{"namespace", "workflow"})
+	workflowNextRun = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mcall_workflow_next_run_timestamp_seconds",
+		Help: "Unix time of the next run of scheduled workflows, absent while suspended",
+	}, []string{"namespace", "workflow"})
+	workflowMissedRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcall_workflow_missed_runs_total",
+		Help: "Number of schedule times of workflows that passed without a run",
+	}, []string{"namespace", "workflow"})
+	workflowRunningTasks = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mcall_workflow_running_tasks",
+		Help: "Number of tasks of the current workflow run in the Running phase",
+	}, []string{"namespace", "workflow"})
+
+	// Completed and succeeded runs by workflow, for the success ratio
+	workflowRunCountsMu sync.Mutex
+	workflowRunCounts   = make(map[string][2]int)
+)
+
+func init() {
+	metrics.Registry.MustRegister(workflowRuns, workflowSuccessRatio, workflowRunDuration, workflowLastRun,
+		workflowNextRun, workflowMissedRuns, workflowRunningTasks)
+}
+
+// recordWorkflowRunMetrics records a completed workflow run and updates the success ratio of the workflow
+func recordWorkflowRunMetrics(workflow *mcallv1.McallWorkflow) {
+	workflowRuns.WithLabelValues(workflow.Namespace, workflow.Name, string(workflow.Status.Phase)).Inc()
+	if workflow.Status.StartTime != nil && workflow.Status.CompletionTime != nil {
+		workflowRunDuration.WithLabelValues(workflow.Namespace, workflow.Name).
+			Observe(workflow.Status.CompletionTime.Sub(workflow.Status.StartTime.Time).Seconds())
+	}
+
+	key := workflow.Namespace + "/" + workflow.Name
+	workflowRunCountsMu.Lock()
+	counts := workflowRunCounts[key]
+	counts[0]++
+	if workflow.Status.Phase == mcallv1.McallWorkflowPhaseSucceeded {
+		counts[1]++
+	}
+	workflowRunCounts[key] = counts
+	workflowRunCountsMu.Unlock()
+
+	workflowSuccessRatio.WithLabelValues(workflow.Namespace, workflow.Name).Set(float64(counts[1]) / float64(counts[0]))
+}
+
+// recordWorkflowSchedule exports the last and next run times of a scheduled workflow from its status
+func recordWorkflowSchedule(workflow *mcallv1.McallWorkflow) {
+	if workflow.Status.LastRunTime != nil {
+		workflowLastRun.WithLabelValues(workflow.Namespace, workflow.Name).Set(float64(workflow.Status.LastRunTime.Unix()))
+	}
+	if workflow.Status.NextRunTime != nil {
+		workflowNextRun.WithLabelValues(workflow.Namespace, workflow.Name).Set(float64(workflow.Status.NextRunTime.Unix()))
+	} else {
+		workflowNextRun.DeleteLabelValues(workflow.Namespace, workflow.Name)
+	}
+}
+
+// recordWorkflowRunningTasks exports the number of running tasks of a workflow run
+func recordWorkflowRunningTasks(workflow *mcallv1.McallWorkflow, tasks []mcallv1.McallTask) {
+	var running int
+	for _, task := range tasks {
+		if task.Status.Phase == mcallv1.McallTaskPhaseRunning {
+			running++
+		}
+	}
+	workflowRunningTasks.WithLabelValues(workflow.Namespace, workflow.Name).Set(float64(running))
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// invalidParametersReason is the status reason of workflows whose parameters or trigger
+// annotation doesn't parse or sets undeclared parameters
+const invalidParametersReason = "InvalidParameters"
+
+// resolveWorkflowParameters returns the parameter values of the next run of a workflow: the
+// values of its trigger annotation, else of its parameters annotation, else the defaults of
+// spec.parameters
+func resolveWorkflowParameters(workflow *mcallv1.McallWorkflow) (map[string]string, error) {
+	values := map[string]string{}
+	for _, annotation := range []string{mcallv1.ParametersAnnotation, mcallv1.TriggerAnnotation} {
+		valuesStr, exists := workflow.Annotations[annotation]
+		if !exists || valuesStr == "" {
+			continue
+		}
+		var annotationValues map[string]string
+		if err := json.Unmarshal([]byte(valuesStr), &annotationValues); err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: %w", annotation, err)
+		}
+		for name, value := range annotationValues {
+			values[name] = value
+		}
+	}
+
+	params := make(map[string]string, len(workflow.Spec.Parameters))
+	for _, param := range workflow.Spec.Parameters {
+		if _, exists := params[param.Name]; exists {
+			return nil, fmt.Errorf("parameter %s is declared more than once", param.Name)
+		}
+		value, exists := values[param.Name]
+		if !exists {
+			value = param.Default
+		}
+		params[param.Name] = value
+	}
+
+	var undeclared []string
+	for name := range values {
+		if _, exists := params[name]; !exists {
+			undeclared = append(undeclared, name)
+		}
+	}
+	if len(undeclared) > 0 {
+		sort.Strings(undeclared)
+		return nil, fmt.Errorf("undeclared parameters: %s", strings.Join(undeclared, ", "))
+	}
+	return params, nil
+}
+
+// workflowTriggered reports whether a run of a workflow was requested with the trigger annotation
+func workflowTriggered(workflow *mcallv1.McallWorkflow) bool {
+	_, exists := workflow.Annotations[mcallv1.TriggerAnnotation]
+	return exists
+}
+
+// substituteParameters replaces the ${params.<name>} placeholders of a string with the
+// parameter values
+func substituteParameters(s string, params map[string]string) string {
+	if len(params) == 0 {
+		return s
+	}
+	replacements := make([]string, 0, 2*len(params))
+	for name, value := range params {
+		replacements = append(replacements, "${params."+name+"}", value)
+	}
+	return strings.NewReplacer(replacements...).Replace(s)
+}
+
+// applyWorkflowParameters substitutes the parameters into the input, input template, HTTP body
+// and headers of a workflow task instance
+func applyWorkflowParameters(task *mcallv1.McallTask, params map[string]string) {
+	if len(params) == 0 {
+		return
+	}
+	task.Spec.Input = substituteParameters(task.Spec.Input, params)
+	task.Spec.InputTemplate = substituteParameters(task.Spec.InputTemplate, params)
+	task.Spec.Body = substituteParameters(task.Spec.Body, params)
+	for name, value := range task.Spec.Headers {
+		task.Spec.Headers[name] = substituteParameters(value, params)
+	}
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestWorkflowParameters tests resolving workflow parameters and substituting them into task instances
+func TestWorkflowParameters(t *testing.T) {
+	parameters := []mcallv1.WorkflowParameter{
+		{Name: "region", Default: "us", Description: "Region to check"},
+		{Name: "path", Default: "/health"},
+	}
+
+	tests := []struct {
+		name        string
+		annotation  string
+		trigger     string
+		parameters  []mcallv1.WorkflowParameter
+		want        map[string]string
+		wantErr     string
+		input       string
+		wantInput   string
+		headerValue string
+	}{
+		{
+			name:       "defaults",
+			parameters: parameters,
+			want:       map[string]string{"region": "us", "path": "/health"},
+			input:      "https://${params.region}.example.com${params.path}",
+			wantInput:  "https://us.example.com/health",
+		},
+		{
+			name:        "annotation values",
+			annotation:  `{"region": "eu"}`,
+			parameters:  parameters,
+			want:        map[string]string{"region": "eu", "path": "/health"},
+			input:       "https://${params.region}.example.com${params.path} ${UNRELATED}",
+			wantInput:   "https://eu.example.com/health ${UNRELATED}",
+			headerValue: "eu",
+		},
+		{
+			name:       "trigger overrides annotation",
+			annotation: `{"region": "eu", "path": "/ready"}`,
+			trigger:    `{"region": "ap"}`,
+			parameters: parameters,
+			want:       map[string]string{"region": "ap", "path": "/ready"},
+			input:      "https://${params.region}.example.com${params.path}",
+			wantInput:  "https://ap.example.com/ready",
+		},
+		{
+			name:       "undeclared parameter",
+			annotation: `{"zone": "a", "regoin": "eu"}`,
+			parameters: parameters,
+			wantErr:    "undeclared parameters: regoin, zone",
+		},
+		{
+			name:       "invalid annotation",
+			annotation: `{"region":`,
+			parameters: parameters,
+			wantErr:    "invalid mcall.tz.io/parameters annotation",
+		},
+		{
+			name:       "invalid trigger",
+			trigger:    `{"zone": "a"}`,
+			parameters: parameters,
+			wantErr:    "undeclared parameters: zone",
+		},
+		{
+			name:       "duplicate parameter",
+			parameters: []mcallv1.WorkflowParameter{{Name: "region"}, {Name: "region"}},
+			wantErr:    "parameter region is declared more than once",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := &mcallv1.McallWorkflow{
+				ObjectMeta: metav1.ObjectMeta{Name: "checks", Namespace: "default", Annotations: map[string]string{}},
+				Spec:       mcallv1.McallWorkflowSpec{Parameters: tt.parameters},
+			}
+			if tt.annotation != "" {
+				workflow.Annotations[mcallv1.ParametersAnnotation] = tt.annotation
+			}
+			if tt.trigger != "" {
+				workflow.Annotations[mcallv1.TriggerAnnotation] = tt.trigger
+			}
+
+			params, err := resolveWorkflowParameters(workflow)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("resolveWorkflowParameters() error = %v, want %q", err, tt.wantErr)
+				}
+				if _, err := (&McallWorkflowValidator{}).ValidateCreate(context.Background(), workflow); err == nil {
+					t.Errorf("ValidateCreate() error = nil, want %q", tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveWorkflowParameters() unexpected error: %v", err)
+			}
+			for name, value := range tt.want {
+				if params[name] != value {
+					t.Errorf("resolveWorkflowParameters()[%s] = %q, want %q", name, params[name], value)
+				}
+			}
+
+			task := &mcallv1.McallTask{Spec: mcallv1.McallTaskSpec{
+				Input:   tt.input,
+				Headers: map[string]string{"X-Region": "${params.region}"},
+			}}
+			applyWorkflowParameters(task, params)
+			if task.Spec.Input != tt.wantInput {
+				t.Errorf("applyWorkflowParameters() input = %q, want %q", task.Spec.Input, tt.wantInput)
+			}
+			if tt.headerValue != "" && task.Spec.Headers["X-Region"] != tt.headerValue {
+				t.Errorf("applyWorkflowParameters() header = %q, want %q", task.Spec.Headers["X-Region"], tt.headerValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// workflowRestartRequested reports whether a workflow was requested to restart with the restart
+// annotation
+func workflowRestartRequested(workflow *mcallv1.McallWorkflow) bool {
+	return workflow.Annotations[mcallv1.RestartAnnotation] == "true"
+}
+
+// restartWorkflow deletes the task instances of the current or last run of a workflow and
+// returns it to Pending. The restart annotation is replaced by a trigger, so the new run starts
+// right away regardless of the schedule and is recorded as a manual run.
+func (r *McallWorkflowReconciler) restartWorkflow(ctx context.Context, workflow *mcallv1.McallWorkflow) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	previousPhase := workflow.Status.Phase
+	if previousPhase != mcallv1.McallWorkflowPhasePending && workflow.Status.StartTime != nil {
+		// A run in progress ends as Failed
+		if previousPhase == mcallv1.McallWorkflowPhaseRunning {
+			workflow.Status.Phase = mcallv1.McallWorkflowPhaseFailed
+			workflow.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+			workflow.Status.LastRunDuration = formatDuration(workflow.Status.CompletionTime.Sub(workflow.Status.StartTime.Time))
+			if err := r.recordWorkflowRun(ctx, workflow); err != nil {
+				log.Error(err, "Failed to record workflow run", "workflow", workflow.Name)
+			}
+			endWorkflowRunSpan(workflow)
+		}
+
+		// Keep copies of the run's task instances if run history is enabled
+		if err := r.archiveWorkflowRun(ctx, workflow); err != nil {
+			log.Error(err, "Failed to archive workflow run history", "workflow", workflow.Name)
+		}
+		if err := r.pruneRunHistory(ctx, workflow); err != nil {
+			log.Error(err, "Failed to prune workflow run history", "workflow", workflow.Name)
+		}
+	}
+
+	if err := r.deleteWorkflowTasks(ctx, workflow); err != nil {
+		log.Error(err, "Failed to delete workflow tasks", "workflow", workflow.Name)
+		return ctrl.Result{}, err
+	}
+
+	// The trigger of a restart keeps the parameter overrides of a pending trigger
+	patch := client.MergeFromWithOptions(workflow.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	delete(workflow.Annotations, mcallv1.RestartAnnotation)
+	if !workflowTriggered(workflow) {
+		workflow.Annotations[mcallv1.TriggerAnnotation] = ""
+	}
+	if err := r.Patch(ctx, workflow, patch); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &mcallv1.McallWorkflow{}
+		if err := r.Get(ctx, types.NamespacedName{Name: workflow.Name, Namespace: workflow.Namespace}, latest); err != nil {
+			return err
+		}
+
+		latest.Status.Phase = mcallv1.McallWorkflowPhasePending
+		latest.Status.StartTime = nil
+		latest.Status.CompletionTime = nil
+		latest.Status.OnExitStartTime = nil
+		latest.Status.RestartCount++
+		latest.Status.LastRestartTime = &metav1.Time{Time: time.Now()}
+		setWorkflowConditions(latest)
+		return r.Status().Update(ctx, latest)
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	log.Info("Workflow restarted", "workflow", workflow.Name, "previousPhase", previousPhase)
+	recordEvent(r.Recorder, workflow, corev1.EventTypeNormal, EventReasonRestarted, "Restarted from phase %s", previousPhase)
+	return ctrl.Result{RequeueAfter: 1 * time.Second}, nil
+}
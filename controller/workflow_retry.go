@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// retryFailedRequested reports whether the failed run of a workflow was requested to resume
+// with the retry-failed annotation
+func retryFailedRequested(workflow *mcallv1.McallWorkflow) bool {
+	_, exists := workflow.Annotations[mcallv1.RetryFailedAnnotation]
+	return exists
+}
+
+// retriedTask reports whether a task instance runs again when its failed run resumes: failed,
+// blocked and skipped tasks do, as do the failure handlers of the tasks that run again
+func retriedTask(task *mcallv1.McallTask, retried map[string]bool) bool {
+	switch task.Status.Phase {
+	case mcallv1.McallTaskPhaseFailed, mcallv1.McallTaskPhaseBlocked, mcallv1.McallTaskPhaseSkipped:
+		return true
+	}
+	failedTask, isHandler := task.Labels[failureHandlerLabel]
+	return isHandler && retried[failedTask]
+}
+
+// resetTaskStatus returns a task instance to Pending, clearing the results of its last execution
+func resetTaskStatus(task *mcallv1.McallTask) {
+	task.Status = mcallv1.McallTaskStatus{
+		Phase:              mcallv1.McallTaskPhasePending,
+		ObservedGeneration: task.Status.ObservedGeneration,
+	}
+	setTaskConditions(task)
+}
+
+// retryFailedTasks resumes the failed run of a workflow. Its failed, blocked and skipped task
+// instances return to Pending and its exit task instances are deleted, to run again with the
+// new outcome. Succeeded instances are kept, so the tasks running again read their results.
+func (r *McallWorkflowReconciler) retryFailedTasks(ctx context.Context, workflow *mcallv1.McallWorkflow) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	if workflow.Status.Phase != mcallv1.McallWorkflowPhaseFailed {
+		log.Info("Ignoring retry of a workflow that didn't fail", "workflow", workflow.Name, "phase", workflow.Status.Phase)
+		recordEvent(r.Recorder, workflow, corev1.EventTypeWarning, EventReasonSkipped, "Retry ignored, the last run %s", workflow.Status.Phase)
+		return ctrl.Result{}, r.consumeRetryFailed(ctx, workflow)
+	}
+
+	var tasks mcallv1.McallTaskList
+	if err := r.List(ctx, &tasks, client.InNamespace(workflow.Namespace), client.MatchingLabels{"mcall.tz.io/workflow": workflow.Name}); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Failure handlers run again with the tasks they handle
+	retried := make(map[string]bool)
+	for i := range tasks.Items {
+		if retriedTask(&tasks.Items[i], nil) {
+			retried[tasks.Items[i].Labels["mcall.tz.io/task"]] = true
+		}
+	}
+
+	var resetCount int
+	for i := range tasks.Items {
+		task := &tasks.Items[i]
+		if _, isExit := task.Labels[exitTaskLabel]; isExit {
+			if err := r.Delete(ctx, task); err != nil && !apierrors.IsNotFound(err) {
+				return ctrl.Result{}, err
+			}
+			continue
+		}
+		if !retriedTask(task, retried) {
+			continue
+		}
+		resetTaskStatus(task)
+		if err := r.Status().Update(ctx, task); err != nil {
+			return ctrl.Result{}, err
+		}
+		resetCount++
+	}
+
+	if err := r.consumeRetryFailed(ctx, workflow); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &mcallv1.McallWorkflow{}
+		if err := r.Get(ctx, types.NamespacedName{Name: workflow.Name, Namespace: workflow.Namespace}, latest); err != nil {
+			return err
+		}
+
+		// The run keeps its start time, so its task instances and McallWorkflowRun are reused
+		latest.Status.Phase = mcallv1.McallWorkflowPhaseRunning
+		latest.Status.CompletionTime = nil
+		latest.Status.OnExitStartTime = nil
+		latest.Status.RetryCount++
+		latest.Status.LastRetryTime = &metav1.Time{Time: time.Now()}
+		setWorkflowConditions(latest)
+		if err := r.Status().Update(ctx, latest); err != nil {
+			return err
+		}
+		workflow.Status = latest.Status
+		return nil
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.recordWorkflowRun(ctx, workflow); err != nil {
+		log.Error(err, "Failed to record workflow run", "workflow", workflow.Name)
+	}
+
+	log.Info("Resumed failed workflow run", "workflow", workflow.Name, "run", workflowRunID(workflow), "retriedTasks", resetCount)
+	recordEvent(r.Recorder, workflow, corev1.EventTypeNormal, EventReasonRetrying, "Resumed the failed run, %d task(s) run again", resetCount)
+	return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+}
+
+// consumeRetryFailed removes the retry-failed annotation of a workflow
+func (r *McallWorkflowReconciler) consumeRetryFailed(ctx context.Context, workflow *mcallv1.McallWorkflow) error {
+	patch := client.MergeFromWithOptions(workflow.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	delete(workflow.Annotations, mcallv1.RetryFailedAnnotation)
+	return r.Patch(ctx, workflow, patch)
+}
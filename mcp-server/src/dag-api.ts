@@ -169,6 +169,75 @@ router.get('/tasks/:namespace/:name', async (req, res) => {
   }
 });
 
+// Annotations documenting tasks and workflows in the catalog
+const DESCRIPTION_ANNOTATION = 'mcall.tz.io/description';
+const OWNER_ANNOTATION = 'mcall.tz.io/owner';
+
+// GET /api/catalog/:namespace - Catalog of template tasks and workflows with last-run stats
+router.get('/catalog/:namespace', async (req, res) => {
+  try {
+    const { namespace } = req.params;
+
+    // Template tasks only: skip workflow instances and run history copies
+    const taskResponse = await k8sClient.listTasks(namespace, '!mcall.tz.io/workflow,!mcall.tz.io/history-of,!mcall.tz.io/warmup-of');
+    const workflowResponse = await k8sClient.listWorkflows(namespace);
+
+    const tasks = (taskResponse.items || []).map((task: any) => ({
+      name: task.metadata?.name,
+      type: task.spec?.type,
+      description: task.metadata?.annotations?.[DESCRIPTION_ANNOTATION] || '',
+      owner: task.metadata?.annotations?.[OWNER_ANNOTATION] || '',
+      schedule: task.spec?.schedule || '',
+      parameters: [
+        ...(task.spec?.inputSources || []).map((source: any) => source.name),
+        ...Object.keys(task.spec?.environment || {}),
+      ],
+      lastRun: {
+        phase: task.status?.phase,
+        startTime: task.status?.startTime,
+        completionTime: task.status?.completionTime,
+        executionTimeMs: task.status?.executionTimeMs,
+        lastTaskRun: task.status?.lastTaskRun,
+      },
+    }));
+
+    const workflows = (workflowResponse.items || []).map((workflow: any) => ({
+      name: workflow.metadata?.name,
+      description: workflow.metadata?.annotations?.[DESCRIPTION_ANNOTATION] || '',
+      owner: workflow.metadata?.annotations?.[OWNER_ANNOTATION] || '',
+      schedule: workflow.spec?.schedule || '',
+      suspend: workflow.spec?.suspend || false,
+      tasks: (workflow.spec?.tasks || []).map((task: any) => ({
+        name: task.name,
+        taskRef: task.taskRef?.name,
+        dependencies: task.dependencies || [],
+      })),
+      parameters: Object.keys(workflow.spec?.environment || {}),
+      lastRun: {
+        phase: workflow.status?.phase,
+        lastRunTime: workflow.status?.lastRunTime,
+        lastRunDuration: workflow.status?.lastRunDuration,
+        nextRunTime: workflow.status?.nextRunTime,
+      },
+    }));
+
+    console.log('[DAG-API] 📚 Catalog for namespace:', namespace, '- tasks:', tasks.length, 'workflows:', workflows.length);
+
+    res.json({
+      success: true,
+      namespace,
+      tasks,
+      workflows,
+    });
+  } catch (error) {
+    console.error('Error building catalog:', error);
+    res.status(500).json({
+      success: false,
+      error: error instanceof Error ? error.message : String(error)
+    });
+  }
+});
+
 // GET /api/namespaces - Get available namespaces with mcall resources
 router.get('/namespaces', async (req, res) => {
   try {
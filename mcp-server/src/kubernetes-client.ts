@@ -1,4 +1,5 @@
 import * as k8s from "@kubernetes/client-node";
+import { withDecodedOutput } from "./task-output.js";
 
 interface TaskParams {
   name: string;
@@ -107,7 +108,8 @@ export class KubernetesClient {
         this.taskPlural,
         name
       );
-      return response.body;
+      // Outputs are returned decoded, the controller may store them compressed
+      return withDecodedOutput(response.body);
     } catch (error: any) {
       throw new Error(`Failed to get task: ${error.body?.message || error.message}`);
     }
@@ -128,7 +130,9 @@ export class KubernetesClient {
         undefined,
         labelSelector
       );
-      return response.body;
+      const body: any = response.body;
+      (body.items || []).forEach(withDecodedOutput);
+      return body;
     } catch (error: any) {
       throw new Error(`Failed to list tasks: ${error.body?.message || error.message}`);
     }
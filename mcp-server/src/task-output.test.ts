@@ -0,0 +1,56 @@
+import { test } from 'node:test';
+import assert from 'node:assert/strict';
+import * as zlib from 'zlib';
+import { decodeOutput, withDecodedOutput } from './task-output.js';
+
+const output = 'HTTP/1.1 200 OK\n'.repeat(200);
+
+function taskWithOutput(output: string, outputEncoding?: string): any {
+  return {
+    metadata: { name: 'health-check' },
+    status: { phase: 'Succeeded', result: { output, outputEncoding, errorCode: '0' } },
+  };
+}
+
+test('decodeOutput returns outputs without encoding as they are', () => {
+  assert.equal(decodeOutput('ok'), 'ok');
+  assert.equal(decodeOutput('ok', ''), 'ok');
+});
+
+test('decodeOutput decodes gzip outputs', () => {
+  const encoded = zlib.gzipSync(output).toString('base64');
+  assert.equal(decodeOutput(encoded, 'gzip'), output);
+});
+
+test('decodeOutput decodes zstd outputs where Node.js supports zstd', (t) => {
+  const zstdCompressSync = (zlib as any).zstdCompressSync;
+  if (!zstdCompressSync) {
+    assert.throws(() => decodeOutput('', 'zstd'), /Node.js 22.15/);
+    t.skip(`zstd not supported by Node.js ${process.version}`);
+    return;
+  }
+  const encoded = zstdCompressSync(Buffer.from(output)).toString('base64');
+  assert.equal(decodeOutput(encoded, 'zstd'), output);
+});
+
+test('decodeOutput rejects unknown encodings', () => {
+  assert.throws(() => decodeOutput('ok', 'brotli'), /unknown output encoding brotli/);
+});
+
+test('withDecodedOutput replaces the compressed output of a task', () => {
+  const task = withDecodedOutput(taskWithOutput(zlib.gzipSync(output).toString('base64'), 'gzip'));
+  assert.equal(task.status.result.output, output);
+  assert.equal(task.status.result.outputEncoding, undefined);
+  assert.equal(task.status.result.errorCode, '0');
+});
+
+test('withDecodedOutput keeps outputs it cannot decode', () => {
+  const task = withDecodedOutput(taskWithOutput('not gzip', 'gzip'));
+  assert.equal(task.status.result.output, 'not gzip');
+  assert.equal(task.status.result.outputEncoding, 'gzip');
+});
+
+test('withDecodedOutput leaves tasks without result alone', () => {
+  const task = { metadata: { name: 'pending' }, status: { phase: 'Pending' } };
+  assert.deepEqual(withDecodedOutput(task), { metadata: { name: 'pending' }, status: { phase: 'Pending' } });
+});
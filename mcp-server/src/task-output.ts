@@ -0,0 +1,46 @@
+/**
+ * Decoding of task outputs the controller stores compressed:
+ * status.result.outputEncoding is "gzip" or "zstd" when status.result.output
+ * holds the compressed output, base64 encoded
+ */
+
+import * as zlib from 'zlib';
+
+// zstd is built into Node.js from 22.15 on
+const zstdDecompressSync: ((buffer: Buffer) => Buffer) | undefined = (zlib as any).zstdDecompressSync;
+
+export function decodeOutput(output: string, encoding?: string): string {
+  if (!encoding) {
+    return output;
+  }
+
+  const data = Buffer.from(output, 'base64');
+  switch (encoding) {
+    case 'gzip':
+      return zlib.gunzipSync(data).toString('utf8');
+    case 'zstd':
+      if (!zstdDecompressSync) {
+        throw new Error(`zstd outputs need Node.js 22.15 or later (running ${process.version})`);
+      }
+      return zstdDecompressSync(data).toString('utf8');
+    default:
+      throw new Error(`unknown output encoding ${encoding}`);
+  }
+}
+
+// withDecodedOutput replaces the compressed output of a task with its decoded output.
+// An output that can't be decoded is kept as it is, with its outputEncoding.
+export function withDecodedOutput(task: any): any {
+  const result = task?.status?.result;
+  if (!result?.outputEncoding || !result.output) {
+    return task;
+  }
+
+  try {
+    result.output = decodeOutput(result.output, result.outputEncoding);
+    delete result.outputEncoding;
+  } catch (error) {
+    console.error(`Failed to decode output of task ${task.metadata?.name}:`, error);
+  }
+  return task;
+}
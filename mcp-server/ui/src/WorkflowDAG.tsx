@@ -49,6 +49,8 @@ const getNodeColor = (phase: string): string => {
       return '#9e9e9e'; // Gray
     case 'Skipped':
       return '#e0e0e0'; // Light Gray
+    case 'Blocked':
+      return '#ff9800'; // Orange
     default:
       return '#bdbdbd';
   }
@@ -62,6 +64,8 @@ const getEdgeColor = (type: string): string => {
       return '#f44336'; // Red
     case 'always':
       return '#9e9e9e'; // Gray
+    case 'dataflow':
+      return '#2196f3'; // Blue
     default:
       return '#757575';
   }
@@ -79,6 +83,8 @@ const getPhaseIcon = (phase: string): string => {
       return '⚪';
     case 'Skipped':
       return '⏭️';
+    case 'Blocked':
+      return '⛔';
     default:
       return '❓';
   }
@@ -425,6 +431,7 @@ export function WorkflowDAG({ namespace, workflowName }: WorkflowDAGProps) {
           style: {
             stroke: getEdgeColor(edge.type),
             strokeWidth: 2,
+            strokeDasharray: edge.type === 'dataflow' ? '6 4' : undefined, // Dashed data flow edges
             opacity: showingStale ? 0.7 : 1, // Dim edges when stale
           },
           markerEnd: {
@@ -859,6 +866,12 @@ export function WorkflowDAG({ namespace, workflowName }: WorkflowDAGProps) {
                   {selectedTask.status.result.output && (
                     <div>
                       <h4 style={{ fontSize: '14px', marginBottom: '8px', color: '#666' }}>Output:</h4>
+                      {/* The server decodes compressed outputs; one it couldn't decode is shown as stored */}
+                      {selectedTask.status.result.outputEncoding && (
+                        <div style={{ fontSize: '12px', marginBottom: '8px', color: '#f44336' }}>
+                          Compressed output ({selectedTask.status.result.outputEncoding}, base64), could not be decoded
+                        </div>
+                      )}
                       <pre style={{
                         textAlign: 'left',
                         backgroundColor: '#f5f5f5',
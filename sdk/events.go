@@ -0,0 +1,140 @@
+// Package sdk contains helpers for applications embedding the mcall operator API
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TaskEvent is a typed McallTask lifecycle event
+type TaskEvent struct {
+	// Name is the name of the McallTask
+	Name string
+
+	// Namespace is the namespace of the McallTask
+	Namespace string
+
+	// Phase is the phase the task moved to
+	Phase mcallv1.McallTaskPhase
+
+	// RunID is the workflow run ID, or the McallTaskRun name for standalone tasks
+	RunID string
+
+	// Result is the execution result (set once the task completed)
+	Result *mcallv1.McallTaskResult
+}
+
+// WatchTaskEvents starts an informer cache for McallTasks and streams their phase changes.
+// An empty namespace watches all namespaces. The channel is closed when ctx is done.
+func WatchTaskEvents(ctx context.Context, config *rest.Config, namespace string) (<-chan TaskEvent, error) {
+	scheme := runtime.NewScheme()
+	if err := mcallv1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+
+	options := cache.Options{Scheme: scheme}
+	if namespace != "" {
+		options.DefaultNamespaces = map[string]cache.Config{namespace: {}}
+	}
+	informers, err := cache.New(config, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create informer cache: %w", err)
+	}
+
+	events, err := WatchTaskEventsFromInformers(ctx, informers)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		_ = informers.Start(ctx)
+	}()
+	if !informers.WaitForCacheSync(ctx) {
+		return nil, fmt.Errorf("failed to sync McallTask informer")
+	}
+
+	return events, nil
+}
+
+// WatchTaskEventsFromInformers streams McallTask phase changes from existing informers,
+// e.g. a controller manager's cache. Tasks already present when the informer syncs are
+// reported once with their current phase. The channel is closed when ctx is done.
+func WatchTaskEventsFromInformers(ctx context.Context, informers cache.Informers) (<-chan TaskEvent, error) {
+	informer, err := informers.GetInformer(ctx, &mcallv1.McallTask{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get McallTask informer: %w", err)
+	}
+
+	events := make(chan TaskEvent, 100)
+	var mu sync.Mutex
+	closed := false
+
+	send := func(task *mcallv1.McallTask) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		select {
+		case events <- newTaskEvent(task):
+		case <-ctx.Done():
+		}
+	}
+
+	registration, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if task, ok := obj.(*mcallv1.McallTask); ok && task.Status.Phase != "" {
+				send(task)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldTask, ok := oldObj.(*mcallv1.McallTask)
+			if !ok {
+				return
+			}
+			newTask, ok := newObj.(*mcallv1.McallTask)
+			if !ok || newTask.Status.Phase == "" || newTask.Status.Phase == oldTask.Status.Phase {
+				return
+			}
+			send(newTask)
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add McallTask event handler: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = informer.RemoveEventHandler(registration)
+		mu.Lock()
+		closed = true
+		close(events)
+		mu.Unlock()
+	}()
+
+	return events, nil
+}
+
+// newTaskEvent builds the event for a task's current status
+func newTaskEvent(task *mcallv1.McallTask) TaskEvent {
+	runID := task.Labels["mcall.tz.io/run"]
+	if runID == "" {
+		runID = task.Status.LastTaskRun
+	}
+
+	return TaskEvent{
+		Name:      task.Name,
+		Namespace: task.Namespace,
+		Phase:     task.Status.Phase,
+		RunID:     runID,
+		Result:    task.Status.Result.DeepCopy(),
+	}
+}
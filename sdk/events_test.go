@@ -0,0 +1,75 @@
+package sdk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+
+	mcallv1 "github.com/doohee323/tz-mcall-operator/api/v1"
+)
+
+// TestWatchTaskEventsFromInformers tests streaming task phase changes as typed events
+func TestWatchTaskEventsFromInformers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = mcallv1.AddToScheme(scheme)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	informers := &informertest.FakeInformers{Scheme: scheme}
+	events, err := WatchTaskEventsFromInformers(ctx, informers)
+	if err != nil {
+		t.Fatalf("WatchTaskEventsFromInformers() error = %v", err)
+	}
+	informer, err := informers.FakeInformerFor(ctx, &mcallv1.McallTask{})
+	if err != nil {
+		t.Fatalf("FakeInformerFor() error = %v", err)
+	}
+
+	pending := &mcallv1.McallTask{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "health-api",
+			Namespace: "default",
+			Labels:    map[string]string{"mcall.tz.io/run": "20250102-030405"},
+		},
+		Status: mcallv1.McallTaskStatus{Phase: mcallv1.McallTaskPhasePending},
+	}
+	informer.Add(pending)
+
+	requeued := pending.DeepCopy()
+	informer.Update(pending, requeued) // same phase, no event
+
+	succeeded := pending.DeepCopy()
+	succeeded.Status.Phase = mcallv1.McallTaskPhaseSucceeded
+	succeeded.Status.Result = &mcallv1.McallTaskResult{Output: "ok", ErrorCode: "0"}
+	informer.Update(requeued, succeeded)
+
+	want := []mcallv1.McallTaskPhase{mcallv1.McallTaskPhasePending, mcallv1.McallTaskPhaseSucceeded}
+	for _, phase := range want {
+		select {
+		case event := <-events:
+			if event.Name != "health-api" || event.Phase != phase || event.RunID != "20250102-030405" {
+				t.Errorf("event = %+v, want phase %s", event, phase)
+			}
+			if phase == mcallv1.McallTaskPhaseSucceeded && (event.Result == nil || event.Result.Output != "ok") {
+				t.Errorf("event result = %+v, want output ok", event.Result)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %s event", phase)
+		}
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Errorf("unexpected event after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("channel not closed after cancel")
+	}
+}